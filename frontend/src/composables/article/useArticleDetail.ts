@@ -96,6 +96,8 @@ export function useArticleDetail() {
   const showContent = ref(false);
   const articleContent = ref('');
   const isLoadingContent = ref(false);
+  const fullTextMode = ref(''); // The feed's full-text mode ('' follows the global setting)
+  const hasFullText = ref(false); // Whether the content already is the full text
   const currentArticleId = ref<number | null>(null);
   const defaultViewMode = ref<ViewMode>('original');
   const pendingRenderAction = ref<RenderAction>(null);
@@ -114,6 +116,8 @@ export function useArticleDetail() {
 
         // Reset content when switching articles
         articleContent.value = '';
+        fullTextMode.value = '';
+        hasFullText.value = false;
         currentArticleId.value = null;
 
         // Always fetch article content for AI chat and translation features
@@ -234,6 +238,9 @@ export function useArticleDetail() {
           content = proxyImagesInHtml(content, feedUrl);
         }
 
+        // Set before the content so the auto-fetch sees the feed's full-text mode
+        fullTextMode.value = data.full_text_mode || '';
+        hasFullText.value = data.full_text === true;
         articleContent.value = content;
 
         // Only show loading animation for non-cached content
@@ -651,6 +658,8 @@ export function useArticleDetail() {
     showContent,
     articleContent,
     isLoadingContent,
+    fullTextMode,
+    hasFullText,
     imageViewerSrc,
     imageViewerAlt,
     locale,
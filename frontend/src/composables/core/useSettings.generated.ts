@@ -22,32 +22,49 @@ export function generateInitialSettings(): SettingsData {
     ai_translation_prompt: settingsDefaults.ai_translation_prompt,
     ai_usage_limit: settingsDefaults.ai_usage_limit,
     ai_usage_tokens: settingsDefaults.ai_usage_tokens,
+    auto_backup_enabled: settingsDefaults.auto_backup_enabled,
+    auto_backup_interval_hours: settingsDefaults.auto_backup_interval_hours,
+    auto_backup_keep: settingsDefaults.auto_backup_keep,
     auto_cleanup_enabled: settingsDefaults.auto_cleanup_enabled,
     auto_show_all_content: settingsDefaults.auto_show_all_content,
     auto_update: settingsDefaults.auto_update,
     baidu_app_id: settingsDefaults.baidu_app_id,
     baidu_secret_key: settingsDefaults.baidu_secret_key,
     close_to_tray: settingsDefaults.close_to_tray,
+    collapse_duplicate_articles: settingsDefaults.collapse_duplicate_articles,
     custom_css_file: settingsDefaults.custom_css_file,
+    dead_feed_days: settingsDefaults.dead_feed_days,
     deepl_api_key: settingsDefaults.deepl_api_key,
     deepl_endpoint: settingsDefaults.deepl_endpoint,
     default_view_mode: settingsDefaults.default_view_mode,
+    fever_enabled: settingsDefaults.fever_enabled,
+    fever_password: settingsDefaults.fever_password,
+    fever_username: settingsDefaults.fever_username,
     freshrss_api_password: settingsDefaults.freshrss_api_password,
     freshrss_auto_sync_interval: settingsDefaults.freshrss_auto_sync_interval,
     freshrss_enabled: settingsDefaults.freshrss_enabled,
     freshrss_last_sync_time: settingsDefaults.freshrss_last_sync_time,
+    freshrss_provider: settingsDefaults.freshrss_provider,
     freshrss_server_url: settingsDefaults.freshrss_server_url,
     freshrss_sync_on_startup: settingsDefaults.freshrss_sync_on_startup,
     freshrss_username: settingsDefaults.freshrss_username,
     full_text_fetch_enabled: settingsDefaults.full_text_fetch_enabled,
     google_translate_endpoint: settingsDefaults.google_translate_endpoint,
+    greader_enabled: settingsDefaults.greader_enabled,
+    greader_password: settingsDefaults.greader_password,
+    greader_username: settingsDefaults.greader_username,
+    host_request_interval_ms: settingsDefaults.host_request_interval_ms,
     hover_mark_as_read: settingsDefaults.hover_mark_as_read,
     image_gallery_enabled: settingsDefaults.image_gallery_enabled,
     language: settingsDefaults.language,
+    last_auto_backup: settingsDefaults.last_auto_backup,
     last_global_refresh: settingsDefaults.last_global_refresh,
     last_network_test: settingsDefaults.last_network_test,
+    mark_duplicates_read: settingsDefaults.mark_duplicates_read,
+    mark_updated_articles_unread: settingsDefaults.mark_updated_articles_unread,
     max_article_age_days: settingsDefaults.max_article_age_days,
     max_cache_size_mb: settingsDefaults.max_cache_size_mb,
+    max_concurrent_per_host: settingsDefaults.max_concurrent_per_host,
     max_concurrent_refreshes: settingsDefaults.max_concurrent_refreshes,
     media_cache_enabled: settingsDefaults.media_cache_enabled,
     media_cache_max_age_days: settingsDefaults.media_cache_max_age_days,
@@ -71,10 +88,17 @@ export function generateInitialSettings(): SettingsData {
     rsshub_enabled: settingsDefaults.rsshub_enabled,
     rsshub_endpoint: settingsDefaults.rsshub_endpoint,
     rules: settingsDefaults.rules,
+    rules_webhook_url: settingsDefaults.rules_webhook_url,
     shortcuts: settingsDefaults.shortcuts,
     shortcuts_enabled: settingsDefaults.shortcuts_enabled,
     show_article_preview_images: settingsDefaults.show_article_preview_images,
     show_hidden_articles: settingsDefaults.show_hidden_articles,
+    smtp_from: settingsDefaults.smtp_from,
+    smtp_host: settingsDefaults.smtp_host,
+    smtp_password: settingsDefaults.smtp_password,
+    smtp_port: settingsDefaults.smtp_port,
+    smtp_security: settingsDefaults.smtp_security,
+    smtp_username: settingsDefaults.smtp_username,
     startup_on_boot: settingsDefaults.startup_on_boot,
     summary_enabled: settingsDefaults.summary_enabled,
     summary_length: settingsDefaults.summary_length,
@@ -85,6 +109,7 @@ export function generateInitialSettings(): SettingsData {
     translation_enabled: settingsDefaults.translation_enabled,
     translation_provider: settingsDefaults.translation_provider,
     update_interval: settingsDefaults.update_interval,
+    websub_callback_url: settingsDefaults.websub_callback_url,
     window_height: settingsDefaults.window_height,
     window_maximized: settingsDefaults.window_maximized,
     window_width: settingsDefaults.window_width,
@@ -108,36 +133,56 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     ai_translation_prompt: data.ai_translation_prompt || settingsDefaults.ai_translation_prompt,
     ai_usage_limit: data.ai_usage_limit || settingsDefaults.ai_usage_limit,
     ai_usage_tokens: data.ai_usage_tokens || settingsDefaults.ai_usage_tokens,
+    auto_backup_enabled: data.auto_backup_enabled === 'true',
+    auto_backup_interval_hours:
+      parseInt(data.auto_backup_interval_hours) || settingsDefaults.auto_backup_interval_hours,
+    auto_backup_keep: parseInt(data.auto_backup_keep) || settingsDefaults.auto_backup_keep,
     auto_cleanup_enabled: data.auto_cleanup_enabled === 'true',
     auto_show_all_content: data.auto_show_all_content === 'true',
     auto_update: data.auto_update === 'true',
     baidu_app_id: data.baidu_app_id || settingsDefaults.baidu_app_id,
     baidu_secret_key: data.baidu_secret_key || settingsDefaults.baidu_secret_key,
     close_to_tray: data.close_to_tray === 'true',
+    collapse_duplicate_articles: data.collapse_duplicate_articles === 'true',
     custom_css_file: data.custom_css_file || settingsDefaults.custom_css_file,
+    dead_feed_days: parseInt(data.dead_feed_days) || settingsDefaults.dead_feed_days,
     deepl_api_key: data.deepl_api_key || settingsDefaults.deepl_api_key,
     deepl_endpoint: data.deepl_endpoint || settingsDefaults.deepl_endpoint,
     default_view_mode: data.default_view_mode || settingsDefaults.default_view_mode,
+    fever_enabled: data.fever_enabled === 'true',
+    fever_password: data.fever_password || settingsDefaults.fever_password,
+    fever_username: data.fever_username || settingsDefaults.fever_username,
     freshrss_api_password: data.freshrss_api_password || settingsDefaults.freshrss_api_password,
     freshrss_auto_sync_interval:
       parseInt(data.freshrss_auto_sync_interval) || settingsDefaults.freshrss_auto_sync_interval,
     freshrss_enabled: data.freshrss_enabled === 'true',
     freshrss_last_sync_time:
       data.freshrss_last_sync_time || settingsDefaults.freshrss_last_sync_time,
+    freshrss_provider: data.freshrss_provider || settingsDefaults.freshrss_provider,
     freshrss_server_url: data.freshrss_server_url || settingsDefaults.freshrss_server_url,
     freshrss_sync_on_startup: data.freshrss_sync_on_startup === 'true',
     freshrss_username: data.freshrss_username || settingsDefaults.freshrss_username,
     full_text_fetch_enabled: data.full_text_fetch_enabled === 'true',
     google_translate_endpoint:
       data.google_translate_endpoint || settingsDefaults.google_translate_endpoint,
+    greader_enabled: data.greader_enabled === 'true',
+    greader_password: data.greader_password || settingsDefaults.greader_password,
+    greader_username: data.greader_username || settingsDefaults.greader_username,
+    host_request_interval_ms:
+      parseInt(data.host_request_interval_ms) || settingsDefaults.host_request_interval_ms,
     hover_mark_as_read: data.hover_mark_as_read === 'true',
     image_gallery_enabled: data.image_gallery_enabled === 'true',
     language: data.language || settingsDefaults.language,
+    last_auto_backup: data.last_auto_backup || settingsDefaults.last_auto_backup,
     last_global_refresh: data.last_global_refresh || settingsDefaults.last_global_refresh,
     last_network_test: data.last_network_test || settingsDefaults.last_network_test,
+    mark_duplicates_read: data.mark_duplicates_read === 'true',
+    mark_updated_articles_unread: data.mark_updated_articles_unread === 'true',
     max_article_age_days:
       parseInt(data.max_article_age_days) || settingsDefaults.max_article_age_days,
     max_cache_size_mb: parseInt(data.max_cache_size_mb) || settingsDefaults.max_cache_size_mb,
+    max_concurrent_per_host:
+      parseInt(data.max_concurrent_per_host) || settingsDefaults.max_concurrent_per_host,
     max_concurrent_refreshes:
       data.max_concurrent_refreshes || settingsDefaults.max_concurrent_refreshes,
     media_cache_enabled: data.media_cache_enabled === 'true',
@@ -165,10 +210,17 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     rsshub_enabled: data.rsshub_enabled === 'true',
     rsshub_endpoint: data.rsshub_endpoint || settingsDefaults.rsshub_endpoint,
     rules: data.rules || settingsDefaults.rules,
+    rules_webhook_url: data.rules_webhook_url || settingsDefaults.rules_webhook_url,
     shortcuts: data.shortcuts || settingsDefaults.shortcuts,
     shortcuts_enabled: data.shortcuts_enabled === 'true',
     show_article_preview_images: data.show_article_preview_images === 'true',
     show_hidden_articles: data.show_hidden_articles === 'true',
+    smtp_from: data.smtp_from || settingsDefaults.smtp_from,
+    smtp_host: data.smtp_host || settingsDefaults.smtp_host,
+    smtp_password: data.smtp_password || settingsDefaults.smtp_password,
+    smtp_port: parseInt(data.smtp_port) || settingsDefaults.smtp_port,
+    smtp_security: data.smtp_security || settingsDefaults.smtp_security,
+    smtp_username: data.smtp_username || settingsDefaults.smtp_username,
     startup_on_boot: data.startup_on_boot === 'true',
     summary_enabled: data.summary_enabled === 'true',
     summary_length: data.summary_length || settingsDefaults.summary_length,
@@ -179,6 +231,7 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     translation_enabled: data.translation_enabled === 'true',
     translation_provider: data.translation_provider || settingsDefaults.translation_provider,
     update_interval: parseInt(data.update_interval) || settingsDefaults.update_interval,
+    websub_callback_url: data.websub_callback_url || settingsDefaults.websub_callback_url,
     window_height: data.window_height || settingsDefaults.window_height,
     window_maximized: data.window_maximized || settingsDefaults.window_maximized,
     window_width: data.window_width || settingsDefaults.window_width,
@@ -205,6 +258,15 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
       settingsRef.value.ai_translation_prompt ?? settingsDefaults.ai_translation_prompt,
     ai_usage_limit: settingsRef.value.ai_usage_limit ?? settingsDefaults.ai_usage_limit,
     ai_usage_tokens: settingsRef.value.ai_usage_tokens ?? settingsDefaults.ai_usage_tokens,
+    auto_backup_enabled: (
+      settingsRef.value.auto_backup_enabled ?? settingsDefaults.auto_backup_enabled
+    ).toString(),
+    auto_backup_interval_hours: (
+      settingsRef.value.auto_backup_interval_hours ?? settingsDefaults.auto_backup_interval_hours
+    ).toString(),
+    auto_backup_keep: (
+      settingsRef.value.auto_backup_keep ?? settingsDefaults.auto_backup_keep
+    ).toString(),
     auto_cleanup_enabled: (
       settingsRef.value.auto_cleanup_enabled ?? settingsDefaults.auto_cleanup_enabled
     ).toString(),
@@ -215,10 +277,19 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     baidu_app_id: settingsRef.value.baidu_app_id ?? settingsDefaults.baidu_app_id,
     baidu_secret_key: settingsRef.value.baidu_secret_key ?? settingsDefaults.baidu_secret_key,
     close_to_tray: (settingsRef.value.close_to_tray ?? settingsDefaults.close_to_tray).toString(),
+    collapse_duplicate_articles: (
+      settingsRef.value.collapse_duplicate_articles ?? settingsDefaults.collapse_duplicate_articles
+    ).toString(),
     custom_css_file: settingsRef.value.custom_css_file ?? settingsDefaults.custom_css_file,
+    dead_feed_days: (
+      settingsRef.value.dead_feed_days ?? settingsDefaults.dead_feed_days
+    ).toString(),
     deepl_api_key: settingsRef.value.deepl_api_key ?? settingsDefaults.deepl_api_key,
     deepl_endpoint: settingsRef.value.deepl_endpoint ?? settingsDefaults.deepl_endpoint,
     default_view_mode: settingsRef.value.default_view_mode ?? settingsDefaults.default_view_mode,
+    fever_enabled: (settingsRef.value.fever_enabled ?? settingsDefaults.fever_enabled).toString(),
+    fever_password: settingsRef.value.fever_password ?? settingsDefaults.fever_password,
+    fever_username: settingsRef.value.fever_username ?? settingsDefaults.fever_username,
     freshrss_api_password:
       settingsRef.value.freshrss_api_password ?? settingsDefaults.freshrss_api_password,
     freshrss_auto_sync_interval: (
@@ -229,6 +300,7 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     ).toString(),
     freshrss_last_sync_time:
       settingsRef.value.freshrss_last_sync_time ?? settingsDefaults.freshrss_last_sync_time,
+    freshrss_provider: settingsRef.value.freshrss_provider ?? settingsDefaults.freshrss_provider,
     freshrss_server_url:
       settingsRef.value.freshrss_server_url ?? settingsDefaults.freshrss_server_url,
     freshrss_sync_on_startup: (
@@ -240,6 +312,14 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     ).toString(),
     google_translate_endpoint:
       settingsRef.value.google_translate_endpoint ?? settingsDefaults.google_translate_endpoint,
+    greader_enabled: (
+      settingsRef.value.greader_enabled ?? settingsDefaults.greader_enabled
+    ).toString(),
+    greader_password: settingsRef.value.greader_password ?? settingsDefaults.greader_password,
+    greader_username: settingsRef.value.greader_username ?? settingsDefaults.greader_username,
+    host_request_interval_ms: (
+      settingsRef.value.host_request_interval_ms ?? settingsDefaults.host_request_interval_ms
+    ).toString(),
     hover_mark_as_read: (
       settingsRef.value.hover_mark_as_read ?? settingsDefaults.hover_mark_as_read
     ).toString(),
@@ -248,12 +328,21 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     ).toString(),
     language: settingsRef.value.language ?? settingsDefaults.language,
     last_network_test: settingsRef.value.last_network_test ?? settingsDefaults.last_network_test,
+    mark_duplicates_read: (
+      settingsRef.value.mark_duplicates_read ?? settingsDefaults.mark_duplicates_read
+    ).toString(),
+    mark_updated_articles_unread: (
+      settingsRef.value.mark_updated_articles_unread ?? settingsDefaults.mark_updated_articles_unread
+    ).toString(),
     max_article_age_days: (
       settingsRef.value.max_article_age_days ?? settingsDefaults.max_article_age_days
     ).toString(),
     max_cache_size_mb: (
       settingsRef.value.max_cache_size_mb ?? settingsDefaults.max_cache_size_mb
     ).toString(),
+    max_concurrent_per_host: (
+      settingsRef.value.max_concurrent_per_host ?? settingsDefaults.max_concurrent_per_host
+    ).toString(),
     max_concurrent_refreshes:
       settingsRef.value.max_concurrent_refreshes ?? settingsDefaults.max_concurrent_refreshes,
     media_cache_enabled: (
@@ -294,6 +383,7 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     ).toString(),
     rsshub_endpoint: settingsRef.value.rsshub_endpoint ?? settingsDefaults.rsshub_endpoint,
     rules: settingsRef.value.rules ?? settingsDefaults.rules,
+    rules_webhook_url: settingsRef.value.rules_webhook_url ?? settingsDefaults.rules_webhook_url,
     shortcuts: settingsRef.value.shortcuts ?? settingsDefaults.shortcuts,
     shortcuts_enabled: (
       settingsRef.value.shortcuts_enabled ?? settingsDefaults.shortcuts_enabled
@@ -304,6 +394,12 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     show_hidden_articles: (
       settingsRef.value.show_hidden_articles ?? settingsDefaults.show_hidden_articles
     ).toString(),
+    smtp_from: settingsRef.value.smtp_from ?? settingsDefaults.smtp_from,
+    smtp_host: settingsRef.value.smtp_host ?? settingsDefaults.smtp_host,
+    smtp_password: settingsRef.value.smtp_password ?? settingsDefaults.smtp_password,
+    smtp_port: (settingsRef.value.smtp_port ?? settingsDefaults.smtp_port).toString(),
+    smtp_security: settingsRef.value.smtp_security ?? settingsDefaults.smtp_security,
+    smtp_username: settingsRef.value.smtp_username ?? settingsDefaults.smtp_username,
     startup_on_boot: (
       settingsRef.value.startup_on_boot ?? settingsDefaults.startup_on_boot
     ).toString(),
@@ -324,5 +420,7 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     update_interval: (
       settingsRef.value.update_interval ?? settingsDefaults.update_interval
     ).toString(),
+    websub_callback_url:
+      settingsRef.value.websub_callback_url ?? settingsDefaults.websub_callback_url,
   };
 }
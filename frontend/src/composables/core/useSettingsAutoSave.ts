@@ -32,8 +32,10 @@ export function useSettingsAutoSave(settings: Ref<SettingsData> | (() => Setting
   // Track previous article display settings to prevent unnecessary refreshes
   const prevArticleDisplaySettings: Ref<{
     showHiddenArticles: string;
+    collapseDuplicateArticles: boolean;
   }> = ref({
     showHiddenArticles: settingsDefaults.show_hidden_articles,
+    collapseDuplicateArticles: settingsDefaults.collapse_duplicate_articles,
   });
 
   /**
@@ -48,6 +50,7 @@ export function useSettingsAutoSave(settings: Ref<SettingsData> | (() => Setting
       };
       prevArticleDisplaySettings.value = {
         showHiddenArticles: settingsRef.value.show_hidden_articles,
+        collapseDuplicateArticles: settingsRef.value.collapse_duplicate_articles,
       };
       isInitialLoad = false;
     }, 100);
@@ -132,6 +135,16 @@ export function useSettingsAutoSave(settings: Ref<SettingsData> | (() => Setting
           settingsRef.value.show_hidden_articles;
       }
 
+      // Refresh articles if collapse_duplicate_articles changed
+      if (
+        settingsRef.value.collapse_duplicate_articles !==
+        prevArticleDisplaySettings.value.collapseDuplicateArticles
+      ) {
+        store.fetchArticles();
+        prevArticleDisplaySettings.value.collapseDuplicateArticles =
+          settingsRef.value.collapse_duplicate_articles;
+      }
+
       // Notify about show_article_preview_images change
       window.dispatchEvent(
         new CustomEvent('show-preview-images-changed', {
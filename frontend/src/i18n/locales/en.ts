@@ -147,6 +147,13 @@ const en: TranslationMessages = {
   audioPlaybackError:
     'Failed to play audio. The file may be unavailable or in an unsupported format.',
   auto: 'Auto (Follow System)',
+  autoBackup: 'Automatic Backups',
+  autoBackupDesc:
+    'Regularly write a backup without secrets next to the database, in the backups folder',
+  autoBackupInterval: 'Backup Interval',
+  autoBackupIntervalDesc: 'Time between automatic backups',
+  autoBackupKeep: 'Backups to Keep',
+  autoBackupKeepDesc: 'Older automatic backups are deleted',
   autoCleanup: 'Auto Cleanup',
   autoCleanupDesc: 'Automatically remove old articles to save space',
   autoTranslateEnabled: 'Auto-translate enabled',
@@ -156,6 +163,27 @@ const en: TranslationMessages = {
   backToRss: 'Back to RSS',
   backToSimple: 'Back to Simple',
   backToUrl: 'Back to URL',
+  backupExport: 'Export Backup',
+  backupRestore: 'Restore Backup',
+  backupDesc:
+    'Back up or restore everything: feeds, articles with their read state, summaries, translations, rules, chats, settings and statistics',
+  backupIncludeSecretsTitle: 'Include Secrets?',
+  backupIncludeSecretsMessage:
+    'Include API keys, passwords and feed credentials in the backup? They are encrypted with the backup passphrase, or with the master passphrase if none is entered.',
+  backupIncludeSecrets: 'Include',
+  backupPassphrase: 'Backup Passphrase',
+  backupPassphraseDesc:
+    'Encrypts the secrets in exported backups, and is needed to restore them. Leave empty to use the master passphrase, or to restore a backup without its secrets.',
+  backupPassphrasePlaceholder: 'Passphrase',
+  backupLeaveOutSecrets: 'Leave Out',
+  backupRestoreTitle: 'Restore Backup',
+  backupRestoreMessage:
+    'Merge the backup into your current data, or replace all current data with the backup?',
+  backupRestoreMerge: 'Merge',
+  backupRestoreReplace: 'Replace',
+  backupRestoredSuccess: 'Backup restored ({count} conflicts, local values were kept)',
+  backupSecretsNotRestored:
+    'The secrets in the backup were not restored. Enter the passphrase they were exported with.',
   baiduAppId: 'Baidu App ID',
   baiduAppIdDesc: 'Enter the Baidu Translate App ID',
   baiduAppIdPlaceholder: 'Enter your App ID',
@@ -234,6 +262,27 @@ const en: TranslationMessages = {
   testConnection: 'Test Connection',
   connectionError: 'Network error. Please try again.',
   fillRequiredFields: 'Please fill in IMAP server, username, and password.',
+  emailIdle: 'Instant Delivery (IMAP IDLE)',
+  emailIdleDesc: 'Keep a connection open so new newsletters appear right away',
+  emailSplitByList: 'One Feed per Newsletter',
+  emailSplitByListDesc:
+    'Put messages with a List-Id header into a separate feed for each newsletter. Deleting a newsletter feed drops its messages until this is turned off.',
+  emailFilters: 'Filters',
+  emailFiltersDesc: 'Only import messages matching an include rule and no exclude rule',
+  emailFilterAdd: 'Add filter',
+  emailFilterInclude: 'Include',
+  emailFilterExclude: 'Exclude',
+  emailFilterSender: 'Sender',
+  emailFilterSubject: 'Subject',
+  emailFilterContains: 'contains',
+  emailFilterExact: 'is',
+  emailFilterRegex: 'matches regex',
+  emailProcessedAction: 'Processed Messages',
+  emailProcessedActionDesc: 'What to do on the mail server with messages that were imported',
+  emailProcessedKeep: 'Leave unchanged',
+  emailProcessedMarkRead: 'Mark as read',
+  emailProcessedMove: 'Move to folder',
+  emailMoveFolderPlaceholder: 'Folder name',
   dark: 'Dark',
   darkMode: 'Dark Mode',
   darkModeDesc: 'Switch between light and dark themes',
@@ -302,6 +351,10 @@ const en: TranslationMessages = {
   enableFullTextFetch: 'Enable Full-Text Fetching',
   enableFullTextFetchDesc:
     'Allow fetching full article content from original websites when RSS provides only summaries',
+  markUpdatedArticlesUnread: 'Mark Updated Articles as Unread',
+  markUpdatedArticlesUnreadDesc:
+    'Mark articles as unread again when their publisher changes the title or content',
+  articleUpdated: 'Updated by the publisher',
   autoShowAllContent: 'Auto Show All Content',
   autoShowAllContentDesc:
     'Automatically display the full content of all articles when viewed as rendered content (may increase loading time)',
@@ -419,6 +472,9 @@ const en: TranslationMessages = {
   freshrssPassword: 'Password',
   freshrssPasswordDesc: 'The FreshRSS password',
   freshrssPasswordPlaceholder: 'Enter your password',
+  freshrssProvider: 'Server Type',
+  freshrssProviderDesc:
+    'The sync server software: FreshRSS, Miniflux, Nextcloud News or Tiny Tiny RSS',
   freshrssServerUrl: 'Server URL',
   freshrssServerUrlDesc: 'FreshRSS server endpoint (without /api path)',
   freshrssServerUrlPlaceholder: 'https://freshrss.example.com',
@@ -536,6 +592,19 @@ const en: TranslationMessages = {
   markAsUnread: 'Mark as Unread',
   markedAllAsRead: 'All articles marked as read',
   noArticlesToMark: 'No articles to mark',
+  masterPassphrase: 'Master Passphrase',
+  masterPassphraseDesc:
+    'Encrypt API keys and passwords with a passphrase instead of a key derived from this machine. It has to be entered after every start, or set in $MRRSS_MASTER_PASSPHRASE.',
+  masterPassphraseEnabled: 'Secrets are encrypted with the master passphrase',
+  masterPassphraseOff: 'Secrets are encrypted with the machine key',
+  masterPassphraseCurrent: 'Current passphrase',
+  masterPassphraseNew: 'New passphrase',
+  masterPassphraseSet: 'Set Passphrase',
+  masterPassphraseChange: 'Change Passphrase',
+  masterPassphraseRemove: 'Remove Passphrase',
+  masterPassphraseRemoveMessage: 'Secrets will be encrypted with the machine key again. Continue?',
+  masterPassphraseUpdated: 'Re-encrypted {count} secrets',
+  masterPassphraseUnreadable: '{count} secrets could not be decrypted and must be entered again',
   maxArticleAge: 'Max Article Age',
   maxArticleAgeDesc: 'Delete articles older than this many days (except favorites)',
   maxCacheSize: 'Max Cache Size',
@@ -708,6 +777,10 @@ const en: TranslationMessages = {
   scriptHelp:
     'Scripts should output valid RSS/Atom XML. Supported: Python, Shell, PowerShell, Node.js, Ruby.',
   scriptsFolderOpened: 'Scripts folder opened',
+  secretsLocked: 'Secrets are locked',
+  secretsLockedDesc: 'Enter the master passphrase to use API keys and passwords',
+  secretsUnlock: 'Unlock',
+  secretsUnlocked: 'Secrets unlocked',
   search: 'Search...',
   searchFeeds: 'Search feeds...',
   searchingFriendLinks: 'Searching for friend links',
@@ -739,6 +812,11 @@ const en: TranslationMessages = {
   showArticlePreviewImagesDesc: 'Display preview images in the article list',
   showHiddenArticles: 'Show Hidden Articles',
   showHiddenArticlesDesc: 'Show articles hidden in the All Articles list',
+  collapseDuplicateArticles: 'Collapse Duplicate Stories',
+  collapseDuplicateArticlesDesc: 'List a story reported by several feeds only once',
+  markDuplicatesRead: 'Mark Duplicates as Read',
+  markDuplicatesReadDesc: 'Reading a story also marks its copies from other feeds as read',
+  duplicateSources: '{count} more sources',
   showOriginal: 'Original',
   showRendered: 'Rendered',
   showTranslations: 'Show Translations',
@@ -911,6 +989,73 @@ const en: TranslationMessages = {
   aiSummaries: 'AI Summaries',
   articlesFavorited: 'Articles Favorited',
   articlesReadLater: 'Added to Read Later',
+  wrongPassphrase: 'Wrong passphrase',
+  smtpServer: 'Mail Server',
+  smtpServerDesc: 'SMTP server used to send the email digests',
+  smtpHost: 'SMTP Host',
+  smtpHostDesc: 'Host name and port of the mail server',
+  smtpSecurity: 'Security',
+  smtpSecurityDesc: 'STARTTLS upgrades the connection and is required if selected',
+  smtpSecurityNone: 'None',
+  smtpUsername: 'Username',
+  smtpUsernameDesc: 'Leave empty if the server does not require authentication',
+  smtpPassword: 'Password',
+  smtpFrom: 'Sender',
+  smtpFromDesc: 'Address the digests are sent from',
+  emailDigests: 'Email Digests',
+  emailDigestsDesc:
+    'Email the articles matching a filter on a schedule. Articles are never sent twice by the same digest.',
+  digestAdd: 'Add Digest',
+  digestSaved: 'Digest saved',
+  digestRecipients: 'Recipients',
+  digestSchedule: 'Schedule',
+  digestDaily: 'Daily',
+  digestWeekly: 'Weekly',
+  digestScheduleDaily: 'Daily at {hour}',
+  digestScheduleWeekly: 'Every {day} at {hour}',
+  digestArticles: 'Articles',
+  digestConditions: '{count} conditions',
+  digestMaxArticles: 'Maximum articles per email',
+  digestAISummary: 'Include AI summaries',
+  digestSendNow: 'Send now',
+  digestSent: 'Sent {count} articles',
+  digestFailed: 'Failed',
+  digestNothingToSend: 'No new articles to send',
+  digestHistory: 'Send history',
+  digestNoHistory: 'Not sent yet',
+  digestDeleteConfirmTitle: 'Delete Digest',
+  digestDeleteConfirmMessage: 'Delete the digest "{name}" and its send history?',
+  notifications: 'Notifications',
+  notificationsDesc:
+    'Send new articles to webhooks, chat rooms and push services as they are fetched. Failed messages are retried a few times before they are listed here.',
+  notificationAdd: 'Add Notification Target',
+  notificationTargetSaved: 'Notification target saved',
+  notificationType: 'Type',
+  notificationWebhook: 'Webhook',
+  notificationURL: 'URL',
+  notificationToken: 'Access Token',
+  notificationAppToken: 'Application Token',
+  notificationTokenSaved: 'Saved, leave empty to keep',
+  notificationRoom: 'Room ID',
+  notificationTemplate: 'JSON Template',
+  notificationTemplateDesc:
+    'Go template producing the request body, from .Target, .Title, .Count and .Articles. Use json to quote values. Leave empty to send every field.',
+  notificationAllArticles: 'All new articles',
+  notificationBatchWindow: 'Batch window (seconds)',
+  notificationBatchWindowDesc:
+    'Wait this long after a new article to send it with the ones that follow',
+  notificationMinInterval: 'Minimum interval (seconds)',
+  notificationMinIntervalDesc: 'Never send two messages closer together than this',
+  notificationMaxArticles: 'Maximum articles per message',
+  notificationTest: 'Send test',
+  notificationTestSent: 'Test notification sent',
+  notificationFailed: 'Failed notifications',
+  notificationArticleCount: '{count} articles',
+  notificationRetry: 'Retry',
+  notificationRetryQueued: 'Notification queued for delivery',
+  notificationDeleteConfirmTitle: 'Delete Notification Target',
+  notificationDeleteConfirmMessage:
+    'Delete the notification target "{name}" and its failed messages?',
 };
 
 export default en;
@@ -142,6 +142,12 @@ const zh: TranslationMessages = {
   articleTitle: '文章标题',
   audioPlaybackError: '无法播放音频。文件可能不可用或格式不受支持。',
   auto: '自动（跟随系统）',
+  autoBackup: '自动备份',
+  autoBackupDesc: '定期在数据库旁的 backups 文件夹中写入不含密钥的备份',
+  autoBackupInterval: '备份间隔',
+  autoBackupIntervalDesc: '两次自动备份之间的时间',
+  autoBackupKeep: '保留备份数',
+  autoBackupKeepDesc: '更早的自动备份将被删除',
   autoCleanup: '自动清理',
   autoCleanupDesc: '自动删除旧文章以节省空间',
   autoTranslateEnabled: '自动翻译已启用',
@@ -151,6 +157,23 @@ const zh: TranslationMessages = {
   backToRss: '返回 RSS',
   backToSimple: '返回简单模式',
   backToUrl: '返回 URL 模式',
+  backupExport: '导出备份',
+  backupRestore: '恢复备份',
+  backupDesc: '备份或恢复全部数据：订阅源、文章及阅读状态、摘要、翻译、规则、对话、设置和统计',
+  backupIncludeSecretsTitle: '包含密钥？',
+  backupIncludeSecretsMessage:
+    '是否在备份中包含 API 密钥、密码和订阅源凭据？它们将使用备份口令加密，未填写时使用主口令加密。',
+  backupIncludeSecrets: '包含',
+  backupPassphrase: '备份口令',
+  backupPassphraseDesc: '用于加密导出备份中的机密信息，恢复时也需要提供。留空则使用主口令，或在恢复时不包含机密信息。',
+  backupPassphrasePlaceholder: '口令',
+  backupLeaveOutSecrets: '不包含',
+  backupRestoreTitle: '恢复备份',
+  backupRestoreMessage: '将备份合并到当前数据中，还是用备份替换所有当前数据？',
+  backupRestoreMerge: '合并',
+  backupRestoreReplace: '替换',
+  backupRestoredSuccess: '备份已恢复（{count} 处冲突，已保留本地值）',
+  backupSecretsNotRestored: '备份中的机密信息未恢复，请输入导出时使用的口令。',
   baiduAppId: '百度 App ID',
   baiduAppIdDesc: '百度翻译 App ID',
   baiduAppIdPlaceholder: '输入您的 App ID',
@@ -231,6 +254,27 @@ const zh: TranslationMessages = {
   testConnection: '测试连接',
   connectionError: '网络错误。请重试。',
   fillRequiredFields: '请填写 IMAP 服务器、用户名和密码。',
+  emailIdle: '即时推送（IMAP IDLE）',
+  emailIdleDesc: '保持连接，新的 Newsletter 会立即出现',
+  emailSplitByList: '每个 Newsletter 一个订阅源',
+  emailSplitByListDesc:
+    '将带有 List-Id 邮件头的邮件按 Newsletter 分到单独的订阅源。删除某个 Newsletter 的订阅源后，在关闭此选项前会丢弃它的邮件',
+  emailFilters: '过滤规则',
+  emailFiltersDesc: '仅导入符合任一包含规则且不符合任何排除规则的邮件',
+  emailFilterAdd: '添加规则',
+  emailFilterInclude: '包含',
+  emailFilterExclude: '排除',
+  emailFilterSender: '发件人',
+  emailFilterSubject: '主题',
+  emailFilterContains: '包含',
+  emailFilterExact: '等于',
+  emailFilterRegex: '匹配正则',
+  emailProcessedAction: '已处理的邮件',
+  emailProcessedActionDesc: '对已导入的邮件在邮件服务器上执行的操作',
+  emailProcessedKeep: '保持不变',
+  emailProcessedMarkRead: '标记为已读',
+  emailProcessedMove: '移动到文件夹',
+  emailMoveFolderPlaceholder: '文件夹名称',
   dark: '暗色',
   darkMode: '暗色模式',
   darkModeDesc: '在亮色和暗色主题之间切换',
@@ -297,6 +341,9 @@ const zh: TranslationMessages = {
   enableSummaryDesc: '自动生成文章摘要',
   enableFullTextFetch: '启用全文提取',
   enableFullTextFetchDesc: '当 RSS 仅提供摘要时，允许从原始网站提取完整文章内容',
+  markUpdatedArticlesUnread: '将更新的文章标记为未读',
+  markUpdatedArticlesUnreadDesc: '发布者修改文章标题或内容后，重新将其标记为未读',
+  articleUpdated: '已被发布者更新',
   autoShowAllContent: '自动展示所有内容',
   autoShowAllContentDesc: '作为渲染内容查看时，自动显示所有文章的完整内容（可能会增加加载时间）',
   enableTranslation: '启用翻译',
@@ -413,6 +460,8 @@ const zh: TranslationMessages = {
   freshrssPassword: '密码',
   freshrssPasswordDesc: 'FreshRSS 密码',
   freshrssPasswordPlaceholder: '输入密码',
+  freshrssProvider: '服务器类型',
+  freshrssProviderDesc: '同步服务器软件：FreshRSS、Miniflux、Nextcloud News 或 Tiny Tiny RSS',
   freshrssServerUrl: '服务器地址',
   freshrssServerUrlDesc: 'FreshRSS 服务器端点（不含 /api 路径）',
   freshrssServerUrlPlaceholder: 'https://freshrss.example.com',
@@ -528,6 +577,19 @@ const zh: TranslationMessages = {
   markAsUnread: '标记为未读',
   markedAllAsRead: '所有文章已标记为已读',
   noArticlesToMark: '没有可标记的文章',
+  masterPassphrase: '主口令',
+  masterPassphraseDesc:
+    '使用口令而非本机派生的密钥加密 API 密钥和密码。每次启动后需要输入口令，也可通过 $MRRSS_MASTER_PASSPHRASE 设置。',
+  masterPassphraseEnabled: '机密信息已使用主口令加密',
+  masterPassphraseOff: '机密信息使用本机密钥加密',
+  masterPassphraseCurrent: '当前口令',
+  masterPassphraseNew: '新口令',
+  masterPassphraseSet: '设置口令',
+  masterPassphraseChange: '修改口令',
+  masterPassphraseRemove: '移除口令',
+  masterPassphraseRemoveMessage: '机密信息将重新使用本机密钥加密，是否继续？',
+  masterPassphraseUpdated: '已重新加密 {count} 项机密信息',
+  masterPassphraseUnreadable: '{count} 项机密信息无法解密，需要重新输入',
   maxArticleAge: '最大文章保留天数',
   maxArticleAgeDesc: '删除超过此天数的旧文章（收藏文章除外）',
   maxCacheSize: '最大缓存大小',
@@ -701,6 +763,10 @@ const zh: TranslationMessages = {
   scriptDocumentation: '查看文档',
   scriptHelp: '脚本应输出有效的 RSS/Atom XML。支持：Python、Shell、PowerShell、Node.js、Ruby。',
   scriptsFolderOpened: '脚本文件夹已打开',
+  secretsLocked: '机密信息已锁定',
+  secretsLockedDesc: '输入主口令以使用 API 密钥和密码',
+  secretsUnlock: '解锁',
+  secretsUnlocked: '机密信息已解锁',
   search: '搜索...',
   searchFeeds: '搜索订阅源...',
   searchingFriendLinks: '正在搜索友链',
@@ -732,6 +798,11 @@ const zh: TranslationMessages = {
   showArticlePreviewImagesDesc: '在文章列表中显示预览图片',
   showHiddenArticles: '显示隐藏文章',
   showHiddenArticlesDesc: '显示在所有文章列表中被隐藏的文章',
+  collapseDuplicateArticles: '折叠重复报道',
+  collapseDuplicateArticlesDesc: '多个订阅源报道的同一新闻只显示一次',
+  markDuplicatesRead: '将重复报道标记为已读',
+  markDuplicatesReadDesc: '阅读一篇报道时，同时将其他订阅源中的相同报道标记为已读',
+  duplicateSources: '另有 {count} 个来源',
   showOriginal: '原文',
   showRendered: '渲染',
   showTranslations: '显示翻译',
@@ -904,6 +975,71 @@ const zh: TranslationMessages = {
   aiSummaries: 'AI 摘要',
   articlesFavorited: '收藏文章',
   articlesReadLater: '加入稍后阅读',
+  wrongPassphrase: '口令错误',
+  smtpServer: '邮件服务器',
+  smtpServerDesc: '用于发送邮件摘要的 SMTP 服务器',
+  smtpHost: 'SMTP 主机',
+  smtpHostDesc: '邮件服务器的主机名和端口',
+  smtpSecurity: '安全',
+  smtpSecurityDesc: 'STARTTLS 会升级连接，选择后服务器必须支持',
+  smtpSecurityNone: '无',
+  smtpUsername: '用户名',
+  smtpUsernameDesc: '服务器不需要认证时留空',
+  smtpPassword: '密码',
+  smtpFrom: '发件人',
+  smtpFromDesc: '发送摘要所用的地址',
+  emailDigests: '邮件摘要',
+  emailDigestsDesc:
+    '按计划通过邮件发送符合筛选条件的文章。同一摘要不会重复发送同一篇文章。',
+  digestAdd: '添加摘要',
+  digestSaved: '摘要已保存',
+  digestRecipients: '收件人',
+  digestSchedule: '计划',
+  digestDaily: '每天',
+  digestWeekly: '每周',
+  digestScheduleDaily: '每天 {hour}',
+  digestScheduleWeekly: '每{day} {hour}',
+  digestArticles: '文章',
+  digestConditions: '{count} 个条件',
+  digestMaxArticles: '每封邮件最多文章数',
+  digestAISummary: '包含 AI 摘要',
+  digestSendNow: '立即发送',
+  digestSent: '已发送 {count} 篇文章',
+  digestFailed: '失败',
+  digestNothingToSend: '没有新文章可发送',
+  digestHistory: '发送历史',
+  digestNoHistory: '尚未发送',
+  digestDeleteConfirmTitle: '删除摘要',
+  digestDeleteConfirmMessage: '删除摘要“{name}”及其发送历史？',
+  notifications: '通知',
+  notificationsDesc:
+    '抓取到新文章时推送到 Webhook、聊天室和推送服务。发送失败的消息会重试几次，之后列在这里。',
+  notificationAdd: '添加通知目标',
+  notificationTargetSaved: '通知目标已保存',
+  notificationType: '类型',
+  notificationWebhook: 'Webhook',
+  notificationURL: 'URL',
+  notificationToken: '访问令牌',
+  notificationAppToken: '应用令牌',
+  notificationTokenSaved: '已保存，留空则保持不变',
+  notificationRoom: '房间 ID',
+  notificationTemplate: 'JSON 模板',
+  notificationTemplateDesc:
+    '生成请求体的 Go 模板，可用 .Target、.Title、.Count 和 .Articles。用 json 给值加引号。留空则发送所有字段。',
+  notificationAllArticles: '所有新文章',
+  notificationBatchWindow: '合并等待时间（秒）',
+  notificationBatchWindowDesc: '有新文章后等待这么久，与随后的文章一起发送',
+  notificationMinInterval: '最小间隔（秒）',
+  notificationMinIntervalDesc: '两条消息之间的最短时间',
+  notificationMaxArticles: '每条消息最多文章数',
+  notificationTest: '发送测试',
+  notificationTestSent: '测试通知已发送',
+  notificationFailed: '发送失败的通知',
+  notificationArticleCount: '{count} 篇文章',
+  notificationRetry: '重试',
+  notificationRetryQueued: '通知已加入发送队列',
+  notificationDeleteConfirmTitle: '删除通知目标',
+  notificationDeleteConfirmMessage: '删除通知目标“{name}”及其发送失败的消息？',
 };
 
 export default zh;
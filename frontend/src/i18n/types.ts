@@ -269,6 +269,8 @@ export interface TranslationMessages {
   freshrssPassword: string;
   freshrssPasswordDesc: string;
   freshrssPasswordPlaceholder: string;
+  freshrssProvider: string;
+  freshrssProviderDesc: string;
   freshrssServerUrl: string;
   freshrssServerUrlDesc: string;
   freshrssServerUrlPlaceholder: string;
@@ -5,6 +5,10 @@ import i18n, { locale } from './i18n';
 import './style.css';
 import App from './App.vue';
 import { useAppStore } from './stores/app';
+import { installServerAuth } from './utils/serverAuth';
+
+// Add CSRF headers and the login redirect before the first API request
+installServerAuth();
 
 const app = createApp(App);
 const pinia = createPinia();
@@ -19,6 +19,9 @@ export interface Article {
   is_read_later: boolean;
   summary?: string; // Cached AI-generated summary
   freshrss_item_id?: string; // FreshRSS/Google Reader item ID
+  updated_at?: string; // When the publisher last changed the title or content
+  cluster_id?: number; // Cluster of duplicate stories across feeds
+  duplicate_count?: number; // Number of other articles in the cluster
 }
 
 export interface Feed {
@@ -62,6 +65,11 @@ export interface Feed {
   // FreshRSS integration
   is_freshrss_source?: boolean; // Whether this feed is from FreshRSS sync
   freshrss_stream_id?: string; // FreshRSS stream ID (e.g., "feed/http://...")
+  // Refresh health
+  consecutive_failures?: number; // Failed refreshes since the last success
+  next_eligible_at?: string; // Scheduled refreshes skip the feed until this time
+  failing_since?: string; // First failure since the last success
+  is_dead?: boolean; // Failing for longer than the dead feed threshold
   // Statistics
   latest_article_time?: string; // Latest article publish time
   articles_per_month?: number; // Average articles per month (calculated from last 90 days)
@@ -14,32 +14,49 @@ export interface SettingsData {
   ai_translation_prompt: string;
   ai_usage_limit: string;
   ai_usage_tokens: string;
+  auto_backup_enabled: boolean;
+  auto_backup_interval_hours: number;
+  auto_backup_keep: number;
   auto_cleanup_enabled: boolean;
   auto_show_all_content: boolean;
   auto_update: boolean;
   baidu_app_id: string;
   baidu_secret_key: string;
   close_to_tray: boolean;
+  collapse_duplicate_articles: boolean;
   custom_css_file: string;
+  dead_feed_days: number;
   deepl_api_key: string;
   deepl_endpoint: string;
   default_view_mode: string;
+  fever_enabled: boolean;
+  fever_password: string;
+  fever_username: string;
   freshrss_api_password: string;
   freshrss_auto_sync_interval: number;
   freshrss_enabled: boolean;
   freshrss_last_sync_time: string;
+  freshrss_provider: string;
   freshrss_server_url: string;
   freshrss_sync_on_startup: boolean;
   freshrss_username: string;
   full_text_fetch_enabled: boolean;
   google_translate_endpoint: string;
+  greader_enabled: boolean;
+  greader_password: string;
+  greader_username: string;
+  host_request_interval_ms: number;
   hover_mark_as_read: boolean;
   image_gallery_enabled: boolean;
   language: string;
+  last_auto_backup: string;
   last_global_refresh: string;
   last_network_test: string;
+  mark_duplicates_read: boolean;
+  mark_updated_articles_unread: boolean;
   max_article_age_days: number;
   max_cache_size_mb: number;
+  max_concurrent_per_host: number;
   max_concurrent_refreshes: string;
   media_cache_enabled: boolean;
   media_cache_max_age_days: number;
@@ -63,10 +80,17 @@ export interface SettingsData {
   rsshub_enabled: boolean;
   rsshub_endpoint: string;
   rules: string;
+  rules_webhook_url: string;
   shortcuts: string;
   shortcuts_enabled: boolean;
   show_article_preview_images: boolean;
   show_hidden_articles: boolean;
+  smtp_from: string;
+  smtp_host: string;
+  smtp_password: string;
+  smtp_port: number;
+  smtp_security: string;
+  smtp_username: string;
   startup_on_boot: boolean;
   summary_enabled: boolean;
   summary_length: string;
@@ -77,6 +101,7 @@ export interface SettingsData {
   translation_enabled: boolean;
   translation_provider: string;
   update_interval: number;
+  websub_callback_url: string;
   window_height: string;
   window_maximized: string;
   window_width: string;
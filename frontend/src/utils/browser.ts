@@ -1,7 +1,8 @@
 /**
  * Opens a URL in the user's default web browser using Wails v3 Browser API.
  * This function calls the backend /api/browser/open endpoint which uses
- * app.Browser.OpenURL() to open URLs securely.
+ * app.Browser.OpenURL() to open URLs securely. The server build has no desktop
+ * browser and refuses the request, so the URL is opened in a new tab instead.
  *
  * @param url - The URL to open (must be http or https)
  * @returns Promise that resolves when URL is successfully opened
@@ -22,16 +23,15 @@ export async function openInBrowser(url: string): Promise<void> {
       body: JSON.stringify({ url }),
     });
 
+    if (response.status === 403) {
+      window.open(url, '_blank', 'noopener');
+      return;
+    }
+
     if (!response.ok) {
       const errorText = await response.text();
       throw new Error(`Failed to open URL: ${errorText}`);
     }
-
-    // Check for redirect instruction (server mode)
-    const data = await response.json();
-    if (data.redirect) {
-      window.open(data.redirect, '_blank');
-    }
   } catch (error) {
     console.error('Error opening URL in browser:', error);
     // Show user-friendly error message
@@ -0,0 +1,66 @@
+/**
+ * Session handling for the server build.
+ *
+ * The server keeps the login session in an HttpOnly cookie and requires unsafe requests
+ * to repeat the CSRF token from the mrrss_csrf cookie in the X-CSRF-Token header.
+ * Requests without a valid session are answered with 401, which sends the user to the
+ * login page. In the desktop app neither the cookie nor 401 responses exist, so this is a
+ * no-op there.
+ */
+
+const CSRF_COOKIE = 'mrrss_csrf';
+const CSRF_HEADER = 'X-CSRF-Token';
+const LOGIN_PATH = '/login';
+const SAFE_METHODS = new Set(['GET', 'HEAD', 'OPTIONS']);
+
+function readCookie(name: string): string | null {
+  for (const part of document.cookie.split(';')) {
+    const [key, ...value] = part.trim().split('=');
+    if (key === name) {
+      return decodeURIComponent(value.join('='));
+    }
+  }
+  return null;
+}
+
+function isApiRequest(url: string): boolean {
+  try {
+    const parsed = new URL(url, window.location.origin);
+    return parsed.origin === window.location.origin && parsed.pathname.startsWith('/api/');
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * Wraps window.fetch to add the CSRF header to unsafe API requests and to redirect to the
+ * login page when the session is missing or expired.
+ */
+export function installServerAuth(): void {
+  const originalFetch = window.fetch.bind(window);
+
+  window.fetch = async (input: RequestInfo | URL, init?: RequestInit): Promise<Response> => {
+    const url = typeof input === 'string' ? input : input instanceof URL ? input.href : input.url;
+    if (!isApiRequest(url)) {
+      return originalFetch(input, init);
+    }
+
+    const method = (
+      init?.method || (input instanceof Request ? input.method : 'GET')
+    ).toUpperCase();
+    const csrfToken = readCookie(CSRF_COOKIE);
+    if (csrfToken && !SAFE_METHODS.has(method)) {
+      const headers = new Headers(
+        init?.headers || (input instanceof Request ? input.headers : undefined)
+      );
+      headers.set(CSRF_HEADER, csrfToken);
+      init = { ...init, headers };
+    }
+
+    const response = await originalFetch(input, init);
+    if (response.status === 401 && window.location.pathname !== LOGIN_PATH) {
+      window.location.assign(LOGIN_PATH);
+    }
+    return response;
+  };
+}
@@ -0,0 +1,173 @@
+// Package auth protects the API of the server build: users with bcrypt-hashed passwords,
+// login sessions kept in a cookie, bearer API tokens and CSRF protection for cookie sessions.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+const (
+	// SessionCookie holds the secret of a login session
+	SessionCookie = "mrrss_session"
+	// CSRFCookie holds the CSRF token of the session, readable by the frontend
+	CSRFCookie = "mrrss_csrf"
+	// CSRFHeader must repeat the CSRF token on unsafe requests of cookie sessions
+	CSRFHeader = "X-CSRF-Token"
+
+	// KindSession is a cookie login session
+	KindSession = "session"
+	// KindAPI is a bearer token for scripts and other clients
+	KindAPI = "api"
+
+	// SessionLifetime is how long a login session stays valid
+	SessionLifetime = 30 * 24 * time.Hour
+
+	// MinPasswordLength is the shortest accepted password
+	MinPasswordLength = 8
+)
+
+// AdminUsername is the name of the admin account created on first start
+const AdminUsername = "admin"
+
+// legacyPasswordHashKey is the setting that held the bcrypt hash of the single server
+// password before there were user accounts
+const legacyPasswordHashKey = "server_password_hash"
+
+// ErrPasswordTooShort is returned for passwords shorter than MinPasswordLength
+var ErrPasswordTooShort = errors.New("password must be at least 8 characters")
+
+// dummyHash is compared against when a login names an unknown user, so that unknown
+// and existing usernames take the same time to reject
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// HashPassword returns the bcrypt hash of a password.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Authenticate returns the user with the given username and password, or nil.
+func Authenticate(db *database.DB, username, password string) *models.User {
+	user, hash, err := db.GetUserByUsername(username)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[Auth] Error looking up user: %v", err)
+		}
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return nil
+	}
+	return user
+}
+
+// CheckPassword reports whether password is the password of a user.
+func CheckPassword(db *database.DB, userID int64, password string) bool {
+	hash, err := db.GetUserPasswordHash(userID)
+	if err != nil {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// SetPassword hashes and stores the password of a user.
+func SetPassword(db *database.DB, userID int64, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return db.SetUserPassword(userID, hash)
+}
+
+// EnsureAdmin makes sure an admin account exists on startup. The first admin owns the
+// main database, so an existing single-user installation becomes their account.
+// An explicitly given password replaces the password of that admin; without one, a new
+// admin keeps the server password of an installation from before user accounts, or gets
+// a random password, which is returned so it can be shown once.
+func EnsureAdmin(db *database.DB, password string) (generated string, err error) {
+	users, err := db.GetUsers()
+	if err != nil {
+		return "", err
+	}
+	for _, user := range users {
+		if user.IsAdmin {
+			if password == "" {
+				return "", nil
+			}
+			return "", SetPassword(db, user.ID, password)
+		}
+	}
+
+	var hash string
+	if password == "" {
+		hash, _ = db.GetSetting(legacyPasswordHashKey)
+	}
+	if hash == "" {
+		if password == "" {
+			generated = randomString(12)
+			password = generated
+		}
+		if hash, err = HashPassword(password); err != nil {
+			return "", err
+		}
+	}
+	admin := &models.User{Username: AdminUsername, IsAdmin: true}
+	if err := db.CreateUser(admin, hash); err != nil {
+		return "", err
+	}
+	// The admin's password replaces the server password
+	return generated, db.DeleteSetting(legacyPasswordHashKey)
+}
+
+// IssueToken creates a session or API token of a user and returns it with its secret.
+// The secret is only available here; the database keeps its hash.
+func IssueToken(db *database.DB, userID int64, kind, name string, lifetime time.Duration) (*models.AuthToken, string, error) {
+	secret := randomString(32)
+	token := &models.AuthToken{UserID: userID, Kind: kind, Name: name}
+	if kind == KindSession {
+		token.CSRFToken = randomString(32)
+	}
+	if lifetime > 0 {
+		expiresAt := time.Now().Add(lifetime).UTC()
+		token.ExpiresAt = &expiresAt
+	}
+	if err := db.CreateAuthToken(token, HashToken(secret)); err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+// HashToken returns the stored form of a token secret. Secrets are random, so a plain
+// SHA-256 is enough.
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString returns n random bytes, base64url-encoded
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("auth: crypto/rand failed: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
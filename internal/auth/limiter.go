@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LoginLimiter locks out clients after repeated failed logins.
+type LoginLimiter struct {
+	mu       sync.Mutex
+	max      int
+	window   time.Duration
+	failures map[string]*loginFailures
+}
+
+type loginFailures struct {
+	count int
+	first time.Time
+}
+
+// NewLoginLimiter allows max failed logins per client within window.
+func NewLoginLimiter(max int, window time.Duration) *LoginLimiter {
+	return &LoginLimiter{max: max, window: window, failures: make(map[string]*loginFailures)}
+}
+
+// Allow reports whether the client of r may attempt a login.
+func (l *LoginLimiter) Allow(r *http.Request) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.failures[clientKey(r)]
+	if !ok {
+		return true
+	}
+	if time.Since(f.first) > l.window {
+		delete(l.failures, clientKey(r))
+		return true
+	}
+	return f.count < l.max
+}
+
+// Fail records a failed login of the client of r.
+func (l *LoginLimiter) Fail(r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := clientKey(r)
+	f, ok := l.failures[key]
+	if !ok || time.Since(f.first) > l.window {
+		f = &loginFailures{first: time.Now()}
+		l.failures[key] = f
+	}
+	f.count++
+}
+
+// Reset forgets the failures of the client of r after a successful login.
+func (l *LoginLimiter) Reset(r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, clientKey(r))
+}
+
+// clientKey identifies a client by its address. Forwarding headers are ignored, since
+// any client can set them.
+func clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// LoginPath is where the login page is served
+const LoginPath = "/login"
+
+//go:embed login.html
+var loginPage []byte
+
+// ServeLoginPage serves the login page. It lives outside the frontend bundle so it
+// works before the app has loaded its settings, which already need a session.
+func ServeLoginPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Write(loginPage)
+}
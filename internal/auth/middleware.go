@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+type contextKey struct{}
+
+// Middleware requires a valid session or API token for every request, except for
+// paths that authenticate on their own (sync APIs) or must stay reachable (login, WebSub).
+type Middleware struct {
+	db     *database.DB
+	public []string
+}
+
+// NewMiddleware creates the middleware. Requests whose path starts with one of the
+// public prefixes are passed through unauthenticated.
+func NewMiddleware(db *database.DB, publicPrefixes ...string) *Middleware {
+	return &Middleware{db: db, public: publicPrefixes}
+}
+
+// Wrap returns next protected by the middleware.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range m.public {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		token, status, message := m.authenticate(r)
+		if token == nil {
+			if status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="MrRSS"`)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": message})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, token)))
+	})
+}
+
+// authenticate finds the token of a request. Bearer tokens take precedence over the
+// session cookie; only cookie sessions need the CSRF header, since browsers never
+// add an Authorization header on their own.
+func (m *Middleware) authenticate(r *http.Request) (*models.AuthToken, int, string) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		secret, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return nil, http.StatusUnauthorized, "unsupported authorization scheme"
+		}
+		token, err := m.lookup(strings.TrimSpace(secret))
+		if err != nil {
+			return nil, http.StatusUnauthorized, "invalid token"
+		}
+		return token, 0, ""
+	}
+
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return nil, http.StatusUnauthorized, "authentication required"
+	}
+	token, err := m.lookup(cookie.Value)
+	if err != nil || token.Kind != KindSession {
+		return nil, http.StatusUnauthorized, "session expired"
+	}
+	if !isSafeMethod(r.Method) {
+		given := r.Header.Get(CSRFHeader)
+		if given == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token.CSRFToken)) != 1 {
+			return nil, http.StatusForbidden, "invalid CSRF token"
+		}
+	}
+	return token, 0, ""
+}
+
+func (m *Middleware) lookup(secret string) (*models.AuthToken, error) {
+	if secret == "" {
+		return nil, sql.ErrNoRows
+	}
+	token, err := m.db.GetAuthTokenByHash(HashToken(secret))
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("[Auth] Error looking up token: %v", err)
+	}
+	return token, err
+}
+
+// TokenFromContext returns the token that authenticated a request, or nil.
+func TokenFromContext(ctx context.Context) *models.AuthToken {
+	token, _ := ctx.Value(contextKey{}).(*models.AuthToken)
+	return token
+}
+
+// WithToken returns ctx carrying token, as the middleware does for authenticated requests.
+func WithToken(ctx context.Context, token *models.AuthToken) context.Context {
+	return context.WithValue(ctx, contextKey{}, token)
+}
+
+// SetSessionCookies stores a new session in the browser: the secret in an HttpOnly
+// cookie and the CSRF token in a cookie the frontend reads and echoes in CSRFHeader.
+func SetSessionCookies(w http.ResponseWriter, r *http.Request, token *models.AuthToken, secret string) {
+	secure := isHTTPS(r)
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    secret,
+		Path:     "/",
+		Expires:  *token.ExpiresAt,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookie,
+		Value:    token.CSRFToken,
+		Path:     "/",
+		Expires:  *token.ExpiresAt,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// ClearSessionCookies removes the session cookies from the browser.
+func ClearSessionCookies(w http.ResponseWriter, r *http.Request) {
+	for _, name := range []string{SessionCookie, CSRFCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1, Secure: isHTTPS(r)})
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// isHTTPS reports whether the client connected over HTTPS, directly or through a proxy
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
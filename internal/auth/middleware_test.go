@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+)
+
+func setupDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestEnsureAdmin(t *testing.T) {
+	db := setupDB(t)
+
+	generated, err := EnsureAdmin(db, "")
+	if err != nil || generated == "" {
+		t.Fatalf("EnsureAdmin() = %q, %v; want a generated password", generated, err)
+	}
+	admin := Authenticate(db, AdminUsername, generated)
+	if admin == nil || !admin.IsAdmin || admin.DBFile != "" {
+		t.Fatalf("admin = %+v, want an admin owning the main database", admin)
+	}
+	if Authenticate(db, AdminUsername, "wrong password") != nil || Authenticate(db, "nobody", generated) != nil {
+		t.Error("authenticated with wrong credentials")
+	}
+
+	// An existing admin is kept
+	if again, err := EnsureAdmin(db, ""); err != nil || again != "" || Authenticate(db, "ADMIN", generated) == nil {
+		t.Errorf("EnsureAdmin() replaced the admin: %q, %v", again, err)
+	}
+
+	// An explicit password replaces the admin's password
+	if _, err := EnsureAdmin(db, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if !CheckPassword(db, admin.ID, "correct horse") || CheckPassword(db, admin.ID, generated) {
+		t.Error("explicit password not applied")
+	}
+
+	if _, err := EnsureAdmin(db, "short"); err != ErrPasswordTooShort {
+		t.Errorf("short password: err = %v", err)
+	}
+	if users, _ := db.GetUsers(); len(users) != 1 {
+		t.Errorf("users = %+v, want only the admin", users)
+	}
+}
+
+func TestEnsureAdmin_LegacyPassword(t *testing.T) {
+	db := setupDB(t)
+
+	// The server password of an installation from before user accounts becomes the admin's
+	hash, _ := HashPassword("old server password")
+	db.SetSetting(legacyPasswordHashKey, hash)
+	if generated, err := EnsureAdmin(db, ""); err != nil || generated != "" {
+		t.Fatalf("EnsureAdmin() = %q, %v; want the server password to be kept", generated, err)
+	}
+	if Authenticate(db, AdminUsername, "old server password") == nil {
+		t.Error("admin can't log in with the server password")
+	}
+	if _, err := db.GetSetting(legacyPasswordHashKey); err != sql.ErrNoRows {
+		t.Errorf("server password setting left behind: err = %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	db := setupDB(t)
+	session, sessionSecret, err := IssueToken(db, 1, KindSession, "browser", SessionLifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, apiSecret, err := IssueToken(db, 1, KindAPI, "script", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, expiredSecret, err := IssueToken(db, 1, KindAPI, "old", time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	var seen string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := TokenFromContext(r.Context()); token != nil {
+			seen = token.Name
+		}
+	})
+	handler := NewMiddleware(db, "/api/auth/login", "/api/websub/").Wrap(next)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		bearer   string
+		cookie   string
+		csrf     string
+		wantCode int
+		wantSeen string
+	}{
+		{"public path", "POST", "/api/websub/1", "", "", "", http.StatusOK, ""},
+		{"no credentials", "GET", "/api/feeds", "", "", "", http.StatusUnauthorized, ""},
+		{"api token", "POST", "/api/feeds/add", apiSecret, "", "", http.StatusOK, "script"},
+		{"session as bearer", "POST", "/api/feeds/add", sessionSecret, "", "", http.StatusOK, "browser"},
+		{"unknown token", "GET", "/api/feeds", "nope", "", "", http.StatusUnauthorized, ""},
+		{"expired token", "GET", "/api/feeds", expiredSecret, "", "", http.StatusUnauthorized, ""},
+		{"session cookie read", "GET", "/api/feeds", "", sessionSecret, "", http.StatusOK, "browser"},
+		{"session cookie write without csrf", "POST", "/api/feeds/add", "", sessionSecret, "", http.StatusForbidden, ""},
+		{"session cookie write with wrong csrf", "POST", "/api/feeds/add", "", sessionSecret, "bad", http.StatusForbidden, ""},
+		{"session cookie write with csrf", "POST", "/api/feeds/add", "", sessionSecret, session.CSRFToken, http.StatusOK, "browser"},
+		{"api token as cookie", "GET", "/api/feeds", "", apiSecret, "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = ""
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.cookie})
+			}
+			if tt.csrf != "" {
+				req.Header.Set(CSRFHeader, tt.csrf)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d (%s)", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if seen != tt.wantSeen {
+				t.Errorf("token in context = %q, want %q", seen, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func TestLoginLimiter(t *testing.T) {
+	limiter := NewLoginLimiter(2, time.Hour)
+	req := httptest.NewRequest("POST", "/api/auth/login", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	other := httptest.NewRequest("POST", "/api/auth/login", nil)
+	other.RemoteAddr = "192.0.2.2:1234"
+
+	limiter.Fail(req)
+	if !limiter.Allow(req) {
+		t.Fatal("locked out after one failure")
+	}
+	limiter.Fail(req)
+	if limiter.Allow(req) {
+		t.Error("not locked out after two failures")
+	}
+	if !limiter.Allow(other) {
+		t.Error("other client locked out")
+	}
+
+	limiter.Reset(req)
+	if !limiter.Allow(req) {
+		t.Error("still locked out after reset")
+	}
+}
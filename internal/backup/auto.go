@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"MrRSS/internal/database"
+)
+
+const autoBackupPrefix = "mrrss-backup-"
+
+// AutoBackupDir returns the directory automatic backups of a database are written to.
+// Each database file gets its own directory, so the users of a server keep their
+// backups apart.
+func AutoBackupDir(db *database.DB) (string, error) {
+	file, err := db.DatabaseFile()
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return filepath.Join(filepath.Dir(file), "backups", name), nil
+}
+
+// WriteAutoBackup writes a backup without secrets to dir and removes the oldest
+// automatic backups beyond keep. It returns the path of the new backup.
+func WriteAutoBackup(db *database.DB, dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, autoBackupPrefix+time.Now().Format("20060102-150405")+".zip")
+
+	// Write to a temporary file so a failed backup never replaces a good one
+	tmp, err := os.CreateTemp(dir, ".tmp-"+autoBackupPrefix)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := Export(db, tmp, ExportOptions{}); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, pruneAutoBackups(dir, keep)
+}
+
+// pruneAutoBackups removes all but the newest keep automatic backups in dir
+func pruneAutoBackups(dir string, keep int) error {
+	if keep < 1 {
+		keep = 1
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), autoBackupPrefix) && strings.HasSuffix(entry.Name(), ".zip") {
+			backups = append(backups, entry.Name())
+		}
+	}
+	// The timestamp in the name sorts backups from oldest to newest
+	slices.Sort(backups)
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
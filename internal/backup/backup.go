@@ -0,0 +1,370 @@
+// Package backup writes and restores backup archives of the complete MrRSS state:
+// feeds, articles with their read state, summaries and translations, rules, chat
+// sessions, settings and statistics.
+//
+// An archive is a zip file with a manifest.json, one NDJSON file per table under
+// tables/ and optionally mrrss.db, a SQLite snapshot taken with VACUUM INTO. Restores
+// read the NDJSON files, so archives can be restored into newer versions of the
+// schema; the snapshot is a plain copy of the database for manual recovery.
+//
+// Secrets are only included encrypted with a passphrase, whose key check value is kept
+// in the manifest, so they can be restored on any machine that knows the passphrase.
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	"MrRSS/internal/version"
+)
+
+const (
+	// FormatName identifies MrRSS backup archives
+	FormatName = "mrrss-backup"
+	// FormatVersion is the version of the archive layout written by Export. Version 1
+	// archives held secrets in plain text.
+	FormatVersion = 2
+
+	manifestFile = "manifest.json"
+	snapshotFile = "mrrss.db"
+	tablesDir    = "tables/"
+
+	// encryptedColumnsKey lists the secret columns of a row, which are encrypted with the
+	// backup passphrase and are encrypted again for the restoring database
+	encryptedColumnsKey = "_encrypted"
+)
+
+// secretColumns are columns holding credentials that are stored in plain text
+var secretColumns = map[string][]string{
+	"feeds": {"email_password"},
+}
+
+var (
+	// ErrInvalidArchive is returned for files that are not MrRSS backups
+	ErrInvalidArchive = errors.New("not a MrRSS backup archive")
+	// ErrPassphraseRequired is returned when secrets are exported without a passphrase
+	// and no master passphrase is unlocked
+	ErrPassphraseRequired = errors.New("a passphrase is required to include secrets")
+)
+
+// Manifest describes a backup archive
+type Manifest struct {
+	Format          string         `json:"format"`
+	Version         int            `json:"version"`
+	AppVersion      string         `json:"app_version"`
+	CreatedAt       time.Time      `json:"created_at"`
+	Tables          map[string]int `json:"tables"` // Rows per table
+	IncludesSecrets bool           `json:"includes_secrets"`
+	SecretsKeyCheck string         `json:"secrets_key_check,omitempty"` // Of the passphrase the secrets are encrypted with
+	HasSnapshot     bool           `json:"has_snapshot"`
+}
+
+// ExportOptions selects the optional parts of a backup
+type ExportOptions struct {
+	// IncludeSecrets adds API keys, passwords and feed credentials, encrypted with
+	// Passphrase. Without it they are left out.
+	IncludeSecrets bool
+	// Passphrase encrypts the secrets; if empty, the master passphrase of the database
+	// is used
+	Passphrase string
+	// IncludeSnapshot adds a SQLite snapshot of the database
+	IncludeSnapshot bool
+}
+
+// ImportOptions controls how a backup is restored
+type ImportOptions struct {
+	Mode database.RestoreMode
+	// Passphrase decrypts the secrets of the backup; if empty, the master passphrase
+	// of the database is tried and the secrets are left out if it doesn't match. A
+	// wrong passphrase fails the restore with crypto.ErrWrongPassphrase before anything
+	// is changed.
+	Passphrase string
+}
+
+// ImportResult describes a restored backup
+type ImportResult struct {
+	Manifest        *Manifest               `json:"manifest"`
+	Report          *database.RestoreReport `json:"report"`
+	SecretsRestored bool                    `json:"secrets_restored"`
+}
+
+// ExportKey returns the key secrets are exported with and its key check value
+func ExportKey(db *database.DB, passphrase string) (*crypto.PassphraseKey, string, error) {
+	if passphrase != "" {
+		return crypto.NewPassphraseKey(passphrase)
+	}
+	key, check, err := db.MasterPassphraseKey()
+	if err != nil {
+		return nil, "", ErrPassphraseRequired
+	}
+	return key, check, nil
+}
+
+// Export writes a backup archive of the database to w
+func Export(db *database.DB, w io.Writer, opts ExportOptions) (*Manifest, error) {
+	var key *crypto.PassphraseKey
+	var keyCheck string
+	if opts.IncludeSecrets {
+		var err error
+		if key, keyCheck, err = ExportKey(db, opts.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &Manifest{
+		Format:          FormatName,
+		Version:         FormatVersion,
+		AppVersion:      version.Version,
+		CreatedAt:       time.Now().UTC(),
+		Tables:          map[string]int{},
+		IncludesSecrets: opts.IncludeSecrets,
+		SecretsKeyCheck: keyCheck,
+	}
+
+	zw := zip.NewWriter(w)
+	for _, table := range database.BackupTables {
+		fw, err := zw.Create(tablesDir + table + ".ndjson")
+		if err != nil {
+			return nil, err
+		}
+		bw := bufio.NewWriter(fw)
+		enc := json.NewEncoder(bw)
+		count := 0
+		err = db.ExportBackupRows(table, func(row database.BackupRow) error {
+			if !exportSecrets(db, table, row, key) {
+				return nil
+			}
+			count++
+			return enc.Encode(row)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", table, err)
+		}
+		if err := bw.Flush(); err != nil {
+			return nil, err
+		}
+		manifest.Tables[table] = count
+	}
+
+	if opts.IncludeSnapshot {
+		if err := writeSnapshot(db, zw); err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		manifest.HasSnapshot = true
+	}
+
+	fw, err := zw.Create(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, zw.Close()
+}
+
+// exportSecrets prepares the secrets of a row for a backup: they are decrypted and
+// encrypted with the backup key, or left out without one. It returns false if the row
+// should be left out.
+func exportSecrets(db *database.DB, table string, row database.BackupRow, key *crypto.PassphraseKey) bool {
+	var encrypted []string
+	for column, value := range row {
+		s, ok := value.(string)
+		if !ok || s == "" {
+			continue
+		}
+		isEncrypted := crypto.IsEncrypted(s)
+		if !isEncrypted && !slices.Contains(secretColumns[table], column) {
+			continue
+		}
+		if key == nil {
+			if table == "settings" {
+				// Leave the setting out so restores keep the local secret
+				return false
+			}
+			row[column] = ""
+			continue
+		}
+		plain, err := db.DecryptSecret(s)
+		if err == nil {
+			row[column], err = key.Encrypt(plain)
+		}
+		if err != nil {
+			log.Printf("[Backup] Leaving out a secret of %s that can't be decrypted: %v", table, err)
+			if table == "settings" {
+				return false
+			}
+			row[column] = ""
+			continue
+		}
+		encrypted = append(encrypted, column)
+	}
+	if len(encrypted) > 0 {
+		row[encryptedColumnsKey] = encrypted
+	}
+	return true
+}
+
+// writeSnapshot adds a VACUUM INTO copy of the database to the archive
+func writeSnapshot(db *database.DB, zw *zip.Writer) error {
+	dir, err := os.MkdirTemp("", "mrrss-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, snapshotFile)
+	if err := db.VacuumInto(path); err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fw, err := zw.Create(snapshotFile)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
+
+// ReadManifest returns the manifest of a backup archive
+func ReadManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(manifestFile)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil || manifest.Format != FormatName {
+		return nil, ErrInvalidArchive
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("backup format version %d is newer than the supported version %d", manifest.Version, FormatVersion)
+	}
+	return &manifest, nil
+}
+
+// Import restores a backup archive into the database
+func Import(db *database.DB, r io.ReaderAt, size int64, opts ImportOptions) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	manifest, err := ReadManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+	key, restoreSecrets, err := importKey(db, manifest, opts.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := db.RestoreBackup(opts.Mode, func(table string, fn func(database.BackupRow) error) error {
+		f, err := zr.Open(tablesDir + table + ".ndjson")
+		if errors.Is(err, os.ErrNotExist) {
+			// Tables added after the backup was written
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		dec := json.NewDecoder(f)
+		dec.UseNumber()
+		for {
+			var row database.BackupRow
+			if err := dec.Decode(&row); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			keep, err := importSecrets(db, table, row, key, restoreSecrets)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ImportResult{Manifest: manifest, Report: report, SecretsRestored: manifest.IncludesSecrets && restoreSecrets}, nil
+}
+
+// importKey returns the key the secrets of a backup are encrypted with, and whether
+// they can be restored
+func importKey(db *database.DB, manifest *Manifest, passphrase string) (*crypto.PassphraseKey, bool, error) {
+	if !manifest.IncludesSecrets {
+		return nil, false, nil
+	}
+	if manifest.SecretsKeyCheck == "" {
+		// Version 1 archives hold secrets in plain text
+		return nil, true, nil
+	}
+	if passphrase != "" {
+		key, err := crypto.OpenPassphraseKey(manifest.SecretsKeyCheck, passphrase)
+		return key, err == nil, err
+	}
+	if key, check, err := db.MasterPassphraseKey(); err == nil && check == manifest.SecretsKeyCheck {
+		return key, true, nil
+	}
+	return nil, false, nil
+}
+
+// importSecrets encrypts the secrets of a row for this database, or leaves them out if
+// they can't be restored. It returns false if the row should be left out.
+func importSecrets(db *database.DB, table string, row database.BackupRow, key *crypto.PassphraseKey, restore bool) (bool, error) {
+	columns, _ := row[encryptedColumnsKey].([]interface{})
+	delete(row, encryptedColumnsKey)
+	for _, c := range columns {
+		column, _ := c.(string)
+		value, ok := row[column].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if !restore {
+			if table == "settings" {
+				return false, nil
+			}
+			row[column] = ""
+			continue
+		}
+		plain := value
+		if key != nil {
+			var err error
+			if plain, err = key.Decrypt(value); err != nil {
+				return false, err
+			}
+		}
+		encrypted, err := db.EncryptSecret(plain)
+		if err != nil {
+			return false, err
+		}
+		row[column] = encrypted
+	}
+	return true, nil
+}
@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func saveArticle(t *testing.T, db *database.DB, feedID int64, title string, published time.Time) int64 {
+	t.Helper()
+	article := &models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + title, PublishedAt: published, HasValidPublishedTime: true}
+	if err := db.SaveArticles(context.Background(), []*models.Article{article}); err != nil {
+		t.Fatal(err)
+	}
+	id, err := db.GetArticleIDByUniqueID(title, feedID, published, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func export(t *testing.T, db *database.DB, opts ExportOptions) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := Export(db, &buf, opts); err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func restore(t *testing.T, db *database.DB, archive *bytes.Reader, opts ImportOptions) *ImportResult {
+	t.Helper()
+	result, err := Import(db, archive, archive.Size(), opts)
+	if err != nil {
+		t.Fatalf("Import(%s) error: %v", opts.Mode, err)
+	}
+	return result
+}
+
+func TestExportImport(t *testing.T) {
+	published := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	src := newTestDB(t)
+	src.SetSetting("theme", "dark")
+	if err := src.SetEncryptedSetting("ai_api_key", "sk-secret"); err != nil {
+		t.Fatal(err)
+	}
+	// A feed that only exists in the backup, and one that also exists locally
+	newsID, _ := src.AddFeed(&models.Feed{Title: "News", URL: "https://news.example/feed", Category: "World"})
+	mailID, _ := src.AddFeed(&models.Feed{Title: "Mail", URL: "https://mail.example/feed"})
+	src.Exec(`UPDATE feeds SET email_password = 'hunter2' WHERE id = ?`, mailID)
+	read := saveArticle(t, src, newsID, "Read story", published)
+	src.MarkArticleRead(read, true)
+	src.SetArticleFavorite(read, true)
+	src.SetArticleContent(read, "<p>Cached</p>")
+	tagID, _ := src.CreateTag("Keep", "#ff0000")
+	src.SetArticlesTag(tagID, []int64{read}, true)
+	shared := saveArticle(t, src, mailID, "Shared story", published)
+	src.MarkArticleRead(shared, true)
+	session, _ := src.CreateChatSession(read, "Questions")
+	src.CreateChatMessage(session, "user", "Why?", "")
+	src.CreateRule(models.Rule{Name: "Hide ads", Enabled: true, Actions: []string{"hide"},
+		Conditions: []models.RuleCondition{{Field: "article_title", Operator: "contains", Value: "Sponsored"}}})
+	src.IncrementStat("article_read")
+
+	archive := export(t, src, ExportOptions{IncludeSnapshot: true})
+
+	// Secrets are left out unless requested, the snapshot is included
+	zr, err := zip.NewReader(archive, archive.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ReadManifest(zr)
+	if err != nil || manifest.IncludesSecrets || !manifest.HasSnapshot || manifest.Tables["articles"] != 2 {
+		t.Fatalf("manifest = %+v, %v", manifest, err)
+	}
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".ndjson") {
+			continue
+		}
+		rc, _ := f.Open()
+		var buf bytes.Buffer
+		buf.ReadFrom(rc)
+		rc.Close()
+		if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "ai_api_key") {
+			t.Errorf("%s contains a secret", f.Name)
+		}
+	}
+
+	// Merging into a database that has the mail feed under another title
+	dst := newTestDB(t)
+	dst.SetSetting("theme", "light")
+	localMail, _ := dst.AddFeed(&models.Feed{Title: "My mail", URL: "https://mail.example/feed"})
+	localShared := saveArticle(t, dst, localMail, "Shared story", published)
+
+	report := restore(t, dst, archive, ImportOptions{Mode: database.RestoreMerge}).Report
+	if got := report.Tables["feeds"]; got.Inserted != 1 || got.Merged != 1 {
+		t.Errorf("feeds report = %+v, want 1 inserted and 1 merged", got)
+	}
+	if got := report.Tables["articles"]; got.Inserted != 1 || got.Merged != 1 {
+		t.Errorf("articles report = %+v, want 1 inserted and 1 merged", got)
+	}
+	if report.ConflictCount != 2 {
+		t.Errorf("conflicts = %+v, want the theme and the feed title", report.Conflicts)
+	}
+	if theme, _ := dst.GetSetting("theme"); theme != "light" {
+		t.Errorf("theme = %q, the local value should be kept", theme)
+	}
+	if article, _ := dst.GetArticleByID(localShared); article == nil || !article.IsRead {
+		t.Error("read state was not merged into the local article")
+	}
+
+	articles, _ := dst.GetArticles("all", 0, "", true, 50, 0)
+	if len(articles) != 2 {
+		t.Fatalf("%d articles after merging, want 2", len(articles))
+	}
+	var restored *models.Article
+	for i := range articles {
+		if articles[i].Title == "Read story" {
+			restored = &articles[i]
+		}
+	}
+	if restored == nil || !restored.IsRead || !restored.IsFavorite {
+		t.Fatalf("restored article = %+v", restored)
+	}
+	if tags, _ := dst.GetArticleTags(restored.ID); len(tags) != 1 || tags[0] != "Keep" {
+		t.Errorf("restored tags = %v", tags)
+	}
+	sessions, _ := dst.GetChatSessionsByArticle(restored.ID)
+	if len(sessions) != 1 {
+		t.Fatalf("restored %d chat sessions, want 1", len(sessions))
+	}
+	if messages, _ := dst.GetChatMessages(sessions[0].ID); len(messages) != 1 {
+		t.Errorf("restored %d chat messages, want 1", len(messages))
+	}
+	if rules, _ := dst.GetRules(); len(rules) != 1 || len(rules[0].Conditions) != 1 {
+		t.Errorf("restored rules = %+v", rules)
+	}
+	var fingerprinted int
+	dst.QueryRow(`SELECT COUNT(*) FROM article_fingerprints`).Scan(&fingerprinted)
+	if fingerprinted != 2 {
+		t.Errorf("%d articles fingerprinted after merging, want both", fingerprinted)
+	}
+
+	// Merging the same backup again adds nothing
+	archive.Seek(0, 0)
+	report = restore(t, dst, archive, ImportOptions{Mode: database.RestoreMerge}).Report
+	for table, got := range report.Tables {
+		if got.Inserted != 0 {
+			t.Errorf("second merge inserted %d rows into %s", got.Inserted, table)
+		}
+	}
+
+	// Secrets are only exported under a passphrase
+	if _, err := Export(src, io.Discard, ExportOptions{IncludeSecrets: true}); err != ErrPassphraseRequired {
+		t.Errorf("Export without a passphrase error = %v, want ErrPassphraseRequired", err)
+	}
+	withSecrets := export(t, src, ExportOptions{IncludeSecrets: true, Passphrase: "backup passphrase"})
+	if _, err := Import(dst, withSecrets, withSecrets.Size(), ImportOptions{Mode: database.RestoreReplace, Passphrase: "wrong"}); !errors.Is(err, crypto.ErrWrongPassphrase) {
+		t.Errorf("Import with a wrong passphrase error = %v", err)
+	}
+	if feed, _ := dst.GetFeedByID(localMail); feed == nil || feed.Title != "My mail" {
+		t.Errorf("feed = %+v, a restore with a wrong passphrase should change nothing", feed)
+	}
+	if result := restore(t, dst, withSecrets, ImportOptions{Mode: database.RestoreMerge}); result.SecretsRestored {
+		t.Error("secrets were restored without the passphrase")
+	}
+	if key, _ := dst.GetEncryptedSetting("ai_api_key"); key != "" {
+		t.Errorf("ai_api_key = %q without the passphrase", key)
+	}
+
+	// Replacing restores the backup exactly, with secrets given the passphrase
+	result := restore(t, dst, withSecrets, ImportOptions{Mode: database.RestoreReplace, Passphrase: "backup passphrase"})
+	if !result.SecretsRestored {
+		t.Error("secrets were not restored")
+	}
+	feeds, _ := dst.GetFeeds()
+	if len(feeds) != 2 {
+		t.Fatalf("%d feeds after replacing, want 2", len(feeds))
+	}
+	for _, feed := range feeds {
+		if feed.URL == "https://mail.example/feed" && (feed.Title != "Mail" || feed.ID != mailID) {
+			t.Errorf("replaced feed = %+v", feed)
+		}
+	}
+	if key, err := dst.GetEncryptedSetting("ai_api_key"); err != nil || key != "sk-secret" {
+		t.Errorf("ai_api_key = %q, %v", key, err)
+	}
+	// The IMAP password was stored in plain text and is encrypted now
+	var password string
+	dst.QueryRow(`SELECT email_password FROM feeds WHERE id = ?`, mailID).Scan(&password)
+	if plain, err := dst.DecryptSecret(password); password == "hunter2" || plain != "hunter2" {
+		t.Errorf("email_password = %q, decrypted %q, %v", password, plain, err)
+	}
+}
+
+func TestImport_InvalidArchive(t *testing.T) {
+	db := newTestDB(t)
+	data := []byte("not a zip")
+	if _, err := Import(db, bytes.NewReader(data), int64(len(data)), ImportOptions{Mode: database.RestoreMerge}); err != ErrInvalidArchive {
+		t.Errorf("Import error = %v, want ErrInvalidArchive", err)
+	}
+}
+
+func TestWriteAutoBackup(t *testing.T) {
+	db := newTestDB(t)
+	dir := t.TempDir()
+	// Older backups, and a file that isn't one
+	for _, name := range []string{"mrrss-backup-20200101-000000.zip", "mrrss-backup-20200102-000000.zip", "notes.txt"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0o600)
+	}
+
+	path, err := WriteAutoBackup(db, dir, 2)
+	if err != nil {
+		t.Fatalf("WriteAutoBackup error: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{"mrrss-backup-20200102-000000.zip", filepath.Base(path), "notes.txt"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("backup directory = %v, want %v", names, want)
+	}
+}
@@ -18,83 +18,108 @@ var defaultsJSON []byte
 
 // Defaults holds all default settings values
 type Defaults struct {
-	AIAPIKey                 string `json:"ai_api_key"`
-	AIChatEnabled            bool   `json:"ai_chat_enabled"`
-	AICustomHeaders          string `json:"ai_custom_headers"`
-	AIEndpoint               string `json:"ai_endpoint"`
-	AIModel                  string `json:"ai_model"`
-	AISummaryPrompt          string `json:"ai_summary_prompt"`
-	AITranslationPrompt      string `json:"ai_translation_prompt"`
-	AIUsageLimit             string `json:"ai_usage_limit"`
-	AIUsageTokens            string `json:"ai_usage_tokens"`
-	AutoCleanupEnabled       bool   `json:"auto_cleanup_enabled"`
-	AutoShowAllContent       bool   `json:"auto_show_all_content"`
-	AutoUpdate               bool   `json:"auto_update"`
-	BaiduAppId               string `json:"baidu_app_id"`
-	BaiduSecretKey           string `json:"baidu_secret_key"`
-	CloseToTray              bool   `json:"close_to_tray"`
-	CustomCssFile            string `json:"custom_css_file"`
-	DeeplAPIKey              string `json:"deepl_api_key"`
-	DeeplEndpoint            string `json:"deepl_endpoint"`
-	DefaultViewMode          string `json:"default_view_mode"`
-	FreshRSSAPIPassword      string `json:"freshrss_api_password"`
-	FreshRSSAutoSyncInterval int    `json:"freshrss_auto_sync_interval"`
-	FreshRSSEnabled          bool   `json:"freshrss_enabled"`
-	FreshRSSLastSyncTime     string `json:"freshrss_last_sync_time"`
-	FreshRSSServerUrl        string `json:"freshrss_server_url"`
-	FreshRSSSyncOnStartup    bool   `json:"freshrss_sync_on_startup"`
-	FreshRSSUsername         string `json:"freshrss_username"`
-	FullTextFetchEnabled     bool   `json:"full_text_fetch_enabled"`
-	GoogleTranslateEndpoint  string `json:"google_translate_endpoint"`
-	HoverMarkAsRead          bool   `json:"hover_mark_as_read"`
-	ImageGalleryEnabled      bool   `json:"image_gallery_enabled"`
-	Language                 string `json:"language"`
-	LastGlobalRefresh        string `json:"last_global_refresh"`
-	LastNetworkTest          string `json:"last_network_test"`
-	MaxArticleAgeDays        int    `json:"max_article_age_days"`
-	MaxCacheSizeMb           int    `json:"max_cache_size_mb"`
-	MaxConcurrentRefreshes   string `json:"max_concurrent_refreshes"`
-	MediaCacheEnabled        bool   `json:"media_cache_enabled"`
-	MediaCacheMaxAgeDays     int    `json:"media_cache_max_age_days"`
-	MediaCacheMaxSizeMb      int    `json:"media_cache_max_size_mb"`
-	MediaProxyFallback       bool   `json:"media_proxy_fallback"`
-	NetworkBandwidthMbps     string `json:"network_bandwidth_mbps"`
-	NetworkLatencyMs         string `json:"network_latency_ms"`
-	NetworkSpeed             string `json:"network_speed"`
-	ObsidianEnabled          bool   `json:"obsidian_enabled"`
-	ObsidianVault            string `json:"obsidian_vault"`
-	ObsidianVaultPath        string `json:"obsidian_vault_path"`
-	ProxyEnabled             bool   `json:"proxy_enabled"`
-	ProxyHost                string `json:"proxy_host"`
-	ProxyPassword            string `json:"proxy_password"`
-	ProxyPort                string `json:"proxy_port"`
-	ProxyType                string `json:"proxy_type"`
-	ProxyUsername            string `json:"proxy_username"`
-	RefreshMode              string `json:"refresh_mode"`
-	RetryTimeoutSeconds      int    `json:"retry_timeout_seconds"`
-	RsshubAPIKey             string `json:"rsshub_api_key"`
-	RsshubEnabled            bool   `json:"rsshub_enabled"`
-	RsshubEndpoint           string `json:"rsshub_endpoint"`
-	Rules                    string `json:"rules"`
-	Shortcuts                string `json:"shortcuts"`
-	ShortcutsEnabled         bool   `json:"shortcuts_enabled"`
-	ShowArticlePreviewImages bool   `json:"show_article_preview_images"`
-	ShowHiddenArticles       bool   `json:"show_hidden_articles"`
-	StartupOnBoot            bool   `json:"startup_on_boot"`
-	SummaryEnabled           bool   `json:"summary_enabled"`
-	SummaryLength            string `json:"summary_length"`
-	SummaryProvider          string `json:"summary_provider"`
-	SummaryTriggerMode       string `json:"summary_trigger_mode"`
-	TargetLanguage           string `json:"target_language"`
-	Theme                    string `json:"theme"`
-	TranslationEnabled       bool   `json:"translation_enabled"`
-	TranslationProvider      string `json:"translation_provider"`
-	UpdateInterval           int    `json:"update_interval"`
-	WindowHeight             string `json:"window_height"`
-	WindowMaximized          string `json:"window_maximized"`
-	WindowWidth              string `json:"window_width"`
-	WindowX                  string `json:"window_x"`
-	WindowY                  string `json:"window_y"`
+	AIAPIKey                  string `json:"ai_api_key"`
+	AIChatEnabled             bool   `json:"ai_chat_enabled"`
+	AICustomHeaders           string `json:"ai_custom_headers"`
+	AIEndpoint                string `json:"ai_endpoint"`
+	AIModel                   string `json:"ai_model"`
+	AISummaryPrompt           string `json:"ai_summary_prompt"`
+	AITranslationPrompt       string `json:"ai_translation_prompt"`
+	AIUsageLimit              string `json:"ai_usage_limit"`
+	AIUsageTokens             string `json:"ai_usage_tokens"`
+	AutoBackupEnabled         bool   `json:"auto_backup_enabled"`
+	AutoBackupIntervalHours   int    `json:"auto_backup_interval_hours"`
+	AutoBackupKeep            int    `json:"auto_backup_keep"`
+	AutoCleanupEnabled        bool   `json:"auto_cleanup_enabled"`
+	AutoShowAllContent        bool   `json:"auto_show_all_content"`
+	AutoUpdate                bool   `json:"auto_update"`
+	BaiduAppId                string `json:"baidu_app_id"`
+	BaiduSecretKey            string `json:"baidu_secret_key"`
+	CloseToTray               bool   `json:"close_to_tray"`
+	CollapseDuplicateArticles bool   `json:"collapse_duplicate_articles"`
+	CustomCssFile             string `json:"custom_css_file"`
+	DeadFeedDays              int    `json:"dead_feed_days"`
+	DeeplAPIKey               string `json:"deepl_api_key"`
+	DeeplEndpoint             string `json:"deepl_endpoint"`
+	DefaultViewMode           string `json:"default_view_mode"`
+	FeverEnabled              bool   `json:"fever_enabled"`
+	FeverPassword             string `json:"fever_password"`
+	FeverUsername             string `json:"fever_username"`
+	FreshRSSAPIPassword       string `json:"freshrss_api_password"`
+	FreshRSSAutoSyncInterval  int    `json:"freshrss_auto_sync_interval"`
+	FreshRSSEnabled           bool   `json:"freshrss_enabled"`
+	FreshRSSLastSyncTime      string `json:"freshrss_last_sync_time"`
+	FreshRSSProvider          string `json:"freshrss_provider"`
+	FreshRSSServerUrl         string `json:"freshrss_server_url"`
+	FreshRSSSyncOnStartup     bool   `json:"freshrss_sync_on_startup"`
+	FreshRSSUsername          string `json:"freshrss_username"`
+	FullTextFetchEnabled      bool   `json:"full_text_fetch_enabled"`
+	GoogleTranslateEndpoint   string `json:"google_translate_endpoint"`
+	GreaderEnabled            bool   `json:"greader_enabled"`
+	GreaderPassword           string `json:"greader_password"`
+	GreaderUsername           string `json:"greader_username"`
+	HostRequestIntervalMs     int    `json:"host_request_interval_ms"`
+	HoverMarkAsRead           bool   `json:"hover_mark_as_read"`
+	ImageGalleryEnabled       bool   `json:"image_gallery_enabled"`
+	Language                  string `json:"language"`
+	LastAutoBackup            string `json:"last_auto_backup"`
+	LastGlobalRefresh         string `json:"last_global_refresh"`
+	LastNetworkTest           string `json:"last_network_test"`
+	MarkDuplicatesRead        bool   `json:"mark_duplicates_read"`
+	MarkUpdatedArticlesUnread bool   `json:"mark_updated_articles_unread"`
+	MaxArticleAgeDays         int    `json:"max_article_age_days"`
+	MaxCacheSizeMb            int    `json:"max_cache_size_mb"`
+	MaxConcurrentPerHost      int    `json:"max_concurrent_per_host"`
+	MaxConcurrentRefreshes    string `json:"max_concurrent_refreshes"`
+	MediaCacheEnabled         bool   `json:"media_cache_enabled"`
+	MediaCacheMaxAgeDays      int    `json:"media_cache_max_age_days"`
+	MediaCacheMaxSizeMb       int    `json:"media_cache_max_size_mb"`
+	MediaProxyFallback        bool   `json:"media_proxy_fallback"`
+	NetworkBandwidthMbps      string `json:"network_bandwidth_mbps"`
+	NetworkLatencyMs          string `json:"network_latency_ms"`
+	NetworkSpeed              string `json:"network_speed"`
+	ObsidianEnabled           bool   `json:"obsidian_enabled"`
+	ObsidianVault             string `json:"obsidian_vault"`
+	ObsidianVaultPath         string `json:"obsidian_vault_path"`
+	ProxyEnabled              bool   `json:"proxy_enabled"`
+	ProxyHost                 string `json:"proxy_host"`
+	ProxyPassword             string `json:"proxy_password"`
+	ProxyPort                 string `json:"proxy_port"`
+	ProxyType                 string `json:"proxy_type"`
+	ProxyUsername             string `json:"proxy_username"`
+	RefreshMode               string `json:"refresh_mode"`
+	RetryTimeoutSeconds       int    `json:"retry_timeout_seconds"`
+	RsshubAPIKey              string `json:"rsshub_api_key"`
+	RsshubEnabled             bool   `json:"rsshub_enabled"`
+	RsshubEndpoint            string `json:"rsshub_endpoint"`
+	Rules                     string `json:"rules"`
+	RulesWebhookUrl           string `json:"rules_webhook_url"`
+	Shortcuts                 string `json:"shortcuts"`
+	ShortcutsEnabled          bool   `json:"shortcuts_enabled"`
+	ShowArticlePreviewImages  bool   `json:"show_article_preview_images"`
+	ShowHiddenArticles        bool   `json:"show_hidden_articles"`
+	SmtpFrom                  string `json:"smtp_from"`
+	SmtpHost                  string `json:"smtp_host"`
+	SmtpPassword              string `json:"smtp_password"`
+	SmtpPort                  int    `json:"smtp_port"`
+	SmtpSecurity              string `json:"smtp_security"`
+	SmtpUsername              string `json:"smtp_username"`
+	StartupOnBoot             bool   `json:"startup_on_boot"`
+	SummaryEnabled            bool   `json:"summary_enabled"`
+	SummaryLength             string `json:"summary_length"`
+	SummaryProvider           string `json:"summary_provider"`
+	SummaryTriggerMode        string `json:"summary_trigger_mode"`
+	TargetLanguage            string `json:"target_language"`
+	Theme                     string `json:"theme"`
+	TranslationEnabled        bool   `json:"translation_enabled"`
+	TranslationProvider       string `json:"translation_provider"`
+	UpdateInterval            int    `json:"update_interval"`
+	WebsubCallbackUrl         string `json:"websub_callback_url"`
+	WindowHeight              string `json:"window_height"`
+	WindowMaximized           string `json:"window_maximized"`
+	WindowWidth               string `json:"window_width"`
+	WindowX                   string `json:"window_x"`
+	WindowY                   string `json:"window_y"`
 }
 
 var defaults Defaults
@@ -131,6 +156,12 @@ func GetString(key string) string {
 		return defaults.AIUsageLimit
 	case "ai_usage_tokens":
 		return defaults.AIUsageTokens
+	case "auto_backup_enabled":
+		return strconv.FormatBool(defaults.AutoBackupEnabled)
+	case "auto_backup_interval_hours":
+		return strconv.Itoa(defaults.AutoBackupIntervalHours)
+	case "auto_backup_keep":
+		return strconv.Itoa(defaults.AutoBackupKeep)
 	case "auto_cleanup_enabled":
 		return strconv.FormatBool(defaults.AutoCleanupEnabled)
 	case "auto_show_all_content":
@@ -143,14 +174,24 @@ func GetString(key string) string {
 		return defaults.BaiduSecretKey
 	case "close_to_tray":
 		return strconv.FormatBool(defaults.CloseToTray)
+	case "collapse_duplicate_articles":
+		return strconv.FormatBool(defaults.CollapseDuplicateArticles)
 	case "custom_css_file":
 		return defaults.CustomCssFile
+	case "dead_feed_days":
+		return strconv.Itoa(defaults.DeadFeedDays)
 	case "deepl_api_key":
 		return defaults.DeeplAPIKey
 	case "deepl_endpoint":
 		return defaults.DeeplEndpoint
 	case "default_view_mode":
 		return defaults.DefaultViewMode
+	case "fever_enabled":
+		return strconv.FormatBool(defaults.FeverEnabled)
+	case "fever_password":
+		return defaults.FeverPassword
+	case "fever_username":
+		return defaults.FeverUsername
 	case "freshrss_api_password":
 		return defaults.FreshRSSAPIPassword
 	case "freshrss_auto_sync_interval":
@@ -159,6 +200,8 @@ func GetString(key string) string {
 		return strconv.FormatBool(defaults.FreshRSSEnabled)
 	case "freshrss_last_sync_time":
 		return defaults.FreshRSSLastSyncTime
+	case "freshrss_provider":
+		return defaults.FreshRSSProvider
 	case "freshrss_server_url":
 		return defaults.FreshRSSServerUrl
 	case "freshrss_sync_on_startup":
@@ -169,20 +212,36 @@ func GetString(key string) string {
 		return strconv.FormatBool(defaults.FullTextFetchEnabled)
 	case "google_translate_endpoint":
 		return defaults.GoogleTranslateEndpoint
+	case "greader_enabled":
+		return strconv.FormatBool(defaults.GreaderEnabled)
+	case "greader_password":
+		return defaults.GreaderPassword
+	case "greader_username":
+		return defaults.GreaderUsername
+	case "host_request_interval_ms":
+		return strconv.Itoa(defaults.HostRequestIntervalMs)
 	case "hover_mark_as_read":
 		return strconv.FormatBool(defaults.HoverMarkAsRead)
 	case "image_gallery_enabled":
 		return strconv.FormatBool(defaults.ImageGalleryEnabled)
 	case "language":
 		return defaults.Language
+	case "last_auto_backup":
+		return defaults.LastAutoBackup
 	case "last_global_refresh":
 		return defaults.LastGlobalRefresh
 	case "last_network_test":
 		return defaults.LastNetworkTest
+	case "mark_duplicates_read":
+		return strconv.FormatBool(defaults.MarkDuplicatesRead)
+	case "mark_updated_articles_unread":
+		return strconv.FormatBool(defaults.MarkUpdatedArticlesUnread)
 	case "max_article_age_days":
 		return strconv.Itoa(defaults.MaxArticleAgeDays)
 	case "max_cache_size_mb":
 		return strconv.Itoa(defaults.MaxCacheSizeMb)
+	case "max_concurrent_per_host":
+		return strconv.Itoa(defaults.MaxConcurrentPerHost)
 	case "max_concurrent_refreshes":
 		return defaults.MaxConcurrentRefreshes
 	case "media_cache_enabled":
@@ -229,6 +288,8 @@ func GetString(key string) string {
 		return defaults.RsshubEndpoint
 	case "rules":
 		return defaults.Rules
+	case "rules_webhook_url":
+		return defaults.RulesWebhookUrl
 	case "shortcuts":
 		return defaults.Shortcuts
 	case "shortcuts_enabled":
@@ -237,6 +298,18 @@ func GetString(key string) string {
 		return strconv.FormatBool(defaults.ShowArticlePreviewImages)
 	case "show_hidden_articles":
 		return strconv.FormatBool(defaults.ShowHiddenArticles)
+	case "smtp_from":
+		return defaults.SmtpFrom
+	case "smtp_host":
+		return defaults.SmtpHost
+	case "smtp_password":
+		return defaults.SmtpPassword
+	case "smtp_port":
+		return strconv.Itoa(defaults.SmtpPort)
+	case "smtp_security":
+		return defaults.SmtpSecurity
+	case "smtp_username":
+		return defaults.SmtpUsername
 	case "startup_on_boot":
 		return strconv.FormatBool(defaults.StartupOnBoot)
 	case "summary_enabled":
@@ -257,6 +330,8 @@ func GetString(key string) string {
 		return defaults.TranslationProvider
 	case "update_interval":
 		return strconv.Itoa(defaults.UpdateInterval)
+	case "websub_callback_url":
+		return defaults.WebsubCallbackUrl
 	case "window_height":
 		return defaults.WindowHeight
 	case "window_maximized":
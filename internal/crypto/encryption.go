@@ -132,6 +132,11 @@ func Decrypt(ciphertextBase64 string) (string, error) {
 		return "", nil
 	}
 
+	// Values encrypted with a master passphrase need its key
+	if IsPassphraseEncrypted(ciphertextBase64) {
+		return "", ErrPassphraseRequired
+	}
+
 	// Check and strip version marker
 	if !strings.HasPrefix(ciphertextBase64, versionMarker) {
 		return "", fmt.Errorf("missing or invalid version marker")
@@ -193,11 +198,12 @@ func Decrypt(ciphertextBase64 string) (string, error) {
 
 // IsEncrypted checks if a value appears to be encrypted by checking for the version marker.
 // This definitively identifies encrypted values and prevents false positives.
+// Values encrypted with a master passphrase count as encrypted too.
 func IsEncrypted(value string) bool {
 	if value == "" {
 		return false
 	}
 
 	// Check for version marker - this is definitive, not a heuristic
-	return strings.HasPrefix(value, versionMarker)
+	return strings.HasPrefix(value, versionMarker) || IsPassphraseEncrypted(value)
 }
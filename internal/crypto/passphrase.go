@@ -0,0 +1,155 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// Version marker of values encrypted with a passphrase key
+	passphraseMarker = "MrRSS-p1:"
+	// Version marker of key check values
+	keyCheckMarker = "MrRSS-k1:"
+	// keyCheckPlaintext is encrypted into key check values to verify passphrases
+	keyCheckPlaintext = "MrRSS key check"
+)
+
+var (
+	// ErrPassphraseRequired is returned when a value encrypted with a passphrase key is
+	// decrypted without it
+	ErrPassphraseRequired = errors.New("the master passphrase is required to decrypt this value")
+	// ErrWrongPassphrase is returned when a passphrase doesn't match a key check value
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// PassphraseKey is an encryption key derived from a passphrase instead of the machine ID,
+// so values encrypted with it can be decrypted on any machine.
+//
+// The key is derived once, with a salt kept in its key check value: a known plaintext
+// encrypted with the key, which tells whether a passphrase is the right one. Values are
+// stored as: "MrRSS-p1:" + base64([nonce(12 bytes)][ciphertext+tag])
+type PassphraseKey struct {
+	gcm cipher.AEAD
+}
+
+// NewPassphraseKey derives a key with a new salt from a passphrase. It returns the key
+// and its key check value, which must be stored to open the key again.
+func NewPassphraseKey(passphrase string) (*PassphraseKey, string, error) {
+	if passphrase == "" {
+		return nil, "", errors.New("passphrase must not be empty")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := newPassphraseKey(passphrase, salt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	check, err := key.seal(keyCheckPlaintext)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, keyCheckMarker + base64.StdEncoding.EncodeToString(append(salt, check...)), nil
+}
+
+// OpenPassphraseKey derives the key of a key check value from a passphrase.
+// It returns ErrWrongPassphrase if the passphrase doesn't match.
+func OpenPassphraseKey(keyCheck, passphrase string) (*PassphraseKey, error) {
+	if !strings.HasPrefix(keyCheck, keyCheckMarker) {
+		return nil, ErrInvalidCiphertext
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(keyCheck, keyCheckMarker))
+	if err != nil || len(data) < saltSize {
+		return nil, ErrInvalidCiphertext
+	}
+
+	key, err := newPassphraseKey(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	if plaintext, err := key.open(data[saltSize:]); err != nil || plaintext != keyCheckPlaintext {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+// newPassphraseKey derives a key from a passphrase and salt
+func newPassphraseKey(passphrase string, salt []byte) (*PassphraseKey, error) {
+	key := pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, keySize, sha256.New)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &PassphraseKey{gcm: gcm}, nil
+}
+
+// Encrypt encrypts plaintext with the key
+func (k *PassphraseKey) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	sealed, err := k.seal(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return passphraseMarker + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value that was encrypted with the key
+func (k *PassphraseKey) Decrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !IsPassphraseEncrypted(value) {
+		return "", fmt.Errorf("missing or invalid version marker")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, passphraseMarker))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode base64: %w", err)
+	}
+	return k.open(data)
+}
+
+// seal encrypts plaintext into [nonce][ciphertext+tag]
+func (k *PassphraseKey) seal(plaintext string) ([]byte, error) {
+	nonce := make([]byte, k.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return k.gcm.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// open decrypts [nonce][ciphertext+tag]
+func (k *PassphraseKey) open(data []byte) (string, error) {
+	nonceSize := k.gcm.NonceSize()
+	if len(data) < nonceSize+k.gcm.Overhead() {
+		return "", ErrInvalidCiphertext
+	}
+	plaintext, err := k.gcm.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	return string(plaintext), nil
+}
+
+// IsPassphraseEncrypted reports whether a value was encrypted with a passphrase key
+func IsPassphraseEncrypted(value string) bool {
+	return strings.HasPrefix(value, passphraseMarker)
+}
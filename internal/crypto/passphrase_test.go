@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPassphraseKey(t *testing.T) {
+	key, check, err := NewPassphraseKey("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("NewPassphraseKey() error = %v", err)
+	}
+
+	encrypted, err := key.Encrypt("sk-1234567890")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(encrypted) || !IsPassphraseEncrypted(encrypted) {
+		t.Errorf("%q is not recognized as encrypted", encrypted)
+	}
+
+	// The key can be opened again from its key check value, e.g. on another machine
+	reopened, err := OpenPassphraseKey(check, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("OpenPassphraseKey() error = %v", err)
+	}
+	if decrypted, err := reopened.Decrypt(encrypted); err != nil || decrypted != "sk-1234567890" {
+		t.Errorf("Decrypt() = %q, %v", decrypted, err)
+	}
+
+	if _, err := OpenPassphraseKey(check, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("OpenPassphraseKey() with a wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+
+	// Another key with the same passphrase has another salt
+	other, _, _ := NewPassphraseKey("correct horse battery staple")
+	if _, err := other.Decrypt(encrypted); err == nil {
+		t.Error("a key with another salt decrypted the value")
+	}
+
+	// The machine key can't decrypt it
+	if _, err := Decrypt(encrypted); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Decrypt() error = %v, want ErrPassphraseRequired", err)
+	}
+
+	if _, _, err := NewPassphraseKey(""); err == nil {
+		t.Error("NewPassphraseKey accepted an empty passphrase")
+	}
+}
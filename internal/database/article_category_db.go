@@ -0,0 +1,81 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// articleCategoriesColumn selects the item categories of article a as a single
+// string separated by the ASCII unit separator (see splitArticleCategories)
+const articleCategoriesColumn = `(SELECT GROUP_CONCAT(name, char(31)) FROM article_categories WHERE article_id = a.id)`
+
+// InitArticleCategoriesTable creates the article_categories table if it doesn't exist.
+// It stores the per-item categories reported by the feed (<category>, dc:subject, XPath).
+func InitArticleCategoriesTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS article_categories (
+		article_id INTEGER NOT NULL,
+		name TEXT NOT NULL COLLATE NOCASE,
+		PRIMARY KEY (article_id, name),
+		FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_article_categories_name ON article_categories(name);
+
+	-- Foreign keys are not enforced, so remove categories of deleted articles explicitly
+	CREATE TRIGGER IF NOT EXISTS article_categories_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_categories WHERE article_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// splitArticleCategories splits the value selected by articleCategoriesColumn
+func splitArticleCategories(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, "\x1f")
+}
+
+// GetArticleCategories returns the item categories of an article.
+func (db *DB) GetArticleCategories(articleID int64) ([]string, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT name FROM article_categories WHERE article_id = ? ORDER BY name`, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		categories = append(categories, name)
+	}
+	return categories, rows.Err()
+}
+
+// GetAllArticleCategories returns the distinct item category names across all articles.
+func (db *DB) GetAllArticleCategories() ([]string, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT DISTINCT name FROM article_categories ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		categories = append(categories, name)
+	}
+	return categories, rows.Err()
+}
@@ -0,0 +1,62 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestArticleAuthorAndCategories(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "Tagged", URL: "https://example.com/tagged", PublishedAt: time.Now(), Author: "Jane Doe", Categories: []string{"Go", "Release"}},
+		{FeedID: feedID, Title: "Plain", URL: "https://example.com/plain", PublishedAt: time.Now()},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles error: %v", err)
+	}
+
+	list, err := db.GetArticles("all", feedID, "", false, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 articles, got %d", len(list))
+	}
+
+	var tagged models.Article
+	for _, a := range list {
+		if a.Title == "Tagged" {
+			tagged = a
+		} else if a.Author != "" || len(a.Categories) != 0 {
+			t.Errorf("expected plain article without author and categories, got %q %v", a.Author, a.Categories)
+		}
+	}
+	if tagged.Author != "Jane Doe" || len(tagged.Categories) != 2 {
+		t.Fatalf("expected author and 2 categories, got %q %v", tagged.Author, tagged.Categories)
+	}
+
+	byID, err := db.GetArticleByID(tagged.ID)
+	if err != nil {
+		t.Fatalf("GetArticleByID error: %v", err)
+	}
+	if byID.Author != "Jane Doe" || len(byID.Categories) != 2 {
+		t.Errorf("GetArticleByID returned %q %v", byID.Author, byID.Categories)
+	}
+
+	all, err := db.GetAllArticleCategories()
+	if err != nil || len(all) != 2 || all[0] != "Go" {
+		t.Errorf("GetAllArticleCategories() = %v, %v", all, err)
+	}
+
+	// Categories are removed together with the article
+	if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, tagged.ID); err != nil {
+		t.Fatalf("delete article: %v", err)
+	}
+	if categories, _ := db.GetArticleCategories(tagged.ID); len(categories) != 0 {
+		t.Errorf("expected categories to be deleted, got %v", categories)
+	}
+}
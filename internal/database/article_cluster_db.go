@@ -0,0 +1,263 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"MrRSS/internal/utils"
+)
+
+const (
+	// clusterTimeWindow is how far apart in time two articles can be published and
+	// still be reports of the same story
+	clusterTimeWindow = 72 * time.Hour
+	// maxClusterSimHashDistance is the number of differing SimHash bits up to which two
+	// article texts count as the same story; unrelated texts differ in about 32 bits
+	maxClusterSimHashDistance = 7
+	// simHashBands is the number of 8-bit bands a SimHash is split into for lookups;
+	// SimHashes that differ in fewer bits than there are bands always share a band
+	simHashBands = 8
+)
+
+// articleDuplicateCountColumn counts the other visible articles in the cluster of
+// the article aliased a, whose fingerprint is aliased fp
+const articleDuplicateCountColumn = `(SELECT COUNT(*) FROM article_fingerprints dc JOIN articles da ON da.id = dc.article_id WHERE dc.cluster_id = fp.cluster_id AND dc.article_id != a.id AND da.is_hidden = 0)`
+
+// InitArticleClustersTable creates the tables used to detect duplicate stories across
+// feeds. Every article gets a fingerprint (canonical URL, title key and SimHash of its
+// text) and a cluster ID, the lowest article ID among its duplicates.
+func InitArticleClustersTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS article_fingerprints (
+		article_id INTEGER PRIMARY KEY,
+		cluster_id INTEGER NOT NULL,
+		canonical_url TEXT NOT NULL DEFAULT '',
+		title_key TEXT NOT NULL DEFAULT '',
+		simhash INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS idx_article_fingerprints_cluster ON article_fingerprints(cluster_id);
+	CREATE INDEX IF NOT EXISTS idx_article_fingerprints_url ON article_fingerprints(canonical_url);
+	CREATE INDEX IF NOT EXISTS idx_article_fingerprints_title ON article_fingerprints(title_key);
+
+	-- Bands of the SimHash, so near-duplicates can be looked up by index
+	CREATE TABLE IF NOT EXISTS article_fingerprint_bands (
+		article_id INTEGER NOT NULL,
+		band INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_article_fingerprint_bands_band ON article_fingerprint_bands(band);
+	CREATE INDEX IF NOT EXISTS idx_article_fingerprint_bands_article ON article_fingerprint_bands(article_id);
+
+	-- Foreign keys are not enforced, so remove fingerprints of deleted articles explicitly
+	CREATE TRIGGER IF NOT EXISTS article_fingerprints_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_fingerprints WHERE article_id = old.id;
+		DELETE FROM article_fingerprint_bands WHERE article_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// simHashBandKeys splits a SimHash into its bands, each tagged with its position
+func simHashBandKeys(hash uint64) []interface{} {
+	keys := make([]interface{}, simHashBands)
+	for i := range keys {
+		keys[i] = int64(i)<<8 | int64((hash>>(8*i))&0xFF)
+	}
+	return keys
+}
+
+// AssignArticleCluster fingerprints an article and adds it to the cluster of the
+// articles from other feeds that report the same story: published within a few days
+// and sharing the canonical URL, the title or a near-identical text. Clusters that
+// the article connects are merged. Articles that were fingerprinted before are left
+// alone. It returns the article's cluster ID.
+func (db *DB) AssignArticleCluster(articleID int64, articleURL, title, content string) (int64, error) {
+	db.WaitForReady()
+
+	var clusterID int64
+	err := db.QueryRow(`SELECT cluster_id FROM article_fingerprints WHERE article_id = ?`, articleID).Scan(&clusterID)
+	if err == nil {
+		return clusterID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	var feedID int64
+	var publishedAt time.Time
+	if err := db.QueryRow(`SELECT feed_id, published_at FROM articles WHERE id = ?`, articleID).Scan(&feedID, &publishedAt); err != nil {
+		return 0, err
+	}
+
+	canonicalURL := utils.CanonicalURL(articleURL)
+	titleKey := utils.TitleKey(title)
+	simHash, hasSimHash := utils.SimHash(utils.HTMLToText(content))
+
+	// Look up candidates by index, then check SimHash distances here
+	var matchClauses []string
+	args := []interface{}{feedID, publishedAt.Add(-clusterTimeWindow), publishedAt.Add(clusterTimeWindow)}
+	if canonicalURL != "" {
+		matchClauses = append(matchClauses, "fp.canonical_url = ?")
+		args = append(args, canonicalURL)
+	}
+	if titleKey != "" {
+		matchClauses = append(matchClauses, "fp.title_key = ?")
+		args = append(args, titleKey)
+	}
+	if hasSimHash {
+		matchClauses = append(matchClauses, "fp.article_id IN (SELECT article_id FROM article_fingerprint_bands WHERE band IN (?"+strings.Repeat(", ?", simHashBands-1)+"))")
+		args = append(args, simHashBandKeys(simHash)...)
+	}
+
+	clusterID = articleID
+	var matchedClusters []interface{}
+	if len(matchClauses) > 0 {
+		rows, err := db.Query(`SELECT fp.cluster_id, fp.canonical_url, fp.title_key, fp.simhash
+			FROM article_fingerprints fp
+			JOIN articles a ON a.id = fp.article_id
+			WHERE a.feed_id != ? AND a.published_at BETWEEN ? AND ?
+			AND (`+strings.Join(matchClauses, " OR ")+`)`, args...)
+		if err != nil {
+			return 0, err
+		}
+		seen := map[int64]bool{}
+		for rows.Next() {
+			var candidateCluster int64
+			var candidateURL, candidateTitle string
+			var candidateHash sql.NullInt64
+			if err := rows.Scan(&candidateCluster, &candidateURL, &candidateTitle, &candidateHash); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			matches := (canonicalURL != "" && candidateURL == canonicalURL) ||
+				(titleKey != "" && candidateTitle == titleKey) ||
+				(hasSimHash && candidateHash.Valid && utils.HammingDistance(simHash, uint64(candidateHash.Int64)) <= maxClusterSimHashDistance)
+			if !matches || seen[candidateCluster] {
+				continue
+			}
+			seen[candidateCluster] = true
+			matchedClusters = append(matchedClusters, candidateCluster)
+			if candidateCluster < clusterID {
+				clusterID = candidateCluster
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var storedHash interface{}
+	if hasSimHash {
+		storedHash = int64(simHash)
+	}
+	result, err := tx.Exec(`INSERT OR IGNORE INTO article_fingerprints (article_id, cluster_id, canonical_url, title_key, simhash) VALUES (?, ?, ?, ?, ?)`,
+		articleID, clusterID, canonicalURL, titleKey, storedHash)
+	if err != nil {
+		return 0, err
+	}
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		// Fingerprinted concurrently by another refresh
+		return clusterID, nil
+	}
+	if hasSimHash {
+		for _, band := range simHashBandKeys(simHash) {
+			if _, err := tx.Exec(`INSERT INTO article_fingerprint_bands (article_id, band) VALUES (?, ?)`, articleID, band); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if len(matchedClusters) > 0 {
+		// Relabel the matched clusters, which merges those the article connects
+		if _, err := tx.Exec(`UPDATE article_fingerprints SET cluster_id = ? WHERE cluster_id IN (?`+strings.Repeat(", ?", len(matchedClusters)-1)+`)`,
+			append([]interface{}{clusterID}, matchedClusters...)...); err != nil {
+			return 0, err
+		}
+	}
+	return clusterID, tx.Commit()
+}
+
+// GetUnreadArticleDuplicates returns the IDs of the unread articles in the cluster of
+// an article, without the article itself
+func (db *DB) GetUnreadArticleDuplicates(articleID int64) ([]int64, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT d.article_id
+		FROM article_fingerprints fp
+		JOIN article_fingerprints d ON d.cluster_id = fp.cluster_id AND d.article_id != fp.article_id
+		JOIN articles a ON a.id = d.article_id
+		WHERE fp.article_id = ? AND a.is_read = 0`, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// clusterBatchSize is the number of articles loaded at a time by ClusterUnfingerprintedArticles
+const clusterBatchSize = 500
+
+// ClusterUnfingerprintedArticles fingerprints and clusters the articles that have no
+// fingerprint yet, such as the articles of a restored backup. It returns the number of
+// articles clustered.
+func (db *DB) ClusterUnfingerprintedArticles() (int, error) {
+	db.WaitForReady()
+
+	type pending struct {
+		id                  int64
+		url, title, content string
+	}
+	clustered := 0
+	var lastID int64
+	for {
+		rows, err := db.Query(`SELECT a.id, COALESCE(a.url, ''), COALESCE(a.title, ''), COALESCE(c.content, '')
+			FROM articles a
+			LEFT JOIN article_fingerprints fp ON fp.article_id = a.id
+			LEFT JOIN article_contents c ON c.article_id = a.id
+			WHERE a.id > ? AND fp.article_id IS NULL
+			ORDER BY a.id LIMIT ?`, lastID, clusterBatchSize)
+		if err != nil {
+			return clustered, err
+		}
+		var batch []pending
+		for rows.Next() {
+			var p pending
+			if err := rows.Scan(&p.id, &p.url, &p.title, &p.content); err != nil {
+				rows.Close()
+				return clustered, err
+			}
+			batch = append(batch, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return clustered, err
+		}
+		if len(batch) == 0 {
+			return clustered, nil
+		}
+
+		for _, p := range batch {
+			if _, err := db.AssignArticleCluster(p.id, p.url, p.title, p.content); err != nil {
+				return clustered, err
+			}
+			clustered++
+		}
+		lastID = batch[len(batch)-1].id
+	}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// clusterStory is a news item long enough for a SimHash
+const clusterStory = "<p>The city council voted on Tuesday to approve a new budget that increases funding for public transport, " +
+	"parks and libraries while cutting administrative costs across several departments over the next two years.</p>" +
+	"<p>Mayor Jane Smith said the plan would make the city more livable, but opposition members warned that the spending " +
+	"commitments rely on optimistic revenue forecasts. Bus fares will be frozen until 2027 and three new tram lines are " +
+	"planned for the eastern districts.</p><p>The budget passed by eleven votes to seven after a debate lasting more than five hours.</p>"
+
+func TestAssignArticleCluster(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+
+	wire, _ := db.AddFeed(&models.Feed{Title: "Wire", URL: "https://wire.example/feed"})
+	daily, _ := db.AddFeed(&models.Feed{Title: "Daily", URL: "https://daily.example/feed"})
+	local, _ := db.AddFeed(&models.Feed{Title: "Local", URL: "https://local.example/feed"})
+	published := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	save := func(feedID int64, title, url, content string, at time.Time) int64 {
+		t.Helper()
+		article := &models.Article{FeedID: feedID, Title: title, URL: url, PublishedAt: at, HasValidPublishedTime: true}
+		if err := db.SaveArticles(context.Background(), []*models.Article{article}); err != nil {
+			t.Fatal(err)
+		}
+		id, err := db.GetArticleIDByUniqueID(title, feedID, at, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.AssignArticleCluster(id, url, title, content); err != nil {
+			t.Fatalf("AssignArticleCluster(%q) error: %v", title, err)
+		}
+		return id
+	}
+	clusterOf := func(id int64) int64 {
+		var cluster int64
+		db.QueryRow(`SELECT cluster_id FROM article_fingerprints WHERE article_id = ?`, id).Scan(&cluster)
+		return cluster
+	}
+
+	original := save(wire, "Council approves budget", "https://wire.example/story/1?utm_source=rss", clusterStory, published)
+	// Same link, different headline
+	sameURL := save(daily, "Budget passes after long debate", "http://www.wire.example/story/1/", "", published.Add(time.Hour))
+	// Same wire text under a different link and headline
+	sameText := save(local, "Our city gets a new budget",
+		"https://local.example/news/budget", strings.Replace(clusterStory, "several", "many", 1)+"<p>Read more at Local.</p>", published.Add(2*time.Hour))
+	// Same headline in the same feed, and in another feed weeks later
+	sameFeed := save(wire, "Council approves budget again", "https://wire.example/story/2", "", published.Add(3*time.Hour))
+	later := save(daily, "Council approves budget", "https://daily.example/story/9", "", published.Add(20*24*time.Hour))
+	unrelated := save(daily, "Scientists discover new fish species", "https://daily.example/story/2", "<p>Deep sea fish.</p>", published)
+
+	cluster := clusterOf(original)
+	if cluster != original {
+		t.Errorf("cluster of the first article = %d, want its own ID %d", cluster, original)
+	}
+	for name, id := range map[string]int64{"same URL": sameURL, "same text": sameText} {
+		if got := clusterOf(id); got != cluster {
+			t.Errorf("%s article is in cluster %d, want %d", name, got, cluster)
+		}
+	}
+	for name, id := range map[string]int64{"same feed": sameFeed, "later": later, "unrelated": unrelated} {
+		if got := clusterOf(id); got != id {
+			t.Errorf("%s article joined cluster %d", name, got)
+		}
+	}
+
+	// Fingerprinting again keeps the cluster
+	if got, _ := db.AssignArticleCluster(sameURL, "https://other.example/x", "Other", ""); got != cluster {
+		t.Errorf("reassigned cluster = %d, want %d", got, cluster)
+	}
+
+	articles, err := db.GetArticlesCollapsed("all", 0, "", false, 50, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(articles) != 4 {
+		t.Fatalf("collapsed list has %d articles, want 4", len(articles))
+	}
+	for _, a := range articles {
+		if a.ID == sameURL || a.ID == sameText {
+			t.Errorf("duplicate %d is listed", a.ID)
+		}
+		if a.ID == original && (a.DuplicateCount != 2 || a.ClusterID != cluster) {
+			t.Errorf("representative has cluster %d with %d duplicates, want %d with 2", a.ClusterID, a.DuplicateCount, cluster)
+		}
+	}
+	if all, _ := db.GetArticles("all", 0, "", false, 50, 0); len(all) != 6 {
+		t.Errorf("uncollapsed list has %d articles, want 6", len(all))
+	}
+
+	// Reading the earliest report makes the next one represent the story among unread articles
+	db.MarkArticleRead(original, true)
+	unread, _ := db.GetArticlesCollapsed("unread", 0, "", false, 50, 0)
+	var ids []int64
+	for _, a := range unread {
+		ids = append(ids, a.ID)
+	}
+	if len(ids) != 4 || !slices.Contains(ids, sameURL) || slices.Contains(ids, sameText) {
+		t.Errorf("collapsed unread list = %v, want %d to represent the story", ids, sameURL)
+	}
+
+	duplicates, err := db.GetUnreadArticleDuplicates(sameText)
+	if err != nil || len(duplicates) != 1 || duplicates[0] != sameURL {
+		t.Errorf("GetUnreadArticleDuplicates = %v, %v; want [%d]", duplicates, err, sameURL)
+	}
+
+	// Deleting an article removes its fingerprint
+	db.Exec(`DELETE FROM articles WHERE id = ?`, sameText)
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM article_fingerprint_bands WHERE article_id = ?`, sameText).Scan(&n)
+	if clusterOf(sameText) != 0 || n != 0 {
+		t.Error("fingerprint of a deleted article was kept")
+	}
+}
@@ -28,17 +28,46 @@ func (db *DB) GetArticleContent(articleID int64) (string, bool, error) {
 	return content, true, nil
 }
 
-// SetArticleContent stores or updates content for an article
+// SetArticleContent stores or updates the feed content of an article. Full text
+// extracted from the article's web page is kept.
 func (db *DB) SetArticleContent(articleID int64, content string) error {
 	db.WaitForReady()
 	_, err := db.Exec(
-		`INSERT OR REPLACE INTO article_contents (article_id, content, fetched_at)
-		 VALUES (?, ?, CURRENT_TIMESTAMP)`,
+		`INSERT INTO article_contents (article_id, content, fetched_at)
+		 VALUES (?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(article_id) DO UPDATE SET content = excluded.content, fetched_at = excluded.fetched_at
+		 WHERE COALESCE(full_text, 0) = 0`,
 		articleID, content,
 	)
 	return err
 }
 
+// SetArticleFullText stores the full text extracted from an article's web page as its content
+func (db *DB) SetArticleFullText(articleID int64, content string) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT INTO article_contents (article_id, content, fetched_at, full_text)
+		 VALUES (?, ?, CURRENT_TIMESTAMP, 1)
+		 ON CONFLICT(article_id) DO UPDATE SET content = excluded.content, fetched_at = excluded.fetched_at, full_text = 1`,
+		articleID, content,
+	)
+	return err
+}
+
+// HasArticleFullText reports whether the stored content of an article is its full text
+func (db *DB) HasArticleFullText(articleID int64) (bool, error) {
+	db.WaitForReady()
+	var fullText bool
+	err := db.QueryRow(
+		`SELECT COALESCE(full_text, 0) FROM article_contents WHERE article_id = ?`,
+		articleID,
+	).Scan(&fullText)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return fullText, err
+}
+
 // DeleteArticleContent removes cached content for an article
 func (db *DB) DeleteArticleContent(articleID int64) error {
 	db.WaitForReady()
@@ -86,6 +86,33 @@ func TestArticleContentCache(t *testing.T) {
 		}
 	})
 
+	t.Run("Full text is kept on feed refresh", func(t *testing.T) {
+		articleID := int64(4)
+		fullText := "<p>Full article</p>"
+
+		if err := db.SetArticleContent(articleID, "<p>Teaser</p>"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SetArticleFullText(articleID, fullText); err != nil {
+			t.Fatal(err)
+		}
+		// A feed refresh caches the feed content again
+		if err := db.SetArticleContent(articleID, "<p>Teaser</p>"); err != nil {
+			t.Fatal(err)
+		}
+
+		content, _, err := db.GetArticleContent(articleID)
+		if err != nil || content != fullText {
+			t.Errorf("content = %q, %v, want the full text", content, err)
+		}
+		if has, err := db.HasArticleFullText(articleID); err != nil || !has {
+			t.Errorf("HasArticleFullText = %v, %v, want true", has, err)
+		}
+		if has, _ := db.HasArticleFullText(1); has {
+			t.Error("feed content reported as full text")
+		}
+	})
+
 	t.Run("Delete ArticleContent", func(t *testing.T) {
 		articleID := int64(3)
 		testContent := "<p>Content to delete</p>"
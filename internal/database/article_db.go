@@ -11,20 +11,61 @@ import (
 	"MrRSS/internal/utils"
 )
 
+// deletedArticleRetention is how long deleted articles are remembered, so feeds that
+// still list them don't bring them back
+const deletedArticleRetention = 365 * 24 * time.Hour
+
+// insertArticleQuery inserts an article unless its unique_id (the last argument, again)
+// already exists or belongs to a deleted article
+const insertArticleQuery = `INSERT OR IGNORE INTO articles (feed_id, title, url, image_url, audio_url, video_url, published_at, translated_title, is_read, is_favorite, is_hidden, is_read_later, summary, unique_id, author, freshrss_item_id)
+	SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM deleted_articles WHERE unique_id = ?)`
+
+// InitDeletedArticlesTable creates the deleted_articles table if it doesn't exist.
+// It keeps the unique_id of deleted articles, which the next refresh would insert again.
+func InitDeletedArticlesTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS deleted_articles (
+		unique_id TEXT PRIMARY KEY,
+		deleted_at DATETIME NOT NULL
+	)`)
+	return err
+}
+
 // SaveArticle saves a single article to the database.
 func (db *DB) SaveArticle(article *models.Article) error {
 	db.WaitForReady()
 
 	// Generate unique_id for deduplication
 	uniqueID := utils.GenerateArticleUniqueID(article.Title, article.FeedID, article.PublishedAt, article.HasValidPublishedTime)
-	query := `INSERT OR IGNORE INTO articles (feed_id, title, url, image_url, audio_url, video_url, published_at, translated_title, is_read, is_favorite, is_hidden, is_read_later, summary, unique_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err := db.Exec(query, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID)
-	return err
+	result, err := db.Exec(insertArticleQuery, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author, article.FreshRSSItemID, uniqueID)
+	if err != nil {
+		return err
+	}
+
+	// Store item categories only for newly inserted articles
+	if rows, _ := result.RowsAffected(); rows > 0 && len(article.Categories) > 0 {
+		articleID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, name := range article.Categories {
+			if _, err := db.Exec(`INSERT OR IGNORE INTO article_categories (article_id, name) VALUES (?, ?)`, articleID, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // SaveArticles saves multiple articles in a transaction.
 // Includes progressive cleanup check to prevent database from exceeding size limit during refresh.
 func (db *DB) SaveArticles(ctx context.Context, articles []*models.Article) error {
+	_, err := db.SaveArticlesCounted(ctx, articles)
+	return err
+}
+
+// SaveArticlesCounted is SaveArticles that also returns how many articles were newly inserted.
+// Newly inserted articles get their ID set, the others keep theirs.
+func (db *DB) SaveArticlesCounted(ctx context.Context, articles []*models.Article) (int, error) {
 	db.WaitForReady()
 
 	// Progressive cleanup: check if we need to clean up before saving
@@ -46,81 +87,201 @@ func (db *DB) SaveArticles(ctx context.Context, articles []*models.Article) erro
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO articles (feed_id, title, url, image_url, audio_url, video_url, published_at, translated_title, is_read, is_favorite, is_hidden, is_read_later, summary, unique_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	stmt, err := tx.PrepareContext(ctx, insertArticleQuery)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer stmt.Close()
 
+	categoryStmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO article_categories (article_id, name) VALUES (?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer categoryStmt.Close()
+
+	// unique_id depends on the title, so an article whose title the publisher edited
+	// looks new; it is matched to the article with the same URL instead
+	batchIDs := make(map[string]bool, len(articles))
+	for _, article := range articles {
+		batchIDs[utils.GenerateArticleUniqueID(article.Title, article.FeedID, article.PublishedAt, article.HasValidPublishedTime)] = true
+	}
+
+	inserted := 0
 	for _, article := range articles {
 		// Check context before each insert
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return 0, ctx.Err()
 		default:
 		}
 
 		// Generate unique_id for deduplication
 		uniqueID := utils.GenerateArticleUniqueID(article.Title, article.FeedID, article.PublishedAt, article.HasValidPublishedTime)
-		_, err := stmt.ExecContext(ctx, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID)
+		if renamed, err := renameEditedArticle(ctx, tx, article, uniqueID, batchIDs); err != nil {
+			log.Println("Error matching edited article in batch:", err)
+		} else if renamed {
+			continue
+		}
+		result, err := stmt.ExecContext(ctx, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author, article.FreshRSSItemID, uniqueID)
 		if err != nil {
 			log.Println("Error saving article in batch:", err)
 			// Continue even if one fails
+			continue
+		}
+
+		// Store item categories only for newly inserted articles
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			continue
+		}
+		inserted++
+		articleID, err := result.LastInsertId()
+		if err != nil {
+			continue
+		}
+		article.ID = articleID
+		for _, name := range article.Categories {
+			if _, err := categoryStmt.ExecContext(ctx, articleID, name); err != nil {
+				log.Println("Error saving article category in batch:", err)
+			}
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// renameEditedArticle gives the article a new title and unique_id if it is a known
+// article whose title was edited: its unique_id is new, and exactly one article of the
+// feed has its URL. That article must not be in the batch itself, as feeds linking all
+// items to the same page would otherwise merge them. The original version is kept
+// as a revision; the change itself is recorded by RecordArticleVersion.
+func renameEditedArticle(ctx context.Context, tx *sql.Tx, article *models.Article, uniqueID string, batchIDs map[string]bool) (bool, error) {
+	if article.URL == "" {
+		return false, nil
+	}
+	var exists int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM articles WHERE unique_id = ?`, uniqueID).Scan(&exists)
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, COALESCE(unique_id, '') FROM articles WHERE feed_id = ? AND url = ? LIMIT 2`, article.FeedID, article.URL)
+	if err != nil {
+		return false, err
+	}
+	var ids []int64
+	var previousID string
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id, &previousID); err != nil {
+			rows.Close()
+			return false, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if len(ids) != 1 || batchIDs[previousID] {
+		return false, nil
+	}
+
+	if _, err := ensureOriginalRevision(tx, ids[0]); err != nil {
+		return false, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE articles SET title = ?, unique_id = ? WHERE id = ?`, article.Title, uniqueID, ids[0])
+	return err == nil, err
 }
 
 // GetArticles retrieves articles with filtering, pagination, and sorting.
 func (db *DB) GetArticles(filter string, feedID int64, category string, showHidden bool, limit, offset int) ([]models.Article, error) {
-	db.WaitForReady()
-	baseQuery := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title
-		FROM articles a
-		JOIN feeds f ON a.feed_id = f.id
-	`
+	return db.queryArticles(filter, feedID, category, showHidden, false, limit, offset)
+}
+
+// GetArticlesCollapsed works like GetArticles but lists every cluster of duplicate
+// stories once, by its earliest article in the view. DuplicateCount tells how many
+// other sources the story has.
+func (db *DB) GetArticlesCollapsed(filter string, feedID int64, category string, showHidden bool, limit, offset int) ([]models.Article, error) {
+	return db.queryArticles(filter, feedID, category, showHidden, true, limit, offset)
+}
+
+// articleListClauses returns the WHERE clauses and arguments of an article list view,
+// for the articles aliased a and their feeds aliased f
+func articleListClauses(a, f, filter string, feedID int64, category string, showHidden bool) ([]string, []interface{}) {
 	var args []interface{}
 	whereClauses := []string{}
 
 	// Always filter hidden articles unless showHidden is true
 	if !showHidden {
-		whereClauses = append(whereClauses, "a.is_hidden = 0")
+		whereClauses = append(whereClauses, a+".is_hidden = 0")
 	}
 
 	switch filter {
 	case "unread":
-		whereClauses = append(whereClauses, "a.is_read = 0")
+		whereClauses = append(whereClauses, a+".is_read = 0")
 		// Exclude feeds marked as hide_from_timeline when viewing unread (unless specific feed/category selected)
 		if feedID <= 0 && category == "" {
-			whereClauses = append(whereClauses, "COALESCE(f.hide_from_timeline, 0) = 0")
+			whereClauses = append(whereClauses, "COALESCE("+f+".hide_from_timeline, 0) = 0")
 		}
 	case "favorites":
-		whereClauses = append(whereClauses, "a.is_favorite = 1")
+		whereClauses = append(whereClauses, a+".is_favorite = 1")
 	case "readLater":
-		whereClauses = append(whereClauses, "a.is_read_later = 1")
+		whereClauses = append(whereClauses, a+".is_read_later = 1")
 	case "all":
 		// Exclude feeds marked as hide_from_timeline when viewing all articles (unless specific feed/category selected)
 		if feedID <= 0 && category == "" {
-			whereClauses = append(whereClauses, "COALESCE(f.hide_from_timeline, 0) = 0")
+			whereClauses = append(whereClauses, "COALESCE("+f+".hide_from_timeline, 0) = 0")
 		}
 	}
 
 	if feedID > 0 {
-		whereClauses = append(whereClauses, "a.feed_id = ?")
+		whereClauses = append(whereClauses, a+".feed_id = ?")
 		args = append(args, feedID)
 	}
 
 	if category != "" {
 		// Simple prefix match for category hierarchy
-		whereClauses = append(whereClauses, "(f.category = ? OR f.category LIKE ?)")
+		whereClauses = append(whereClauses, "("+f+".category = ? OR "+f+".category LIKE ?)")
 		args = append(args, category, category+"/%")
 	}
 
+	// "tag:<name>" filters by user tag
+	if tag, ok := strings.CutPrefix(filter, "tag:"); ok {
+		whereClauses = append(whereClauses, "EXISTS (SELECT 1 FROM article_tags at JOIN tags t ON t.id = at.tag_id WHERE at.article_id = "+a+".id AND t.name = ?)")
+		args = append(args, tag)
+	}
+	return whereClauses, args
+}
+
+// queryArticles lists the articles of a view, optionally collapsing duplicate stories
+func (db *DB) queryArticles(filter string, feedID int64, category string, showHidden, collapseDuplicates bool, limit, offset int) ([]models.Article, error) {
+	db.WaitForReady()
+	baseQuery := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, COALESCE(a.author, ''), a.updated_at, ` + articleCategoriesColumn + `, ` + articleTagsColumn + `,
+			COALESCE(fp.cluster_id, 0), ` + articleDuplicateCountColumn + `
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		LEFT JOIN article_fingerprints fp ON fp.article_id = a.id
+	`
+	whereClauses, args := articleListClauses("a", "f", filter, feedID, category, showHidden)
+
+	if collapseDuplicates {
+		// Skip articles with an earlier duplicate that is in the same view
+		memberClauses, memberArgs := articleListClauses("b", "bf", filter, feedID, category, showHidden)
+		memberClauses = append(memberClauses, "d.cluster_id = fp.cluster_id", "d.article_id != a.id",
+			"(b.published_at < a.published_at OR (b.published_at = a.published_at AND b.id < a.id))")
+		whereClauses = append(whereClauses, `NOT EXISTS (SELECT 1 FROM article_fingerprints d
+			JOIN articles b ON b.id = d.article_id
+			JOIN feeds bf ON bf.id = b.feed_id
+			WHERE `+strings.Join(memberClauses, " AND ")+`)`)
+		args = append(args, memberArgs...)
+	}
+
 	query := baseQuery
 	if len(whereClauses) > 0 {
 		query += " WHERE " + whereClauses[0]
@@ -140,9 +301,10 @@ func (db *DB) GetArticles(filter string, feedID int64, category string, showHidd
 	var articles []models.Article
 	for rows.Next() {
 		var a models.Article
-		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID sql.NullString
+		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, categories, tags sql.NullString
 		var publishedAt sql.NullTime
-		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle); err != nil {
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle, &a.Author, &updatedAt, &categories, &tags, &a.ClusterID, &a.DuplicateCount); err != nil {
 			log.Println("Error scanning article:", err)
 			continue
 		}
@@ -156,6 +318,11 @@ func (db *DB) GetArticles(filter string, feedID int64, category string, showHidd
 		}
 		a.TranslatedTitle = translatedTitle.String
 		a.Summary = summary.String
+		a.Categories = splitArticleCategories(categories.String)
+		a.Tags = splitArticleCategories(tags.String)
+		if updatedAt.Valid {
+			a.UpdatedAt = &updatedAt.Time
+		}
 		a.FreshRSSItemID = freshrssItemID.String
 		articles = append(articles, a)
 	}
@@ -167,7 +334,7 @@ func (db *DB) GetArticles(filter string, feedID int64, category string, showHidd
 func (db *DB) GetArticleByID(id int64) (*models.Article, error) {
 	db.WaitForReady()
 	query := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, COALESCE(a.author, ''), a.updated_at, ` + articleCategoriesColumn + `, ` + articleTagsColumn + `
 		FROM articles a
 		JOIN feeds f ON a.feed_id = f.id
 		WHERE a.id = ?
@@ -175,9 +342,10 @@ func (db *DB) GetArticleByID(id int64) (*models.Article, error) {
 	row := db.QueryRow(query, id)
 
 	var a models.Article
-	var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID sql.NullString
+	var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, categories, tags sql.NullString
 	var publishedAt sql.NullTime
-	if err := row.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle); err != nil {
+	var updatedAt sql.NullTime
+	if err := row.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle, &a.Author, &updatedAt, &categories, &tags); err != nil {
 		return nil, err
 	}
 	a.ImageURL = imageURL.String
@@ -190,6 +358,11 @@ func (db *DB) GetArticleByID(id int64) (*models.Article, error) {
 	}
 	a.TranslatedTitle = translatedTitle.String
 	a.Summary = summary.String
+	a.Categories = splitArticleCategories(categories.String)
+	a.Tags = splitArticleCategories(tags.String)
+	if updatedAt.Valid {
+		a.UpdatedAt = &updatedAt.Time
+	}
 	a.FreshRSSItemID = freshrssItemID.String
 	return &a, nil
 }
@@ -210,7 +383,7 @@ func (db *DB) GetArticlesByIDs(ids []int64) ([]models.Article, error) {
 	}
 
 	query := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, COALESCE(a.author, ''), a.updated_at, ` + articleCategoriesColumn + `, ` + articleTagsColumn + `
 		FROM articles a
 		JOIN feeds f ON a.feed_id = f.id
 		WHERE a.id IN (` + strings.Join(placeholders, ",") + `)
@@ -225,10 +398,11 @@ func (db *DB) GetArticlesByIDs(ids []int64) ([]models.Article, error) {
 	articles := []models.Article{}
 	for rows.Next() {
 		var a models.Article
-		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID sql.NullString
+		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, categories, tags sql.NullString
 		var publishedAt sql.NullTime
+		var updatedAt sql.NullTime
 
-		err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle)
+		err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle, &a.Author, &updatedAt, &categories, &tags)
 		if err != nil {
 			return nil, err
 		}
@@ -243,6 +417,11 @@ func (db *DB) GetArticlesByIDs(ids []int64) ([]models.Article, error) {
 		}
 		a.TranslatedTitle = translatedTitle.String
 		a.Summary = summary.String
+		a.Categories = splitArticleCategories(categories.String)
+		a.Tags = splitArticleCategories(tags.String)
+		if updatedAt.Valid {
+			a.UpdatedAt = &updatedAt.Time
+		}
 		a.FreshRSSItemID = freshrssItemID.String
 
 		articles = append(articles, a)
@@ -465,7 +644,7 @@ func (db *DB) ClearReadLater() error {
 func (db *DB) GetImageGalleryArticles(feedID int64, showHidden bool, limit, offset int) ([]models.Article, error) {
 	db.WaitForReady()
 	baseQuery := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, f.title
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, f.title, COALESCE(a.author, ''), a.updated_at, ` + articleCategoriesColumn + `, ` + articleTagsColumn + `
 		FROM articles a
 		JOIN feeds f ON a.feed_id = f.id
 		WHERE COALESCE(f.is_image_mode, 0) = 1
@@ -497,9 +676,10 @@ func (db *DB) GetImageGalleryArticles(feedID int64, showHidden bool, limit, offs
 	articles := make([]models.Article, 0)
 	for rows.Next() {
 		var a models.Article
-		var imageURL, audioURL, videoURL, translatedTitle, summary sql.NullString
+		var imageURL, audioURL, videoURL, translatedTitle, summary, categories, tags sql.NullString
 		var publishedAt sql.NullTime
-		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &a.FeedTitle); err != nil {
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &a.FeedTitle, &a.Author, &updatedAt, &categories, &tags); err != nil {
 			log.Println("Error scanning article:", err)
 			continue
 		}
@@ -513,6 +693,11 @@ func (db *DB) GetImageGalleryArticles(feedID int64, showHidden bool, limit, offs
 		}
 		a.TranslatedTitle = translatedTitle.String
 		a.Summary = summary.String
+		a.Categories = splitArticleCategories(categories.String)
+		a.Tags = splitArticleCategories(tags.String)
+		if updatedAt.Valid {
+			a.UpdatedAt = &updatedAt.Time
+		}
 		articles = append(articles, a)
 	}
 	return articles, nil
@@ -525,6 +710,29 @@ func (db *DB) UpdateArticleSummary(id int64, summary string) error {
 	return err
 }
 
+// DeleteArticle deletes an article and its cached content. The article is remembered,
+// so it is not saved again while its feed still lists it.
+func (db *DB) DeleteArticle(id int64) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO deleted_articles (unique_id, deleted_at)
+		SELECT unique_id, ? FROM articles WHERE id = ? AND COALESCE(unique_id, '') != ''`, time.Now().UTC(), id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM article_contents WHERE article_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM articles WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // GetArticleIDByUniqueID retrieves an article's ID by its unique identifier.
 // This is the preferred method for looking up articles as it uses the title+feed_id+published_date based deduplication.
 // Note: Uses date only (YYYY-MM-DD) rather than full timestamp for better deduplication.
@@ -289,8 +289,9 @@ func (db *DB) ShouldSyncWithFreshRSS() bool {
 	return serverURL != "" && username != "" && password != ""
 }
 
-// GetFreshRSSConfig retrieves FreshRSS configuration
-func (db *DB) GetFreshRSSConfig() (serverURL, username, password string, err error) {
+// GetFreshRSSConfig retrieves the configuration of the sync server
+func (db *DB) GetFreshRSSConfig() (provider, serverURL, username, password string, err error) {
+	provider, _ = db.GetSetting("freshrss_provider")
 	serverURL, _ = db.GetSetting("freshrss_server_url")
 	username, _ = db.GetSetting("freshrss_username")
 	password, err = db.GetEncryptedSetting("freshrss_api_password")
@@ -310,3 +311,119 @@ func (db *DB) UpdateFreshRSSItemID(articleID int64, freshRSSItemID string) error
 	log.Printf("[UpdateFreshRSSItemID] Updated article %d with FreshRSS Item ID: %s", articleID, freshRSSItemID)
 	return nil
 }
+
+// TagArticlesWithSync adds or removes a user tag on articles. It returns the number of
+// articles whose tags changed, and sync requests for those that belong to FreshRSS feeds,
+// so the tag is mirrored as a label.
+func (db *DB) TagArticlesWithSync(tagID int64, articleIDs []int64, add bool) (int, []SyncRequest, error) {
+	tag, err := db.GetTagByID(tagID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if tag == nil {
+		return 0, nil, sql.ErrNoRows
+	}
+
+	changed, err := db.SetArticlesTag(tagID, articleIDs, add)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	enabled, _ := db.GetSetting("freshrss_enabled")
+	if enabled != "true" || len(changed) == 0 {
+		return len(changed), nil, nil
+	}
+
+	action := LabelSyncAction(tag.Name, add)
+	var requests []SyncRequest
+	for _, id := range changed {
+		var url string
+		var isFreshRSSFeed bool
+		err := db.QueryRow(`
+			SELECT a.url, COALESCE(f.is_freshrss_source, 0)
+			FROM articles a JOIN feeds f ON a.feed_id = f.id
+			WHERE a.id = ?`, id).Scan(&url, &isFreshRSSFeed)
+		if err != nil || !isFreshRSSFeed {
+			continue
+		}
+		log.Printf("[FreshRSS Sync] Article %d needs sync: %s", id, action)
+		requests = append(requests, SyncRequest{
+			ArticleID:  id,
+			ArticleURL: url,
+			Action:     action,
+		})
+	}
+	return len(changed), requests, nil
+}
+
+// UpdateTagWithSync updates a tag like UpdateTag. If the tag is renamed, it returns sync
+// requests that move the label of its articles in FreshRSS feeds to the new name.
+func (db *DB) UpdateTagWithSync(id int64, name, color string, position int) ([]SyncRequest, error) {
+	tag, err := db.GetTagByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, sql.ErrNoRows
+	}
+	if err := db.UpdateTag(id, name, color, position); err != nil {
+		return nil, err
+	}
+
+	renamed, err := db.GetTagByID(id)
+	if err != nil || renamed == nil || renamed.Name == tag.Name {
+		return nil, err
+	}
+	return db.tagLabelSyncRequests(id, LabelSyncAction(tag.Name, false), LabelSyncAction(renamed.Name, true))
+}
+
+// DeleteTagWithSync deletes a tag like DeleteTag and returns sync requests that remove
+// its label from its articles in FreshRSS feeds
+func (db *DB) DeleteTagWithSync(id int64) ([]SyncRequest, error) {
+	tag, err := db.GetTagByID(id)
+	if err != nil || tag == nil {
+		return nil, err
+	}
+	// The tagged articles must be looked up before the tag is removed from them
+	requests, err := db.tagLabelSyncRequests(id, LabelSyncAction(tag.Name, false))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.DeleteTag(id); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// tagLabelSyncRequests returns sync requests with the given actions for every article in
+// a FreshRSS feed that has a tag. It returns nothing if FreshRSS sync is disabled.
+func (db *DB) tagLabelSyncRequests(tagID int64, actions ...SyncAction) ([]SyncRequest, error) {
+	enabled, _ := db.GetSetting("freshrss_enabled")
+	if enabled != "true" {
+		return nil, nil
+	}
+
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT a.id, a.url FROM article_tags at
+		JOIN articles a ON a.id = at.article_id
+		JOIN feeds f ON f.id = a.feed_id
+		WHERE at.tag_id = ? AND COALESCE(f.is_freshrss_source, 0) = 1`, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []SyncRequest
+	for rows.Next() {
+		var id int64
+		var url string
+		if err := rows.Scan(&id, &url); err != nil {
+			return nil, err
+		}
+		for _, action := range actions {
+			requests = append(requests, SyncRequest{ArticleID: id, ArticleURL: url, Action: action})
+		}
+	}
+	return requests, rows.Err()
+}
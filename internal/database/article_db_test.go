@@ -323,3 +323,51 @@ func TestArticleDifferentTitlesNotDeduplicated(t *testing.T) {
 		t.Fatalf("expected 2 articles with different titles, got %d", len(articles))
 	}
 }
+
+func TestDeletedArticlesAreNotSavedAgain(t *testing.T) {
+	db := setupDBWithFeed(t)
+
+	var feedID int64
+	row := db.QueryRow(`SELECT id FROM feeds WHERE url = ?`, "https://example.com/feed")
+	if err := row.Scan(&feedID); err != nil {
+		t.Fatalf("scan feed id: %v", err)
+	}
+
+	publishedAt := time.Now()
+	newBatch := func() []*models.Article {
+		return []*models.Article{
+			{FeedID: feedID, Title: "Kept", URL: "https://example.com/kept", PublishedAt: publishedAt},
+			{FeedID: feedID, Title: "Deleted", URL: "https://example.com/deleted", PublishedAt: publishedAt},
+		}
+	}
+
+	batch := newBatch()
+	if inserted, err := db.SaveArticlesCounted(context.Background(), batch); err != nil || inserted != 2 {
+		t.Fatalf("SaveArticlesCounted = %d, %v, want 2 inserted", inserted, err)
+	}
+	if batch[0].ID == 0 || batch[1].ID == 0 {
+		t.Fatalf("inserted articles without IDs: %+v", batch)
+	}
+	if err := db.DeleteArticle(batch[1].ID); err != nil {
+		t.Fatalf("DeleteArticle error: %v", err)
+	}
+
+	// The next refresh still lists the deleted article
+	again := newBatch()
+	if inserted, err := db.SaveArticlesCounted(context.Background(), again); err != nil || inserted != 0 {
+		t.Errorf("SaveArticlesCounted after delete = %d, %v, want 0 inserted", inserted, err)
+	}
+	if again[0].ID != 0 || again[1].ID != 0 {
+		t.Errorf("existing articles got IDs: %+v", again)
+	}
+	if err := db.SaveArticle(newBatch()[1]); err != nil {
+		t.Fatalf("SaveArticle error: %v", err)
+	}
+	articles, err := db.GetArticles("all", feedID, "", true, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "Kept" {
+		t.Errorf("expected only the kept article, got %+v", articles)
+	}
+}
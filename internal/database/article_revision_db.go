@@ -0,0 +1,153 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+)
+
+// maxArticleRevisions is the number of revisions kept per article; older ones are
+// dropped, except for the original version
+const maxArticleRevisions = 20
+
+// InitArticleRevisionsTable creates the article_revisions table if it doesn't exist.
+// Revisions are only stored once an article changes: the original version becomes
+// revision 1 and every change adds the next one.
+func InitArticleRevisionsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS article_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		article_id INTEGER NOT NULL,
+		revision INTEGER NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		content TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(article_id, revision)
+	);
+
+	-- Foreign keys are not enforced, so remove revisions of deleted articles explicitly
+	CREATE TRIGGER IF NOT EXISTS article_revisions_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_revisions WHERE article_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// articleVersionHash identifies the visible title and text of an article version, so
+// markup-only changes such as new tracking parameters don't count as edits
+func articleVersionHash(title, content string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(title) + "\x00" + utils.HTMLToText(content)))
+	return hex.EncodeToString(hash[:])
+}
+
+// RecordArticleVersion compares the title and content of an article in its feed with
+// the version seen last, and stores a new revision if the publisher changed them.
+// The first version seen of an article is only remembered. Changed articles get an
+// updated_at time and are marked unread again if markUnread is set.
+func (db *DB) RecordArticleVersion(articleID int64, title, content string, markUnread bool) (bool, error) {
+	db.WaitForReady()
+	hash := articleVersionHash(title, content)
+
+	var lastHash string
+	if err := db.QueryRow(`SELECT COALESCE(content_hash, '') FROM articles WHERE id = ?`, articleID).Scan(&lastHash); err != nil {
+		return false, err
+	}
+	if lastHash == hash {
+		return false, nil
+	}
+	if lastHash == "" {
+		_, err := db.Exec(`UPDATE articles SET content_hash = ? WHERE id = ?`, hash, articleID)
+		return false, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	latest, err := ensureOriginalRevision(tx, articleID)
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`INSERT INTO article_revisions (article_id, revision, title, content) VALUES (?, ?, ?, ?)`,
+		articleID, latest+1, title, content); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`DELETE FROM article_revisions WHERE article_id = ? AND revision > 1 AND revision <= ?`,
+		articleID, latest+1-maxArticleRevisions); err != nil {
+		return false, err
+	}
+
+	query := `UPDATE articles SET content_hash = ?, title = ?, updated_at = CURRENT_TIMESTAMP`
+	if markUnread {
+		query += `, is_read = 0`
+	}
+	if _, err := tx.Exec(query+` WHERE id = ?`, hash, title, articleID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// ensureOriginalRevision stores the current title and feed content of an article as
+// revision 1 if it has no revisions yet, and returns its latest revision number.
+// The content is empty if it was cleaned up or replaced by the full text.
+func ensureOriginalRevision(tx *sql.Tx, articleID int64) (int, error) {
+	var latest int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(revision), 0) FROM article_revisions WHERE article_id = ?`, articleID).Scan(&latest); err != nil {
+		return 0, err
+	}
+	if latest > 0 {
+		return latest, nil
+	}
+
+	_, err := tx.Exec(`INSERT INTO article_revisions (article_id, revision, title, content, created_at)
+		SELECT a.id, 1, COALESCE(a.title, ''), COALESCE(c.content, ''), COALESCE(a.published_at, CURRENT_TIMESTAMP)
+		FROM articles a
+		LEFT JOIN article_contents c ON c.article_id = a.id AND COALESCE(c.full_text, 0) = 0
+		WHERE a.id = ?`, articleID)
+	return 1, err
+}
+
+// GetArticleRevisions returns the revisions of an article, oldest first, without their content
+func (db *DB) GetArticleRevisions(articleID int64) ([]models.ArticleRevision, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT id, article_id, revision, title, created_at
+		FROM article_revisions WHERE article_id = ? ORDER BY revision`, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.ArticleRevision{}
+	for rows.Next() {
+		var r models.ArticleRevision
+		if err := rows.Scan(&r.ID, &r.ArticleID, &r.Revision, &r.Title, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// GetArticleRevision returns a revision of an article with its content, or nil if it doesn't exist
+func (db *DB) GetArticleRevision(articleID int64, revision int) (*models.ArticleRevision, error) {
+	db.WaitForReady()
+	var r models.ArticleRevision
+	err := db.QueryRow(`SELECT id, article_id, revision, title, content, created_at
+		FROM article_revisions WHERE article_id = ? AND revision = ?`, articleID, revision).
+		Scan(&r.ID, &r.ArticleID, &r.Revision, &r.Title, &r.Content, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestRecordArticleVersion(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+
+	feedID, _ := db.AddFeed(&models.Feed{Title: "News", URL: "https://example.com/feed"})
+	published := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	article := &models.Article{FeedID: feedID, Title: "Minister resigns", URL: "https://example.com/1", PublishedAt: published, HasValidPublishedTime: true, IsRead: true}
+	if err := db.SaveArticles(context.Background(), []*models.Article{article}); err != nil {
+		t.Fatal(err)
+	}
+	articleID, err := db.GetArticleIDByUniqueID(article.Title, feedID, published, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetArticleContent(articleID, "<p>The minister resigned.</p>")
+	db.Exec(`UPDATE articles SET is_read = 1 WHERE id = ?`, articleID)
+
+	// The first version is only remembered, and markup-only changes are ignored
+	for _, content := range []string{"<p>The minister resigned.</p>", `<p class="x">The minister  resigned.</p>`} {
+		if changed, err := db.RecordArticleVersion(articleID, "Minister resigns", content, true); err != nil || changed {
+			t.Fatalf("RecordArticleVersion(%q) = %v, %v; want unchanged", content, changed, err)
+		}
+	}
+	if revisions, _ := db.GetArticleRevisions(articleID); len(revisions) != 0 {
+		t.Errorf("unchanged article has revisions: %+v", revisions)
+	}
+
+	changed, err := db.RecordArticleVersion(articleID, "Minister resigns", "<p>The minister did not resign.</p>", true)
+	if err != nil || !changed {
+		t.Fatalf("RecordArticleVersion = %v, %v; want changed", changed, err)
+	}
+
+	revisions, err := db.GetArticleRevisions(articleID)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("GetArticleRevisions = %+v, %v", revisions, err)
+	}
+	original, _ := db.GetArticleRevision(articleID, 1)
+	latest, _ := db.GetArticleRevision(articleID, 2)
+	if original.Content != "<p>The minister resigned.</p>" || latest.Content != "<p>The minister did not resign.</p>" {
+		t.Errorf("revision contents = %q, %q", original.Content, latest.Content)
+	}
+	if got, _ := db.GetArticleRevision(articleID, 3); got != nil {
+		t.Errorf("revision 3 = %+v, want none", got)
+	}
+
+	a, _ := db.GetArticleByID(articleID)
+	if a.UpdatedAt == nil || a.IsRead {
+		t.Errorf("updated article: updated_at %v, is_read %v", a.UpdatedAt, a.IsRead)
+	}
+
+	// Revisions are removed with their article
+	db.Exec(`DELETE FROM articles WHERE id = ?`, articleID)
+	if revisions, _ := db.GetArticleRevisions(articleID); len(revisions) != 0 {
+		t.Errorf("revisions of a deleted article: %+v", revisions)
+	}
+}
+
+func TestSaveArticles_EditedTitle(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	feedID, _ := db.AddFeed(&models.Feed{Title: "News", URL: "https://example.com/feed"})
+	if err := db.SaveArticles(ctx, []*models.Article{{FeedID: feedID, Title: "Old headline", URL: "https://example.com/1"}}); err != nil {
+		t.Fatal(err)
+	}
+	oldID, _ := db.GetArticleIDByUniqueID("Old headline", feedID, time.Time{}, false)
+
+	// An edited title keeps the article and its original title as revision 1
+	inserted, err := db.SaveArticlesCounted(ctx, []*models.Article{{FeedID: feedID, Title: "New headline", URL: "https://example.com/1"}})
+	if err != nil || inserted != 0 {
+		t.Fatalf("SaveArticlesCounted = %d, %v; want no new article", inserted, err)
+	}
+	newID, err := db.GetArticleIDByUniqueID("New headline", feedID, time.Time{}, false)
+	if err != nil || newID != oldID {
+		t.Fatalf("edited article has ID %d (%v), want %d", newID, err, oldID)
+	}
+	if original, _ := db.GetArticleRevision(oldID, 1); original == nil || original.Title != "Old headline" {
+		t.Errorf("original revision = %+v", original)
+	}
+
+	// Items of one batch sharing a URL stay separate articles
+	inserted, err = db.SaveArticlesCounted(ctx, []*models.Article{
+		{FeedID: feedID, Title: "Live blog 1", URL: "https://example.com/live"},
+		{FeedID: feedID, Title: "Live blog 2", URL: "https://example.com/live"},
+	})
+	if err != nil || inserted != 2 {
+		t.Errorf("SaveArticlesCounted = %d, %v; want 2 new articles", inserted, err)
+	}
+}
@@ -0,0 +1,229 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// ArticleStreamFilter selects the articles of a sync API stream.
+// Hidden articles are never part of a stream.
+type ArticleStreamFilter struct {
+	FeedID         int64     // Only articles of this feed
+	Category       string    // Only articles of feeds in this category or its subcategories
+	Tag            string    // Only articles with this user tag
+	OnlyRead       bool      // Only read articles
+	OnlyUnread     bool      // Only unread articles
+	OnlyStarred    bool      // Only favorite articles
+	ExcludeStarred bool      // Skip favorite articles
+	After          time.Time // Only articles published at or after this time
+	Before         time.Time // Only articles published before this time
+	SinceID        int64     // Only articles with a greater ID
+	MaxID          int64     // Only articles with a smaller ID
+	IDs            []int64   // Only these articles
+	OldestFirst    bool      // Sort ascending instead of descending
+	ByID           bool      // Sort by article ID instead of publish time
+	Limit          int
+	Offset         int
+}
+
+// where returns the WHERE clause and arguments of the filter, for a query on articles aliased as a
+func (f ArticleStreamFilter) where() (string, []interface{}) {
+	clauses := []string{"a.is_hidden = 0"}
+	var args []interface{}
+
+	if f.FeedID > 0 {
+		clauses = append(clauses, "a.feed_id = ?")
+		args = append(args, f.FeedID)
+	}
+	if f.Category != "" {
+		clauses = append(clauses, "a.feed_id IN (SELECT id FROM feeds WHERE category = ? OR category LIKE ?)")
+		args = append(args, f.Category, f.Category+"/%")
+	}
+	if f.Tag != "" {
+		clauses = append(clauses, "EXISTS (SELECT 1 FROM article_tags at JOIN tags t ON t.id = at.tag_id WHERE at.article_id = a.id AND t.name = ?)")
+		args = append(args, f.Tag)
+	}
+	if f.OnlyRead {
+		clauses = append(clauses, "a.is_read = 1")
+	}
+	if f.OnlyUnread {
+		clauses = append(clauses, "a.is_read = 0")
+	}
+	if f.OnlyStarred {
+		clauses = append(clauses, "a.is_favorite = 1")
+	}
+	if f.ExcludeStarred {
+		clauses = append(clauses, "a.is_favorite = 0")
+	}
+	if !f.After.IsZero() {
+		clauses = append(clauses, "a.published_at >= ?")
+		args = append(args, f.After.UTC())
+	}
+	if !f.Before.IsZero() {
+		clauses = append(clauses, "a.published_at < ?")
+		args = append(args, f.Before.UTC())
+	}
+
+	if f.SinceID > 0 {
+		clauses = append(clauses, "a.id > ?")
+		args = append(args, f.SinceID)
+	}
+	if f.MaxID > 0 {
+		clauses = append(clauses, "a.id < ?")
+		args = append(args, f.MaxID)
+	}
+	if f.IDs != nil {
+		// A leading NULL keeps the list valid when IDs is empty
+		clauses = append(clauses, "a.id IN (NULL"+strings.Repeat(", ?", len(f.IDs))+")")
+		for _, id := range f.IDs {
+			args = append(args, id)
+		}
+	}
+
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// orderBy returns the ORDER BY, LIMIT and OFFSET clauses of the filter and appends their arguments
+func (f ArticleStreamFilter) orderBy(args []interface{}) (string, []interface{}) {
+	order := "DESC"
+	if f.OldestFirst {
+		order = "ASC"
+	}
+	column := "a.published_at " + order + ", a.id"
+	if f.ByID {
+		column = "a.id"
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	return " ORDER BY " + column + " " + order + " LIMIT ? OFFSET ?", append(args, limit, f.Offset)
+}
+
+// GetArticleStream returns the articles matching filter, newest first unless OldestFirst is set.
+func (db *DB) GetArticleStream(filter ArticleStreamFilter) ([]models.Article, error) {
+	db.WaitForReady()
+
+	where, args := filter.where()
+	order, args := filter.orderBy(args)
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, COALESCE(a.author, ''), a.updated_at, ` + articleCategoriesColumn + `, ` + articleTagsColumn + `
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id` + where + order
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	articles := []models.Article{}
+	for rows.Next() {
+		var a models.Article
+		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, categories, tags sql.NullString
+		var publishedAt sql.NullTime
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &a.FeedTitle, &a.Author, &updatedAt, &categories, &tags); err != nil {
+			return nil, err
+		}
+		a.ImageURL = imageURL.String
+		a.AudioURL = audioURL.String
+		a.VideoURL = videoURL.String
+		if publishedAt.Valid {
+			a.PublishedAt = publishedAt.Time
+		}
+		a.TranslatedTitle = translatedTitle.String
+		a.Summary = summary.String
+		a.Categories = splitArticleCategories(categories.String)
+		a.Tags = splitArticleCategories(tags.String)
+		if updatedAt.Valid {
+			a.UpdatedAt = &updatedAt.Time
+		}
+		a.FreshRSSItemID = freshrssItemID.String
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// GetArticleStreamIDs returns the IDs of the articles matching filter, in the order of GetArticleStream.
+func (db *DB) GetArticleStreamIDs(filter ArticleStreamFilter) ([]int64, error) {
+	db.WaitForReady()
+
+	where, args := filter.where()
+	order, args := filter.orderBy(args)
+	rows, err := db.Query("SELECT a.id FROM articles a"+where+order, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CountArticleStream returns the number of articles matching filter, ignoring pagination.
+func (db *DB) CountArticleStream(filter ArticleStreamFilter) (int, error) {
+	db.WaitForReady()
+
+	where, args := filter.where()
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM articles a"+where, args...).Scan(&count)
+	return count, err
+}
+
+// UnreadStat is the number of unread articles of a feed and the publish time of the newest one
+type UnreadStat struct {
+	Count  int
+	Newest time.Time
+}
+
+// GetUnreadStatsForAllFeeds returns the unread statistics of every feed with unread articles.
+func (db *DB) GetUnreadStatsForAllFeeds() (map[int64]UnreadStat, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT feed_id, COUNT(*), MAX(published_at)
+		FROM articles
+		WHERE is_read = 0 AND is_hidden = 0
+		GROUP BY feed_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int64]UnreadStat)
+	for rows.Next() {
+		var feedID int64
+		var stat UnreadStat
+		var newest sql.NullString
+		if err := rows.Scan(&feedID, &stat.Count, &newest); err != nil {
+			return nil, err
+		}
+		if newest.Valid {
+			stat.Newest = parseStoredTime(newest.String)
+		}
+		stats[feedID] = stat
+	}
+	return stats, rows.Err()
+}
+
+// parseStoredTime parses a time read back from an aggregate, where the driver returns the stored text.
+// It returns the zero time if the text is in none of the known formats.
+func parseStoredTime(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999 -0700 MST", time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
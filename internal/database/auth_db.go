@@ -0,0 +1,143 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// authTokenTouchInterval limits how often last_used_at is written for a busy token
+const authTokenTouchInterval = time.Minute
+
+// InitAuthTokensTable creates the auth_tokens table if it doesn't exist
+func InitAuthTokensTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS auth_tokens (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		token_hash TEXT NOT NULL UNIQUE,
+		csrf_token TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		last_used_at DATETIME NOT NULL,
+		expires_at DATETIME
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_auth_tokens_user ON auth_tokens(user_id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// CreateAuthToken stores a new token. Only the hash of the token secret is kept.
+func (db *DB) CreateAuthToken(token *models.AuthToken, tokenHash string) error {
+	db.WaitForReady()
+	now := time.Now().UTC()
+	token.CreatedAt = now
+	token.LastUsedAt = now
+
+	var expiresAt interface{}
+	if token.ExpiresAt != nil {
+		expiresAt = token.ExpiresAt.UTC()
+	}
+	result, err := db.Exec(`INSERT INTO auth_tokens
+		(user_id, kind, name, token_hash, csrf_token, created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		token.UserID, token.Kind, token.Name, tokenHash, token.CSRFToken, now, now, expiresAt)
+	if err != nil {
+		return err
+	}
+	token.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// GetAuthTokenByHash looks up an unexpired token by the hash of its secret and records its use.
+// It returns sql.ErrNoRows if there is no such token.
+func (db *DB) GetAuthTokenByHash(tokenHash string) (*models.AuthToken, error) {
+	db.WaitForReady()
+	row := db.QueryRow(`SELECT id, user_id, kind, name, csrf_token, created_at, last_used_at, expires_at
+		FROM auth_tokens WHERE token_hash = ?`, tokenHash)
+	token, err := scanAuthToken(row)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+		return nil, sql.ErrNoRows
+	}
+	if now.Sub(token.LastUsedAt) > authTokenTouchInterval {
+		token.LastUsedAt = now.UTC()
+		if _, err := db.Exec("UPDATE auth_tokens SET last_used_at = ? WHERE id = ?", token.LastUsedAt, token.ID); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// GetAuthTokens returns the unexpired tokens of a user, newest first.
+func (db *DB) GetAuthTokens(userID int64) ([]models.AuthToken, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT id, user_id, kind, name, csrf_token, created_at, last_used_at, expires_at
+		FROM auth_tokens WHERE user_id = ? ORDER BY id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	tokens := make([]models.AuthToken, 0)
+	for rows.Next() {
+		token, err := scanAuthToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		if token.ExpiresAt == nil || token.ExpiresAt.After(now) {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteAuthToken revokes a token of a user. It returns sql.ErrNoRows if the user has no such token.
+func (db *DB) DeleteAuthToken(userID, id int64) error {
+	db.WaitForReady()
+	result, err := db.Exec("DELETE FROM auth_tokens WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteSessionsExcept revokes all login sessions of a user but the given one, e.g. after a password change.
+func (db *DB) DeleteSessionsExcept(userID, keepID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec("DELETE FROM auth_tokens WHERE user_id = ? AND kind = 'session' AND id != ?", userID, keepID)
+	return err
+}
+
+// DeleteExpiredAuthTokens removes tokens that can no longer be used.
+func (db *DB) DeleteExpiredAuthTokens() error {
+	db.WaitForReady()
+	_, err := db.Exec("DELETE FROM auth_tokens WHERE expires_at IS NOT NULL AND expires_at <= ?", time.Now().UTC())
+	return err
+}
+
+func scanAuthToken(row interface{ Scan(...interface{}) error }) (*models.AuthToken, error) {
+	var token models.AuthToken
+	var expiresAt sql.NullTime
+	if err := row.Scan(&token.ID, &token.UserID, &token.Kind, &token.Name, &token.CSRFToken,
+		&token.CreatedAt, &token.LastUsedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	return &token, nil
+}
@@ -0,0 +1,637 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/utils"
+)
+
+// BackupTables lists the tables that make up a backup, parents before the tables
+// referencing them. Derived data (search index, duplicate clusters) is rebuilt as rows are
+// restored and right after a restore; refresh and digest send history, sync and
+// notification queues are refetched or start empty instead.
+var BackupTables = []string{
+	"settings",
+	"feeds",
+	"feed_http_settings",
+	"feed_fulltext_settings",
+	"feed_email_settings",
+	"feed_email_lists",
+	"feed_email_lists_removed",
+	"tags",
+	"articles",
+	"article_contents",
+	"article_categories",
+	"article_tags",
+	"article_revisions",
+	"rules",
+	"rule_conditions",
+	"rule_stats",
+	"rule_history",
+	"digests",
+	"digest_articles",
+	"notification_targets",
+	"chat_sessions",
+	"chat_messages",
+	"statistics",
+	"translation_cache",
+}
+
+// backupSkippedSettings are not backed up: window placement belongs to the machine, the
+// rules setting is a mirror of the rules tables and the key check belongs to the secrets
+// stored locally
+var backupSkippedSettings = map[string]bool{
+	rulesSettingKey:       true,
+	secretKeyCheckSetting: true,
+	"window_x":            true,
+	"window_y":            true,
+	"window_width":        true,
+	"window_height":       true,
+	"window_maximized":    true,
+}
+
+// maxRestoreConflicts is the number of conflicts listed in a restore report
+const maxRestoreConflicts = 200
+
+// BackupRow is a table row in a backup, by column name
+type BackupRow map[string]interface{}
+
+// RestoreMode selects how a backup is combined with the existing data
+type RestoreMode string
+
+const (
+	// RestoreMerge adds the backup to the existing data. Feeds, tags and articles that
+	// exist already are matched and keep their local values, except that read,
+	// favorite and read-later flags are combined.
+	RestoreMerge RestoreMode = "merge"
+	// RestoreReplace deletes the existing data first. Settings missing from the
+	// backup, such as secrets that were left out, are kept.
+	RestoreReplace RestoreMode = "replace"
+)
+
+// RestoreTableReport counts what happened to the rows of a table during a restore
+type RestoreTableReport struct {
+	Inserted int `json:"inserted"`
+	Merged   int `json:"merged"`  // Matched an existing row
+	Skipped  int `json:"skipped"` // Duplicates and rows whose parent was not restored
+}
+
+// RestoreConflict is a backup row that differs from the existing data it was matched with
+type RestoreConflict struct {
+	Table  string `json:"table"`
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// RestoreReport describes the outcome of a restore
+type RestoreReport struct {
+	Mode          RestoreMode                    `json:"mode"`
+	Tables        map[string]*RestoreTableReport `json:"tables"`
+	Conflicts     []RestoreConflict              `json:"conflicts"`
+	ConflictCount int                            `json:"conflict_count"` // Total, Conflicts is capped
+}
+
+// BackupRowReader calls fn with every row of a table in a backup
+type BackupRowReader func(table string, fn func(BackupRow) error) error
+
+// DatabaseFile returns the path of the database file
+func (db *DB) DatabaseFile() (string, error) {
+	var file string
+	err := db.QueryRow(`SELECT file FROM pragma_database_list WHERE name = 'main'`).Scan(&file)
+	return file, err
+}
+
+// VacuumInto writes a consistent copy of the database to path, which must not exist
+func (db *DB) VacuumInto(path string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
+// ExportBackupRows calls fn with every row of a backup table
+func (db *DB) ExportBackupRows(table string, fn func(BackupRow) error) error {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT * FROM ` + table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		row := make(BackupRow, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		if table == "settings" && backupSkippedSettings[fmt.Sprint(row["key"])] {
+			continue
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// RestoreBackup restores the tables of a backup in a single transaction
+func (db *DB) RestoreBackup(mode RestoreMode, read BackupRowReader) (*RestoreReport, error) {
+	if mode != RestoreMerge && mode != RestoreReplace {
+		return nil, fmt.Errorf("unknown restore mode %q", mode)
+	}
+
+	report := &RestoreReport{Mode: mode, Tables: map[string]*RestoreTableReport{}, Conflicts: []RestoreConflict{}}
+	// Restoring the rules tables has to refresh the rules cache and setting mirror
+	err := db.writeRules(func(tx *sql.Tx) error {
+		r := &restorer{
+			tx:         tx,
+			report:     report,
+			columns:    map[string]map[string]string{},
+			feedIDs:    map[int64]int64{},
+			articleIDs: map[int64]int64{},
+			tagIDs:     map[int64]int64{},
+			ruleIDs:    map[int64]int64{},
+			digestIDs:  map[int64]int64{},
+			sessionIDs: map[int64]int64{},
+		}
+		if mode == RestoreReplace {
+			if err := r.clear(); err != nil {
+				return err
+			}
+		}
+		for _, table := range BackupTables {
+			tableReport := &RestoreTableReport{}
+			report.Tables[table] = tableReport
+			err := read(table, func(row BackupRow) error {
+				if table == "settings" && backupSkippedSettings[rowString(row, "key")] {
+					tableReport.Skipped++
+					return nil
+				}
+				if mode == RestoreReplace {
+					return r.replaceRow(table, row, tableReport)
+				}
+				return r.mergeRow(table, row, tableReport)
+			})
+			if err != nil {
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Restored articles have no fingerprints, so group them into duplicate clusters now
+	if _, err := db.ClusterUnfingerprintedArticles(); err != nil {
+		log.Printf("Error clustering restored articles: %v", err)
+	}
+	return report, nil
+}
+
+// restorer holds the state of a restore: table columns and the IDs rows were
+// restored under, by their ID in the backup
+type restorer struct {
+	tx      *sql.Tx
+	report  *RestoreReport
+	columns map[string]map[string]string
+
+	feedIDs    map[int64]int64
+	articleIDs map[int64]int64
+	tagIDs     map[int64]int64
+	digestIDs  map[int64]int64
+	// ruleIDs and sessionIDs only hold rules and chat sessions that were inserted,
+	// so the rows belonging to them are not merged into existing ones
+	ruleIDs    map[int64]int64
+	sessionIDs map[int64]int64
+}
+
+// clear deletes the data that a replacing restore overwrites
+func (r *restorer) clear() error {
+	for i := len(BackupTables) - 1; i >= 0; i-- {
+		if BackupTables[i] == "settings" {
+			continue
+		}
+		if _, err := r.tx.Exec(`DELETE FROM ` + BackupTables[i]); err != nil {
+			return err
+		}
+	}
+	// Queued changes, hub subscriptions, digest sends and notifications refer to the deleted
+	// articles, feeds, digests and notification targets
+	for _, table := range []string{"freshrss_sync_queue", "websub_subscriptions", "digest_sends",
+		"notification_queue", "notification_deliveries"} {
+		if _, err := r.tx.Exec(`DELETE FROM ` + table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the columns of a table in this database with their declared types
+func (r *restorer) tableColumns(table string) (map[string]string, error) {
+	if columns, ok := r.columns[table]; ok {
+		return columns, nil
+	}
+	rows, err := r.tx.Query(`SELECT name, type FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := map[string]string{}
+	for rows.Next() {
+		var name, columnType string
+		if err := rows.Scan(&name, &columnType); err != nil {
+			return nil, err
+		}
+		columns[name] = strings.ToUpper(columnType)
+	}
+	r.columns[table] = columns
+	return columns, rows.Err()
+}
+
+// insert inserts a row, leaving out the omitted columns and columns this database
+// doesn't have. It returns the ID of the row, or 0 if "OR IGNORE" skipped it.
+func (r *restorer) insert(verb, table string, row BackupRow, omit ...string) (int64, error) {
+	columns, err := r.tableColumns(table)
+	if err != nil {
+		return 0, err
+	}
+	var names []string
+	var args []interface{}
+	for name, value := range row {
+		columnType, ok := columns[name]
+		if !ok || slices.Contains(omit, name) {
+			continue
+		}
+		names = append(names, name)
+		args = append(args, restoreValue(columnType, value))
+	}
+	if len(names) == 0 {
+		return 0, nil
+	}
+	result, err := r.tx.Exec(verb+` INTO `+table+` (`+strings.Join(names, ", ")+`) VALUES (?`+strings.Repeat(", ?", len(names)-1)+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, nil
+	}
+	return result.LastInsertId()
+}
+
+// replaceRow restores a row with its original IDs
+func (r *restorer) replaceRow(table string, row BackupRow, tableReport *RestoreTableReport) error {
+	verb := "INSERT OR IGNORE"
+	if table == "settings" {
+		verb = "INSERT OR REPLACE"
+	}
+	id, err := r.insert(verb, table, row)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		tableReport.Skipped++
+	} else {
+		tableReport.Inserted++
+	}
+	return nil
+}
+
+// mergeRow adds a row to the existing data, matching it with existing rows and
+// translating the IDs it refers to
+func (r *restorer) mergeRow(table string, row BackupRow, tableReport *RestoreTableReport) error {
+	// mapped replaces a reference column by the ID its row was restored under
+	mapped := func(column string, ids map[int64]int64) bool {
+		id, ok := ids[rowInt(row, column)]
+		if ok {
+			row[column] = id
+		}
+		return ok
+	}
+	// insertOrIgnore inserts a row that has no identity of its own
+	insertOrIgnore := func(omit ...string) error {
+		id, err := r.insert("INSERT OR IGNORE", table, row, omit...)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			tableReport.Skipped++
+		} else {
+			tableReport.Inserted++
+		}
+		return nil
+	}
+	// insertNew inserts a row under a new ID and remembers it
+	insertNew := func(ids map[int64]int64) error {
+		backupID := rowInt(row, "id")
+		id, err := r.insert("INSERT", table, row, "id")
+		if err != nil {
+			return err
+		}
+		ids[backupID] = id
+		tableReport.Inserted++
+		return nil
+	}
+
+	switch table {
+	case "settings":
+		key, value := rowString(row, "key"), rowString(row, "value")
+		var local string
+		err := r.tx.QueryRow(`SELECT COALESCE(value, '') FROM settings WHERE key = ?`, key).Scan(&local)
+		if err == sql.ErrNoRows {
+			return insertOrIgnore()
+		}
+		if err != nil {
+			return err
+		}
+		tableReport.Merged++
+		// Encrypted values differ even for the same secret
+		if local != value && !crypto.IsEncrypted(local) && !crypto.IsEncrypted(value) {
+			r.conflict(table, key, "kept the local value")
+		}
+		return nil
+
+	case "feeds":
+		var localID int64
+		var title, category string
+		err := r.tx.QueryRow(`SELECT id, COALESCE(title, ''), COALESCE(category, '') FROM feeds
+			WHERE url = ? AND COALESCE(is_freshrss_source, 0) = ? LIMIT 1`,
+			rowString(row, "url"), rowInt(row, "is_freshrss_source")).Scan(&localID, &title, &category)
+		if err == sql.ErrNoRows {
+			return insertNew(r.feedIDs)
+		}
+		if err != nil {
+			return err
+		}
+		r.feedIDs[rowInt(row, "id")] = localID
+		tableReport.Merged++
+		if title != rowString(row, "title") || category != rowString(row, "category") {
+			r.conflict(table, rowString(row, "url"), "kept the local title and category")
+		}
+		return nil
+
+	case "feed_http_settings", "feed_fulltext_settings", "feed_email_settings":
+		if !mapped("feed_id", r.feedIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore()
+
+	case "feed_email_lists":
+		if !mapped("feed_id", r.feedIDs) || !mapped("mailbox_feed_id", r.feedIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore()
+
+	case "feed_email_lists_removed":
+		if !mapped("mailbox_feed_id", r.feedIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore()
+
+	case "tags":
+		var localID int64
+		err := r.tx.QueryRow(`SELECT id FROM tags WHERE name = ?`, rowString(row, "name")).Scan(&localID)
+		if err == sql.ErrNoRows {
+			return insertNew(r.tagIDs)
+		}
+		if err != nil {
+			return err
+		}
+		r.tagIDs[rowInt(row, "id")] = localID
+		tableReport.Merged++
+		return nil
+
+	case "articles":
+		backupFeedID := rowInt(row, "feed_id")
+		if !mapped("feed_id", r.feedIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		uniqueID := remapArticleUniqueID(row, backupFeedID, rowInt(row, "feed_id"))
+		row["unique_id"] = uniqueID
+
+		var localID int64
+		err := r.tx.QueryRow(`SELECT id FROM articles WHERE unique_id = ?`, uniqueID).Scan(&localID)
+		if err == sql.ErrNoRows {
+			return insertNew(r.articleIDs)
+		}
+		if err != nil {
+			return err
+		}
+		r.articleIDs[rowInt(row, "id")] = localID
+		tableReport.Merged++
+		_, err = r.tx.Exec(`UPDATE articles SET
+			is_read = MAX(is_read, ?), is_favorite = MAX(is_favorite, ?),
+			is_read_later = MAX(is_read_later, ?), is_hidden = MAX(is_hidden, ?),
+			summary = CASE WHEN COALESCE(summary, '') = '' THEN ? ELSE summary END,
+			translated_title = CASE WHEN COALESCE(translated_title, '') = '' THEN ? ELSE translated_title END
+			WHERE id = ?`,
+			rowInt(row, "is_read"), rowInt(row, "is_favorite"), rowInt(row, "is_read_later"), rowInt(row, "is_hidden"),
+			rowString(row, "summary"), rowString(row, "translated_title"), localID)
+		return err
+
+	case "article_contents", "article_categories", "article_revisions":
+		if !mapped("article_id", r.articleIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore("id")
+
+	case "article_tags":
+		if !mapped("article_id", r.articleIDs) || !mapped("tag_id", r.tagIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore()
+
+	case "rules":
+		var localID int64
+		var actions string
+		err := r.tx.QueryRow(`SELECT id, actions FROM rules WHERE name = ? AND name != '' LIMIT 1`, rowString(row, "name")).Scan(&localID, &actions)
+		if err == sql.ErrNoRows {
+			return insertNew(r.ruleIDs)
+		}
+		if err != nil {
+			return err
+		}
+		tableReport.Merged++
+		if actions != rowString(row, "actions") {
+			r.conflict(table, rowString(row, "name"), "kept the local rule with the same name")
+		}
+		return nil
+
+	case "rule_conditions", "rule_stats", "rule_history":
+		if !mapped("rule_id", r.ruleIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore("id")
+
+	case "digests":
+		var localID int64
+		err := r.tx.QueryRow(`SELECT id FROM digests WHERE name = ? AND name != '' LIMIT 1`, rowString(row, "name")).Scan(&localID)
+		if err == sql.ErrNoRows {
+			return insertNew(r.digestIDs)
+		}
+		if err != nil {
+			return err
+		}
+		// Articles sent by either copy of the digest are not sent again
+		r.digestIDs[rowInt(row, "id")] = localID
+		tableReport.Merged++
+		return nil
+
+	case "digest_articles":
+		if !mapped("digest_id", r.digestIDs) || !mapped("article_id", r.articleIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore()
+
+	case "notification_targets":
+		var exists bool
+		err := r.tx.QueryRow(`SELECT 1 FROM notification_targets WHERE name = ? AND name != '' LIMIT 1`, rowString(row, "name")).Scan(&exists)
+		if err == sql.ErrNoRows {
+			if _, err := r.insert("INSERT", table, row, "id"); err != nil {
+				return err
+			}
+			tableReport.Inserted++
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		tableReport.Merged++
+		return nil
+
+	case "chat_sessions":
+		if !mapped("article_id", r.articleIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		var exists bool
+		if err := r.tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM chat_sessions WHERE article_id = ? AND title = ?)`,
+			row["article_id"], rowString(row, "title")).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			tableReport.Merged++
+			return nil
+		}
+		return insertNew(r.sessionIDs)
+
+	case "chat_messages":
+		if !mapped("session_id", r.sessionIDs) {
+			tableReport.Skipped++
+			return nil
+		}
+		return insertOrIgnore("id")
+
+	case "statistics":
+		result, err := r.tx.Exec(`UPDATE statistics SET count = MAX(count, ?) WHERE event_date = ? AND event_type = ?`,
+			rowInt(row, "count"), rowString(row, "event_date"), rowString(row, "event_type"))
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			tableReport.Merged++
+			return nil
+		}
+		return insertOrIgnore("id")
+
+	default:
+		return insertOrIgnore("id")
+	}
+}
+
+// conflict records a conflict in the report
+func (r *restorer) conflict(table, key, reason string) {
+	r.report.ConflictCount++
+	if len(r.report.Conflicts) < maxRestoreConflicts {
+		r.report.Conflicts = append(r.report.Conflicts, RestoreConflict{Table: table, Key: key, Reason: reason})
+	}
+}
+
+// remapArticleUniqueID returns the unique_id of an article moved to another feed ID.
+// The unique_id includes the feed ID, so it must change or the next refresh would add
+// the article again.
+func remapArticleUniqueID(row BackupRow, oldFeedID, newFeedID int64) string {
+	uniqueID := rowString(row, "unique_id")
+	if oldFeedID == newFeedID {
+		return uniqueID
+	}
+	title := rowString(row, "title")
+	publishedAt, _ := restoreValue("DATETIME", row["published_at"]).(time.Time)
+	// Articles without a valid publication date were identified without one
+	hasValidTime := uniqueID != utils.GenerateArticleUniqueID(title, oldFeedID, publishedAt, false)
+	return utils.GenerateArticleUniqueID(title, newFeedID, publishedAt, hasValidTime)
+}
+
+// restoreValue converts a value decoded from a backup for a column of the given type
+func restoreValue(columnType string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+		return v
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case string:
+		// Times are stored in the driver's format so they keep sorting correctly
+		if strings.Contains(columnType, "DATE") || strings.Contains(columnType, "TIME") {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t
+			}
+		}
+		return v
+	}
+	return value
+}
+
+// rowInt returns a numeric column of a backup row
+func rowInt(row BackupRow, column string) int64 {
+	i, _ := restoreValue("", row[column]).(int64)
+	return i
+}
+
+// rowString returns a text column of a backup row
+func rowString(row BackupRow, column string) string {
+	if s, ok := row[column].(string); ok {
+		return s
+	}
+	return ""
+}
@@ -50,6 +50,7 @@ func (db *DB) CleanupOldArticles() (int64, error) {
 	// Also cleanup related caches with the same age limit
 	_, _ = db.CleanupTranslationCache(maxAgeDays)
 	_, _ = db.CleanupOldArticleContents(maxAgeDays)
+	_, _ = db.Exec(`DELETE FROM deleted_articles WHERE deleted_at < ?`, time.Now().Add(-deletedArticleRetention).UTC())
 
 	// Run VACUUM to reclaim space
 	_, _ = db.Exec("VACUUM")
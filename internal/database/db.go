@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"MrRSS/internal/config"
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/models"
 
 	_ "modernc.org/sqlite"
 )
@@ -18,6 +20,16 @@ type DB struct {
 	*sql.DB
 	ready chan struct{}
 	once  sync.Once
+
+	// rulesMu guards the cached automation rules; writes hold it for the whole transaction
+	rulesMu     sync.RWMutex
+	rules       []models.Rule
+	rulesLoaded bool
+
+	// secretKey is the master passphrase key once it has been entered; writes hold
+	// secretsMu while secrets are re-encrypted
+	secretsMu sync.RWMutex
+	secretKey *crypto.PassphraseKey
 }
 
 // NewDB creates a new database connection with optimized settings.
@@ -71,6 +83,21 @@ func (db *DB) Init() error {
 			return
 		}
 
+		// Initialize WebSub subscriptions table
+		if err = InitWebSubTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize rule hit counters table
+		if err = InitRuleStatsTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize tombstones of deleted articles
+		if err = InitDeletedArticlesTable(db.DB); err != nil {
+			return
+		}
+
 		// Create settings table if not exists
 		_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS settings (
 			key TEXT PRIMARY KEY,
@@ -85,6 +112,17 @@ func (db *DB) Init() error {
 			_, _ = db.Exec(fmt.Sprintf(`INSERT OR IGNORE INTO settings (key, value) VALUES ('%s', '%s')`, key, defaultVal))
 		}
 
+		// Initialize automation rules tables and move rules out of the legacy JSON setting
+		if err = InitRulesTables(db.DB); err != nil {
+			return
+		}
+		if err = migrateRulesAutoincrement(db.DB); err != nil {
+			return
+		}
+		if err = migrateRulesFromSettings(db.DB); err != nil {
+			return
+		}
+
 		// Migration: Add link column to feeds table if it doesn't exist
 		// Note: SQLite doesn't support IF NOT EXISTS for ALTER TABLE ADD COLUMN.
 		// Error is ignored - if column exists, the operation fails harmlessly.
@@ -263,7 +301,15 @@ func (db *DB) Init() error {
 					email_folder TEXT DEFAULT 'INBOX',
 					email_last_uid INTEGER DEFAULT 0,
 					is_freshrss_source BOOLEAN DEFAULT 0,
-					freshrss_stream_id TEXT DEFAULT ''
+					freshrss_stream_id TEXT DEFAULT '',
+					etag TEXT DEFAULT '',
+					last_modified TEXT DEFAULT '',
+					fetch_count INTEGER DEFAULT 0,
+					not_modified_count INTEGER DEFAULT 0,
+					consecutive_failures INTEGER DEFAULT 0,
+					next_eligible_at DATETIME,
+					failing_since DATETIME,
+					is_dead BOOLEAN DEFAULT 0
 				)
 			`)
 			if err == nil {
@@ -326,6 +372,72 @@ func (db *DB) Init() error {
 				log.Printf("Error creating feeds_new table: %v", err)
 			}
 		}
+
+		// The following tables use triggers on articles or feeds, so they must be initialized
+		// after the migrations above, which may recreate those tables
+
+		// Initialize per-article item categories table
+		if err = InitArticleCategoriesTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize user tags tables
+		if err = InitTagsTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize full-text search index
+		if err = InitArticleSearchTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize per-feed HTTP request settings (trigger on feeds)
+		if err = InitFeedHTTPSettingsTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize per-feed full-text settings (trigger on feeds)
+		if err = InitFeedFullTextSettingsTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize per-feed newsletter settings and split newsletter feeds (triggers on feeds)
+		if err = InitFeedEmailSettingsTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize article revisions (trigger on articles)
+		if err = InitArticleRevisionsTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize duplicate story clusters (trigger on articles)
+		if err = InitArticleClustersTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize refresh history (trigger on feeds)
+		if err = InitFetchLogTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize email digests and their send history (trigger on articles)
+		if err = InitDigestTables(db.DB); err != nil {
+			return
+		}
+
+		// Initialize notification targets, their queues and deliveries (trigger on articles)
+		if err = InitNotificationTables(db.DB); err != nil {
+			return
+		}
+
+		// Initialize users, login sessions and API tokens of the server build
+		if err = InitUsersTable(db.DB); err != nil {
+			return
+		}
+		if err = InitAuthTokensTable(db.DB); err != nil {
+			return
+		}
 	})
 	return err
 }
@@ -531,6 +643,33 @@ func runMigrations(db *sql.DB) error {
 	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN freshrss_stream_id TEXT DEFAULT ''`)
 	_, _ = db.Exec(`ALTER TABLE articles ADD COLUMN freshrss_item_id TEXT DEFAULT ''`)
 
+	// Migration: Add HTTP cache validators for conditional GET (ETag / Last-Modified)
+	// fetch_count and not_modified_count track how often a feed answers 304 Not Modified
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN etag TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN last_modified TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN fetch_count INTEGER DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN not_modified_count INTEGER DEFAULT 0`)
+
+	// Migration: Add failure backoff state so backed-off feeds stay deferred across restarts
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN consecutive_failures INTEGER DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN next_eligible_at DATETIME`)
+
+	// Migration: Add feed health state; failing_since marks the start of the current failure streak
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN failing_since DATETIME`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN is_dead BOOLEAN DEFAULT 0`)
+
+	// Migration: Add author column for item authors (item categories are stored in article_categories)
+	_, _ = db.Exec(`ALTER TABLE articles ADD COLUMN author TEXT DEFAULT ''`)
+
+	// Migration: Mark article contents extracted from the article's web page, which feed
+	// refreshes must not overwrite with the feed's content
+	_, _ = db.Exec(`ALTER TABLE article_contents ADD COLUMN full_text BOOLEAN DEFAULT 0`)
+
+	// Migration: Add change detection; content_hash identifies the title and content last
+	// seen in the feed, updated_at marks articles the publisher changed
+	_, _ = db.Exec(`ALTER TABLE articles ADD COLUMN content_hash TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE articles ADD COLUMN updated_at DATETIME`)
+
 	return nil
 }
 
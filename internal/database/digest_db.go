@@ -0,0 +1,314 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// digestSendsPerDigest is the number of sends kept in the history of each digest.
+// The articles of older sends are still remembered, so they aren't sent again.
+const digestSendsPerDigest = 100
+
+// InitDigestTables creates the digests, digest_sends and digest_articles tables if they don't exist
+func InitDigestTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS digests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		schedule TEXT NOT NULL DEFAULT 'daily',
+		weekday INTEGER NOT NULL DEFAULT 0,
+		hour INTEGER NOT NULL DEFAULT 8,
+		recipients TEXT NOT NULL DEFAULT '[]',
+		conditions TEXT NOT NULL DEFAULT '[]',
+		max_articles INTEGER NOT NULL DEFAULT 0,
+		ai_summary BOOLEAN NOT NULL DEFAULT 0,
+		last_run_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS digest_sends (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		digest_id INTEGER NOT NULL,
+		sent_at DATETIME NOT NULL,
+		recipients TEXT NOT NULL DEFAULT '[]',
+		article_count INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL,
+		error TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_digest_sends_digest ON digest_sends(digest_id, id DESC);
+
+	CREATE TABLE IF NOT EXISTS digest_articles (
+		digest_id INTEGER NOT NULL,
+		article_id INTEGER NOT NULL,
+		send_id INTEGER NOT NULL,
+		PRIMARY KEY (digest_id, article_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_digest_articles_send ON digest_articles(send_id);
+
+	CREATE TRIGGER IF NOT EXISTS digest_articles_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM digest_articles WHERE article_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetDigests returns all digests
+func (db *DB) GetDigests() ([]models.Digest, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT id, name, enabled, schedule, weekday, hour, recipients, conditions,
+		max_articles, ai_summary, last_run_at, created_at FROM digests ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	digests := make([]models.Digest, 0)
+	for rows.Next() {
+		d, err := scanDigest(rows)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, *d)
+	}
+	return digests, rows.Err()
+}
+
+// GetDigest returns a digest by ID. Returns sql.ErrNoRows if it doesn't exist.
+func (db *DB) GetDigest(id int64) (*models.Digest, error) {
+	db.WaitForReady()
+	row := db.QueryRow(`SELECT id, name, enabled, schedule, weekday, hour, recipients, conditions,
+		max_articles, ai_summary, last_run_at, created_at FROM digests WHERE id = ?`, id)
+	return scanDigest(row)
+}
+
+// CreateDigest inserts a new digest and returns its ID. Its first send is due at the
+// first scheduled time after it was created.
+func (db *DB) CreateDigest(digest models.Digest) (int64, error) {
+	db.WaitForReady()
+	recipients, conditions, err := marshalDigestLists(digest)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(`INSERT INTO digests (name, enabled, schedule, weekday, hour, recipients, conditions,
+		max_articles, ai_summary, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		digest.Name, digest.Enabled, digest.Schedule, digest.Weekday, digest.Hour, recipients, conditions,
+		digest.MaxArticles, digest.AISummary, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateDigest replaces a digest's name, schedule, recipients and criteria.
+// Returns sql.ErrNoRows if the digest doesn't exist.
+func (db *DB) UpdateDigest(digest models.Digest) error {
+	db.WaitForReady()
+	recipients, conditions, err := marshalDigestLists(digest)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`UPDATE digests SET name = ?, enabled = ?, schedule = ?, weekday = ?, hour = ?,
+		recipients = ?, conditions = ?, max_articles = ?, ai_summary = ? WHERE id = ?`,
+		digest.Name, digest.Enabled, digest.Schedule, digest.Weekday, digest.Hour, recipients, conditions,
+		digest.MaxArticles, digest.AISummary, digest.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteDigest deletes a digest with its send history. Returns sql.ErrNoRows if it doesn't exist.
+func (db *DB) DeleteDigest(id int64) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM digests WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec(`DELETE FROM digest_sends WHERE digest_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM digest_articles WHERE digest_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetDigestLastRun records when a digest was last due, whether or not anything was sent
+func (db *DB) SetDigestLastRun(id int64, at time.Time) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE digests SET last_run_at = ? WHERE id = ?`, at.UTC(), id)
+	return err
+}
+
+// GetSentDigestArticleIDs returns the articles that were already sent with a digest
+func (db *DB) GetSentDigestArticleIDs(digestID int64) (map[int64]bool, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT article_id FROM digest_articles WHERE digest_id = ?`, digestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// AddDigestSend records a digest email. The articles of successful sends are remembered,
+// so they are not sent with the digest again.
+func (db *DB) AddDigestSend(send *models.DigestSend) error {
+	db.WaitForReady()
+	if send.SentAt.IsZero() {
+		send.SentAt = time.Now()
+	}
+	recipients, err := json.Marshal(send.Recipients)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO digest_sends (digest_id, sent_at, recipients, article_count, status, error)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		send.DigestID, send.SentAt.UTC(), string(recipients), len(send.ArticleIDs), send.Status, send.Error)
+	if err != nil {
+		return err
+	}
+	send.ID, _ = result.LastInsertId()
+	send.ArticleCount = len(send.ArticleIDs)
+
+	if send.Status == models.DigestSendSent {
+		for _, articleID := range send.ArticleIDs {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO digest_articles (digest_id, article_id, send_id) VALUES (?, ?, ?)`,
+				send.DigestID, articleID, send.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM digest_sends WHERE digest_id = ? AND id <= (
+		SELECT id FROM digest_sends WHERE digest_id = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		send.DigestID, send.DigestID, digestSendsPerDigest); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetDigestSends returns the latest sends of a digest with their articles, newest first
+func (db *DB) GetDigestSends(digestID int64, limit int) ([]models.DigestSend, error) {
+	db.WaitForReady()
+	if limit <= 0 || limit > digestSendsPerDigest {
+		limit = digestSendsPerDigest
+	}
+
+	rows, err := db.Query(`SELECT id, digest_id, sent_at, recipients, article_count, status, error
+		FROM digest_sends WHERE digest_id = ? ORDER BY id DESC LIMIT ?`, digestID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sends := make([]models.DigestSend, 0)
+	for rows.Next() {
+		var s models.DigestSend
+		var recipients string
+		if err := rows.Scan(&s.ID, &s.DigestID, &s.SentAt, &recipients, &s.ArticleCount, &s.Status, &s.Error); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(recipients), &s.Recipients)
+		s.ArticleIDs = []int64{}
+		sends = append(sends, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range sends {
+		articleRows, err := db.Query(`SELECT article_id FROM digest_articles WHERE send_id = ? ORDER BY article_id`, sends[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		for articleRows.Next() {
+			var id int64
+			if err := articleRows.Scan(&id); err != nil {
+				articleRows.Close()
+				return nil, err
+			}
+			sends[i].ArticleIDs = append(sends[i].ArticleIDs, id)
+		}
+		articleRows.Close()
+	}
+	return sends, nil
+}
+
+// marshalDigestLists encodes the recipients and conditions of a digest for storage
+func marshalDigestLists(digest models.Digest) (string, string, error) {
+	if digest.Recipients == nil {
+		digest.Recipients = []string{}
+	}
+	if digest.Conditions == nil {
+		digest.Conditions = []models.FilterCondition{}
+	}
+	recipients, err := json.Marshal(digest.Recipients)
+	if err != nil {
+		return "", "", err
+	}
+	conditions, err := json.Marshal(digest.Conditions)
+	if err != nil {
+		return "", "", err
+	}
+	return string(recipients), string(conditions), nil
+}
+
+func scanDigest(row rowScanner) (*models.Digest, error) {
+	var d models.Digest
+	var recipients, conditions string
+	var lastRun sql.NullTime
+	if err := row.Scan(&d.ID, &d.Name, &d.Enabled, &d.Schedule, &d.Weekday, &d.Hour, &recipients, &conditions,
+		&d.MaxArticles, &d.AISummary, &lastRun, &d.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(recipients), &d.Recipients); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(conditions), &d.Conditions); err != nil {
+		return nil, err
+	}
+	if lastRun.Valid {
+		d.LastRunAt = &lastRun.Time
+	}
+	return &d, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestDigests(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+
+	id, err := db.CreateDigest(models.Digest{
+		Name:       "Weekly",
+		Enabled:    true,
+		Schedule:   models.DigestWeekly,
+		Weekday:    1,
+		Hour:       9,
+		Recipients: []string{"team@example.com"},
+		Conditions: []models.FilterCondition{{Field: "is_favorite", Value: "true"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateDigest error: %v", err)
+	}
+	d, err := db.GetDigest(id)
+	if err != nil {
+		t.Fatalf("GetDigest error: %v", err)
+	}
+	if d.Name != "Weekly" || d.Weekday != 1 || len(d.Conditions) != 1 || d.Conditions[0].Field != "is_favorite" ||
+		d.LastRunAt != nil || d.CreatedAt.IsZero() {
+		t.Errorf("unexpected digest: %+v", d)
+	}
+
+	d.Name = "Monday favorites"
+	if err := db.UpdateDigest(*d); err != nil {
+		t.Fatalf("UpdateDigest error: %v", err)
+	}
+	if err := db.UpdateDigest(models.Digest{ID: id + 1}); err != sql.ErrNoRows {
+		t.Errorf("updating a missing digest: err = %v, want sql.ErrNoRows", err)
+	}
+	runAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	db.SetDigestLastRun(id, runAt)
+	if d, _ = db.GetDigest(id); d.Name != "Monday favorites" || d.LastRunAt == nil || !d.LastRunAt.Equal(runAt) {
+		t.Errorf("unexpected digest after update: %+v", d)
+	}
+
+	// Only the articles of successful sends are remembered
+	feedID, _ := db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	db.SaveArticle(&models.Article{FeedID: feedID, Title: "A", URL: "https://example.com/a"})
+	db.SaveArticle(&models.Article{FeedID: feedID, Title: "B", URL: "https://example.com/b"})
+	articles, _ := db.GetArticles("", feedID, "", true, 10, 0)
+	if len(articles) != 2 {
+		t.Fatalf("%d articles saved", len(articles))
+	}
+	failed := &models.DigestSend{DigestID: id, Recipients: d.Recipients, ArticleIDs: []int64{articles[0].ID}, Status: models.DigestSendFailed, Error: "refused"}
+	sent := &models.DigestSend{DigestID: id, Recipients: d.Recipients, ArticleIDs: []int64{articles[0].ID, articles[1].ID}, Status: models.DigestSendSent}
+	for _, send := range []*models.DigestSend{failed, sent} {
+		if err := db.AddDigestSend(send); err != nil {
+			t.Fatalf("AddDigestSend error: %v", err)
+		}
+	}
+	sends, err := db.GetDigestSends(id, 0)
+	if err != nil || len(sends) != 2 {
+		t.Fatalf("GetDigestSends = %+v, %v", sends, err)
+	}
+	if sends[0].Status != models.DigestSendSent || len(sends[0].ArticleIDs) != 2 ||
+		sends[1].Error != "refused" || sends[1].ArticleCount != 1 || len(sends[1].ArticleIDs) != 0 {
+		t.Errorf("unexpected sends: %+v", sends)
+	}
+
+	// Deleted articles are forgotten
+	db.DeleteArticle(articles[0].ID)
+	if ids, _ := db.GetSentDigestArticleIDs(id); len(ids) != 1 || !ids[articles[1].ID] {
+		t.Errorf("sent articles = %v", ids)
+	}
+
+	if err := db.DeleteDigest(id); err != nil {
+		t.Fatalf("DeleteDigest error: %v", err)
+	}
+	if _, err := db.GetDigest(id); err != sql.ErrNoRows {
+		t.Errorf("GetDigest after delete: err = %v", err)
+	}
+	if sends, _ := db.GetDigestSends(id, 0); len(sends) != 0 {
+		t.Errorf("sends of a deleted digest: %+v", sends)
+	}
+	if err := db.DeleteDigest(id); err != sql.ErrNoRows {
+		t.Errorf("deleting a missing digest: err = %v, want sql.ErrNoRows", err)
+	}
+}
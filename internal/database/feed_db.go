@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"strings"
 	"time"
 
@@ -29,10 +30,16 @@ func parseDateTimeForSQL(timeStr string) string {
 func (db *DB) AddFeed(feed *models.Feed) (int64, error) {
 	db.WaitForReady()
 
+	// IMAP passwords are stored encrypted
+	emailPassword, err := db.storedSecret(feed.EmailPassword)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt IMAP password: %w", err)
+	}
+
 	// Check if feed already exists with same URL AND same source type
 	var existingID int64
 	var existingIsFreshRSS bool
-	err := db.QueryRow("SELECT id, is_freshrss_source FROM feeds WHERE url = ?", feed.URL).Scan(&existingID, &existingIsFreshRSS)
+	err = db.QueryRow("SELECT id, is_freshrss_source FROM feeds WHERE url = ?", feed.URL).Scan(&existingID, &existingIsFreshRSS)
 
 	if err == sql.ErrNoRows {
 		// Feed doesn't exist, insert new
@@ -68,7 +75,7 @@ func (db *DB) AddFeed(feed *models.Feed) (int64, error) {
 			feed.XPathItemThumbnail, feed.XPathItemCategories, feed.XPathItemUid,
 			feed.ArticleViewMode, feed.AutoExpandContent,
 			feed.EmailAddress, feed.EmailIMAPServer, feed.EmailIMAPPort,
-			feed.EmailUsername, feed.EmailPassword, feed.EmailFolder, feed.EmailLastUID,
+			feed.EmailUsername, emailPassword, feed.EmailFolder, feed.EmailLastUID,
 			feed.IsFreshRSSSource, feed.FreshRSSStreamID,
 			time.Now())
 		if err != nil {
@@ -118,7 +125,7 @@ func (db *DB) AddFeed(feed *models.Feed) (int64, error) {
 			feed.XPathItemThumbnail, feed.XPathItemCategories, feed.XPathItemUid,
 			feed.ArticleViewMode, feed.AutoExpandContent,
 			feed.EmailAddress, feed.EmailIMAPServer, feed.EmailIMAPPort,
-			feed.EmailUsername, feed.EmailPassword, feed.EmailFolder, feed.EmailLastUID,
+			feed.EmailUsername, emailPassword, feed.EmailFolder, feed.EmailLastUID,
 			feed.IsFreshRSSSource, feed.FreshRSSStreamID,
 			time.Now())
 		if err != nil {
@@ -134,19 +141,33 @@ func (db *DB) AddFeed(feed *models.Feed) (int64, error) {
 	// Same URL and same source type - update existing feed
 	// (note: we don't update is_freshrss_source or freshrss_stream_id for existing feeds)
 	query := `UPDATE feeds SET title = ?, link = ?, description = ?, category = ?, image_url = ?, position = ?, script_path = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ?, email_last_uid = ?, last_updated = ? WHERE id = ?`
-	_, err = db.Exec(query, feed.Title, feed.Link, feed.Description, feed.Category, feed.ImageURL, feed.Position, feed.ScriptPath, feed.HideFromTimeline, feed.ProxyURL, feed.ProxyEnabled, feed.RefreshInterval, feed.IsImageMode, feed.Type, feed.XPathItem, feed.XPathItemTitle, feed.XPathItemContent, feed.XPathItemUri, feed.XPathItemAuthor, feed.XPathItemTimestamp, feed.XPathItemTimeFormat, feed.XPathItemThumbnail, feed.XPathItemCategories, feed.XPathItemUid, feed.ArticleViewMode, feed.AutoExpandContent, feed.EmailAddress, feed.EmailIMAPServer, feed.EmailIMAPPort, feed.EmailUsername, feed.EmailPassword, feed.EmailFolder, feed.EmailLastUID, time.Now(), existingID)
+	_, err = db.Exec(query, feed.Title, feed.Link, feed.Description, feed.Category, feed.ImageURL, feed.Position, feed.ScriptPath, feed.HideFromTimeline, feed.ProxyURL, feed.ProxyEnabled, feed.RefreshInterval, feed.IsImageMode, feed.Type, feed.XPathItem, feed.XPathItemTitle, feed.XPathItemContent, feed.XPathItemUri, feed.XPathItemAuthor, feed.XPathItemTimestamp, feed.XPathItemTimeFormat, feed.XPathItemThumbnail, feed.XPathItemCategories, feed.XPathItemUid, feed.ArticleViewMode, feed.AutoExpandContent, feed.EmailAddress, feed.EmailIMAPServer, feed.EmailIMAPPort, feed.EmailUsername, emailPassword, feed.EmailFolder, feed.EmailLastUID, time.Now(), existingID)
 	return existingID, err
 }
 
 // DeleteFeed deletes a feed and all its articles.
 func (db *DB) DeleteFeed(id int64) error {
 	db.WaitForReady()
+	// The newsletter feeds a mailbox was split into go with it
+	listFeedIDs, err := db.getEmailListFeedIDs(id)
+	if err != nil {
+		return err
+	}
+	for _, listFeedID := range listFeedIDs {
+		if err := db.DeleteFeed(listFeedID); err != nil {
+			return err
+		}
+	}
 	// First delete associated articles
-	_, err := db.Exec("DELETE FROM articles WHERE feed_id = ?", id)
+	_, err = db.Exec("DELETE FROM articles WHERE feed_id = ?", id)
 	if err != nil {
 		return err
 	}
 	_, err = db.Exec("DELETE FROM feeds WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM websub_subscriptions WHERE feed_id = ?", id)
 	return err
 }
 
@@ -173,6 +194,10 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 			COALESCE(f.email_password, ''), COALESCE(f.email_folder, 'INBOX'),
 			COALESCE(f.email_last_uid, 0), COALESCE(f.is_freshrss_source, 0),
 			COALESCE(f.freshrss_stream_id, ''),
+			COALESCE(f.etag, ''), COALESCE(f.last_modified, ''),
+			COALESCE(f.fetch_count, 0), COALESCE(f.not_modified_count, 0),
+			COALESCE(f.consecutive_failures, 0), f.next_eligible_at,
+			f.failing_since, COALESCE(f.is_dead, 0),
 			(SELECT MAX(a.published_at) FROM articles a WHERE a.feed_id = f.id) as latest_article_time,
 			CAST(COALESCE((
 				SELECT
@@ -204,7 +229,7 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 	for rows.Next() {
 		var f models.Feed
 		var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID, latestArticleTimeStr sql.NullString
-		var lastUpdated sql.NullTime
+		var lastUpdated, nextEligibleAt, failingSince sql.NullTime
 		if err := rows.Scan(
 			&f.ID, &f.Title, &f.URL, &link, &f.Description, &category, &imageURL,
 			&f.Position, &lastUpdated, &lastError, &f.DiscoveryCompleted, &scriptPath,
@@ -214,7 +239,11 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 			&xpathItemThumbnail, &xpathItemCategories, &xpathItemUid, &articleViewMode,
 			&autoExpandContent, &emailAddress, &emailIMAPServer, &f.EmailIMAPPort,
 			&emailUsername, &emailPassword, &emailFolder, &f.EmailLastUID,
-			&f.IsFreshRSSSource, &freshRSSStreamID, &latestArticleTimeStr, &f.ArticlesPerMonth,
+			&f.IsFreshRSSSource, &freshRSSStreamID,
+			&f.ETag, &f.LastModified, &f.FetchCount, &f.NotModifiedCount,
+			&f.ConsecutiveFailures, &nextEligibleAt,
+			&failingSince, &f.IsDead,
+			&latestArticleTimeStr, &f.ArticlesPerMonth,
 		); err != nil {
 			return nil, err
 		}
@@ -264,6 +293,12 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 			f.EmailIMAPPort = 993
 		}
 		f.FreshRSSStreamID = freshRSSStreamID.String
+		if nextEligibleAt.Valid {
+			f.NextEligibleAt = &nextEligibleAt.Time
+		}
+		if failingSince.Valid {
+			f.FailingSince = &failingSince.Time
+		}
 
 		// Set latest article time from string
 		// Format from database: "2025-11-15 18:39:02 +0000 UTC" (Go's time.String() format)
@@ -306,12 +341,12 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 // GetFeedByID retrieves a specific feed by its ID.
 func (db *DB) GetFeedByID(id int64) (*models.Feed, error) {
 	db.WaitForReady()
-	row := db.QueryRow("SELECT id, title, url, link, description, category, image_url, COALESCE(position, 0), last_updated, last_error, COALESCE(discovery_completed, 0), COALESCE(script_path, ''), COALESCE(hide_from_timeline, 0), COALESCE(proxy_url, ''), COALESCE(proxy_enabled, 0), COALESCE(refresh_interval, 0), COALESCE(is_image_mode, 0), COALESCE(type, ''), COALESCE(xpath_item, ''), COALESCE(xpath_item_title, ''), COALESCE(xpath_item_content, ''), COALESCE(xpath_item_uri, ''), COALESCE(xpath_item_author, ''), COALESCE(xpath_item_timestamp, ''), COALESCE(xpath_item_time_format, ''), COALESCE(xpath_item_thumbnail, ''), COALESCE(xpath_item_categories, ''), COALESCE(xpath_item_uid, ''), COALESCE(article_view_mode, 'global'), COALESCE(auto_expand_content, 'global'), COALESCE(email_address, ''), COALESCE(email_imap_server, ''), COALESCE(email_imap_port, 993), COALESCE(email_username, ''), COALESCE(email_password, ''), COALESCE(email_folder, 'INBOX'), COALESCE(email_last_uid, 0), COALESCE(is_freshrss_source, 0), COALESCE(freshrss_stream_id, '') FROM feeds WHERE id = ?", id)
+	row := db.QueryRow("SELECT id, title, url, link, description, category, image_url, COALESCE(position, 0), last_updated, last_error, COALESCE(discovery_completed, 0), COALESCE(script_path, ''), COALESCE(hide_from_timeline, 0), COALESCE(proxy_url, ''), COALESCE(proxy_enabled, 0), COALESCE(refresh_interval, 0), COALESCE(is_image_mode, 0), COALESCE(type, ''), COALESCE(xpath_item, ''), COALESCE(xpath_item_title, ''), COALESCE(xpath_item_content, ''), COALESCE(xpath_item_uri, ''), COALESCE(xpath_item_author, ''), COALESCE(xpath_item_timestamp, ''), COALESCE(xpath_item_time_format, ''), COALESCE(xpath_item_thumbnail, ''), COALESCE(xpath_item_categories, ''), COALESCE(xpath_item_uid, ''), COALESCE(article_view_mode, 'global'), COALESCE(auto_expand_content, 'global'), COALESCE(email_address, ''), COALESCE(email_imap_server, ''), COALESCE(email_imap_port, 993), COALESCE(email_username, ''), COALESCE(email_password, ''), COALESCE(email_folder, 'INBOX'), COALESCE(email_last_uid, 0), COALESCE(is_freshrss_source, 0), COALESCE(freshrss_stream_id, ''), COALESCE(etag, ''), COALESCE(last_modified, ''), COALESCE(fetch_count, 0), COALESCE(not_modified_count, 0), COALESCE(consecutive_failures, 0), next_eligible_at, failing_since, COALESCE(is_dead, 0) FROM feeds WHERE id = ?", id)
 
 	var f models.Feed
 	var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID sql.NullString
-	var lastUpdated sql.NullTime
-	if err := row.Scan(&f.ID, &f.Title, &f.URL, &link, &f.Description, &category, &imageURL, &f.Position, &lastUpdated, &lastError, &f.DiscoveryCompleted, &scriptPath, &f.HideFromTimeline, &proxyURL, &f.ProxyEnabled, &f.RefreshInterval, &f.IsImageMode, &feedType, &xpathItem, &xpathItemTitle, &xpathItemContent, &xpathItemUri, &xpathItemAuthor, &xpathItemTimestamp, &xpathItemTimeFormat, &xpathItemThumbnail, &xpathItemCategories, &xpathItemUid, &articleViewMode, &autoExpandContent, &emailAddress, &emailIMAPServer, &f.EmailIMAPPort, &emailUsername, &emailPassword, &emailFolder, &f.EmailLastUID, &f.IsFreshRSSSource, &freshRSSStreamID); err != nil {
+	var lastUpdated, nextEligibleAt, failingSince sql.NullTime
+	if err := row.Scan(&f.ID, &f.Title, &f.URL, &link, &f.Description, &category, &imageURL, &f.Position, &lastUpdated, &lastError, &f.DiscoveryCompleted, &scriptPath, &f.HideFromTimeline, &proxyURL, &f.ProxyEnabled, &f.RefreshInterval, &f.IsImageMode, &feedType, &xpathItem, &xpathItemTitle, &xpathItemContent, &xpathItemUri, &xpathItemAuthor, &xpathItemTimestamp, &xpathItemTimeFormat, &xpathItemThumbnail, &xpathItemCategories, &xpathItemUid, &articleViewMode, &autoExpandContent, &emailAddress, &emailIMAPServer, &f.EmailIMAPPort, &emailUsername, &emailPassword, &emailFolder, &f.EmailLastUID, &f.IsFreshRSSSource, &freshRSSStreamID, &f.ETag, &f.LastModified, &f.FetchCount, &f.NotModifiedCount, &f.ConsecutiveFailures, &nextEligibleAt, &failingSince, &f.IsDead); err != nil {
 		return nil, err
 	}
 	f.Link = link.String
@@ -356,6 +391,12 @@ func (db *DB) GetFeedByID(id int64) (*models.Feed, error) {
 		f.EmailIMAPPort = 993
 	}
 	f.FreshRSSStreamID = freshRSSStreamID.String
+	if nextEligibleAt.Valid {
+		f.NextEligibleAt = &nextEligibleAt.Time
+	}
+	if failingSince.Valid {
+		f.FailingSince = &failingSince.Time
+	}
 
 	return &f, nil
 }
@@ -383,14 +424,22 @@ func (db *DB) GetAllFeedURLs() (map[string]bool, error) {
 // UpdateFeed updates feed title, URL, category, script_path, hide_from_timeline, proxy settings, refresh_interval, is_image_mode, XPath fields, article_view_mode, auto_expand_content, and email settings.
 func (db *DB) UpdateFeed(id int64, title, url, category, scriptPath string, hideFromTimeline bool, proxyURL string, proxyEnabled bool, refreshInterval int, isImageMode bool, feedType string, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder string, emailIMAPPort int) error {
 	db.WaitForReady()
-	_, err := db.Exec("UPDATE feeds SET title = ?, url = ?, category = ?, script_path = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", title, url, category, scriptPath, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
+	emailPassword, err := db.storedSecret(emailPassword)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt IMAP password: %w", err)
+	}
+	_, err = db.Exec("UPDATE feeds SET title = ?, url = ?, category = ?, script_path = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", title, url, category, scriptPath, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
 	return err
 }
 
 // UpdateFeedWithPosition updates a feed including its position field.
 func (db *DB) UpdateFeedWithPosition(id int64, title, url, category, scriptPath string, position int, hideFromTimeline bool, proxyURL string, proxyEnabled bool, refreshInterval int, isImageMode bool, feedType string, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder string, emailIMAPPort int) error {
 	db.WaitForReady()
-	_, err := db.Exec("UPDATE feeds SET title = ?, url = ?, category = ?, script_path = ?, position = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", title, url, category, scriptPath, position, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
+	emailPassword, err := db.storedSecret(emailPassword)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt IMAP password: %w", err)
+	}
+	_, err = db.Exec("UPDATE feeds SET title = ?, url = ?, category = ?, script_path = ?, position = ?, hide_from_timeline = ?, proxy_url = ?, proxy_enabled = ?, refresh_interval = ?, is_image_mode = ?, type = ?, xpath_item = ?, xpath_item_title = ?, xpath_item_content = ?, xpath_item_uri = ?, xpath_item_author = ?, xpath_item_timestamp = ?, xpath_item_time_format = ?, xpath_item_thumbnail = ?, xpath_item_categories = ?, xpath_item_uid = ?, article_view_mode = ?, auto_expand_content = ?, email_address = ?, email_imap_server = ?, email_imap_port = ?, email_username = ?, email_password = ?, email_folder = ? WHERE id = ?", title, url, category, scriptPath, position, hideFromTimeline, proxyURL, proxyEnabled, refreshInterval, isImageMode, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailIMAPPort, emailUsername, emailPassword, emailFolder, id)
 	return err
 }
 
@@ -423,9 +472,10 @@ func (db *DB) UpdateFeedError(id int64, errorMsg string) error {
 }
 
 // ClearAllFeedErrors clears error messages for all feeds.
+// Feeds that are still backing off after failures keep their error.
 func (db *DB) ClearAllFeedErrors() error {
 	db.WaitForReady()
-	_, err := db.Exec("UPDATE feeds SET last_error = ''")
+	_, err := db.Exec("UPDATE feeds SET last_error = '' WHERE next_eligible_at IS NULL OR next_eligible_at <= ?", time.Now().UTC())
 	return err
 }
 
@@ -443,6 +493,92 @@ func (db *DB) UpdateFeedEmailLastUID(id int64, lastUID int) error {
 	return err
 }
 
+// UpdateFeedValidators stores the ETag and Last-Modified headers from a feed's last successful fetch.
+func (db *DB) UpdateFeedValidators(id int64, etag, lastModified string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET etag = ?, last_modified = ? WHERE id = ?", etag, lastModified, id)
+	return err
+}
+
+// RecordFeedFetchResult records the outcome of a conditional fetch for a feed.
+// Both counters are halved once fetch_count reaches 100 so the ratio reflects recent behavior.
+func (db *DB) RecordFeedFetchResult(id int64, notModified bool) error {
+	db.WaitForReady()
+	notModifiedInc := 0
+	if notModified {
+		notModifiedInc = 1
+	}
+	_, err := db.Exec(`UPDATE feeds SET
+		fetch_count = COALESCE(fetch_count, 0) + 1,
+		not_modified_count = COALESCE(not_modified_count, 0) + ?
+		WHERE id = ?`, notModifiedInc, id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`UPDATE feeds SET
+		fetch_count = fetch_count / 2,
+		not_modified_count = not_modified_count / 2
+		WHERE id = ? AND fetch_count >= 100`, id)
+	return err
+}
+
+// RecordFeedFailure increments a feed's consecutive failure count and defers scheduled
+// refreshes until nextEligible. The first failure after a success starts the failure streak.
+// It returns the new failure count.
+func (db *DB) RecordFeedFailure(id int64, nextEligible time.Time) (int, error) {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE feeds SET
+		consecutive_failures = COALESCE(consecutive_failures, 0) + 1,
+		next_eligible_at = ?,
+		failing_since = COALESCE(failing_since, ?)
+		WHERE id = ?`, nextEligible.UTC(), time.Now().UTC(), id)
+	if err != nil {
+		return 0, err
+	}
+	var failures int
+	err = db.QueryRow("SELECT COALESCE(consecutive_failures, 0) FROM feeds WHERE id = ?", id).Scan(&failures)
+	return failures, err
+}
+
+// SetFeedNextEligible defers scheduled refreshes of a feed until nextEligible
+// without counting a failure, e.g. when the server asked us to retry later.
+func (db *DB) SetFeedNextEligible(id int64, nextEligible time.Time) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET next_eligible_at = ? WHERE id = ?", nextEligible.UTC(), id)
+	return err
+}
+
+// ResetFeedBackoff clears a feed's failure streak, next eligible time and dead flag after a successful fetch.
+func (db *DB) ResetFeedBackoff(id int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE feeds SET consecutive_failures = 0, next_eligible_at = NULL, failing_since = NULL, is_dead = 0
+		WHERE id = ? AND (COALESCE(consecutive_failures, 0) != 0 OR next_eligible_at IS NOT NULL OR failing_since IS NOT NULL)`, id)
+	return err
+}
+
+// UpdateDeadFeeds flags feeds that have been failing for longer than deadAfter as dead and
+// clears the flag on feeds that no longer qualify. A non-positive deadAfter clears all flags.
+// It returns the number of newly flagged feeds.
+func (db *DB) UpdateDeadFeeds(deadAfter time.Duration) (int64, error) {
+	db.WaitForReady()
+	if deadAfter <= 0 {
+		_, err := db.Exec("UPDATE feeds SET is_dead = 0 WHERE is_dead = 1")
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-deadAfter).UTC()
+	result, err := db.Exec(`UPDATE feeds SET is_dead = 1
+		WHERE COALESCE(is_dead, 0) = 0 AND failing_since IS NOT NULL AND failing_since <= ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	flagged, _ := result.RowsAffected()
+
+	_, err = db.Exec(`UPDATE feeds SET is_dead = 0
+		WHERE is_dead = 1 AND (failing_since IS NULL OR failing_since > ?)`, cutoff)
+	return flagged, err
+}
+
 // MarkFeedDiscovered marks a feed as having completed discovery.
 func (db *DB) MarkFeedDiscovered(id int64) error {
 	db.WaitForReady()
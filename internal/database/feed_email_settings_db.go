@@ -0,0 +1,258 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"MrRSS/internal/models"
+)
+
+var (
+	// ErrInvalidEmailFilter is returned for an email filter with an unknown field or operator,
+	// or an invalid regular expression
+	ErrInvalidEmailFilter = errors.New("email filters need a field of \"sender\", \"subject\" or \"list_id\", an operator of \"contains\", \"exact\" or \"regex\" and a value")
+	// ErrInvalidProcessedAction is returned for an unsupported action on processed messages
+	ErrInvalidProcessedAction = errors.New("processed action must be empty, \"mark_read\" or \"move\" with a folder")
+)
+
+// InitFeedEmailSettingsTable creates the feed_email_settings, feed_email_lists and
+// feed_email_lists_removed tables if they don't exist
+func InitFeedEmailSettingsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS feed_email_settings (
+		feed_id INTEGER PRIMARY KEY,
+		idle BOOLEAN NOT NULL DEFAULT 0,
+		filters TEXT NOT NULL DEFAULT '',
+		split_by_list BOOLEAN NOT NULL DEFAULT 0,
+		processed_action TEXT NOT NULL DEFAULT '',
+		move_folder TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TRIGGER IF NOT EXISTS feed_email_settings_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM feed_email_settings WHERE feed_id = old.id;
+	END;
+
+	CREATE TABLE IF NOT EXISTS feed_email_lists (
+		feed_id INTEGER PRIMARY KEY,
+		mailbox_feed_id INTEGER NOT NULL,
+		list_id TEXT NOT NULL,
+		UNIQUE(mailbox_feed_id, list_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS feed_email_lists_removed (
+		mailbox_feed_id INTEGER NOT NULL,
+		list_id TEXT NOT NULL,
+		PRIMARY KEY (mailbox_feed_id, list_id)
+	);
+
+	-- A deleted newsletter feed is remembered, so the next fetch doesn't add it again
+	DROP TRIGGER IF EXISTS feed_email_lists_delete;
+	CREATE TRIGGER feed_email_lists_delete AFTER DELETE ON feeds BEGIN
+		INSERT OR IGNORE INTO feed_email_lists_removed (mailbox_feed_id, list_id)
+			SELECT mailbox_feed_id, list_id FROM feed_email_lists WHERE feed_id = old.id;
+		DELETE FROM feed_email_lists WHERE feed_id = old.id;
+		DELETE FROM feed_email_lists_removed WHERE mailbox_feed_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetFeedEmailSettings returns the email settings of a newsletter feed, or nil if it has none.
+func (db *DB) GetFeedEmailSettings(feedID int64) (*models.FeedEmailSettings, error) {
+	db.WaitForReady()
+	var s models.FeedEmailSettings
+	var filters string
+	err := db.QueryRow(`SELECT feed_id, idle, filters, split_by_list, processed_action, move_folder
+		FROM feed_email_settings WHERE feed_id = ?`, feedID).
+		Scan(&s.FeedID, &s.Idle, &filters, &s.SplitByList, &s.ProcessedAction, &s.MoveFolder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if filters != "" {
+		if err := json.Unmarshal([]byte(filters), &s.Filters); err != nil {
+			log.Printf("Error parsing email filters of feed %d: %v", feedID, err)
+		}
+	}
+	return &s, nil
+}
+
+// SetFeedEmailSettings stores the email settings of a newsletter feed. Empty settings
+// remove the feed's entry.
+func (db *DB) SetFeedEmailSettings(settings *models.FeedEmailSettings) error {
+	db.WaitForReady()
+
+	filters := make([]models.EmailFilter, 0, len(settings.Filters))
+	for _, filter := range settings.Filters {
+		filter.Field = strings.ToLower(strings.TrimSpace(filter.Field))
+		filter.Operator = strings.ToLower(strings.TrimSpace(filter.Operator))
+		filter.Value = strings.TrimSpace(filter.Value)
+		if filter.Value == "" {
+			continue
+		}
+		if err := validateEmailFilter(filter); err != nil {
+			return err
+		}
+		filters = append(filters, filter)
+	}
+	settings.Filters = filters
+
+	settings.ProcessedAction = strings.ToLower(strings.TrimSpace(settings.ProcessedAction))
+	settings.MoveFolder = strings.TrimSpace(settings.MoveFolder)
+	switch settings.ProcessedAction {
+	case models.EmailProcessedKeep, models.EmailProcessedMarkRead:
+		settings.MoveFolder = ""
+	case models.EmailProcessedMove:
+		if settings.MoveFolder == "" {
+			return ErrInvalidProcessedAction
+		}
+	default:
+		return ErrInvalidProcessedAction
+	}
+
+	// Without splitting, the deleted newsletter feeds are forgotten and come back when
+	// the mailbox is split again
+	if !settings.SplitByList {
+		if _, err := db.Exec(`DELETE FROM feed_email_lists_removed WHERE mailbox_feed_id = ?`, settings.FeedID); err != nil {
+			return err
+		}
+	}
+
+	if settings.IsEmpty() {
+		_, err := db.Exec(`DELETE FROM feed_email_settings WHERE feed_id = ?`, settings.FeedID)
+		return err
+	}
+
+	var filtersJSON string
+	if len(settings.Filters) > 0 {
+		data, err := json.Marshal(settings.Filters)
+		if err != nil {
+			return err
+		}
+		filtersJSON = string(data)
+	}
+
+	_, err := db.Exec(`INSERT OR REPLACE INTO feed_email_settings
+		(feed_id, idle, filters, split_by_list, processed_action, move_folder) VALUES (?, ?, ?, ?, ?, ?)`,
+		settings.FeedID, settings.Idle, filtersJSON, settings.SplitByList, settings.ProcessedAction, settings.MoveFolder)
+	return err
+}
+
+// validateEmailFilter checks the field and operator of a filter and compiles regular expressions
+func validateEmailFilter(filter models.EmailFilter) error {
+	switch filter.Field {
+	case models.EmailFilterSender, models.EmailFilterSubject, models.EmailFilterListID:
+	default:
+		return ErrInvalidEmailFilter
+	}
+	switch filter.Operator {
+	case models.EmailFilterContains, models.EmailFilterExact:
+	case models.EmailFilterRegex:
+		if _, err := regexp.Compile(filter.Value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidEmailFilter, err)
+		}
+	default:
+		return ErrInvalidEmailFilter
+	}
+	return nil
+}
+
+// GetIdleEmailFeedIDs returns the newsletter feeds that keep an IMAP IDLE connection open
+func (db *DB) GetIdleEmailFeedIDs() ([]int64, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT s.feed_id FROM feed_email_settings s
+		JOIN feeds f ON f.id = s.feed_id
+		WHERE s.idle = 1 AND f.type = ?`, models.FeedTypeEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetEmailListFeedID returns the feed a mailbox feed splits a newsletter into, or 0 if
+// the newsletter has none yet
+func (db *DB) GetEmailListFeedID(mailboxFeedID int64, listID string) (int64, error) {
+	db.WaitForReady()
+	var id int64
+	err := db.QueryRow(`SELECT feed_id FROM feed_email_lists WHERE mailbox_feed_id = ? AND list_id = ?`,
+		mailboxFeedID, listID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// IsEmailListRemoved reports whether the feed of a newsletter in a mailbox was deleted
+func (db *DB) IsEmailListRemoved(mailboxFeedID int64, listID string) (bool, error) {
+	db.WaitForReady()
+	var removed bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM feed_email_lists_removed WHERE mailbox_feed_id = ? AND list_id = ?)`,
+		mailboxFeedID, listID).Scan(&removed)
+	return removed, err
+}
+
+// AddEmailListFeed adds the feed of a newsletter that a mailbox feed is split into
+func (db *DB) AddEmailListFeed(mailbox *models.Feed, listID, title string) (int64, error) {
+	feedID, err := db.AddFeed(&models.Feed{
+		Title:        title,
+		URL:          fmt.Sprintf("email://%s/%s", mailbox.EmailAddress, listID),
+		Description:  fmt.Sprintf("Newsletter %s in %s", listID, mailbox.EmailAddress),
+		Category:     mailbox.Category,
+		Type:         models.FeedTypeEmailList,
+		EmailAddress: mailbox.EmailAddress,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.Exec(`INSERT OR REPLACE INTO feed_email_lists (feed_id, mailbox_feed_id, list_id) VALUES (?, ?, ?)`,
+		feedID, mailbox.ID, listID)
+	return feedID, err
+}
+
+// GetEmailListMailboxID returns the mailbox feed a newsletter feed was split from
+func (db *DB) GetEmailListMailboxID(feedID int64) (int64, error) {
+	db.WaitForReady()
+	var id int64
+	err := db.QueryRow(`SELECT mailbox_feed_id FROM feed_email_lists WHERE feed_id = ?`, feedID).Scan(&id)
+	return id, err
+}
+
+// getEmailListFeedIDs returns the newsletter feeds a mailbox feed was split into
+func (db *DB) getEmailListFeedIDs(mailboxFeedID int64) ([]int64, error) {
+	rows, err := db.Query(`SELECT feed_id FROM feed_email_lists WHERE mailbox_feed_id = ?`, mailboxFeedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestFeedEmailSettings(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Newsletters", URL: "email://me@example.com", Type: models.FeedTypeEmail, EmailAddress: "me@example.com"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	invalid := []*models.FeedEmailSettings{
+		{FeedID: feedID, Filters: []models.EmailFilter{{Field: "body", Operator: "contains", Value: "x"}}},
+		{FeedID: feedID, Filters: []models.EmailFilter{{Field: "sender", Operator: "regex", Value: "("}}},
+	}
+	for _, settings := range invalid {
+		if err := db.SetFeedEmailSettings(settings); !errors.Is(err, ErrInvalidEmailFilter) {
+			t.Errorf("filters %+v: err = %v, want ErrInvalidEmailFilter", settings.Filters, err)
+		}
+	}
+	if err := db.SetFeedEmailSettings(&models.FeedEmailSettings{FeedID: feedID, ProcessedAction: "move"}); err != ErrInvalidProcessedAction {
+		t.Errorf("move without folder: err = %v, want ErrInvalidProcessedAction", err)
+	}
+
+	settings := &models.FeedEmailSettings{
+		FeedID: feedID,
+		Idle:   true,
+		Filters: []models.EmailFilter{
+			{Field: " Sender ", Operator: "Contains", Value: "@weekly.example.com"},
+			{Field: "subject", Operator: "exact", Value: " "},
+		},
+		ProcessedAction: "move",
+		MoveFolder:      " Newsletters ",
+	}
+	if err := db.SetFeedEmailSettings(settings); err != nil {
+		t.Fatalf("SetFeedEmailSettings error: %v", err)
+	}
+	got, err := db.GetFeedEmailSettings(feedID)
+	if err != nil || got == nil {
+		t.Fatalf("GetFeedEmailSettings error: %v (settings %v)", err, got)
+	}
+	if !got.Idle || len(got.Filters) != 1 || got.Filters[0].Field != models.EmailFilterSender ||
+		got.Filters[0].Operator != models.EmailFilterContains || got.MoveFolder != "Newsletters" {
+		t.Errorf("unexpected settings: %+v", got)
+	}
+	if ids, err := db.GetIdleEmailFeedIDs(); err != nil || !slices.Equal(ids, []int64{feedID}) {
+		t.Errorf("IDLE feeds = %v, %v", ids, err)
+	}
+
+	// Empty settings remove the entry
+	if err := db.SetFeedEmailSettings(&models.FeedEmailSettings{FeedID: feedID}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.GetFeedEmailSettings(feedID); err != nil || got != nil {
+		t.Errorf("settings after reset = %v, %v, want none", got, err)
+	}
+
+	// Newsletter feeds are linked to their mailbox and removed with it
+	mailbox, _ := db.GetFeedByID(feedID)
+	listFeedID, err := db.AddEmailListFeed(mailbox, "weekly.example.com", "Weekly News")
+	if err != nil {
+		t.Fatalf("AddEmailListFeed error: %v", err)
+	}
+	if id, _ := db.GetEmailListFeedID(feedID, "weekly.example.com"); id != listFeedID {
+		t.Errorf("newsletter feed = %d, want %d", id, listFeedID)
+	}
+	if id, _ := db.GetEmailListMailboxID(listFeedID); id != feedID {
+		t.Errorf("mailbox feed = %d, want %d", id, feedID)
+	}
+
+	// Deleted newsletter feeds are remembered until the mailbox stops being split
+	db.SetFeedEmailSettings(&models.FeedEmailSettings{FeedID: feedID, SplitByList: true})
+	if err := db.DeleteFeed(listFeedID); err != nil {
+		t.Fatal(err)
+	}
+	if removed, _ := db.IsEmailListRemoved(feedID, "weekly.example.com"); !removed {
+		t.Error("deleted newsletter feed not remembered")
+	}
+	db.SetFeedEmailSettings(&models.FeedEmailSettings{FeedID: feedID})
+	if removed, _ := db.IsEmailListRemoved(feedID, "weekly.example.com"); removed {
+		t.Error("deleted newsletter feed still remembered without splitting")
+	}
+	if listFeedID, err = db.AddEmailListFeed(mailbox, "weekly.example.com", "Weekly News"); err != nil {
+		t.Fatalf("AddEmailListFeed error: %v", err)
+	}
+	if err := db.DeleteFeed(feedID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetFeedByID(listFeedID); err == nil {
+		t.Error("newsletter feed outlived its mailbox")
+	}
+	if id, _ := db.GetEmailListFeedID(feedID, "weekly.example.com"); id != 0 {
+		t.Errorf("newsletter link of a deleted feed: %d", id)
+	}
+}
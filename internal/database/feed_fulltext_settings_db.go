@@ -0,0 +1,105 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"MrRSS/internal/models"
+)
+
+// ErrInvalidFullTextMode is returned for an unsupported full-text mode
+var ErrInvalidFullTextMode = errors.New("full-text mode must be empty, \"never\", \"on_open\" or \"eager\"")
+
+// InitFeedFullTextSettingsTable creates the feed_fulltext_settings table if it doesn't exist
+func InitFeedFullTextSettingsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS feed_fulltext_settings (
+		feed_id INTEGER PRIMARY KEY,
+		mode TEXT NOT NULL DEFAULT '',
+		content_selector TEXT NOT NULL DEFAULT '',
+		strip_selectors TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TRIGGER IF NOT EXISTS feed_fulltext_settings_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM feed_fulltext_settings WHERE feed_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetFeedFullTextSettings returns the full-text settings of a feed, or nil if it has none.
+func (db *DB) GetFeedFullTextSettings(feedID int64) (*models.FeedFullTextSettings, error) {
+	db.WaitForReady()
+	var s models.FeedFullTextSettings
+	var stripSelectors string
+	err := db.QueryRow(`SELECT feed_id, mode, content_selector, strip_selectors
+		FROM feed_fulltext_settings WHERE feed_id = ?`, feedID).
+		Scan(&s.FeedID, &s.Mode, &s.ContentSelector, &stripSelectors)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stripSelectors != "" {
+		if err := json.Unmarshal([]byte(stripSelectors), &s.StripSelectors); err != nil {
+			log.Printf("Error parsing strip selectors of feed %d: %v", feedID, err)
+		}
+	}
+	return &s, nil
+}
+
+// GetFeedFullTextMode returns the full-text mode of a feed
+func (db *DB) GetFeedFullTextMode(feedID int64) string {
+	settings, err := db.GetFeedFullTextSettings(feedID)
+	if err != nil || settings == nil {
+		return models.FullTextModeDefault
+	}
+	return settings.Mode
+}
+
+// SetFeedFullTextSettings stores the full-text settings of a feed. Empty settings remove
+// the feed's entry.
+func (db *DB) SetFeedFullTextSettings(settings *models.FeedFullTextSettings) error {
+	db.WaitForReady()
+
+	settings.Mode = strings.ToLower(strings.TrimSpace(settings.Mode))
+	switch settings.Mode {
+	case models.FullTextModeDefault, models.FullTextModeNever, models.FullTextModeOnOpen, models.FullTextModeEager:
+	default:
+		return ErrInvalidFullTextMode
+	}
+	settings.ContentSelector = strings.TrimSpace(settings.ContentSelector)
+	stripSelectors := make([]string, 0, len(settings.StripSelectors))
+	for _, selector := range settings.StripSelectors {
+		if selector = strings.TrimSpace(selector); selector != "" {
+			stripSelectors = append(stripSelectors, selector)
+		}
+	}
+	settings.StripSelectors = stripSelectors
+
+	if settings.IsEmpty() {
+		_, err := db.Exec(`DELETE FROM feed_fulltext_settings WHERE feed_id = ?`, settings.FeedID)
+		return err
+	}
+
+	var strip string
+	if len(settings.StripSelectors) > 0 {
+		data, err := json.Marshal(settings.StripSelectors)
+		if err != nil {
+			return err
+		}
+		strip = string(data)
+	}
+
+	_, err := db.Exec(`INSERT OR REPLACE INTO feed_fulltext_settings
+		(feed_id, mode, content_selector, strip_selectors) VALUES (?, ?, ?, ?)`,
+		settings.FeedID, settings.Mode, settings.ContentSelector, strip)
+	return err
+}
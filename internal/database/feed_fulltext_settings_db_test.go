@@ -0,0 +1,66 @@
+package database
+
+import (
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestFeedFullTextSettings(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Teasers", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	if err := db.SetFeedFullTextSettings(&models.FeedFullTextSettings{FeedID: feedID, Mode: "always"}); err != ErrInvalidFullTextMode {
+		t.Errorf("invalid mode: err = %v, want ErrInvalidFullTextMode", err)
+	}
+
+	settings := &models.FeedFullTextSettings{
+		FeedID:          feedID,
+		Mode:            " Eager ",
+		ContentSelector: "article .body",
+		StripSelectors:  []string{".share", " ", "//aside"},
+	}
+	if err := db.SetFeedFullTextSettings(settings); err != nil {
+		t.Fatalf("SetFeedFullTextSettings error: %v", err)
+	}
+
+	got, err := db.GetFeedFullTextSettings(feedID)
+	if err != nil || got == nil {
+		t.Fatalf("GetFeedFullTextSettings error: %v (settings %v)", err, got)
+	}
+	if got.Mode != models.FullTextModeEager || got.ContentSelector != "article .body" ||
+		len(got.StripSelectors) != 2 || got.StripSelectors[1] != "//aside" {
+		t.Errorf("unexpected settings: %+v", got)
+	}
+	if mode := db.GetFeedFullTextMode(feedID); mode != models.FullTextModeEager {
+		t.Errorf("mode = %q, want eager", mode)
+	}
+
+	// Empty settings remove the entry
+	if err := db.SetFeedFullTextSettings(&models.FeedFullTextSettings{FeedID: feedID}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.GetFeedFullTextSettings(feedID); err != nil || got != nil {
+		t.Errorf("settings after reset = %v, %v, want none", got, err)
+	}
+
+	// Settings are removed with their feed
+	db.SetFeedFullTextSettings(&models.FeedFullTextSettings{FeedID: feedID, Mode: models.FullTextModeNever})
+	if err := db.DeleteFeed(feedID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := db.GetFeedFullTextSettings(feedID); got != nil {
+		t.Errorf("settings of a deleted feed: %+v", got)
+	}
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"MrRSS/internal/models"
+)
+
+// ErrInvalidAuthType is returned for an unsupported feed authentication type
+var ErrInvalidAuthType = errors.New("auth type must be empty, \"basic\" or \"bearer\"")
+
+// InitFeedHTTPSettingsTable creates the feed_http_settings table if it doesn't exist
+func InitFeedHTTPSettingsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS feed_http_settings (
+		feed_id INTEGER PRIMARY KEY,
+		user_agent TEXT NOT NULL DEFAULT '',
+		headers TEXT NOT NULL DEFAULT '',
+		cookie TEXT NOT NULL DEFAULT '',
+		auth_type TEXT NOT NULL DEFAULT '',
+		auth_username TEXT NOT NULL DEFAULT '',
+		auth_secret TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TRIGGER IF NOT EXISTS feed_http_settings_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM feed_http_settings WHERE feed_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetFeedHTTPSettings returns the request settings of a feed, or nil if it has none.
+func (db *DB) GetFeedHTTPSettings(feedID int64) (*models.FeedHTTPSettings, error) {
+	db.WaitForReady()
+	row := db.QueryRow(`SELECT feed_id, user_agent, headers, cookie, auth_type, auth_username, auth_secret
+		FROM feed_http_settings WHERE feed_id = ?`, feedID)
+	settings, err := db.scanFeedHTTPSettings(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return settings, err
+}
+
+// GetAllFeedHTTPSettings returns the request settings of all feeds that have any, by feed ID.
+func (db *DB) GetAllFeedHTTPSettings() (map[int64]*models.FeedHTTPSettings, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT feed_id, user_agent, headers, cookie, auth_type, auth_username, auth_secret
+		FROM feed_http_settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]*models.FeedHTTPSettings)
+	for rows.Next() {
+		settings, err := db.scanFeedHTTPSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[settings.FeedID] = settings
+	}
+	return result, rows.Err()
+}
+
+// SetFeedHTTPSettings stores the request settings of a feed, encrypting the cookie and
+// auth secret. Empty settings remove the feed's entry.
+func (db *DB) SetFeedHTTPSettings(settings *models.FeedHTTPSettings) error {
+	db.WaitForReady()
+
+	settings.AuthType = strings.ToLower(strings.TrimSpace(settings.AuthType))
+	switch settings.AuthType {
+	case "", "basic", "bearer":
+	default:
+		return ErrInvalidAuthType
+	}
+
+	if settings.IsEmpty() {
+		_, err := db.Exec(`DELETE FROM feed_http_settings WHERE feed_id = ?`, settings.FeedID)
+		return err
+	}
+
+	var headers string
+	if len(settings.Headers) > 0 {
+		data, err := json.Marshal(settings.Headers)
+		if err != nil {
+			return err
+		}
+		headers = string(data)
+	}
+
+	cookie, err := db.EncryptSecret(settings.Cookie)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt cookie: %w", err)
+	}
+	authSecret, err := db.EncryptSecret(settings.AuthSecret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt auth secret: %w", err)
+	}
+
+	_, err = db.Exec(`INSERT OR REPLACE INTO feed_http_settings
+		(feed_id, user_agent, headers, cookie, auth_type, auth_username, auth_secret)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		settings.FeedID, settings.UserAgent, headers, cookie, settings.AuthType, settings.AuthUsername, authSecret)
+	return err
+}
+
+func (db *DB) scanFeedHTTPSettings(row rowScanner) (*models.FeedHTTPSettings, error) {
+	var s models.FeedHTTPSettings
+	var headers, cookie, authSecret string
+	if err := row.Scan(&s.FeedID, &s.UserAgent, &headers, &cookie, &s.AuthType, &s.AuthUsername, &authSecret); err != nil {
+		return nil, err
+	}
+
+	if headers != "" {
+		if err := json.Unmarshal([]byte(headers), &s.Headers); err != nil {
+			log.Printf("Error parsing HTTP headers of feed %d: %v", s.FeedID, err)
+		}
+	}
+	s.Cookie = db.decryptOptional(cookie, s.FeedID)
+	s.AuthSecret = db.decryptOptional(authSecret, s.FeedID)
+	return &s, nil
+}
+
+// decryptOptional decrypts a stored secret, returning an empty string if it can't be decrypted
+func (db *DB) decryptOptional(value string, feedID int64) string {
+	decrypted, err := db.DecryptSecret(value)
+	if err != nil {
+		log.Printf("Warning: Failed to decrypt HTTP secret of feed %d: %v", feedID, err)
+		return ""
+	}
+	return decrypted
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestFeedHTTPSettings(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Private", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	settings := &models.FeedHTTPSettings{
+		FeedID:       feedID,
+		UserAgent:    "MrRSS-Test",
+		Headers:      map[string]string{"X-Api-Key": "abc"},
+		Cookie:       "session=secret",
+		AuthType:     "Bearer",
+		AuthSecret:   "token-123",
+		AuthUsername: "",
+	}
+	if err := db.SetFeedHTTPSettings(settings); err != nil {
+		t.Fatalf("SetFeedHTTPSettings error: %v", err)
+	}
+
+	// Secrets must not be stored in plain text
+	var cookie, secret string
+	if err := db.QueryRow(`SELECT cookie, auth_secret FROM feed_http_settings WHERE feed_id = ?`, feedID).Scan(&cookie, &secret); err != nil {
+		t.Fatalf("query error: %v", err)
+	}
+	if cookie == "session=secret" || secret == "token-123" {
+		t.Error("expected cookie and auth secret to be encrypted at rest")
+	}
+
+	got, err := db.GetFeedHTTPSettings(feedID)
+	if err != nil || got == nil {
+		t.Fatalf("GetFeedHTTPSettings error: %v (settings %v)", err, got)
+	}
+	if got.UserAgent != "MrRSS-Test" || got.Headers["X-Api-Key"] != "abc" ||
+		got.Cookie != "session=secret" || got.AuthType != "bearer" || got.AuthSecret != "token-123" {
+		t.Errorf("unexpected settings after round trip: %+v", got)
+	}
+
+	all, err := db.GetAllFeedHTTPSettings()
+	if err != nil || len(all) != 1 || all[feedID] == nil {
+		t.Errorf("GetAllFeedHTTPSettings = %v, %v", all, err)
+	}
+
+	if err := db.SetFeedHTTPSettings(&models.FeedHTTPSettings{FeedID: feedID, AuthType: "digest"}); err != ErrInvalidAuthType {
+		t.Errorf("expected ErrInvalidAuthType, got %v", err)
+	}
+
+	// Empty settings remove the entry
+	if err := db.SetFeedHTTPSettings(&models.FeedHTTPSettings{FeedID: feedID}); err != nil {
+		t.Fatalf("SetFeedHTTPSettings (empty) error: %v", err)
+	}
+	if got, _ := db.GetFeedHTTPSettings(feedID); got != nil {
+		t.Errorf("expected settings to be removed, got %+v", got)
+	}
+
+	// Deleting the feed removes its settings
+	if err := db.SetFeedHTTPSettings(settings); err != nil {
+		t.Fatalf("SetFeedHTTPSettings error: %v", err)
+	}
+	if err := db.DeleteFeed(feedID); err != nil {
+		t.Fatalf("DeleteFeed error: %v", err)
+	}
+	if got, _ := db.GetFeedHTTPSettings(feedID); got != nil {
+		t.Errorf("expected settings to be removed with the feed, got %+v", got)
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// fetchLogPerFeed is the number of refresh attempts kept per feed
+const fetchLogPerFeed = 100
+
+// InitFetchLogTable creates the fetch_log table if it doesn't exist
+func InitFetchLogTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS fetch_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_id INTEGER NOT NULL,
+		fetched_at DATETIME NOT NULL,
+		duration_ms INTEGER NOT NULL DEFAULT 0,
+		http_status INTEGER NOT NULL DEFAULT 0,
+		bytes INTEGER NOT NULL DEFAULT 0,
+		new_items INTEGER NOT NULL DEFAULT 0,
+		updated_items INTEGER NOT NULL DEFAULT 0,
+		error_class TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_fetch_log_feed ON fetch_log(feed_id, id DESC);
+
+	CREATE TRIGGER IF NOT EXISTS fetch_log_feed_delete AFTER DELETE ON feeds BEGIN
+		DELETE FROM fetch_log WHERE feed_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// AddFetchLogEntry records a refresh attempt, keeping only the latest attempts of each feed.
+func (db *DB) AddFetchLogEntry(entry *models.FetchLogEntry) error {
+	db.WaitForReady()
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = time.Now()
+	}
+
+	result, err := db.Exec(`INSERT INTO fetch_log
+		(feed_id, fetched_at, duration_ms, http_status, bytes, new_items, updated_items, error_class, error, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.FeedID, entry.FetchedAt.UTC(), entry.DurationMs, entry.HTTPStatus, entry.Bytes,
+		entry.NewItems, entry.UpdatedItems, entry.ErrorClass, entry.Error, entry.Reason)
+	if err != nil {
+		return err
+	}
+	entry.ID, _ = result.LastInsertId()
+
+	_, err = db.Exec(`DELETE FROM fetch_log WHERE feed_id = ? AND id <= (
+		SELECT id FROM fetch_log WHERE feed_id = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		entry.FeedID, entry.FeedID, fetchLogPerFeed)
+	return err
+}
+
+// GetFetchLog returns the latest refresh attempts of a feed, newest first.
+func (db *DB) GetFetchLog(feedID int64, limit int) ([]models.FetchLogEntry, error) {
+	db.WaitForReady()
+	if limit <= 0 || limit > fetchLogPerFeed {
+		limit = fetchLogPerFeed
+	}
+
+	rows, err := db.Query(`SELECT id, feed_id, fetched_at, duration_ms, http_status, bytes,
+		new_items, updated_items, error_class, error, reason
+		FROM fetch_log WHERE feed_id = ? ORDER BY id DESC LIMIT ?`, feedID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.FetchLogEntry, 0)
+	for rows.Next() {
+		var e models.FetchLogEntry
+		if err := rows.Scan(&e.ID, &e.FeedID, &e.FetchedAt, &e.DurationMs, &e.HTTPStatus, &e.Bytes,
+			&e.NewItems, &e.UpdatedItems, &e.ErrorClass, &e.Error, &e.Reason); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetFeedHealth summarizes the fetch log of a feed. recent is the number of latest
+// attempts included in the result. It returns sql.ErrNoRows if the feed doesn't exist.
+func (db *DB) GetFeedHealth(feedID int64, recent int) (*models.FeedHealth, error) {
+	db.WaitForReady()
+
+	health := &models.FeedHealth{FeedID: feedID, ErrorClasses: make(map[string]int)}
+	var failingSince sql.NullTime
+	if err := db.QueryRow("SELECT failing_since, COALESCE(is_dead, 0) FROM feeds WHERE id = ?", feedID).
+		Scan(&failingSince, &health.IsDead); err != nil {
+		return nil, err
+	}
+	if failingSince.Valid {
+		health.FailingSince = &failingSince.Time
+	}
+
+	entries, err := db.GetFetchLog(feedID, fetchLogPerFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalDuration int64
+	streakOpen := true
+	for i := range entries {
+		e := &entries[i]
+		health.Attempts++
+		totalDuration += e.DurationMs
+		if e.ErrorClass == "" {
+			health.Successes++
+			if health.LastSuccessAt == nil {
+				health.LastSuccessAt = &e.FetchedAt
+			}
+			streakOpen = false
+		} else {
+			health.ErrorClasses[e.ErrorClass]++
+			if streakOpen {
+				health.ConsecutiveFailures++
+			}
+		}
+	}
+	if health.Attempts > 0 {
+		health.SuccessRatio = float64(health.Successes) / float64(health.Attempts)
+		health.MeanLatencyMs = float64(totalDuration) / float64(health.Attempts)
+	}
+
+	if recent > len(entries) {
+		recent = len(entries)
+	}
+	if recent < 0 {
+		recent = 0
+	}
+	health.Recent = entries[:recent]
+	return health, nil
+}
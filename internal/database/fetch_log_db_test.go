@@ -0,0 +1,92 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestFetchLogBoundAndHealth(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+
+	for i := 0; i < 105; i++ {
+		entry := &models.FetchLogEntry{FeedID: feedID, DurationMs: 10, HTTPStatus: 200, NewItems: 1, Reason: "scheduled_global"}
+		if err := db.AddFetchLogEntry(entry); err != nil {
+			t.Fatalf("AddFetchLogEntry error: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		db.AddFetchLogEntry(&models.FetchLogEntry{FeedID: feedID, DurationMs: 40, ErrorClass: "dns", Error: "no such host"})
+	}
+
+	entries, err := db.GetFetchLog(feedID, 0)
+	if err != nil {
+		t.Fatalf("GetFetchLog error: %v", err)
+	}
+	if len(entries) != 100 {
+		t.Fatalf("expected the log to be bounded to 100 entries, got %d", len(entries))
+	}
+	if entries[0].ErrorClass != "dns" || entries[0].FetchedAt.IsZero() {
+		t.Errorf("expected newest entry first, got %+v", entries[0])
+	}
+
+	health, err := db.GetFeedHealth(feedID, 5)
+	if err != nil {
+		t.Fatalf("GetFeedHealth error: %v", err)
+	}
+	if health.Attempts != 100 || health.Successes != 97 || health.ConsecutiveFailures != 3 {
+		t.Errorf("unexpected counts: %+v", health)
+	}
+	if health.SuccessRatio != 0.97 || health.MeanLatencyMs != 10.9 {
+		t.Errorf("unexpected ratio/latency: %v / %v", health.SuccessRatio, health.MeanLatencyMs)
+	}
+	if health.ErrorClasses["dns"] != 3 || len(health.Recent) != 5 || health.LastSuccessAt == nil {
+		t.Errorf("unexpected details: %+v", health)
+	}
+
+	if _, err := db.GetFeedHealth(feedID+1000, 5); err == nil {
+		t.Error("expected an error for an unknown feed")
+	}
+
+	// Deleting the feed removes its log
+	if err := db.DeleteFeed(feedID); err != nil {
+		t.Fatalf("DeleteFeed error: %v", err)
+	}
+	if entries, _ := db.GetFetchLog(feedID, 0); len(entries) != 0 {
+		t.Errorf("expected fetch log to be removed with the feed, got %d entries", len(entries))
+	}
+}
+
+func TestDeadFeedFlag(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+
+	if _, err := db.RecordFeedFailure(feedID, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("RecordFeedFailure error: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if flagged, err := db.UpdateDeadFeeds(time.Hour); err != nil || flagged != 0 {
+		t.Errorf("expected no dead feeds yet, got %d (%v)", flagged, err)
+	}
+	if flagged, err := db.UpdateDeadFeeds(10 * time.Millisecond); err != nil || flagged != 1 {
+		t.Errorf("expected 1 newly dead feed, got %d (%v)", flagged, err)
+	}
+	feed, _ := db.GetFeedByID(feedID)
+	if !feed.IsDead || feed.FailingSince == nil {
+		t.Errorf("expected feed to be flagged dead, got is_dead=%v failing_since=%v", feed.IsDead, feed.FailingSince)
+	}
+
+	// A longer threshold unflags it again, and so does a success
+	db.UpdateDeadFeeds(time.Hour)
+	if feed, _ = db.GetFeedByID(feedID); feed.IsDead {
+		t.Error("expected the flag to be cleared under a longer threshold")
+	}
+	db.UpdateDeadFeeds(10 * time.Millisecond)
+	if err := db.ResetFeedBackoff(feedID); err != nil {
+		t.Fatalf("ResetFeedBackoff error: %v", err)
+	}
+	if feed, _ = db.GetFeedByID(feedID); feed.IsDead || feed.FailingSince != nil {
+		t.Errorf("expected success to clear the dead flag, got is_dead=%v failing_since=%v", feed.IsDead, feed.FailingSince)
+	}
+}
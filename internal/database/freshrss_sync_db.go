@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,32 @@ const (
 	SyncActionUnstar     SyncAction = "unstar"
 )
 
+// Label sync actions carry the user tag name after the prefix, e.g. "add_label:to-cite"
+const (
+	syncActionAddLabelPrefix    = "add_label:"
+	syncActionRemoveLabelPrefix = "remove_label:"
+)
+
+// LabelSyncAction returns the sync action that adds or removes a user label
+func LabelSyncAction(label string, add bool) SyncAction {
+	if add {
+		return SyncAction(syncActionAddLabelPrefix + label)
+	}
+	return SyncAction(syncActionRemoveLabelPrefix + label)
+}
+
+// Label returns the label name of a label sync action and whether it adds the label.
+// ok is false for non-label actions.
+func (a SyncAction) Label() (label string, add bool, ok bool) {
+	if label, found := strings.CutPrefix(string(a), syncActionAddLabelPrefix); found {
+		return label, true, true
+	}
+	if label, found := strings.CutPrefix(string(a), syncActionRemoveLabelPrefix); found {
+		return label, false, true
+	}
+	return "", false, false
+}
+
 // SyncQueueItem represents an item in the FreshRSS sync queue
 type SyncQueueItem struct {
 	ID         int64
@@ -76,6 +103,17 @@ func (db *DB) EnqueueSyncChange(articleID int64, articleURL string, action SyncA
 	return nil
 }
 
+// EnqueueSyncRequests adds the state changes returned by the *WithSync methods to the sync queue,
+// so they are pushed to FreshRSS with the next sync
+func (db *DB) EnqueueSyncRequests(requests []SyncRequest) error {
+	for _, req := range requests {
+		if err := db.EnqueueSyncChange(req.ArticleID, req.ArticleURL, req.Action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetPendingSyncChanges retrieves all pending sync changes that haven't been synced yet
 func (db *DB) GetPendingSyncChanges(limit int) ([]SyncQueueItem, error) {
 	db.WaitForReady()
@@ -0,0 +1,400 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// deadNotificationsPerTarget is the number of dead deliveries kept for each notification target
+const deadNotificationsPerTarget = 100
+
+// InitNotificationTables creates the notification_targets, notification_queue and
+// notification_deliveries tables if they don't exist
+func InitNotificationTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS notification_targets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		type TEXT NOT NULL,
+		url TEXT NOT NULL DEFAULT '',
+		token TEXT NOT NULL DEFAULT '',
+		room TEXT NOT NULL DEFAULT '',
+		template TEXT NOT NULL DEFAULT '',
+		conditions TEXT NOT NULL DEFAULT '[]',
+		batch_window INTEGER NOT NULL DEFAULT 0,
+		min_interval INTEGER NOT NULL DEFAULT 0,
+		max_articles INTEGER NOT NULL DEFAULT 0,
+		last_sent_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS notification_queue (
+		target_id INTEGER NOT NULL,
+		article_id INTEGER NOT NULL,
+		queued_at DATETIME NOT NULL,
+		PRIMARY KEY (target_id, article_id)
+	);
+
+	CREATE TRIGGER IF NOT EXISTS notification_queue_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM notification_queue WHERE article_id = old.id;
+	END;
+
+	CREATE TABLE IF NOT EXISTS notification_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		target_id INTEGER NOT NULL,
+		article_ids TEXT NOT NULL DEFAULT '[]',
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		next_attempt_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notification_deliveries_status ON notification_deliveries(status, next_attempt_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+const notificationTargetColumns = `id, name, enabled, type, url, token, room, template, conditions,
+	batch_window, min_interval, max_articles, last_sent_at, created_at`
+
+// GetNotificationTargets returns all notification targets
+func (db *DB) GetNotificationTargets() ([]models.NotificationTarget, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT ` + notificationTargetColumns + ` FROM notification_targets ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := make([]models.NotificationTarget, 0)
+	for rows.Next() {
+		target, err := db.scanNotificationTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, *target)
+	}
+	return targets, rows.Err()
+}
+
+// GetNotificationTarget returns a notification target by ID. Returns sql.ErrNoRows if it doesn't exist.
+func (db *DB) GetNotificationTarget(id int64) (*models.NotificationTarget, error) {
+	db.WaitForReady()
+	row := db.QueryRow(`SELECT `+notificationTargetColumns+` FROM notification_targets WHERE id = ?`, id)
+	return db.scanNotificationTarget(row)
+}
+
+// CreateNotificationTarget inserts a notification target, encrypting its token, and returns its ID
+func (db *DB) CreateNotificationTarget(target models.NotificationTarget) (int64, error) {
+	db.WaitForReady()
+	token, conditions, err := db.notificationTargetValues(target)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(`INSERT INTO notification_targets (name, enabled, type, url, token, room, template,
+		conditions, batch_window, min_interval, max_articles, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		target.Name, target.Enabled, target.Type, target.URL, token, target.Room, target.Template,
+		conditions, target.BatchWindow, target.MinInterval, target.MaxArticles, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateNotificationTarget replaces the settings of a notification target. Articles it
+// already queued stay queued. Returns sql.ErrNoRows if the target doesn't exist.
+func (db *DB) UpdateNotificationTarget(target models.NotificationTarget) error {
+	db.WaitForReady()
+	token, conditions, err := db.notificationTargetValues(target)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`UPDATE notification_targets SET name = ?, enabled = ?, type = ?, url = ?, token = ?,
+		room = ?, template = ?, conditions = ?, batch_window = ?, min_interval = ?, max_articles = ? WHERE id = ?`,
+		target.Name, target.Enabled, target.Type, target.URL, token, target.Room, target.Template,
+		conditions, target.BatchWindow, target.MinInterval, target.MaxArticles, target.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteNotificationTarget deletes a notification target with its queued articles and
+// deliveries. Returns sql.ErrNoRows if it doesn't exist.
+func (db *DB) DeleteNotificationTarget(id int64) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM notification_targets WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec(`DELETE FROM notification_queue WHERE target_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM notification_deliveries WHERE target_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// QueueNotifications adds articles to the queue of a notification target. Articles that
+// are already queued keep their place.
+func (db *DB) QueueNotifications(targetID int64, articleIDs []int64, at time.Time) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, articleID := range articleIDs {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO notification_queue (target_id, article_id, queued_at) VALUES (?, ?, ?)`,
+			targetID, articleID, at.UTC()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetNotificationQueueStart returns when the oldest queued article of a notification
+// target was queued, or nil if its queue is empty
+func (db *DB) GetNotificationQueueStart(targetID int64) (*time.Time, error) {
+	db.WaitForReady()
+	// Not MIN(queued_at), aggregates return the stored text instead of a time
+	var queuedAt time.Time
+	err := db.QueryRow(`SELECT queued_at FROM notification_queue WHERE target_id = ?
+		ORDER BY queued_at LIMIT 1`, targetID).Scan(&queuedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &queuedAt, nil
+}
+
+// CreateNotificationDelivery moves up to limit of the oldest queued articles of a
+// notification target into a new pending delivery, due at once. It returns nil if the
+// queue is empty.
+func (db *DB) CreateNotificationDelivery(targetID int64, limit int, now time.Time) (*models.NotificationDelivery, error) {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT article_id FROM notification_queue WHERE target_id = ?
+		ORDER BY queued_at, article_id LIMIT ?`, targetID, limit)
+	if err != nil {
+		return nil, err
+	}
+	articleIDs := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		articleIDs = append(articleIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(articleIDs) == 0 {
+		return nil, nil
+	}
+
+	for _, articleID := range articleIDs {
+		if _, err := tx.Exec(`DELETE FROM notification_queue WHERE target_id = ? AND article_id = ?`, targetID, articleID); err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.Marshal(articleIDs)
+	if err != nil {
+		return nil, err
+	}
+	delivery := &models.NotificationDelivery{
+		TargetID:      targetID,
+		ArticleIDs:    articleIDs,
+		Status:        models.NotificationPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	result, err := tx.Exec(`INSERT INTO notification_deliveries (target_id, article_ids, status, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?)`, targetID, string(data), delivery.Status, now.UTC(), now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	delivery.ID, _ = result.LastInsertId()
+	return delivery, tx.Commit()
+}
+
+// GetDueNotificationDeliveries returns the pending deliveries whose next attempt is due, oldest first
+func (db *DB) GetDueNotificationDeliveries(now time.Time) ([]models.NotificationDelivery, error) {
+	db.WaitForReady()
+	return db.queryNotificationDeliveries(`SELECT id, target_id, article_ids, status, attempts, last_error,
+		next_attempt_at, created_at FROM notification_deliveries WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY id`, models.NotificationPending, now.UTC())
+}
+
+// CompleteNotificationDelivery removes a delivered message and records when its target last sent one
+func (db *DB) CompleteNotificationDelivery(delivery *models.NotificationDelivery, at time.Time) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM notification_deliveries WHERE id = ?`, delivery.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE notification_targets SET last_sent_at = ? WHERE id = ?`, at.UTC(), delivery.TargetID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateNotificationDelivery saves the attempts, error, status and next attempt of a
+// delivery. Dead deliveries beyond the dead-letter log's size are removed.
+func (db *DB) UpdateNotificationDelivery(delivery *models.NotificationDelivery) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE notification_deliveries SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?
+		WHERE id = ?`, delivery.Status, delivery.Attempts, delivery.LastError, delivery.NextAttemptAt.UTC(), delivery.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM notification_deliveries WHERE target_id = ? AND status = ? AND id <= (
+		SELECT id FROM notification_deliveries WHERE target_id = ? AND status = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		delivery.TargetID, models.NotificationDead, delivery.TargetID, models.NotificationDead, deadNotificationsPerTarget); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteNotificationDelivery removes a delivery. Returns sql.ErrNoRows if it doesn't exist.
+func (db *DB) DeleteNotificationDelivery(id int64) error {
+	db.WaitForReady()
+	result, err := db.Exec(`DELETE FROM notification_deliveries WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetDeadNotifications returns the dead-letter log of a notification target, or of all
+// targets if targetID is 0, newest first
+func (db *DB) GetDeadNotifications(targetID int64) ([]models.NotificationDelivery, error) {
+	db.WaitForReady()
+	return db.queryNotificationDeliveries(`SELECT id, target_id, article_ids, status, attempts, last_error,
+		next_attempt_at, created_at FROM notification_deliveries WHERE status = ? AND (? = 0 OR target_id = ?)
+		ORDER BY id DESC`, models.NotificationDead, targetID, targetID)
+}
+
+// RetryDeadNotification moves a dead delivery back to the pending deliveries with a fresh
+// set of attempts, due at once. Returns sql.ErrNoRows if there is no such dead delivery.
+func (db *DB) RetryDeadNotification(id int64, now time.Time) error {
+	db.WaitForReady()
+	result, err := db.Exec(`UPDATE notification_deliveries SET status = ?, attempts = 0, next_attempt_at = ?
+		WHERE id = ? AND status = ?`, models.NotificationPending, now.UTC(), id, models.NotificationDead)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (db *DB) queryNotificationDeliveries(query string, args ...interface{}) ([]models.NotificationDelivery, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.NotificationDelivery, 0)
+	for rows.Next() {
+		var d models.NotificationDelivery
+		var articleIDs string
+		if err := rows.Scan(&d.ID, &d.TargetID, &articleIDs, &d.Status, &d.Attempts, &d.LastError,
+			&d.NextAttemptAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(articleIDs), &d.ArticleIDs); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// notificationTargetValues returns the encrypted token and the encoded conditions of a target
+func (db *DB) notificationTargetValues(target models.NotificationTarget) (string, string, error) {
+	token, err := db.EncryptSecret(target.Token)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	if target.Conditions == nil {
+		target.Conditions = []models.RuleCondition{}
+	}
+	conditions, err := json.Marshal(target.Conditions)
+	if err != nil {
+		return "", "", err
+	}
+	return token, string(conditions), nil
+}
+
+func (db *DB) scanNotificationTarget(row rowScanner) (*models.NotificationTarget, error) {
+	var t models.NotificationTarget
+	var token, conditions string
+	var lastSent sql.NullTime
+	if err := row.Scan(&t.ID, &t.Name, &t.Enabled, &t.Type, &t.URL, &token, &t.Room, &t.Template, &conditions,
+		&t.BatchWindow, &t.MinInterval, &t.MaxArticles, &lastSent, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(conditions), &t.Conditions); err != nil {
+		return nil, err
+	}
+	decrypted, err := db.DecryptSecret(token)
+	if err != nil {
+		log.Printf("Warning: Failed to decrypt the token of notification target %d: %v", t.ID, err)
+	}
+	t.Token = decrypted
+	if lastSent.Valid {
+		t.LastSentAt = &lastSent.Time
+	}
+	return &t, nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+func TestNotifications(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer db.Close()
+
+	id, err := db.CreateNotificationTarget(models.NotificationTarget{
+		Name:       "Alerts",
+		Enabled:    true,
+		Type:       models.NotifyGotify,
+		URL:        "https://gotify.example.com",
+		Token:      "app-token",
+		Conditions: []models.RuleCondition{{Field: "article_title", Operator: "contains", Value: "release"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateNotificationTarget error: %v", err)
+	}
+	target, err := db.GetNotificationTarget(id)
+	if err != nil {
+		t.Fatalf("GetNotificationTarget error: %v", err)
+	}
+	if target.Name != "Alerts" || target.Token != "app-token" || len(target.Conditions) != 1 ||
+		target.Conditions[0].Value != "release" || target.LastSentAt != nil || target.CreatedAt.IsZero() {
+		t.Errorf("unexpected target: %+v", target)
+	}
+	var stored string
+	db.QueryRow(`SELECT token FROM notification_targets WHERE id = ?`, id).Scan(&stored)
+	if stored == "app-token" {
+		t.Error("token stored in plain text")
+	}
+	if err := db.UpdateNotificationTarget(models.NotificationTarget{ID: id + 1, Type: models.NotifySlack}); err != sql.ErrNoRows {
+		t.Errorf("updating a missing target: err = %v, want sql.ErrNoRows", err)
+	}
+
+	// Queued articles keep their place and leave the queue with their articles
+	feedID, _ := db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	for _, title := range []string{"A", "B", "C"} {
+		db.SaveArticle(&models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + title})
+	}
+	articles, _ := db.GetArticles("", feedID, "", true, 10, 0)
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+	a, b, c := articles[2].ID, articles[1].ID, articles[0].ID
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if err := db.QueueNotifications(id, []int64{a, b}, start); err != nil {
+		t.Fatalf("QueueNotifications error: %v", err)
+	}
+	db.QueueNotifications(id, []int64{b, c}, start.Add(time.Minute))
+	if queuedAt, err := db.GetNotificationQueueStart(id); err != nil || queuedAt == nil || !queuedAt.Equal(start) {
+		t.Errorf("GetNotificationQueueStart = %v, %v, want %v", queuedAt, err, start)
+	}
+	db.DeleteArticle(a)
+
+	delivery, err := db.CreateNotificationDelivery(id, 1, start.Add(time.Hour))
+	if err != nil || delivery == nil || len(delivery.ArticleIDs) != 1 || delivery.ArticleIDs[0] != b {
+		t.Fatalf("CreateNotificationDelivery = %+v, %v, want article %d", delivery, err, b)
+	}
+	if due, _ := db.GetDueNotificationDeliveries(start); len(due) != 0 {
+		t.Errorf("delivery due before it was created: %+v", due)
+	}
+	if due, _ := db.GetDueNotificationDeliveries(start.Add(time.Hour)); len(due) != 1 || due[0].ID != delivery.ID {
+		t.Errorf("GetDueNotificationDeliveries = %+v, want delivery %d", due, delivery.ID)
+	}
+
+	// Dead deliveries form the dead-letter log until they are retried or deleted
+	delivery.Status = models.NotificationDead
+	delivery.Attempts = 5
+	delivery.LastError = "gotify returned status 500"
+	if err := db.UpdateNotificationDelivery(delivery); err != nil {
+		t.Fatalf("UpdateNotificationDelivery error: %v", err)
+	}
+	if dead, _ := db.GetDeadNotifications(id); len(dead) != 1 || dead[0].LastError != delivery.LastError {
+		t.Errorf("GetDeadNotifications = %+v", dead)
+	}
+	if err := db.RetryDeadNotification(delivery.ID, start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("RetryDeadNotification error: %v", err)
+	}
+	due, _ := db.GetDueNotificationDeliveries(start.Add(2 * time.Hour))
+	if len(due) != 1 || due[0].Attempts != 0 || due[0].Status != models.NotificationPending {
+		t.Errorf("unexpected retried delivery: %+v", due)
+	}
+	if err := db.RetryDeadNotification(delivery.ID, start); err != sql.ErrNoRows {
+		t.Errorf("retrying a pending delivery: err = %v, want sql.ErrNoRows", err)
+	}
+
+	sentAt := start.Add(3 * time.Hour)
+	if err := db.CompleteNotificationDelivery(&due[0], sentAt); err != nil {
+		t.Fatalf("CompleteNotificationDelivery error: %v", err)
+	}
+	if target, _ = db.GetNotificationTarget(id); target.LastSentAt == nil || !target.LastSentAt.Equal(sentAt) {
+		t.Errorf("last send = %v, want %v", target.LastSentAt, sentAt)
+	}
+	if due, _ := db.GetDueNotificationDeliveries(sentAt); len(due) != 0 {
+		t.Errorf("completed delivery still pending: %+v", due)
+	}
+
+	// Deleting a target removes what is left of its queue
+	if err := db.DeleteNotificationTarget(id); err != nil {
+		t.Fatalf("DeleteNotificationTarget error: %v", err)
+	}
+	var queued int
+	db.QueryRow(`SELECT COUNT(*) FROM notification_queue`).Scan(&queued)
+	if queued != 0 {
+		t.Errorf("%d articles still queued", queued)
+	}
+	if _, err := db.GetNotificationTarget(id); err != sql.ErrNoRows {
+		t.Errorf("GetNotificationTarget after delete: err = %v, want sql.ErrNoRows", err)
+	}
+	if err := db.DeleteNotificationTarget(id); err != sql.ErrNoRows {
+		t.Errorf("deleting a missing target: err = %v, want sql.ErrNoRows", err)
+	}
+}
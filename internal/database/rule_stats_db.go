@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RuleStats holds the hit counter of an automation rule
+type RuleStats struct {
+	RuleID        int64      `json:"rule_id"`
+	HitCount      int64      `json:"hit_count"`
+	LastMatchedAt *time.Time `json:"last_matched_at"`
+}
+
+// InitRuleStatsTable creates the rule_stats table if it doesn't exist
+func InitRuleStatsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS rule_stats (
+		rule_id INTEGER PRIMARY KEY,
+		hit_count INTEGER NOT NULL DEFAULT 0,
+		last_matched_at DATETIME
+	);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// RecordRuleHits adds hits to a rule's counter and updates its last-matched time.
+func (db *DB) RecordRuleHits(ruleID int64, hits int, matchedAt time.Time) error {
+	db.WaitForReady()
+	if hits <= 0 {
+		return nil
+	}
+	_, err := db.Exec(`
+		INSERT INTO rule_stats (rule_id, hit_count, last_matched_at) VALUES (?, ?, ?)
+		ON CONFLICT(rule_id) DO UPDATE SET
+			hit_count = hit_count + excluded.hit_count,
+			last_matched_at = excluded.last_matched_at
+	`, ruleID, hits, matchedAt)
+	return err
+}
+
+// GetRuleStats returns the hit counters of all rules that have matched at least once.
+func (db *DB) GetRuleStats() ([]RuleStats, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT rule_id, hit_count, last_matched_at FROM rule_stats ORDER BY rule_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []RuleStats{}
+	for rows.Next() {
+		var s RuleStats
+		var lastMatched sql.NullTime
+		if err := rows.Scan(&s.RuleID, &s.HitCount, &lastMatched); err != nil {
+			return nil, err
+		}
+		if lastMatched.Valid {
+			t := lastMatched.Time
+			s.LastMatchedAt = &t
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// ResetRuleStats clears the hit counter of a rule.
+func (db *DB) ResetRuleStats(ruleID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM rule_stats WHERE rule_id = ?`, ruleID)
+	return err
+}
@@ -0,0 +1,612 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"reflect"
+	"strings"
+
+	"MrRSS/internal/models"
+)
+
+// ruleHistoryLimit is the number of versions kept per rule
+const ruleHistoryLimit = 50
+
+// rulesSettingKey is the legacy setting that stored all rules as one JSON array.
+// It is kept as a read-only mirror of the rules tables so settings clients keep working.
+const rulesSettingKey = "rules"
+
+// rulesValidator checks rules written through the legacy setting. The rules package
+// registers it, as this package can't import it.
+var rulesValidator func([]models.Rule) error
+
+// RegisterRulesValidator sets the validation ImportRulesJSON applies before replacing the rules.
+func RegisterRulesValidator(validate func([]models.Rule) error) {
+	rulesValidator = validate
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// InitRulesTables creates the rules, rule_conditions and rule_history tables if they don't exist
+func InitRulesTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		position INTEGER NOT NULL DEFAULT 0,
+		actions TEXT NOT NULL DEFAULT '[]',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS rule_conditions (
+		rule_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		condition_id INTEGER NOT NULL DEFAULT 0,
+		logic TEXT NOT NULL DEFAULT '',
+		negate BOOLEAN NOT NULL DEFAULT 0,
+		field TEXT NOT NULL,
+		operator TEXT NOT NULL DEFAULT '',
+		value TEXT NOT NULL DEFAULT '',
+		value_list TEXT,
+		PRIMARY KEY (rule_id, position)
+	);
+
+	CREATE TABLE IF NOT EXISTS rule_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		rule_id INTEGER NOT NULL,
+		action TEXT NOT NULL,
+		snapshot TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_rule_history_rule_id ON rule_history(rule_id, id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// migrateRulesAutoincrement recreates a rules table created without AUTOINCREMENT, so the
+// ID of a deleted rule is never given to a new rule that would inherit its history.
+// IDs already used in the history are reserved as well.
+func migrateRulesAutoincrement(db *sql.DB) error {
+	var tableSQL string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'rules'`).Scan(&tableSQL); err != nil {
+		return err
+	}
+	if strings.Contains(strings.ToUpper(tableSQL), "AUTOINCREMENT") {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`CREATE TABLE rules_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL DEFAULT '',
+			enabled BOOLEAN NOT NULL DEFAULT 1,
+			position INTEGER NOT NULL DEFAULT 0,
+			actions TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO rules_new (id, name, enabled, position, actions, created_at, updated_at)
+			SELECT id, name, enabled, position, actions, created_at, updated_at FROM rules`,
+		`DROP TABLE rules`,
+		`ALTER TABLE rules_new RENAME TO rules`,
+		`DELETE FROM sqlite_sequence WHERE name = 'rules'`,
+		`INSERT INTO sqlite_sequence (name, seq) SELECT 'rules', COALESCE(MAX(id), 0) FROM (
+			SELECT id FROM rules UNION ALL SELECT rule_id FROM rule_history
+		)`,
+	} {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// migrateRulesFromSettings moves rules stored in the legacy JSON setting into the rules tables.
+// It only runs while the rules table is empty, so it is a no-op after the first start.
+func migrateRulesFromSettings(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM rules`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var rulesJSON sql.NullString
+	err := db.QueryRow(`SELECT value FROM settings WHERE key = ?`, rulesSettingKey).Scan(&rulesJSON)
+	if err == sql.ErrNoRows || rulesJSON.String == "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var rules []models.Rule
+	if err := json.Unmarshal([]byte(rulesJSON.String), &rules); err != nil {
+		log.Printf("Error parsing legacy rules setting, skipping migration: %v", err)
+		return nil
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seen := make(map[int64]bool, len(rules))
+	for _, rule := range rules {
+		// Give rules with a duplicate ID a new one instead of failing the migration
+		if seen[rule.ID] {
+			rule.ID = 0
+		}
+		id, err := saveRule(tx, rule, "create")
+		if err != nil {
+			return err
+		}
+		seen[id] = true
+	}
+	if err := mirrorRulesSetting(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("Migrated %d rules from settings to the rules table", len(rules))
+	return nil
+}
+
+// GetRules returns all rules ordered by position.
+// The result is cached until the rules change and must not be modified by the caller.
+func (db *DB) GetRules() ([]models.Rule, error) {
+	db.WaitForReady()
+
+	db.rulesMu.RLock()
+	if db.rulesLoaded {
+		rules := db.rules
+		db.rulesMu.RUnlock()
+		return rules, nil
+	}
+	db.rulesMu.RUnlock()
+
+	db.rulesMu.Lock()
+	defer db.rulesMu.Unlock()
+	if !db.rulesLoaded {
+		rules, err := loadRules(db.DB)
+		if err != nil {
+			return nil, err
+		}
+		db.rules = rules
+		db.rulesLoaded = true
+	}
+	return db.rules, nil
+}
+
+// GetRule returns a rule by ID, or nil if it doesn't exist.
+func (db *DB) GetRule(id int64) (*models.Rule, error) {
+	rules, err := db.GetRules()
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].ID == id {
+			rule := rules[i]
+			return &rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateRule inserts a new rule and returns its ID.
+// A zero rule ID lets the database assign one.
+func (db *DB) CreateRule(rule models.Rule) (int64, error) {
+	var id int64
+	err := db.writeRules(func(tx *sql.Tx) error {
+		var err error
+		id, err = saveRule(tx, rule, "create")
+		return err
+	})
+	return id, err
+}
+
+// UpdateRule replaces a rule's name, state, position, conditions and actions.
+// Returns sql.ErrNoRows if the rule doesn't exist.
+func (db *DB) UpdateRule(rule models.Rule) error {
+	return db.writeRules(func(tx *sql.Tx) error {
+		existing, err := loadRule(tx, rule.ID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return sql.ErrNoRows
+		}
+		if reflect.DeepEqual(*existing, normalizeRule(rule)) {
+			return nil
+		}
+		_, err = saveRule(tx, rule, "update")
+		return err
+	})
+}
+
+// DeleteRule deletes a rule, its conditions and its hit counter. The deleted version is kept
+// in the history so it can be restored. Returns sql.ErrNoRows if the rule doesn't exist.
+func (db *DB) DeleteRule(id int64) error {
+	return db.writeRules(func(tx *sql.Tx) error {
+		existing, err := loadRule(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return sql.ErrNoRows
+		}
+		return deleteRule(tx, *existing)
+	})
+}
+
+// ReplaceRules replaces the whole rule set, e.g. when importing rules.
+// Only rules that actually changed are written and recorded in the history.
+func (db *DB) ReplaceRules(rules []models.Rule) error {
+	return db.writeRules(func(tx *sql.Tx) error {
+		existing, err := loadRules(tx)
+		if err != nil {
+			return err
+		}
+		existingByID := make(map[int64]models.Rule, len(existing))
+		for _, rule := range existing {
+			existingByID[rule.ID] = rule
+		}
+
+		keep := make(map[int64]bool, len(rules))
+		for _, rule := range rules {
+			action := "create"
+			if old, ok := existingByID[rule.ID]; ok && rule.ID != 0 {
+				if reflect.DeepEqual(old, normalizeRule(rule)) {
+					keep[rule.ID] = true
+					continue
+				}
+				action = "update"
+			}
+			id, err := saveRule(tx, rule, action)
+			if err != nil {
+				return err
+			}
+			keep[id] = true
+		}
+
+		for _, rule := range existing {
+			if !keep[rule.ID] {
+				if err := deleteRule(tx, rule); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ImportRulesJSON replaces the rule set with rules encoded as a JSON array.
+// An empty string clears all rules. Invalid rules are rejected and nothing is changed.
+func (db *DB) ImportRulesJSON(rulesJSON string) error {
+	var rules []models.Rule
+	if rulesJSON != "" {
+		if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+			return err
+		}
+	}
+	if rulesValidator != nil {
+		if err := rulesValidator(rules); err != nil {
+			return err
+		}
+	}
+	return db.ReplaceRules(rules)
+}
+
+// GetRuleHistory returns the recorded versions of a rule, newest first.
+// A zero ruleID returns the history of all rules.
+func (db *DB) GetRuleHistory(ruleID int64, limit int) ([]models.RuleHistoryEntry, error) {
+	db.WaitForReady()
+	if limit <= 0 {
+		limit = ruleHistoryLimit
+	}
+
+	query := `SELECT id, rule_id, action, snapshot, created_at FROM rule_history`
+	args := []interface{}{}
+	if ruleID != 0 {
+		query += ` WHERE rule_id = ?`
+		args = append(args, ruleID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.RuleHistoryEntry{}
+	for rows.Next() {
+		entry, err := scanRuleHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// RollbackRule restores the rule version recorded in a history entry, recreating the rule
+// if it was deleted. Returns sql.ErrNoRows if the history entry doesn't exist.
+func (db *DB) RollbackRule(historyID int64) (*models.Rule, error) {
+	var restored models.Rule
+	err := db.writeRules(func(tx *sql.Tx) error {
+		row := tx.QueryRow(`SELECT id, rule_id, action, snapshot, created_at FROM rule_history WHERE id = ?`, historyID)
+		entry, err := scanRuleHistoryEntry(row)
+		if err != nil {
+			return err
+		}
+
+		restored = normalizeRule(entry.Rule)
+		_, err = saveRule(tx, restored, "rollback")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// writeRules runs fn in a transaction, refreshes the legacy setting mirror and
+// invalidates the rules cache.
+func (db *DB) writeRules(fn func(tx *sql.Tx) error) error {
+	db.WaitForReady()
+
+	db.rulesMu.Lock()
+	defer db.rulesMu.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := mirrorRulesSetting(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	db.rules = nil
+	db.rulesLoaded = false
+	return nil
+}
+
+// saveRule inserts or replaces a rule with its conditions and records the new version
+// in the history. Returns the rule ID.
+func saveRule(tx *sql.Tx, rule models.Rule, action string) (int64, error) {
+	rule = normalizeRule(rule)
+
+	actionsJSON, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return 0, err
+	}
+
+	var ruleID interface{}
+	if rule.ID != 0 {
+		ruleID = rule.ID
+	}
+	switch action {
+	case "create":
+		result, err := tx.Exec(`INSERT INTO rules (id, name, enabled, position, actions) VALUES (?, ?, ?, ?, ?)`,
+			ruleID, rule.Name, rule.Enabled, rule.Position, string(actionsJSON))
+		if err != nil {
+			return 0, err
+		}
+		if rule.ID, err = result.LastInsertId(); err != nil {
+			return 0, err
+		}
+	default:
+		_, err := tx.Exec(`
+			INSERT INTO rules (id, name, enabled, position, actions) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				name = excluded.name,
+				enabled = excluded.enabled,
+				position = excluded.position,
+				actions = excluded.actions,
+				updated_at = CURRENT_TIMESTAMP
+		`, ruleID, rule.Name, rule.Enabled, rule.Position, string(actionsJSON))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM rule_conditions WHERE rule_id = ?`, rule.ID); err != nil {
+		return 0, err
+	}
+	for i, cond := range rule.Conditions {
+		var values interface{}
+		if cond.Values != nil {
+			valuesJSON, err := json.Marshal(cond.Values)
+			if err != nil {
+				return 0, err
+			}
+			values = string(valuesJSON)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO rule_conditions (rule_id, position, condition_id, logic, negate, field, operator, value, value_list)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, rule.ID, i, cond.ID, cond.Logic, cond.Negate, cond.Field, cond.Operator, cond.Value, values)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return rule.ID, recordRuleHistory(tx, rule, action)
+}
+
+// deleteRule removes a rule and records its last version in the history
+func deleteRule(tx *sql.Tx, rule models.Rule) error {
+	for _, query := range []string{
+		`DELETE FROM rule_conditions WHERE rule_id = ?`,
+		`DELETE FROM rule_stats WHERE rule_id = ?`,
+		`DELETE FROM rules WHERE id = ?`,
+	} {
+		if _, err := tx.Exec(query, rule.ID); err != nil {
+			return err
+		}
+	}
+	return recordRuleHistory(tx, rule, "delete")
+}
+
+// recordRuleHistory stores a rule version and prunes versions beyond ruleHistoryLimit
+func recordRuleHistory(tx *sql.Tx, rule models.Rule, action string) error {
+	snapshot, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO rule_history (rule_id, action, snapshot, created_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)`,
+		rule.ID, action, string(snapshot)); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		DELETE FROM rule_history WHERE rule_id = ? AND id NOT IN (
+			SELECT id FROM rule_history WHERE rule_id = ? ORDER BY id DESC LIMIT ?
+		)
+	`, rule.ID, rule.ID, ruleHistoryLimit)
+	return err
+}
+
+// mirrorRulesSetting writes the current rule set to the legacy rules setting
+func mirrorRulesSetting(tx *sql.Tx) error {
+	rules, err := loadRules(tx)
+	if err != nil {
+		return err
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)`, rulesSettingKey, string(rulesJSON))
+	return err
+}
+
+// loadRules reads all rules with their conditions, ordered by position
+func loadRules(q queryer) ([]models.Rule, error) {
+	rows, err := q.Query(`SELECT id, name, enabled, position, actions FROM rules ORDER BY position, id`)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []models.Rule{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		var rule models.Rule
+		var actionsJSON string
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Enabled, &rule.Position, &actionsJSON); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(actionsJSON), &rule.Actions); err != nil {
+			log.Printf("Error parsing actions of rule %d: %v", rule.ID, err)
+		}
+		rule = normalizeRule(rule)
+		index[rule.ID] = len(rules)
+		rules = append(rules, rule)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	condRows, err := q.Query(`
+		SELECT rule_id, condition_id, logic, negate, field, operator, value, value_list
+		FROM rule_conditions ORDER BY rule_id, position
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer condRows.Close()
+
+	for condRows.Next() {
+		var ruleID int64
+		var cond models.RuleCondition
+		var values sql.NullString
+		if err := condRows.Scan(&ruleID, &cond.ID, &cond.Logic, &cond.Negate, &cond.Field, &cond.Operator, &cond.Value, &values); err != nil {
+			return nil, err
+		}
+		if values.Valid {
+			if err := json.Unmarshal([]byte(values.String), &cond.Values); err != nil {
+				log.Printf("Error parsing condition values of rule %d: %v", ruleID, err)
+			}
+		}
+		if i, ok := index[ruleID]; ok {
+			rules[i].Conditions = append(rules[i].Conditions, cond)
+		}
+	}
+	return rules, condRows.Err()
+}
+
+// loadRule reads a single rule, or returns nil if it doesn't exist
+func loadRule(q queryer, id int64) (*models.Rule, error) {
+	rules, err := loadRules(q)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].ID == id {
+			return &rules[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// normalizeRule gives empty lists a single representation so stored and
+// submitted rules compare equal
+func normalizeRule(rule models.Rule) models.Rule {
+	if rule.Actions == nil {
+		rule.Actions = []string{}
+	}
+	conditions := make([]models.RuleCondition, len(rule.Conditions))
+	copy(conditions, rule.Conditions)
+	rule.Conditions = conditions
+	return rule
+}
+
+func scanRuleHistoryEntry(row rowScanner) (*models.RuleHistoryEntry, error) {
+	var entry models.RuleHistoryEntry
+	var snapshot string
+	var createdAt sql.NullTime
+	if err := row.Scan(&entry.ID, &entry.RuleID, &entry.Action, &snapshot, &createdAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(snapshot), &entry.Rule); err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		entry.CreatedAt = createdAt.Time
+	}
+	return &entry, nil
+}
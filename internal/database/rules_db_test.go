@@ -0,0 +1,182 @@
+package database_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	dbpkg "MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestRulesCRUDHistoryAndRollback(t *testing.T) {
+	db, _ := setupSearchDB(t)
+
+	rule := models.Rule{
+		Name:       "Go",
+		Enabled:    true,
+		Conditions: []models.RuleCondition{{Field: "article_title", Operator: "contains", Value: "go"}},
+		Actions:    []string{"favorite"},
+	}
+	id, err := db.CreateRule(rule)
+	if err != nil {
+		t.Fatalf("CreateRule error: %v", err)
+	}
+
+	rule.ID = id
+	rule.Conditions[0].Value = "rust"
+	if err := db.UpdateRule(rule); err != nil {
+		t.Fatalf("UpdateRule error: %v", err)
+	}
+
+	got, err := db.GetRule(id)
+	if err != nil || got == nil {
+		t.Fatalf("GetRule error: %v", err)
+	}
+	if got.Conditions[0].Value != "rust" {
+		t.Errorf("Expected updated condition value, got %q", got.Conditions[0].Value)
+	}
+
+	// The legacy setting mirrors the tables
+	rulesJSON, _ := db.GetSetting("rules")
+	var mirrored []models.Rule
+	if err := json.Unmarshal([]byte(rulesJSON), &mirrored); err != nil || len(mirrored) != 1 || mirrored[0].ID != id {
+		t.Errorf("Expected rules setting to mirror the rule, got %s", rulesJSON)
+	}
+
+	history, err := db.GetRuleHistory(id, 0)
+	if err != nil {
+		t.Fatalf("GetRuleHistory error: %v", err)
+	}
+	if len(history) != 2 || history[0].Action != "update" || history[1].Action != "create" {
+		t.Fatalf("Unexpected history: %+v", history)
+	}
+
+	if err := db.DeleteRule(id); err != nil {
+		t.Fatalf("DeleteRule error: %v", err)
+	}
+	if err := db.DeleteRule(id); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows deleting a missing rule, got %v", err)
+	}
+
+	// Rolling back to the first version recreates the deleted rule
+	restored, err := db.RollbackRule(history[1].ID)
+	if err != nil {
+		t.Fatalf("RollbackRule error: %v", err)
+	}
+	if restored.ID != id || restored.Conditions[0].Value != "go" {
+		t.Errorf("Unexpected restored rule: %+v", restored)
+	}
+	rules, _ := db.GetRules()
+	if len(rules) != 1 || rules[0].Conditions[0].Value != "go" {
+		t.Errorf("Expected restored rule in rule list, got %+v", rules)
+	}
+}
+
+func TestReplaceRulesOnlyRecordsChanges(t *testing.T) {
+	db, _ := setupSearchDB(t)
+
+	rules := []models.Rule{
+		{ID: 1, Name: "A", Enabled: true, Conditions: []models.RuleCondition{{Field: "feed_name", Values: []string{"Tech"}}}, Actions: []string{"mark_read"}, Position: 0},
+		{ID: 2, Name: "B", Enabled: true, Actions: []string{"hide"}, Position: 1},
+	}
+	if err := db.ReplaceRules(rules); err != nil {
+		t.Fatalf("ReplaceRules error: %v", err)
+	}
+
+	// Saving the same set again (as the settings page does) changes nothing
+	data, _ := json.Marshal(rules)
+	if err := db.SetSetting("rules", string(data)); err != nil {
+		t.Fatalf("SetSetting error: %v", err)
+	}
+	history, _ := db.GetRuleHistory(0, 0)
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history entries after an unchanged save, got %d", len(history))
+	}
+
+	// Dropping B and editing A records one delete and one update
+	rules[0].Enabled = false
+	if err := db.ReplaceRules(rules[:1]); err != nil {
+		t.Fatalf("ReplaceRules error: %v", err)
+	}
+	history, _ = db.GetRuleHistory(0, 0)
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 history entries, got %d", len(history))
+	}
+	actions := map[string]int64{history[0].Action: history[0].RuleID, history[1].Action: history[1].RuleID}
+	if actions["update"] != 1 || actions["delete"] != 2 {
+		t.Errorf("Unexpected history actions: %+v", history[:2])
+	}
+
+	got, _ := db.GetRules()
+	if len(got) != 1 || got[0].Enabled || got[0].Conditions[0].Values[0] != "Tech" {
+		t.Errorf("Unexpected rules after replace: %+v", got)
+	}
+}
+
+func TestDeletedRuleIDsAreNotReused(t *testing.T) {
+	path := t.TempDir() + "/rules.db"
+	db, err := dbpkg.NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	rule := models.Rule{Name: "Go", Enabled: true, Actions: []string{"favorite"}}
+	first, _ := db.CreateRule(rule)
+	second, _ := db.CreateRule(rule)
+	db.RecordRuleHits(second, 3, time.Now())
+	if err := db.DeleteRule(second); err != nil {
+		t.Fatalf("DeleteRule error: %v", err)
+	}
+	third, err := db.CreateRule(rule)
+	if err != nil {
+		t.Fatalf("CreateRule error: %v", err)
+	}
+	if third == second {
+		t.Errorf("new rule got the ID %d of the deleted rule", third)
+	}
+	if history, _ := db.GetRuleHistory(third, 0); len(history) != 1 || history[0].Action != "create" {
+		t.Errorf("new rule inherited history: %+v", history)
+	}
+
+	// A rules table created before AUTOINCREMENT is migrated, reserving the IDs of deleted rules
+	for _, query := range []string{
+		`DROP TABLE rules`,
+		`CREATE TABLE rules (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL DEFAULT '',
+			enabled BOOLEAN NOT NULL DEFAULT 1,
+			position INTEGER NOT NULL DEFAULT 0,
+			actions TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`DELETE FROM sqlite_sequence WHERE name = 'rules'`,
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	db.Exec(`INSERT INTO rules (id, name) VALUES (?, 'Go')`, first)
+	db.Exec(`INSERT INTO rule_history (rule_id, action, snapshot) VALUES (?, 'delete', '{}')`, third+5)
+	db.Close()
+
+	db, err = dbpkg.NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error after migration: %v", err)
+	}
+	defer db.Close()
+	if rules, _ := db.GetRules(); len(rules) != 1 || rules[0].ID != first {
+		t.Errorf("rules not kept by the migration: %+v", rules)
+	}
+	if id, _ := db.CreateRule(rule); id != third+6 {
+		t.Errorf("rule created after the migration got ID %d, want %d", id, third+6)
+	}
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/search"
+
+	sqlite "modernc.org/sqlite"
+)
+
+func init() {
+	// fts_text prepares text for the full-text index (HTML stripping and CJK segmentation).
+	// It is used by the articles_fts triggers and must be registered before any connection is opened.
+	sqlite.MustRegisterDeterministicScalarFunction("fts_text", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return search.PrepareForIndex(v), nil
+		case []byte:
+			return search.PrepareForIndex(string(v)), nil
+		default:
+			return "", nil
+		}
+	})
+}
+
+// ArticleSearchResult is an article matched by a full-text search
+type ArticleSearchResult struct {
+	models.Article
+	Snippet string  `json:"snippet"` // HTML snippet with <mark> highlights
+	Score   float64 `json:"score"`   // BM25 rank, lower is more relevant
+}
+
+// InitArticleSearchTable creates the articles_fts index and the triggers that keep it in sync.
+// The index is rebuilt from existing articles when it is empty.
+func InitArticleSearchTable(db *sql.DB) error {
+	query := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS articles_fts USING fts5(
+		title,
+		translated_title,
+		summary,
+		content,
+		tokenize = 'unicode61 remove_diacritics 2'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS articles_fts_insert AFTER INSERT ON articles BEGIN
+		INSERT INTO articles_fts(rowid, title, translated_title, summary, content)
+		VALUES (
+			new.id,
+			fts_text(new.title),
+			fts_text(new.translated_title),
+			fts_text(new.summary),
+			fts_text((SELECT content FROM article_contents WHERE article_id = new.id))
+		);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS articles_fts_update AFTER UPDATE OF title, translated_title, summary ON articles BEGIN
+		UPDATE articles_fts
+		SET title = fts_text(new.title),
+			translated_title = fts_text(new.translated_title),
+			summary = fts_text(new.summary)
+		WHERE rowid = new.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS articles_fts_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM articles_fts WHERE rowid = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS article_contents_fts_insert AFTER INSERT ON article_contents BEGIN
+		UPDATE articles_fts SET content = fts_text(new.content) WHERE rowid = new.article_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS article_contents_fts_update AFTER UPDATE OF content ON article_contents BEGIN
+		UPDATE articles_fts SET content = fts_text(new.content) WHERE rowid = new.article_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS article_contents_fts_delete AFTER DELETE ON article_contents BEGIN
+		UPDATE articles_fts SET content = '' WHERE rowid = old.article_id;
+	END;
+	`
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// Backfill the index for databases created before search was available
+	var indexed, total int64
+	if err := db.QueryRow(`SELECT COUNT(*) FROM articles_fts`).Scan(&indexed); err != nil {
+		return err
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM articles`).Scan(&total); err != nil {
+		return err
+	}
+	if indexed == 0 && total > 0 {
+		start := time.Now()
+		_, err := db.Exec(`
+			INSERT INTO articles_fts(rowid, title, translated_title, summary, content)
+			SELECT a.id, fts_text(a.title), fts_text(a.translated_title), fts_text(a.summary), fts_text(c.content)
+			FROM articles a
+			LEFT JOIN article_contents c ON c.article_id = a.id
+		`)
+		if err != nil {
+			return err
+		}
+		log.Printf("Built full-text search index for %d articles in %v", total, time.Since(start))
+	}
+
+	return nil
+}
+
+// SearchArticles runs a full-text search over article titles, translated titles, summaries
+// and cached content. Results are ordered by relevance. It also returns the total number
+// of matches for pagination. Hidden articles are excluded.
+func (db *DB) SearchArticles(query string, feedID int64, category string, limit, offset int) ([]ArticleSearchResult, int, error) {
+	db.WaitForReady()
+
+	match := search.BuildMatchQuery(query)
+	if match == "" {
+		return []ArticleSearchResult{}, 0, nil
+	}
+
+	whereClauses := []string{"articles_fts MATCH ?", "a.is_hidden = 0"}
+	args := []interface{}{match}
+
+	if feedID > 0 {
+		whereClauses = append(whereClauses, "a.feed_id = ?")
+		args = append(args, feedID)
+	}
+
+	if category != "" {
+		whereClauses = append(whereClauses, "(f.category = ? OR f.category LIKE ?)")
+		args = append(args, category, category+"/%")
+	}
+
+	from := `
+		FROM articles_fts
+		JOIN articles a ON a.id = articles_fts.rowid
+		JOIN feeds f ON a.feed_id = f.id
+		WHERE ` + strings.Join(whereClauses, " AND ")
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) `+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Column weights: title, translated_title, summary, content
+	selectQuery := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, COALESCE(a.author, ''), ` + articleCategoriesColumn + `, ` + articleTagsColumn + `,
+			snippet(articles_fts, -1, '` + search.SnippetStart + `', '` + search.SnippetEnd + `', '…', 16),
+			bm25(articles_fts, 10.0, 8.0, 3.0, 1.0) AS score
+	` + from + `
+		ORDER BY score, a.published_at DESC
+		LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(selectQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []ArticleSearchResult{}
+	for rows.Next() {
+		var r ArticleSearchResult
+		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, categories, tags, snippet sql.NullString
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&r.ID, &r.FeedID, &r.Title, &r.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &r.IsRead, &r.IsFavorite, &r.IsHidden, &r.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &r.FeedTitle, &r.Author, &categories, &tags, &snippet, &r.Score); err != nil {
+			log.Println("Error scanning search result:", err)
+			continue
+		}
+		r.ImageURL = imageURL.String
+		r.AudioURL = audioURL.String
+		r.VideoURL = videoURL.String
+		if publishedAt.Valid {
+			r.PublishedAt = publishedAt.Time
+		}
+		r.TranslatedTitle = translatedTitle.String
+		r.Summary = summary.String
+		r.Categories = splitArticleCategories(categories.String)
+		r.Tags = splitArticleCategories(tags.String)
+		r.FreshRSSItemID = freshrssItemID.String
+		r.Snippet = search.FormatSnippet(snippet.String)
+		results = append(results, r)
+	}
+	return results, total, rows.Err()
+}
@@ -0,0 +1,133 @@
+package database_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	dbpkg "MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func setupSearchDB(t *testing.T) (*dbpkg.DB, int64) {
+	t.Helper()
+	db, err := dbpkg.NewDB(t.TempDir() + "/search.db")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Search Feed", URL: "https://example.com/feed", Category: "tech"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	return db, feedID
+}
+
+func saveSearchArticle(t *testing.T, db *dbpkg.DB, feedID int64, title string) int64 {
+	t.Helper()
+	a := &models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + title, PublishedAt: time.Now()}
+	if err := db.SaveArticle(a); err != nil {
+		t.Fatalf("SaveArticle error: %v", err)
+	}
+	var id int64
+	if err := db.QueryRow(`SELECT id FROM articles WHERE title = ?`, title).Scan(&id); err != nil {
+		t.Fatalf("lookup article id: %v", err)
+	}
+	return id
+}
+
+func TestSearchArticlesTriggers(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+
+	id := saveSearchArticle(t, db, feedID, "Rust async runtime released")
+	saveSearchArticle(t, db, feedID, "Gardening tips for spring")
+
+	results, total, err := db.SearchArticles("async", 0, "", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchArticles error: %v", err)
+	}
+	if total != 1 || len(results) != 1 || results[0].ID != id {
+		t.Fatalf("expected one match for title search, got total=%d results=%+v", total, results)
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>async</mark>") {
+		t.Errorf("expected highlighted snippet, got %q", results[0].Snippet)
+	}
+
+	// Cached content is indexed through the article_contents triggers
+	if err := db.SetArticleContent(id, "<p>The scheduler uses <b>work stealing</b> queues</p>"); err != nil {
+		t.Fatalf("SetArticleContent error: %v", err)
+	}
+	if _, total, _ := db.SearchArticles(`"work stealing"`, 0, "", 10, 0); total != 1 {
+		t.Errorf("expected phrase match in cached content, got %d", total)
+	}
+	if _, total, _ := db.SearchArticles(`"stealing work"`, 0, "", 10, 0); total != 0 {
+		t.Errorf("expected no match for reversed phrase, got %d", total)
+	}
+
+	// Summary updates are picked up by the update trigger
+	if err := db.UpdateArticleSummary(id, "Benchmarks included"); err != nil {
+		t.Fatalf("UpdateArticleSummary error: %v", err)
+	}
+	if _, total, _ := db.SearchArticles("bench*", 0, "", 10, 0); total != 1 {
+		t.Errorf("expected prefix match in summary, got %d", total)
+	}
+
+	// Deleted articles are removed from the index
+	if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, id); err != nil {
+		t.Fatalf("delete article: %v", err)
+	}
+	if _, total, _ := db.SearchArticles("async", 0, "", 10, 0); total != 0 {
+		t.Errorf("expected deleted article to be removed from index, got %d", total)
+	}
+}
+
+func TestSearchArticlesRankingAndPagination(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+
+	titleMatch := saveSearchArticle(t, db, feedID, "Kubernetes operators explained")
+	summaryMatch := saveSearchArticle(t, db, feedID, "Weekly cloud digest")
+	if err := db.UpdateArticleSummary(summaryMatch, "A short note about kubernetes"); err != nil {
+		t.Fatalf("UpdateArticleSummary error: %v", err)
+	}
+
+	results, total, err := db.SearchArticles("kubernetes", 0, "", 1, 0)
+	if err != nil {
+		t.Fatalf("SearchArticles error: %v", err)
+	}
+	if total != 2 || len(results) != 1 {
+		t.Fatalf("expected first page of 1 out of 2, got total=%d len=%d", total, len(results))
+	}
+	if results[0].ID != titleMatch {
+		t.Errorf("expected title match to rank first, got article %d", results[0].ID)
+	}
+
+	results, _, _ = db.SearchArticles("kubernetes", 0, "", 1, 1)
+	if len(results) != 1 || results[0].ID != summaryMatch {
+		t.Errorf("expected summary match on second page, got %+v", results)
+	}
+
+	if _, total, _ := db.SearchArticles("kubernetes", 0, "other", 10, 0); total != 0 {
+		t.Errorf("expected category filter to exclude results, got %d", total)
+	}
+}
+
+func TestSearchArticlesCJK(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+
+	id := saveSearchArticle(t, db, feedID, "我们喜欢阅读新闻")
+
+	results, total, err := db.SearchArticles("阅读", 0, "", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchArticles error: %v", err)
+	}
+	if total != 1 || results[0].ID != id {
+		t.Fatalf("expected CJK word match, got total=%d", total)
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>阅读</mark>") || strings.Contains(results[0].Snippet, "我们 ") {
+		t.Errorf("expected joined CJK snippet with highlight, got %q", results[0].Snippet)
+	}
+}
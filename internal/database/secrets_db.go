@@ -0,0 +1,266 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"MrRSS/internal/crypto"
+)
+
+// secretKeyCheckSetting holds the key check value of the master passphrase. Without it
+// secrets are encrypted with the machine key.
+const secretKeyCheckSetting = "secret_key_check"
+
+var (
+	// ErrSecretsLocked is returned when secrets are read or written before the master
+	// passphrase has been entered
+	ErrSecretsLocked = errors.New("secrets are locked until the master passphrase is entered")
+	// ErrNoMasterPassphrase is returned when unlocking secrets that use the machine key
+	ErrNoMasterPassphrase = errors.New("no master passphrase is set")
+)
+
+// SecretsStatus describes how the secrets of the database are encrypted
+type SecretsStatus struct {
+	PassphraseEnabled bool `json:"passphrase_enabled"` // Encrypted with a master passphrase instead of the machine key
+	Locked            bool `json:"locked"`             // The master passphrase has not been entered yet
+}
+
+// RekeyReport describes the outcome of re-encrypting the secrets
+type RekeyReport struct {
+	Reencrypted int `json:"reencrypted"`
+	// Unreadable lists secrets that could not be decrypted, e.g. because they were
+	// encrypted on another machine. They are left as they are and must be entered again.
+	Unreadable []string `json:"unreadable"`
+}
+
+// keyCheck returns the key check value of the master passphrase, or "" if none is set
+func (db *DB) keyCheck() string {
+	check, _ := db.GetSetting(secretKeyCheckSetting)
+	return check
+}
+
+// SecretsStatus returns how the secrets of the database are encrypted
+func (db *DB) SecretsStatus() SecretsStatus {
+	db.secretsMu.RLock()
+	defer db.secretsMu.RUnlock()
+	enabled := db.keyCheck() != ""
+	return SecretsStatus{PassphraseEnabled: enabled, Locked: enabled && db.secretKey == nil}
+}
+
+// UnlockSecrets enters the master passphrase, so secrets can be decrypted and encrypted
+func (db *DB) UnlockSecrets(passphrase string) error {
+	db.WaitForReady()
+	db.secretsMu.Lock()
+	defer db.secretsMu.Unlock()
+
+	check := db.keyCheck()
+	if check == "" {
+		return ErrNoMasterPassphrase
+	}
+	key, err := crypto.OpenPassphraseKey(check, passphrase)
+	if err != nil {
+		return err
+	}
+	db.secretKey = key
+	return nil
+}
+
+// MasterPassphraseKey returns the key of the master passphrase and its key check value
+func (db *DB) MasterPassphraseKey() (*crypto.PassphraseKey, string, error) {
+	db.secretsMu.RLock()
+	defer db.secretsMu.RUnlock()
+	check := db.keyCheck()
+	if check == "" {
+		return nil, "", ErrNoMasterPassphrase
+	}
+	if db.secretKey == nil {
+		return nil, "", ErrSecretsLocked
+	}
+	return db.secretKey, check, nil
+}
+
+// EncryptSecret encrypts a secret with the master passphrase, or with the machine key
+// if no master passphrase is set
+func (db *DB) EncryptSecret(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	db.secretsMu.RLock()
+	defer db.secretsMu.RUnlock()
+	if db.keyCheck() == "" {
+		return crypto.Encrypt(plaintext)
+	}
+	if db.secretKey == nil {
+		return "", ErrSecretsLocked
+	}
+	return db.secretKey.Encrypt(plaintext)
+}
+
+// DecryptSecret decrypts a secret stored by EncryptSecret. Values that are not
+// encrypted, from before secrets were encrypted, are returned as they are.
+func (db *DB) DecryptSecret(value string) (string, error) {
+	if !crypto.IsEncrypted(value) {
+		return value, nil
+	}
+	if !crypto.IsPassphraseEncrypted(value) {
+		return crypto.Decrypt(value)
+	}
+	db.secretsMu.RLock()
+	key := db.secretKey
+	db.secretsMu.RUnlock()
+	if key == nil {
+		return "", ErrSecretsLocked
+	}
+	return key.Decrypt(value)
+}
+
+// storedSecret returns the form a secret is stored in: values that are already
+// encrypted, such as secrets read from the database and saved again, are kept
+func (db *DB) storedSecret(value string) (string, error) {
+	if value == "" || crypto.IsEncrypted(value) {
+		return value, nil
+	}
+	return db.EncryptSecret(value)
+}
+
+// SetMasterPassphrase sets, changes or (with an empty passphrase) removes the master
+// passphrase and re-encrypts all secrets with the new key. If a master passphrase is
+// set, current must match it.
+func (db *DB) SetMasterPassphrase(current, passphrase string) (*RekeyReport, error) {
+	db.WaitForReady()
+	db.secretsMu.Lock()
+	defer db.secretsMu.Unlock()
+
+	var currentKey *crypto.PassphraseKey
+	if check := db.keyCheck(); check != "" {
+		var err error
+		if currentKey, err = crypto.OpenPassphraseKey(check, current); err != nil {
+			return nil, err
+		}
+	} else if passphrase == "" {
+		return nil, ErrNoMasterPassphrase
+	}
+
+	var newKey *crypto.PassphraseKey
+	var newCheck string
+	if passphrase != "" {
+		var err error
+		if newKey, newCheck, err = crypto.NewPassphraseKey(passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	decrypt := func(value string) (string, error) {
+		if crypto.IsPassphraseEncrypted(value) {
+			if currentKey == nil {
+				return "", crypto.ErrPassphraseRequired
+			}
+			return currentKey.Decrypt(value)
+		}
+		return crypto.Decrypt(value)
+	}
+	encrypt := func(plaintext string) (string, error) {
+		if newKey != nil {
+			return newKey.Encrypt(plaintext)
+		}
+		return crypto.Encrypt(plaintext)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report, err := rekeySecrets(tx, decrypt, encrypt)
+	if err != nil {
+		return nil, err
+	}
+	if newCheck != "" {
+		_, err = tx.Exec(`INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)`, secretKeyCheckSetting, newCheck)
+	} else {
+		_, err = tx.Exec(`DELETE FROM settings WHERE key = ?`, secretKeyCheckSetting)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	db.secretKey = newKey
+	return report, nil
+}
+
+// storedSecretRow is an encrypted value and where it is stored
+type storedSecretRow struct {
+	name   string // For reports, e.g. "setting ai_api_key"
+	update string // Statement storing the value, with the value as first argument
+	id     interface{}
+	value  string
+	plain  bool // Plain text values are secrets too and get encrypted
+}
+
+// rekeySecrets re-encrypts the encrypted settings, feed HTTP secrets, IMAP passwords
+// and notification tokens. IMAP passwords that are still stored in plain text are encrypted.
+func rekeySecrets(tx *sql.Tx, decrypt, encrypt func(string) (string, error)) (*RekeyReport, error) {
+	var secrets []storedSecretRow
+	collect := func(query, name, update string, plain bool) error {
+		rows, err := tx.Query(query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id interface{}
+			var value string
+			if err := rows.Scan(&id, &value); err != nil {
+				return err
+			}
+			secrets = append(secrets, storedSecretRow{name: fmt.Sprintf(name, id), update: update, id: id, value: value, plain: plain})
+		}
+		return rows.Err()
+	}
+
+	// Rows are collected first, the transaction can't update while a query is open
+	queries := []struct {
+		query, name, update string
+		plain               bool
+	}{
+		{`SELECT key, value FROM settings WHERE value LIKE 'MrRSS-%'`, "setting %s", `UPDATE settings SET value = ? WHERE key = ?`, false},
+		{`SELECT feed_id, cookie FROM feed_http_settings WHERE cookie != ''`, "cookie of feed %d", `UPDATE feed_http_settings SET cookie = ? WHERE feed_id = ?`, false},
+		{`SELECT feed_id, auth_secret FROM feed_http_settings WHERE auth_secret != ''`, "auth secret of feed %d", `UPDATE feed_http_settings SET auth_secret = ? WHERE feed_id = ?`, false},
+		// IMAP passwords were stored in plain text by earlier versions
+		{`SELECT id, email_password FROM feeds WHERE COALESCE(email_password, '') != ''`, "IMAP password of feed %d", `UPDATE feeds SET email_password = ? WHERE id = ?`, true},
+		{`SELECT id, token FROM notification_targets WHERE token != ''`, "token of notification target %d", `UPDATE notification_targets SET token = ? WHERE id = ?`, false},
+	}
+	for _, q := range queries {
+		if err := collect(q.query, q.name, q.update, q.plain); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &RekeyReport{Unreadable: []string{}}
+	for _, secret := range secrets {
+		plaintext := secret.value
+		if crypto.IsEncrypted(secret.value) {
+			var err error
+			if plaintext, err = decrypt(secret.value); err != nil {
+				report.Unreadable = append(report.Unreadable, secret.name)
+				continue
+			}
+		} else if !secret.plain {
+			// Not a secret, such as the key check value
+			continue
+		}
+		encrypted, err := encrypt(plaintext)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(secret.update, encrypted, secret.id); err != nil {
+			return nil, err
+		}
+		report.Reencrypted++
+	}
+	return report, nil
+}
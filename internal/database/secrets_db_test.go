@@ -0,0 +1,109 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/models"
+)
+
+func TestMasterPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	db.SetEncryptedSetting("ai_api_key", "sk-secret")
+	feedID, _ := db.AddFeed(&models.Feed{Title: "Mail", URL: "email://me@example.com", EmailPassword: "imap-pass"})
+	// Earlier versions stored IMAP passwords in plain text
+	legacyID, _ := db.AddFeed(&models.Feed{Title: "Old mail", URL: "email://old@example.com"})
+	db.Exec(`UPDATE feeds SET email_password = 'legacy-pass' WHERE id = ?`, legacyID)
+	db.SetFeedHTTPSettings(&models.FeedHTTPSettings{FeedID: feedID, Cookie: "session=1"})
+
+	storedPassword := func(db *DB, id int64) string {
+		var value string
+		db.QueryRow(`SELECT email_password FROM feeds WHERE id = ?`, id).Scan(&value)
+		return value
+	}
+	if stored := storedPassword(db, feedID); !crypto.IsEncrypted(stored) {
+		t.Errorf("IMAP password stored as %q", stored)
+	}
+
+	if _, err := db.SetMasterPassphrase("", ""); !errors.Is(err, ErrNoMasterPassphrase) {
+		t.Errorf("removing a missing passphrase error = %v", err)
+	}
+	report, err := db.SetMasterPassphrase("", "open sesame")
+	if err != nil {
+		t.Fatalf("SetMasterPassphrase error: %v", err)
+	}
+	if report.Reencrypted != 4 || len(report.Unreadable) != 0 {
+		t.Errorf("rekey report = %+v, want 4 re-encrypted secrets", report)
+	}
+	for _, id := range []int64{feedID, legacyID} {
+		if stored := storedPassword(db, id); !crypto.IsPassphraseEncrypted(stored) {
+			t.Errorf("IMAP password of feed %d stored as %q", id, stored)
+		}
+	}
+	db.Close()
+
+	// After a restart the secrets are locked until the passphrase is entered
+	db, err = NewDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Init()
+	defer db.Close()
+
+	if status := db.SecretsStatus(); !status.PassphraseEnabled || !status.Locked {
+		t.Errorf("status = %+v, want locked", status)
+	}
+	if _, err := db.GetEncryptedSetting("ai_api_key"); !errors.Is(err, ErrSecretsLocked) {
+		t.Errorf("reading a locked secret error = %v", err)
+	}
+	// Saving the empty value a locked secret reads as keeps it
+	if err := db.SetEncryptedSetting("ai_api_key", ""); err != nil {
+		t.Errorf("SetEncryptedSetting error = %v", err)
+	}
+	if err := db.SetEncryptedSetting("deepl_api_key", "new"); !errors.Is(err, ErrSecretsLocked) {
+		t.Errorf("writing a locked secret error = %v", err)
+	}
+
+	if err := db.UnlockSecrets("wrong"); !errors.Is(err, crypto.ErrWrongPassphrase) {
+		t.Errorf("UnlockSecrets with a wrong passphrase error = %v", err)
+	}
+	if err := db.UnlockSecrets("open sesame"); err != nil {
+		t.Fatalf("UnlockSecrets error: %v", err)
+	}
+	if key, err := db.GetEncryptedSetting("ai_api_key"); err != nil || key != "sk-secret" {
+		t.Errorf("ai_api_key = %q, %v", key, err)
+	}
+	if password, err := db.DecryptSecret(storedPassword(db, legacyID)); err != nil || password != "legacy-pass" {
+		t.Errorf("legacy IMAP password = %q, %v", password, err)
+	}
+	if settings, _ := db.GetFeedHTTPSettings(feedID); settings == nil || settings.Cookie != "session=1" {
+		t.Errorf("HTTP settings = %+v", settings)
+	}
+
+	// Changing or removing the passphrase requires the current one
+	if _, err := db.SetMasterPassphrase("wrong", ""); !errors.Is(err, crypto.ErrWrongPassphrase) {
+		t.Errorf("removing with a wrong passphrase error = %v", err)
+	}
+	if _, err := db.SetMasterPassphrase("open sesame", ""); err != nil {
+		t.Fatalf("removing the passphrase error: %v", err)
+	}
+	if status := db.SecretsStatus(); status.PassphraseEnabled {
+		t.Errorf("status = %+v after removing the passphrase", status)
+	}
+	if stored := storedPassword(db, feedID); crypto.IsPassphraseEncrypted(stored) || !crypto.IsEncrypted(stored) {
+		t.Errorf("IMAP password stored as %q, want the machine key", stored)
+	}
+	if key, err := db.GetEncryptedSetting("ai_api_key"); err != nil || key != "sk-secret" {
+		t.Errorf("ai_api_key = %q, %v after removing the passphrase", key, err)
+	}
+}
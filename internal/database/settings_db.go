@@ -20,10 +20,21 @@ func (db *DB) GetSetting(key string) (string, error) {
 // SetSetting stores a setting value.
 func (db *DB) SetSetting(key, value string) error {
 	db.WaitForReady()
+	// Rules live in their own tables; the setting is only a mirror kept for older clients
+	if key == rulesSettingKey {
+		return db.ImportRulesJSON(value)
+	}
 	_, err := db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
 	return err
 }
 
+// DeleteSetting removes a setting. Deleting a missing setting is not an error.
+func (db *DB) DeleteSetting(key string) error {
+	db.WaitForReady()
+	_, err := db.Exec("DELETE FROM settings WHERE key = ?", key)
+	return err
+}
+
 // GetEncryptedSetting retrieves and decrypts a sensitive setting value.
 // If the value is not encrypted (plain text), it will be automatically encrypted
 // and stored back to support migration from old versions.
@@ -44,7 +55,7 @@ func (db *DB) GetEncryptedSetting(key string) (string, error) {
 	// Check if the value is already encrypted
 	if crypto.IsEncrypted(storedValue) {
 		// Decrypt and return
-		decrypted, err := crypto.Decrypt(storedValue)
+		decrypted, err := db.DecryptSecret(storedValue)
 		if err != nil {
 			return "", fmt.Errorf("failed to decrypt setting %s: %w", key, err)
 		}
@@ -55,7 +66,7 @@ func (db *DB) GetEncryptedSetting(key string) (string, error) {
 	log.Printf("Migrating plain text setting to encrypted storage")
 
 	// Encrypt the plain text value
-	encrypted, err := crypto.Encrypt(storedValue)
+	encrypted, err := db.EncryptSecret(storedValue)
 	if err != nil {
 		// If encryption fails, return an error to the caller
 		log.Printf("Warning: Failed to encrypt setting during migration: %v", err)
@@ -79,11 +90,17 @@ func (db *DB) SetEncryptedSetting(key, value string) error {
 
 	// Empty value - store as is
 	if value == "" {
+		// While locked, secrets read as empty; saving them back must not delete them
+		if db.SecretsStatus().Locked {
+			if stored, _ := db.GetSetting(key); crypto.IsPassphraseEncrypted(stored) {
+				return nil
+			}
+		}
 		return db.SetSetting(key, value)
 	}
 
 	// Encrypt the value
-	encrypted, err := crypto.Encrypt(value)
+	encrypted, err := db.EncryptSecret(value)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt setting %s: %w", key, err)
 	}
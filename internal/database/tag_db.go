@@ -0,0 +1,265 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// ErrInvalidTagName is returned when a tag name is empty or contains invalid characters
+var ErrInvalidTagName = errors.New("invalid tag name")
+
+// articleTagsColumn selects the user tags of article a as a single string separated by
+// the ASCII unit separator (see splitArticleCategories)
+const articleTagsColumn = `(SELECT GROUP_CONCAT(t.name, char(31)) FROM article_tags at JOIN tags t ON t.id = at.tag_id WHERE at.article_id = a.id)`
+
+// InitTagsTable creates the tags and article_tags tables if they don't exist
+func InitTagsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		color TEXT DEFAULT '',
+		position INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS article_tags (
+		article_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (article_id, tag_id),
+		FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE,
+		FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_article_tags_tag_id ON article_tags(tag_id);
+
+	-- Foreign keys are not enforced, so remove tag assignments of deleted articles explicitly
+	CREATE TRIGGER IF NOT EXISTS article_tags_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_tags WHERE article_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// NormalizeTagName trims a tag name and validates it.
+// Names must be non-empty and must not contain "/" or control characters,
+// so they can be mapped to Google Reader labels.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 || strings.ContainsAny(name, "/\x1f") {
+		return "", ErrInvalidTagName
+	}
+	for _, r := range name {
+		if r < 0x20 {
+			return "", ErrInvalidTagName
+		}
+	}
+	return name, nil
+}
+
+// GetTags returns all tags ordered by position and name, with their article counts.
+func (db *DB) GetTags() ([]models.Tag, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT t.id, t.name, COALESCE(t.color, ''), COALESCE(t.position, 0), t.created_at,
+			(SELECT COUNT(*) FROM article_tags at WHERE at.tag_id = t.id)
+		FROM tags t
+		ORDER BY t.position, t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		var createdAt sql.NullTime
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.Position, &createdAt, &tag.ArticleCount); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			tag.CreatedAt = createdAt.Time
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetTagByID returns a tag by its ID, or nil if it doesn't exist.
+func (db *DB) GetTagByID(id int64) (*models.Tag, error) {
+	db.WaitForReady()
+	return db.getTag(`SELECT id, name, COALESCE(color, ''), COALESCE(position, 0), created_at FROM tags WHERE id = ?`, id)
+}
+
+// GetTagByName returns a tag by its name (case-insensitive), or nil if it doesn't exist.
+func (db *DB) GetTagByName(name string) (*models.Tag, error) {
+	db.WaitForReady()
+	return db.getTag(`SELECT id, name, COALESCE(color, ''), COALESCE(position, 0), created_at FROM tags WHERE name = ?`, strings.TrimSpace(name))
+}
+
+func (db *DB) getTag(query string, arg interface{}) (*models.Tag, error) {
+	var tag models.Tag
+	var createdAt sql.NullTime
+	err := db.QueryRow(query, arg).Scan(&tag.ID, &tag.Name, &tag.Color, &tag.Position, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		tag.CreatedAt = createdAt.Time
+	}
+	return &tag, nil
+}
+
+// CreateTag creates a new tag and returns its ID.
+func (db *DB) CreateTag(name, color string) (int64, error) {
+	db.WaitForReady()
+	name, err := NormalizeTagName(name)
+	if err != nil {
+		return 0, err
+	}
+
+	var position int
+	_ = db.QueryRow(`SELECT COALESCE(MAX(position), -1) + 1 FROM tags`).Scan(&position)
+
+	result, err := db.Exec(`INSERT INTO tags (name, color, position, created_at) VALUES (?, ?, ?, ?)`, name, color, position, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetOrCreateTag returns the ID of the tag with the given name, creating it if necessary.
+func (db *DB) GetOrCreateTag(name string) (int64, error) {
+	name, err := NormalizeTagName(name)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := db.GetTagByName(name)
+	if err != nil {
+		return 0, err
+	}
+	if tag != nil {
+		return tag.ID, nil
+	}
+	return db.CreateTag(name, "")
+}
+
+// UpdateTag renames a tag and updates its color and position.
+func (db *DB) UpdateTag(id int64, name, color string, position int) error {
+	db.WaitForReady()
+	name, err := NormalizeTagName(name)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`UPDATE tags SET name = ?, color = ?, position = ? WHERE id = ?`, name, color, position, id)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteTag deletes a tag and removes it from all articles.
+func (db *DB) DeleteTag(id int64) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE tag_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetArticlesTag adds (or removes) a tag on multiple articles in a single transaction.
+// Returns the IDs of the articles whose tags actually changed.
+func (db *DB) SetArticlesTag(tagID int64, articleIDs []int64, add bool) ([]int64, error) {
+	db.WaitForReady()
+	if len(articleIDs) == 0 {
+		return nil, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM article_tags WHERE article_id = ? AND tag_id = ?`
+	if add {
+		query = `INSERT OR IGNORE INTO article_tags (article_id, tag_id, created_at)
+			SELECT id, ?, CURRENT_TIMESTAMP FROM articles WHERE id = ?`
+	}
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var changed []int64
+	for _, articleID := range articleIDs {
+		var result sql.Result
+		if add {
+			result, err = stmt.Exec(tagID, articleID)
+		} else {
+			result, err = stmt.Exec(articleID, tagID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rows, _ := result.RowsAffected(); rows > 0 {
+			changed = append(changed, articleID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// GetArticleTags returns the names of the tags attached to an article.
+func (db *DB) GetArticleTags(articleID int64) ([]string, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT t.name FROM article_tags at
+		JOIN tags t ON t.id = at.tag_id
+		WHERE at.article_id = ?
+		ORDER BY t.position, t.name
+	`, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,131 @@
+package database_test
+
+import (
+	"testing"
+
+	dbpkg "MrRSS/internal/database"
+)
+
+func TestTagsCRUDAndArticleTagging(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+
+	first := saveSearchArticle(t, db, feedID, "Graph neural networks survey")
+	second := saveSearchArticle(t, db, feedID, "Weekly newsletter")
+
+	if _, err := db.CreateTag("  ", ""); err != dbpkg.ErrInvalidTagName {
+		t.Errorf("expected ErrInvalidTagName for blank name, got %v", err)
+	}
+	if _, err := db.CreateTag("a/b", ""); err != dbpkg.ErrInvalidTagName {
+		t.Errorf("expected ErrInvalidTagName for name with slash, got %v", err)
+	}
+
+	tagID, err := db.CreateTag("to-cite", "#ff0000")
+	if err != nil {
+		t.Fatalf("CreateTag error: %v", err)
+	}
+	if id, err := db.GetOrCreateTag("TO-CITE"); err != nil || id != tagID {
+		t.Errorf("expected case-insensitive lookup to return %d, got %d (%v)", tagID, id, err)
+	}
+
+	changed, err := db.SetArticlesTag(tagID, []int64{first, second, 9999}, true)
+	if err != nil {
+		t.Fatalf("SetArticlesTag error: %v", err)
+	}
+	if len(changed) != 2 {
+		t.Errorf("expected 2 articles tagged (missing article ignored), got %v", changed)
+	}
+	if changed, _ := db.SetArticlesTag(tagID, []int64{first}, true); len(changed) != 0 {
+		t.Errorf("expected re-tagging to be a no-op, got %v", changed)
+	}
+
+	tagged, err := db.GetArticles("tag:to-cite", 0, "", true, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	if len(tagged) != 2 || len(tagged[0].Tags) != 1 || tagged[0].Tags[0] != "to-cite" {
+		t.Fatalf("expected 2 tagged articles with tag names, got %+v", tagged)
+	}
+
+	if _, err := db.SetArticlesTag(tagID, []int64{second}, false); err != nil {
+		t.Fatalf("untag error: %v", err)
+	}
+	if tagged, _ := db.GetArticles("tag:to-cite", 0, "", true, 10, 0); len(tagged) != 1 || tagged[0].ID != first {
+		t.Errorf("expected only the first article to remain tagged, got %+v", tagged)
+	}
+
+	if err := db.UpdateTag(tagID, "reading", "", 3); err != nil {
+		t.Fatalf("UpdateTag error: %v", err)
+	}
+	if tags, _ := db.GetArticleTags(first); len(tags) != 1 || tags[0] != "reading" {
+		t.Errorf("expected renamed tag on article, got %v", tags)
+	}
+
+	tags, err := db.GetTags()
+	if err != nil || len(tags) != 1 || tags[0].ArticleCount != 1 || tags[0].Position != 3 {
+		t.Fatalf("unexpected tags list %+v (%v)", tags, err)
+	}
+
+	// Deleting an article removes its tag assignments
+	if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, first); err != nil {
+		t.Fatalf("delete article: %v", err)
+	}
+	if tags, _ := db.GetTags(); tags[0].ArticleCount != 0 {
+		t.Errorf("expected tag assignments of deleted article to be removed, got %d", tags[0].ArticleCount)
+	}
+
+	if err := db.DeleteTag(tagID); err != nil {
+		t.Fatalf("DeleteTag error: %v", err)
+	}
+	if tag, _ := db.GetTagByID(tagID); tag != nil {
+		t.Errorf("expected tag to be deleted, got %+v", tag)
+	}
+}
+
+func TestLabelSyncAction(t *testing.T) {
+	action := dbpkg.LabelSyncAction("to-cite", true)
+	if label, add, ok := action.Label(); !ok || !add || label != "to-cite" {
+		t.Errorf("unexpected add label action parse: %q %v %v", label, add, ok)
+	}
+	action = dbpkg.LabelSyncAction("to-cite", false)
+	if label, add, ok := action.Label(); !ok || add || label != "to-cite" {
+		t.Errorf("unexpected remove label action parse: %q %v %v", label, add, ok)
+	}
+	if _, _, ok := dbpkg.SyncActionStar.Label(); ok {
+		t.Error("expected star action not to be a label action")
+	}
+}
+
+func TestTagLabelSync(t *testing.T) {
+	db, feedID := setupSearchDB(t)
+	article := saveSearchArticle(t, db, feedID, "Graph neural networks survey")
+	if _, err := db.Exec(`UPDATE feeds SET is_freshrss_source = 1 WHERE id = ?`, feedID); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetSetting("freshrss_enabled", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	tagID, _ := db.CreateTag("to-cite", "")
+	changed, requests, err := db.TagArticlesWithSync(tagID, []int64{article, 9999}, true)
+	if err != nil || changed != 1 || len(requests) != 1 || requests[0].Action != dbpkg.LabelSyncAction("to-cite", true) {
+		t.Fatalf("TagArticlesWithSync = %d, %+v, %v", changed, requests, err)
+	}
+
+	// Renaming moves the label, other changes don't touch it
+	if requests, err := db.UpdateTagWithSync(tagID, "to-cite", "#00ff00", 1); err != nil || len(requests) != 0 {
+		t.Errorf("UpdateTagWithSync without rename = %+v, %v", requests, err)
+	}
+	requests, err = db.UpdateTagWithSync(tagID, "reading", "", 1)
+	if err != nil || len(requests) != 2 || requests[0].Action != dbpkg.LabelSyncAction("to-cite", false) ||
+		requests[1].Action != dbpkg.LabelSyncAction("reading", true) || requests[0].ArticleID != article {
+		t.Errorf("UpdateTagWithSync = %+v, %v", requests, err)
+	}
+
+	requests, err = db.DeleteTagWithSync(tagID)
+	if err != nil || len(requests) != 1 || requests[0].Action != dbpkg.LabelSyncAction("reading", false) {
+		t.Errorf("DeleteTagWithSync = %+v, %v", requests, err)
+	}
+	if tag, _ := db.GetTagByID(tagID); tag != nil {
+		t.Errorf("expected tag to be deleted, got %+v", tag)
+	}
+}
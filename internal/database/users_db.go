@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// InitUsersTable creates the users table if it doesn't exist. Only the main database
+// of the server build holds users; their data lives in their own databases.
+func InitUsersTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		password_hash TEXT NOT NULL,
+		is_admin BOOLEAN NOT NULL DEFAULT 0,
+		db_file TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// CreateUser adds a user with the given bcrypt password hash.
+func (db *DB) CreateUser(user *models.User, passwordHash string) error {
+	db.WaitForReady()
+	user.CreatedAt = time.Now().UTC()
+	result, err := db.Exec(`INSERT INTO users (username, password_hash, is_admin, db_file, created_at)
+		VALUES (?, ?, ?, ?, ?)`, user.Username, passwordHash, user.IsAdmin, user.DBFile, user.CreatedAt)
+	if err != nil {
+		return err
+	}
+	user.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// SetUserDBFile records the database file of a user.
+func (db *DB) SetUserDBFile(id int64, dbFile string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE users SET db_file = ? WHERE id = ?", dbFile, id)
+	return err
+}
+
+// GetUser returns a user by ID. It returns sql.ErrNoRows if there is no such user.
+func (db *DB) GetUser(id int64) (*models.User, error) {
+	db.WaitForReady()
+	var user models.User
+	err := db.QueryRow("SELECT id, username, is_admin, db_file, created_at FROM users WHERE id = ?", id).
+		Scan(&user.ID, &user.Username, &user.IsAdmin, &user.DBFile, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByUsername returns a user and their password hash. Usernames are case-insensitive.
+// It returns sql.ErrNoRows if there is no such user.
+func (db *DB) GetUserByUsername(username string) (*models.User, string, error) {
+	db.WaitForReady()
+	var user models.User
+	var passwordHash string
+	err := db.QueryRow("SELECT id, username, is_admin, db_file, created_at, password_hash FROM users WHERE username = ?", username).
+		Scan(&user.ID, &user.Username, &user.IsAdmin, &user.DBFile, &user.CreatedAt, &passwordHash)
+	if err != nil {
+		return nil, "", err
+	}
+	return &user, passwordHash, nil
+}
+
+// GetUserPasswordHash returns the password hash of a user.
+func (db *DB) GetUserPasswordHash(id int64) (string, error) {
+	db.WaitForReady()
+	var passwordHash string
+	err := db.QueryRow("SELECT password_hash FROM users WHERE id = ?", id).Scan(&passwordHash)
+	return passwordHash, err
+}
+
+// GetUsers returns all users, oldest first.
+func (db *DB) GetUsers() ([]models.User, error) {
+	db.WaitForReady()
+	rows, err := db.Query("SELECT id, username, is_admin, db_file, created_at FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.IsAdmin, &user.DBFile, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// SetUserPassword replaces the password hash of a user.
+func (db *DB) SetUserPassword(id int64, passwordHash string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE users SET password_hash = ? WHERE id = ?", passwordHash, id)
+	return err
+}
+
+// DeleteUser removes a user and revokes their sessions and tokens.
+// It returns sql.ErrNoRows if the user doesn't exist.
+func (db *DB) DeleteUser(id int64) error {
+	db.WaitForReady()
+	result, err := db.Exec("DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	_, err = db.Exec("DELETE FROM auth_tokens WHERE user_id = ?", id)
+	return err
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WebSub subscription states
+const (
+	WebSubStatePending      = "pending"      // Subscribe request sent, waiting for hub verification
+	WebSubStateActive       = "active"       // Hub verified the subscription and the lease is running
+	WebSubStateUnsubscribed = "unsubscribed" // Unsubscribe request sent or verified
+	WebSubStateDenied       = "denied"       // Hub denied the subscription
+)
+
+// WebSubSubscription represents a WebSub (PubSubHubbub) subscription for a feed
+type WebSubSubscription struct {
+	FeedID       int64
+	HubURL       string
+	TopicURL     string
+	Secret       string
+	State        string
+	LeaseSeconds int
+	LeaseExpires *time.Time
+	LastError    string
+	UpdatedAt    time.Time
+}
+
+// IsActive reports whether the subscription has a verified, unexpired lease.
+func (s *WebSubSubscription) IsActive() bool {
+	return s.State == WebSubStateActive && s.LeaseExpires != nil && time.Now().Before(*s.LeaseExpires)
+}
+
+// InitWebSubTable creates the websub_subscriptions table if it doesn't exist
+func InitWebSubTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS websub_subscriptions (
+		feed_id INTEGER PRIMARY KEY,
+		hub_url TEXT NOT NULL,
+		topic_url TEXT NOT NULL,
+		secret TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL DEFAULT 'pending',
+		lease_seconds INTEGER DEFAULT 0,
+		lease_expires INTEGER,
+		last_error TEXT DEFAULT '',
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_websub_state_expires ON websub_subscriptions(state, lease_expires);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// SaveWebSubSubscription creates or replaces the WebSub subscription of a feed.
+func (db *DB) SaveWebSubSubscription(sub *WebSubSubscription) error {
+	db.WaitForReady()
+
+	var leaseExpires interface{}
+	if sub.LeaseExpires != nil {
+		leaseExpires = sub.LeaseExpires.Unix()
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO websub_subscriptions (feed_id, hub_url, topic_url, secret, state, lease_seconds, lease_expires, last_error, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(feed_id) DO UPDATE SET
+			hub_url = excluded.hub_url,
+			topic_url = excluded.topic_url,
+			secret = excluded.secret,
+			state = excluded.state,
+			lease_seconds = excluded.lease_seconds,
+			lease_expires = excluded.lease_expires,
+			last_error = excluded.last_error,
+			updated_at = excluded.updated_at`,
+		sub.FeedID, sub.HubURL, sub.TopicURL, sub.Secret, sub.State, sub.LeaseSeconds, leaseExpires, sub.LastError, time.Now().Unix())
+	return err
+}
+
+// GetWebSubSubscription returns the WebSub subscription of a feed, or nil if there is none.
+func (db *DB) GetWebSubSubscription(feedID int64) (*WebSubSubscription, error) {
+	db.WaitForReady()
+
+	row := db.QueryRow(`
+		SELECT feed_id, hub_url, topic_url, secret, state, COALESCE(lease_seconds, 0), lease_expires, COALESCE(last_error, ''), updated_at
+		FROM websub_subscriptions WHERE feed_id = ?`, feedID)
+
+	sub, err := scanWebSubSubscription(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// GetWebSubSubscriptionsExpiringBefore returns active subscriptions whose lease expires before the given time.
+func (db *DB) GetWebSubSubscriptionsExpiringBefore(before time.Time) ([]WebSubSubscription, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`
+		SELECT feed_id, hub_url, topic_url, secret, state, COALESCE(lease_seconds, 0), lease_expires, COALESCE(last_error, ''), updated_at
+		FROM websub_subscriptions
+		WHERE state = ? AND lease_expires IS NOT NULL AND lease_expires < ?`,
+		WebSubStateActive, before.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []WebSubSubscription
+	for rows.Next() {
+		sub, err := scanWebSubSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+// GetActiveWebSubFeedIDs returns the IDs of feeds with a verified, unexpired WebSub lease.
+func (db *DB) GetActiveWebSubFeedIDs() (map[int64]bool, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`SELECT feed_id FROM websub_subscriptions WHERE state = ? AND lease_expires > ?`,
+		WebSubStateActive, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// UpdateWebSubState updates the state, lease and error of a feed's WebSub subscription.
+// A leaseSeconds of 0 leaves the lease expiry cleared.
+func (db *DB) UpdateWebSubState(feedID int64, state string, leaseSeconds int, lastError string) error {
+	db.WaitForReady()
+
+	var leaseExpires interface{}
+	if leaseSeconds > 0 {
+		leaseExpires = time.Now().Add(time.Duration(leaseSeconds) * time.Second).Unix()
+	}
+
+	_, err := db.Exec(`UPDATE websub_subscriptions SET state = ?, lease_seconds = ?, lease_expires = ?, last_error = ?, updated_at = ? WHERE feed_id = ?`,
+		state, leaseSeconds, leaseExpires, lastError, time.Now().Unix(), feedID)
+	return err
+}
+
+// DeleteWebSubSubscription removes the WebSub subscription of a feed.
+func (db *DB) DeleteWebSubSubscription(feedID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM websub_subscriptions WHERE feed_id = ?`, feedID)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebSubSubscription(row rowScanner) (*WebSubSubscription, error) {
+	var sub WebSubSubscription
+	var leaseExpires sql.NullInt64
+	var updatedAt int64
+	if err := row.Scan(&sub.FeedID, &sub.HubURL, &sub.TopicURL, &sub.Secret, &sub.State,
+		&sub.LeaseSeconds, &leaseExpires, &sub.LastError, &updatedAt); err != nil {
+		return nil, err
+	}
+	if leaseExpires.Valid {
+		t := time.Unix(leaseExpires.Int64, 0)
+		sub.LeaseExpires = &t
+	}
+	sub.UpdatedAt = time.Unix(updatedAt, 0)
+	return &sub, nil
+}
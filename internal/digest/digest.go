@@ -0,0 +1,346 @@
+// Package digest emails the articles matching a set of filter conditions on a daily or
+// weekly schedule, optionally with AI summaries.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/aiusage"
+	"MrRSS/internal/database"
+	"MrRSS/internal/filter"
+	"MrRSS/internal/models"
+	"MrRSS/internal/summary"
+	"MrRSS/internal/utils"
+)
+
+const (
+	// defaultMaxArticles limits digests that don't set a maximum
+	defaultMaxArticles = 50
+	// maxArticlesLimit is the largest allowed maximum
+	maxArticlesLimit = 500
+	// candidateArticles is the number of newest articles the conditions are checked against
+	candidateArticles = 50000
+	// candidatePageSize is the number of candidate articles loaded at a time
+	candidatePageSize = 500
+)
+
+// ErrInvalidDigest is wrapped by the errors of Validate
+var ErrInvalidDigest = errors.New("invalid digest")
+
+// Summarizer summarizes the content of an article. AISummarizer implements it.
+type Summarizer interface {
+	Summarize(text string, length summary.SummaryLength) (summary.SummaryResult, error)
+}
+
+// Service builds and sends digests
+type Service struct {
+	db        *database.DB
+	aiTracker *aiusage.Tracker
+	running   sync.Mutex // Held while due digests are sent, so ticks don't overlap
+	sending   sync.Mutex // Held by Send, so a manual and a scheduled send don't email the same articles
+
+	// newSummarizer creates the summarizer for a send, from the AI settings by default
+	newSummarizer func() Summarizer
+	// loadSMTPConfig returns the mail server, from the SMTP settings by default
+	loadSMTPConfig func() (*SMTPConfig, error)
+}
+
+// NewService creates a digest service
+func NewService(db *database.DB, aiTracker *aiusage.Tracker) *Service {
+	s := &Service{db: db, aiTracker: aiTracker}
+	s.newSummarizer = s.aiSummarizer
+	s.loadSMTPConfig = func() (*SMTPConfig, error) { return LoadSMTPConfig(db) }
+	return s
+}
+
+// Validate checks and normalizes a digest before it is stored
+func Validate(d *models.Digest) error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDigest)
+	}
+
+	d.Schedule = strings.ToLower(strings.TrimSpace(d.Schedule))
+	switch d.Schedule {
+	case models.DigestDaily:
+		d.Weekday = 0
+	case models.DigestWeekly:
+		if d.Weekday < 0 || d.Weekday > 6 {
+			return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6", ErrInvalidDigest)
+		}
+	default:
+		return fmt.Errorf("%w: schedule must be \"daily\" or \"weekly\"", ErrInvalidDigest)
+	}
+	if d.Hour < 0 || d.Hour > 23 {
+		return fmt.Errorf("%w: hour must be between 0 and 23", ErrInvalidDigest)
+	}
+	if d.MaxArticles < 0 || d.MaxArticles > maxArticlesLimit {
+		return fmt.Errorf("%w: at most %d articles per digest", ErrInvalidDigest, maxArticlesLimit)
+	}
+
+	recipients := make([]string, 0, len(d.Recipients))
+	for _, recipient := range d.Recipients {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("%w: invalid recipient %q", ErrInvalidDigest, recipient)
+		}
+		recipients = append(recipients, address.Address)
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("%w: at least one recipient is required", ErrInvalidDigest)
+	}
+	d.Recipients = recipients
+	return nil
+}
+
+// RunDue sends the enabled digests whose scheduled time has passed since they last ran.
+// A digest that is due but has no new articles is skipped until its next scheduled time.
+func (s *Service) RunDue(ctx context.Context, now time.Time) {
+	if !s.running.TryLock() {
+		return
+	}
+	defer s.running.Unlock()
+
+	digests, err := s.db.GetDigests()
+	if err != nil {
+		log.Printf("Error getting digests: %v", err)
+		return
+	}
+	for i := range digests {
+		d := &digests[i]
+		if !d.Enabled || !IsDue(d, now) {
+			continue
+		}
+		// Record the run first, a failing send is not retried before the next scheduled time
+		if err := s.db.SetDigestLastRun(d.ID, now); err != nil {
+			log.Printf("Error saving the run of digest %d: %v", d.ID, err)
+			continue
+		}
+		send, err := s.Send(ctx, d, now)
+		switch {
+		case err != nil:
+			log.Printf("Digest %q failed: %v", d.Name, err)
+		case send != nil:
+			log.Printf("Digest %q sent with %d articles", d.Name, send.ArticleCount)
+		}
+	}
+}
+
+// IsDue reports whether the latest scheduled time of a digest has passed since it last
+// ran, or since it was created if it never ran
+func IsDue(d *models.Digest, now time.Time) bool {
+	since := d.CreatedAt
+	if d.LastRunAt != nil {
+		since = *d.LastRunAt
+	}
+	return lastScheduledTime(d, now).After(since)
+}
+
+// lastScheduledTime returns the latest scheduled time of a digest at or before now, in local time
+func lastScheduledTime(d *models.Digest, now time.Time) time.Time {
+	now = now.Local()
+	t := time.Date(now.Year(), now.Month(), now.Day(), d.Hour, 0, 0, 0, time.Local)
+	if t.After(now) {
+		t = t.AddDate(0, 0, -1)
+	}
+	if d.Schedule == models.DigestWeekly {
+		for int(t.Weekday()) != d.Weekday {
+			t = t.AddDate(0, 0, -1)
+		}
+	}
+	return t
+}
+
+// Send emails the articles matching a digest that it didn't send before. It returns nil
+// without sending when there are no such articles. Sends and failed attempts are recorded.
+// Sends run one at a time, so a send never includes the articles of one still in progress.
+func (s *Service) Send(ctx context.Context, d *models.Digest, now time.Time) (*models.DigestSend, error) {
+	s.sending.Lock()
+	defer s.sending.Unlock()
+
+	config, err := s.loadSMTPConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.Build(d, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(message.Items) == 0 {
+		return nil, nil
+	}
+
+	send := &models.DigestSend{DigestID: d.ID, SentAt: now, Recipients: d.Recipients, Status: models.DigestSendSent}
+	for _, item := range message.Items {
+		send.ArticleIDs = append(send.ArticleIDs, item.ArticleID)
+	}
+
+	raw, err := message.Render(config.From, d.Recipients)
+	if err == nil {
+		err = config.Send(ctx, d.Recipients, raw)
+	}
+	if err != nil {
+		send.Status = models.DigestSendFailed
+		send.Error = err.Error()
+	}
+	if recordErr := s.db.AddDigestSend(send); recordErr != nil {
+		log.Printf("Error recording the send of digest %d: %v", d.ID, recordErr)
+	}
+	return send, err
+}
+
+// Build selects the articles of the next email of a digest: the newest articles matching its
+// conditions that it didn't send before, with summaries if the digest asks for them
+func (s *Service) Build(d *models.Digest, now time.Time) (*Message, error) {
+	sent, err := s.db.GetSentDigestArticleIDs(d.ID)
+	if err != nil {
+		return nil, err
+	}
+	feeds, err := s.db.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+	feedCategories := make(map[int64]string)
+	feedTypes := make(map[int64]string)
+	feedIsImageMode := make(map[int64]bool)
+	for _, feed := range feeds {
+		feedCategories[feed.ID] = feed.Category
+		feedTypes[feed.ID] = filter.FeedType(&feed)
+		feedIsImageMode[feed.ID] = feed.IsImageMode
+	}
+
+	limit := d.MaxArticles
+	if limit <= 0 {
+		limit = defaultMaxArticles
+	}
+
+	message := &Message{Name: d.Name, Date: now, Items: []Item{}}
+	var summarizer Summarizer
+	// Candidates are loaded a page at a time until the digest is full
+	for offset := 0; offset < candidateArticles && len(message.Items) < limit; offset += candidatePageSize {
+		articles, err := s.db.GetArticles("", 0, "", false, candidatePageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, article := range articles {
+			if len(message.Items) >= limit {
+				break
+			}
+			if sent[article.ID] || !filter.Matches(article, d.Conditions, feedCategories, feedTypes, feedIsImageMode) {
+				continue
+			}
+
+			item := Item{
+				ArticleID:   article.ID,
+				Title:       article.Title,
+				URL:         article.URL,
+				FeedTitle:   article.FeedTitle,
+				PublishedAt: article.PublishedAt,
+			}
+			if d.AISummary {
+				item.Summary = article.Summary
+				if item.Summary == "" {
+					if summarizer == nil {
+						summarizer = s.newSummarizer()
+					}
+					item.Summary = s.summarize(summarizer, article.ID)
+				}
+			}
+			message.Items = append(message.Items, item)
+			// Articles inserted meanwhile shift the pages, don't add one twice
+			sent[article.ID] = true
+		}
+		if len(articles) < candidatePageSize {
+			break
+		}
+	}
+
+	if len(message.Items) == 1 {
+		message.Subject = fmt.Sprintf("%s: 1 new article", d.Name)
+	} else {
+		message.Subject = fmt.Sprintf("%s: %d new articles", d.Name, len(message.Items))
+	}
+	return message, nil
+}
+
+// summarize returns the AI summary of an article and caches it like generated summaries,
+// or "" if it has no content or the summary fails
+func (s *Service) summarize(summarizer Summarizer, articleID int64) string {
+	if s.aiTracker != nil && s.aiTracker.IsLimitReached() {
+		return ""
+	}
+	content, found, err := s.db.GetArticleContent(articleID)
+	if err != nil || !found {
+		return ""
+	}
+	// Only the text is sent, without the markup of the page
+	if content = utils.HTMLToText(content); content == "" {
+		return ""
+	}
+
+	if s.aiTracker != nil {
+		s.aiTracker.WaitForRateLimit()
+	}
+	result, err := summarizer.Summarize(content, s.summaryLength())
+	if err != nil {
+		log.Printf("Digest summary of article %d failed: %v", articleID, err)
+		return ""
+	}
+	if result.IsTooShort || result.Summary == "" {
+		return ""
+	}
+
+	if s.aiTracker != nil {
+		s.aiTracker.TrackSummary(content, result.Summary)
+	}
+	_ = s.db.IncrementStat("ai_summary")
+	if err := s.db.UpdateArticleSummary(articleID, result.Summary); err != nil {
+		log.Printf("Error saving the summary of article %d: %v", articleID, err)
+	}
+	return result.Summary
+}
+
+// summaryLength returns the configured summary length
+func (s *Service) summaryLength() summary.SummaryLength {
+	switch length, _ := s.db.GetSetting("summary_length"); length {
+	case "short":
+		return summary.Short
+	case "long":
+		return summary.Long
+	}
+	return summary.Medium
+}
+
+// aiSummarizer creates an AISummarizer from the AI settings
+func (s *Service) aiSummarizer() Summarizer {
+	apiKey, _ := s.db.GetEncryptedSetting("ai_api_key")
+	endpoint, _ := s.db.GetSetting("ai_endpoint")
+	model, _ := s.db.GetSetting("ai_model")
+	systemPrompt, _ := s.db.GetSetting("ai_summary_prompt")
+	customHeaders, _ := s.db.GetSetting("ai_custom_headers")
+	language, _ := s.db.GetSetting("language")
+
+	aiSummarizer := summary.NewAISummarizerWithDB(apiKey, endpoint, model, s.db)
+	if systemPrompt != "" {
+		aiSummarizer.SetSystemPrompt(systemPrompt)
+	}
+	if customHeaders != "" {
+		aiSummarizer.SetCustomHeaders(customHeaders)
+	}
+	if language != "" {
+		aiSummarizer.SetLanguage(language)
+	}
+	return aiSummarizer
+}
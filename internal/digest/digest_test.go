@@ -0,0 +1,425 @@
+package digest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/summary"
+)
+
+// sinkMessage is a message received by smtpSink
+type sinkMessage struct {
+	from string
+	to   []string
+	data []byte
+	tls  bool   // Sent after STARTTLS
+	auth string // Decoded AUTH PLAIN credentials
+}
+
+// smtpSink is a minimal SMTP server that keeps the messages it receives.
+// It offers STARTTLS if it has a TLS config.
+type smtpSink struct {
+	addr      *net.TCPAddr
+	tlsConfig *tls.Config
+	messages  chan sinkMessage
+}
+
+func startSMTPSink(t *testing.T, tlsConfig *tls.Config) *smtpSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sink := &smtpSink{addr: ln.Addr().(*net.TCPAddr), tlsConfig: tlsConfig, messages: make(chan sinkMessage, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go sink.serve(conn)
+		}
+	}()
+	return sink
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer func() { conn.Close() }() // conn is replaced after STARTTLS
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 sink ESMTP")
+
+	var msg sinkMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(command) {
+		case "EHLO":
+			tp.PrintfLine("250-sink")
+			if s.tlsConfig != nil && !msg.tls {
+				tp.PrintfLine("250-STARTTLS")
+			}
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			tp.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			msg.tls = true
+		case "AUTH":
+			_, initial, _ := strings.Cut(arg, " ")
+			credentials, _ := base64.StdEncoding.DecodeString(initial)
+			msg.auth = string(credentials)
+			tp.PrintfLine("235 Authenticated")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 Go ahead")
+			msg.data, err = tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			tp.PrintfLine("250 Queued")
+			s.messages <- msg
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+// fakeSummarizer returns the content it was given as the summary
+type fakeSummarizer struct {
+	calls int
+}
+
+func (f *fakeSummarizer) Summarize(text string, length summary.SummaryLength) (summary.SummaryResult, error) {
+	f.calls++
+	return summary.SummaryResult{Summary: "AI summary of " + text}, nil
+}
+
+func setupTestService(t *testing.T) (*Service, *database.DB) {
+	t.Helper()
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewService(db, nil), db
+}
+
+func addArticle(t *testing.T, db *database.DB, feedID int64, title string, read bool) int64 {
+	t.Helper()
+	if err := db.SaveArticle(&models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + title, IsRead: read, PublishedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveArticle error: %v", err)
+	}
+	articles, err := db.GetArticles("", feedID, "", true, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, article := range articles {
+		if article.Title == title {
+			return article.ID
+		}
+	}
+	t.Fatalf("article %q not saved", title)
+	return 0
+}
+
+// parts returns the plain text and HTML parts of a digest email
+func parts(t *testing.T, data []byte) (string, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("invalid content type: %v", err)
+	}
+	var text, html string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			html = string(body)
+		} else {
+			text = string(body)
+		}
+	}
+	return text, html
+}
+
+func TestSendDigest(t *testing.T) {
+	s, db := setupTestService(t)
+
+	// The sink offers STARTTLS with the certificate of a test TLS server
+	tlsServer := httptest.NewTLSServer(nil)
+	defer tlsServer.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(tlsServer.Certificate())
+	sink := startSMTPSink(t, tlsServer.TLS)
+
+	db.SetSetting("smtp_host", "127.0.0.1")
+	db.SetSetting("smtp_port", strconv.Itoa(sink.addr.Port))
+	db.SetSetting("smtp_security", SecuritySTARTTLS)
+	db.SetSetting("smtp_from", "MrRSS <digest@example.com>")
+	db.SetEncryptedSetting("smtp_username", "team")
+	db.SetEncryptedSetting("smtp_password", "s3cret")
+	if stored, _ := db.GetSetting("smtp_password"); stored == "s3cret" {
+		t.Error("SMTP password stored in plain text")
+	}
+	s.loadSMTPConfig = func() (*SMTPConfig, error) {
+		config, err := LoadSMTPConfig(db)
+		if config != nil {
+			config.rootCAs = rootCAs
+		}
+		return config, err
+	}
+	summarizer := &fakeSummarizer{}
+	s.newSummarizer = func() Summarizer { return summarizer }
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "News", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withContent := addArticle(t, db, feedID, "Fresh & new", false)
+	db.SetArticleContent(withContent, "<p>Long article body</p>")
+	addArticle(t, db, feedID, "Also unread", false)
+	addArticle(t, db, feedID, "Already read", true)
+
+	d := models.Digest{
+		Name:       "Team digest",
+		Enabled:    true,
+		Schedule:   models.DigestDaily,
+		Hour:       8,
+		Recipients: []string{"Team <team@example.com>", " ", "boss@example.com"},
+		Conditions: []models.FilterCondition{{Field: "is_read", Value: "false"}},
+		AISummary:  true,
+	}
+	if err := Validate(&d); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if d.ID, err = db.CreateDigest(d); err != nil {
+		t.Fatal(err)
+	}
+
+	send, err := s.Send(context.Background(), &d, time.Now())
+	if err != nil {
+		t.Fatalf("Send error: %v", err)
+	}
+	if send == nil || send.Status != models.DigestSendSent || send.ArticleCount != 2 {
+		t.Fatalf("send = %+v", send)
+	}
+
+	var msg sinkMessage
+	select {
+	case msg = <-sink.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+	if !msg.tls {
+		t.Error("message sent without STARTTLS")
+	}
+	if msg.auth != "\x00team\x00s3cret" {
+		t.Errorf("auth = %q", msg.auth)
+	}
+	if msg.from != "digest@example.com" || strings.Join(msg.to, ",") != "team@example.com,boss@example.com" {
+		t.Errorf("envelope from %s to %v", msg.from, msg.to)
+	}
+	text, html := parts(t, msg.data)
+	for _, want := range []string{"Fresh & new", "Also unread", "AI summary of Long article body", "https://example.com/Also unread"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("plain text part misses %q:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(html, "Fresh &amp; new") || !strings.Contains(html, "AI summary of Long article body") {
+		t.Errorf("HTML part is not escaped:\n%s", html)
+	}
+	if strings.Contains(text, "Already read") {
+		t.Error("read article included")
+	}
+	if summarizer.calls != 1 {
+		t.Errorf("%d summaries generated, want 1", summarizer.calls)
+	}
+	if article, _ := db.GetArticleByID(withContent); article.Summary == "" {
+		t.Error("generated summary not saved with the article")
+	}
+
+	// Sent articles are not sent again
+	send, err = s.Send(context.Background(), &d, time.Now())
+	if err != nil || send != nil {
+		t.Fatalf("second send = %+v, %v, want nothing to send", send, err)
+	}
+	newID := addArticle(t, db, feedID, "Breaking", false)
+
+	// A manual send overlapping a scheduled one doesn't email the article twice
+	sends := make(chan *models.DigestSend, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			send, err := s.Send(context.Background(), &d, time.Now())
+			if err != nil {
+				t.Errorf("concurrent send error: %v", err)
+			}
+			sends <- send
+		}()
+	}
+	first, second := <-sends, <-sends
+	if first == nil {
+		first, second = second, first
+	}
+	if first == nil || second != nil || len(first.ArticleIDs) != 1 || first.ArticleIDs[0] != newID {
+		t.Fatalf("concurrent sends = %+v, %+v, want only one with the new article", first, second)
+	}
+	<-sink.messages
+
+	history, err := db.GetDigestSends(d.ID, 0)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("sends = %+v, %v", history, err)
+	}
+	if history[0].ArticleCount != 1 || history[1].ArticleCount != 2 || len(history[1].ArticleIDs) != 2 {
+		t.Errorf("unexpected send history: %+v", history)
+	}
+}
+
+func TestBuild_PagesThroughCandidates(t *testing.T) {
+	s, db := setupTestService(t)
+	feedID, err := db.AddFeed(&models.Feed{Title: "News", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The only unread article is older than a page of read ones
+	now := time.Now()
+	articles := []*models.Article{{FeedID: feedID, Title: "Old unread", URL: "https://example.com/old", PublishedAt: now.Add(-time.Hour)}}
+	for i := 0; i < candidatePageSize+10; i++ {
+		title := "Read " + strconv.Itoa(i)
+		articles = append(articles, &models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + title, IsRead: true, PublishedAt: now})
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &models.Digest{Name: "Unread", Conditions: []models.FilterCondition{{Field: "is_read", Value: "false"}}}
+	message, err := s.Build(d, now)
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	if len(message.Items) != 1 || message.Items[0].Title != "Old unread" {
+		t.Errorf("items = %+v, want the unread article from the second page", message.Items)
+	}
+}
+
+func TestSendDigest_RequiresSTARTTLS(t *testing.T) {
+	s, db := setupTestService(t)
+	sink := startSMTPSink(t, nil)
+	s.loadSMTPConfig = func() (*SMTPConfig, error) {
+		return &SMTPConfig{Host: "127.0.0.1", Port: sink.addr.Port, Security: SecuritySTARTTLS, From: "digest@example.com"}, nil
+	}
+
+	feedID, _ := db.AddFeed(&models.Feed{Title: "News", URL: "https://example.com/feed"})
+	addArticle(t, db, feedID, "Story", false)
+	d := models.Digest{Name: "Digest", Schedule: models.DigestDaily, Recipients: []string{"team@example.com"}}
+	d.ID, _ = db.CreateDigest(d)
+
+	send, err := s.Send(context.Background(), &d, time.Now())
+	if !errors.Is(err, ErrNoSTARTTLS) {
+		t.Fatalf("err = %v, want ErrNoSTARTTLS", err)
+	}
+	if send == nil || send.Status != models.DigestSendFailed {
+		t.Errorf("send = %+v, want a failed send", send)
+	}
+
+	// The articles of a failed send are sent the next time
+	message, err := s.Build(&d, time.Now())
+	if err != nil || len(message.Items) != 1 {
+		t.Errorf("next digest = %+v, %v", message, err)
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.Local) }
+	// March 2, 2026 is a Monday
+	daily := &models.Digest{Schedule: models.DigestDaily, Hour: 8, CreatedAt: at(2, 7)}
+	weekly := &models.Digest{Schedule: models.DigestWeekly, Weekday: int(time.Wednesday), Hour: 8, CreatedAt: at(2, 7)}
+
+	tests := []struct {
+		digest  *models.Digest
+		lastRun time.Time
+		now     time.Time
+		want    bool
+	}{
+		{daily, time.Time{}, at(2, 7), false},
+		{daily, time.Time{}, at(2, 8), true},
+		{daily, at(2, 8), at(2, 23), false},
+		{daily, at(2, 8), at(3, 8), true},
+		{weekly, time.Time{}, at(3, 9), false},
+		{weekly, time.Time{}, at(4, 8), true},
+		{weekly, at(4, 8), at(10, 9), false},
+		{weekly, at(4, 8), at(11, 8), true},
+	}
+	for i, tt := range tests {
+		d := *tt.digest
+		if !tt.lastRun.IsZero() {
+			d.LastRunAt = &tt.lastRun
+		}
+		if got := IsDue(&d, tt.now); got != tt.want {
+			t.Errorf("case %d: IsDue(%s at %v) = %v, want %v", i, d.Schedule, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []models.Digest{
+		{Schedule: models.DigestDaily, Recipients: []string{"team@example.com"}},
+		{Name: "Digest", Schedule: "monthly", Recipients: []string{"team@example.com"}},
+		{Name: "Digest", Schedule: models.DigestWeekly, Weekday: 7, Recipients: []string{"team@example.com"}},
+		{Name: "Digest", Schedule: models.DigestDaily, Hour: 24, Recipients: []string{"team@example.com"}},
+		{Name: "Digest", Schedule: models.DigestDaily, Recipients: []string{"not an address"}},
+		{Name: "Digest", Schedule: models.DigestDaily},
+	}
+	for i, d := range invalid {
+		if err := Validate(&d); !errors.Is(err, ErrInvalidDigest) {
+			t.Errorf("case %d: err = %v, want ErrInvalidDigest", i, err)
+		}
+	}
+}
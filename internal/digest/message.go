@@ -0,0 +1,111 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed digest.html
+var htmlTemplateSource string
+
+//go:embed digest.txt
+var textTemplateSource string
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Parse(htmlTemplateSource))
+	textTemplate = texttemplate.Must(texttemplate.New("digest.txt").Parse(textTemplateSource))
+)
+
+// Item is an article in a digest email
+type Item struct {
+	ArticleID   int64
+	Title       string
+	URL         string
+	FeedTitle   string
+	PublishedAt time.Time
+	Summary     string
+}
+
+// Message is the content of a digest email, rendered by the HTML and plain text templates
+type Message struct {
+	Name    string
+	Subject string
+	Date    time.Time
+	Items   []Item
+}
+
+// Render builds the MIME message with an HTML and a plain text part
+func (m *Message) Render(from string, recipients []string) ([]byte, error) {
+	var html, text bytes.Buffer
+	if err := htmlTemplate.Execute(&html, m); err != nil {
+		return nil, fmt.Errorf("failed to render the HTML digest: %w", err)
+	}
+	if err := textTemplate.Execute(&text, m); err != nil {
+		return nil, fmt.Errorf("failed to render the plain text digest: %w", err)
+	}
+
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+	domain := "mrrss.local"
+	if at := strings.LastIndex(sender.Address, "@"); at >= 0 {
+		domain = sender.Address[at+1:]
+	}
+	id := make([]byte, 12)
+	rand.Read(id)
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	header := []string{
+		"From: " + sender.String(),
+		"To: " + strings.Join(recipients, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date: " + m.Date.Format(time.RFC1123Z),
+		fmt.Sprintf("Message-ID: <%s@%s>", hex.EncodeToString(id), domain),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + parts.Boundary(),
+	}
+
+	// Mail clients show the last alternative they support, so HTML comes last
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	message := strings.Join(header, "\r\n") + "\r\n\r\n"
+	return append([]byte(message), body.Bytes()...), nil
+}
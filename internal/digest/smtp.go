@@ -0,0 +1,153 @@
+package digest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"MrRSS/internal/database"
+)
+
+// SMTP connection security, stored in the smtp_security setting
+const (
+	SecuritySTARTTLS = "starttls" // Plain connection upgraded with STARTTLS (port 587)
+	SecurityTLS      = "tls"      // Implicit TLS (port 465)
+	SecurityNone     = "none"     // No encryption, only for local relays
+)
+
+// smtpTimeout limits a whole SMTP session
+const smtpTimeout = 2 * time.Minute
+
+var (
+	// ErrSMTPNotConfigured is returned when no SMTP server or sender address is set
+	ErrSMTPNotConfigured = errors.New("SMTP server and sender address are not configured")
+	// ErrNoSTARTTLS is returned when the server doesn't offer STARTTLS although it is required
+	ErrNoSTARTTLS = errors.New("SMTP server does not support STARTTLS")
+)
+
+// SMTPConfig is the mail server digests are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Security string
+	Username string
+	Password string
+	From     string // Sender, e.g. "MrRSS <digest@example.com>"
+
+	rootCAs *x509.CertPool // Trusted certificates, nil for the system pool
+}
+
+// LoadSMTPConfig reads the SMTP settings. The credentials are stored encrypted.
+func LoadSMTPConfig(db *database.DB) (*SMTPConfig, error) {
+	host, _ := db.GetSetting("smtp_host")
+	from, _ := db.GetSetting("smtp_from")
+	if host == "" || from == "" {
+		return nil, ErrSMTPNotConfigured
+	}
+
+	portStr, _ := db.GetSetting("smtp_port")
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 {
+		port = 587
+	}
+	security, _ := db.GetSetting("smtp_security")
+	if security == "" {
+		security = SecuritySTARTTLS
+	}
+	username, err := db.GetEncryptedSetting("smtp_username")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the SMTP username: %w", err)
+	}
+	password, err := db.GetEncryptedSetting("smtp_password")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the SMTP password: %w", err)
+	}
+
+	return &SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Security: security,
+		Username: username,
+		Password: password,
+		From:     from,
+	}, nil
+}
+
+// Send delivers a message to the recipients
+func (c *SMTPConfig) Send(ctx context.Context, recipients []string, message []byte) error {
+	from, err := mail.ParseAddress(c.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", c.From, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	tlsConfig := &tls.Config{ServerName: c.Host, RootCAs: c.rootCAs}
+
+	var conn net.Conn
+	switch c.Security {
+	case SecurityTLS:
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	case SecuritySTARTTLS, SecurityNone:
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	default:
+		return fmt.Errorf("unknown SMTP security %q", c.Security)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.Security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return ErrNoSTARTTLS
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	// PlainAuth refuses to send the password over an unencrypted connection to another host
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
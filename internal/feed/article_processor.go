@@ -93,6 +93,8 @@ func (f *Fetcher) processArticles(feed models.Feed, items []*gofeed.Item) []*Art
 			PublishedAt:           published,
 			HasValidPublishedTime: hasValidPublishedTime,
 			TranslatedTitle:       translatedTitle,
+			Author:                extractAuthor(item),
+			Categories:            extractCategories(item),
 		}
 
 		articlesWithContent = append(articlesWithContent, &ArticleWithContent{
@@ -104,6 +106,52 @@ func (f *Fetcher) processArticles(feed models.Feed, items []*gofeed.Item) []*Art
 	return articlesWithContent
 }
 
+// extractAuthor returns the item author names, joined with ", " when there are several.
+// Falls back to the email address for authors without a name.
+func extractAuthor(item *gofeed.Item) string {
+	people := item.Authors
+	if len(people) == 0 && item.Author != nil {
+		people = []*gofeed.Person{item.Author}
+	}
+
+	var names []string
+	for _, person := range people {
+		if person == nil {
+			continue
+		}
+		name := strings.TrimSpace(person.Name)
+		if name == "" {
+			name = strings.TrimSpace(person.Email)
+		}
+		if name != "" && !containsFold(names, name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// extractCategories returns the trimmed, de-duplicated item categories
+func extractCategories(item *gofeed.Item) []string {
+	var categories []string
+	for _, category := range item.Categories {
+		category = strings.TrimSpace(category)
+		if category != "" && !containsFold(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractImageURL extracts the image URL from a feed item and resolves relative URLs
 func extractImageURL(item *gofeed.Item, feedURL string) string {
 	// Try item.Image first
@@ -456,3 +456,29 @@ func TestProcessArticlesWithYouTubeFeed(t *testing.T) {
 		t.Errorf("Expected video URL '%s', got '%s'", expectedVideoURL, article.VideoURL)
 	}
 }
+
+func TestExtractAuthorAndCategories(t *testing.T) {
+	item := &gofeed.Item{
+		Authors: []*gofeed.Person{
+			{Name: "Jane Doe"},
+			{Email: "john@example.com"},
+			{Name: "jane doe"},
+		},
+		Categories: []string{" Go ", "go", "", "Release"},
+	}
+
+	if got := extractAuthor(item); got != "Jane Doe, john@example.com" {
+		t.Errorf("extractAuthor() = %q", got)
+	}
+
+	categories := extractCategories(item)
+	if len(categories) != 2 || categories[0] != "Go" || categories[1] != "Release" {
+		t.Errorf("extractCategories() = %v", categories)
+	}
+
+	// XPath feeds only set the deprecated single Author field
+	legacy := &gofeed.Item{Author: &gofeed.Person{Name: "Solo"}}
+	if got := extractAuthor(legacy); got != "Solo" {
+		t.Errorf("extractAuthor() with Author = %q", got)
+	}
+}
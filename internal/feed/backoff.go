@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Failure backoff limits for feeds that keep failing
+const (
+	backoffBase = 5 * time.Minute
+	backoffMax  = 24 * time.Hour
+)
+
+// HTTPStatusError is returned when a feed server answers with a non-OK status.
+// RetryAfter is set when a 429 or 503 response carried a Retry-After header.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Time
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Status)
+}
+
+// IsRateLimited reports whether the server asked us to slow down
+func (e *HTTPStatusError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// newHTTPStatusError builds the error for a non-OK response
+func newHTTPStatusError(resp *http.Response) *HTTPStatusError {
+	err := &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if err.IsRateLimited() {
+		err.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return err
+}
+
+// parseRetryAfter parses a Retry-After header given either as delay seconds or as an HTTP date.
+// It returns the zero time if the header is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return time.Time{}
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t
+	}
+	return time.Time{}
+}
+
+// backoffDelay returns how long to wait before the next scheduled refresh of a feed
+// that failed the given number of times in a row. The delay doubles with every failure
+// up to backoffMax, and is randomized between half and the full delay so feeds that
+// failed together don't retry together.
+func backoffDelay(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+
+	delay := backoffBase
+	for i := 1; i < failures && delay < backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > backoffMax {
+		delay = backoffMax
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
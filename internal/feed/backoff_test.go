@@ -0,0 +1,43 @@
+package feed
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	if got := parseRetryAfter("120", now); !got.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("delay seconds: got %v", got)
+	}
+	date := now.Add(time.Hour)
+	if got := parseRetryAfter(date.Format(http.TimeFormat), now); !got.Equal(date) {
+		t.Errorf("HTTP date: got %v, want %v", got, date)
+	}
+	for _, value := range []string{"", "-5", "soon", now.Add(-time.Hour).Format(http.TimeFormat)} {
+		if got := parseRetryAfter(value, now); !got.IsZero() {
+			t.Errorf("parseRetryAfter(%q) = %v, want zero time", value, got)
+		}
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	if backoffDelay(0) != 0 {
+		t.Error("expected no backoff without failures")
+	}
+
+	for failures := 1; failures <= 20; failures++ {
+		full := backoffBase << (failures - 1)
+		if failures > 10 || full > backoffMax {
+			full = backoffMax
+		}
+		for i := 0; i < 20; i++ {
+			d := backoffDelay(failures)
+			if d < full/2 || d > full {
+				t.Fatalf("backoffDelay(%d) = %v, want between %v and %v", failures, d, full/2, full)
+			}
+		}
+	}
+}
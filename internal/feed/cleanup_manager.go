@@ -301,3 +301,13 @@ func (cm *CleanupManager) CheckSizeAndCleanup() {
 		cm.RequestCleanup()
 	}
 }
+
+// HasRoomForContent reports whether the database is below the configured cache size,
+// so optional content such as eagerly fetched full text can still be stored
+func (cm *CleanupManager) HasRoomForContent() bool {
+	currentSizeMB, err := cm.fetcher.db.GetDatabaseSizeMB()
+	if err != nil {
+		return true
+	}
+	return currentSizeMB < cm.getTargetSize()
+}
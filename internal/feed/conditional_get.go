@@ -0,0 +1,34 @@
+package feed
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrNotModified is returned when a conditional fetch reports that the feed
+// has not changed since the last successful fetch (HTTP 304 Not Modified).
+var ErrNotModified = errors.New("feed not modified")
+
+// FeedValidators holds the HTTP cache validators used for conditional GET requests.
+type FeedValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// apply sets the If-None-Match and If-Modified-Since headers on the request.
+func (v FeedValidators) apply(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// validatorsFromResponse extracts the cache validators from an HTTP response.
+func validatorsFromResponse(resp *http.Response) FeedValidators {
+	return FeedValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
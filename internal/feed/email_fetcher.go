@@ -4,12 +4,19 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"html/template"
 	"io"
+	"log"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/emersion/go-imap"
 	"github.com/emersion/go-imap/client"
+	_ "github.com/emersion/go-message/charset" // Decodes messages in other charsets than UTF-8
+	"github.com/emersion/go-message/mail"
 	"github.com/mmcdole/gofeed"
 
 	"MrRSS/internal/database"
@@ -20,22 +27,46 @@ import (
 type EmailFetcher struct {
 	db     *database.DB
 	parser *gofeed.Parser
+
+	idleMu       sync.Mutex
+	idleWatchers map[int64]context.CancelFunc // IMAP IDLE connections by feed ID
 }
 
 // NewEmailFetcher creates a new email fetcher
 func NewEmailFetcher(db *database.DB) *EmailFetcher {
 	return &EmailFetcher{
-		db:     db,
-		parser: gofeed.NewParser(),
+		db:           db,
+		parser:       gofeed.NewParser(),
+		idleWatchers: make(map[int64]context.CancelFunc),
 	}
 }
 
-// FetchEmails fetches new emails from IMAP and converts them to feed items
-func (ef *EmailFetcher) FetchEmails(ctx context.Context, feed *models.Feed) ([]*gofeed.Item, error) {
+// Custom fields of the items made from newsletter messages
+const (
+	emailItemListID   = "email_list_id"   // List-Id of the message, without the angle brackets
+	emailItemListName = "email_list_name" // Display name of the list
+)
+
+// FetchEmails fetches new emails from IMAP and converts them to feed items.
+// The first fetch looks at the last month, later fetches continue after the last
+// processed UID. Messages are selected by the feed's filters.
+//
+// The items are passed to save, if given. Only once it succeeds are the messages turned
+// into items flagged as read or moved on the server, and left out of later fetches.
+// Without save, nothing is changed on the server or in the feed.
+func (ef *EmailFetcher) FetchEmails(ctx context.Context, feed *models.Feed, save func([]*gofeed.Item) error) ([]*gofeed.Item, error) {
 	if feed.EmailIMAPServer == "" || feed.EmailUsername == "" || feed.EmailPassword == "" {
 		return nil, fmt.Errorf("IMAP credentials not configured")
 	}
 
+	settings, err := ef.db.GetFeedEmailSettings(feed.ID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.FeedEmailSettings{FeedID: feed.ID}
+	}
+
 	// Connect to IMAP server
 	c, err := ef.connectToIMAP(feed)
 	if err != nil {
@@ -58,22 +89,29 @@ func (ef *EmailFetcher) FetchEmails(ctx context.Context, feed *models.Feed) ([]*
 	// Search for emails newer than last processed UID
 	criteria := imap.NewSearchCriteria()
 	seqset := new(imap.SeqSet)
-	seqset.AddRange(fromUID, ^uint32(0)) // Use ^uint32(0) as max UID
+	seqset.AddRange(fromUID, 0) // 0 stands for the largest UID
 	criteria.Uid = seqset
-	criteria.Since = time.Now().AddDate(0, -1, 0) // Last 1 month
+	if feed.EmailLastUID == 0 {
+		criteria.Since = time.Now().AddDate(0, -1, 0) // Last 1 month
+	}
 
-	uids, err := c.Search(criteria)
+	uids, err := c.UidSearch(criteria)
 	if err != nil {
 		return nil, fmt.Errorf("IMAP search failed: %w", err)
 	}
+	// A range past the largest UID still matches the last message
+	uids = slices.DeleteFunc(uids, func(uid uint32) bool { return uid < fromUID })
 
 	if len(uids) == 0 {
 		return nil, nil
 	}
+	slices.Sort(uids)
 
 	// Fetch emails in batches
+	filters := compileEmailFilters(settings.Filters)
 	batchSize := 50
 	items := make([]*gofeed.Item, 0, len(uids))
+	var processed []uint32
 	maxUID := feed.EmailLastUID
 
 	for i := 0; i < len(uids); i += batchSize {
@@ -88,11 +126,24 @@ func (ef *EmailFetcher) FetchEmails(ctx context.Context, feed *models.Feed) ([]*
 			maxUID = int(batchUIDs[len(batchUIDs)-1])
 		}
 
-		batchItems, err := ef.fetchEmailBatch(c, batchUIDs)
+		batchItems, batchProcessed, err := ef.fetchEmailBatch(c, batchUIDs, filters)
 		if err != nil {
 			return nil, err
 		}
 		items = append(items, batchItems...)
+		processed = append(processed, batchProcessed...)
+	}
+
+	if save == nil {
+		return items, nil
+	}
+	if err := save(items); err != nil {
+		return nil, err
+	}
+
+	// Failing to flag or move messages doesn't lose them, so the items are still kept
+	if err := processEmailMessages(c, processed, settings); err != nil {
+		log.Printf("Failed to process newsletter messages of feed %s: %v", feed.Title, err)
 	}
 
 	// Update last UID if we processed new emails
@@ -125,8 +176,13 @@ func (ef *EmailFetcher) connectToIMAP(feed *models.Feed) (*client.Client, error)
 		}
 	}
 
-	// Login
-	if err := c.Login(feed.EmailUsername, feed.EmailPassword); err != nil {
+	// Login; the password is stored encrypted
+	password, err := ef.db.DecryptSecret(feed.EmailPassword)
+	if err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("failed to decrypt IMAP password: %w", err)
+	}
+	if err := c.Login(feed.EmailUsername, password); err != nil {
 		c.Logout()
 		return nil, fmt.Errorf("IMAP authentication failed: %w", err)
 	}
@@ -134,46 +190,54 @@ func (ef *EmailFetcher) connectToIMAP(feed *models.Feed) (*client.Client, error)
 	return c, nil
 }
 
-// fetchEmailBatch fetches and parses a batch of emails
-func (ef *EmailFetcher) fetchEmailBatch(c *client.Client, uids []uint32) ([]*gofeed.Item, error) {
+// fetchEmailBatch fetches and parses a batch of emails. It returns the items of the
+// messages that pass the filters and their UIDs.
+func (ef *EmailFetcher) fetchEmailBatch(c *client.Client, uids []uint32, filters []emailFilter) ([]*gofeed.Item, []uint32, error) {
 	seqset := new(imap.SeqSet)
 	seqset.AddNum(uids...)
 
-	// Fetch the envelope and body
+	// Fetch the envelope and the whole message, without flagging it as read
+	section := &imap.BodySectionName{Peek: true}
 	messages := make(chan *imap.Message, len(uids))
-	err := c.Fetch(seqset, []imap.FetchItem{imap.FetchEnvelope, imap.FetchBody}, messages)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch messages: %w", err)
-	}
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(seqset, []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope, section.FetchItem()}, messages)
+	}()
 
 	items := make([]*gofeed.Item, 0, len(uids))
+	var processed []uint32
 
 	for msg := range messages {
-		if msg == nil {
-			break
+		if msg == nil || msg.Envelope == nil {
+			continue
 		}
 
-		item, err := ef.parseEmailToItem(msg)
+		item, err := ef.parseEmailToItem(msg, section)
 		if err != nil {
 			// Skip invalid emails but continue processing others
 			continue
 		}
 
-		if item != nil {
+		if item != nil && matchesEmailFilters(filters, item) {
 			items = append(items, item)
+			processed = append(processed, msg.Uid)
 		}
 	}
+	if err := <-done; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch messages: %w", err)
+	}
 
-	return items, nil
+	return items, processed, nil
 }
 
 // parseEmailToItem converts an IMAP message to a gofeed Item
-func (ef *EmailFetcher) parseEmailToItem(msg *imap.Message) (*gofeed.Item, error) {
+func (ef *EmailFetcher) parseEmailToItem(msg *imap.Message, section *imap.BodySectionName) (*gofeed.Item, error) {
 	item := &gofeed.Item{
 		Title:     msg.Envelope.Subject,
 		Link:      fmt.Sprintf("email://%d", msg.Uid),
 		GUID:      fmt.Sprintf("email-%d", msg.Uid),
 		Published: msg.Envelope.Date.Format(time.RFC1123),
+		Custom:    map[string]string{},
 	}
 
 	// Extract sender as author if available
@@ -191,8 +255,18 @@ func (ef *EmailFetcher) parseEmailToItem(msg *imap.Message) (*gofeed.Item, error
 		}
 	}
 
-	// Extract email body
-	if item.Description, _ = ef.extractEmailBody(msg); item.Description == "" {
+	// Extract the body and the List-Id of newsletters
+	var body string
+	if literal := msg.GetBody(section); literal != nil {
+		mr, err := mail.CreateReader(literal)
+		if err == nil {
+			if listID, err := mr.Header.Text("List-Id"); err == nil && listID != "" {
+				item.Custom[emailItemListID], item.Custom[emailItemListName] = parseListID(listID)
+			}
+			body = extractEmailBody(mr)
+		}
+	}
+	if item.Description = body; item.Description == "" {
 		// Fallback if no body found
 		item.Description = "(No content available)"
 	}
@@ -203,23 +277,131 @@ func (ef *EmailFetcher) parseEmailToItem(msg *imap.Message) (*gofeed.Item, error
 	return item, nil
 }
 
-// extractEmailBody extracts the text/HTML content from an email message
-func (ef *EmailFetcher) extractEmailBody(msg *imap.Message) (string, error) {
-	// Try to get the body section from the message
-	for _, r := range msg.Body {
-		// r is already an imap.Literal (io.Reader)
-		data, err := io.ReadAll(r)
+// extractEmailBody returns the HTML part of an email, or else its text part
+func extractEmailBody(mr *mail.Reader) string {
+	var html, text string
+	for {
+		part, err := mr.NextPart()
 		if err != nil {
+			// io.EOF after the last part; a broken part ends the message as well
+			break
+		}
+		header, ok := part.Header.(*mail.InlineHeader)
+		if !ok {
+			continue
+		}
+		mediaType, _, _ := header.ContentType()
+		data, err := io.ReadAll(part.Body)
+		if err != nil || strings.TrimSpace(string(data)) == "" {
 			continue
 		}
+		switch {
+		case mediaType == "text/html" && html == "":
+			html = string(data)
+		case (mediaType == "text/plain" || mediaType == "") && text == "":
+			text = string(data)
+		}
+	}
+
+	if html != "" {
+		return html
+	}
+	if text != "" {
+		return "<pre>" + template.HTMLEscapeString(text) + "</pre>"
+	}
+	return ""
+}
+
+// parseListID splits a List-Id header such as "Weekly News <news.example.com>" into
+// the list ID and its display name
+func parseListID(header string) (id, name string) {
+	header = strings.TrimSpace(header)
+	start, end := strings.LastIndex(header, "<"), strings.LastIndex(header, ">")
+	if start < 0 || end < start {
+		return strings.ToLower(header), ""
+	}
+	name = strings.Trim(strings.TrimSpace(header[:start]), `"`)
+	return strings.ToLower(strings.TrimSpace(header[start+1 : end])), name
+}
+
+// emailFilter is a filter of a feed with its regular expression compiled
+type emailFilter struct {
+	models.EmailFilter
+	re *regexp.Regexp // nil unless a valid regex filter
+}
+
+// compileEmailFilters prepares a feed's filters for the messages of a fetch
+func compileEmailFilters(filters []models.EmailFilter) []emailFilter {
+	compiled := make([]emailFilter, len(filters))
+	for i, filter := range filters {
+		compiled[i].EmailFilter = filter
+		if filter.Operator == models.EmailFilterRegex {
+			compiled[i].re, _ = regexp.Compile("(?i)" + filter.Value)
+		}
+	}
+	return compiled
+}
+
+// matchesEmailFilters reports whether the item of a message passes a feed's filters:
+// it must match one of the include filters, if there are any, and no exclude filter
+func matchesEmailFilters(filters []emailFilter, item *gofeed.Item) bool {
+	included, hasIncludes := false, false
+	for _, filter := range filters {
+		var values []string
+		switch filter.Field {
+		case models.EmailFilterSender:
+			if item.Author != nil {
+				values = []string{item.Author.Email, item.Author.Name}
+			}
+		case models.EmailFilterSubject:
+			values = []string{item.Title}
+		case models.EmailFilterListID:
+			values = []string{item.Custom[emailItemListID], item.Custom[emailItemListName]}
+		}
 
-		content := string(data)
-		if strings.TrimSpace(content) != "" {
-			return content, nil
+		matched := slices.ContainsFunc(values, func(value string) bool {
+			return value != "" && matchEmailFilter(filter, value)
+		})
+		if filter.Exclude {
+			if matched {
+				return false
+			}
+			continue
 		}
+		hasIncludes = true
+		included = included || matched
+	}
+	return included || !hasIncludes
+}
+
+// matchEmailFilter matches a value against a single filter, ignoring case
+func matchEmailFilter(filter emailFilter, value string) bool {
+	switch filter.Operator {
+	case models.EmailFilterExact:
+		return strings.EqualFold(value, filter.Value)
+	case models.EmailFilterRegex:
+		return filter.re != nil && filter.re.MatchString(value)
+	default:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(filter.Value))
 	}
+}
 
-	return "", fmt.Errorf("no body content found")
+// processEmailMessages flags the messages turned into items as read, or moves them,
+// as configured for the feed
+func processEmailMessages(c *client.Client, uids []uint32, settings *models.FeedEmailSettings) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uids...)
+
+	switch settings.ProcessedAction {
+	case models.EmailProcessedMarkRead:
+		return c.UidStore(seqset, imap.FormatFlagsOp(imap.AddFlags, true), []interface{}{imap.SeenFlag}, nil)
+	case models.EmailProcessedMove:
+		return c.UidMove(seqset, settings.MoveFolder)
+	}
+	return nil
 }
 
 // cleanEmailContent removes unnecessary elements from email HTML
@@ -0,0 +1,283 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+	"github.com/mmcdole/gofeed"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+// updatingBackend is a memory backend that reports new messages to IDLE connections
+type updatingBackend struct {
+	*memory.Backend
+	updates chan backend.Update
+}
+
+func (b *updatingBackend) Updates() <-chan backend.Update {
+	return b.updates
+}
+
+// startIMAPServer serves be on a local port. The memory backend has the user
+// "username" with password "password" and one message in INBOX.
+func startIMAPServer(t *testing.T, be backend.Backend) int {
+	t.Helper()
+	s := server.New(be)
+	s.AllowInsecureAuth = true
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(ln)
+	t.Cleanup(func() { s.Close() })
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func inbox(t *testing.T, be backend.Backend) *memory.Mailbox {
+	t.Helper()
+	user, err := be.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mbox, err := user.GetMailbox("INBOX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mbox.(*memory.Mailbox)
+}
+
+func deliver(t *testing.T, mbox *memory.Mailbox, from, listID, subject, contentType, body string) {
+	t.Helper()
+	message := "From: " + from + "\r\n" +
+		"To: me@example.com\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n"
+	if listID != "" {
+		message += "List-Id: " + listID + "\r\n"
+	}
+	message += "Content-Type: " + contentType + "\r\n\r\n" + body
+	if err := mbox.CreateMessage(nil, time.Now(), bytes.NewBufferString(message)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func addMailboxFeed(t *testing.T, db *database.DB, port int) *models.Feed {
+	t.Helper()
+	id, err := db.AddFeed(&models.Feed{
+		Title:           "Newsletters",
+		URL:             "email://me@example.com",
+		Type:            models.FeedTypeEmail,
+		EmailAddress:    "me@example.com",
+		EmailIMAPServer: "127.0.0.1",
+		EmailIMAPPort:   port,
+		EmailUsername:   "username",
+		EmailPassword:   "password",
+		EmailFolder:     "INBOX",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return feed
+}
+
+func articleTitles(t *testing.T, db *database.DB, feedID int64) []string {
+	t.Helper()
+	articles, err := db.GetArticles("", feedID, "", true, 50, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, article := range articles {
+		titles = append(titles, article.Title)
+	}
+	slices.Sort(titles)
+	return titles
+}
+
+func TestFetchEmails_FilterSplitAndMarkRead(t *testing.T) {
+	be := memory.New()
+	mbox := inbox(t, be)
+	deliver(t, mbox, "Weekly News <news@weekly.example.com>", "Weekly News <weekly.example.com>", "Issue 1", "text/html", "<p>First</p>")
+	deliver(t, mbox, "Shop <promo@shop.example>", "", "Big Sale", "text/plain", "Buy now")
+	deliver(t, mbox, "Weekly News <news@weekly.example.com>", "Weekly News <weekly.example.com>", "Issue 2", "text/html", "<p>Second</p>")
+
+	db := setupDBForFeedTests(t)
+	f := NewFetcher(db)
+	feed := addMailboxFeed(t, db, startIMAPServer(t, be))
+	err := db.SetFeedEmailSettings(&models.FeedEmailSettings{
+		FeedID:          feed.ID,
+		Filters:         []models.EmailFilter{{Field: models.EmailFilterSubject, Operator: models.EmailFilterContains, Value: "sale", Exclude: true}},
+		SplitByList:     true,
+		ProcessedAction: models.EmailProcessedMarkRead,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+
+	listFeedID, err := db.GetEmailListFeedID(feed.ID, "weekly.example.com")
+	if err != nil || listFeedID == 0 {
+		t.Fatalf("newsletter feed = %d, %v", listFeedID, err)
+	}
+	listFeed, _ := db.GetFeedByID(listFeedID)
+	if listFeed.Title != "Weekly News" || listFeed.Type != models.FeedTypeEmailList {
+		t.Errorf("newsletter feed = %+v", listFeed)
+	}
+	if got := articleTitles(t, db, listFeedID); !slices.Equal(got, []string{"Issue 1", "Issue 2"}) {
+		t.Errorf("newsletter articles = %v", got)
+	}
+	// The message without a List-Id stays in the mailbox feed, the sale is filtered out
+	if got := articleTitles(t, db, feed.ID); !slices.Equal(got, []string{"A little message, just for you"}) {
+		t.Errorf("mailbox articles = %v", got)
+	}
+
+	// Only the processed messages are flagged as read
+	for _, msg := range mbox.Messages {
+		seen := slices.Contains(msg.Flags, imap.SeenFlag)
+		sale := bytes.Contains(msg.Body, []byte("Big Sale"))
+		if seen == sale {
+			t.Errorf("message %d flags = %v", msg.Uid, msg.Flags)
+		}
+	}
+
+	// Later fetches only pick up new messages, and reuse the newsletter feed
+	deliver(t, mbox, "Weekly News <news@weekly.example.com>", "Weekly News <weekly.example.com>", "Issue 3", "text/plain", "Third")
+	feed, _ = db.GetFeedByID(feed.ID)
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("second fetch error: %v", err)
+	}
+	if got := articleTitles(t, db, listFeedID); !slices.Equal(got, []string{"Issue 1", "Issue 2", "Issue 3"}) {
+		t.Errorf("newsletter articles after the second fetch = %v", got)
+	}
+	if feeds, _ := db.GetFeeds(); len(feeds) != 2 {
+		t.Errorf("%d feeds, want the mailbox and one newsletter", len(feeds))
+	}
+
+	// A deleted newsletter feed isn't added again, and its messages are dropped
+	if err := db.DeleteFeed(listFeedID); err != nil {
+		t.Fatal(err)
+	}
+	deliver(t, mbox, "Weekly News <news@weekly.example.com>", "Weekly News <weekly.example.com>", "Issue 4", "text/plain", "Fourth")
+	feed, _ = db.GetFeedByID(feed.ID)
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("fetch after deleting the newsletter error: %v", err)
+	}
+	if feeds, _ := db.GetFeeds(); len(feeds) != 1 {
+		t.Errorf("%d feeds, want only the mailbox after deleting the newsletter", len(feeds))
+	}
+	if got := articleTitles(t, db, feed.ID); !slices.Equal(got, []string{"A little message, just for you"}) {
+		t.Errorf("mailbox articles after deleting the newsletter = %v", got)
+	}
+
+	// Deleting the mailbox deletes its newsletter feeds
+	if _, err := db.AddEmailListFeed(feed, "other.example.com", "Other"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeleteFeed(feed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if feeds, _ := db.GetFeeds(); len(feeds) != 0 {
+		t.Errorf("%d feeds left after deleting the mailbox", len(feeds))
+	}
+	if removed, _ := db.IsEmailListRemoved(feed.ID, "weekly.example.com"); removed {
+		t.Error("deleted newsletters still remembered after deleting the mailbox")
+	}
+}
+
+func TestFetchEmails_ProcessedAfterSave(t *testing.T) {
+	be := memory.New()
+	mbox := inbox(t, be)
+
+	db := setupDBForFeedTests(t)
+	ef := NewEmailFetcher(db)
+	feed := addMailboxFeed(t, db, startIMAPServer(t, be))
+	if err := db.SetFeedEmailSettings(&models.FeedEmailSettings{FeedID: feed.ID, ProcessedAction: models.EmailProcessedMarkRead}); err != nil {
+		t.Fatal(err)
+	}
+	// The message of the memory backend is flagged as seen already
+	deliver(t, mbox, "Weekly News <news@weekly.example.com>", "", "Issue 1", "text/plain", "Hello")
+	seen := func() bool {
+		return slices.Contains(mbox.Messages[len(mbox.Messages)-1].Flags, imap.SeenFlag)
+	}
+
+	// A failed save leaves the messages alone, so the next fetch picks them up again
+	if _, err := ef.FetchEmails(context.Background(), feed, func([]*gofeed.Item) error { return errors.New("disk full") }); err == nil {
+		t.Fatal("expected the error of the save")
+	}
+	if feed, _ = db.GetFeedByID(feed.ID); seen() || feed.EmailLastUID != 0 {
+		t.Fatalf("messages processed before they were saved (seen: %v, last UID %d)", seen(), feed.EmailLastUID)
+	}
+
+	var saved []*gofeed.Item
+	if _, err := ef.FetchEmails(context.Background(), feed, func(items []*gofeed.Item) error { saved = items; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if feed, _ = db.GetFeedByID(feed.ID); len(saved) != 2 || !seen() || feed.EmailLastUID == 0 {
+		t.Errorf("saved %d items (seen: %v, last UID %d), want the message processed", len(saved), seen(), feed.EmailLastUID)
+	}
+}
+
+func TestEmailIdle(t *testing.T) {
+	be := &updatingBackend{Backend: memory.New(), updates: make(chan backend.Update)}
+	mbox := inbox(t, be)
+
+	db := setupDBForFeedTests(t)
+	ef := NewEmailFetcher(db)
+	feed := addMailboxFeed(t, db, startIMAPServer(t, be))
+
+	notified := make(chan int64, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ef.syncIdle(ctx, []int64{feed.ID}, func(feedID int64) { notified <- feedID })
+
+	waitForNotification := func(what string) {
+		t.Helper()
+		select {
+		case id := <-notified:
+			if id != feed.ID {
+				t.Errorf("notified feed %d, want %d", id, feed.ID)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no notification %s", what)
+		}
+	}
+	// Messages that arrived while disconnected are fetched on connecting
+	waitForNotification("on connecting")
+
+	// Give the client time to enter IDLE
+	time.Sleep(200 * time.Millisecond)
+	deliver(t, mbox, "Weekly News <news@weekly.example.com>", "", "Issue 1", "text/plain", "Hello")
+	status, err := mbox.Status([]imap.StatusItem{imap.StatusMessages})
+	if err != nil {
+		t.Fatal(err)
+	}
+	be.updates <- &backend.MailboxUpdate{Update: backend.NewUpdate("username", "INBOX"), MailboxStatus: status}
+	waitForNotification("for a new message")
+
+	// Feeds that no longer use IDLE are disconnected
+	ef.syncIdle(ctx, nil, nil)
+	ef.idleMu.Lock()
+	watchers := len(ef.idleWatchers)
+	ef.idleMu.Unlock()
+	if watchers != 0 {
+		t.Errorf("%d IDLE watchers left", watchers)
+	}
+}
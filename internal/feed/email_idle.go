@@ -0,0 +1,138 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/emersion/go-imap/client"
+)
+
+const (
+	// emailIdleRetryDelay is how long a broken IDLE connection waits before reconnecting.
+	// It doubles with every failure up to emailIdleMaxRetryDelay.
+	emailIdleRetryDelay    = 30 * time.Second
+	emailIdleMaxRetryDelay = 15 * time.Minute
+)
+
+// SyncEmailIdle starts IMAP IDLE connections for the newsletter feeds that enable them and
+// stops those of feeds that no longer do. When the server reports new messages, the feed
+// is refreshed right away. Regular refreshes keep running in case a connection misses one.
+func (f *Fetcher) SyncEmailIdle(ctx context.Context) {
+	feedIDs, err := f.db.GetIdleEmailFeedIDs()
+	if err != nil {
+		log.Printf("Error getting IMAP IDLE feeds: %v", err)
+		return
+	}
+
+	f.emailFetcher.syncIdle(ctx, feedIDs, func(feedID int64) {
+		feed, err := f.db.GetFeedByID(feedID)
+		if err != nil {
+			log.Printf("Error getting feed %d for IMAP IDLE: %v", feedID, err)
+			return
+		}
+		f.taskManager.AddToQueueHead(ctx, *feed, TaskReasonEmailPush)
+	})
+}
+
+// syncIdle runs one IDLE watcher for each of the given feeds and stops the others
+func (ef *EmailFetcher) syncIdle(ctx context.Context, feedIDs []int64, onNewMessages func(feedID int64)) {
+	ef.idleMu.Lock()
+	defer ef.idleMu.Unlock()
+
+	wanted := make(map[int64]bool, len(feedIDs))
+	for _, id := range feedIDs {
+		wanted[id] = true
+		if _, ok := ef.idleWatchers[id]; ok {
+			continue
+		}
+		watchCtx, cancel := context.WithCancel(ctx)
+		ef.idleWatchers[id] = cancel
+		go ef.watchIdle(watchCtx, id, onNewMessages)
+	}
+	for id, cancel := range ef.idleWatchers {
+		if !wanted[id] {
+			cancel()
+			delete(ef.idleWatchers, id)
+		}
+	}
+}
+
+// watchIdle keeps an IDLE connection to a feed's folder open until ctx is cancelled,
+// reconnecting after errors
+func (ef *EmailFetcher) watchIdle(ctx context.Context, feedID int64, onNewMessages func(feedID int64)) {
+	delay := emailIdleRetryDelay
+	for {
+		connected, err := ef.idle(ctx, feedID, onNewMessages)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = emailIdleRetryDelay
+		}
+		log.Printf("IMAP IDLE for feed %d stopped: %v, reconnecting in %v", feedID, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, emailIdleMaxRetryDelay)
+	}
+}
+
+// idle connects to a feed's folder and waits for new messages until the connection
+// breaks or ctx is cancelled. connected reports whether the folder could be selected.
+func (ef *EmailFetcher) idle(ctx context.Context, feedID int64, onNewMessages func(feedID int64)) (connected bool, err error) {
+	// Settings changed since the last connection take effect on reconnecting
+	feed, err := ef.db.GetFeedByID(feedID)
+	if err != nil {
+		return false, err
+	}
+	c, err := ef.connectToIMAP(feed)
+	if err != nil {
+		return false, err
+	}
+	defer c.Logout()
+
+	updates := make(chan client.Update, 16)
+	c.Updates = updates
+	status, err := c.Select(feed.EmailFolder, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to select mailbox %s: %w", feed.EmailFolder, err)
+	}
+	known := status.Messages
+
+	// Messages may have arrived while there was no connection
+	onNewMessages(feedID)
+
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Idle(stop, nil)
+	}()
+
+	for {
+		select {
+		case update := <-updates:
+			mailbox, ok := update.(*client.MailboxUpdate)
+			if !ok {
+				continue
+			}
+			if mailbox.Mailbox.Messages > known {
+				onNewMessages(feedID)
+			}
+			known = mailbox.Mailbox.Messages
+		case err := <-done:
+			if err == nil {
+				err = fmt.Errorf("connection closed")
+			}
+			return true, err
+		case <-ctx.Done():
+			close(stop)
+			<-done
+			return true, ctx.Err()
+		}
+	}
+}
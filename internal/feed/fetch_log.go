@@ -0,0 +1,176 @@
+package feed
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// Error classes recorded in the fetch log
+const (
+	ErrorClassDNS     = "dns"
+	ErrorClassTLS     = "tls"
+	ErrorClassTimeout = "timeout"
+	ErrorClassHTTP    = "http"
+	ErrorClassNetwork = "network"
+	ErrorClassParse   = "parse"
+	ErrorClassXPath   = "xpath"
+	ErrorClassScript  = "script"
+	ErrorClassOther   = "other"
+)
+
+// String returns the name recorded in the fetch log
+func (r TaskReason) String() string {
+	switch r {
+	case TaskReasonManualAdd:
+		return "manual_add"
+	case TaskReasonManualRefresh:
+		return "manual_refresh"
+	case TaskReasonScheduledCustom:
+		return "scheduled_custom"
+	case TaskReasonScheduledGlobal:
+		return "scheduled_global"
+	case TaskReasonArticleClick:
+		return "article_click"
+	case TaskReasonEmailPush:
+		return "email_push"
+	default:
+		return "unknown"
+	}
+}
+
+// fetchStats collects what a refresh attempt did while it runs through the fetch pipeline
+type fetchStats struct {
+	httpStatus   int
+	bytes        int64
+	newItems     int
+	updatedItems int
+}
+
+type fetchStatsKey struct{}
+
+// withFetchStats returns a context that collects fetch statistics into stats
+func withFetchStats(ctx context.Context, stats *fetchStats) context.Context {
+	return context.WithValue(ctx, fetchStatsKey{}, stats)
+}
+
+// fetchStatsFrom returns the statistics collector of ctx, or nil if there is none.
+// All fetchStats methods are safe to call on nil.
+func fetchStatsFrom(ctx context.Context) *fetchStats {
+	stats, _ := ctx.Value(fetchStatsKey{}).(*fetchStats)
+	return stats
+}
+
+func (s *fetchStats) recordResponse(status int) {
+	if s != nil {
+		s.httpStatus = status
+	}
+}
+
+func (s *fetchStats) recordBody(n int) {
+	if s != nil {
+		s.bytes += int64(n)
+	}
+}
+
+func (s *fetchStats) recordNewItems(n int) {
+	if s != nil {
+		s.newItems += n
+	}
+}
+
+func (s *fetchStats) recordUpdatedItems(n int) {
+	if s != nil {
+		s.updatedItems += n
+	}
+}
+
+// fetchAndLog runs one refresh attempt of a feed and records it in the fetch log
+func (f *Fetcher) fetchAndLog(ctx context.Context, feed models.Feed, reason TaskReason) error {
+	stats := &fetchStats{}
+	start := time.Now()
+	err := f.fetchFeedWithContext(withFetchStats(ctx, stats), feed)
+
+	entry := &models.FetchLogEntry{
+		FeedID:       feed.ID,
+		FetchedAt:    start,
+		DurationMs:   time.Since(start).Milliseconds(),
+		HTTPStatus:   stats.httpStatus,
+		Bytes:        stats.bytes,
+		NewItems:     stats.newItems,
+		UpdatedItems: stats.updatedItems,
+		Reason:       reason.String(),
+	}
+	if err != nil {
+		entry.ErrorClass = classifyFetchError(err)
+		entry.Error = err.Error()
+	}
+	if logErr := f.db.AddFetchLogEntry(entry); logErr != nil {
+		log.Printf("Error recording fetch log for feed %s: %v", feed.Title, logErr)
+	}
+	return err
+}
+
+// classifyFetchError maps a refresh error to an error class.
+// Network causes are checked first so an XPath or script fetch that failed on DNS is reported as DNS.
+func classifyFetchError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassDNS
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalidCert x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) || errors.As(err, &invalidCert) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &recordErr) {
+		return ErrorClassTLS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return ErrorClassHTTP
+	}
+
+	var xpathErr *XPathError
+	if errors.As(err, &xpathErr) {
+		return ErrorClassXPath
+	}
+
+	var scriptErr *ScriptError
+	if errors.As(err, &scriptErr) {
+		return ErrorClassScript
+	}
+
+	if errors.As(err, &netErr) {
+		return ErrorClassNetwork
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "tls:") || strings.Contains(msg, "x509:"):
+		return ErrorClassTLS
+	case strings.Contains(msg, "parse") || strings.Contains(msg, "xml syntax") ||
+		strings.Contains(msg, "feed type") || strings.Contains(msg, "valid rss"):
+		return ErrorClassParse
+	}
+	return ErrorClassOther
+}
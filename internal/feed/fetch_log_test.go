@@ -0,0 +1,134 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestClassifyFetchError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{fmt.Errorf("failed to fetch feed: %w", &net.DNSError{Err: "no such host", Name: "x.invalid"}), ErrorClassDNS},
+		{&XPathError{Operation: "fetch", Err: &net.DNSError{Err: "no such host"}}, ErrorClassDNS},
+		{errors.New("tls: failed to verify certificate: x509: certificate signed by unknown authority"), ErrorClassTLS},
+		{fmt.Errorf("failed to fetch feed: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{&HTTPStatusError{StatusCode: 404, Status: "404 Not Found"}, ErrorClassHTTP},
+		{&XPathError{Operation: "parse", Details: "bad"}, ErrorClassXPath},
+		{&ScriptError{Message: "exit 1"}, ErrorClassScript},
+		{errors.New("failed to parse feed: XML syntax error"), ErrorClassParse},
+		{errors.New("something else"), ErrorClassOther},
+	}
+	for _, tt := range tests {
+		if got := classifyFetchError(tt.err); got != tt.want {
+			t.Errorf("classifyFetchError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTaskManager_RecordsFetchLog(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	defer db.Close()
+
+	rss := `<?xml version="1.0"?><rss><channel><title>Log</title>` +
+		`<item><title>first</title><link>/1</link><guid>1</guid></item>` +
+		`<item><title>second</title><link>/2</link><guid>2</guid></item>` +
+		`</channel></rss>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rss))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "log", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	feed, _ := db.GetFeedByID(id)
+
+	tm := f.GetTaskManager()
+	tm.AddToQueueHead(context.Background(), *feed, TaskReasonManualRefresh)
+	if !tm.Wait(5 * time.Second) {
+		t.Fatal("task manager did not finish in time")
+	}
+
+	entries, err := db.GetFetchLog(id, 10)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected 1 fetch log entry, got %d (%v)", len(entries), err)
+	}
+	e := entries[0]
+	if e.HTTPStatus != 200 || e.Bytes != int64(len(rss)) || e.NewItems != 2 || e.ErrorClass != "" || e.Reason != "manual_refresh" {
+		t.Errorf("unexpected fetch log entry: %+v", e)
+	}
+}
+
+func TestTaskManager_RecordsUpdatedItems(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	defer db.Close()
+
+	body := "The minister resigned."
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss><channel><title>Log</title>`+
+			`<item><title>Minister</title><link>%s/1</link><description>%s</description></item>`+
+			`<item><title>Weather</title><link>%s/2</link><description>Sunny.</description></item>`+
+			`</channel></rss>`, srvURL, body, srvURL)
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "log", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	feed, _ := db.GetFeedByID(id)
+	tm := f.GetTaskManager()
+
+	refresh := func() models.FetchLogEntry {
+		t.Helper()
+		tm.AddToQueueHead(context.Background(), *feed, TaskReasonManualRefresh)
+		if !tm.Wait(5 * time.Second) {
+			t.Fatal("task manager did not finish in time")
+		}
+		time.Sleep(50 * time.Millisecond) // content caching runs in the background
+		entries, err := db.GetFetchLog(id, 1)
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("GetFetchLog = %v, %v", entries, err)
+		}
+		return entries[0]
+	}
+
+	if e := refresh(); e.NewItems != 2 || e.UpdatedItems != 0 {
+		t.Errorf("first refresh: %+v", e)
+	}
+	body = "The minister did not resign."
+	if e := refresh(); e.NewItems != 0 || e.UpdatedItems != 1 {
+		t.Errorf("refresh after an edit: %+v", e)
+	}
+}
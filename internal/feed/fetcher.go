@@ -3,10 +3,12 @@ package feed
 import (
 	"MrRSS/internal/database"
 	"MrRSS/internal/models"
+	"MrRSS/internal/notify"
 	"MrRSS/internal/rsshub"
 	"MrRSS/internal/rules"
 	"MrRSS/internal/utils"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -34,6 +36,9 @@ type Fetcher struct {
 	refreshCalculator *IntelligentRefreshCalculator
 	taskManager       *TaskManager
 	cleanupManager    *CleanupManager
+	sharedFetches     *SharedFetches     // nil unless downloads are shared with other users' fetchers
+	webSubPath        string             // path of the WebSub callbacks, joined with the feed ID
+	notifier          *notify.Dispatcher // nil unless new articles are sent to notification targets
 }
 
 func NewFetcher(db *database.DB) *Fetcher {
@@ -72,6 +77,7 @@ func NewFetcher(db *database.DB) *Fetcher {
 		scriptExecutor:    executor,
 		emailFetcher:      NewEmailFetcher(db),
 		refreshCalculator: NewIntelligentRefreshCalculator(db),
+		webSubPath:        DefaultWebSubPath,
 	}
 
 	// Initialize task manager with default capacity (increased from 5 to 10)
@@ -154,6 +160,42 @@ func (f *Fetcher) getConcurrencyLimit() int {
 	return concurrency
 }
 
+// getHostLimits returns the per-host concurrency cap and the minimum spacing
+// between requests to the same host
+func (f *Fetcher) getHostLimits() (int, time.Duration) {
+	maxPerHost := defaultMaxPerHost
+	if s, err := f.db.GetSetting("max_concurrent_per_host"); err == nil {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			maxPerHost = n
+		}
+	}
+
+	interval := defaultHostRequestWait
+	if s, err := f.db.GetSetting("host_request_interval_ms"); err == nil {
+		if ms, err := strconv.Atoi(s); err == nil && ms >= 0 {
+			interval = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return maxPerHost, interval
+}
+
+// updateDeadFeeds flags feeds that have been failing for longer than dead_feed_days
+func (f *Fetcher) updateDeadFeeds() {
+	days := 14
+	if s, err := f.db.GetSetting("dead_feed_days"); err == nil {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			days = n
+		}
+	}
+
+	flagged, err := f.db.UpdateDeadFeeds(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		log.Printf("Error updating dead feeds: %v", err)
+	} else if flagged > 0 {
+		log.Printf("Flagged %d feeds as dead (failing for more than %d days)", flagged, days)
+	}
+}
+
 // getHTTPClient returns an HTTP client configured with proxy if needed
 // Proxy precedence (highest to lowest):
 // 1. Feed custom proxy (ProxyEnabled=true, ProxyURL != "")
@@ -190,7 +232,30 @@ func (f *Fetcher) getHTTPClient(feed models.Feed) (*http.Client, error) {
 	)
 }
 
+// getFeedHTTPSettings returns the request settings of a saved feed, or nil if it has none
+func (f *Fetcher) getFeedHTTPSettings(feed *models.Feed) *models.FeedHTTPSettings {
+	if feed.ID == 0 {
+		return nil
+	}
+	settings, err := f.db.GetFeedHTTPSettings(feed.ID)
+	if err != nil {
+		log.Printf("Error loading HTTP settings for feed %d: %v", feed.ID, err)
+		return nil
+	}
+	return settings
+}
+
 func (f *Fetcher) FetchAll(ctx context.Context) {
+	f.fetchAll(ctx, false)
+}
+
+// FetchAllScheduled is FetchAll for the background scheduler.
+// Feeds that receive WebSub pushes are only polled at the fallback interval.
+func (f *Fetcher) FetchAllScheduled(ctx context.Context) {
+	f.fetchAll(ctx, true)
+}
+
+func (f *Fetcher) fetchAll(ctx context.Context, deferPushedFeeds bool) {
 	// Get all feeds
 	feeds, err := f.db.GetFeeds()
 	if err != nil {
@@ -198,6 +263,10 @@ func (f *Fetcher) FetchAll(ctx context.Context) {
 		return
 	}
 
+	if deferPushedFeeds {
+		feeds = f.FilterWebSubPushedFeeds(feeds)
+	}
+
 	if len(feeds) == 0 {
 		log.Println("No feeds to refresh")
 		// Mark progress as completed since there's nothing to do
@@ -211,6 +280,9 @@ func (f *Fetcher) FetchAll(ctx context.Context) {
 	for _, feed := range feeds {
 		if feed.IsFreshRSSSource {
 			freshRSSCount++
+		} else if feed.Type == models.FeedTypeEmailList {
+			// Refreshed with their mailbox
+			continue
 		} else {
 			filteredFeeds = append(filteredFeeds, feed)
 		}
@@ -242,14 +314,24 @@ func (f *Fetcher) FetchAll(ctx context.Context) {
 	// Update task manager capacity based on network
 	concurrency := f.getConcurrencyLimit()
 	f.taskManager.SetPoolCapacity(concurrency)
+	f.taskManager.SetHostLimits(f.getHostLimits())
+
+	// Feeds that are skipped while backing off still age towards the dead threshold
+	f.updateDeadFeeds()
 
 	// Use task manager for global refresh (all feeds go to queue tail)
 	f.taskManager.AddGlobalRefresh(ctx, filteredFeeds)
 }
 
 func (f *Fetcher) FetchFeed(ctx context.Context, feed models.Feed) {
-	// Use ParseFeedWithFeed with normal priority for feed refresh
-	parsedFeed, err := f.ParseFeedWithFeed(ctx, &feed, false) // Normal priority for refresh
+	// Use a conditional GET so unchanged feeds answer 304 without a body
+	parsedFeed, err := f.ParseFeedConditional(ctx, &feed)
+	if errors.Is(err, ErrNotModified) {
+		f.db.UpdateFeedError(feed.ID, "")
+		f.recordFetchResult(feed, true)
+		utils.DebugLog("Feed not modified: %s", feed.Title)
+		return
+	}
 	if err != nil {
 		log.Printf("Error parsing feed %s: %v", feed.URL, err)
 		f.db.UpdateFeedError(feed.ID, err.Error())
@@ -293,26 +375,24 @@ func (f *Fetcher) FetchFeed(ctx context.Context, feed models.Feed) {
 			articlesToSave[i] = awc.Article
 		}
 
-		if err := f.db.SaveArticles(ctx, articlesToSave); err != nil {
+		if _, err := f.db.SaveArticlesCounted(ctx, articlesToSave); err != nil {
 			log.Printf("Error saving articles for feed %s: %v", feed.Title, err)
 		} else {
+			f.recordArticleVersions(articlesWithContent)
+
 			// Cache article content from RSS feed
-			f.cacheArticleContents(articlesWithContent)
+			contents := f.cacheArticleContents(articlesWithContent)
+			f.clusterArticles(articlesWithContent)
 
-			// Apply rules to newly saved articles
-			// We fetch the recent articles for this feed since SaveArticles doesn't return IDs
-			// This is limited to the number of articles we just saved
+			f.applyRulesToNewArticles(feed, articlesToSave, contents)
 			savedArticles, err := f.db.GetArticles("", feed.ID, "", false, len(articlesToSave), 0)
 			if err == nil && len(savedArticles) > 0 {
-				engine := rules.NewEngine(f.db)
-				affected, err := engine.ApplyRulesToArticles(savedArticles)
-				if err != nil {
-					log.Printf("Error applying rules for feed %s: %v", feed.Title, err)
-				} else if affected > 0 {
-					utils.DebugLog("Applied rules to %d articles in feed %s", affected, feed.Title)
-				}
+				f.fetchEagerFullText(ctx, feed, savedArticles)
 			}
+			f.recordFetchResult(feed, false)
 		}
+	} else {
+		f.recordFetchResult(feed, false)
 	}
 	utils.DebugLog("Updated feed: %s", feed.Title)
 }
@@ -320,8 +400,34 @@ func (f *Fetcher) FetchFeed(ctx context.Context, feed models.Feed) {
 // fetchFeedWithContext is the internal fetch method used by TaskManager
 // Returns error instead of storing in progress.Errors
 func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) error {
-	// Use ParseFeedWithFeed with normal priority for feed refresh
-	parsedFeed, err := f.ParseFeedWithFeed(ctx, &feed, false)
+	// Newsletters split from a mailbox are refreshed with their mailbox
+	if feed.Type == models.FeedTypeEmailList {
+		mailboxID, err := f.db.GetEmailListMailboxID(feed.ID)
+		if err != nil {
+			return fmt.Errorf("mailbox of newsletter feed not found: %w", err)
+		}
+		mailbox, err := f.db.GetFeedByID(mailboxID)
+		if err != nil {
+			return fmt.Errorf("mailbox of newsletter feed not found: %w", err)
+		}
+		return f.fetchFeedWithContext(ctx, *mailbox)
+	}
+	if feed.Type == models.FeedTypeEmail {
+		if err := f.fetchEmailFeed(ctx, feed); err != nil {
+			return err
+		}
+		f.recordFetchResult(feed, false)
+		return nil
+	}
+
+	// Use a conditional GET so unchanged feeds answer 304 without a body
+	parsedFeed, err := f.ParseFeedConditional(ctx, &feed)
+	if errors.Is(err, ErrNotModified) {
+		// Not modified counts as a successful refresh; nothing to parse or save
+		f.recordFetchResult(feed, true)
+		utils.DebugLog("Feed not modified: %s", feed.Title)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -333,6 +439,20 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 	default:
 	}
 
+	if err := f.saveParsedFeed(ctx, feed, parsedFeed); err != nil {
+		return err
+	}
+
+	// Only remember the validators once the articles have been saved,
+	// otherwise a later 304 could hide articles we never stored
+	f.recordFetchResult(feed, false)
+	return nil
+}
+
+// saveParsedFeed runs a parsed feed through the article pipeline:
+// feed metadata updates, processArticles, SaveArticles, content caching and rules.
+// It is shared by polled refreshes and WebSub content pushes.
+func (f *Fetcher) saveParsedFeed(ctx context.Context, feed models.Feed, parsedFeed *gofeed.Feed) error {
 	// Clear any previous error on successful fetch
 	f.db.UpdateFeedError(feed.ID, "")
 
@@ -370,39 +490,201 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 			articlesToSave[i] = awc.Article
 		}
 
-		if err := f.db.SaveArticles(ctx, articlesToSave); err != nil {
+		inserted, err := f.db.SaveArticlesCounted(ctx, articlesToSave)
+		if err != nil {
 			return err
 		}
+		fetchStatsFrom(ctx).recordNewItems(inserted)
+		// Detect edited articles before the new content is cached over the old one
+		fetchStatsFrom(ctx).recordUpdatedItems(f.recordArticleVersions(articlesWithContent))
 
-		// Post-processing operations (content caching and rule application)
+		// Post-processing operations (content caching, rule application and eager full text)
 		// These are non-critical and run asynchronously to avoid blocking the feed refresh
 		// Even if they fail or are slow, the feed has already been successfully saved
 		go func() {
 			// Cache article content from RSS feed
-			f.cacheArticleContents(articlesWithContent)
+			contents := f.cacheArticleContents(articlesWithContent)
+			f.clusterArticles(articlesWithContent)
 
-			// Apply rules to newly saved articles
-			savedArticles, err := f.db.GetArticles("", feed.ID, "", false, len(articlesToSave), 0)
-			if err != nil {
-				log.Printf("Error getting articles for rule application: %v", err)
-				return
-			}
-			if len(savedArticles) == 0 {
-				return
+			f.applyRulesToNewArticles(feed, articlesToSave, contents)
+
+			// Notify about the articles that were inserted by this refresh, as the rules left them
+			if f.notifier != nil && inserted > 0 {
+				f.notifier.Enqueue(f.insertedArticles(articlesToSave), contents)
 			}
 
-			engine := rules.NewEngine(f.db)
-			affected, err := engine.ApplyRulesToArticles(savedArticles)
+			// Eager full text also retries the recent articles whose full text failed before
+			savedArticles, err := f.db.GetArticles("", feed.ID, "", false, len(articlesToSave), 0)
 			if err != nil {
-				log.Printf("Error applying rules for feed %s: %v", feed.Title, err)
-			} else if affected > 0 {
-				utils.DebugLog("Applied rules to %d articles in feed %s", affected, feed.Title)
+				log.Printf("Error getting articles for full text: %v", err)
+				return
 			}
+			// The refresh task's context is cancelled when the task ends,
+			// which must not stop the full text fetching
+			f.fetchEagerFullText(context.WithoutCancel(ctx), feed, savedArticles)
 		}()
 	}
 	return nil
 }
 
+// fetchEmailFeed fetches the new messages of a mailbox feed and saves them. The messages
+// are only flagged or moved on the server once they have been saved.
+func (f *Fetcher) fetchEmailFeed(ctx context.Context, feed models.Feed) error {
+	if f.emailFetcher == nil {
+		return fmt.Errorf("email fetcher not initialized")
+	}
+	_, err := f.emailFetcher.FetchEmails(ctx, &feed, func(items []*gofeed.Item) error {
+		// Check context before saving
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		return f.saveEmailFeed(ctx, feed, &gofeed.Feed{
+			Title:       feed.Title,
+			Link:        feed.URL,
+			Description: feed.Description,
+			Items:       items,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch emails: %w", err)
+	}
+	return nil
+}
+
+// saveEmailFeed saves the messages of a mailbox feed. When the mailbox is split by
+// newsletter, messages with a List-Id go to the newsletter's own feed, which is added
+// when the newsletter first arrives and not again once the user deleted it; the other
+// messages stay in the mailbox feed.
+func (f *Fetcher) saveEmailFeed(ctx context.Context, feed models.Feed, parsedFeed *gofeed.Feed) error {
+	settings, err := f.db.GetFeedEmailSettings(feed.ID)
+	if err != nil {
+		return err
+	}
+	if settings == nil || !settings.SplitByList {
+		return f.saveParsedFeed(ctx, feed, parsedFeed)
+	}
+
+	var mailboxItems []*gofeed.Item
+	var listIDs []string
+	listItems := make(map[string][]*gofeed.Item)
+	for _, item := range parsedFeed.Items {
+		listID := item.Custom[emailItemListID]
+		if listID == "" {
+			mailboxItems = append(mailboxItems, item)
+			continue
+		}
+		if _, ok := listItems[listID]; !ok {
+			listIDs = append(listIDs, listID)
+		}
+		listItems[listID] = append(listItems[listID], item)
+	}
+
+	for _, listID := range listIDs {
+		items := listItems[listID]
+		listFeed, err := f.emailListFeed(&feed, listID, items[0])
+		if err != nil {
+			return fmt.Errorf("failed to add feed of newsletter %s: %w", listID, err)
+		}
+		if listFeed == nil {
+			continue
+		}
+		if err := f.saveParsedFeed(ctx, *listFeed, &gofeed.Feed{Title: listFeed.Title, Link: listFeed.URL, Items: items}); err != nil {
+			return err
+		}
+	}
+
+	return f.saveParsedFeed(ctx, feed, &gofeed.Feed{
+		Title:       parsedFeed.Title,
+		Link:        parsedFeed.Link,
+		Description: parsedFeed.Description,
+		Items:       mailboxItems,
+	})
+}
+
+// emailListFeed returns the feed of a newsletter in a split mailbox, adding it if needed.
+// New feeds are named after the list, or else the sender of its first message. It returns
+// nil if the user deleted the newsletter's feed, whose messages are then dropped.
+func (f *Fetcher) emailListFeed(mailbox *models.Feed, listID string, first *gofeed.Item) (*models.Feed, error) {
+	feedID, err := f.db.GetEmailListFeedID(mailbox.ID, listID)
+	if err != nil {
+		return nil, err
+	}
+	if feedID == 0 {
+		if removed, err := f.db.IsEmailListRemoved(mailbox.ID, listID); err != nil || removed {
+			return nil, err
+		}
+		title := first.Custom[emailItemListName]
+		if title == "" && first.Author != nil {
+			title = first.Author.Name
+		}
+		if title == "" {
+			title = listID
+		}
+		if feedID, err = f.db.AddEmailListFeed(mailbox, listID, title); err != nil {
+			return nil, err
+		}
+		log.Printf("Added feed %q for newsletter %s in %s", title, listID, mailbox.Title)
+	}
+	return f.db.GetFeedByID(feedID)
+}
+
+// SetNotifier makes the fetcher send the articles it inserts to the notification targets
+func (f *Fetcher) SetNotifier(notifier *notify.Dispatcher) {
+	f.notifier = notifier
+}
+
+// applyRulesToNewArticles applies the rules to the saved articles that were newly inserted.
+// The others went through the rules when they were first saved, and actions such as
+// webhooks or full text must not run again on every refresh.
+func (f *Fetcher) applyRulesToNewArticles(feed models.Feed, saved []*models.Article, contents map[int64]string) {
+	newArticles := f.insertedArticles(saved)
+	if len(newArticles) == 0 {
+		return
+	}
+	engine := rules.NewEngine(f.db)
+	affected, err := engine.ApplyRulesToArticlesWithContent(newArticles, contents)
+	if err != nil {
+		log.Printf("Error applying rules for feed %s: %v", feed.Title, err)
+	} else if affected > 0 {
+		utils.DebugLog("Applied rules to %d articles in feed %s", affected, feed.Title)
+	}
+}
+
+// insertedArticles reloads the saved articles that were newly inserted, which
+// SaveArticlesCounted gave an ID
+func (f *Fetcher) insertedArticles(saved []*models.Article) []models.Article {
+	var ids []int64
+	for _, article := range saved {
+		if article.ID != 0 {
+			ids = append(ids, article.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	articles, err := f.db.GetArticlesByIDs(ids)
+	if err != nil {
+		log.Printf("Error getting newly inserted articles: %v", err)
+		return nil
+	}
+	return articles
+}
+
+// recordFetchResult updates the conditional GET bookkeeping for a feed after a successful fetch.
+// For a full fetch the new ETag/Last-Modified validators are stored as well.
+func (f *Fetcher) recordFetchResult(feed models.Feed, notModified bool) {
+	if !notModified {
+		if err := f.db.UpdateFeedValidators(feed.ID, feed.ETag, feed.LastModified); err != nil {
+			log.Printf("Error saving cache validators for feed %s: %v", feed.Title, err)
+		}
+	}
+	if err := f.db.RecordFeedFetchResult(feed.ID, notModified); err != nil {
+		log.Printf("Error recording fetch result for feed %s: %v", feed.Title, err)
+	}
+}
+
 // FetchSingleFeed fetches a single feed with progress tracking.
 // This is used when adding a new feed, refreshing a single feed from the context menu,
 // or when the scheduler triggers individual feed refreshes.
@@ -444,9 +726,51 @@ func (f *Fetcher) FetchFeedsByIDs(ctx context.Context, feedIDs []int64) {
 	}
 }
 
+// recordArticleVersions stores a revision of every saved article whose title or content
+// the publisher changed, and returns how many changed
+func (f *Fetcher) recordArticleVersions(articlesWithContent []*ArticleWithContent) int {
+	markUnread, _ := f.db.GetSetting("mark_updated_articles_unread")
+	updated := 0
+	for _, awc := range articlesWithContent {
+		articleID, err := f.db.GetArticleIDByUniqueID(awc.Article.Title, awc.Article.FeedID, awc.Article.PublishedAt, awc.Article.HasValidPublishedTime)
+		if err != nil {
+			continue
+		}
+		changed, err := f.db.RecordArticleVersion(articleID, awc.Article.Title, awc.Content, markUnread == "true")
+		if err != nil {
+			log.Printf("Error recording version of article %d: %v", articleID, err)
+			continue
+		}
+		if changed {
+			updated++
+			utils.DebugLog("Article %d was updated by its publisher", articleID)
+		}
+	}
+	return updated
+}
+
+// clusterArticles groups newly saved articles with the articles of other feeds that
+// report the same story
+func (f *Fetcher) clusterArticles(articlesWithContent []*ArticleWithContent) {
+	for _, awc := range articlesWithContent {
+		articleID, err := f.db.GetArticleIDByUniqueID(awc.Article.Title, awc.Article.FeedID, awc.Article.PublishedAt, awc.Article.HasValidPublishedTime)
+		if err != nil {
+			continue
+		}
+		clusterID, err := f.db.AssignArticleCluster(articleID, awc.Article.URL, awc.Article.Title, awc.Content)
+		if err != nil {
+			log.Printf("Error clustering article %d: %v", articleID, err)
+		} else if clusterID != articleID {
+			utils.DebugLog("Article %d is a duplicate in cluster %d", articleID, clusterID)
+		}
+	}
+}
+
 // cacheArticleContents caches article contents from RSS feeds
 // This is called after articles are saved to the database
-func (f *Fetcher) cacheArticleContents(articlesWithContent []*ArticleWithContent) {
+// Returns the cached contents by article ID so rules can match on them without reloading
+func (f *Fetcher) cacheArticleContents(articlesWithContent []*ArticleWithContent) map[int64]string {
+	contents := make(map[int64]string)
 	for _, awc := range articlesWithContent {
 		// Only cache if content is not empty and URL is present
 		if awc.Content == "" || awc.Article.URL == "" {
@@ -461,6 +785,8 @@ func (f *Fetcher) cacheArticleContents(articlesWithContent []*ArticleWithContent
 			continue
 		}
 
+		contents[articleID] = awc.Content
+
 		// Cache the content (this will overwrite any existing cache as required)
 		if err := f.db.SetArticleContent(articleID, awc.Content); err != nil {
 			log.Printf("Error caching content for article %d: %v", articleID, err)
@@ -468,4 +794,5 @@ func (f *Fetcher) cacheArticleContents(articlesWithContent []*ArticleWithContent
 			utils.DebugLog("Cached content for article %d", articleID)
 		}
 	}
+	return contents
 }
@@ -0,0 +1,116 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestFetchFeedWithContext_ConditionalGet(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	defer db.Close()
+
+	rss := `<?xml version="1.0"?><rss><channel><title>Cond</title>` +
+		`<item><title>first</title><link>/1</link><guid>1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 MST</pubDate></item>` +
+		`</channel></rss>`
+
+	const etag = `"v1"`
+	var fullResponses, notModifiedResponses int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModifiedResponses, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&fullResponses, 1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rss))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "cond", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	// First fetch downloads the feed and stores the validators
+	feed, _ := db.GetFeedByID(id)
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("first fetch error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	feed, _ = db.GetFeedByID(id)
+	if feed.ETag != etag {
+		t.Errorf("expected ETag %q to be stored, got %q", etag, feed.ETag)
+	}
+	if feed.LastModified == "" {
+		t.Error("expected Last-Modified to be stored")
+	}
+
+	// Second fetch sends If-None-Match and gets 304, which counts as success
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("second fetch should succeed on 304, got: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&fullResponses); got != 1 {
+		t.Errorf("expected 1 full response, got %d", got)
+	}
+	if got := atomic.LoadInt32(&notModifiedResponses); got != 1 {
+		t.Errorf("expected 1 not modified response, got %d", got)
+	}
+
+	feed, _ = db.GetFeedByID(id)
+	if feed.FetchCount != 2 || feed.NotModifiedCount != 1 {
+		t.Errorf("expected fetch_count=2 not_modified_count=1, got %d/%d", feed.FetchCount, feed.NotModifiedCount)
+	}
+	if feed.ETag != etag {
+		t.Errorf("expected ETag to be kept after 304, got %q", feed.ETag)
+	}
+
+	articles, err := db.GetArticles("all", id, "", false, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("expected 1 article, got %d", len(articles))
+	}
+}
+
+func TestNotModifiedRatio(t *testing.T) {
+	tests := []struct {
+		name        string
+		fetches     int
+		notModified int
+		want        float64
+	}{
+		{"no history", 0, 0, 0},
+		{"too few fetches", 5, 5, 0},
+		{"half not modified", 20, 10, 0.5},
+		{"all not modified", 10, 10, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := models.Feed{FetchCount: tt.fetches, NotModifiedCount: tt.notModified}
+			if got := NotModifiedRatio(feed); got != tt.want {
+				t.Errorf("NotModifiedRatio() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
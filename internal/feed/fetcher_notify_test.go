@@ -0,0 +1,80 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/notify"
+)
+
+func TestFetchFeedWithContext_NotifiesOnlyNewArticles(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	defer db.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss><channel><title>Notify</title>` +
+			`<item><title>first</title><link>/1</link><guid>1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 MST</pubDate></item>` +
+			`<item><title>second</title><link>/2</link><guid>2</guid><pubDate>Mon, 02 Jan 2006 15:04:05 MST</pubDate></item>` +
+			`</channel></rss>`))
+	}))
+	defer srv.Close()
+
+	// The batch window keeps the articles queued, so the queue shows what was enqueued
+	if _, err := db.CreateNotificationTarget(models.NotificationTarget{
+		Name:        "Everything",
+		Enabled:     true,
+		Type:        models.NotifyWebhook,
+		URL:         srv.URL + "/hook",
+		BatchWindow: 3600,
+	}); err != nil {
+		t.Fatalf("CreateNotificationTarget error: %v", err)
+	}
+
+	f := NewFetcher(db)
+	f.SetNotifier(notify.NewDispatcher(db))
+	id, err := db.AddFeed(&models.Feed{Title: "Notify", URL: srv.URL + "/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	// Notifications are queued in the background after the refresh
+	fetchAndSettle := func(want int) int {
+		t.Helper()
+		feed, _ := db.GetFeedByID(id)
+		if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+			t.Fatalf("fetch error: %v", err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			var queued int
+			db.QueryRow(`SELECT COUNT(*) FROM notification_queue`).Scan(&queued)
+			if queued >= want || time.Now().After(deadline) {
+				time.Sleep(100 * time.Millisecond)
+				db.QueryRow(`SELECT COUNT(*) FROM notification_queue`).Scan(&queued)
+				return queued
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	if queued := fetchAndSettle(2); queued != 2 {
+		t.Fatalf("expected the inserted articles to be queued, got %d", queued)
+	}
+
+	// A refresh of the same items queues nothing more
+	if queued := fetchAndSettle(3); queued != 2 {
+		t.Errorf("expected no notifications for articles already seen, got %d queued in total", queued)
+	}
+}
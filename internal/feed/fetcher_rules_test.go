@@ -0,0 +1,92 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestFetchFeedWithContext_RulesOnlyOnNewArticles(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	defer db.Close()
+
+	var webhookPosts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hook" {
+			atomic.AddInt32(&webhookPosts, 1)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<?xml version="1.0"?><rss><channel><title>Rules</title>` +
+			`<item><title>keep me</title><link>/1</link><guid>1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 MST</pubDate></item>` +
+			`<item><title>spam offer</title><link>/2</link><guid>2</guid><pubDate>Mon, 02 Jan 2006 15:04:05 MST</pubDate></item>` +
+			`</channel></rss>`))
+	}))
+	defer srv.Close()
+
+	db.SetSetting("rules_webhook_url", srv.URL+"/hook")
+	if _, err := db.CreateRule(models.Rule{
+		Name:       "Drop spam",
+		Enabled:    true,
+		Conditions: []models.RuleCondition{{Field: "article_title", Operator: "contains", Value: "spam"}},
+		Actions:    []string{"delete"},
+	}); err != nil {
+		t.Fatalf("CreateRule error: %v", err)
+	}
+	if _, err := db.CreateRule(models.Rule{Name: "Post", Enabled: true, Actions: []string{"webhook"}, Position: 1}); err != nil {
+		t.Fatalf("CreateRule error: %v", err)
+	}
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "Rules", URL: srv.URL + "/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	// Rules run in the background after the refresh
+	fetchAndSettle := func() []models.Article {
+		t.Helper()
+		feed, _ := db.GetFeedByID(id)
+		if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+			t.Fatalf("fetch error: %v", err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			articles, _ := db.GetArticles("all", id, "", true, 10, 0)
+			if (len(articles) == 1 && atomic.LoadInt32(&webhookPosts) >= 1) || time.Now().After(deadline) {
+				time.Sleep(100 * time.Millisecond)
+				return articles
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	articles := fetchAndSettle()
+	if len(articles) != 1 || articles[0].Title != "keep me" {
+		t.Fatalf("expected only the article kept by the rules, got %+v", articles)
+	}
+	if got := atomic.LoadInt32(&webhookPosts); got != 1 {
+		t.Fatalf("expected a webhook for the kept article, got %d", got)
+	}
+
+	// A refresh of the same items neither brings back the deleted article nor runs the rules again
+	articles = fetchAndSettle()
+	if len(articles) != 1 || articles[0].Title != "keep me" {
+		t.Errorf("expected the deleted article to stay deleted, got %+v", articles)
+	}
+	if got := atomic.LoadInt32(&webhookPosts); got != 1 {
+		t.Errorf("expected no webhooks for articles already seen, got %d in total", got)
+	}
+}
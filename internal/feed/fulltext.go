@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+)
+
+// maxEagerFullTextPerRefresh caps the number of web pages fetched for an eager
+// full-text feed in one refresh, so a feed full of new items can't stall the fetcher
+const maxEagerFullTextPerRefresh = 20
+
+// FetchFullText fetches the full text of an article page with the request and
+// full-text settings of its feed
+func (f *Fetcher) FetchFullText(pageURL string, feed *models.Feed) (string, error) {
+	settings, err := f.db.GetFeedFullTextSettings(feed.ID)
+	if err != nil {
+		log.Printf("Error loading full-text settings for feed %d: %v", feed.ID, err)
+	}
+	httpSettings := f.getFeedHTTPSettings(feed)
+	return utils.FetchFullText(pageURL, settings, func(req *http.Request) {
+		utils.ApplyFeedHTTPSettings(req, httpSettings, feed.URL)
+	})
+}
+
+// fetchEagerFullText stores the full text of newly saved articles of a feed in eager
+// full-text mode. Fetching stops once the content cache reaches its size limit.
+func (f *Fetcher) fetchEagerFullText(ctx context.Context, feed models.Feed, articles []models.Article) {
+	if f.db.GetFeedFullTextMode(feed.ID) != models.FullTextModeEager {
+		return
+	}
+
+	fetched := 0
+	for _, article := range articles {
+		if fetched >= maxEagerFullTextPerRefresh || ctx.Err() != nil {
+			return
+		}
+		if article.URL == "" {
+			continue
+		}
+		if hasFullText, err := f.db.HasArticleFullText(article.ID); err != nil || hasFullText {
+			continue
+		}
+		if f.cleanupManager != nil && !f.cleanupManager.HasRoomForContent() {
+			utils.DebugLog("Content cache is full, skipping full text for feed %s", feed.Title)
+			f.cleanupManager.RequestCleanup()
+			return
+		}
+
+		fetched++
+		content, err := f.FetchFullText(article.URL, &feed)
+		if err != nil {
+			utils.DebugLog("Error fetching full text of %s: %v", article.URL, err)
+			continue
+		}
+		if content == "" {
+			continue
+		}
+		if err := f.db.SetArticleFullText(article.ID, content); err != nil {
+			log.Printf("Error storing full text for article %d: %v", article.ID, err)
+		}
+	}
+}
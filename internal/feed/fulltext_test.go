@@ -0,0 +1,93 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestFetchFeed_EagerFullText(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	defer db.Close()
+
+	var pageRequests int32
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/posts/") {
+			atomic.AddInt32(&pageRequests, 1)
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, `<html><body><div class="ad">Buy now</div><div id="text"><p>The whole story of %s.</p></div></body></html>`, r.URL.Path)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss><channel><title>Teasers</title>`+
+			`<item><title>first</title><link>%[1]s/posts/1</link><guid>1</guid><description>Teaser</description></item>`+
+			`</channel></rss>`, srvURL)
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "Teasers", URL: srv.URL + "/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	if err := db.SetFeedFullTextSettings(&models.FeedFullTextSettings{
+		FeedID: id, Mode: models.FullTextModeEager, ContentSelector: "#text",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	feed, _ := db.GetFeedByID(id)
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+
+	articles, err := db.GetArticles("", id, "", false, 10, 0)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("GetArticles = %v, %v", articles, err)
+	}
+	articleID := articles[0].ID
+
+	// Full text is fetched in the background after the refresh
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if ok, _ := db.HasArticleFullText(articleID); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("full text was not fetched for an eager feed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	content, _, _ := db.GetArticleContent(articleID)
+	if !strings.Contains(content, "The whole story of /posts/1") || strings.Contains(content, "Buy now") {
+		t.Errorf("stored content = %q", content)
+	}
+
+	// A refresh neither overwrites nor refetches stored full text
+	if err := f.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("second fetch error: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt32(&pageRequests); n != 1 {
+		t.Errorf("article page fetched %d times, want 1", n)
+	}
+	if content, _, _ := db.GetArticleContent(articleID); !strings.Contains(content, "The whole story") {
+		t.Errorf("full text replaced by the feed content: %q", content)
+	}
+}
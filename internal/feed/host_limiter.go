@@ -0,0 +1,201 @@
+package feed
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/rsshub"
+)
+
+// Default per-host politeness limits
+const (
+	defaultMaxPerHost      = 2
+	defaultHostRequestWait = time.Second
+)
+
+// hostLimiter keeps refreshes polite towards each host: it caps the number of
+// concurrent requests, spaces out request starts, and pauses a host that asked
+// us to retry later.
+type hostLimiter struct {
+	mu          sync.Mutex
+	hosts       map[string]*hostState
+	maxPerHost  int
+	minInterval time.Duration
+	released    chan struct{} // Closed and replaced whenever a slot is released
+}
+
+type hostState struct {
+	active       int       // Requests in flight
+	nextStart    time.Time // Earliest start time for the next request
+	blockedUntil time.Time // Retry-After deadline sent by the host
+}
+
+func newHostLimiter(maxPerHost int, minInterval time.Duration) *hostLimiter {
+	l := &hostLimiter{
+		hosts:    make(map[string]*hostState),
+		released: make(chan struct{}),
+	}
+	l.configure(maxPerHost, minInterval)
+	return l
+}
+
+// configure updates the limits; requests already in flight are not affected
+func (l *hostLimiter) configure(maxPerHost int, minInterval time.Duration) {
+	if maxPerHost < 1 {
+		maxPerHost = defaultMaxPerHost
+	}
+	if minInterval < 0 {
+		minInterval = 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxPerHost = maxPerHost
+	l.minInterval = minInterval
+}
+
+func (l *hostLimiter) state(host string) *hostState {
+	s := l.hosts[host]
+	if s == nil {
+		s = &hostState{}
+		l.hosts[host] = s
+	}
+	return s
+}
+
+// tryAcquire takes a concurrency slot for host if one is free.
+// Requests without a host (scripts, email, local files) are never limited.
+func (l *hostLimiter) tryAcquire(host string) bool {
+	if host == "" {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.state(host)
+	if s.active >= l.maxPerHost {
+		return false
+	}
+	s.active++
+	return true
+}
+
+// acquire waits for a concurrency slot for host
+func (l *hostLimiter) acquire(ctx context.Context, host string) error {
+	for {
+		if l.tryAcquire(host) {
+			return nil
+		}
+
+		l.mu.Lock()
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release returns a slot taken with tryAcquire or acquire
+func (l *hostLimiter) release(host string) {
+	if host == "" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s := l.hosts[host]; s != nil && s.active > 0 {
+		s.active--
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// wait reserves the next start time for host and sleeps until it is reached
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	if host == "" {
+		return nil
+	}
+
+	l.mu.Lock()
+	s := l.state(host)
+	now := time.Now()
+	start := now
+	if s.nextStart.After(start) {
+		start = s.nextStart
+	}
+	s.nextStart = start.Add(l.minInterval)
+	l.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// block pauses requests to host until the given time
+func (l *hostLimiter) block(host string, until time.Time) {
+	if host == "" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.state(host)
+	if until.After(s.blockedUntil) {
+		s.blockedUntil = until
+	}
+}
+
+// blockedUntil returns the time until which host asked us to wait, or the zero time
+func (l *hostLimiter) blockedUntil(host string) time.Time {
+	if host == "" {
+		return time.Time{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s := l.hosts[host]; s != nil && s.blockedUntil.After(time.Now()) {
+		return s.blockedUntil
+	}
+	return time.Time{}
+}
+
+// feedHost returns the host a feed refresh will contact, or "" for feeds that are
+// not fetched over HTTP. RSSHub routes are attributed to the configured instance.
+func (f *Fetcher) feedHost(feed models.Feed) string {
+	if feed.Type == "email" || feed.ScriptPath != "" {
+		return ""
+	}
+
+	feedURL := feed.URL
+	if rsshub.IsRSSHubURL(feedURL) {
+		endpoint, _ := f.db.GetSetting("rsshub_endpoint")
+		if endpoint == "" {
+			endpoint = "https://rsshub.app"
+		}
+		feedURL = endpoint
+	}
+
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
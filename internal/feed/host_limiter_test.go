@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterConcurrency(t *testing.T) {
+	l := newHostLimiter(2, 0)
+
+	if !l.tryAcquire("a.com") || !l.tryAcquire("a.com") {
+		t.Fatal("expected two slots for a.com")
+	}
+	if l.tryAcquire("a.com") {
+		t.Error("expected third slot for a.com to be refused")
+	}
+	if !l.tryAcquire("b.com") {
+		t.Error("expected other hosts to be unaffected")
+	}
+	if !l.tryAcquire("") {
+		t.Error("expected requests without a host to be unlimited")
+	}
+
+	// acquire waits for a release
+	done := make(chan error, 1)
+	go func() { done <- l.acquire(context.Background(), "a.com") }()
+	select {
+	case <-done:
+		t.Fatal("acquire should block while a.com is at capacity")
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.release("a.com")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("acquire error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire did not return after release")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.acquire(ctx, "a.com"); err == nil {
+		t.Error("expected acquire to fail on a cancelled context")
+	}
+}
+
+func TestHostLimiterSpacingAndBlock(t *testing.T) {
+	l := newHostLimiter(2, 50*time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(context.Background(), "a.com"); err != nil {
+			t.Fatalf("wait error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected requests to be spaced out, three starts took %v", elapsed)
+	}
+
+	if !l.blockedUntil("a.com").IsZero() {
+		t.Error("expected a.com not to be blocked")
+	}
+	until := time.Now().Add(time.Minute)
+	l.block("a.com", until)
+	l.block("a.com", time.Now().Add(time.Second)) // An earlier deadline doesn't shorten the pause
+	if got := l.blockedUntil("a.com"); !got.Equal(until) {
+		t.Errorf("blockedUntil = %v, want %v", got, until)
+	}
+	if !l.blockedUntil("b.com").IsZero() {
+		t.Error("expected b.com not to be blocked")
+	}
+}
@@ -14,6 +14,8 @@ const (
 	MaxRefreshInterval = 24 * time.Hour
 	// Default interval if no history
 	DefaultRefreshInterval = 30 * time.Minute
+	// Minimum number of recorded fetches before the 304 ratio is trusted
+	MinFetchesForNotModifiedRatio = 10
 )
 
 // IntelligentRefreshCalculator calculates optimal refresh intervals based on feed activity
@@ -43,6 +45,10 @@ func (irc *IntelligentRefreshCalculator) CalculateInterval(feed models.Feed) tim
 	// but with reasonable bounds
 	optimalInterval := avgInterval / 2
 
+	// Feeds that mostly answer 304 Not Modified are polled too often,
+	// so stretch the interval by up to 2x based on the recent 304 ratio
+	optimalInterval = time.Duration(float64(optimalInterval) * (1 + NotModifiedRatio(feed)))
+
 	// Clamp to min/max bounds (5 minutes to 24 hours)
 	if optimalInterval < MinRefreshInterval {
 		return MinRefreshInterval
@@ -54,6 +60,16 @@ func (irc *IntelligentRefreshCalculator) CalculateInterval(feed models.Feed) tim
 	return optimalInterval
 }
 
+// NotModifiedRatio returns the fraction of recent fetches answered with 304 Not Modified.
+// Returns 0 if there are not enough recorded fetches to be meaningful.
+func NotModifiedRatio(feed models.Feed) float64 {
+	if feed.FetchCount < MinFetchesForNotModifiedRatio {
+		return 0
+	}
+	ratio := float64(feed.NotModifiedCount) / float64(feed.FetchCount)
+	return math.Min(math.Max(ratio, 0), 1)
+}
+
 // calculateAverageInterval computes the average time between article publications
 func (irc *IntelligentRefreshCalculator) calculateAverageInterval(articles []models.Article) time.Duration {
 	if len(articles) < 2 {
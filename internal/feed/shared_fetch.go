@@ -0,0 +1,128 @@
+package feed
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// SharedFetches lets the fetchers of several users share downloads of the feed URLs they
+// have in common, so a feed with many subscribers is fetched once per refresh interval
+// instead of once per subscriber.
+//
+// A download is reused by another fetcher while it is younger than that fetcher's refresh
+// interval for the feed, as a refresh of its own wouldn't have been due any earlier.
+// Only plain HTTP feeds without their own request settings are shared. A fetcher never
+// reuses its own download, so the refresh behavior of a single user is unchanged.
+type SharedFetches struct {
+	mu      sync.Mutex
+	entries map[string]*sharedFetch
+}
+
+// sharedFetch is the latest download of a feed URL
+type sharedFetch struct {
+	owner      *Fetcher
+	done       chan struct{} // closed once the download finished
+	xml        string
+	validators FeedValidators
+	fetchedAt  time.Time
+	err        error
+	maxAge     time.Duration // longest refresh interval of the fetchers using the download
+}
+
+// NewSharedFetches creates an empty cache of shared downloads.
+func NewSharedFetches() *SharedFetches {
+	return &SharedFetches{
+		entries: make(map[string]*sharedFetch),
+	}
+}
+
+// ShareFetches makes the fetcher share feed downloads with the other fetchers using shared.
+func (f *Fetcher) ShareFetches(shared *SharedFetches) {
+	f.sharedFetches = shared
+}
+
+// fetch returns the feed at url, either from a running download of another fetcher or one
+// younger than maxAge, or by calling download. validators are the caller's conditional GET
+// validators; a shared download with the same validators is reported as ErrNotModified.
+func (s *SharedFetches) fetch(ctx context.Context, owner *Fetcher, url string, maxAge time.Duration, validators FeedValidators,
+	download func(FeedValidators) (string, FeedValidators, error)) (string, FeedValidators, error) {
+	s.mu.Lock()
+	if entry := s.entries[url]; entry != nil && entry.owner != owner {
+		if maxAge > entry.maxAge {
+			entry.maxAge = maxAge
+		}
+		s.mu.Unlock()
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return "", FeedValidators{}, ctx.Err()
+		}
+		if entry.err == nil && time.Since(entry.fetchedAt) < maxAge {
+			if validators != (FeedValidators{}) && validators == entry.validators {
+				return "", validators, ErrNotModified
+			}
+			return entry.xml, entry.validators, nil
+		}
+		s.mu.Lock()
+	}
+
+	entry := &sharedFetch{owner: owner, done: make(chan struct{}), maxAge: maxAge}
+	previous := s.entries[url]
+	s.entries[url] = entry
+	s.mu.Unlock()
+
+	entry.xml, entry.validators, entry.err = download(validators)
+	entry.fetchedAt = time.Now()
+	close(entry.done)
+
+	// A failed or not-modified download has nothing to share, keep the previous one instead
+	if entry.err != nil {
+		s.mu.Lock()
+		if s.entries[url] == entry {
+			if previous != nil {
+				s.entries[url] = previous
+			} else {
+				delete(s.entries, url)
+			}
+		}
+		s.mu.Unlock()
+	}
+	s.prune()
+
+	return entry.xml, entry.validators, entry.err
+}
+
+// prune drops downloads that are too old to be shared with any of their fetchers
+func (s *SharedFetches) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for url, entry := range s.entries {
+		select {
+		case <-entry.done:
+			if time.Since(entry.fetchedAt) >= entry.maxAge {
+				delete(s.entries, url)
+			}
+		default:
+		}
+	}
+}
+
+// sharedFetchMaxAge returns how old a download of the feed by another fetcher may be to be
+// reused, which is the interval the scheduler refreshes the feed at.
+func (f *Fetcher) sharedFetchMaxAge(feed *models.Feed) time.Duration {
+	switch {
+	case feed.RefreshInterval > 0:
+		return time.Duration(feed.RefreshInterval) * time.Minute
+	case feed.RefreshInterval == -1:
+		return NewIntelligentRefreshCalculator(f.db).CalculateInterval(*feed)
+	}
+	intervalStr, _ := f.db.GetSetting("update_interval")
+	if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+		return time.Duration(interval) * time.Minute
+	}
+	return DefaultRefreshInterval
+}
@@ -0,0 +1,122 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestSharedFetches(t *testing.T) {
+	rss := `<?xml version="1.0"?><rss><channel><title>Shared</title>` +
+		`<item><title>first</title><link>/1</link><guid>1</guid></item>` +
+		`</channel></rss>`
+	const etag = `"v1"`
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(rss))
+	}))
+	defer srv.Close()
+
+	shared := NewSharedFetches()
+	newFetcher := func() *Fetcher {
+		db, err := database.NewDB(":memory:")
+		if err != nil {
+			t.Fatalf("NewDB error: %v", err)
+		}
+		if err := db.Init(); err != nil {
+			t.Fatalf("db Init error: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		f := NewFetcher(db)
+		f.ShareFetches(shared)
+		return f
+	}
+	alice, bob := newFetcher(), newFetcher()
+	ctx := context.Background()
+
+	if parsed, err := alice.ParseFeedWithFeed(ctx, &models.Feed{URL: srv.URL}, false); err != nil || len(parsed.Items) != 1 {
+		t.Fatalf("first fetch: %v, %v", parsed, err)
+	}
+
+	// Another user's fetcher reuses the download
+	bobFeed := &models.Feed{URL: srv.URL}
+	if parsed, err := bob.ParseFeedConditional(ctx, bobFeed); err != nil || len(parsed.Items) != 1 {
+		t.Fatalf("shared fetch: %v, %v", parsed, err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("server hit %d times, want 1", n)
+	}
+	if bobFeed.ETag != etag {
+		t.Errorf("ETag = %q, want the validators of the shared download", bobFeed.ETag)
+	}
+
+	// With the validators of the shared download, the feed is unchanged
+	if _, err := bob.ParseFeedConditional(ctx, bobFeed); !errors.Is(err, ErrNotModified) {
+		t.Errorf("err = %v, want ErrNotModified", err)
+	}
+
+	// A fetcher never reuses its own download
+	if _, err := alice.ParseFeedWithFeed(ctx, &models.Feed{URL: srv.URL}, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("server hit %d times, want 2", n)
+	}
+
+	// Feeds with their own request settings are always fetched by their owner
+	customID, err := bob.db.AddFeed(&models.Feed{Title: "custom", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.db.SetFeedHTTPSettings(&models.FeedHTTPSettings{FeedID: customID, UserAgent: "custom"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.ParseFeedWithFeed(ctx, &models.Feed{ID: customID, URL: srv.URL}, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("server hit %d times, want 3", n)
+	}
+}
+
+func TestSharedFetches_Expire(t *testing.T) {
+	shared := NewSharedFetches()
+	alice, bob, carol := &Fetcher{}, &Fetcher{}, &Fetcher{}
+	var downloads int
+	download := func(FeedValidators) (string, FeedValidators, error) {
+		downloads++
+		return "<rss/>", FeedValidators{}, nil
+	}
+
+	shared.fetch(context.Background(), alice, "https://example.com/feed", time.Hour, FeedValidators{}, download)
+	time.Sleep(5 * time.Millisecond)
+
+	// Whether a download is recent enough depends on the refresh interval of the fetcher asking
+	shared.fetch(context.Background(), bob, "https://example.com/feed", time.Hour, FeedValidators{}, download)
+	if downloads != 1 {
+		t.Errorf("downloads = %d, want a download within the refresh interval to be reused", downloads)
+	}
+	shared.fetch(context.Background(), carol, "https://example.com/feed", time.Millisecond, FeedValidators{}, download)
+	if downloads != 2 {
+		t.Errorf("downloads = %d, want an expired download to be fetched again", downloads)
+	}
+
+	failing := func(FeedValidators) (string, FeedValidators, error) {
+		downloads++
+		return "", FeedValidators{}, errors.New("unreachable")
+	}
+	shared.fetch(context.Background(), alice, "https://example.com/other", time.Minute, FeedValidators{}, failing)
+	shared.fetch(context.Background(), bob, "https://example.com/other", time.Minute, FeedValidators{}, failing)
+	if downloads != 4 {
+		t.Errorf("downloads = %d, want failed downloads not to be shared", downloads)
+	}
+}
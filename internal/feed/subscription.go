@@ -5,6 +5,7 @@ import (
 	"MrRSS/internal/rsshub"
 	"MrRSS/internal/utils"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -110,6 +111,15 @@ func sanitizeFeedXML(xmlContent string) string {
 
 // fetchAndSanitizeFeed fetches feed content and sanitizes it before parsing
 func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (string, error) {
+	cleanedXML, _, err := f.fetchAndSanitizeFeedConditional(ctx, feedURL, FeedValidators{}, nil)
+	return cleanedXML, err
+}
+
+// fetchAndSanitizeFeedConditional fetches feed content using the given cache validators and
+// the feed's request settings (may be nil).
+// It returns ErrNotModified when the server answers 304 Not Modified, and the validators
+// from the response on success so they can be sent with the next request.
+func (f *Fetcher) fetchAndSanitizeFeedConditional(ctx context.Context, feedURL string, validators FeedValidators, httpSettings *models.FeedHTTPSettings) (string, FeedValidators, error) {
 	debugTimer := NewDebugTimer(fmt.Sprintf("FetchSanitize-%s", feedURL), shouldEnableDebugLogging(feedURL))
 	defer debugTimer.End()
 
@@ -120,7 +130,7 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	httpClient, err := f.getHTTPClient(models.Feed{URL: feedURL})
 	if err != nil {
 		debugTimer.LogWithTime("Failed to create HTTP client: %v", err)
-		return "", fmt.Errorf("failed to create HTTP client: %w", err)
+		return "", FeedValidators{}, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 	debugTimer.Stage("HTTP client created")
 
@@ -128,7 +138,7 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
 		debugTimer.LogWithTime("Failed to create request: %v", err)
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", FeedValidators{}, fmt.Errorf("failed to create request: %w", err)
 	}
 	debugTimer.Stage("Request created")
 
@@ -141,19 +151,28 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	req.Header.Set("DNT", "1")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Upgrade-Insecure-Requests", "1")
+	utils.ApplyFeedHTTPSettings(req, httpSettings, feedURL)
+	validators.apply(req)
 
 	debugTimer.LogWithTime("Sending HTTP request to %s", feedURL)
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		debugTimer.LogWithTime("HTTP request failed: %v", err)
-		return "", fmt.Errorf("failed to fetch feed: %w", err)
+		return "", FeedValidators{}, fmt.Errorf("failed to fetch feed: %w", err)
 	}
 	defer resp.Body.Close()
 	debugTimer.Stage("HTTP request completed")
+	stats := fetchStatsFrom(ctx)
+	stats.recordResponse(resp.StatusCode)
+
+	if resp.StatusCode == http.StatusNotModified {
+		debugTimer.LogWithTime("Feed not modified since last fetch")
+		return "", validators, ErrNotModified
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		debugTimer.LogWithTime("HTTP status not OK: %d", resp.StatusCode)
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return "", FeedValidators{}, newHTTPStatusError(resp)
 	}
 
 	debugTimer.LogWithTime("Reading response body")
@@ -162,9 +181,10 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		debugTimer.LogWithTime("Failed to read body: %v", err)
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", FeedValidators{}, fmt.Errorf("failed to read response body: %w", err)
 	}
 	debugTimer.LogWithTime("Read %d bytes from response", len(body))
+	stats.recordBody(len(body))
 	debugTimer.Stage("Body read complete")
 
 	xmlContent := string(body)
@@ -175,7 +195,7 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feedURL string) (str
 	debugTimer.LogWithTime("Sanitization complete, length=%d", len(cleanedXML))
 	debugTimer.Stage("Sanitization complete")
 
-	return cleanedXML, nil
+	return cleanedXML, validatorsFromResponse(resp), nil
 }
 
 // AddSubscription adds a new feed subscription and returns the feed ID.
@@ -215,7 +235,15 @@ func (f *Fetcher) AddSubscription(url string, category string, customTitle strin
 				feed.ImageURL = parsedFeed.Image.URL
 			}
 
-			return f.db.AddFeed(feed)
+			feedID, err := f.db.AddFeed(feed)
+			if err != nil {
+				return 0, err
+			}
+
+			// Subscribe to the feed's WebSub hub (if any) so updates are pushed to us
+			go f.subscribeWebSubFromDocument(context.Background(), feedID, url, cleanedXML)
+
+			return feedID, nil
 		}
 		utils.DebugLog("AddSubscription: Parsing sanitized feed failed: %v", parseErr)
 	}
@@ -490,11 +518,19 @@ func (f *Fetcher) ParseFeedWithScript(ctx context.Context, url string, scriptPat
 // ParseFeedWithFeed parses a feed using the feed configuration (script or XPath)
 func (f *Fetcher) ParseFeedWithFeed(ctx context.Context, feed *models.Feed, priority bool) (*gofeed.Feed, error) {
 	// Parse the feed - priority parameter is kept for compatibility but no longer uses priorityMu
-	return f.parseFeedWithFeedInternal(ctx, feed, priority)
+	return f.parseFeedWithFeedInternal(ctx, feed, priority, false)
+}
+
+// ParseFeedConditional parses a feed for a refresh, sending the feed's stored ETag and
+// Last-Modified validators. It returns ErrNotModified if the feed has not changed.
+// On success, feed.ETag and feed.LastModified are updated from the response.
+func (f *Fetcher) ParseFeedConditional(ctx context.Context, feed *models.Feed) (*gofeed.Feed, error) {
+	return f.parseFeedWithFeedInternal(ctx, feed, false, true)
 }
 
 // parseFeedWithFeedInternal does the actual parsing work
-func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Feed, priority bool) (*gofeed.Feed, error) {
+// conditional: true to send cache validators from the feed (only used for plain HTTP feeds)
+func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Feed, priority bool, conditional bool) (*gofeed.Feed, error) {
 	// Enable debug timing for problematic feeds
 	debugTimer := NewDebugTimer(fmt.Sprintf("Feed-%s", feed.URL), shouldEnableDebugLogging(feed.URL))
 	defer debugTimer.End()
@@ -509,8 +545,8 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 			return nil, fmt.Errorf("email fetcher not initialized")
 		}
 
-		// Fetch emails from IMAP
-		items, err := f.emailFetcher.FetchEmails(ctx, feed)
+		// Fetch emails from IMAP; refreshes save them through fetchEmailFeed instead
+		items, err := f.emailFetcher.FetchEmails(ctx, feed, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch emails: %w", err)
 		}
@@ -585,9 +621,35 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 	// Try fetching and sanitizing the feed first to handle file:// URLs in atom:link
 	debugTimer.LogWithTime("About to call fetchAndSanitizeFeed")
 	utils.DebugLog("parseFeedWithFeedInternal: Attempting to fetch and sanitize feed for %s", actualURL)
-	cleanedXML, sanitizeErr := f.fetchAndSanitizeFeed(fetchCtx, actualURL)
+	var requestValidators FeedValidators
+	if conditional {
+		requestValidators = FeedValidators{ETag: feed.ETag, LastModified: feed.LastModified}
+	}
+	httpSettings := f.getFeedHTTPSettings(feed)
+	var cleanedXML string
+	var responseValidators FeedValidators
+	var sanitizeErr error
+	if f.sharedFetches != nil && httpSettings.IsEmpty() {
+		cleanedXML, responseValidators, sanitizeErr = f.sharedFetches.fetch(fetchCtx, f, actualURL, f.sharedFetchMaxAge(feed), requestValidators,
+			func(validators FeedValidators) (string, FeedValidators, error) {
+				return f.fetchAndSanitizeFeedConditional(fetchCtx, actualURL, validators, nil)
+			})
+	} else {
+		cleanedXML, responseValidators, sanitizeErr = f.fetchAndSanitizeFeedConditional(fetchCtx, actualURL, requestValidators, httpSettings)
+	}
 	debugTimer.LogWithTime("fetchAndSanitizeFeed completed, err=%v", sanitizeErr)
 
+	if errors.Is(sanitizeErr, ErrNotModified) {
+		utils.DebugLog("parseFeedWithFeedInternal: Feed not modified since last fetch: %s", actualURL)
+		return nil, ErrNotModified
+	}
+
+	if conditional {
+		// Remember the validators from this response (empty if the fetch failed)
+		feed.ETag = responseValidators.ETag
+		feed.LastModified = responseValidators.LastModified
+	}
+
 	if sanitizeErr == nil {
 		debugTimer.Stage("Parsing sanitized XML")
 		// Successfully fetched and sanitized, try parsing
@@ -606,12 +668,27 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 			return parsedFeed, nil
 		}
 		utils.DebugLog("parseFeedWithFeedInternal: Parsing sanitized feed failed: %v", err)
+		if !httpSettings.IsEmpty() {
+			return nil, fmt.Errorf("failed to parse feed: %w", err)
+		}
 		// Fall through to standard parsing
 	} else {
 		debugTimer.LogWithTime("Sanitization failed, will try standard parsing")
 		utils.DebugLog("parseFeedWithFeedInternal: Sanitization failed: %v", sanitizeErr)
 	}
 
+	// The fallbacks below fetch without the feed's request settings, so they can't reach
+	// a feed that needs its own headers or credentials
+	if !httpSettings.IsEmpty() {
+		return nil, sanitizeErr
+	}
+
+	// Don't hit a server that asked us to slow down again through the fallbacks
+	var statusErr *HTTPStatusError
+	if errors.As(sanitizeErr, &statusErr) && statusErr.IsRateLimited() {
+		return nil, sanitizeErr
+	}
+
 	// Fallback: Try standard parsing first
 	debugTimer.Stage("Standard parsing via ParseURLWithContext")
 	debugTimer.LogWithTime("About to call ParseURLWithContext")
@@ -667,7 +744,7 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 }
 
 // parseFeedWithXPath parses a feed using XPath expressions
-func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gofeed.Feed, error) {
+func (f *Fetcher) parseFeedWithXPath(ctx context.Context, feed *models.Feed) (*gofeed.Feed, error) {
 	if feed.XPathItem == "" {
 		return nil, &XPathError{
 			Operation: "validate",
@@ -685,7 +762,17 @@ func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gof
 			Err:       err,
 		}
 	}
-	resp, err := httpClient.Get(feed.URL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return nil, &XPathError{
+			Operation: "fetch",
+			URL:       feed.URL,
+			Details:   "Invalid feed URL",
+			Err:       err,
+		}
+	}
+	utils.ApplyFeedHTTPSettings(req, f.getFeedHTTPSettings(feed), feed.URL)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, &XPathError{
 			Operation: "fetch",
@@ -695,13 +782,20 @@ func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gof
 		}
 	}
 	defer resp.Body.Close()
+	stats := fetchStatsFrom(ctx)
+	stats.recordResponse(resp.StatusCode)
 
 	if resp.StatusCode != 200 {
-		return nil, &XPathError{
+		xpathErr := &XPathError{
 			Operation: "fetch",
 			URL:       feed.URL,
 			Details:   fmt.Sprintf("HTTP %d: %s. The server may be unreachable or the page may have moved", resp.StatusCode, resp.Status),
 		}
+		// Keep the Retry-After deadline reachable for the task manager
+		if statusErr := newHTTPStatusError(resp); statusErr.IsRateLimited() {
+			xpathErr.Err = statusErr
+		}
+		return nil, xpathErr
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -713,6 +807,7 @@ func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gof
 			Err:       err,
 		}
 	}
+	stats.recordBody(len(body))
 
 	// Create gofeed.Feed
 	parsedFeed := &gofeed.Feed{
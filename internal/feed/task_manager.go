@@ -2,7 +2,9 @@ package feed
 
 import (
 	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -21,6 +23,7 @@ const (
 	TaskReasonScheduledCustom                   // Scheduled refresh with custom interval
 	TaskReasonScheduledGlobal                   // Global refresh
 	TaskReasonArticleClick                      // Article content missing
+	TaskReasonEmailPush                         // New message reported by IMAP IDLE
 )
 
 // RefreshTask represents a single feed refresh task
@@ -30,6 +33,12 @@ type RefreshTask struct {
 	CreatedAt time.Time
 }
 
+// queuedTask is what the task manager remembers about a queued feed
+type queuedTask struct {
+	host   string
+	reason TaskReason
+}
+
 // TaskManager manages the task queue and pool for feed refreshing
 type TaskManager struct {
 	fetcher *Fetcher
@@ -46,6 +55,10 @@ type TaskManager struct {
 	poolCapacity int
 	poolSem      chan struct{} // Semaphore for pool capacity
 
+	// Per-host politeness limits
+	hosts  *hostLimiter
+	queued map[int64]queuedTask // Host and reason of each queued feed, guarded by queueMutex
+
 	// State tracking
 	isRunning  bool
 	isStopped  bool
@@ -84,6 +97,8 @@ func NewTaskManager(fetcher *Fetcher, poolCapacity int) *TaskManager {
 		pool:         make(map[int64]*RefreshTask),
 		poolCapacity: poolCapacity,
 		poolSem:      make(chan struct{}, poolCapacity),
+		hosts:        newHostLimiter(defaultMaxPerHost, defaultHostRequestWait),
+		queued:       make(map[int64]queuedTask),
 		stopChan:     make(chan struct{}),
 	}
 
@@ -106,6 +121,12 @@ func (tm *TaskManager) SetPoolCapacity(capacity int) {
 	log.Printf("Task manager pool capacity updated to %d", capacity)
 }
 
+// SetHostLimits updates the per-host concurrency cap and the minimum spacing between
+// requests to the same host
+func (tm *TaskManager) SetHostLimits(maxPerHost int, interval time.Duration) {
+	tm.hosts.configure(maxPerHost, interval)
+}
+
 // Start starts the task manager
 func (tm *TaskManager) Start() {
 	tm.stateMutex.Lock()
@@ -147,6 +168,7 @@ func (tm *TaskManager) Stop() {
 	// Clear state
 	tm.queueMutex.Lock()
 	tm.queue = make([]int64, 0)
+	tm.queued = make(map[int64]queuedTask)
 	tm.queueMutex.Unlock()
 
 	log.Println("Task manager stopped")
@@ -198,6 +220,8 @@ func (tm *TaskManager) AddToQueueHead(ctx context.Context, feed models.Feed, rea
 	}
 	tm.progressMutex.Unlock()
 
+	host := tm.fetcher.feedHost(feed)
+
 	// Remove existing task from queue if present
 	tm.queueMutex.Lock()
 	removed := removeFromQueue(&tm.queue, feed.ID)
@@ -212,6 +236,7 @@ func (tm *TaskManager) AddToQueueHead(ctx context.Context, feed models.Feed, rea
 	if !inPool {
 		// Add to queue head
 		tm.queue = append([]int64{feed.ID}, tm.queue...)
+		tm.queued[feed.ID] = queuedTask{host: host, reason: reason}
 		added = true
 	}
 
@@ -246,6 +271,12 @@ func (tm *TaskManager) AddToQueueTail(ctx context.Context, feed models.Feed, rea
 		return
 	}
 
+	// Skip feeds that are backing off after repeated failures
+	if isBackingOff(feed, time.Now()) {
+		utils.DebugLog("Skipping feed %s until %s (backing off)", feed.Title, feed.NextEligibleAt.Format(time.RFC3339))
+		return
+	}
+
 	tm.stateMutex.RLock()
 	isStopped := tm.isStopped
 	tm.stateMutex.RUnlock()
@@ -263,6 +294,8 @@ func (tm *TaskManager) AddToQueueTail(ctx context.Context, feed models.Feed, rea
 	}
 	tm.progressMutex.Unlock()
 
+	host := tm.fetcher.feedHost(feed)
+
 	// Check if already in queue or pool
 	tm.queueMutex.Lock()
 	tm.poolMutex.RLock()
@@ -276,6 +309,7 @@ func (tm *TaskManager) AddToQueueTail(ctx context.Context, feed models.Feed, rea
 	var added bool
 	if !inQueue && !inPool {
 		tm.queue = append(tm.queue, feed.ID)
+		tm.queued[feed.ID] = queuedTask{host: host, reason: reason}
 		added = true
 	}
 
@@ -317,11 +351,16 @@ func (tm *TaskManager) AddGlobalRefresh(ctx context.Context, feeds []models.Feed
 	}
 
 	// Filter out FreshRSS feeds - they are refreshed via sync, not standard refresh
+	// Feeds that are backing off after repeated failures wait for their next eligible time
 	filteredFeeds := make([]models.Feed, 0, len(feeds))
 	skippedCount := 0
+	backoffCount := 0
+	now := time.Now()
 	for _, feed := range feeds {
 		if feed.IsFreshRSSSource {
 			skippedCount++
+		} else if isBackingOff(feed, now) {
+			backoffCount++
 		} else {
 			filteredFeeds = append(filteredFeeds, feed)
 		}
@@ -329,6 +368,9 @@ func (tm *TaskManager) AddGlobalRefresh(ctx context.Context, feeds []models.Feed
 	if skippedCount > 0 {
 		log.Printf("Filtered out %d FreshRSS feeds from global refresh (refreshed via sync only)", skippedCount)
 	}
+	if backoffCount > 0 {
+		log.Printf("Deferred %d failing feeds from global refresh (backing off)", backoffCount)
+	}
 	feeds = filteredFeeds
 
 	if len(feeds) == 0 {
@@ -366,6 +408,11 @@ func (tm *TaskManager) AddGlobalRefresh(ctx context.Context, feeds []models.Feed
 		log.Printf("Failed to clear all feed errors: %v", err)
 	}
 
+	hosts := make(map[int64]string, len(feeds))
+	for _, feed := range feeds {
+		hosts[feed.ID] = tm.fetcher.feedHost(feed)
+	}
+
 	// Add feeds to queue tail with deduplication
 	tm.queueMutex.Lock()
 	tm.poolMutex.RLock()
@@ -386,6 +433,7 @@ func (tm *TaskManager) AddGlobalRefresh(ctx context.Context, feeds []models.Feed
 	for _, feed := range feeds {
 		if !existingFeedIDs[feed.ID] {
 			tm.queue = append(tm.queue, feed.ID)
+			tm.queued[feed.ID] = queuedTask{host: hosts[feed.ID], reason: TaskReasonScheduledGlobal}
 			existingFeedIDs[feed.ID] = true
 			addedCount++
 			addedFeeds = append(addedFeeds, feed)
@@ -425,6 +473,7 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 	// Remove from queue if present
 	tm.queueMutex.Lock()
 	removedFromQueue := removeFromQueue(&tm.queue, feed.ID)
+	delete(tm.queued, feed.ID)
 	tm.queueMutex.Unlock()
 
 	// Remove from pool if present
@@ -472,6 +521,13 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 			tm.checkCompletion()
 		}()
 
+		// Respect a Retry-After pause requested by the host
+		host := tm.fetcher.feedHost(task.Feed)
+		if until := tm.hosts.blockedUntil(host); !until.IsZero() {
+			log.Printf("Skipping immediate fetch of feed %s: %s asked to retry after %s", task.Feed.Title, host, until.Format(time.RFC3339))
+			return
+		}
+
 		// Execute with timeout and retry
 		var err error
 		var success bool
@@ -483,20 +539,24 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 		ctx1, cancel1 := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel1()
 
-		err = tm.fetcher.fetchFeedWithContext(ctx1, task.Feed)
+		if err = tm.hosts.wait(ctx1, host); err == nil {
+			err = tm.fetcher.fetchAndLog(ctx1, task.Feed, task.Reason)
+		}
 		if err == nil {
 			success = true
 			log.Printf("Successfully fetched feed: %s (immediate, first attempt)", task.Feed.Title)
 		}
 
 		// Second attempt: use configured retry timeout if first attempt failed
-		if !success && err != nil {
+		if !success && err != nil && !isRateLimited(err) {
 			log.Printf("First attempt failed for %s: %v, retrying with %v timeout", task.Feed.Title, err, retryTimeoutSeconds)
 
 			ctx2, cancel2 := context.WithTimeout(ctx, retryTimeoutSeconds)
 			defer cancel2()
 
-			err = tm.fetcher.fetchFeedWithContext(ctx2, task.Feed)
+			if err = tm.hosts.wait(ctx2, host); err == nil {
+				err = tm.fetcher.fetchAndLog(ctx2, task.Feed, task.Reason)
+			}
 			if err == nil {
 				success = true
 				log.Printf("Successfully fetched feed: %s (immediate, second attempt)", task.Feed.Title)
@@ -508,6 +568,7 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 			log.Printf("Failed to fetch feed %s (immediate): %v", task.Feed.Title, err)
 			tm.fetcher.db.UpdateFeedError(task.Feed.ID, err.Error())
 			tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
+			tm.recordFailure(task.Feed, host, err)
 
 			tm.progressMutex.Lock()
 			if tm.progress.Errors == nil {
@@ -518,6 +579,7 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 		} else {
 			tm.fetcher.db.UpdateFeedError(task.Feed.ID, "")
 			tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
+			tm.fetcher.db.ResetFeedBackoff(task.Feed.ID)
 		}
 	}()
 
@@ -543,18 +605,27 @@ func (tm *TaskManager) processQueue(ctx context.Context) {
 		tm.queueMutex.Lock()
 		tm.poolMutex.Lock()
 
-		// Get next task from queue
+		// Get the first queued task whose host has a free slot
+		// Tasks for busy hosts keep their place and are picked up when a slot frees
 		var feedID int64
+		var next queuedTask
 		if len(tm.queue) > 0 && len(tm.pool) < tm.poolCapacity {
-			feedID = tm.queue[0]
-			tm.queue = tm.queue[1:]
+			for i, id := range tm.queue {
+				if tm.hosts.tryAcquire(tm.queued[id].host) {
+					feedID, next = id, tm.queued[id]
+					tm.queue = append(tm.queue[:i], tm.queue[i+1:]...)
+					delete(tm.queued, id)
+					break
+				}
+			}
 		}
+		host := next.host
 
 		tm.poolMutex.Unlock()
 		tm.queueMutex.Unlock()
 
 		if feedID == 0 {
-			// No task available or pool is full
+			// No task available, pool is full or all queued hosts are busy
 			tm.checkCompletion()
 			return
 		}
@@ -563,13 +634,14 @@ func (tm *TaskManager) processQueue(ctx context.Context) {
 		feed, err := tm.fetcher.db.GetFeedByID(feedID)
 		if err != nil {
 			log.Printf("Error getting feed %d: %v", feedID, err)
+			tm.hosts.release(host)
 			continue
 		}
 
 		// Create task
 		task := &RefreshTask{
 			Feed:      *feed,
-			Reason:    TaskReasonScheduledGlobal, // Default reason
+			Reason:    next.reason,
 			CreatedAt: time.Now(),
 		}
 
@@ -590,15 +662,16 @@ func (tm *TaskManager) processQueue(ctx context.Context) {
 
 		// Start worker goroutine
 		tm.wg.Add(1)
-		go tm.processTask(ctx, task)
+		go tm.processTask(ctx, task, host)
 	}
 }
 
 // processTask processes a single task with timeout and retry logic
-func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
+func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask, host string) {
 	defer func() {
-		// Release semaphore
+		// Release semaphore and host slot
 		<-tm.poolSem
+		tm.hosts.release(host)
 		tm.wg.Done()
 
 		// Remove from pool
@@ -618,6 +691,12 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 
 	log.Printf("Processing feed: %s (reason: %d)", task.Feed.Title, task.Reason)
 
+	// Respect a Retry-After pause requested by the host instead of fetching now
+	if until := tm.hosts.blockedUntil(host); !until.IsZero() {
+		tm.deferForHost(task.Feed, host, until)
+		return
+	}
+
 	// Try fetching with timeout and retry
 	var err error
 	var success bool
@@ -631,21 +710,26 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 	defer cancel1()
 
 	log.Printf("Starting first attempt to fetch feed: %s (timeout: 60s)", task.Feed.Title)
-	err = tm.fetcher.fetchFeedWithContext(ctx1, task.Feed)
+	if err = tm.hosts.wait(ctx1, host); err == nil {
+		err = tm.fetcher.fetchAndLog(ctx1, task.Feed, task.Reason)
+	}
 	if err == nil {
 		success = true
 		log.Printf("Successfully fetched feed: %s (first attempt)", task.Feed.Title)
 	}
 
 	// Second attempt: use configured retry timeout if first attempt failed
-	if !success && err != nil {
+	// A host that answered 429/503 is not retried right away
+	if !success && err != nil && !isRateLimited(err) {
 		log.Printf("First attempt failed for %s: %v, retrying with %v timeout", task.Feed.Title, err, retryTimeoutSeconds)
 		tm.logOperation("RT", task.Feed.Title)
 
 		ctx2, cancel2 := context.WithTimeout(ctx, retryTimeoutSeconds)
 		defer cancel2()
 
-		err = tm.fetcher.fetchFeedWithContext(ctx2, task.Feed)
+		if err = tm.hosts.wait(ctx2, host); err == nil {
+			err = tm.fetcher.fetchAndLog(ctx2, task.Feed, task.Reason)
+		}
 		if err == nil {
 			success = true
 			log.Printf("Successfully fetched feed: %s (second attempt)", task.Feed.Title)
@@ -660,6 +744,7 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 		// Update feed error and last_updated in database
 		tm.fetcher.db.UpdateFeedError(task.Feed.ID, err.Error())
 		tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
+		tm.recordFailure(task.Feed, host, err)
 
 		// Add to progress errors
 		tm.progressMutex.Lock()
@@ -673,6 +758,44 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 		// Clear error on success and update last_updated
 		tm.fetcher.db.UpdateFeedError(task.Feed.ID, "")
 		tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
+		tm.fetcher.db.ResetFeedBackoff(task.Feed.ID)
+	}
+}
+
+// recordFailure backs off a feed after a failed refresh so feeds that keep failing
+// aren't retried at full cadence. A Retry-After from the server also pauses the host
+// and pushes the next refresh at least that far out.
+func (tm *TaskManager) recordFailure(feed models.Feed, host string, err error) {
+	next := time.Now().Add(backoffDelay(feed.ConsecutiveFailures + 1))
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && !statusErr.RetryAfter.IsZero() {
+		tm.hosts.block(host, statusErr.RetryAfter)
+		if statusErr.RetryAfter.After(next) {
+			next = statusErr.RetryAfter
+		}
+	}
+
+	failures, dbErr := tm.fetcher.db.RecordFeedFailure(feed.ID, next)
+	if dbErr != nil {
+		log.Printf("Failed to record failure for feed %s: %v", feed.Title, dbErr)
+		return
+	}
+	log.Printf("Feed %s failed %d time(s) in a row, next scheduled refresh after %s", feed.Title, failures, next.Format(time.RFC3339))
+
+	tm.fetcher.updateDeadFeeds()
+}
+
+// deferForHost postpones a feed whose host asked us to retry later
+func (tm *TaskManager) deferForHost(feed models.Feed, host string, until time.Time) {
+	log.Printf("Deferring feed %s: %s asked to retry after %s", feed.Title, host, until.Format(time.RFC3339))
+	tm.logOperation("DF", feed.Title)
+
+	if feed.NextEligibleAt != nil && !feed.NextEligibleAt.Before(until) {
+		return
+	}
+	if err := tm.fetcher.db.SetFeedNextEligible(feed.ID, until); err != nil {
+		log.Printf("Failed to defer feed %s: %v", feed.Title, err)
 	}
 }
 
@@ -880,6 +1003,7 @@ func (tm *TaskManager) ClearQueue() {
 	defer tm.queueMutex.Unlock()
 
 	tm.queue = make([]int64, 0)
+	tm.queued = make(map[int64]queuedTask)
 
 	log.Println("Queue cleared")
 }
@@ -902,6 +1026,17 @@ func (tm *TaskManager) updateStats() {
 
 // Helper functions
 
+// isBackingOff reports whether scheduled refreshes should still skip a feed
+func isBackingOff(feed models.Feed, now time.Time) bool {
+	return feed.NextEligibleAt != nil && feed.NextEligibleAt.After(now)
+}
+
+// isRateLimited reports whether err is a 429 or 503 response
+func isRateLimited(err error) bool {
+	var statusErr *HTTPStatusError
+	return errors.As(err, &statusErr) && statusErr.IsRateLimited()
+}
+
 // removeFromQueue removes a feed ID from the queue and returns true if it was present
 func removeFromQueue(queue *[]int64, feedID int64) bool {
 	for i, id := range *queue {
@@ -988,9 +1123,9 @@ func (tm *TaskManager) initTaskLog() {
 }
 
 // logOperation logs a task operation with the specified format
-// Format: AF/AR/MV/RT/SC/FL n/m name
+// Format: AF/AR/MV/RT/SC/FL/DF n/m name
 // AF = Add to Front (queue head), AR = Add to Rear (queue tail)
-// MV = Move to Pool, RT = Retry, SC = Success, FL = Failure
+// MV = Move to Pool, RT = Retry, SC = Success, FL = Failure, DF = Deferred (host rate limit)
 // n = pool task count, m = queue task count
 func (tm *TaskManager) logOperation(operation string, feedName string) {
 	if !tm.logEnabled || tm.logFile == nil {
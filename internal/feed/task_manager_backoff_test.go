@@ -0,0 +1,81 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func TestTaskManager_RetryAfterAndBackoff(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	defer db.Close()
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	f := NewFetcher(db)
+	id, err := db.AddFeed(&models.Feed{Title: "limited", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	feed, _ := db.GetFeedByID(id)
+
+	tm := f.GetTaskManager()
+	tm.AddToQueueHead(context.Background(), *feed, TaskReasonManualRefresh)
+	if !tm.Wait(5 * time.Second) {
+		t.Fatal("task manager did not finish in time")
+	}
+
+	// A 429 is neither retried nor refetched through the fallback parsers
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+
+	feed, _ = db.GetFeedByID(id)
+	if feed.ConsecutiveFailures != 1 {
+		t.Errorf("expected 1 consecutive failure, got %d", feed.ConsecutiveFailures)
+	}
+	if feed.NextEligibleAt == nil || time.Until(*feed.NextEligibleAt) < 59*time.Minute {
+		t.Errorf("expected next eligible time about an hour out, got %v", feed.NextEligibleAt)
+	}
+	if tm.hosts.blockedUntil(f.feedHost(*feed)).IsZero() {
+		t.Error("expected the host to be paused")
+	}
+
+	// Scheduled refreshes skip the feed while it backs off
+	tm.AddGlobalRefresh(context.Background(), []models.Feed{*feed})
+	tm.AddToQueueTail(context.Background(), *feed, TaskReasonScheduledCustom)
+	tm.Wait(time.Second)
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("expected backed-off feed to be skipped, got %d requests", got)
+	}
+	if feed, _ = db.GetFeedByID(id); feed.LastError == "" {
+		t.Error("expected a backed-off feed to keep its error across a global refresh")
+	}
+
+	// A success clears the backoff
+	if err := db.ResetFeedBackoff(id); err != nil {
+		t.Fatalf("ResetFeedBackoff error: %v", err)
+	}
+	feed, _ = db.GetFeedByID(id)
+	if feed.ConsecutiveFailures != 0 || feed.NextEligibleAt != nil {
+		t.Errorf("expected backoff to be cleared, got %d / %v", feed.ConsecutiveFailures, feed.NextEligibleAt)
+	}
+}
@@ -0,0 +1,309 @@
+package feed
+
+import (
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+	"MrRSS/internal/websub"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	// WebSubFallbackPollInterval is how often feeds with an active WebSub lease are still polled,
+	// in case the hub silently stops delivering updates
+	WebSubFallbackPollInterval = 24 * time.Hour
+	// WebSubRenewWindow is how long before lease expiry a subscription is renewed
+	WebSubRenewWindow = 24 * time.Hour
+)
+
+// DefaultWebSubPath is where the server receives WebSub callbacks
+const DefaultWebSubPath = "/api/websub/"
+
+// ErrWebSubUnknownFeed is returned when a WebSub callback targets a feed without a subscription
+var ErrWebSubUnknownFeed = errors.New("no websub subscription for feed")
+
+// webSubCallbackURL returns the public callback URL for a feed, or "" if WebSub is disabled.
+// WebSub is only available in server mode with websub_callback_url configured,
+// since hubs must be able to reach the callback from the internet.
+func (f *Fetcher) webSubCallbackURL(feedID int64) string {
+	if !utils.IsServerMode() {
+		return ""
+	}
+	base, _ := f.db.GetSetting("websub_callback_url")
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s%s%d", base, f.webSubPath, feedID)
+}
+
+// SetWebSubPath sets the path under which hubs reach this fetcher's WebSub callbacks,
+// for servers that route callbacks of several users' fetchers.
+func (f *Fetcher) SetWebSubPath(path string) {
+	f.webSubPath = path
+}
+
+// subscribeWebSubFromDocument subscribes a feed to its hub if the feed document advertises one.
+func (f *Fetcher) subscribeWebSubFromDocument(ctx context.Context, feedID int64, feedURL string, document string) {
+	if f.webSubCallbackURL(feedID) == "" {
+		return
+	}
+
+	links := websub.DiscoverLinks(nil, []byte(document))
+	if links.Hub == "" {
+		return
+	}
+	topic := links.Self
+	if topic == "" {
+		topic = feedURL
+	}
+
+	if err := f.SubscribeWebSub(ctx, feedID, links.Hub, topic); err != nil {
+		log.Printf("WebSub: failed to subscribe feed %d to hub %s: %v", feedID, links.Hub, err)
+	}
+}
+
+// SubscribeWebSub sends a subscription request for the feed to the hub.
+// The subscription becomes active once the hub verifies it through the callback.
+func (f *Fetcher) SubscribeWebSub(ctx context.Context, feedID int64, hubURL, topicURL string) error {
+	callback := f.webSubCallbackURL(feedID)
+	if callback == "" {
+		return fmt.Errorf("websub is not enabled")
+	}
+
+	existing, err := f.db.GetWebSubSubscription(feedID)
+	if err != nil {
+		return err
+	}
+
+	// Keep the existing secret when renewing so in-flight deliveries still verify
+	secret := ""
+	if existing != nil {
+		secret = existing.Secret
+	}
+	if secret == "" {
+		secret, err = websub.GenerateSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+	}
+
+	sub := &database.WebSubSubscription{
+		FeedID:   feedID,
+		HubURL:   hubURL,
+		TopicURL: topicURL,
+		Secret:   secret,
+		State:    database.WebSubStatePending,
+	}
+	// Keep the current lease while a renewal is pending so polling stays backed off
+	if existing != nil && existing.IsActive() {
+		sub.State = existing.State
+		sub.LeaseSeconds = existing.LeaseSeconds
+		sub.LeaseExpires = existing.LeaseExpires
+	}
+	if err := f.db.SaveWebSubSubscription(sub); err != nil {
+		return err
+	}
+
+	feed, err := f.db.GetFeedByID(feedID)
+	if err != nil {
+		return err
+	}
+	client, err := f.getHTTPClient(*feed)
+	if err != nil {
+		return err
+	}
+
+	err = websub.Subscribe(ctx, client, websub.SubscriptionRequest{
+		Hub:          hubURL,
+		Topic:        topicURL,
+		Callback:     callback,
+		Secret:       secret,
+		LeaseSeconds: websub.DefaultLeaseSeconds,
+	})
+	if err != nil {
+		sub.LastError = err.Error()
+		f.db.SaveWebSubSubscription(sub)
+		return err
+	}
+
+	utils.DebugLog("WebSub: subscription request sent for feed %d (hub: %s, topic: %s)", feedID, hubURL, topicURL)
+	return nil
+}
+
+// VerifyWebSubIntent handles a hub's verification request for the callback of a feed.
+// It returns true if the request matches a subscription we asked for, in which case
+// the caller must echo the challenge back to the hub.
+func (f *Fetcher) VerifyWebSubIntent(feedID int64, mode, topic string, leaseSeconds int) bool {
+	sub, err := f.db.GetWebSubSubscription(feedID)
+	if err != nil {
+		return false
+	}
+	if sub == nil {
+		// Confirm unsubscribing from feeds that have been deleted
+		return mode == websub.ModeUnsubscribe
+	}
+	if topic != sub.TopicURL {
+		return false
+	}
+
+	switch mode {
+	case websub.ModeSubscribe:
+		if sub.State == database.WebSubStateUnsubscribed {
+			return false
+		}
+		if leaseSeconds <= 0 {
+			leaseSeconds = websub.DefaultLeaseSeconds
+		}
+		if err := f.db.UpdateWebSubState(feedID, database.WebSubStateActive, leaseSeconds, ""); err != nil {
+			log.Printf("WebSub: failed to activate subscription for feed %d: %v", feedID, err)
+			return false
+		}
+		log.Printf("WebSub: subscription for feed %d verified (lease: %ds)", feedID, leaseSeconds)
+		return true
+	case websub.ModeUnsubscribe:
+		if sub.State != database.WebSubStateUnsubscribed {
+			return false
+		}
+		if err := f.db.DeleteWebSubSubscription(feedID); err != nil {
+			log.Printf("WebSub: failed to delete subscription for feed %d: %v", feedID, err)
+		}
+		return true
+	}
+	return false
+}
+
+// UnsubscribeWebSub asks the hub to stop pushing updates for a feed.
+func (f *Fetcher) UnsubscribeWebSub(ctx context.Context, feedID int64) error {
+	sub, err := f.db.GetWebSubSubscription(feedID)
+	if err != nil || sub == nil {
+		return err
+	}
+	if err := f.db.UpdateWebSubState(feedID, database.WebSubStateUnsubscribed, 0, ""); err != nil {
+		return err
+	}
+
+	callback := f.webSubCallbackURL(feedID)
+	if callback == "" {
+		return nil
+	}
+	client, err := f.getHTTPClient(models.Feed{})
+	if err != nil {
+		return err
+	}
+	return websub.Unsubscribe(ctx, client, websub.SubscriptionRequest{
+		Hub:      sub.HubURL,
+		Topic:    sub.TopicURL,
+		Callback: callback,
+	})
+}
+
+// DenyWebSubSubscription records that the hub denied the subscription of a feed.
+func (f *Fetcher) DenyWebSubSubscription(feedID int64, topic, reason string) {
+	sub, err := f.db.GetWebSubSubscription(feedID)
+	if err != nil || sub == nil || sub.TopicURL != topic {
+		return
+	}
+	if err := f.db.UpdateWebSubState(feedID, database.WebSubStateDenied, 0, reason); err != nil {
+		log.Printf("WebSub: failed to mark subscription for feed %d as denied: %v", feedID, err)
+	}
+	log.Printf("WebSub: hub denied subscription for feed %d: %s", feedID, reason)
+}
+
+// HandleWebSubContent processes a content distribution request pushed by a hub.
+// The payload is checked against the subscription secret and then goes through
+// the same article pipeline as a polled refresh.
+func (f *Fetcher) HandleWebSubContent(ctx context.Context, feedID int64, body []byte, signature string) error {
+	sub, err := f.db.GetWebSubSubscription(feedID)
+	if err != nil {
+		return err
+	}
+	if sub == nil || sub.State == database.WebSubStateUnsubscribed {
+		return ErrWebSubUnknownFeed
+	}
+	if sub.Secret != "" && !websub.VerifySignature(sub.Secret, body, signature) {
+		return fmt.Errorf("invalid X-Hub-Signature for feed %d", feedID)
+	}
+
+	feed, err := f.db.GetFeedByID(feedID)
+	if err != nil {
+		return err
+	}
+
+	parser := gofeed.NewParser()
+	parsedFeed, err := parser.ParseString(sanitizeFeedXML(string(body)))
+	if err != nil {
+		return fmt.Errorf("failed to parse pushed content: %w", err)
+	}
+
+	if err := f.saveParsedFeed(ctx, *feed, parsedFeed); err != nil {
+		return err
+	}
+	f.db.UpdateFeedLastUpdated(feedID)
+
+	utils.DebugLog("WebSub: processed %d pushed items for feed %s", len(parsedFeed.Items), feed.Title)
+	return nil
+}
+
+// RenewWebSubLeases re-subscribes feeds whose lease is about to expire.
+func (f *Fetcher) RenewWebSubLeases(ctx context.Context) {
+	subs, err := f.db.GetWebSubSubscriptionsExpiringBefore(time.Now().Add(WebSubRenewWindow))
+	if err != nil {
+		log.Printf("WebSub: failed to load subscriptions for renewal: %v", err)
+		return
+	}
+
+	for _, sub := range subs {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// For short leases, wait until half of the lease has passed
+		if sub.LeaseSeconds > 0 && sub.LeaseExpires != nil {
+			renewWindow := time.Duration(sub.LeaseSeconds) * time.Second / 2
+			if renewWindow > WebSubRenewWindow {
+				renewWindow = WebSubRenewWindow
+			}
+			if time.Until(*sub.LeaseExpires) > renewWindow {
+				continue
+			}
+		}
+		// Skip renewals that were already requested recently and are awaiting verification
+		if time.Since(sub.UpdatedAt) < time.Hour && sub.LastError == "" && sub.LeaseExpires != nil && time.Until(*sub.LeaseExpires) > 0 {
+			continue
+		}
+
+		log.Printf("WebSub: renewing lease for feed %d", sub.FeedID)
+		if err := f.SubscribeWebSub(ctx, sub.FeedID, sub.HubURL, sub.TopicURL); err != nil {
+			log.Printf("WebSub: failed to renew lease for feed %d: %v", sub.FeedID, err)
+		}
+	}
+}
+
+// FilterWebSubPushedFeeds removes feeds whose content is pushed through an active
+// WebSub lease, unless their fallback poll is due.
+func (f *Fetcher) FilterWebSubPushedFeeds(feeds []models.Feed) []models.Feed {
+	active, err := f.db.GetActiveWebSubFeedIDs()
+	if err != nil || len(active) == 0 {
+		return feeds
+	}
+
+	filtered := make([]models.Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if active[feed.ID] && time.Since(feed.LastUpdated) < WebSubFallbackPollInterval {
+			utils.DebugLog("WebSub: skipping poll for feed %s (active lease)", feed.Title)
+			continue
+		}
+		filtered = append(filtered, feed)
+	}
+	return filtered
+}
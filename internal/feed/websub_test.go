@@ -0,0 +1,125 @@
+package feed
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+func setupWebSubTest(t *testing.T) (*database.DB, *Fetcher, int64) {
+	t.Helper()
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, err := db.AddFeed(&models.Feed{Title: "push", URL: "https://example.com/feed.xml"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	err = db.SaveWebSubSubscription(&database.WebSubSubscription{
+		FeedID:   id,
+		HubURL:   "https://hub.example.com/",
+		TopicURL: "https://example.com/feed.xml",
+		Secret:   "secret",
+		State:    database.WebSubStatePending,
+	})
+	if err != nil {
+		t.Fatalf("SaveWebSubSubscription error: %v", err)
+	}
+
+	return db, NewFetcher(db), id
+}
+
+func TestVerifyWebSubIntent(t *testing.T) {
+	db, f, id := setupWebSubTest(t)
+
+	if f.VerifyWebSubIntent(id, "subscribe", "https://other.example.com/feed.xml", 3600) {
+		t.Error("expected verification with wrong topic to fail")
+	}
+	if f.VerifyWebSubIntent(id, "unsubscribe", "https://example.com/feed.xml", 0) {
+		t.Error("expected unsubscribe verification of a wanted subscription to fail")
+	}
+	if !f.VerifyWebSubIntent(id, "subscribe", "https://example.com/feed.xml", 3600) {
+		t.Fatal("expected subscribe verification to succeed")
+	}
+
+	sub, err := db.GetWebSubSubscription(id)
+	if err != nil || sub == nil {
+		t.Fatalf("GetWebSubSubscription error: %v", err)
+	}
+	if !sub.IsActive() {
+		t.Errorf("expected subscription to be active, got state %q", sub.State)
+	}
+	if sub.LeaseExpires == nil || time.Until(*sub.LeaseExpires) > time.Hour {
+		t.Errorf("expected lease of about one hour, got %v", sub.LeaseExpires)
+	}
+}
+
+func TestHandleWebSubContent(t *testing.T) {
+	db, f, id := setupWebSubTest(t)
+
+	body := []byte(`<?xml version="1.0"?><rss><channel><title>Push</title>` +
+		`<item><title>pushed item</title><link>https://example.com/1</link><guid>1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 MST</pubDate></item>` +
+		`</channel></rss>`)
+
+	if err := f.HandleWebSubContent(context.Background(), id, body, "sha1=deadbeef"); err == nil {
+		t.Fatal("expected error for invalid signature")
+	}
+
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := f.HandleWebSubContent(context.Background(), id, body, signature); err != nil {
+		t.Fatalf("HandleWebSubContent error: %v", err)
+	}
+
+	articles, err := db.GetArticles("all", id, "", false, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticles error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "pushed item" {
+		t.Errorf("expected pushed article to be saved, got %+v", articles)
+	}
+}
+
+func TestFilterWebSubPushedFeeds(t *testing.T) {
+	db, f, id := setupWebSubTest(t)
+
+	feeds := []models.Feed{
+		{ID: id, Title: "push", LastUpdated: time.Now()},
+		{ID: id + 1, Title: "poll", LastUpdated: time.Now()},
+	}
+
+	// Pending subscriptions do not affect polling
+	if got := f.FilterWebSubPushedFeeds(feeds); len(got) != 2 {
+		t.Fatalf("expected 2 feeds before verification, got %d", len(got))
+	}
+
+	if err := db.UpdateWebSubState(id, database.WebSubStateActive, 3600, ""); err != nil {
+		t.Fatalf("UpdateWebSubState error: %v", err)
+	}
+
+	got := f.FilterWebSubPushedFeeds(feeds)
+	if len(got) != 1 || got[0].ID != id+1 {
+		t.Errorf("expected only the polled feed, got %+v", got)
+	}
+
+	// Fallback poll is due after WebSubFallbackPollInterval
+	feeds[0].LastUpdated = time.Now().Add(-WebSubFallbackPollInterval - time.Minute)
+	if got := f.FilterWebSubPushedFeeds(feeds); len(got) != 2 {
+		t.Errorf("expected fallback poll to include pushed feed, got %d feeds", len(got))
+	}
+}
@@ -0,0 +1,335 @@
+package fever
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+// iconWorkers is the number of icons loaded concurrently for a favicons request
+const iconWorkers = 8
+
+type group struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type feedsGroup struct {
+	GroupID int64  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type favicon struct {
+	ID   int64  `json:"id"`
+	Data string `json:"data"`
+}
+
+type item struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+// groups lists every category and parent category, ordered by path
+func groups(feeds []models.Feed) []group {
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range feeds {
+		for _, name := range categoryGroups(f.Category) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]group, 0, len(names))
+	for _, name := range names {
+		result = append(result, group{ID: groupID(name), Title: name})
+	}
+	return result
+}
+
+// feedsGroups lists the feeds of every group, including the feeds of its subcategories
+func feedsGroups(feeds []models.Feed) []feedsGroup {
+	members := make(map[string][]string)
+	var names []string
+	for _, f := range feeds {
+		for _, name := range categoryGroups(f.Category) {
+			if _, ok := members[name]; !ok {
+				names = append(names, name)
+			}
+			members[name] = append(members[name], strconv.FormatInt(f.ID, 10))
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]feedsGroup, 0, len(names))
+	for _, name := range names {
+		result = append(result, feedsGroup{GroupID: groupID(name), FeedIDs: strings.Join(members[name], ",")})
+	}
+	return result
+}
+
+func feedList(feeds []models.Feed) []feed {
+	result := make([]feed, 0, len(feeds))
+	for _, f := range feeds {
+		entry := feed{
+			ID:      f.ID,
+			Title:   f.Title,
+			URL:     f.URL,
+			SiteURL: f.Link,
+		}
+		if iconURL(f) != "" {
+			entry.FaviconID = f.ID
+		}
+		if !f.LastUpdated.IsZero() {
+			entry.LastUpdatedOnTime = f.LastUpdated.Unix()
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// iconURL returns the icon of a feed, falling back to the favicon service the frontend uses
+func iconURL(f models.Feed) string {
+	if f.ImageURL != "" {
+		return f.ImageURL
+	}
+	site := f.Link
+	if site == "" {
+		site = f.URL
+	}
+	u, err := url.Parse(site)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return "https://www.google.com/s2/favicons?domain=" + u.Hostname()
+}
+
+// favicons loads the icons of all feeds as data URIs. Icons that can't be loaded are left out.
+func (s *account) favicons(feeds []models.Feed) []favicon {
+	jobs := make(chan models.Feed)
+	var mu sync.Mutex
+	result := []favicon{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < iconWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				data, contentType, err := s.icon(iconURL(f))
+				if err != nil || len(data) == 0 {
+					continue
+				}
+				if contentType == "" {
+					contentType = http.DetectContentType(data)
+				}
+				mu.Lock()
+				result = append(result, favicon{ID: f.ID, Data: contentType + ";base64," + base64.StdEncoding.EncodeToString(data)})
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, f := range feeds {
+		if iconURL(f) != "" {
+			jobs <- f
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// items adds up to maxItems items selected by with_ids, max_id or since_id, and the total item count
+func (s *account) items(r *http.Request, response map[string]interface{}) error {
+	filter := database.ArticleStreamFilter{ByID: true, Limit: maxItems}
+	switch {
+	case r.Form.Get("with_ids") != "":
+		ids := parseIDs(r.Form.Get("with_ids"))
+		if len(ids) > maxItems {
+			ids = ids[:maxItems]
+		}
+		filter.IDs = ids
+		filter.OldestFirst = true
+	case r.Form.Get("max_id") != "":
+		filter.MaxID, _ = strconv.ParseInt(r.Form.Get("max_id"), 10, 64)
+	default:
+		filter.SinceID, _ = strconv.ParseInt(r.Form.Get("since_id"), 10, 64)
+		filter.OldestFirst = true
+	}
+
+	articles, err := s.db.GetArticleStream(filter)
+	if err != nil {
+		return err
+	}
+	total, err := s.db.CountArticleStream(database.ArticleStreamFilter{})
+	if err != nil {
+		return err
+	}
+
+	items := make([]item, 0, len(articles))
+	for _, a := range articles {
+		html, _, err := s.db.GetArticleContent(a.ID)
+		if err != nil {
+			log.Printf("[Fever] Error loading content of article %d: %v", a.ID, err)
+		}
+		it := item{
+			ID:     a.ID,
+			FeedID: a.FeedID,
+			Title:  a.Title,
+			Author: a.Author,
+			HTML:   html,
+			URL:    a.URL,
+		}
+		if a.IsFavorite {
+			it.IsSaved = 1
+		}
+		if a.IsRead {
+			it.IsRead = 1
+		}
+		if !a.PublishedAt.IsZero() {
+			it.CreatedOnTime = a.PublishedAt.Unix()
+		}
+		items = append(items, it)
+	}
+	response["items"] = items
+	response["total_items"] = total
+	return nil
+}
+
+// addIDs adds the comma-separated IDs of the articles matching filter to the response
+func (s *account) addIDs(response map[string]interface{}, key string, filter database.ArticleStreamFilter) error {
+	filter.ByID = true
+	filter.OldestFirst = true
+	ids, err := s.db.GetArticleStreamIDs(filter)
+	if err != nil {
+		return err
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	response[key] = strings.Join(parts, ",")
+	return nil
+}
+
+// mark changes the read or saved state of an item, or marks a feed or group as read.
+// Changes go through the *WithSync methods and are queued for FreshRSS, so that
+// articles of FreshRSS feeds stay consistent upstream.
+func (s *account) mark(r *http.Request, mark string, feeds []models.Feed, response map[string]interface{}) error {
+	as := r.Form.Get("as")
+	id, err := strconv.ParseInt(r.Form.Get("id"), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	var requests []database.SyncRequest
+	switch mark {
+	case "item":
+		var req *database.SyncRequest
+		switch as {
+		case "read", "unread":
+			req, err = s.db.MarkArticleReadWithSync(id, as == "read")
+			if err == nil || err == sql.ErrNoRows {
+				err = s.addIDs(response, "unread_item_ids", database.ArticleStreamFilter{OnlyUnread: true})
+			}
+		case "saved", "unsaved":
+			req, err = s.db.SetArticleFavoriteWithSync(id, as == "saved")
+			if err == nil || err == sql.ErrNoRows {
+				err = s.addIDs(response, "saved_item_ids", database.ArticleStreamFilter{OnlyStarred: true})
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if req != nil {
+			requests = append(requests, *req)
+		}
+	case "feed", "group":
+		if as != "read" {
+			return nil
+		}
+		filter := database.ArticleStreamFilter{OnlyUnread: true}
+		if before, err := strconv.ParseInt(r.Form.Get("before"), 10, 64); err == nil && before > 0 {
+			filter.Before = time.Unix(before, 0)
+		}
+		if mark == "feed" {
+			filter.FeedID = id
+		} else if id > 0 {
+			// Group 0 is all items; negative groups (Sparks) have no feeds
+			category, ok := groupCategory(feeds, id)
+			if !ok {
+				return nil
+			}
+			filter.Category = category
+		} else if id < 0 {
+			return nil
+		}
+
+		ids, err := s.db.GetArticleStreamIDs(filter)
+		if err != nil {
+			return err
+		}
+		if requests, err = s.db.MarkArticlesReadWithSync(ids, true); err != nil {
+			return err
+		}
+		if err := s.addIDs(response, "unread_item_ids", database.ArticleStreamFilter{OnlyUnread: true}); err != nil {
+			return err
+		}
+	}
+	return s.db.EnqueueSyncRequests(requests)
+}
+
+// groupCategory finds the category path of a group ID
+func groupCategory(feeds []models.Feed, id int64) (string, bool) {
+	for _, f := range feeds {
+		for _, name := range categoryGroups(f.Category) {
+			if groupID(name) == id {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseIDs parses a comma-separated list of item IDs, skipping invalid entries
+func parseIDs(value string) []int64 {
+	ids := []int64{}
+	for _, part := range strings.Split(value, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,217 @@
+// Package fever implements the Fever API (version 3) for lightweight third-party readers.
+// Groups map to the slash-separated feed category hierarchy: a feed in "Tech/Go" belongs
+// to the groups "Tech" and "Tech/Go". In the multi-user server, the api_key selects the
+// user whose database is served.
+package fever
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strings"
+
+	"MrRSS/internal/cache"
+	"MrRSS/internal/database"
+	"MrRSS/internal/utils"
+)
+
+// PathPrefix is where the API is mounted. Clients append "?api" to it.
+const PathPrefix = "/fever/"
+
+// apiVersion is the Fever API version implemented by the server
+const apiVersion = 3
+
+// maxItems is the number of items returned per items request, as in the original Fever
+const maxItems = 50
+
+// Server serves the Fever API on top of the MrRSS database of each user.
+type Server struct {
+	databases func() []*database.DB
+	// icon loads a feed icon and its content type; by default from the media cache
+	icon func(iconURL string) ([]byte, string, error)
+}
+
+// account serves the requests authenticated with the api_key of one database
+type account struct {
+	db   *database.DB
+	icon func(iconURL string) ([]byte, string, error)
+}
+
+// NewServer creates a Fever API server for a single database.
+func NewServer(db *database.DB) *Server {
+	return NewMultiUserServer(func() []*database.DB { return []*database.DB{db} })
+}
+
+// NewMultiUserServer creates a Fever API server for the databases of several users.
+// Requests are served from the database whose api_key they carry.
+func NewMultiUserServer(databases func() []*database.DB) *Server {
+	return &Server{databases: databases, icon: cachedIcon}
+}
+
+// APIKey returns the api_key clients authenticate with: the MD5 of "username:password".
+func APIKey(username, password string) string {
+	sum := md5.Sum([]byte(username + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// ServeHTTP answers a Fever API request. Every response carries api_version and auth;
+// the requested sections are only added when the api_key is valid.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var enabled []*database.DB
+	for _, db := range s.databases() {
+		if value, _ := db.GetSetting("fever_enabled"); value == "true" {
+			enabled = append(enabled, db)
+		}
+	}
+	if len(enabled) == 0 {
+		http.Error(w, "Fever API is disabled", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if _, ok := r.Form["api"]; !ok {
+		http.Error(w, "Missing api parameter", http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{"api_version": apiVersion, "auth": 0}
+	given := strings.ToLower(strings.TrimSpace(r.Form.Get("api_key")))
+	var a *account
+	for _, db := range enabled {
+		apiKey, ok := apiKeyOf(db)
+		if ok && subtle.ConstantTimeCompare([]byte(given), []byte(apiKey)) == 1 {
+			a = &account{db: db, icon: s.icon}
+			break
+		}
+	}
+	if a == nil {
+		writeJSON(w, response)
+		return
+	}
+	response["auth"] = 1
+
+	if err := a.respond(r, response); err != nil {
+		log.Printf("[Fever] Error handling request: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, response)
+}
+
+// respond performs the mark operation of the request, if any, and adds the requested sections
+func (s *account) respond(r *http.Request, response map[string]interface{}) error {
+	feeds, err := s.db.GetFeeds()
+	if err != nil {
+		return err
+	}
+	var lastRefreshed int64
+	for _, feed := range feeds {
+		if t := feed.LastUpdated.Unix(); !feed.LastUpdated.IsZero() && t > lastRefreshed {
+			lastRefreshed = t
+		}
+	}
+	response["last_refreshed_on_time"] = lastRefreshed
+
+	if mark := r.Form.Get("mark"); mark != "" {
+		if err := s.mark(r, mark, feeds, response); err != nil {
+			return err
+		}
+	}
+
+	has := func(name string) bool {
+		_, ok := r.Form[name]
+		return ok
+	}
+	if has("groups") {
+		response["groups"] = groups(feeds)
+		response["feeds_groups"] = feedsGroups(feeds)
+	}
+	if has("feeds") {
+		response["feeds"] = feedList(feeds)
+		response["feeds_groups"] = feedsGroups(feeds)
+	}
+	if has("favicons") {
+		response["favicons"] = s.favicons(feeds)
+	}
+	if has("items") {
+		if err := s.items(r, response); err != nil {
+			return err
+		}
+	}
+	if has("links") {
+		response["links"] = []interface{}{}
+	}
+	if has("unread_item_ids") {
+		if err := s.addIDs(response, "unread_item_ids", database.ArticleStreamFilter{OnlyUnread: true}); err != nil {
+			return err
+		}
+	}
+	if has("saved_item_ids") {
+		if err := s.addIDs(response, "saved_item_ids", database.ArticleStreamFilter{OnlyStarred: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apiKeyOf returns the api_key of the user configured in a database
+func apiKeyOf(db *database.DB) (string, bool) {
+	username, _ := db.GetSetting("fever_username")
+	password, err := db.GetEncryptedSetting("fever_password")
+	if err != nil {
+		log.Printf("[Fever] Error reading API password: %v", err)
+		return "", false
+	}
+	if username == "" || password == "" {
+		return "", false
+	}
+	return APIKey(username, password), true
+}
+
+// groupID derives a stable group ID from a category path
+func groupID(category string) int64 {
+	h := fnv.New32a()
+	h.Write([]byte(category))
+	id := int64(h.Sum32() & 0x7fffffff)
+	if id == 0 {
+		id = 1
+	}
+	return id
+}
+
+// categoryGroups returns the groups of a category: the category and all its parents
+func categoryGroups(category string) []string {
+	if category == "" {
+		return nil
+	}
+	parts := strings.Split(category, "/")
+	groups := make([]string, 0, len(parts))
+	for i := range parts {
+		groups = append(groups, strings.Join(parts[:i+1], "/"))
+	}
+	return groups
+}
+
+// cachedIcon loads an icon through the media cache, downloading it on first use
+func cachedIcon(iconURL string) ([]byte, string, error) {
+	cacheDir, err := utils.GetMediaCacheDir()
+	if err != nil {
+		return nil, "", err
+	}
+	mediaCache, err := cache.NewMediaCache(cacheDir)
+	if err != nil {
+		return nil, "", err
+	}
+	return mediaCache.Get(iconURL, "")
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
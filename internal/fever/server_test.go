@@ -0,0 +1,300 @@
+package fever
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+const (
+	testUser     = "alice"
+	testPassword = "s3cret"
+)
+
+type testEnv struct {
+	db     *database.DB
+	server *Server
+	feeds  map[string]int64 // Feed ID by title
+	items  []int64          // Article IDs in insertion order
+}
+
+// setupServer creates feeds in "Tech", "Tech/Go" and "News" with two articles each
+func setupServer(t *testing.T) *testEnv {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	db.SetSetting("fever_enabled", "true")
+	db.SetSetting("fever_username", testUser)
+	if err := db.SetEncryptedSetting("fever_password", testPassword); err != nil {
+		t.Fatalf("SetEncryptedSetting error: %v", err)
+	}
+
+	env := &testEnv{db: db, server: NewServer(db), feeds: make(map[string]int64)}
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, f := range []models.Feed{
+		{Title: "Tech", URL: "https://tech.example.com/feed", Link: "https://tech.example.com", Category: "Tech"},
+		{Title: "Go", URL: "https://go.example.com/feed", Category: "Tech/Go", ImageURL: "https://go.example.com/icon.png"},
+		{Title: "News", URL: "https://news.example.com/feed", Category: "News"},
+	} {
+		feedID, err := db.AddFeed(&f)
+		if err != nil {
+			t.Fatalf("AddFeed error: %v", err)
+		}
+		env.feeds[f.Title] = feedID
+		for j := 0; j < 2; j++ {
+			title := f.Title + " " + string(rune('A'+j))
+			if err := db.SaveArticle(&models.Article{
+				FeedID:                feedID,
+				Title:                 title,
+				URL:                   f.Link + "/" + title,
+				PublishedAt:           base.Add(time.Duration(i*2+j) * time.Hour),
+				HasValidPublishedTime: true,
+			}); err != nil {
+				t.Fatalf("SaveArticle error: %v", err)
+			}
+		}
+	}
+	env.items, err = db.GetArticleStreamIDs(database.ArticleStreamFilter{ByID: true, OldestFirst: true})
+	if err != nil || len(env.items) != 6 {
+		t.Fatalf("GetArticleStreamIDs = %v, %v", env.items, err)
+	}
+	return env
+}
+
+// call posts a Fever request with the given query (after "?api&") and form values
+func (env *testEnv) call(t *testing.T, query string, form url.Values) (int, map[string]json.RawMessage) {
+	t.Helper()
+	if form == nil {
+		form = url.Values{}
+	}
+	if form.Get("api_key") == "" {
+		form.Set("api_key", APIKey(testUser, testPassword))
+	}
+	req := httptest.NewRequest(http.MethodPost, PathPrefix+"?api&"+query, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	env.server.ServeHTTP(rr, req)
+
+	var body map[string]json.RawMessage
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode error: %v (%s)", err, rr.Body.String())
+		}
+	}
+	return rr.Code, body
+}
+
+func decode(t *testing.T, raw json.RawMessage, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	// md5("alice:s3cret")
+	if got := APIKey(testUser, testPassword); got != "8861c3242302e22691326ee9719aee4a" {
+		t.Errorf("APIKey = %q", got)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	env := setupServer(t)
+
+	code, body := env.call(t, "groups", url.Values{"api_key": {APIKey(testUser, "wrong")}})
+	if code != http.StatusOK || string(body["auth"]) != "0" || body["groups"] != nil {
+		t.Errorf("bad key: code %d, body %v", code, body)
+	}
+	if string(body["api_version"]) != "3" {
+		t.Errorf("api_version = %s", body["api_version"])
+	}
+
+	code, body = env.call(t, "", nil)
+	if code != http.StatusOK || string(body["auth"]) != "1" || body["last_refreshed_on_time"] == nil {
+		t.Errorf("good key: code %d, body %v", code, body)
+	}
+
+	env.db.SetSetting("fever_enabled", "false")
+	if code, _ := env.call(t, "", nil); code != http.StatusForbidden {
+		t.Errorf("disabled: code %d, want %d", code, http.StatusForbidden)
+	}
+}
+
+func TestServer_GroupsFollowCategoryHierarchy(t *testing.T) {
+	env := setupServer(t)
+	_, body := env.call(t, "groups", nil)
+
+	var groups []group
+	var feedsGroups []feedsGroup
+	decode(t, body["groups"], &groups)
+	decode(t, body["feeds_groups"], &feedsGroups)
+
+	titles := make(map[int64]string)
+	for _, g := range groups {
+		titles[g.ID] = g.Title
+	}
+	if len(groups) != 3 || titles[groupID("Tech")] != "Tech" || titles[groupID("Tech/Go")] != "Tech/Go" || titles[groupID("News")] != "News" {
+		t.Fatalf("groups = %+v", groups)
+	}
+
+	members := make(map[string]string)
+	for _, fg := range feedsGroups {
+		members[titles[fg.GroupID]] = fg.FeedIDs
+	}
+	tech, golang := env.feeds["Tech"], env.feeds["Go"]
+	if members["Tech"] != joinIDs(tech, golang) || members["Tech/Go"] != joinIDs(golang) || members["News"] != joinIDs(env.feeds["News"]) {
+		t.Errorf("feeds_groups = %v", members)
+	}
+}
+
+func TestServer_FeedsAndFavicons(t *testing.T) {
+	env := setupServer(t)
+	var requested []string
+	env.server.icon = func(iconURL string) ([]byte, string, error) {
+		requested = append(requested, iconURL)
+		if strings.Contains(iconURL, "news.example.com") {
+			return nil, "", errors.New("not found")
+		}
+		return []byte("PNG"), "image/png", nil
+	}
+
+	_, body := env.call(t, "feeds", nil)
+	var feeds []feed
+	decode(t, body["feeds"], &feeds)
+	if len(feeds) != 3 || body["feeds_groups"] == nil {
+		t.Fatalf("feeds = %+v", feeds)
+	}
+	for _, f := range feeds {
+		if f.FaviconID != f.ID {
+			t.Errorf("feed %d favicon_id = %d", f.ID, f.FaviconID)
+		}
+	}
+
+	_, body = env.call(t, "favicons", nil)
+	var favicons []favicon
+	decode(t, body["favicons"], &favicons)
+	if len(requested) != 3 || len(favicons) != 2 {
+		t.Fatalf("requested %v, favicons %+v", requested, favicons)
+	}
+	if favicons[0].Data != "image/png;base64,UE5H" {
+		t.Errorf("favicon data = %q", favicons[0].Data)
+	}
+}
+
+func TestServer_Items(t *testing.T) {
+	env := setupServer(t)
+	var items []item
+
+	_, body := env.call(t, "items&since_id="+itoa(env.items[3]), nil)
+	decode(t, body["items"], &items)
+	if len(items) != 2 || items[0].ID != env.items[4] || items[1].ID != env.items[5] || string(body["total_items"]) != "6" {
+		t.Errorf("since_id items = %+v, total %s", items, body["total_items"])
+	}
+
+	_, body = env.call(t, "items&max_id="+itoa(env.items[2]), nil)
+	decode(t, body["items"], &items)
+	if len(items) != 2 || items[0].ID != env.items[1] || items[1].ID != env.items[0] {
+		t.Errorf("max_id items = %+v", items)
+	}
+
+	_, body = env.call(t, "items&with_ids="+joinIDs(env.items[5], env.items[0], 999999), nil)
+	decode(t, body["items"], &items)
+	if len(items) != 2 || items[0].ID != env.items[0] || items[0].Title != "Tech A" || items[0].CreatedOnTime == 0 {
+		t.Errorf("with_ids items = %+v", items)
+	}
+}
+
+func TestServer_Mark(t *testing.T) {
+	env := setupServer(t)
+	var ids string
+
+	_, body := env.call(t, "", url.Values{"mark": {"item"}, "as": {"read"}, "id": {itoa(env.items[0])}})
+	decode(t, body["unread_item_ids"], &ids)
+	if ids != joinIDs(env.items[1:]...) {
+		t.Errorf("unread after mark item = %q", ids)
+	}
+
+	_, body = env.call(t, "", url.Values{"mark": {"item"}, "as": {"saved"}, "id": {itoa(env.items[2])}})
+	decode(t, body["saved_item_ids"], &ids)
+	if ids != itoa(env.items[2]) {
+		t.Errorf("saved after mark item = %q", ids)
+	}
+
+	// Group "Tech" covers the Tech and Tech/Go feeds; before limits it to older articles
+	before := time.Date(2025, 3, 1, 15, 0, 0, 0, time.UTC).Unix()
+	env.call(t, "", url.Values{"mark": {"group"}, "as": {"read"}, "id": {itoa(groupID("Tech"))}, "before": {itoa(before)}})
+	_, body = env.call(t, "unread_item_ids", nil)
+	decode(t, body["unread_item_ids"], &ids)
+	if ids != joinIDs(env.items[3:]...) {
+		t.Errorf("unread after mark group = %q", ids)
+	}
+
+	env.call(t, "", url.Values{"mark": {"feed"}, "as": {"read"}, "id": {itoa(env.feeds["News"])}})
+	_, body = env.call(t, "unread_item_ids", nil)
+	decode(t, body["unread_item_ids"], &ids)
+	if ids != itoa(env.items[3]) {
+		t.Errorf("unread after mark feed = %q", ids)
+	}
+
+	env.call(t, "", url.Values{"mark": {"group"}, "as": {"read"}, "id": {"0"}})
+	_, body = env.call(t, "unread_item_ids", nil)
+	decode(t, body["unread_item_ids"], &ids)
+	if ids != "" {
+		t.Errorf("unread after mark all = %q", ids)
+	}
+}
+
+func TestServer_MarkQueuesFreshRSSChanges(t *testing.T) {
+	env := setupServer(t)
+	env.db.SetSetting("freshrss_enabled", "true")
+
+	feedID, err := env.db.AddFeed(&models.Feed{Title: "Upstream", URL: "https://upstream.example.com/feed.xml", IsFreshRSSSource: true})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	if err := env.db.SaveArticle(&models.Article{FeedID: feedID, Title: "Synced", URL: "https://upstream.example.com/synced", PublishedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveArticle error: %v", err)
+	}
+	ids, err := env.db.GetArticleStreamIDs(database.ArticleStreamFilter{FeedID: feedID})
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("GetArticleStreamIDs = %v, %v", ids, err)
+	}
+
+	env.call(t, "", url.Values{"mark": {"feed"}, "as": {"read"}, "id": {itoa(feedID)}})
+
+	pending, err := env.db.GetPendingSyncChanges(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ArticleID != ids[0] || pending[0].Action != database.SyncActionMarkRead {
+		t.Errorf("pending sync changes = %+v", pending)
+	}
+}
+
+func itoa(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func joinIDs(ids ...int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,171 @@
+// Package filter evaluates the advanced article filter conditions of the article list,
+// which are also used to select the articles of email digests.
+package filter
+
+import (
+	"log"
+	"time"
+
+	"MrRSS/internal/models"
+	"MrRSS/internal/rsshub"
+)
+
+// FeedType returns the type code of a feed
+// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email"
+func FeedType(feed *models.Feed) string {
+	// Check FreshRSS
+	if feed.IsFreshRSSSource {
+		return "freshrss"
+	}
+
+	// Check RSSHub
+	if rsshub.IsRSSHubURL(feed.URL) {
+		return "rsshub"
+	}
+
+	// Check custom script
+	if feed.ScriptPath != "" {
+		return "script"
+	}
+
+	// Check email
+	if feed.Type == "email" {
+		return "email"
+	}
+
+	// Check XPath
+	if feed.Type == "HTML+XPath" || feed.Type == "XML+XPath" {
+		return "xpath"
+	}
+
+	// Default: regular RSS/Atom feed
+	return "regular"
+}
+
+// Matches evaluates all filter conditions for an article. The maps hold the category,
+// type (see FeedType) and image mode of each feed.
+func Matches(article models.Article, conditions []models.FilterCondition, feedCategories map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool) bool {
+	if len(conditions) == 0 {
+		return true
+	}
+
+	matcher := NewTextMatcher()
+	result := evaluateSingleCondition(article, conditions[0], feedCategories, feedTypes, feedIsImageMode, matcher)
+
+	for i := 1; i < len(conditions); i++ {
+		condition := conditions[i]
+		conditionResult := evaluateSingleCondition(article, condition, feedCategories, feedTypes, feedIsImageMode, matcher)
+
+		switch condition.Logic {
+		case "and":
+			result = result && conditionResult
+		case "or":
+			result = result || conditionResult
+		}
+	}
+
+	return result
+}
+
+// evaluateSingleCondition evaluates a single filter condition for an article
+func evaluateSingleCondition(article models.Article, condition models.FilterCondition, feedCategories map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, matcher *TextMatcher) bool {
+	var result bool
+
+	switch condition.Field {
+	case "feed_name":
+		result = MatchMultiSelect(article.FeedTitle, condition.Values, condition.Value)
+
+	case "feed_category":
+		feedCategory := feedCategories[article.FeedID]
+		result = MatchMultiSelect(feedCategory, condition.Values, condition.Value)
+
+	case "article_title":
+		result = matcher.MatchText(article.Title, condition.Operator, condition.Value)
+
+	case "article_author":
+		result = matcher.MatchText(article.Author, condition.Operator, condition.Value)
+
+	case "article_tag":
+		result = matcher.MatchCategories(article.Categories, condition.Operator, condition.Values, condition.Value)
+
+	case "feed_type":
+		feedType := feedTypes[article.FeedID]
+		result = MatchMultiSelect(feedType, condition.Values, condition.Value)
+
+	case "is_image_mode_feed":
+		if condition.Value == "" {
+			result = true
+		} else {
+			wantImageMode := condition.Value == "true"
+			result = feedIsImageMode[article.FeedID] == wantImageMode
+		}
+
+	case "published_after":
+		if condition.Value == "" {
+			result = true
+		} else {
+			afterDate, err := time.Parse("2006-01-02", condition.Value)
+			if err != nil {
+				log.Printf("Invalid date format for published_after filter: %s", condition.Value)
+				result = true
+			} else {
+				result = article.PublishedAt.After(afterDate) || article.PublishedAt.Equal(afterDate)
+			}
+		}
+
+	case "published_before":
+		if condition.Value == "" {
+			result = true
+		} else {
+			beforeDate, err := time.Parse("2006-01-02", condition.Value)
+			if err != nil {
+				log.Printf("Invalid date format for published_before filter: %s", condition.Value)
+				result = true
+			} else {
+				// For "before Dec 24 (inclusive)", we want articles published on Dec 24 or earlier
+				// We compare dates only (not times) - any article from Dec 24 should be included
+				// Truncate to remove time component, preserving date in local timezone context
+				articleDateOnly := article.PublishedAt.UTC().Truncate(24 * time.Hour)
+				beforeDateOnly := beforeDate.Truncate(24 * time.Hour)
+				// Include articles on the selected date or before
+				result = !articleDateOnly.After(beforeDateOnly)
+			}
+		}
+
+	case "is_read":
+		// Filter by read/unread status
+		if condition.Value == "" {
+			result = true
+		} else {
+			wantRead := condition.Value == "true"
+			result = article.IsRead == wantRead
+		}
+
+	case "is_favorite":
+		// Filter by favorite/unfavorite status
+		if condition.Value == "" {
+			result = true
+		} else {
+			wantFavorite := condition.Value == "true"
+			result = article.IsFavorite == wantFavorite
+		}
+
+	case "is_read_later":
+		// Filter by read later status
+		if condition.Value == "" {
+			result = true
+		} else {
+			wantReadLater := condition.Value == "true"
+			result = article.IsReadLater == wantReadLater
+		}
+
+	default:
+		result = true
+	}
+
+	// Apply NOT modifier
+	if condition.Negate {
+		return !result
+	}
+	return result
+}
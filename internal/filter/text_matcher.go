@@ -0,0 +1,104 @@
+package filter
+
+import (
+	"log"
+	"regexp"
+	"strings"
+)
+
+// TextMatcher evaluates the text conditions of filters, rules, digests and notifications.
+// It compiles each regex pattern once, so a single matcher should be shared across all
+// articles of one run. A nil *TextMatcher is valid and compiles patterns every time.
+type TextMatcher struct {
+	regexes map[string]*regexp.Regexp // nil value = invalid pattern
+}
+
+// NewTextMatcher creates a text matcher with an empty pattern cache
+func NewTextMatcher() *TextMatcher {
+	return &TextMatcher{regexes: make(map[string]*regexp.Regexp)}
+}
+
+// regex returns the compiled pattern, or nil if it is invalid
+func (m *TextMatcher) regex(pattern string) *regexp.Regexp {
+	if m != nil {
+		if re, ok := m.regexes[pattern]; ok {
+			return re
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Printf("Invalid regex pattern: %v", err)
+		re = nil
+	}
+	if m != nil {
+		m.regexes[pattern] = re
+	}
+	return re
+}
+
+// MatchText matches a text field against a condition value.
+// Operators: "contains" (default), "exact", "starts_with", "word" (whole word) and "regex".
+// Matching is case-insensitive except for regex.
+func (m *TextMatcher) MatchText(text, operator, value string) bool {
+	if value == "" {
+		return true
+	}
+
+	switch operator {
+	case "exact":
+		return strings.EqualFold(text, value)
+	case "starts_with":
+		return strings.HasPrefix(strings.ToLower(text), strings.ToLower(value))
+	case "word":
+		re := m.regex(`(?i)(^|\W)` + regexp.QuoteMeta(value) + `($|\W)`)
+		return re != nil && re.MatchString(text)
+	case "regex":
+		re := m.regex(value)
+		return re != nil && re.MatchString(text)
+	default:
+		return strings.Contains(strings.ToLower(text), strings.ToLower(value))
+	}
+}
+
+// MatchCategories checks if any of the article's item categories matches the condition.
+// Selected values must match a category exactly (ignoring case); a single value uses the text operator.
+func (m *TextMatcher) MatchCategories(categories []string, operator string, values []string, singleValue string) bool {
+	if len(values) > 0 {
+		for _, category := range categories {
+			for _, val := range values {
+				if strings.EqualFold(category, val) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if singleValue == "" {
+		return true
+	}
+	for _, category := range categories {
+		if m.MatchText(category, operator, singleValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchMultiSelect checks if fieldValue contains any of the selected values, or the single
+// value when nothing is selected. Matching is case-insensitive.
+func MatchMultiSelect(fieldValue string, values []string, singleValue string) bool {
+	if len(values) > 0 {
+		lowerField := strings.ToLower(fieldValue)
+		for _, val := range values {
+			if strings.Contains(lowerField, strings.ToLower(val)) {
+				return true
+			}
+		}
+		return false
+	} else if singleValue != "" {
+		return strings.Contains(strings.ToLower(fieldValue), strings.ToLower(singleValue))
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package filter
+
+import (
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestTextMatcher(t *testing.T) {
+	matcher := NewTextMatcher()
+	tests := []struct {
+		name     string
+		text     string
+		operator string
+		value    string
+		want     bool
+	}{
+		{"contains ignores case", "Go 1.24 Released", "", "released", true},
+		{"exact", "Go 1.24 released", "exact", "GO 1.24 RELEASED", true},
+		{"exact mismatch", "Go 1.24 released", "exact", "Go 1.24", false},
+		{"starts_with", "https://blog.example.com/post", "starts_with", "HTTPS://blog.", true},
+		{"word", "Notes on C++ and Go", "word", "c++", true},
+		{"word needs boundary", "Go scheduler", "word", "sched", false},
+		{"regex", "A new   scheduler", "regex", `new\s+scheduler`, true},
+		{"invalid regex never matches", "(", "regex", "(", false},
+		{"empty value matches", "anything", "regex", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.MatchText(tt.text, tt.operator, tt.value); got != tt.want {
+				t.Errorf("MatchText(%q, %q, %q) = %v, want %v", tt.text, tt.operator, tt.value, got, tt.want)
+			}
+		})
+	}
+
+	// Patterns are compiled once per matcher
+	if _, ok := matcher.regexes[`new\s+scheduler`]; !ok {
+		t.Error("expected regex to be cached")
+	}
+	if re, ok := matcher.regexes["("]; !ok || re != nil {
+		t.Error("expected invalid regex to be cached as nil")
+	}
+
+	var unshared *TextMatcher
+	if !unshared.MatchText("Go scheduler", "word", "go") {
+		t.Error("expected a nil matcher to match without caching")
+	}
+}
+
+func TestMatches_TextOperators(t *testing.T) {
+	article := models.Article{Title: "Go 1.24 released", Categories: []string{"Programming", "Go"}}
+	tests := []struct {
+		name       string
+		conditions []models.FilterCondition
+		want       bool
+	}{
+		{"title word", []models.FilterCondition{{Field: "article_title", Operator: "word", Value: "go"}}, true},
+		{"title starts_with", []models.FilterCondition{{Field: "article_title", Operator: "starts_with", Value: "1.24"}}, false},
+		{"tag selected values", []models.FilterCondition{{Field: "article_tag", Values: []string{"go"}}}, true},
+		{"tag regex", []models.FilterCondition{{Field: "article_tag", Operator: "regex", Value: "^Prog"}}, true},
+		{"negated", []models.FilterCondition{{Field: "article_title", Value: "rust", Negate: true}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(article, tt.conditions, nil, nil, nil); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
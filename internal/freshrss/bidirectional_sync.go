@@ -25,11 +25,11 @@ type SyncResult struct {
 
 // BidirectionalSyncService handles bidirectional synchronization
 type BidirectionalSyncService struct {
-	client *Client
+	client Provider
 	db     *database.DB
 }
 
-// NewBidirectionalSyncService creates a new bidirectional sync service
+// NewBidirectionalSyncService creates a new bidirectional sync service for a FreshRSS server
 func NewBidirectionalSyncService(serverURL, username, password string, db *database.DB) *BidirectionalSyncService {
 	return &BidirectionalSyncService{
 		client: NewClient(serverURL, username, password),
@@ -154,6 +154,14 @@ func (s *BidirectionalSyncService) SyncArticleStatus(ctx context.Context, articl
 		syncErr = s.client.StarBatch(ctx, []string{identifier})
 	case database.SyncActionUnstar:
 		syncErr = s.client.UnstarBatch(ctx, []string{identifier})
+	default:
+		if label, add, ok := action.Label(); ok {
+			if add {
+				syncErr = s.client.AddLabelBatch(ctx, []string{identifier}, label)
+			} else {
+				syncErr = s.client.RemoveLabelBatch(ctx, []string{identifier}, label)
+			}
+		}
 	}
 
 	if syncErr != nil {
@@ -703,6 +711,7 @@ func (s *BidirectionalSyncService) saveArticlesFromServer(ctx context.Context, a
 			Title:          article.Title,
 			URL:            article.URL,
 			ImageURL:       imageURL,
+			Author:         article.Author,
 			Summary:        "",
 			PublishedAt:    article.Published,
 			IsRead:         isRead,
@@ -922,6 +931,7 @@ func (s *BidirectionalSyncService) pushPendingItems(ctx context.Context, pending
 	unreadIDs := make([]string, 0)
 	starIDs := make([]string, 0)
 	unstarIDs := make([]string, 0)
+	labelIDs := make(map[database.SyncAction][]string)
 	itemIDs := make([]int64, 0)
 
 	// Get article IDs to fetch FreshRSS item IDs
@@ -966,6 +976,10 @@ func (s *BidirectionalSyncService) pushPendingItems(ctx context.Context, pending
 			starIDs = append(starIDs, identifier)
 		case database.SyncActionUnstar:
 			unstarIDs = append(unstarIDs, identifier)
+		default:
+			if _, _, ok := item.Action.Label(); ok {
+				labelIDs[item.Action] = append(labelIDs[item.Action], identifier)
+			}
 		}
 	}
 
@@ -1001,6 +1015,21 @@ func (s *BidirectionalSyncService) pushPendingItems(ctx context.Context, pending
 		totalChanges += len(unstarIDs)
 	}
 
+	// Label changes are grouped by label and direction
+	for action, ids := range labelIDs {
+		label, add, _ := action.Label()
+		var err error
+		if add {
+			err = s.client.AddLabelBatch(ctx, ids, label)
+		} else {
+			err = s.client.RemoveLabelBatch(ctx, ids, label)
+		}
+		if err != nil {
+			return totalChanges, fmt.Errorf("label batch %s: %w", label, err)
+		}
+		totalChanges += len(ids)
+	}
+
 	// Mark all as synced
 	if err := s.db.MarkSynced(itemIDs); err != nil {
 		log.Printf("Warning: Failed to mark items as synced: %v", err)
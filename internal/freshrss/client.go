@@ -409,8 +409,16 @@ func (c *Client) GetStreamContents(ctx context.Context, streamID string, exclude
 const (
 	TagRead    = "user/-/state/com.google/read"
 	TagStarred = "user/-/state/com.google/starred"
+
+	// TagLabelPrefix is the stream ID prefix of user labels (folders and tags)
+	TagLabelPrefix = "user/-/label/"
 )
 
+// LabelTag returns the Google Reader tag for a user label
+func LabelTag(name string) string {
+	return TagLabelPrefix + name
+}
+
 // editTag is a helper function to add or remove tags from items
 func (c *Client) editTag(ctx context.Context, itemIDs []string, addTag string, removeTag string) error {
 	if c.authToken == "" {
@@ -505,6 +513,16 @@ func (c *Client) UnstarBatch(ctx context.Context, itemIDs []string) error {
 	return c.editTag(ctx, itemIDs, "", TagStarred)
 }
 
+// AddLabelBatch adds a user label to articles
+func (c *Client) AddLabelBatch(ctx context.Context, itemIDs []string, label string) error {
+	return c.editTag(ctx, itemIDs, LabelTag(label), "")
+}
+
+// RemoveLabelBatch removes a user label from articles
+func (c *Client) RemoveLabelBatch(ctx context.Context, itemIDs []string, label string) error {
+	return c.editTag(ctx, itemIDs, "", LabelTag(label))
+}
+
 // SubscribeToFeed subscribes to a new feed
 func (c *Client) SubscribeToFeed(ctx context.Context, feedURL, title string) error {
 	if c.authToken == "" {
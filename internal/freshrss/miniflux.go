@@ -0,0 +1,201 @@
+package freshrss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinifluxClient syncs with the REST API of a Miniflux server
+type MinifluxClient struct {
+	api jsonAPI
+}
+
+// NewMinifluxClient creates a client for a Miniflux server
+func NewMinifluxClient(serverURL, username, password string) *MinifluxClient {
+	baseURL := strings.TrimSuffix(strings.TrimSuffix(serverURL, "/"), "/v1")
+	return &MinifluxClient{api: newJSONAPI("miniflux", baseURL, username, password)}
+}
+
+type minifluxEntry struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	Status      string    `json:"status"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author"`
+	Content     string    `json:"content"`
+	PublishedAt time.Time `json:"published_at"`
+	ChangedAt   time.Time `json:"changed_at"`
+	Starred     bool      `json:"starred"`
+}
+
+// Login checks the credentials
+func (c *MinifluxClient) Login(ctx context.Context) error {
+	if err := c.api.do(ctx, http.MethodGet, "/v1/me", nil, nil); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	return nil
+}
+
+// GetSubscriptions returns the feeds with their categories
+func (c *MinifluxClient) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var feeds []struct {
+		ID       int64  `json:"id"`
+		Title    string `json:"title"`
+		FeedURL  string `json:"feed_url"`
+		Category *struct {
+			Title string `json:"title"`
+		} `json:"category"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, "/v1/feeds", nil, &feeds); err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]Subscription, 0, len(feeds))
+	for _, feed := range feeds {
+		sub := Subscription{ID: feedStreamID(feed.ID), Title: feed.Title, URL: feed.FeedURL}
+		if feed.Category != nil && feed.Category.Title != "" {
+			sub.Categories = []Category{{ID: LabelTag(feed.Category.Title), Label: feed.Category.Title}}
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, nil
+}
+
+// GetStreamContents returns the newest entries of a feed, the starred stream or the
+// read stream. The continuation is the offset of the next page.
+func (c *MinifluxClient) GetStreamContents(ctx context.Context, streamID string, excludeTypes []string, maxItems int, continuation string) (*StreamContentsResult, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(maxItems))
+	params.Set("order", "published_at")
+	params.Set("direction", "desc")
+	if continuation != "" {
+		params.Set("offset", continuation)
+	}
+
+	path := "/v1/entries"
+	switch streamID {
+	case TagStarred:
+		params.Set("starred", "true")
+	case TagRead:
+		params.Set("status", "read")
+	default:
+		feedID, ok := parseFeedStreamID(streamID)
+		if !ok {
+			return nil, fmt.Errorf("unsupported stream %q", streamID)
+		}
+		path = fmt.Sprintf("/v1/feeds/%d/entries", feedID)
+	}
+	if excludesRead(excludeTypes) {
+		params.Set("status", "unread")
+	}
+
+	var result struct {
+		Total   int             `json:"total"`
+		Entries []minifluxEntry `json:"entries"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, path+"?"+params.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+
+	contents := &StreamContentsResult{Items: make([]Article, 0, len(result.Entries)), Updated: time.Now().Unix()}
+	for _, entry := range result.Entries {
+		contents.Items = append(contents.Items, Article{
+			ID:             strconv.FormatInt(entry.ID, 10),
+			Title:          entry.Title,
+			URL:            entry.URL,
+			Content:        entry.Content,
+			Published:      entry.PublishedAt,
+			Updated:        entry.ChangedAt,
+			Author:         entry.Author,
+			Categories:     stateCategories(entry.Status == "read", entry.Starred),
+			OriginStreamID: feedStreamID(entry.FeedID),
+		})
+	}
+	offset, _ := strconv.Atoi(continuation)
+	if next := offset + len(result.Entries); len(result.Entries) > 0 && next < result.Total {
+		contents.Continuation = strconv.Itoa(next)
+	}
+	return contents, nil
+}
+
+// GetStarredArticles returns the newest starred entries
+func (c *MinifluxClient) GetStarredArticles(ctx context.Context, maxItems int) ([]Article, error) {
+	result, err := c.GetStreamContents(ctx, TagStarred, nil, maxItems, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// GetReadArticles returns the newest read entries
+func (c *MinifluxClient) GetReadArticles(ctx context.Context, maxItems int) ([]Article, error) {
+	result, err := c.GetStreamContents(ctx, TagRead, nil, maxItems, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// setStatus changes the status of entries
+func (c *MinifluxClient) setStatus(ctx context.Context, itemIDs []string, status string) error {
+	ids := numericIDs(itemIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+	body := map[string]interface{}{"entry_ids": ids, "status": status}
+	return c.api.do(ctx, http.MethodPut, "/v1/entries", body, nil)
+}
+
+// MarkAsReadBatch marks entries as read
+func (c *MinifluxClient) MarkAsReadBatch(ctx context.Context, itemIDs []string) error {
+	return c.setStatus(ctx, itemIDs, "read")
+}
+
+// MarkAsUnreadBatch marks entries as unread
+func (c *MinifluxClient) MarkAsUnreadBatch(ctx context.Context, itemIDs []string) error {
+	return c.setStatus(ctx, itemIDs, "unread")
+}
+
+// setStarred stars or unstars entries. Miniflux only toggles bookmarks, so the
+// current state of each entry is checked first.
+func (c *MinifluxClient) setStarred(ctx context.Context, itemIDs []string, starred bool) error {
+	for _, id := range numericIDs(itemIDs) {
+		var entry minifluxEntry
+		if err := c.api.do(ctx, http.MethodGet, fmt.Sprintf("/v1/entries/%d", id), nil, &entry); err != nil {
+			return err
+		}
+		if entry.Starred == starred {
+			continue
+		}
+		if err := c.api.do(ctx, http.MethodPut, fmt.Sprintf("/v1/entries/%d/bookmark", id), nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StarBatch stars entries
+func (c *MinifluxClient) StarBatch(ctx context.Context, itemIDs []string) error {
+	return c.setStarred(ctx, itemIDs, true)
+}
+
+// UnstarBatch unstars entries
+func (c *MinifluxClient) UnstarBatch(ctx context.Context, itemIDs []string) error {
+	return c.setStarred(ctx, itemIDs, false)
+}
+
+// AddLabelBatch does nothing: Miniflux has no labels on entries
+func (c *MinifluxClient) AddLabelBatch(ctx context.Context, itemIDs []string, label string) error {
+	return nil
+}
+
+// RemoveLabelBatch does nothing: Miniflux has no labels on entries
+func (c *MinifluxClient) RemoveLabelBatch(ctx context.Context, itemIDs []string, label string) error {
+	return nil
+}
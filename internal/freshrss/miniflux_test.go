@@ -0,0 +1,113 @@
+package freshrss
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"MrRSS/internal/database"
+)
+
+// minifluxStandIn serves the parts of the Miniflux API used for syncing
+type minifluxStandIn struct {
+	mu      sync.Mutex
+	status  map[int64]string
+	starred map[int64]bool
+}
+
+func (m *minifluxStandIn) entry(id int64) map[string]interface{} {
+	url, title := testReadURL, testReadTitle
+	if id == 102 {
+		url, title = testStarredURL, testStarredTitle
+	}
+	return map[string]interface{}{
+		"id": id, "feed_id": 7, "title": title, "url": url, "content": "<p>" + title + "</p>",
+		"published_at": "2024-05-01T10:00:00Z", "status": m.status[id], "starred": m.starred[id],
+	}
+}
+
+func (m *minifluxStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "secret" {
+		http.Error(w, `{"error_message":"Access Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var entries []map[string]interface{}
+	for _, id := range []int64{102, 101} {
+		status := r.URL.Query().Get("status")
+		if (status == "" || status == m.status[id]) && (r.URL.Query().Get("starred") == "" || m.starred[id]) {
+			entries = append(entries, m.entry(id))
+		}
+	}
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/v1/me":
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "username": "alice"})
+	case r.Method == "GET" && r.URL.Path == "/v1/feeds":
+		fmt.Fprintf(w, `[{"id":7,"title":%q,"feed_url":%q,"category":{"id":2,"title":%q}}]`, testFeedTitle, testFeedURL, testFeedFolder)
+	case r.Method == "GET" && (r.URL.Path == "/v1/feeds/7/entries" || r.URL.Path == "/v1/entries"):
+		json.NewEncoder(w).Encode(map[string]interface{}{"total": len(entries), "entries": entries})
+	case r.Method == "GET" && r.URL.Path == "/v1/entries/101":
+		json.NewEncoder(w).Encode(m.entry(101))
+	case r.Method == "GET" && r.URL.Path == "/v1/entries/102":
+		json.NewEncoder(w).Encode(m.entry(102))
+	case r.Method == "PUT" && r.URL.Path == "/v1/entries":
+		var req struct {
+			EntryIDs []int64 `json:"entry_ids"`
+			Status   string  `json:"status"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		for _, id := range req.EntryIDs {
+			m.status[id] = req.Status
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PUT" && r.URL.Path == "/v1/entries/101/bookmark":
+		m.starred[101] = !m.starred[101]
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PUT" && r.URL.Path == "/v1/entries/102/bookmark":
+		m.starred[102] = !m.starred[102]
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestMinifluxSync(t *testing.T) {
+	standIn := &minifluxStandIn{
+		status:  map[int64]string{101: "read", 102: "unread"},
+		starred: map[int64]bool{102: true},
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	s, db := syncWithProvider(t, ProviderMiniflux, srv.URL+"/v1/")
+	checkPulled(t, db)
+
+	pushStatus(t, s, db, testReadURL, database.SyncActionMarkUnread)
+	pushStatus(t, s, db, testReadURL, database.SyncActionStar)
+	pushStatus(t, s, db, testStarredURL, database.SyncActionStar) // already starred
+	pushStatus(t, s, db, testStarredURL, database.SyncActionMarkRead)
+
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	if standIn.status[101] != "unread" || standIn.status[102] != "read" {
+		t.Errorf("status = %v, want 101 unread and 102 read", standIn.status)
+	}
+	if !standIn.starred[101] || !standIn.starred[102] {
+		t.Errorf("starred = %v, want both starred", standIn.starred)
+	}
+}
+
+func TestMiniflux_WrongPassword(t *testing.T) {
+	srv := httptest.NewServer(&minifluxStandIn{})
+	defer srv.Close()
+
+	if err := NewMinifluxClient(srv.URL, "alice", "wrong").Login(t.Context()); err == nil {
+		t.Error("login with a wrong password succeeded")
+	}
+}
@@ -0,0 +1,216 @@
+package freshrss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nextcloudAPIPath is the path of the News API below a Nextcloud installation
+const nextcloudAPIPath = "/index.php/apps/news/api/v1-3"
+
+// Item types of the Nextcloud News API
+const (
+	nextcloudTypeFeed    = 0
+	nextcloudTypeStarred = 2
+	nextcloudTypeAll     = 3
+)
+
+// NextcloudClient syncs with the v1.3 API of the Nextcloud News app
+type NextcloudClient struct {
+	api jsonAPI
+}
+
+// NewNextcloudClient creates a client for a Nextcloud server. The server URL is the
+// Nextcloud installation or the News API itself.
+func NewNextcloudClient(serverURL, username, password string) *NextcloudClient {
+	baseURL := strings.TrimSuffix(serverURL, "/")
+	if !strings.Contains(baseURL, "/apps/news/api") {
+		baseURL += nextcloudAPIPath
+	}
+	return &NextcloudClient{api: newJSONAPI("nextcloud news", baseURL, username, password)}
+}
+
+type nextcloudItem struct {
+	ID          int64  `json:"id"`
+	FeedID      int64  `json:"feedId"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Author      string `json:"author"`
+	Body        string `json:"body"`
+	PubDate     int64  `json:"pubDate"`
+	UpdatedDate int64  `json:"updatedDate"`
+	Unread      bool   `json:"unread"`
+	Starred     bool   `json:"starred"`
+}
+
+// Login checks the credentials
+func (c *NextcloudClient) Login(ctx context.Context) error {
+	if err := c.api.do(ctx, http.MethodGet, "/folders", nil, nil); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	return nil
+}
+
+// GetSubscriptions returns the feeds with their folders as categories
+func (c *NextcloudClient) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var folders struct {
+		Folders []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"folders"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, "/folders", nil, &folders); err != nil {
+		return nil, err
+	}
+	folderNames := make(map[int64]string, len(folders.Folders))
+	for _, folder := range folders.Folders {
+		folderNames[folder.ID] = folder.Name
+	}
+
+	var feeds struct {
+		Feeds []struct {
+			ID       int64  `json:"id"`
+			URL      string `json:"url"`
+			Title    string `json:"title"`
+			FolderID *int64 `json:"folderId"`
+		} `json:"feeds"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, "/feeds", nil, &feeds); err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]Subscription, 0, len(feeds.Feeds))
+	for _, feed := range feeds.Feeds {
+		sub := Subscription{ID: feedStreamID(feed.ID), Title: feed.Title, URL: feed.URL}
+		if feed.FolderID != nil && folderNames[*feed.FolderID] != "" {
+			name := folderNames[*feed.FolderID]
+			sub.Categories = []Category{{ID: LabelTag(name), Label: name}}
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, nil
+}
+
+// GetStreamContents returns the newest items of a feed, the starred stream or the
+// read stream. The continuation is the ID of the last item returned.
+func (c *NextcloudClient) GetStreamContents(ctx context.Context, streamID string, excludeTypes []string, maxItems int, continuation string) (*StreamContentsResult, error) {
+	params := url.Values{}
+	params.Set("batchSize", strconv.Itoa(maxItems))
+	params.Set("oldestFirst", "false")
+	params.Set("getRead", strconv.FormatBool(!excludesRead(excludeTypes)))
+	if continuation != "" {
+		params.Set("offset", continuation)
+	}
+
+	onlyRead := false
+	switch streamID {
+	case TagStarred:
+		params.Set("type", strconv.Itoa(nextcloudTypeStarred))
+		params.Set("id", "0")
+	case TagRead:
+		// The API can't filter by read state, so read items are picked from all items
+		params.Set("type", strconv.Itoa(nextcloudTypeAll))
+		params.Set("id", "0")
+		params.Set("getRead", "true")
+		onlyRead = true
+	default:
+		feedID, ok := parseFeedStreamID(streamID)
+		if !ok {
+			return nil, fmt.Errorf("unsupported stream %q", streamID)
+		}
+		params.Set("type", strconv.Itoa(nextcloudTypeFeed))
+		params.Set("id", strconv.FormatInt(feedID, 10))
+	}
+
+	var result struct {
+		Items []nextcloudItem `json:"items"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, "/items?"+params.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+
+	contents := &StreamContentsResult{Items: make([]Article, 0, len(result.Items)), Updated: time.Now().Unix()}
+	for _, item := range result.Items {
+		if onlyRead && item.Unread {
+			continue
+		}
+		contents.Items = append(contents.Items, Article{
+			ID:             strconv.FormatInt(item.ID, 10),
+			Title:          item.Title,
+			URL:            item.URL,
+			Content:        item.Body,
+			Published:      time.Unix(item.PubDate, 0),
+			Updated:        time.Unix(item.UpdatedDate, 0),
+			Author:         item.Author,
+			Categories:     stateCategories(!item.Unread, item.Starred),
+			OriginStreamID: feedStreamID(item.FeedID),
+		})
+	}
+	if n := len(result.Items); n > 0 && n == maxItems {
+		contents.Continuation = strconv.FormatInt(result.Items[n-1].ID, 10)
+	}
+	return contents, nil
+}
+
+// GetStarredArticles returns the newest starred items
+func (c *NextcloudClient) GetStarredArticles(ctx context.Context, maxItems int) ([]Article, error) {
+	result, err := c.GetStreamContents(ctx, TagStarred, nil, maxItems, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// GetReadArticles returns the read items among the newest items
+func (c *NextcloudClient) GetReadArticles(ctx context.Context, maxItems int) ([]Article, error) {
+	result, err := c.GetStreamContents(ctx, TagRead, nil, maxItems, "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// changeItems applies an action such as "read" or "star" to items
+func (c *NextcloudClient) changeItems(ctx context.Context, action string, itemIDs []string) error {
+	ids := numericIDs(itemIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+	body := map[string]interface{}{"itemIds": ids}
+	return c.api.do(ctx, http.MethodPost, "/items/"+action+"/multiple", body, nil)
+}
+
+// MarkAsReadBatch marks items as read
+func (c *NextcloudClient) MarkAsReadBatch(ctx context.Context, itemIDs []string) error {
+	return c.changeItems(ctx, "read", itemIDs)
+}
+
+// MarkAsUnreadBatch marks items as unread
+func (c *NextcloudClient) MarkAsUnreadBatch(ctx context.Context, itemIDs []string) error {
+	return c.changeItems(ctx, "unread", itemIDs)
+}
+
+// StarBatch stars items
+func (c *NextcloudClient) StarBatch(ctx context.Context, itemIDs []string) error {
+	return c.changeItems(ctx, "star", itemIDs)
+}
+
+// UnstarBatch unstars items
+func (c *NextcloudClient) UnstarBatch(ctx context.Context, itemIDs []string) error {
+	return c.changeItems(ctx, "unstar", itemIDs)
+}
+
+// AddLabelBatch does nothing: Nextcloud News has no labels on items
+func (c *NextcloudClient) AddLabelBatch(ctx context.Context, itemIDs []string, label string) error {
+	return nil
+}
+
+// RemoveLabelBatch does nothing: Nextcloud News has no labels on items
+func (c *NextcloudClient) RemoveLabelBatch(ctx context.Context, itemIDs []string, label string) error {
+	return nil
+}
@@ -0,0 +1,114 @@
+package freshrss
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"MrRSS/internal/database"
+)
+
+// nextcloudStandIn serves the parts of the Nextcloud News API used for syncing
+type nextcloudStandIn struct {
+	mu      sync.Mutex
+	unread  map[int64]bool
+	starred map[int64]bool
+}
+
+func (n *nextcloudStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, nextcloudAPIPath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch {
+	case r.Method == "GET" && path == "/folders":
+		fmt.Fprintf(w, `{"folders":[{"id":3,"name":%q}]}`, testFeedFolder)
+	case r.Method == "GET" && path == "/feeds":
+		fmt.Fprintf(w, `{"feeds":[{"id":7,"url":%q,"title":%q,"folderId":3}],"starredCount":1}`, testFeedURL, testFeedTitle)
+	case r.Method == "GET" && path == "/items":
+		q := r.URL.Query()
+		var items []map[string]interface{}
+		for _, id := range []int64{102, 101} {
+			if q.Get("getRead") == "false" && !n.unread[id] || q.Get("type") == "2" && !n.starred[id] {
+				continue
+			}
+			url, title := testReadURL, testReadTitle
+			if id == 102 {
+				url, title = testStarredURL, testStarredTitle
+			}
+			items = append(items, map[string]interface{}{
+				"id": id, "feedId": 7, "title": title, "url": url, "body": "<p>" + title + "</p>",
+				"pubDate": 1714557600, "unread": n.unread[id], "starred": n.starred[id],
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	case r.Method == "POST" && strings.HasPrefix(path, "/items/"):
+		var req struct {
+			ItemIDs []int64 `json:"itemIds"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		for _, id := range req.ItemIDs {
+			switch path {
+			case "/items/read/multiple":
+				n.unread[id] = false
+			case "/items/unread/multiple":
+				n.unread[id] = true
+			case "/items/star/multiple":
+				n.starred[id] = true
+			case "/items/unstar/multiple":
+				n.starred[id] = false
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestNextcloudSync(t *testing.T) {
+	standIn := &nextcloudStandIn{
+		unread:  map[int64]bool{101: false, 102: true},
+		starred: map[int64]bool{102: true},
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	s, db := syncWithProvider(t, ProviderNextcloud, srv.URL)
+	checkPulled(t, db)
+
+	pushStatus(t, s, db, testReadURL, database.SyncActionMarkUnread)
+	pushStatus(t, s, db, testStarredURL, database.SyncActionUnstar)
+
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	if !standIn.unread[101] {
+		t.Error("item 101 not marked unread")
+	}
+	if standIn.starred[102] {
+		t.Error("item 102 not unstarred")
+	}
+}
+
+func TestNewNextcloudClient_APIURL(t *testing.T) {
+	for serverURL, want := range map[string]string{
+		"https://cloud.example.com":                                  "https://cloud.example.com" + nextcloudAPIPath,
+		"https://cloud.example.com/":                                 "https://cloud.example.com" + nextcloudAPIPath,
+		"https://cloud.example.com/index.php/apps/news/api/v1-3/":    "https://cloud.example.com/index.php/apps/news/api/v1-3",
+		"https://example.com/nextcloud/index.php/apps/news/api/v1-3": "https://example.com/nextcloud/index.php/apps/news/api/v1-3",
+	} {
+		if got := NewNextcloudClient(serverURL, "alice", "secret").api.baseURL; got != want {
+			t.Errorf("%s: API URL %s, want %s", serverURL, got, want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package freshrss
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/database"
+)
+
+// Sync providers
+const (
+	ProviderFreshRSS  = "freshrss"
+	ProviderMiniflux  = "miniflux"
+	ProviderNextcloud = "nextcloud"
+	ProviderTTRSS     = "ttrss"
+)
+
+// Provider is the API of a sync server. Providers speak in Google Reader terms:
+// subscriptions have stream IDs like "feed/<id>", articles carry TagRead and
+// TagStarred in their categories, and the starred and read streams are TagStarred
+// and TagRead.
+type Provider interface {
+	Login(ctx context.Context) error
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)
+	// GetStreamContents returns the newest articles of a stream. Excluding TagRead
+	// returns unread articles only.
+	GetStreamContents(ctx context.Context, streamID string, excludeTypes []string, maxItems int, continuation string) (*StreamContentsResult, error)
+	GetStarredArticles(ctx context.Context, maxItems int) ([]Article, error)
+	GetReadArticles(ctx context.Context, maxItems int) ([]Article, error)
+	MarkAsReadBatch(ctx context.Context, itemIDs []string) error
+	MarkAsUnreadBatch(ctx context.Context, itemIDs []string) error
+	StarBatch(ctx context.Context, itemIDs []string) error
+	UnstarBatch(ctx context.Context, itemIDs []string) error
+	AddLabelBatch(ctx context.Context, itemIDs []string, label string) error
+	RemoveLabelBatch(ctx context.Context, itemIDs []string, label string) error
+}
+
+// NewProvider creates the client of a sync server. An empty provider is FreshRSS.
+func NewProvider(provider, serverURL, username, password string) (Provider, error) {
+	switch provider {
+	case "", ProviderFreshRSS:
+		return NewClient(serverURL, username, password), nil
+	case ProviderMiniflux:
+		return NewMinifluxClient(serverURL, username, password), nil
+	case ProviderNextcloud:
+		return NewNextcloudClient(serverURL, username, password), nil
+	case ProviderTTRSS:
+		return NewTTRSSClient(serverURL, username, password), nil
+	default:
+		return nil, fmt.Errorf("unknown sync provider %q", provider)
+	}
+}
+
+// NewProviderSyncService creates a bidirectional sync service for a server of the
+// given provider
+func NewProviderSyncService(provider, serverURL, username, password string, db *database.DB) (*BidirectionalSyncService, error) {
+	client, err := NewProvider(provider, serverURL, username, password)
+	if err != nil {
+		return nil, err
+	}
+	return &BidirectionalSyncService{client: client, db: db}, nil
+}
+
+// feedStreamID returns the stream ID of a remote feed
+func feedStreamID(id int64) string {
+	return "feed/" + strconv.FormatInt(id, 10)
+}
+
+// parseFeedStreamID returns the remote feed ID of a stream ID
+func parseFeedStreamID(streamID string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(streamID, "feed/"), 10, 64)
+	return id, err == nil && strings.HasPrefix(streamID, "feed/")
+}
+
+// numericIDs parses the item IDs of servers with numeric IDs. Articles that were
+// never pulled from the server are identified by their URL and are skipped.
+func numericIDs(itemIDs []string) []int64 {
+	ids := make([]int64, 0, len(itemIDs))
+	for _, itemID := range itemIDs {
+		if id, err := strconv.ParseInt(itemID, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// excludesRead reports whether read articles are excluded from a stream
+func excludesRead(excludeTypes []string) bool {
+	for _, t := range excludeTypes {
+		if t == TagRead {
+			return true
+		}
+	}
+	return false
+}
+
+// stateCategories returns the Google Reader categories of an article's state
+func stateCategories(read, starred bool) []string {
+	var categories []string
+	if read {
+		categories = append(categories, TagRead)
+	}
+	if starred {
+		categories = append(categories, TagStarred)
+	}
+	return categories
+}
+
+// jsonAPI sends JSON requests to the REST APIs of Miniflux and Nextcloud News
+type jsonAPI struct {
+	name       string
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+func newJSONAPI(name, baseURL, username, password string) jsonAPI {
+	return jsonAPI{
+		name:       name,
+		baseURL:    baseURL,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends a request with basic authentication and decodes the response into out
+func (a *jsonAPI) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create %s request: %w", a.name, err)
+	}
+	req.SetBasicAuth(a.username, a.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request: %w", a.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s %s failed with status %d: %s", a.name, method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s response: %w", a.name, err)
+	}
+	return nil
+}
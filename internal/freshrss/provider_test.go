@@ -0,0 +1,108 @@
+package freshrss
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"MrRSS/internal/database"
+)
+
+// The stand-in servers of the provider tests serve one feed (remote ID 7, in the
+// "News" category) with a read article (ID 101) and an unread, starred one (ID 102).
+const (
+	testFeedURL      = "https://example.com/feed.xml"
+	testReadURL      = "https://example.com/read"
+	testStarredURL   = "https://example.com/starred"
+	testFeedTitle    = "Example"
+	testFeedFolder   = "News"
+	testReadTitle    = "Read article"
+	testStarredTitle = "Starred article"
+)
+
+// syncWithProvider runs a full sync with a stand-in server into a new database
+func syncWithProvider(t *testing.T, provider, serverURL string) (*BidirectionalSyncService, *database.DB) {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s, err := NewProviderSyncService(provider, serverURL, "alice", "secret", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	return s, db
+}
+
+// checkPulled checks the feed and articles pulled from a stand-in server
+func checkPulled(t *testing.T, db *database.DB) {
+	t.Helper()
+	feeds, err := db.GetFeeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feeds) != 1 {
+		t.Fatalf("feeds = %+v, want one", feeds)
+	}
+	feed := feeds[0]
+	if feed.URL != testFeedURL || feed.Title != testFeedTitle || feed.Category != testFeedFolder ||
+		!feed.IsFreshRSSSource || feed.FreshRSSStreamID != "feed/7" {
+		t.Errorf("unexpected feed: %+v", feed)
+	}
+
+	read, err := db.GetArticleByURL(testReadURL)
+	if err != nil {
+		t.Fatalf("read article not pulled: %v", err)
+	}
+	if read.FeedID != feed.ID || read.Title != testReadTitle || !read.IsRead || read.IsFavorite || read.FreshRSSItemID != "101" {
+		t.Errorf("unexpected read article: %+v", read)
+	}
+	starred, err := db.GetArticleByURL(testStarredURL)
+	if err != nil {
+		t.Fatalf("starred article not pulled: %v", err)
+	}
+	if starred.Title != testStarredTitle || starred.IsRead || !starred.IsFavorite || starred.FreshRSSItemID != "102" {
+		t.Errorf("unexpected starred article: %+v", starred)
+	}
+}
+
+// pushStatus pushes a status change of a pulled article
+func pushStatus(t *testing.T, s *BidirectionalSyncService, db *database.DB, articleURL string, action database.SyncAction) {
+	t.Helper()
+	article, err := db.GetArticleByURL(articleURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SyncArticleStatus(context.Background(), article.ID, article.URL, action); err != nil {
+		t.Fatalf("SyncArticleStatus(%s) error: %v", action, err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	for provider, want := range map[string]string{
+		"":                "*freshrss.Client",
+		ProviderFreshRSS:  "*freshrss.Client",
+		ProviderMiniflux:  "*freshrss.MinifluxClient",
+		ProviderNextcloud: "*freshrss.NextcloudClient",
+		ProviderTTRSS:     "*freshrss.TTRSSClient",
+	} {
+		p, err := NewProvider(provider, "https://example.com", "alice", "secret")
+		if err != nil {
+			t.Errorf("%q: %v", provider, err)
+		} else if got := fmt.Sprintf("%T", p); got != want {
+			t.Errorf("%q: provider %s, want %s", provider, got, want)
+		}
+	}
+
+	if _, err := NewProvider("inoreader", "https://example.com", "alice", "secret"); err == nil {
+		t.Error("unknown provider accepted")
+	}
+}
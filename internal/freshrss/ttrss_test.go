@@ -0,0 +1,160 @@
+package freshrss
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"MrRSS/internal/database"
+)
+
+// ttrssStandIn serves the parts of the TT-RSS API used for syncing
+type ttrssStandIn struct {
+	mu       sync.Mutex
+	sessions int
+	unread   map[int64]bool
+	marked   map[int64]bool
+	labels   map[int64][]string
+}
+
+func (s *ttrssStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/tt-rss/api/" {
+		http.NotFound(w, r)
+		return
+	}
+	var req map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&req)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	respond := func(content interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"seq": 0, "status": 0, "content": content})
+	}
+	fail := func(msg string) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"seq": 0, "status": 1, "content": map[string]string{"error": msg}})
+	}
+
+	op, _ := req["op"].(string)
+	if op == "login" {
+		if req["user"] != "alice" || req["password"] != "secret" {
+			fail("LOGIN_ERROR")
+			return
+		}
+		s.sessions++
+		respond(map[string]interface{}{"session_id": "session-" + strconv.Itoa(s.sessions), "api_level": 18})
+		return
+	}
+	if req["sid"] != "session-"+strconv.Itoa(s.sessions) {
+		fail("NOT_LOGGED_IN")
+		return
+	}
+
+	ids := func() []int64 {
+		var ids []int64
+		for _, part := range strings.Split(req["article_ids"].(string), ",") {
+			id, _ := strconv.ParseInt(part, 10, 64)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	switch op {
+	case "getCategories":
+		respond([]map[string]interface{}{{"id": "4", "title": testFeedFolder}, {"id": -1, "title": "Special"}})
+	case "getFeeds":
+		respond([]map[string]interface{}{{"id": 7, "title": testFeedTitle, "feed_url": testFeedURL, "cat_id": 4}})
+	case "getHeadlines":
+		feedID := int64(req["feed_id"].(float64))
+		var headlines []map[string]interface{}
+		for _, id := range []int64{102, 101} {
+			if feedID == ttrssFeedStarred && !s.marked[id] || req["view_mode"] == "unread" && !s.unread[id] {
+				continue
+			}
+			url, title := testReadURL, testReadTitle
+			if id == 102 {
+				url, title = testStarredURL, testStarredTitle
+			}
+			var labels [][]interface{}
+			for _, label := range s.labels[id] {
+				labels = append(labels, []interface{}{-1026, label, "", ""})
+			}
+			headlines = append(headlines, map[string]interface{}{
+				"id": id, "feed_id": "7", "title": title, "link": url, "content": "<p>" + title + "</p>",
+				"updated": 1714557600, "unread": s.unread[id], "marked": s.marked[id], "labels": labels,
+			})
+		}
+		respond(headlines)
+	case "updateArticle":
+		value := req["mode"].(float64) == 1
+		for _, id := range ids() {
+			switch int(req["field"].(float64)) {
+			case ttrssFieldStarred:
+				s.marked[id] = value
+			case ttrssFieldUnread:
+				s.unread[id] = value
+			}
+		}
+		respond(map[string]interface{}{"status": "OK", "updated": 1})
+	case "getLabels":
+		respond([]map[string]interface{}{{"id": -1026, "caption": "Later"}})
+	case "setArticleLabel":
+		for _, id := range ids() {
+			if req["assign"] == true {
+				s.labels[id] = append(s.labels[id], "Later")
+			} else {
+				s.labels[id] = nil
+			}
+		}
+		respond(map[string]interface{}{"status": "OK", "updated": 1})
+	default:
+		fail("UNKNOWN_METHOD")
+	}
+}
+
+func TestTTRSSSync(t *testing.T) {
+	standIn := &ttrssStandIn{
+		unread: map[int64]bool{101: false, 102: true},
+		marked: map[int64]bool{102: true},
+		labels: map[int64][]string{},
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	s, db := syncWithProvider(t, ProviderTTRSS, srv.URL+"/tt-rss")
+	checkPulled(t, db)
+
+	pushStatus(t, s, db, testStarredURL, database.SyncActionMarkRead)
+	pushStatus(t, s, db, testReadURL, database.SyncActionStar)
+	pushStatus(t, s, db, testReadURL, database.LabelSyncAction("Later", true))
+	pushStatus(t, s, db, testReadURL, database.LabelSyncAction("Unknown", true))
+
+	// An expired session is renewed
+	standIn.mu.Lock()
+	standIn.sessions++
+	standIn.mu.Unlock()
+	if err := s.client.UnstarBatch(t.Context(), []string{"102"}); err != nil {
+		t.Fatalf("UnstarBatch with an expired session: %v", err)
+	}
+
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	if standIn.unread[102] || !standIn.marked[101] || standIn.marked[102] {
+		t.Errorf("unread = %v, marked = %v", standIn.unread, standIn.marked)
+	}
+	if got := standIn.labels[101]; len(got) != 1 || got[0] != "Later" {
+		t.Errorf("labels of 101 = %v, want [Later]", got)
+	}
+}
+
+func TestTTRSS_WrongPassword(t *testing.T) {
+	srv := httptest.NewServer(&ttrssStandIn{})
+	defer srv.Close()
+
+	if err := NewTTRSSClient(srv.URL+"/tt-rss/api", "alice", "wrong").Login(t.Context()); err == nil {
+		t.Error("login with a wrong password succeeded")
+	}
+}
@@ -0,0 +1,497 @@
+package greader
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+type category struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type subscription struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Categories []category `json:"categories"`
+	URL        string     `json:"url"`
+	HTMLURL    string     `json:"htmlUrl"`
+	IconURL    string     `json:"iconUrl"`
+}
+
+type tag struct {
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+type unreadCount struct {
+	ID                      string `json:"id"`
+	Count                   int    `json:"count"`
+	NewestItemTimestampUsec int64  `json:"newestItemTimestampUsec"`
+}
+
+type link struct {
+	Href string `json:"href"`
+}
+
+type content struct {
+	Content   string `json:"content"`
+	Direction string `json:"direction"`
+}
+
+type origin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type item struct {
+	ID            string   `json:"id"`
+	CrawlTimeMsec string   `json:"crawlTimeMsec"`
+	TimestampUsec string   `json:"timestampUsec"`
+	Published     int64    `json:"published"`
+	Updated       int64    `json:"updated"`
+	Title         string   `json:"title"`
+	Canonical     []link   `json:"canonical"`
+	Alternate     []link   `json:"alternate"`
+	Summary       content  `json:"summary"`
+	Author        string   `json:"author,omitempty"`
+	Categories    []string `json:"categories"`
+	Origin        origin   `json:"origin"`
+}
+
+type itemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+func feedStreamID(feedID int64) string {
+	return FeedPrefix + strconv.FormatInt(feedID, 10)
+}
+
+// handleSubscriptionList lists all feeds with their category as label
+func (s *account) handleSubscriptionList(w http.ResponseWriter) {
+	feeds, err := s.db.GetFeeds()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	subscriptions := make([]subscription, 0, len(feeds))
+	for _, feed := range feeds {
+		sub := subscription{
+			ID:         feedStreamID(feed.ID),
+			Title:      feed.Title,
+			Categories: []category{},
+			URL:        feed.URL,
+			HTMLURL:    feed.Link,
+			IconURL:    feed.ImageURL,
+		}
+		if feed.Category != "" {
+			sub.Categories = append(sub.Categories, category{ID: LabelPrefix + feed.Category, Label: feed.Category})
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	writeJSON(w, map[string]interface{}{"subscriptions": subscriptions})
+}
+
+// handleTagList lists the starred state, the feed categories (folders) and the user tags
+func (s *account) handleTagList(w http.ResponseWriter) {
+	feeds, err := s.db.GetFeeds()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	userTags, err := s.db.GetTags()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tags := []tag{{ID: StreamStarred}}
+	seen := make(map[string]bool)
+	var folders []string
+	for _, feed := range feeds {
+		if feed.Category != "" && !seen[feed.Category] {
+			seen[feed.Category] = true
+			folders = append(folders, feed.Category)
+		}
+	}
+	sort.Strings(folders)
+	for _, folder := range folders {
+		tags = append(tags, tag{ID: LabelPrefix + folder, Type: "folder"})
+	}
+	for _, t := range userTags {
+		if !seen[t.Name] {
+			tags = append(tags, tag{ID: LabelPrefix + t.Name, Type: "tag"})
+		}
+	}
+	writeJSON(w, map[string]interface{}{"tags": tags})
+}
+
+// handleUnreadCount returns the unread counts of every feed, every category and the reading list
+func (s *account) handleUnreadCount(w http.ResponseWriter) {
+	feeds, err := s.db.GetFeeds()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	stats, err := s.db.GetUnreadStatsForAllFeeds()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var total database.UnreadStat
+	categories := make(map[string]database.UnreadStat)
+	var categoryOrder []string
+	counts := []unreadCount{}
+	for _, feed := range feeds {
+		stat, ok := stats[feed.ID]
+		if !ok {
+			continue
+		}
+		counts = append(counts, unreadCount{ID: feedStreamID(feed.ID), Count: stat.Count, NewestItemTimestampUsec: usec(stat.Newest)})
+		total = addUnread(total, stat)
+		if feed.Category != "" {
+			if _, ok := categories[feed.Category]; !ok {
+				categoryOrder = append(categoryOrder, feed.Category)
+			}
+			categories[feed.Category] = addUnread(categories[feed.Category], stat)
+		}
+	}
+	for _, name := range categoryOrder {
+		stat := categories[name]
+		counts = append(counts, unreadCount{ID: LabelPrefix + name, Count: stat.Count, NewestItemTimestampUsec: usec(stat.Newest)})
+	}
+	counts = append(counts, unreadCount{ID: StreamReadingList, Count: total.Count, NewestItemTimestampUsec: usec(total.Newest)})
+
+	writeJSON(w, map[string]interface{}{"max": total.Count, "unreadcounts": counts})
+}
+
+func addUnread(sum, stat database.UnreadStat) database.UnreadStat {
+	sum.Count += stat.Count
+	if stat.Newest.After(sum.Newest) {
+		sum.Newest = stat.Newest
+	}
+	return sum
+}
+
+func usec(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMicro()
+}
+
+// handleStreamContents returns one page of the articles of a stream
+func (s *account) handleStreamContents(w http.ResponseWriter, r *http.Request, streamID string) {
+	filter, err := s.requestFilter(streamID, r.Form, maxStreamItems)
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+	articles, continuation, err := s.page(filter)
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+
+	feeds, err := s.feedsByID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := map[string]interface{}{
+		"id":      streamID,
+		"updated": time.Now().Unix(),
+		"items":   s.items(articles, feeds),
+	}
+	if continuation != "" {
+		result["continuation"] = continuation
+	}
+	writeJSON(w, result)
+}
+
+// handleStreamItemIDs returns the IDs of one page of the articles of a stream
+func (s *account) handleStreamItemIDs(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.requestFilter(r.Form.Get("s"), r.Form, maxStreamItemIDs)
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+	articles, continuation, err := s.page(filter)
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+
+	refs := make([]itemRef, 0, len(articles))
+	for _, a := range articles {
+		refs = append(refs, itemRef{
+			ID:              strconv.FormatInt(a.ID, 10),
+			DirectStreamIDs: []string{feedStreamID(a.FeedID)},
+			TimestampUsec:   strconv.FormatInt(usec(a.PublishedAt), 10),
+		})
+	}
+	result := map[string]interface{}{"itemRefs": refs}
+	if continuation != "" {
+		result["continuation"] = continuation
+	}
+	writeJSON(w, result)
+}
+
+// handleItemContents returns the articles with the item IDs given in the i parameters
+func (s *account) handleItemContents(w http.ResponseWriter, r *http.Request) {
+	ids, ok := parseItemIDs(w, r.Form["i"])
+	if !ok {
+		return
+	}
+	articles, err := s.db.GetArticlesByIDs(ids)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Keep the order of the request
+	byID := make(map[int64]models.Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+	ordered := make([]models.Article, 0, len(articles))
+	for _, id := range ids {
+		if a, ok := byID[id]; ok {
+			ordered = append(ordered, a)
+		}
+	}
+
+	feeds, err := s.feedsByID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"id":      StreamReadingList,
+		"updated": time.Now().Unix(),
+		"items":   s.items(ordered, feeds),
+	})
+}
+
+// handleEditTag adds (a) and removes (r) the read, starred and label tags of items (i)
+func (s *account) handleEditTag(w http.ResponseWriter, r *http.Request) {
+	ids, ok := parseItemIDs(w, r.Form["i"])
+	if !ok {
+		return
+	}
+
+	for _, add := range r.Form["a"] {
+		if err := s.setTag(ids, normalizeStreamID(add), true); err != nil {
+			writeTagError(w, err)
+			return
+		}
+	}
+	for _, remove := range r.Form["r"] {
+		if err := s.setTag(ids, normalizeStreamID(remove), false); err != nil {
+			writeTagError(w, err)
+			return
+		}
+	}
+	writeOK(w)
+}
+
+// setTag applies or removes one tag on articles. Unsupported states are ignored.
+// Changes go through the *WithSync methods and are queued for FreshRSS, so that
+// articles of FreshRSS feeds stay consistent upstream.
+func (s *account) setTag(ids []int64, tagID string, add bool) error {
+	var requests []database.SyncRequest
+	switch {
+	case tagID == StreamRead || tagID == StreamKeptUnread:
+		read := add == (tagID == StreamRead)
+		for _, id := range ids {
+			req, err := s.db.MarkArticleReadWithSync(id, read)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if req != nil {
+				requests = append(requests, *req)
+			}
+		}
+	case tagID == StreamStarred:
+		for _, id := range ids {
+			req, err := s.db.SetArticleFavoriteWithSync(id, add)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if req != nil {
+				requests = append(requests, *req)
+			}
+		}
+	case strings.HasPrefix(tagID, LabelPrefix):
+		name := strings.TrimPrefix(tagID, LabelPrefix)
+		var id int64
+		if add {
+			var err error
+			if id, err = s.db.GetOrCreateTag(name); err != nil {
+				return err
+			}
+		} else {
+			t, err := s.db.GetTagByName(name)
+			if err != nil || t == nil {
+				return err
+			}
+			id = t.ID
+		}
+		_, tagRequests, err := s.db.TagArticlesWithSync(id, ids, add)
+		if err != nil {
+			return err
+		}
+		requests = append(requests, tagRequests...)
+	}
+	return s.db.EnqueueSyncRequests(requests)
+}
+
+func writeTagError(w http.ResponseWriter, err error) {
+	if err == database.ErrInvalidTagName {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// markAllAsReadBatchSize is the number of articles mark-all-as-read marks at a time
+const markAllAsReadBatchSize = 500
+
+// handleMarkAllAsRead marks the articles of stream s as read, limited to articles
+// published before ts (microseconds) when given
+func (s *account) handleMarkAllAsRead(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.streamFilter(r.Form.Get("s"))
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+	if ts, err := strconv.ParseInt(r.Form.Get("ts"), 10, 64); err == nil && ts > 0 {
+		filter.Before = time.UnixMicro(ts)
+	}
+
+	// Mark the stream in pages of article IDs, so a large stream isn't loaded at once
+	filter.OnlyUnread = true
+	filter.ByID = true
+	filter.OldestFirst = true
+	filter.Limit = markAllAsReadBatchSize
+	marked := 0
+	for {
+		ids, err := s.db.GetArticleStreamIDs(filter)
+		if err == nil && len(ids) > 0 {
+			var requests []database.SyncRequest
+			requests, err = s.db.MarkArticlesReadWithSync(ids, true)
+			if err == nil {
+				err = s.db.EnqueueSyncRequests(requests)
+			}
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		marked += len(ids)
+		if len(ids) < markAllAsReadBatchSize {
+			break
+		}
+		filter.SinceID = ids[len(ids)-1]
+	}
+	log.Printf("[GReader] Marked %d articles as read in %s", marked, r.Form.Get("s"))
+	writeOK(w)
+}
+
+func parseItemIDs(w http.ResponseWriter, values []string) ([]int64, bool) {
+	if len(values) == 0 {
+		http.Error(w, "Missing item IDs", http.StatusBadRequest)
+		return nil, false
+	}
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		id, err := ParseItemID(value)
+		if err != nil {
+			http.Error(w, "Invalid item ID "+strconv.Quote(value), http.StatusBadRequest)
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+func (s *account) feedsByID() (map[int64]models.Feed, error) {
+	feeds, err := s.db.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Feed, len(feeds))
+	for _, feed := range feeds {
+		byID[feed.ID] = feed
+	}
+	return byID, nil
+}
+
+// items converts articles to stream items. The content is the cached article content, if any.
+func (s *account) items(articles []models.Article, feeds map[int64]models.Feed) []item {
+	items := make([]item, 0, len(articles))
+	for _, a := range articles {
+		feed := feeds[a.FeedID]
+		body, _, err := s.db.GetArticleContent(a.ID)
+		if err != nil {
+			log.Printf("[GReader] Error loading content of article %d: %v", a.ID, err)
+		}
+
+		categories := []string{StreamReadingList}
+		if a.IsRead {
+			categories = append(categories, StreamRead)
+		}
+		if a.IsFavorite {
+			categories = append(categories, StreamStarred)
+		}
+		if feed.Category != "" {
+			categories = append(categories, LabelPrefix+feed.Category)
+		}
+		for _, t := range a.Tags {
+			categories = append(categories, LabelPrefix+t)
+		}
+
+		published := a.PublishedAt
+		if published.IsZero() {
+			published = time.Unix(0, 0)
+		}
+		items = append(items, item{
+			ID:            ItemID(a.ID),
+			CrawlTimeMsec: strconv.FormatInt(published.UnixMilli(), 10),
+			TimestampUsec: strconv.FormatInt(published.UnixMicro(), 10),
+			Published:     published.Unix(),
+			Updated:       published.Unix(),
+			Title:         a.Title,
+			Canonical:     []link{{Href: a.URL}},
+			Alternate:     []link{{Href: a.URL}},
+			Summary:       content{Content: body, Direction: "ltr"},
+			Author:        a.Author,
+			Categories:    categories,
+			Origin: origin{
+				StreamID: feedStreamID(a.FeedID),
+				Title:    a.FeedTitle,
+				HTMLURL:  feed.Link,
+			},
+		})
+	}
+	return items
+}
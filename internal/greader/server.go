@@ -0,0 +1,214 @@
+// Package greader implements the server side of the Google Reader API, as spoken by
+// FreshRSS and by mobile clients such as Reeder, FeedMe and NetNewsWire.
+// It serves the subscriptions, articles and read/starred/label state of the MrRSS database,
+// or of the database of each user in the multi-user server.
+package greader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"MrRSS/internal/database"
+)
+
+// PathPrefix is where the API is mounted, matching the endpoint FreshRSS exposes
+// so that clients (including our own FreshRSS client) find it under the same path.
+const PathPrefix = "/api/greader.php"
+
+// Server serves the Google Reader API on top of the MrRSS database of each user.
+type Server struct {
+	databases func() []*database.DB
+}
+
+// account is the API account of one database, configured in its settings
+type account struct {
+	db       *database.DB
+	username string
+	password string
+}
+
+// NewServer creates a Google Reader API server for a single database.
+func NewServer(db *database.DB) *Server {
+	return NewMultiUserServer(func() []*database.DB { return []*database.DB{db} })
+}
+
+// NewMultiUserServer creates a Google Reader API server for the databases of several users.
+// Requests are served from the database whose API username and password they carry.
+func NewMultiUserServer(databases func() []*database.DB) *Server {
+	return &Server{databases: databases}
+}
+
+// ServeHTTP dispatches an API request. The API of a database is only available when it
+// has been enabled and a username and password have been configured.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	accounts := s.accounts()
+	if len(accounts) == 0 {
+		http.Error(w, "Google Reader API is disabled", http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, PathPrefix)
+	if path == "/accounts/ClientLogin" {
+		handleClientLogin(w, r, accounts)
+		return
+	}
+
+	path, ok := strings.CutPrefix(path, "/reader/api/0/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	a := authorizedAccount(r, accounts)
+	if a == nil {
+		w.Header().Set("Google-Bad-Token", "true")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	a.serve(w, r, path)
+}
+
+// serve handles an authorized API request
+func (s *account) serve(w http.ResponseWriter, r *http.Request, path string) {
+	switch {
+	case path == "token":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, writeToken(s.username, s.password))
+	case path == "user-info":
+		writeJSON(w, map[string]string{
+			"userId":        s.username,
+			"userName":      s.username,
+			"userProfileId": s.username,
+			"userEmail":     "",
+		})
+	case path == "subscription/list":
+		s.handleSubscriptionList(w)
+	case path == "tag/list":
+		s.handleTagList(w)
+	case path == "unread-count":
+		s.handleUnreadCount(w)
+	case strings.HasPrefix(path, "stream/contents"):
+		streamID := strings.TrimPrefix(strings.TrimPrefix(path, "stream/contents"), "/")
+		if streamID == "" {
+			streamID = r.Form.Get("s")
+		}
+		s.handleStreamContents(w, r, streamID)
+	case path == "stream/items/ids":
+		s.handleStreamItemIDs(w, r)
+	case path == "stream/items/contents":
+		s.handleItemContents(w, r)
+	case path == "edit-tag":
+		s.modify(w, r, s.handleEditTag)
+	case path == "mark-all-as-read":
+		s.modify(w, r, s.handleMarkAllAsRead)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// accounts returns the databases with an enabled API and their credentials
+func (s *Server) accounts() []*account {
+	var accounts []*account
+	for _, db := range s.databases() {
+		enabled, _ := db.GetSetting("greader_enabled")
+		if enabled != "true" {
+			continue
+		}
+		username, _ := db.GetSetting("greader_username")
+		password, err := db.GetEncryptedSetting("greader_password")
+		if err != nil {
+			log.Printf("[GReader] Error reading API password: %v", err)
+			continue
+		}
+		if username != "" && password != "" {
+			accounts = append(accounts, &account{db: db, username: username, password: password})
+		}
+	}
+	return accounts
+}
+
+// handleClientLogin exchanges the username (Email) and password (Passwd) for an auth token
+func handleClientLogin(w http.ResponseWriter, r *http.Request, accounts []*account) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	for _, a := range accounts {
+		userOK := subtle.ConstantTimeCompare([]byte(r.Form.Get("Email")), []byte(a.username)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(r.Form.Get("Passwd")), []byte(a.password)) == 1
+		if userOK && passOK {
+			token := authToken(a.username, a.password)
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprintf(w, "SID=%s\nLSID=null\nAuth=%s\n", token, token)
+			return
+		}
+	}
+	http.Error(w, "Error=BadAuthentication", http.StatusUnauthorized)
+}
+
+// authorizedAccount returns the account whose "GoogleLogin auth=" token a request carries
+func authorizedAccount(r *http.Request, accounts []*account) *account {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "GoogleLogin auth=")
+	if !ok {
+		return nil
+	}
+	token = strings.TrimSpace(token)
+	for _, a := range accounts {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(authToken(a.username, a.password))) == 1 {
+			return a
+		}
+	}
+	return nil
+}
+
+// modify runs a modifying request, which must be a POST carrying the write token returned by /token
+func (s *account) modify(w http.ResponseWriter, r *http.Request, handle func(http.ResponseWriter, *http.Request)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Form.Get("T")), []byte(writeToken(s.username, s.password))) != 1 {
+		w.Header().Set("X-Reader-Google-Bad-Token", "true")
+		http.Error(w, "Invalid write token", http.StatusUnauthorized)
+		return
+	}
+	handle(w, r)
+}
+
+// authToken derives the ClientLogin token from the credentials, so that tokens need no
+// storage and are revoked by changing the password.
+func authToken(username, password string) string {
+	return username + "/" + sign(password, "auth:"+username)
+}
+
+// writeToken derives the token required by modifying requests
+func writeToken(username, password string) string {
+	return sign(password, "write:"+username)
+}
+
+func sign(key, message string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
@@ -0,0 +1,417 @@
+package greader_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/freshrss"
+	"MrRSS/internal/greader"
+	"MrRSS/internal/models"
+)
+
+const (
+	testUser     = "alice"
+	testPassword = "s3cret"
+)
+
+// setupServer starts the API on an in-memory database with one feed of three articles,
+// the newest one first in the returned IDs.
+func setupServer(t *testing.T) (*database.DB, *httptest.Server, []int64) {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	db.SetSetting("greader_enabled", "true")
+	db.SetSetting("greader_username", testUser)
+	if err := db.SetEncryptedSetting("greader_password", testPassword); err != nil {
+		t.Fatalf("SetEncryptedSetting error: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Blog", URL: "https://example.com/feed.xml", Link: "https://example.com", Category: "Tech"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, title := range []string{"First", "Second", "Third"} {
+		if err := db.SaveArticle(&models.Article{
+			FeedID:                feedID,
+			Title:                 title,
+			URL:                   "https://example.com/" + strings.ToLower(title),
+			PublishedAt:           base.Add(time.Duration(i) * time.Hour),
+			HasValidPublishedTime: true,
+		}); err != nil {
+			t.Fatalf("SaveArticle error: %v", err)
+		}
+	}
+	articles, err := db.GetArticleStream(database.ArticleStreamFilter{})
+	if err != nil || len(articles) != 3 {
+		t.Fatalf("GetArticleStream = %d articles, %v", len(articles), err)
+	}
+	ids := []int64{articles[0].ID, articles[1].ID, articles[2].ID}
+	if err := db.SetArticleContent(ids[0], "<p>Third body</p>"); err != nil {
+		t.Fatalf("SetArticleContent error: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(greader.PathPrefix+"/", greader.NewServer(db))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return db, ts, ids
+}
+
+// login returns the auth token of the test user
+func login(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+	resp, err := http.PostForm(ts.URL+greader.PathPrefix+"/accounts/ClientLogin", url.Values{"Email": {testUser}, "Passwd": {testPassword}})
+	if err != nil {
+		t.Fatalf("ClientLogin error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, line := range strings.Split(string(body), "\n") {
+		if token, ok := strings.CutPrefix(line, "Auth="); ok {
+			return token
+		}
+	}
+	t.Fatalf("no Auth token in %q", body)
+	return ""
+}
+
+func get(t *testing.T, ts *httptest.Server, token, path string, v interface{}) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+greader.PathPrefix+path, nil)
+	req.Header.Set("Authorization", "GoogleLogin auth="+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s error: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s status = %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s decode error: %v", path, err)
+	}
+}
+
+func TestServer_Disabled(t *testing.T) {
+	db, ts, _ := setupServer(t)
+	db.SetSetting("greader_enabled", "false")
+
+	resp, err := http.PostForm(ts.URL+greader.PathPrefix+"/accounts/ClientLogin", url.Values{"Email": {testUser}, "Passwd": {testPassword}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+}
+
+func TestServer_Authentication(t *testing.T) {
+	_, ts, _ := setupServer(t)
+
+	resp, err := http.PostForm(ts.URL+greader.PathPrefix+"/accounts/ClientLogin", url.Values{"Email": {testUser}, "Passwd": {"wrong"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("bad password status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	resp, err = http.Get(ts.URL + greader.PathPrefix + "/reader/api/0/subscription/list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("missing token status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	// Modifying requests need the write token
+	token := login(t, ts)
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+greader.PathPrefix+"/reader/api/0/mark-all-as-read",
+		strings.NewReader(url.Values{"s": {greader.StreamReadingList}, "T": {"bogus"}}.Encode()))
+	req.Header.Set("Authorization", "GoogleLogin auth="+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("X-Reader-Google-Bad-Token") != "true" {
+		t.Errorf("bad write token status = %d, header %q", resp.StatusCode, resp.Header.Get("X-Reader-Google-Bad-Token"))
+	}
+}
+
+// TestServer_FreshRSSClient syncs against the server with our own FreshRSS client
+func TestServer_FreshRSSClient(t *testing.T) {
+	db, ts, ids := setupServer(t)
+	ctx := context.Background()
+
+	client := freshrss.NewClient(ts.URL, testUser, testPassword)
+	if err := client.Login(ctx); err != nil {
+		t.Fatalf("Login error: %v", err)
+	}
+
+	subs, err := client.GetSubscriptions(ctx)
+	if err != nil {
+		t.Fatalf("GetSubscriptions error: %v", err)
+	}
+	if len(subs) != 1 || subs[0].URL != "https://example.com/feed.xml" ||
+		len(subs[0].Categories) != 1 || subs[0].Categories[0].Label != "Tech" {
+		t.Fatalf("subscriptions = %+v", subs)
+	}
+
+	categories, err := client.GetCategories(ctx)
+	if err != nil || len(categories) != 1 || categories[0].Label != "Tech" {
+		t.Fatalf("GetCategories = %+v, %v", categories, err)
+	}
+
+	counts, err := client.GetUnreadCount(ctx)
+	if err != nil {
+		t.Fatalf("GetUnreadCount error: %v", err)
+	}
+	if counts[subs[0].ID] != 3 || counts[greader.LabelPrefix+"Tech"] != 3 || counts[greader.StreamReadingList] != 3 {
+		t.Errorf("unread counts = %v", counts)
+	}
+
+	result, err := client.GetStreamContents(ctx, greader.StreamReadingList, nil, 2, "")
+	if err != nil {
+		t.Fatalf("GetStreamContents error: %v", err)
+	}
+	if len(result.Items) != 2 || result.Continuation == "" {
+		t.Fatalf("first page = %d items, continuation %q", len(result.Items), result.Continuation)
+	}
+	newest := result.Items[0]
+	if newest.ID != greader.ItemID(ids[0]) || newest.Title != "Third" || newest.Content != "<p>Third body</p>" ||
+		newest.URL != "https://example.com/third" || newest.OriginStreamID != subs[0].ID {
+		t.Errorf("newest item = %+v", newest)
+	}
+
+	next, err := client.GetStreamContents(ctx, greader.StreamReadingList, nil, 2, result.Continuation)
+	if err != nil || len(next.Items) != 1 || next.Items[0].Title != "First" || next.Continuation != "" {
+		t.Fatalf("second page = %+v, %v", next, err)
+	}
+
+	// Write back read, starred and label state with the long form item IDs
+	if err := client.MarkAsRead(ctx, []string{newest.ID}); err != nil {
+		t.Fatalf("MarkAsRead error: %v", err)
+	}
+	if err := client.StarBatch(ctx, []string{newest.ID}); err != nil {
+		t.Fatalf("StarBatch error: %v", err)
+	}
+	if err := client.AddLabelBatch(ctx, []string{newest.ID}, "Later"); err != nil {
+		t.Fatalf("AddLabelBatch error: %v", err)
+	}
+
+	article, err := db.GetArticleByID(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !article.IsRead || !article.IsFavorite || len(article.Tags) != 1 || article.Tags[0] != "Later" {
+		t.Errorf("article after edit-tag = read %v, favorite %v, tags %v", article.IsRead, article.IsFavorite, article.Tags)
+	}
+
+	unread, err := client.GetStreamContents(ctx, greader.StreamReadingList, []string{freshrss.TagRead}, 10, "")
+	if err != nil || len(unread.Items) != 2 {
+		t.Fatalf("unread stream = %+v, %v", unread, err)
+	}
+	starred, err := client.GetStarredArticles(ctx, 10)
+	if err != nil || len(starred) != 1 || starred[0].ID != newest.ID {
+		t.Fatalf("starred = %+v, %v", starred, err)
+	}
+	labeled, err := client.GetStreamContents(ctx, greader.LabelPrefix+"Later", nil, 10, "")
+	if err != nil || len(labeled.Items) != 1 {
+		t.Fatalf("label stream = %+v, %v", labeled, err)
+	}
+	if !containsString(labeled.Items[0].Categories, freshrss.TagStarred) || !containsString(labeled.Items[0].Categories, greader.LabelPrefix+"Later") {
+		t.Errorf("item categories = %v", labeled.Items[0].Categories)
+	}
+
+	if err := client.RemoveLabelBatch(ctx, []string{newest.ID}, "Later"); err != nil {
+		t.Fatalf("RemoveLabelBatch error: %v", err)
+	}
+	if tags, _ := db.GetArticleTags(ids[0]); len(tags) != 0 {
+		t.Errorf("tags after removal = %v", tags)
+	}
+}
+
+// TestServer_QueuesFreshRSSChanges checks that changes to articles of FreshRSS feeds are queued for upstream
+func TestServer_QueuesFreshRSSChanges(t *testing.T) {
+	db, ts, _ := setupServer(t)
+	db.SetSetting("freshrss_enabled", "true")
+	ctx := context.Background()
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Upstream", URL: "https://upstream.example.com/feed.xml", IsFreshRSSSource: true})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	if err := db.SaveArticle(&models.Article{FeedID: feedID, Title: "Synced", URL: "https://upstream.example.com/synced", PublishedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveArticle error: %v", err)
+	}
+	articles, err := db.GetArticleStream(database.ArticleStreamFilter{FeedID: feedID})
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("GetArticleStream = %d articles, %v", len(articles), err)
+	}
+
+	client := freshrss.NewClient(ts.URL, testUser, testPassword)
+	if err := client.Login(ctx); err != nil {
+		t.Fatalf("Login error: %v", err)
+	}
+	if err := client.StarBatch(ctx, []string{greader.ItemID(articles[0].ID)}); err != nil {
+		t.Fatalf("StarBatch error: %v", err)
+	}
+
+	pending, err := db.GetPendingSyncChanges(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ArticleID != articles[0].ID || pending[0].Action != database.SyncActionStar {
+		t.Errorf("pending sync changes = %+v", pending)
+	}
+}
+
+func TestServer_ItemIDsAndContents(t *testing.T) {
+	_, ts, ids := setupServer(t)
+	token := login(t, ts)
+
+	var refs struct {
+		ItemRefs []struct {
+			ID string `json:"id"`
+		} `json:"itemRefs"`
+		Continuation string `json:"continuation"`
+	}
+	get(t, ts, token, "/reader/api/0/stream/items/ids?output=json&n=10&r=o&s="+url.QueryEscape(greader.StreamReadingList), &refs)
+	if len(refs.ItemRefs) != 3 || refs.Continuation != "" {
+		t.Fatalf("itemRefs = %+v", refs)
+	}
+	if first, _ := greader.ParseItemID(refs.ItemRefs[0].ID); first != ids[2] {
+		t.Errorf("oldest first: first ref = %s, want %d", refs.ItemRefs[0].ID, ids[2])
+	}
+
+	// Clients mix short (decimal) and long form IDs
+	form := url.Values{"i": {refs.ItemRefs[0].ID, greader.ItemID(ids[0])}}
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+greader.PathPrefix+"/reader/api/0/stream/items/contents?output=json", strings.NewReader(form.Encode()))
+	req.Header.Set("Authorization", "GoogleLogin auth="+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var contents struct {
+		Items []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&contents); err != nil {
+		t.Fatal(err)
+	}
+	if len(contents.Items) != 2 || contents.Items[0].Title != "First" || contents.Items[1].ID != greader.ItemID(ids[0]) {
+		t.Errorf("items = %+v", contents.Items)
+	}
+}
+
+func TestServer_MarkAllAsRead(t *testing.T) {
+	db, ts, ids := setupServer(t)
+	token := login(t, ts)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+greader.PathPrefix+"/reader/api/0/token", nil)
+	req.Header.Set("Authorization", "GoogleLogin auth="+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeToken, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// Only articles published before ts are marked: the newest stays unread
+	newest, _ := db.GetArticleByID(ids[0])
+	form := url.Values{
+		"s":  {greader.LabelPrefix + "Tech"},
+		"ts": {strconv.FormatInt(newest.PublishedAt.Add(-time.Minute).UnixMicro(), 10)},
+		"T":  {string(writeToken)},
+	}
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+greader.PathPrefix+"/reader/api/0/mark-all-as-read", strings.NewReader(form.Encode()))
+	req.Header.Set("Authorization", "GoogleLogin auth="+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	for i, id := range ids {
+		a, _ := db.GetArticleByID(id)
+		if want := i > 0; a.IsRead != want {
+			t.Errorf("article %d read = %v, want %v", i, a.IsRead, want)
+		}
+	}
+
+	// Streams larger than a page are marked completely
+	article, _ := db.GetArticleByID(ids[0])
+	more := make([]*models.Article, 1200)
+	for i := range more {
+		more[i] = &models.Article{FeedID: article.FeedID, Title: "More " + strconv.Itoa(i), URL: "https://example.com/more/" + strconv.Itoa(i)}
+	}
+	if _, err := db.SaveArticlesCounted(context.Background(), more); err != nil {
+		t.Fatalf("SaveArticlesCounted error: %v", err)
+	}
+	form = url.Values{"s": {greader.StreamReadingList}, "T": {string(writeToken)}}
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+greader.PathPrefix+"/reader/api/0/mark-all-as-read", strings.NewReader(form.Encode()))
+	req.Header.Set("Authorization", "GoogleLogin auth="+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if unread, _ := db.GetArticleStreamIDs(database.ArticleStreamFilter{OnlyUnread: true}); resp.StatusCode != http.StatusOK || len(unread) != 0 {
+		t.Errorf("status = %d, %d articles left unread", resp.StatusCode, len(unread))
+	}
+}
+
+func TestParseItemID(t *testing.T) {
+	for _, id := range []string{greader.ItemID(42), "42"} {
+		if got, err := greader.ParseItemID(id); err != nil || got != 42 {
+			t.Errorf("ParseItemID(%q) = %d, %v", id, got, err)
+		}
+	}
+	if greader.ItemID(255) != "tag:google.com,2005:reader/item/00000000000000ff" {
+		t.Errorf("ItemID(255) = %s", greader.ItemID(255))
+	}
+	if _, err := greader.ParseItemID("tag:google.com,2005:reader/item/xyz"); err == nil {
+		t.Error("expected error for invalid hex ID")
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package greader
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+)
+
+// Stream and state IDs, in the forms the FreshRSS client uses (see internal/freshrss)
+const (
+	StreamReadingList = "user/-/state/com.google/reading-list"
+	StreamRead        = "user/-/state/com.google/read"
+	StreamStarred     = "user/-/state/com.google/starred"
+	StreamKeptUnread  = "user/-/state/com.google/kept-unread"
+	LabelPrefix       = "user/-/label/"
+	FeedPrefix        = "feed/"
+
+	// ItemIDPrefix is the prefix of long form item IDs, followed by the ID in 16 hex digits
+	ItemIDPrefix = "tag:google.com,2005:reader/item/"
+)
+
+// Page sizes of stream requests
+const (
+	defaultStreamItems = 20
+	maxStreamItems     = 1000
+	maxStreamItemIDs   = 10000
+)
+
+var errUnknownStream = errors.New("unknown stream")
+
+// ItemID returns the long form item ID of an article
+func ItemID(articleID int64) string {
+	return fmt.Sprintf("%s%016x", ItemIDPrefix, articleID)
+}
+
+// ParseItemID parses a long form (hex) or short form (decimal) item ID
+func ParseItemID(id string) (int64, error) {
+	if hexID, ok := strings.CutPrefix(id, ItemIDPrefix); ok {
+		v, err := strconv.ParseUint(hexID, 16, 64)
+		return int64(v), err
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+// normalizeStreamID rewrites "user/<id>/..." to the "user/-/..." form
+func normalizeStreamID(streamID string) string {
+	rest, ok := strings.CutPrefix(streamID, "user/")
+	if !ok {
+		return streamID
+	}
+	if i := strings.Index(rest, "/"); i > 0 {
+		return "user/-" + rest[i:]
+	}
+	return streamID
+}
+
+// streamFilter maps a stream ID to an article filter
+func (s *account) streamFilter(streamID string) (database.ArticleStreamFilter, error) {
+	var filter database.ArticleStreamFilter
+	streamID = normalizeStreamID(streamID)
+
+	switch {
+	case streamID == StreamReadingList:
+		return filter, nil
+	case streamID == StreamRead:
+		filter.OnlyRead = true
+		return filter, nil
+	case streamID == StreamStarred:
+		filter.OnlyStarred = true
+		return filter, nil
+	case strings.HasPrefix(streamID, LabelPrefix):
+		label := strings.TrimPrefix(streamID, LabelPrefix)
+		feeds, err := s.db.GetFeeds()
+		if err != nil {
+			return filter, err
+		}
+		for _, feed := range feeds {
+			if feed.Category == label || strings.HasPrefix(feed.Category, label+"/") {
+				filter.Category = label
+				return filter, nil
+			}
+		}
+		tag, err := s.db.GetTagByName(label)
+		if err != nil {
+			return filter, err
+		}
+		if tag == nil {
+			return filter, errUnknownStream
+		}
+		filter.Tag = tag.Name
+		return filter, nil
+	case strings.HasPrefix(streamID, FeedPrefix):
+		feed, err := s.findFeed(strings.TrimPrefix(streamID, FeedPrefix))
+		if err != nil {
+			return filter, err
+		}
+		filter.FeedID = feed.ID
+		return filter, nil
+	}
+	return filter, errUnknownStream
+}
+
+// findFeed looks a feed up by ID, or by URL for clients that use "feed/<url>" stream IDs
+func (s *account) findFeed(ref string) (*models.Feed, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		feed, err := s.db.GetFeedByID(id)
+		if err != nil {
+			return nil, errUnknownStream
+		}
+		return feed, nil
+	}
+
+	feeds, err := s.db.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+	for i := range feeds {
+		if feeds[i].URL == ref {
+			return &feeds[i], nil
+		}
+	}
+	return nil, errUnknownStream
+}
+
+// requestFilter builds the article filter of a stream request from its parameters:
+// xt/it (exclude/include state), ot/nt (time bounds in seconds), r=o (oldest first),
+// n (page size) and c (continuation).
+func (s *account) requestFilter(streamID string, form url.Values, maxItems int) (database.ArticleStreamFilter, error) {
+	filter, err := s.streamFilter(streamID)
+	if err != nil {
+		return filter, err
+	}
+
+	for _, exclude := range form["xt"] {
+		switch normalizeStreamID(exclude) {
+		case StreamRead:
+			filter.OnlyUnread = true
+		case StreamStarred:
+			filter.ExcludeStarred = true
+		}
+	}
+	for _, include := range form["it"] {
+		switch normalizeStreamID(include) {
+		case StreamRead:
+			filter.OnlyRead = true
+		case StreamStarred:
+			filter.OnlyStarred = true
+		}
+	}
+	if ot, err := strconv.ParseInt(form.Get("ot"), 10, 64); err == nil && ot > 0 {
+		filter.After = time.Unix(ot, 0)
+	}
+	if nt, err := strconv.ParseInt(form.Get("nt"), 10, 64); err == nil && nt > 0 {
+		filter.Before = time.Unix(nt, 0)
+	}
+	filter.OldestFirst = form.Get("r") == "o"
+
+	filter.Limit = defaultStreamItems
+	if n, err := strconv.Atoi(form.Get("n")); err == nil && n > 0 {
+		filter.Limit = n
+	}
+	if filter.Limit > maxItems {
+		filter.Limit = maxItems
+	}
+	if c, err := strconv.Atoi(form.Get("c")); err == nil && c > 0 {
+		filter.Offset = c
+	}
+	return filter, nil
+}
+
+// page loads one page of a stream and returns the continuation of the next page, if any
+func (s *account) page(filter database.ArticleStreamFilter) ([]models.Article, string, error) {
+	limit := filter.Limit
+	filter.Limit++
+	articles, err := s.db.GetArticleStream(filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(articles) <= limit {
+		return articles, "", nil
+	}
+	return articles[:limit], strconv.Itoa(filter.Offset + limit), nil
+}
+
+func writeStreamError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errUnknownStream) {
+		http.Error(w, "Unknown stream", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
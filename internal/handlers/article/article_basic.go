@@ -18,8 +18,10 @@ import (
 // @Param        filter    query     string  false  "Filter: 'all', 'unread', 'favorite', 'read_later'"  Enums(all, unread, favorite, read_later)
 // @Param        feed_id   query     int64   false  "Filter by feed ID"
 // @Param        category  query     string  false  "Filter by category name"
+// @Param        tag       query     string  false  "Filter by user tag name"
 // @Param        page      query     int     false  "Page number (default: 1)"  minimum(1)
 // @Param        limit     query     int     false  "Items per page (default: 50, max: 500)"  minimum(1)  maximum(500)
+// @Param        collapse  query     bool    false  "List duplicate stories from several feeds once (default: collapse_duplicate_articles setting)"
 // @Success      200  {array}   models.Article  "List of articles"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /articles [get]
@@ -30,6 +32,11 @@ func HandleArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	pageStr := r.URL.Query().Get("page")
 	limitStr := r.URL.Query().Get("limit")
 
+	// Tag view is expressed as a "tag:<name>" filter
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		filter = "tag:" + tag
+	}
+
 	var feedID int64
 	if feedIDStr != "" {
 		feedID, _ = strconv.ParseInt(feedIDStr, 10, 64)
@@ -51,7 +58,16 @@ func HandleArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	showHiddenStr, _ := h.DB.GetSetting("show_hidden_articles")
 	showHidden := showHiddenStr == "true"
 
-	articles, err := h.DB.GetArticles(filter, feedID, category, showHidden, limit, offset)
+	collapse, _ := h.DB.GetSetting("collapse_duplicate_articles")
+	if v := r.URL.Query().Get("collapse"); v != "" {
+		collapse = v
+	}
+
+	getArticles := h.DB.GetArticles
+	if collapse == "true" || collapse == "1" {
+		getArticles = h.DB.GetArticlesCollapsed
+	}
+	articles, err := getArticles(filter, feedID, category, showHidden, limit, offset)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -172,3 +188,21 @@ func HandleImageGalleryArticles(h *core.Handler, w http.ResponseWriter, r *http.
 	}
 	json.NewEncoder(w).Encode(articles)
 }
+
+// HandleArticleCategories returns the distinct item categories reported by feeds.
+// @Summary      Get article item categories
+// @Description  Get the distinct per-item categories (tags) reported by feeds, e.g. for the article_tag rule condition
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   string  "Category names"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/categories [get]
+func HandleArticleCategories(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	categories, err := h.DB.GetAllArticleCategories()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(categories)
+}
@@ -7,7 +7,9 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"MrRSS/internal/database"
 	"MrRSS/internal/handlers/core"
 )
 
@@ -232,3 +234,81 @@ func HandleGetArticleContentCacheInfo(h *core.Handler, w http.ResponseWriter, r
 		"cached_articles": count,
 	})
 }
+
+// BulkTagRequest represents a request to tag or untag multiple articles
+type BulkTagRequest struct {
+	ArticleIDs []int64 `json:"article_ids"`
+	Tag        string  `json:"tag"`
+	Remove     bool    `json:"remove"`
+}
+
+// HandleBulkTagArticles adds or removes a user tag on multiple articles.
+// @Summary      Bulk tag articles
+// @Description  Add a user tag to (or remove it from) multiple articles. The tag is created if it doesn't exist. When FreshRSS sync is enabled, the tag is mirrored as a label.
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        request  body      BulkTagRequest  true  "Article IDs, tag name and whether to remove it"
+// @Success      200  {object}  map[string]interface{}  "Result (success, tag ID, changed count)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/tags [post]
+func HandleBulkTagArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BulkTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.ArticleIDs) == 0 || strings.TrimSpace(req.Tag) == "" {
+		http.Error(w, "Missing article_ids or tag", http.StatusBadRequest)
+		return
+	}
+
+	var tagID int64
+	var err error
+	if req.Remove {
+		tag, getErr := h.DB.GetTagByName(req.Tag)
+		if getErr != nil {
+			http.Error(w, getErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		if tag == nil {
+			http.Error(w, "Tag not found", http.StatusNotFound)
+			return
+		}
+		tagID = tag.ID
+	} else {
+		tagID, err = h.DB.GetOrCreateTag(req.Tag)
+		if err == database.ErrInvalidTagName {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	changed, syncReqs, err := h.DB.TagArticlesWithSync(tagID, req.ArticleIDs, !req.Remove)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Immediately sync labels to FreshRSS if needed
+	for i := range syncReqs {
+		go performImmediateSync(h, &syncReqs[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"tag_id":  tagID,
+		"changed": changed,
+	})
+}
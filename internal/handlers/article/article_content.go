@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
 )
 
 // HandleGetArticleContent fetches the article content from RSS feed dynamically.
@@ -16,7 +17,7 @@ import (
 // @Accept       json
 // @Produce      json
 // @Param        id   query     int64   true  "Article ID"
-// @Success      200  {object}  map[string]string  "Article content (content, feed_url)"
+// @Success      200  {object}  map[string]interface{}  "Article content (content, feed_url, cached, full_text, full_text_mode)"
 // @Failure      400  {object}  map[string]string  "Bad request (invalid article ID)"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /articles/content [get]
@@ -59,16 +60,21 @@ func HandleGetArticleContent(h *core.Handler, w http.ResponseWriter, r *http.Req
 		feedURL = feed.URL
 	}
 
+	// Full text stored by eager fetching or an earlier fetch doesn't need fetching again
+	hasFullText, _ := h.DB.HasArticleFullText(articleID)
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"content":  content,
-		"feed_url": feedURL,
-		"cached":   wasCached,
+		"content":        content,
+		"feed_url":       feedURL,
+		"cached":         wasCached,
+		"full_text":      hasFullText,
+		"full_text_mode": h.DB.GetFeedFullTextMode(article.FeedID),
 	})
 }
 
 // HandleFetchFullArticle fetches the full article content from the original URL using readability.
 // @Summary      Fetch full article content
-// @Description  Fetch the full article content from the original URL using readability extraction (requires full_text_fetch_enabled setting)
+// @Description  Fetch the full article content from the original URL using the feed's selectors or readability extraction (requires full_text_fetch_enabled setting, or the feed's full-text mode "on_open" or "eager")
 // @Tags         articles
 // @Accept       json
 // @Produce      json
@@ -104,22 +110,37 @@ func HandleFetchFullArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Check if full-text fetching is enabled (global setting only)
-	// auto_expand_content only affects auto-expansion behavior, not manual button clicks
-	fullTextEnabledStr, _ := h.DB.GetSetting("full_text_fetch_enabled")
-	if fullTextEnabledStr != "true" {
-		http.Error(w, "Full-text fetching is disabled", http.StatusForbidden)
+	// Check if full-text fetching is enabled: the feed's full-text mode overrides the
+	// global setting. auto_expand_content only affects auto-expansion behavior, not manual
+	// button clicks
+	mode := h.DB.GetFeedFullTextMode(article.FeedID)
+	switch mode {
+	case models.FullTextModeNever:
+		http.Error(w, "Full-text fetching is disabled for this feed", http.StatusForbidden)
 		return
+	case models.FullTextModeDefault:
+		fullTextEnabledStr, _ := h.DB.GetSetting("full_text_fetch_enabled")
+		if fullTextEnabledStr != "true" {
+			http.Error(w, "Full-text fetching is disabled", http.StatusForbidden)
+			return
+		}
 	}
 
 	// Fetch full content
-	fullContent, err := h.FetchFullArticleContent(article.URL)
+	fullContent, err := h.FetchFullArticleContent(article.URL, article.FeedID)
 	if err != nil {
 		log.Printf("Error fetching full article content: %v", err)
 		http.Error(w, "Failed to fetch full article content", http.StatusInternalServerError)
 		return
 	}
 
+	// Feeds that opted into full text keep it, so the page isn't fetched on every open
+	if mode != models.FullTextModeDefault && fullContent != "" {
+		if err := h.DB.SetArticleFullText(articleID, fullContent); err != nil {
+			log.Printf("Error storing full text for article %d: %v", articleID, err)
+		}
+	}
+
 	// Get feed URL to use as referer for image proxying
 	feed, err := h.DB.GetFeedByID(article.FeedID)
 	var feedURL string
@@ -8,43 +8,11 @@ import (
 	"sort"
 	"time"
 
+	"MrRSS/internal/filter"
 	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/models"
-	"MrRSS/internal/rsshub"
 )
 
-// GetFeedType returns the type code of a feed
-// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email"
-func GetFeedType(feed *models.Feed) string {
-	// Check FreshRSS
-	if feed.IsFreshRSSSource {
-		return "freshrss"
-	}
-
-	// Check RSSHub
-	if rsshub.IsRSSHubURL(feed.URL) {
-		return "rsshub"
-	}
-
-	// Check custom script
-	if feed.ScriptPath != "" {
-		return "script"
-	}
-
-	// Check email
-	if feed.Type == "email" {
-		return "email"
-	}
-
-	// Check XPath
-	if feed.Type == "HTML+XPath" || feed.Type == "XML+XPath" {
-		return "xpath"
-	}
-
-	// Default: regular RSS/Atom feed
-	return "regular"
-}
-
 // HandleProgress returns the current fetch progress with statistics.
 // @Summary      Get fetch progress
 // @Description  Get the current feed fetching progress with statistics
@@ -198,7 +166,7 @@ func HandleFilteredArticles(h *core.Handler, w http.ResponseWriter, r *http.Requ
 
 	for _, feed := range feeds {
 		feedCategories[feed.ID] = feed.Category
-		feedTypes[feed.ID] = GetFeedType(&feed)
+		feedTypes[feed.ID] = filter.FeedType(&feed)
 		feedIsImageMode[feed.ID] = feed.IsImageMode
 	}
 
@@ -206,7 +174,7 @@ func HandleFilteredArticles(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	if len(req.Conditions) > 0 {
 		var filteredArticles []models.Article
 		for _, article := range articles {
-			if evaluateArticleConditions(article, req.Conditions, feedCategories, feedTypes, feedIsImageMode) {
+			if filter.Matches(article, req.Conditions, feedCategories, feedTypes, feedIsImageMode) {
 				filteredArticles = append(filteredArticles, article)
 			}
 		}
@@ -198,3 +198,199 @@ func TestHandleExportToObsidian(t *testing.T) {
 		t.Fatalf("Export not successful: %v", response)
 	}
 }
+
+func TestHandleFetchFullArticle_FullTextModes(t *testing.T) {
+	h := setupHandler(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><nav>Menu</nav><div class="story"><p>The full story.</p></div></body></html>`)
+	}))
+	defer srv.Close()
+
+	feedID, err := h.DB.AddFeed(&models.Feed{Title: "F", URL: srv.URL + "/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	if err := h.DB.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "teaser", URL: srv.URL + "/posts/1", PublishedAt: time.Now()},
+	}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	articles, _ := h.DB.GetArticles("", feedID, "", false, 1, 0)
+	articleID := articles[0].ID
+
+	fetchFull := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/articles/fetch-full?id=%d", articleID), nil)
+		w := httptest.NewRecorder()
+		article.HandleFetchFullArticle(h, w, req)
+		return w
+	}
+	setMode := func(mode string) {
+		t.Helper()
+		if err := h.DB.SetFeedFullTextSettings(&models.FeedFullTextSettings{FeedID: feedID, Mode: mode, ContentSelector: ".story"}); err != nil {
+			t.Fatalf("SetFeedFullTextSettings: %v", err)
+		}
+	}
+
+	// The default mode follows the global setting
+	h.DB.SetSetting("full_text_fetch_enabled", "false")
+	setMode(models.FullTextModeDefault)
+	if w := fetchFull(); w.Code != http.StatusForbidden {
+		t.Errorf("default mode: expected 403, got %d", w.Code)
+	}
+
+	// "never" wins over the global setting
+	h.DB.SetSetting("full_text_fetch_enabled", "true")
+	setMode(models.FullTextModeNever)
+	if w := fetchFull(); w.Code != http.StatusForbidden {
+		t.Errorf("never mode: expected 403, got %d", w.Code)
+	}
+
+	// "on_open" fetches with the feed's selector even with the global setting off, and keeps the result
+	h.DB.SetSetting("full_text_fetch_enabled", "false")
+	setMode(models.FullTextModeOnOpen)
+	w := fetchFull()
+	if w.Code != http.StatusOK {
+		t.Fatalf("on_open mode: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]string
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !strings.Contains(resp["content"], "The full story.") || strings.Contains(resp["content"], "Menu") {
+		t.Errorf("unexpected content: %q", resp["content"])
+	}
+	if ok, _ := h.DB.HasArticleFullText(articleID); !ok {
+		t.Error("full text of an on_open feed was not stored")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/articles/content?id=%d", articleID), nil)
+	w = httptest.NewRecorder()
+	article.HandleGetArticleContent(h, w, req)
+	var content map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&content)
+	if content["full_text"] != true || content["full_text_mode"] != models.FullTextModeOnOpen {
+		t.Errorf("unexpected content response: %v", content)
+	}
+}
+
+func TestHandleArticleRevisionDiff(t *testing.T) {
+	h := setupHandler(t)
+
+	feedID, _ := h.DB.AddFeed(&models.Feed{Title: "F", URL: "http://x"})
+	if err := h.DB.SaveArticles(context.Background(), []*models.Article{{FeedID: feedID, Title: "Storm", URL: "http://x/1"}}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	articles, _ := h.DB.GetArticles("", feedID, "", false, 1, 0)
+	articleID := articles[0].ID
+
+	diff := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/articles/revisions/diff?id=%d%s", articleID, query), nil)
+		w := httptest.NewRecorder()
+		article.HandleArticleRevisionDiff(h, w, req)
+		return w
+	}
+	if w := diff(""); w.Code != http.StatusNotFound {
+		t.Errorf("article without revisions: expected 404, got %d", w.Code)
+	}
+
+	h.DB.SetArticleContent(articleID, "<p>Heavy rain expected.</p>")
+	h.DB.RecordArticleVersion(articleID, "Storm", "<p>Heavy rain expected.</p>", false)
+	h.DB.RecordArticleVersion(articleID, "Storm warning", "<p>Heavy rain and wind expected.</p>", false)
+
+	w := diff("")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		From        int `json:"from"`
+		To          int `json:"to"`
+		ContentDiff []struct {
+			Op   string `json:"op"`
+			Text string `json:"text"`
+		} `json:"content_diff"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var inserted string
+	for _, seg := range resp.ContentDiff {
+		if seg.Op == "insert" {
+			inserted += seg.Text
+		}
+	}
+	if resp.From != 1 || resp.To != 2 || strings.TrimSpace(inserted) != "and wind" {
+		t.Errorf("unexpected diff: %+v", resp)
+	}
+
+	if w := diff("&from=1&to=5"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown revision: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleArticles_DuplicateStories(t *testing.T) {
+	h := setupHandler(t)
+
+	published := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	var ids []int64
+	for i, feedURL := range []string{"http://wire", "http://daily"} {
+		feedID, _ := h.DB.AddFeed(&models.Feed{Title: feedURL, URL: feedURL})
+		at := published.Add(time.Duration(i) * time.Hour)
+		a := &models.Article{FeedID: feedID, Title: "Council approves the new budget", URL: fmt.Sprintf("%s/story/%d", feedURL, i), PublishedAt: at, HasValidPublishedTime: true}
+		if err := h.DB.SaveArticles(context.Background(), []*models.Article{a}); err != nil {
+			t.Fatalf("SaveArticles: %v", err)
+		}
+		id, _ := h.DB.GetArticleIDByUniqueID(a.Title, feedID, at, true)
+		if _, err := h.DB.AssignArticleCluster(id, a.URL, a.Title, ""); err != nil {
+			t.Fatalf("AssignArticleCluster: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	list := func(query string) []models.Article {
+		req := httptest.NewRequest(http.MethodGet, "/api/articles?filter=all"+query, nil)
+		w := httptest.NewRecorder()
+		article.HandleArticles(h, w, req)
+		var articles []models.Article
+		if err := json.NewDecoder(w.Body).Decode(&articles); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return articles
+	}
+	if got := list(""); len(got) != 2 {
+		t.Errorf("expected both articles without collapsing, got %d", len(got))
+	}
+	h.DB.SetSetting("collapse_duplicate_articles", "true")
+	got := list("")
+	if len(got) != 1 || got[0].ID != ids[0] || got[0].DuplicateCount != 1 {
+		t.Errorf("expected the earliest article with 1 duplicate, got %+v", got)
+	}
+	if got := list("&collapse=false"); len(got) != 2 {
+		t.Errorf("collapse=false: expected 2 articles, got %d", len(got))
+	}
+
+	markRead := func(id int64) {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/articles/read?id=%d&read=true", id), nil)
+		article.HandleMarkReadWithImmediateSync(h, httptest.NewRecorder(), req)
+	}
+	markRead(ids[0])
+	if a, _ := h.DB.GetArticleByID(ids[1]); a.IsRead {
+		t.Error("duplicate marked read with mark_duplicates_read disabled")
+	}
+	h.DB.SetSetting("mark_duplicates_read", "true")
+	h.DB.MarkArticleRead(ids[0], false)
+	markRead(ids[0])
+	if a, _ := h.DB.GetArticleByID(ids[1]); !a.IsRead {
+		t.Error("duplicate not marked read with mark_duplicates_read enabled")
+	}
+
+	// Duplicates from FreshRSS feeds are queued for the next sync instead of synced one by one
+	h.DB.SetSetting("freshrss_enabled", "true")
+	duplicate, _ := h.DB.GetArticleByID(ids[1])
+	h.DB.Exec(`UPDATE feeds SET is_freshrss_source = 1 WHERE id = ?`, duplicate.FeedID)
+	h.DB.MarkArticleRead(ids[0], false)
+	h.DB.MarkArticleRead(ids[1], false)
+	markRead(ids[0])
+	if pending, _ := h.DB.GetPendingSyncChanges(10); len(pending) != 1 || pending[0].ArticleID != ids[1] {
+		t.Errorf("expected the duplicate to be queued for sync, got %+v", pending)
+	}
+}
@@ -0,0 +1,122 @@
+package article
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/utils"
+)
+
+// HandleArticleRevisions lists the revisions of an article.
+// @Summary      List article revisions
+// @Description  Versions of an article's title and content as published in its feed, oldest first. Revisions are only kept once the publisher changes an article; revision 1 is the original version.
+// @Tags         articles
+// @Produce      json
+// @Param        id   query     int64  true  "Article ID"
+// @Success      200  {array}   models.ArticleRevision  "Revisions without their content"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid article ID)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/revisions [get]
+func HandleArticleRevisions(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	articleID, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.DB.GetArticleRevisions(articleID)
+	if err != nil {
+		log.Printf("Error getting revisions of article %d: %v", articleID, err)
+		http.Error(w, "Failed to get article revisions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// HandleArticleRevisionDiff returns the word-level differences between two revisions of an article.
+// @Summary      Diff article revisions
+// @Description  Word-level diff of the title and the text content between two revisions. "to" defaults to the latest revision and "from" to the one before "to".
+// @Tags         articles
+// @Produce      json
+// @Param        id    query     int64  true   "Article ID"
+// @Param        from  query     int    false  "Older revision"
+// @Param        to    query     int    false  "Newer revision"
+// @Success      200  {object}  map[string]interface{}  "Diff (from, to, title_diff, content_diff)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid parameters)"
+// @Failure      404  {object}  map[string]string  "Revision not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/revisions/diff [get]
+func HandleArticleRevisionDiff(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	articleID, err := strconv.ParseInt(query.Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.DB.GetArticleRevisions(articleID)
+	if err != nil {
+		log.Printf("Error getting revisions of article %d: %v", articleID, err)
+		http.Error(w, "Failed to get article revisions", http.StatusInternalServerError)
+		return
+	}
+	if len(revisions) == 0 {
+		http.Error(w, "Article has no revisions", http.StatusNotFound)
+		return
+	}
+
+	to := revisions[len(revisions)-1].Revision
+	if v := query.Get("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid to parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	from := to - 1
+	if v := query.Get("from"); v != "" {
+		if from, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid from parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	oldRevision, err := h.DB.GetArticleRevision(articleID, from)
+	if err != nil {
+		http.Error(w, "Failed to get article revision", http.StatusInternalServerError)
+		return
+	}
+	newRevision, err := h.DB.GetArticleRevision(articleID, to)
+	if err != nil {
+		http.Error(w, "Failed to get article revision", http.StatusInternalServerError)
+		return
+	}
+	if oldRevision == nil || newRevision == nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":         oldRevision.Revision,
+		"to":           newRevision.Revision,
+		"from_date":    oldRevision.CreatedAt,
+		"to_date":      newRevision.CreatedAt,
+		"title_diff":   utils.WordDiff(oldRevision.Title, newRevision.Title),
+		"content_diff": utils.WordDiff(utils.HTMLToText(oldRevision.Content), utils.HTMLToText(newRevision.Content)),
+	})
+}
@@ -0,0 +1,87 @@
+package article
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+)
+
+// maxSearchLimit caps the page size of search results
+const maxSearchLimit = 200
+
+// SearchResponse represents the response for a full-text article search
+type SearchResponse struct {
+	Results []database.ArticleSearchResult `json:"results"`
+	Total   int                            `json:"total"`
+	Page    int                            `json:"page"`
+	Limit   int                            `json:"limit"`
+	HasMore bool                           `json:"has_more"`
+}
+
+// HandleSearchArticles runs a full-text search over articles.
+// @Summary      Search articles
+// @Description  Full-text search over article titles, translated titles, summaries and cached content. Supports "quoted phrases", prefix* queries and OR. Results are ranked by relevance and include a highlighted snippet.
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        q         query     string  true   "Search query"
+// @Param        feed_id   query     int64   false  "Filter by feed ID"
+// @Param        category  query     string  false  "Filter by category name"
+// @Param        page      query     int     false  "Page number (default: 1)"  minimum(1)
+// @Param        limit     query     int     false  "Items per page (default: 50, max: 200)"  minimum(1)  maximum(200)
+// @Success      200  {object}  SearchResponse  "Ranked search results"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/search [get]
+func HandleSearchArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Missing search query", http.StatusBadRequest)
+		return
+	}
+
+	var feedID int64
+	if feedIDStr := r.URL.Query().Get("feed_id"); feedIDStr != "" {
+		feedID, _ = strconv.ParseInt(feedIDStr, 10, 64)
+	}
+	category := r.URL.Query().Get("category")
+
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	offset := (page - 1) * limit
+
+	results, total, err := h.DB.SearchArticles(query, feedID, category, limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SearchResponse{
+		Results: results,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		HasMore: offset+len(results) < total,
+	})
+}
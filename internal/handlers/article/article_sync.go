@@ -13,7 +13,7 @@ import (
 
 // HandleMarkReadWithImmediateSync marks an article as read/unread and immediately syncs to FreshRSS
 // @Summary      Mark article as read/unread with immediate FreshRSS sync
-// @Description  Mark a specific article as read or unread and immediately sync to FreshRSS if configured
+// @Description  Mark a specific article as read or unread and immediately sync to FreshRSS if configured. With the mark_duplicates_read setting, marking an article read also marks its duplicates from other feeds read; their FreshRSS changes are queued for the next sync.
 // @Tags         articles
 // @Accept       json
 // @Produce      json
@@ -39,6 +39,22 @@ func HandleMarkReadWithImmediateSync(h *core.Handler, w http.ResponseWriter, r *
 		return
 	}
 
+	// Duplicates are queued and pushed together by the next sync, so marking an article
+	// with many duplicates doesn't start a login and sync for each of them
+	if markDuplicates, _ := h.DB.GetSetting("mark_duplicates_read"); read && markDuplicates == "true" {
+		duplicates, err := h.DB.GetUnreadArticleDuplicates(id)
+		if err != nil {
+			log.Printf("Error getting duplicates of article %d: %v", id, err)
+		}
+		duplicateReqs, err := h.DB.MarkArticlesReadWithSync(duplicates, true)
+		if err == nil {
+			err = h.DB.EnqueueSyncRequests(duplicateReqs)
+		}
+		if err != nil {
+			log.Printf("Error marking duplicates of article %d read: %v", id, err)
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 
 	// Immediately sync to FreshRSS if needed
@@ -84,14 +100,18 @@ func performImmediateSync(h *core.Handler, syncReq *database.SyncRequest) {
 		return
 	}
 
-	serverURL, username, password, err := h.DB.GetFreshRSSConfig()
+	provider, serverURL, username, password, err := h.DB.GetFreshRSSConfig()
 	if err != nil || serverURL == "" || username == "" || password == "" {
 		log.Printf("[Immediate Sync] FreshRSS not configured, skipping sync")
 		return
 	}
 
 	// Create sync service
-	syncService := freshrss.NewBidirectionalSyncService(serverURL, username, password, h.DB)
+	syncService, err := freshrss.NewProviderSyncService(provider, serverURL, username, password, h.DB)
+	if err != nil {
+		log.Printf("[Immediate Sync] %v", err)
+		return
+	}
 
 	// Perform immediate sync
 	ctx := context.Background()
@@ -0,0 +1,267 @@
+// Package auth provides HTTP handlers for logging in to the server build and managing
+// its sessions and API tokens.
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MrRSS/internal/auth"
+	"MrRSS/internal/handlers/core"
+)
+
+// maxTokenNameLength bounds token names and stored user agents
+const maxTokenNameLength = 200
+
+// loginLimiter locks a client out for 15 minutes after 5 wrong passwords
+var loginLimiter = auth.NewLoginLimiter(5, 15*time.Minute)
+
+// HandleLogin checks a user's password and starts a cookie session.
+// @Summary      Log in
+// @Description  Check the username and password and start a session. The session secret is set as an HttpOnly cookie; unsafe requests of the session must repeat csrf_token (also set as the mrrss_csrf cookie) in the X-CSRF-Token header.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Login request (username, password)"
+// @Success      200  {object}  map[string]interface{}  "Session started (user, csrf_token, expires_at)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Wrong username or password"
+// @Failure      429  {object}  map[string]string  "Too many failed attempts"
+// @Router       /auth/login [post]
+func HandleLogin(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !loginLimiter.Allow(r) {
+		http.Error(w, "Too many failed login attempts", http.StatusTooManyRequests)
+		return
+	}
+	user := auth.Authenticate(h.DB, req.Username, req.Password)
+	if user == nil {
+		loginLimiter.Fail(r)
+		log.Printf("[Auth] Failed login for %q from %s", req.Username, r.RemoteAddr)
+		http.Error(w, "Wrong username or password", http.StatusUnauthorized)
+		return
+	}
+	loginLimiter.Reset(r)
+
+	if err := h.DB.DeleteExpiredAuthTokens(); err != nil {
+		log.Printf("[Auth] Error removing expired tokens: %v", err)
+	}
+	token, secret, err := auth.IssueToken(h.DB, user.ID, auth.KindSession, truncate(r.UserAgent()), auth.SessionLifetime)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	auth.SetSessionCookies(w, r, token, secret)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user":       user,
+		"csrf_token": token.CSRFToken,
+		"expires_at": token.ExpiresAt,
+	})
+}
+
+// HandleLogout ends the current session.
+// @Summary      Log out
+// @Description  Revoke the session or token used for the request and clear the session cookies
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]string  "Logged out (status)"
+// @Security     BearerAuth
+// @Router       /auth/logout [post]
+func HandleLogout(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if token := auth.TokenFromContext(r.Context()); token != nil && token.Kind == auth.KindSession {
+		if err := h.DB.DeleteAuthToken(token.UserID, token.ID); err != nil && err != sql.ErrNoRows {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	auth.ClearSessionCookies(w, r)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "logged_out"})
+}
+
+// HandleTokens lists, creates and revokes the sessions and API tokens of the current user.
+// @Summary      Manage sessions and API tokens
+// @Description  GET lists the user's active sessions and API tokens. POST creates an API token; its secret is only returned once. DELETE revokes a session or token by id.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        id       query     int64   false  "Token ID (DELETE)"
+// @Param        request  body      object  false  "New API token (name, expires_in_days; 0 never expires) (POST)"
+// @Success      200  {array}   models.AuthToken  "Sessions and tokens (GET)"
+// @Success      201  {object}  map[string]interface{}  "Created token with its secret (token)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      404  {object}  map[string]string  "Token not found"
+// @Security     BearerAuth
+// @Router       /auth/tokens [get]
+// @Router       /auth/tokens [post]
+// @Router       /auth/tokens [delete]
+func HandleTokens(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	current := auth.TokenFromContext(r.Context())
+	if current == nil {
+		http.Error(w, "Authentication is disabled", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		tokens, err := h.DB.GetAuthTokens(current.UserID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range tokens {
+			tokens[i].Current = tokens[i].ID == current.ID
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tokens)
+
+	case http.MethodPost:
+		var req struct {
+			Name          string `json:"name"`
+			ExpiresInDays int    `json:"expires_in_days"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Name == "" || len(req.Name) > maxTokenNameLength {
+			http.Error(w, "Token name is required", http.StatusBadRequest)
+			return
+		}
+		if req.ExpiresInDays < 0 {
+			http.Error(w, "Invalid expiry", http.StatusBadRequest)
+			return
+		}
+
+		token, secret, err := auth.IssueToken(h.DB, current.UserID, auth.KindAPI, req.Name, time.Duration(req.ExpiresInDays)*24*time.Hour)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":         token.ID,
+			"kind":       token.Kind,
+			"name":       token.Name,
+			"created_at": token.CreatedAt,
+			"expires_at": token.ExpiresAt,
+			"token":      secret,
+		})
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid token ID", http.StatusBadRequest)
+			return
+		}
+		err = h.DB.DeleteAuthToken(current.UserID, id)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Token not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if current.ID == id {
+			auth.ClearSessionCookies(w, r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "revoked"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleChangePassword replaces the current user's password and signs out their other sessions.
+// @Summary      Change password
+// @Description  Replace the current user's password. All their sessions except the current one are revoked; API tokens stay valid.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Password change (current_password, new_password)"
+// @Success      200  {object}  map[string]string  "Password changed (status)"
+// @Failure      400  {object}  map[string]string  "New password too short"
+// @Failure      403  {object}  map[string]string  "Wrong current password"
+// @Security     BearerAuth
+// @Router       /auth/password [post]
+func HandleChangePassword(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	current := auth.TokenFromContext(r.Context())
+	if current == nil {
+		http.Error(w, "Authentication is disabled", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !loginLimiter.Allow(r) {
+		http.Error(w, "Too many failed attempts", http.StatusTooManyRequests)
+		return
+	}
+	if !auth.CheckPassword(h.DB, current.UserID, req.CurrentPassword) {
+		loginLimiter.Fail(r)
+		http.Error(w, "Wrong current password", http.StatusForbidden)
+		return
+	}
+	if err := auth.SetPassword(h.DB, current.UserID, req.NewPassword); err == auth.ErrPasswordTooShort {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.DB.DeleteSessionsExcept(current.UserID, current.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "changed"})
+}
+
+func truncate(s string) string {
+	if len(s) > maxTokenNameLength {
+		return s[:maxTokenNameLength]
+	}
+	return s
+}
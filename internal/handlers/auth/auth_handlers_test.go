@@ -0,0 +1,211 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"MrRSS/internal/auth"
+	"MrRSS/internal/database"
+	ff "MrRSS/internal/feed"
+	ah "MrRSS/internal/handlers/auth"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+const testPassword = "correct horse"
+
+func setupHandler(t *testing.T) *core.Handler {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	if _, err := auth.EnsureAdmin(db, testPassword); err != nil {
+		t.Fatal(err)
+	}
+	return core.NewHandler(db, ff.NewFetcher(db), nil)
+}
+
+func login(h *core.Handler, password, remoteAddr string) *httptest.ResponseRecorder {
+	body := `{"username":"` + auth.AdminUsername + `","password":"` + password + `"}`
+	req := httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	ah.HandleLogin(h, w, req)
+	return w
+}
+
+// withToken authenticates a request the way the middleware does
+func withToken(req *http.Request, token *models.AuthToken) *http.Request {
+	return req.WithContext(auth.WithToken(req.Context(), token))
+}
+
+func TestHandleLogin(t *testing.T) {
+	h := setupHandler(t)
+
+	w := login(h, testPassword, "192.0.2.1:1000")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	cookies := make(map[string]*http.Cookie)
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	session, csrf := cookies[auth.SessionCookie], cookies[auth.CSRFCookie]
+	if session == nil || !session.HttpOnly || csrf == nil || csrf.HttpOnly {
+		t.Fatalf("unexpected cookies: %v", w.Result().Cookies())
+	}
+	var resp struct {
+		User      models.User `json:"user"`
+		CSRFToken string      `json:"csrf_token"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.CSRFToken == "" || resp.CSRFToken != csrf.Value {
+		t.Errorf("csrf_token = %q, cookie %q", resp.CSRFToken, csrf.Value)
+	}
+	if resp.User.Username != auth.AdminUsername || !resp.User.IsAdmin {
+		t.Errorf("user = %+v", resp.User)
+	}
+
+	token, err := h.DB.GetAuthTokenByHash(auth.HashToken(session.Value))
+	if err != nil || token.Kind != auth.KindSession || token.CSRFToken != csrf.Value || token.UserID != resp.User.ID {
+		t.Errorf("stored session = %+v, %v", token, err)
+	}
+}
+
+func TestHandleLogin_WrongPasswordLocksOut(t *testing.T) {
+	h := setupHandler(t)
+	addr := "192.0.2.2:1000"
+
+	for i := 0; i < 5; i++ {
+		if w := login(h, "wrong password", addr); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i, w.Code)
+		}
+	}
+	if w := login(h, testPassword, addr); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 after repeated failures, got %d", w.Code)
+	}
+	if w := login(h, testPassword, "192.0.2.3:1000"); w.Code != http.StatusOK {
+		t.Errorf("other client: expected 200, got %d", w.Code)
+	}
+}
+
+func TestHandleTokens(t *testing.T) {
+	h := setupHandler(t)
+	session, _, err := auth.IssueToken(h.DB, 1, auth.KindSession, "browser", auth.SessionLifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherUser, _, err := auth.IssueToken(h.DB, 2, auth.KindAPI, "someone else", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create
+	req := withToken(httptest.NewRequest("POST", "/api/auth/tokens", strings.NewReader(`{"name":"cli","expires_in_days":30}`)), session)
+	w := httptest.NewRecorder()
+	ah.HandleTokens(h, w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		ID        int64  `json:"id"`
+		Token     string `json:"token"`
+		ExpiresAt string `json:"expires_at"`
+	}
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.Token == "" || created.ExpiresAt == "" {
+		t.Fatalf("unexpected created token: %+v", created)
+	}
+	if token, err := h.DB.GetAuthTokenByHash(auth.HashToken(created.Token)); err != nil || token.Kind != auth.KindAPI || token.Name != "cli" {
+		t.Errorf("stored token = %+v, %v", token, err)
+	}
+
+	// List
+	req = withToken(httptest.NewRequest("GET", "/api/auth/tokens", nil), session)
+	w = httptest.NewRecorder()
+	ah.HandleTokens(h, w, req)
+	var tokens []models.AuthToken
+	json.NewDecoder(w.Body).Decode(&tokens)
+	if len(tokens) != 2 || tokens[0].Name != "cli" || tokens[0].Current || !tokens[1].Current {
+		t.Errorf("unexpected token list: %+v", tokens)
+	}
+	if strings.Contains(w.Body.String(), created.Token) {
+		t.Error("token list exposes the secret")
+	}
+
+	// Tokens of other users can't be revoked
+	req = withToken(httptest.NewRequest("DELETE", "/api/auth/tokens?id="+strconv.FormatInt(otherUser.ID, 10), nil), session)
+	w = httptest.NewRecorder()
+	ah.HandleTokens(h, w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("revoke other user's token: expected 404, got %d", w.Code)
+	}
+
+	// Revoke
+	req = withToken(httptest.NewRequest("DELETE", "/api/auth/tokens?id=1000", nil), session)
+	w = httptest.NewRecorder()
+	ah.HandleTokens(h, w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("revoke unknown: expected 404, got %d", w.Code)
+	}
+	req = withToken(httptest.NewRequest("DELETE", "/api/auth/tokens?id="+strconv.FormatInt(created.ID, 10), nil), session)
+	w = httptest.NewRecorder()
+	ah.HandleTokens(h, w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("revoke: expected 200, got %d", w.Code)
+	}
+	if _, err := h.DB.GetAuthTokenByHash(auth.HashToken(created.Token)); err == nil {
+		t.Error("revoked token still valid")
+	}
+}
+
+func TestHandleChangePassword(t *testing.T) {
+	h := setupHandler(t)
+	current, _, _ := auth.IssueToken(h.DB, 1, auth.KindSession, "this browser", auth.SessionLifetime)
+	_, otherSecret, _ := auth.IssueToken(h.DB, 1, auth.KindSession, "other browser", auth.SessionLifetime)
+	_, apiSecret, _ := auth.IssueToken(h.DB, 1, auth.KindAPI, "cli", 0)
+	_, otherUserSecret, _ := auth.IssueToken(h.DB, 2, auth.KindSession, "other user", auth.SessionLifetime)
+
+	change := func(body string) int {
+		req := withToken(httptest.NewRequest("POST", "/api/auth/password", strings.NewReader(body)), current)
+		req.RemoteAddr = "192.0.2.4:1000"
+		w := httptest.NewRecorder()
+		ah.HandleChangePassword(h, w, req)
+		return w.Code
+	}
+
+	if code := change(`{"current_password":"wrong","new_password":"new password"}`); code != http.StatusForbidden {
+		t.Errorf("wrong current password: expected 403, got %d", code)
+	}
+	if code := change(`{"current_password":"` + testPassword + `","new_password":"short"}`); code != http.StatusBadRequest {
+		t.Errorf("short password: expected 400, got %d", code)
+	}
+	if code := change(`{"current_password":"` + testPassword + `","new_password":"new password"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	if !auth.CheckPassword(h.DB, 1, "new password") {
+		t.Error("password not changed")
+	}
+	if _, err := h.DB.GetAuthTokenByHash(auth.HashToken(otherSecret)); err == nil {
+		t.Error("other session still valid")
+	}
+	if _, err := h.DB.GetAuthTokenByHash(auth.HashToken(apiSecret)); err != nil {
+		t.Errorf("API token revoked: %v", err)
+	}
+	if _, err := h.DB.GetAuthTokenByHash(auth.HashToken(otherUserSecret)); err != nil {
+		t.Errorf("session of another user revoked: %v", err)
+	}
+	tokens, _ := h.DB.GetAuthTokens(1)
+	if len(tokens) != 2 {
+		t.Errorf("expected current session and API token to remain, got %+v", tokens)
+	}
+}
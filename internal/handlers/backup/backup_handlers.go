@@ -0,0 +1,161 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"MrRSS/internal/backup"
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+)
+
+// maxBackupUploadSize limits the size of an uploaded backup archive (2 GB), which can include
+// a snapshot of the whole database
+const maxBackupUploadSize = 2 << 30
+
+// HandleBackupExport streams a backup archive of the complete state.
+// @Summary      Export a full backup
+// @Description  Download a zip archive with all feeds, articles with read state, summaries and translations, rules, chat sessions, settings and statistics. Secrets (API keys, passwords, feed credentials) are only included with include_secrets=true, encrypted with the given passphrase or else the master passphrase. Send the passphrase as a POST form field.
+// @Tags         backup
+// @Accept       x-www-form-urlencoded
+// @Produce      application/zip
+// @Param        include_secrets  formData  bool    false  "Include secrets"
+// @Param        passphrase       formData  string  false  "Passphrase to encrypt the secrets with"
+// @Param        snapshot         formData  bool    false  "Add a SQLite snapshot of the database"
+// @Success      200  {file}    file    "Backup archive"
+// @Failure      400  {string}  string  "A passphrase is required to include secrets"
+// @Failure      405  {string}  string  "Method not allowed"
+// @Router       /backup/export [get]
+// @Router       /backup/export [post]
+func HandleBackupExport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	opts := backup.ExportOptions{
+		IncludeSecrets:  r.FormValue("include_secrets") == "true",
+		Passphrase:      r.PostFormValue("passphrase"),
+		IncludeSnapshot: r.FormValue("snapshot") == "true",
+	}
+	// Check the passphrase before the archive starts streaming
+	if opts.IncludeSecrets {
+		if _, _, err := backup.ExportKey(h.DB, opts.Passphrase); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	filename := fmt.Sprintf("mrrss-backup-%s.zip", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+
+	// The archive is streamed, so errors after the first write can only be logged
+	manifest, err := backup.Export(h.DB, w, opts)
+	if err != nil {
+		log.Printf("[Backup] Export failed: %v", err)
+		return
+	}
+	log.Printf("[Backup] Exported backup (secrets: %v, snapshot: %v, tables: %v)",
+		opts.IncludeSecrets, opts.IncludeSnapshot, manifest.Tables)
+}
+
+// HandleBackupImport restores a backup archive.
+// @Summary      Restore a full backup
+// @Description  Restore a backup archive, uploaded as the "file" form field or as the raw request body. In merge mode the backup is added to the existing data; in replace mode the existing data is deleted first. The report counts the restored rows per table and lists conflicts with existing data. Secrets are restored if the passphrase they were exported with is given or is the master passphrase; without a passphrase and with another master passphrase they are left out. A wrong passphrase restores nothing.
+// @Tags         backup
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file        formData  file    false  "Backup archive"
+// @Param        passphrase  formData  string  false  "Passphrase the secrets were exported with"
+// @Param        mode        query     string  false  "merge (default) or replace"
+// @Success      200  {object}  backup.ImportResult  "Manifest of the backup, restore report and whether secrets were restored"
+// @Failure      400  {string}  string  "Bad request"
+// @Failure      403  {string}  string  "Wrong passphrase, or secrets locked"
+// @Failure      405  {string}  string  "Method not allowed"
+// @Failure      413  {string}  string  "Backup archive too large"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /backup/import [post]
+func HandleBackupImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mode := database.RestoreMode(r.URL.Query().Get("mode"))
+	if mode == "" {
+		mode = database.RestoreMerge
+	}
+	if mode != database.RestoreMerge && mode != database.RestoreReplace {
+		http.Error(w, "mode must be merge or replace", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBackupUploadSize)
+	var archive io.ReaderAt
+	var size int64
+	var passphrase string
+	if strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+		defer f.Close()
+		archive, size = f, header.Size
+		passphrase = r.FormValue("passphrase")
+	} else {
+		// A zip archive needs random access, so spool the raw body to a file
+		tmp, err := os.CreateTemp("", "mrrss-restore-*.zip")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		size, err = io.Copy(tmp, r.Body)
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+		archive = tmp
+	}
+
+	result, err := backup.Import(h.DB, archive, size, backup.ImportOptions{Mode: mode, Passphrase: passphrase})
+	if err != nil {
+		log.Printf("[Backup] Restore failed: %v", err)
+		switch {
+		case errors.Is(err, backup.ErrInvalidArchive):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, crypto.ErrWrongPassphrase):
+			http.Error(w, "Wrong passphrase for the secrets of the backup, nothing was restored. Leave the passphrase empty to restore without secrets.", http.StatusForbidden)
+		case errors.Is(err, database.ErrSecretsLocked):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("[Backup] Restored backup from %s (%s mode, %d conflicts, secrets restored: %v)",
+		result.Manifest.CreatedAt.Format(time.RFC3339), mode, result.Report.ConflictCount, result.SecretsRestored)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeUploadError reports an error reading an uploaded archive
+func writeUploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("Backup archive is larger than %d MB", maxBackupUploadSize>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -3,10 +3,10 @@
 package core
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -14,15 +14,15 @@ import (
 	"MrRSS/internal/aiusage"
 	"MrRSS/internal/cache"
 	"MrRSS/internal/database"
+	"MrRSS/internal/digest"
 	"MrRSS/internal/discovery"
 	"MrRSS/internal/feed"
 	"MrRSS/internal/models"
+	"MrRSS/internal/notify"
 	"MrRSS/internal/statistics"
 	"MrRSS/internal/translation"
 	"MrRSS/internal/utils"
 
-	"codeberg.org/readeck/go-readability/v2"
-
 	"github.com/mmcdole/gofeed"
 )
 
@@ -53,6 +53,8 @@ type Handler struct {
 	App              interface{}         // Wails app instance for browser integration (interface{} to avoid import in server mode)
 	ContentCache     *cache.ContentCache // Cache for article content
 	Stats            *statistics.Service // Statistics tracking service
+	Digests          *digest.Service     // Scheduled email digests
+	Notifications    *notify.Dispatcher  // Notification targets of new articles
 
 	// Discovery state tracking for polling-based progress
 	DiscoveryMu          sync.RWMutex
@@ -71,6 +73,11 @@ func NewHandler(db *database.DB, fetcher *feed.Fetcher, translator translation.T
 		ContentCache:     cache.NewContentCache(100, 30*time.Minute), // Cache up to 100 articles for 30 minutes
 		Stats:            statistics.NewService(db),
 	}
+	h.Digests = digest.NewService(db, h.AITracker)
+	h.Notifications = notify.NewDispatcher(db)
+	if fetcher != nil {
+		fetcher.SetNotifier(h.Notifications)
+	}
 
 	return h
 }
@@ -166,22 +173,24 @@ func (h *Handler) GetArticleContent(articleID int64) (string, bool, error) {
 	return "", false, nil
 }
 
-// FetchFullArticleContent fetches the full article content from the original URL using readability.
-func (h *Handler) FetchFullArticleContent(url string) (string, error) {
-	// Use FromURL which handles the HTTP request internally
-	article, err := readability.FromURL(url, 30*time.Second)
+// FetchFullArticleContent fetches the full article content from the original URL.
+// The request and full-text settings of the article's feed are applied.
+func (h *Handler) FetchFullArticleContent(url string, feedID int64) (string, error) {
+	fullTextSettings, err := h.DB.GetFeedFullTextSettings(feedID)
 	if err != nil {
-		return "", fmt.Errorf("readability parse: %w", err)
+		log.Printf("Error loading full-text settings of feed %d: %v", feedID, err)
 	}
-
-	// Render the article content as HTML
-	var buf bytes.Buffer
-	err = article.RenderHTML(&buf)
+	settings, err := h.DB.GetFeedHTTPSettings(feedID)
+	if err != nil || settings == nil {
+		return utils.FetchFullText(url, fullTextSettings)
+	}
+	feed, err := h.DB.GetFeedByID(feedID)
 	if err != nil {
-		return "", fmt.Errorf("render HTML: %w", err)
+		return utils.FetchFullText(url, fullTextSettings)
 	}
-
-	return buf.String(), nil
+	return utils.FetchFullText(url, fullTextSettings, func(req *http.Request) {
+		utils.ApplyFeedHTTPSettings(req, settings, feed.URL)
+	})
 }
 
 // findMatchingFeedItem finds the best matching feed item for an article using multiple criteria
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"MrRSS/internal/backup"
 	"MrRSS/internal/cache"
 	"MrRSS/internal/utils"
 )
@@ -94,6 +95,9 @@ func (h *Handler) startScheduler(ctx context.Context, intelligentMode bool) {
 	globalInterval := getGlobalInterval()
 	log.Printf("Global refresh interval: %v", globalInterval)
 
+	// Open IMAP IDLE connections right away, the ticker keeps them in sync with the feeds
+	go h.Fetcher.SyncEmailIdle(ctx)
+
 	// Use a ticker to check every minute
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
@@ -116,10 +120,67 @@ func (h *Handler) startScheduler(ctx context.Context, intelligentMode bool) {
 
 			// Schedule individual feeds with custom intervals
 			go h.scheduleIndividualFeeds(ctx, intelligentMode)
+
+			// Renew WebSub leases that are about to expire
+			go h.Fetcher.RenewWebSubLeases(ctx)
+
+			// Start and stop IMAP IDLE connections of newsletter feeds
+			go h.Fetcher.SyncEmailIdle(ctx)
+
+			// Write an automatic backup when one is due
+			go h.runAutoBackup()
+
+			// Email the digests whose scheduled time has come
+			go h.Digests.RunDue(ctx, time.Now())
+
+			// Send the notification batches that are ready and retry failed ones
+			go h.Notifications.Flush(ctx, time.Now())
 		}
 	}
 }
 
+// runAutoBackup writes an automatic backup if they are enabled and the interval has
+// passed since the last one
+func (h *Handler) runAutoBackup() {
+	enabled, _ := h.DB.GetSetting("auto_backup_enabled")
+	if enabled != "true" {
+		return
+	}
+
+	intervalStr, _ := h.DB.GetSetting("auto_backup_interval_hours")
+	interval, err := strconv.Atoi(intervalStr)
+	if err != nil || interval <= 0 {
+		interval = 24
+	}
+	lastStr, _ := h.DB.GetSetting("last_auto_backup")
+	if last, err := time.Parse(time.RFC3339, lastStr); err == nil && time.Since(last) < time.Duration(interval)*time.Hour {
+		return
+	}
+
+	// Record the attempt first, so the next tick doesn't start another backup
+	if err := h.DB.SetSetting("last_auto_backup", time.Now().Format(time.RFC3339)); err != nil {
+		log.Printf("Failed to save last_auto_backup: %v", err)
+		return
+	}
+
+	keepStr, _ := h.DB.GetSetting("auto_backup_keep")
+	keep, err := strconv.Atoi(keepStr)
+	if err != nil || keep <= 0 {
+		keep = 7
+	}
+	dir, err := backup.AutoBackupDir(h.DB)
+	if err != nil {
+		log.Printf("Failed to get the backup directory: %v", err)
+		return
+	}
+	path, err := backup.WriteAutoBackup(h.DB, dir, keep)
+	if err != nil {
+		log.Printf("Automatic backup failed: %v", err)
+		return
+	}
+	log.Printf("Automatic backup written to %s", path)
+}
+
 // triggerGlobalRefresh triggers a global refresh for all feeds with RefreshInterval == 0
 // In intelligent mode, this calculates intervals per feed
 // In fixed mode, all feeds refresh together at the global interval
@@ -141,7 +202,8 @@ func (h *Handler) triggerGlobalRefresh(ctx context.Context, intelligentMode bool
 	// Check if there are any refreshable feeds (excluding FreshRSS feeds)
 	refreshableFeeds := make([]models.Feed, 0)
 	for _, feed := range globalFeeds {
-		if !feed.IsFreshRSSSource {
+		// Newsletters split from a mailbox are refreshed with their mailbox
+		if !feed.IsFreshRSSSource && feed.Type != models.FeedTypeEmailList {
 			refreshableFeeds = append(refreshableFeeds, feed)
 		}
 	}
@@ -168,8 +230,9 @@ func (h *Handler) triggerGlobalRefresh(ctx context.Context, intelligentMode bool
 
 	if intelligentMode {
 		// In intelligent mode, schedule each feed individually with calculated intervals
+		// Feeds receiving WebSub pushes are only polled at the fallback interval
 		calculator := h.Fetcher.GetIntelligentRefreshCalculator()
-		for _, feed := range refreshableFeeds {
+		for _, feed := range h.Fetcher.FilterWebSubPushedFeeds(refreshableFeeds) {
 			interval := calculator.CalculateInterval(feed)
 			staggerDelay := h.Fetcher.GetStaggeredDelay(feed.ID, len(refreshableFeeds))
 
@@ -185,8 +248,8 @@ func (h *Handler) triggerGlobalRefresh(ctx context.Context, intelligentMode bool
 			}(feed, staggerDelay, interval)
 		}
 	} else {
-		// In fixed mode, refresh all feeds together
-		h.Fetcher.FetchAll(ctx)
+		// In fixed mode, refresh all feeds together (except those receiving WebSub pushes)
+		h.Fetcher.FetchAllScheduled(ctx)
 	}
 
 	// Run media cache cleanup if enabled
@@ -207,7 +270,10 @@ func (h *Handler) scheduleIndividualFeeds(ctx context.Context, intelligentMode b
 
 	calculator := h.Fetcher.GetIntelligentRefreshCalculator()
 
-	for _, feed := range feeds {
+	// Feeds receiving WebSub pushes are only polled at the fallback interval
+	pollableFeeds := h.Fetcher.FilterWebSubPushedFeeds(feeds)
+
+	for _, feed := range pollableFeeds {
 		// Skip feeds using global setting (RefreshInterval == 0)
 		if feed.RefreshInterval == 0 {
 			continue
@@ -218,6 +284,11 @@ func (h *Handler) scheduleIndividualFeeds(ctx context.Context, intelligentMode b
 			continue
 		}
 
+		// Skip newsletters split from a mailbox - they are refreshed with their mailbox
+		if feed.Type == models.FeedTypeEmailList {
+			continue
+		}
+
 		// Check if context is cancelled
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,218 @@
+package digest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MrRSS/internal/digest"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+// HandleDigests lists all digests (GET) or creates a new digest (POST).
+// @Summary      List or create email digests
+// @Description  GET returns all email digests. POST validates and creates a digest; its first email is sent at the first scheduled time after it was created. Articles are selected with the conditions of the advanced article filter.
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        digest  body      models.Digest  false  "Digest to create (POST only)"
+// @Success      200  {array}   models.Digest  "List of digests (GET)"
+// @Success      201  {object}  map[string]interface{}  "Created digest ID (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid digest)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests [get]
+// @Router       /digests [post]
+func HandleDigests(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.DB.GetDigests()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	case http.MethodPost:
+		var d models.Digest
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := digest.Validate(&d); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id, err := h.DB.CreateDigest(d)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleUpdateDigest replaces an existing digest.
+// @Summary      Update an email digest
+// @Description  Validate and replace the name, state, schedule, recipients and criteria of a digest. Articles it already sent are not sent again.
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        digest  body      models.Digest  true  "Digest (id required)"
+// @Success      200  {string}  string  "Digest updated successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid digest)"
+// @Failure      404  {object}  map[string]string  "Digest not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/update [post]
+func HandleUpdateDigest(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var d models.Digest
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := digest.Validate(&d); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.UpdateDigest(d); err != nil {
+		writeDigestError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeleteDigest deletes a digest.
+// @Summary      Delete an email digest
+// @Description  Delete a digest by ID, with its send history
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "Digest ID"
+// @Success      200  {string}  string  "Digest deleted successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      404  {object}  map[string]string  "Digest not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/delete [post]
+func HandleDeleteDigest(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteDigest(id); err != nil {
+		writeDigestError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleSendDigest sends a digest right away.
+// @Summary      Send an email digest now
+// @Description  Email the articles matching a digest that it didn't send before, without waiting for its schedule. The send is recorded in the digest's history.
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "Digest ID"
+// @Success      200  {object}  models.DigestSend  "Digest sent"
+// @Success      204  {string}  string  "No new articles to send"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id or SMTP not configured)"
+// @Failure      404  {object}  map[string]string  "Digest not found"
+// @Failure      502  {object}  map[string]string  "The mail server rejected the digest"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/send [post]
+func HandleSendDigest(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+	d, err := h.DB.GetDigest(id)
+	if err != nil {
+		writeDigestError(w, err)
+		return
+	}
+
+	send, err := h.Digests.Send(r.Context(), d, time.Now())
+	switch {
+	case errors.Is(err, digest.ErrSMTPNotConfigured):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil && send != nil:
+		// The attempt was recorded, the mail server or the connection failed
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case send == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(send)
+	}
+}
+
+// HandleDigestSends returns the send history of a digest.
+// @Summary      Get digest send history
+// @Description  Get the latest emails of a digest with the articles they included, newest first
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Param        id     query     int64  true   "Digest ID"
+// @Param        limit  query     int    false  "Maximum number of sends (default: 100)"
+// @Success      200  {array}   models.DigestSend  "Digest sends"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /digests/sends [get]
+func HandleDigestSends(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	sends, err := h.DB.GetDigestSends(id, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sends)
+}
+
+// writeDigestError maps digest database errors to HTTP status codes
+func writeDigestError(w http.ResponseWriter, err error) {
+	if err == sql.ErrNoRows {
+		http.Error(w, "Digest not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
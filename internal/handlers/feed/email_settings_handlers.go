@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+// HandleFeedEmailSettings gets (GET) or sets (POST) the newsletter settings of an email feed.
+// @Summary      Get or set feed newsletter settings
+// @Description  Per-feed IMAP IDLE push, sender/subject/List-Id filters, splitting the mailbox into one feed per newsletter (keyed by List-Id) and what happens to processed messages on the server ("" keeps them, "mark_read" or "move" to move_folder). IDLE connections start within a minute. Posting empty settings removes them.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        id        query     int64                     false  "Feed ID (GET only)"
+// @Param        settings  body      models.FeedEmailSettings  false  "Settings to store (POST only, feed_id required)"
+// @Success      200  {object}  models.FeedEmailSettings  "Feed newsletter settings (GET)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id, filter or action, or not an email feed)"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/email-settings [get]
+// @Router       /feeds/email-settings [post]
+func HandleFeedEmailSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+			return
+		}
+
+		settings, err := h.DB.GetFeedEmailSettings(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if settings == nil {
+			settings = &models.FeedEmailSettings{FeedID: id}
+		}
+		if settings.Filters == nil {
+			settings.Filters = []models.EmailFilter{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(settings)
+	case http.MethodPost:
+		var settings models.FeedEmailSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		feed, err := h.DB.GetFeedByID(settings.FeedID)
+		if err != nil {
+			http.Error(w, "Feed not found", http.StatusNotFound)
+			return
+		}
+		if feed.Type != models.FeedTypeEmail {
+			http.Error(w, "Not an email feed", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.DB.SetFeedEmailSettings(&settings); err != nil {
+			if errors.Is(err, database.ErrInvalidEmailFilter) || errors.Is(err, database.ErrInvalidProcessedAction) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -3,8 +3,10 @@ package feed
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"MrRSS/internal/handlers/core"
 	"MrRSS/internal/rsshub"
@@ -161,6 +163,16 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 func HandleDeleteFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	idStr := r.URL.Query().Get("id")
 	id, _ := strconv.ParseInt(idStr, 10, 64)
+
+	// Stop WebSub pushes for this feed before removing it (best effort)
+	if h.Fetcher != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		if err := h.Fetcher.UnsubscribeWebSub(ctx, id); err != nil {
+			log.Printf("Failed to unsubscribe feed %d from WebSub hub: %v", id, err)
+		}
+		cancel()
+	}
+
 	if err := h.DB.DeleteFeed(id); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
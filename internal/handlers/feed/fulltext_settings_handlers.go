@@ -0,0 +1,162 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+)
+
+// HandleFeedFullTextSettings gets (GET) or sets (POST) the full-text settings of a feed.
+// @Summary      Get or set feed full-text settings
+// @Description  Per-feed full-text mode ("" follows the global setting, "never", "on_open" or "eager"), a CSS or XPath selector of the article body and selectors of elements to strip. Posting empty settings removes them.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        id        query     int64                        false  "Feed ID (GET only)"
+// @Param        settings  body      models.FeedFullTextSettings  false  "Settings to store (POST only, feed_id required)"
+// @Success      200  {object}  models.FeedFullTextSettings  "Feed full-text settings (GET)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id, mode or selector)"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/fulltext-settings [get]
+// @Router       /feeds/fulltext-settings [post]
+func HandleFeedFullTextSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+			return
+		}
+
+		settings, err := h.DB.GetFeedFullTextSettings(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if settings == nil {
+			settings = &models.FeedFullTextSettings{FeedID: id}
+		}
+		if settings.StripSelectors == nil {
+			settings.StripSelectors = []string{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(settings)
+	case http.MethodPost:
+		var settings models.FeedFullTextSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := validateSelectors(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := h.DB.GetFeedByID(settings.FeedID); err != nil {
+			http.Error(w, "Feed not found", http.StatusNotFound)
+			return
+		}
+
+		if err := h.DB.SetFeedFullTextSettings(&settings); err != nil {
+			if err == database.ErrInvalidFullTextMode {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleTestFullTextExtraction extracts the full text of a page with unsaved selectors.
+// @Summary      Test full-text extraction
+// @Description  Fetches a page and extracts its content with the given selectors, so they can be tried before saving. The request settings of feed_id are applied if given. Only http and https pages on public addresses are fetched.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Page to test (url, feed_id, content_selector, strip_selectors)"
+// @Success      200  {object}  map[string]string  "Extracted content (content)"
+// @Failure      400  {object}  map[string]string  "Bad request (missing or refused URL, or invalid selector)"
+// @Failure      502  {object}  map[string]string  "Page could not be fetched or extracted"
+// @Router       /feeds/fulltext-test [post]
+func HandleTestFullTextExtraction(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		URL             string   `json:"url"`
+		FeedID          int64    `json:"feed_id"`
+		ContentSelector string   `json:"content_selector"`
+		StripSelectors  []string `json:"strip_selectors"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+
+	settings := &models.FeedFullTextSettings{
+		FeedID:          req.FeedID,
+		ContentSelector: req.ContentSelector,
+		StripSelectors:  req.StripSelectors,
+	}
+	if err := validateSelectors(settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var modifiers []func(*http.Request)
+	if req.FeedID != 0 {
+		if feed, err := h.DB.GetFeedByID(req.FeedID); err == nil {
+			if httpSettings, _ := h.DB.GetFeedHTTPSettings(req.FeedID); httpSettings != nil {
+				modifiers = append(modifiers, func(r *http.Request) {
+					utils.ApplyFeedHTTPSettings(r, httpSettings, feed.URL)
+				})
+			}
+		}
+	}
+
+	content, err := utils.FetchPublicFullText(req.URL, settings, modifiers...)
+	if errors.Is(err, utils.ErrURLNotAllowed) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Full-text test of %s failed: %v", req.URL, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"content": content})
+}
+
+// validateSelectors rejects content and strip selectors that are neither valid CSS nor XPath
+func validateSelectors(settings *models.FeedFullTextSettings) error {
+	for _, selector := range append([]string{settings.ContentSelector}, settings.StripSelectors...) {
+		if strings.TrimSpace(selector) == "" {
+			continue
+		}
+		if err := utils.ValidateSelector(selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
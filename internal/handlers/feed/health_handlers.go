@@ -0,0 +1,56 @@
+package feed
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"MrRSS/internal/handlers/core"
+)
+
+// HandleFeedHealth returns the refresh health of a feed.
+// @Summary      Get feed health
+// @Description  Summarize the feed's recorded refresh attempts: success ratio, mean latency, current failure streak, failures by error class and whether the feed has been flagged as dead
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int64  true   "Feed ID"
+// @Param        recent  query     int    false  "Number of latest attempts to include (default: 20)"
+// @Success      200  {object}  models.FeedHealth  "Feed health"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/{id}/health [get]
+func HandleFeedHealth(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	recent := 20
+	if s := r.URL.Query().Get("recent"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			recent = n
+		}
+	}
+
+	health, err := h.DB.GetFeedHealth(id, recent)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(health)
+}
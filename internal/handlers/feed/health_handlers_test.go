@@ -0,0 +1,64 @@
+package feed_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	fh "MrRSS/internal/handlers/feed"
+	"MrRSS/internal/models"
+)
+
+func TestHandleFeedHealth(t *testing.T) {
+	h := setupHandler(t)
+
+	id, err := h.DB.AddFeed(&models.Feed{Title: "a", URL: "http://x/1"})
+	if err != nil {
+		t.Fatalf("add feed: %v", err)
+	}
+	h.DB.AddFetchLogEntry(&models.FetchLogEntry{FeedID: id, DurationMs: 100, HTTPStatus: 200})
+	h.DB.AddFetchLogEntry(&models.FetchLogEntry{FeedID: id, DurationMs: 300, ErrorClass: "timeout", Error: "deadline"})
+
+	req := httptest.NewRequest("GET", "/api/feeds/1/health?recent=1", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	fh.HandleFeedHealth(h, w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+
+	var health models.FeedHealth
+	if err := json.NewDecoder(w.Body).Decode(&health); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if health.Attempts != 2 || health.SuccessRatio != 0.5 || health.MeanLatencyMs != 200 ||
+		health.ConsecutiveFailures != 1 || len(health.Recent) != 1 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+
+	// Unknown feed
+	req = httptest.NewRequest("GET", "/api/feeds/999/health", nil)
+	req.SetPathValue("id", "999")
+	w = httptest.NewRecorder()
+	fh.HandleFeedHealth(h, w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404 for unknown feed, got %d", w.Code)
+	}
+}
+
+func TestHandleFeedHealth_BadRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/feeds/abc/health", nil)
+	req.SetPathValue("id", "abc")
+	w := httptest.NewRecorder()
+	fh.HandleFeedHealth(nil, w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400 for invalid id, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/api/feeds/1/health", nil)
+	w = httptest.NewRecorder()
+	fh.HandleFeedHealth(nil, w, req)
+	if w.Code != 405 {
+		t.Errorf("expected 405 for POST, got %d", w.Code)
+	}
+}
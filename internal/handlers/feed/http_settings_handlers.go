@@ -0,0 +1,120 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+// HTTPSettingsResponse is the HTTP settings of a feed without the cookie and auth secret,
+// which are never sent back
+type HTTPSettingsResponse struct {
+	*models.FeedHTTPSettings
+	HasCookie     bool `json:"has_cookie"`
+	HasAuthSecret bool `json:"has_auth_secret"`
+}
+
+// HandleFeedHTTPSettings gets (GET) or sets (POST) the HTTP request settings of a feed.
+// @Summary      Get or set feed HTTP settings
+// @Description  Per-feed User-Agent, extra headers, cookie and basic/bearer authentication used when fetching the feed and its full-text articles. The cookie and auth secret are stored encrypted and are write-only: GET only tells whether they are set, and posting an empty cookie or auth secret keeps the stored one. The auth secret is dropped when the auth type is cleared. Posting empty settings removes them.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        id        query     int64                    false  "Feed ID (GET only)"
+// @Param        settings  body      models.FeedHTTPSettings  false  "Settings to store (POST only, feed_id required)"
+// @Success      200  {object}  HTTPSettingsResponse  "Feed HTTP settings without secrets (GET)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id, header or auth type)"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/http-settings [get]
+// @Router       /feeds/http-settings [post]
+func HandleFeedHTTPSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+			return
+		}
+
+		settings, err := h.DB.GetFeedHTTPSettings(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if settings == nil {
+			settings = &models.FeedHTTPSettings{FeedID: id}
+		}
+		response := HTTPSettingsResponse{
+			FeedHTTPSettings: settings,
+			HasCookie:        settings.Cookie != "",
+			HasAuthSecret:    settings.AuthSecret != "",
+		}
+		settings.Cookie, settings.AuthSecret = "", ""
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	case http.MethodPost:
+		var settings models.FeedHTTPSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := validateHeaders(settings.Headers); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := h.DB.GetFeedByID(settings.FeedID); err != nil {
+			http.Error(w, "Feed not found", http.StatusNotFound)
+			return
+		}
+
+		// Secrets are never sent to the client, so empty ones keep what is stored
+		if !settings.IsEmpty() && (settings.Cookie == "" || settings.AuthSecret == "") {
+			stored, err := h.DB.GetFeedHTTPSettings(settings.FeedID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if stored != nil {
+				if settings.Cookie == "" {
+					settings.Cookie = stored.Cookie
+				}
+				if settings.AuthSecret == "" && settings.AuthType != "" {
+					settings.AuthSecret = stored.AuthSecret
+				}
+			}
+		}
+
+		if err := h.DB.SetFeedHTTPSettings(&settings); err != nil {
+			if err == database.ErrInvalidAuthType {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validateHeaders rejects header names and values that can't be sent in a request
+func validateHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, ": \t\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for header %q", name)
+		}
+	}
+	return nil
+}
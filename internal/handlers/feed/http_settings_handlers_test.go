@@ -0,0 +1,56 @@
+package feed_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	fh "MrRSS/internal/handlers/feed"
+	"MrRSS/internal/models"
+)
+
+func TestHandleFeedHTTPSettings_SecretsAreWriteOnly(t *testing.T) {
+	h := setupHandler(t)
+	id, err := h.DB.AddFeed(&models.Feed{Title: "a", URL: "http://x/1"})
+	if err != nil {
+		t.Fatalf("add feed: %v", err)
+	}
+
+	post := func(body string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		fh.HandleFeedHTTPSettings(h, w, httptest.NewRequest("POST", "/api/feeds/http-settings", strings.NewReader(body)))
+		if w.Code != 200 {
+			t.Fatalf("POST %s: got %d %s", body, w.Code, w.Body.String())
+		}
+	}
+	post(`{"feed_id": 1, "cookie": "session=abc", "auth_type": "bearer", "auth_secret": "token-123"}`)
+
+	w := httptest.NewRecorder()
+	fh.HandleFeedHTTPSettings(h, w, httptest.NewRequest("GET", "/api/feeds/http-settings?id=1", nil))
+	if strings.Contains(w.Body.String(), "session=abc") || strings.Contains(w.Body.String(), "token-123") {
+		t.Fatalf("secrets returned: %s", w.Body.String())
+	}
+	var got fh.HTTPSettingsResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.HasCookie || !got.HasAuthSecret || got.AuthType != "bearer" {
+		t.Errorf("unexpected settings: %+v", got)
+	}
+
+	// Saving what GET returned keeps the stored secrets
+	post(`{"feed_id": 1, "user_agent": "Reader/1.0", "auth_type": "bearer"}`)
+	stored, _ := h.DB.GetFeedHTTPSettings(id)
+	if stored.UserAgent != "Reader/1.0" || stored.Cookie != "session=abc" || stored.AuthSecret != "token-123" {
+		t.Errorf("secrets not kept: %+v", stored)
+	}
+
+	// Clearing the auth type drops the auth secret
+	post(`{"feed_id": 1, "user_agent": "Reader/1.0"}`)
+	stored, _ = h.DB.GetFeedHTTPSettings(id)
+	if stored.Cookie != "session=abc" || stored.AuthSecret != "" {
+		t.Errorf("unexpected settings after clearing the auth type: %+v", stored)
+	}
+}
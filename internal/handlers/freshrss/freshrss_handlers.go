@@ -48,9 +48,7 @@ func HandleSyncFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	serverURL, _ := h.DB.GetSetting("freshrss_server_url")
-	username, _ := h.DB.GetSetting("freshrss_username")
-	password, _ := h.DB.GetEncryptedSetting("freshrss_api_password")
+	provider, serverURL, username, password, _ := h.DB.GetFreshRSSConfig()
 
 	if serverURL == "" || username == "" || password == "" {
 		http.Error(w, "FreshRSS settings incomplete", http.StatusBadRequest)
@@ -58,7 +56,11 @@ func HandleSyncFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create bidirectional sync service
-	syncService := freshrss.NewBidirectionalSyncService(serverURL, username, password, h.DB)
+	syncService, err := freshrss.NewProviderSyncService(provider, serverURL, username, password, h.DB)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	log.Printf("[HandleSyncFeed] Syncing stream: %s", streamID)
 
 	// Perform sync in background
@@ -112,9 +114,7 @@ func HandleSync(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	serverURL, _ := h.DB.GetSetting("freshrss_server_url")
-	username, _ := h.DB.GetSetting("freshrss_username")
-	password, _ := h.DB.GetEncryptedSetting("freshrss_api_password")
+	provider, serverURL, username, password, _ := h.DB.GetFreshRSSConfig()
 
 	if serverURL == "" || username == "" || password == "" {
 		http.Error(w, "FreshRSS settings incomplete", http.StatusBadRequest)
@@ -122,7 +122,11 @@ func HandleSync(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create bidirectional sync service
-	syncService := freshrss.NewBidirectionalSyncService(serverURL, username, password, h.DB)
+	syncService, err := freshrss.NewProviderSyncService(provider, serverURL, username, password, h.DB)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	log.Printf("[HandleSync] Sync service created, starting sync")
 
 	// Perform sync in background
@@ -0,0 +1,297 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+	"MrRSS/internal/notify"
+)
+
+// NotificationTargetResponse is a notification target without its token, which is never
+// sent back
+type NotificationTargetResponse struct {
+	models.NotificationTarget
+	HasToken bool `json:"has_token"`
+}
+
+// HandleNotificationTargets lists all notification targets (GET) or creates a new one (POST).
+// @Summary      List or create notification targets
+// @Description  GET returns all notification targets; their tokens are write-only, GET only tells whether they are set. POST validates and creates a target; it is notified of the articles inserted by later refreshes that match its conditions, which have the shape of rule conditions.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        target  body      models.NotificationTarget  false  "Target to create (POST only)"
+// @Success      200  {array}   NotificationTargetResponse  "List of notification targets without tokens (GET)"
+// @Success      201  {object}  map[string]interface{}  "Created target ID (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid target)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /notifications/targets [get]
+// @Router       /notifications/targets [post]
+func HandleNotificationTargets(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		targets, err := h.DB.GetNotificationTargets()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response := make([]NotificationTargetResponse, len(targets))
+		for i, target := range targets {
+			response[i] = NotificationTargetResponse{NotificationTarget: target, HasToken: target.Token != ""}
+			response[i].Token = ""
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	case http.MethodPost:
+		var target models.NotificationTarget
+		if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := notify.Validate(&target); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id, err := h.DB.CreateNotificationTarget(target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleUpdateNotificationTarget replaces an existing notification target.
+// @Summary      Update a notification target
+// @Description  Validate and replace the settings of a notification target. An empty token keeps the stored one. Articles it already queued stay queued.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        target  body      models.NotificationTarget  true  "Target (id required)"
+// @Success      200  {string}  string  "Target updated successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid target)"
+// @Failure      404  {object}  map[string]string  "Target not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /notifications/targets/update [post]
+func HandleUpdateNotificationTarget(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var target models.NotificationTarget
+	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := keepStoredToken(h, &target); err != nil {
+		writeNotificationError(w, err, "Notification target not found")
+		return
+	}
+	if err := notify.Validate(&target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.UpdateNotificationTarget(target); err != nil {
+		writeNotificationError(w, err, "Notification target not found")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeleteNotificationTarget deletes a notification target.
+// @Summary      Delete a notification target
+// @Description  Delete a notification target by ID, with its queued articles and its dead-letter log
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "Target ID"
+// @Success      200  {string}  string  "Target deleted successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      404  {object}  map[string]string  "Target not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /notifications/targets/delete [post]
+func HandleDeleteNotificationTarget(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteNotificationTarget(id); err != nil {
+		writeNotificationError(w, err, "Notification target not found")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleTestNotification sends a test message to a notification target.
+// @Summary      Send a test notification
+// @Description  Validate a notification target, which doesn't need to be saved, and send it a sample message right away, without batching or retries. Saved targets (with an id) sent with an empty token use the stored one.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        target  body      models.NotificationTarget  true  "Target to test"
+// @Success      200  {string}  string  "Test message delivered"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid target)"
+// @Failure      404  {object}  map[string]string  "Target not found"
+// @Failure      502  {object}  map[string]string  "The target rejected the message, couldn't be reached or isn't on a public address"
+// @Router       /notifications/test [post]
+func HandleTestNotification(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var target models.NotificationTarget
+	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if target.ID != 0 {
+		if err := keepStoredToken(h, &target); err != nil {
+			writeNotificationError(w, err, "Notification target not found")
+			return
+		}
+	}
+	if err := notify.Validate(&target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Notifications.Test(r.Context(), &target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeadNotifications returns the dead-letter log.
+// @Summary      Get failed notifications
+// @Description  Get the notifications that failed every delivery attempt, newest first, for one target or for all targets
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        target_id  query     int64  false  "Target ID (default: all targets)"
+// @Success      200  {array}   models.NotificationDelivery  "Dead notifications"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /notifications/dead-letters [get]
+func HandleDeadNotifications(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	targetID, _ := strconv.ParseInt(r.URL.Query().Get("target_id"), 10, 64)
+	deliveries, err := h.DB.GetDeadNotifications(targetID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// HandleRetryDeadNotification delivers a failed notification again.
+// @Summary      Retry a failed notification
+// @Description  Move a notification from the dead-letter log back to the deliveries, with a fresh set of attempts, and attempt it right away
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "Notification ID"
+// @Success      200  {string}  string  "Notification queued for delivery"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      404  {object}  map[string]string  "Failed notification not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /notifications/dead-letters/retry [post]
+func HandleRetryDeadNotification(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if err := h.DB.RetryDeadNotification(id, now); err != nil {
+		writeNotificationError(w, err, "Failed notification not found")
+		return
+	}
+	go h.Notifications.Flush(context.Background(), now)
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeleteDeadNotification removes a failed notification from the dead-letter log.
+// @Summary      Delete a failed notification
+// @Description  Remove a notification from the dead-letter log without delivering it
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "Notification ID"
+// @Success      200  {string}  string  "Notification deleted"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      404  {object}  map[string]string  "Failed notification not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /notifications/dead-letters/delete [post]
+func HandleDeleteDeadNotification(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteNotificationDelivery(id); err != nil {
+		writeNotificationError(w, err, "Failed notification not found")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// keepStoredToken fills in the stored token of a saved target sent without one. Tokens are
+// never sent to the client, so an empty token means it wasn't changed.
+func keepStoredToken(h *core.Handler, target *models.NotificationTarget) error {
+	if target.Token != "" {
+		return nil
+	}
+	stored, err := h.DB.GetNotificationTarget(target.ID)
+	if err != nil {
+		return err
+	}
+	target.Token = stored.Token
+	return nil
+}
+
+// writeNotificationError maps notification database errors to HTTP status codes
+func writeNotificationError(w http.ResponseWriter, err error, notFound string) {
+	if err == sql.ErrNoRows {
+		http.Error(w, notFound, http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,71 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+	nh "MrRSS/internal/handlers/notify"
+)
+
+func setupHandler(t *testing.T) *core.Handler {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return core.NewHandler(db, nil, nil)
+}
+
+func TestHandleNotificationTargets_TokensAreWriteOnly(t *testing.T) {
+	h := setupHandler(t)
+
+	post := func(url, body string, want int) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", url, strings.NewReader(body))
+		if strings.HasSuffix(url, "/update") {
+			nh.HandleUpdateNotificationTarget(h, w, r)
+		} else {
+			nh.HandleNotificationTargets(h, w, r)
+		}
+		if w.Code != want {
+			t.Fatalf("POST %s %s: got %d %s", url, body, w.Code, w.Body.String())
+		}
+	}
+	post("/api/notifications/targets", `{"name": "Phone", "type": "gotify", "url": "https://gotify.example.com", "token": "app-token"}`, 201)
+
+	w := httptest.NewRecorder()
+	nh.HandleNotificationTargets(h, w, httptest.NewRequest("GET", "/api/notifications/targets", nil))
+	if strings.Contains(w.Body.String(), "app-token") {
+		t.Fatalf("token returned: %s", w.Body.String())
+	}
+	var got []nh.NotificationTargetResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 1 || !got[0].HasToken || got[0].Name != "Phone" {
+		t.Fatalf("unexpected targets: %+v", got)
+	}
+
+	// Saving what GET returned keeps the stored token
+	post("/api/notifications/targets/update", `{"id": 1, "name": "Tablet", "type": "gotify", "url": "https://gotify.example.com"}`, 200)
+	stored, err := h.DB.GetNotificationTarget(1)
+	if err != nil || stored.Name != "Tablet" || stored.Token != "app-token" {
+		t.Errorf("token not kept: %+v, %v", stored, err)
+	}
+
+	post("/api/notifications/targets/update", `{"id": 1, "name": "Tablet", "type": "gotify", "url": "https://gotify.example.com", "token": "new-token"}`, 200)
+	if stored, _ := h.DB.GetNotificationTarget(1); stored.Token != "new-token" {
+		t.Errorf("token not replaced: %+v", stored)
+	}
+
+	post("/api/notifications/targets/update", `{"id": 2, "name": "Tablet", "type": "gotify", "url": "https://gotify.example.com"}`, 404)
+}
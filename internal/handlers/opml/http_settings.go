@@ -0,0 +1,42 @@
+package opml
+
+import (
+	"log"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+// attachHTTPSettings adds each feed's HTTP request settings for export.
+// Cookies and auth secrets are only included when includeSecrets is set,
+// since exported OPML files are often shared.
+func attachHTTPSettings(h *core.Handler, feeds []models.Feed, includeSecrets bool) {
+	all, err := h.DB.GetAllFeedHTTPSettings()
+	if err != nil {
+		log.Printf("Error loading feed HTTP settings for export: %v", err)
+		return
+	}
+
+	for i := range feeds {
+		settings, ok := all[feeds[i].ID]
+		if !ok {
+			continue
+		}
+		if !includeSecrets {
+			settings.Cookie = ""
+			settings.AuthSecret = ""
+		}
+		feeds[i].HTTPSettings = settings
+	}
+}
+
+// importHTTPSettings stores the HTTP request settings of an imported feed
+func importHTTPSettings(h *core.Handler, feedID int64, feed models.Feed) {
+	if feed.HTTPSettings.IsEmpty() {
+		return
+	}
+	feed.HTTPSettings.FeedID = feedID
+	if err := h.DB.SetFeedHTTPSettings(feed.HTTPSettings); err != nil {
+		log.Printf("Error importing HTTP settings for feed %s: %v", feed.Title, err)
+	}
+}
@@ -104,6 +104,7 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			log.Printf("Error importing feed %s: %v", f.Title, err)
 			continue
 		}
+		importHTTPSettings(h, feedID, f)
 		feedIDs = append(feedIDs, feedID)
 	}
 
@@ -119,10 +120,11 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 
 // HandleOPMLExport handles OPML file export.
 // @Summary      Export subscriptions to OPML
-// @Description  Export all local RSS feed subscriptions to an OPML file (excludes FreshRSS feeds)
+// @Description  Export all local RSS feed subscriptions to an OPML file (excludes FreshRSS feeds). Per-feed HTTP settings are exported as namespaced attributes; cookies and auth secrets only with include_secrets=true.
 // @Tags         opml
 // @Accept       json
 // @Produce      text/xml
+// @Param        include_secrets  query  bool  false  "Include feed cookies and auth secrets"
 // @Success      200  {string}  string  "OPML file content"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /opml/export [get]
@@ -143,6 +145,7 @@ func HandleOPMLExport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("[OPML Export] Exporting %d local feeds (excluded %d FreshRSS feeds)",
 		len(localFeeds), len(feeds)-len(localFeeds))
+	attachHTTPSettings(h, localFeeds, r.URL.Query().Get("include_secrets") == "true")
 
 	data, err := opml.Generate(localFeeds)
 	if err != nil {
@@ -287,6 +290,7 @@ func HandleOPMLImportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 			log.Printf("Error importing feed %s: %v", f.Title, err)
 			continue
 		}
+		importHTTPSettings(h, feedID, f)
 		feedIDs = append(feedIDs, feedID)
 	}
 
@@ -347,6 +351,7 @@ func HandleOPMLExportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 
 	log.Printf("[OPML Export Dialog] Exporting %d local feeds (excluded %d FreshRSS feeds)",
 		len(localFeeds), len(feeds)-len(localFeeds))
+	attachHTTPSettings(h, localFeeds, r.URL.Query().Get("include_secrets") == "true")
 
 	// Type assert to *application.App to access Dialog
 	app, ok := h.App.(*application.App)
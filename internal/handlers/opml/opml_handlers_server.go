@@ -67,11 +67,12 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	// Import feeds
 	imported := 0
 	for _, feed := range feeds {
-		_, err := h.DB.AddFeed(&feed)
+		feedID, err := h.DB.AddFeed(&feed)
 		if err != nil {
 			log.Printf("Error importing feed %s: %v", feed.URL, err)
 			continue
 		}
+		importHTTPSettings(h, feedID, feed)
 		imported++
 	}
 
@@ -105,10 +106,11 @@ func HandleOPMLImportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 
 // HandleOPMLExport handles OPML export for server mode.
 // @Summary      Export OPML file
-// @Description  Export all feeds as an OPML file (server mode - direct download)
+// @Description  Export all feeds as an OPML file (server mode - direct download). Per-feed HTTP settings are exported as namespaced attributes; cookies and auth secrets only with include_secrets=true.
 // @Tags         opml
 // @Accept       json
 // @Produce      xml
+// @Param        include_secrets  query  bool  false  "Include feed cookies and auth secrets"
 // @Success      200  {string}  string  "OPML XML file"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /opml/export [get]
@@ -120,6 +122,8 @@ func HandleOPMLExport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	attachHTTPSettings(h, feeds, r.URL.Query().Get("include_secrets") == "true")
+
 	// Generate OPML content
 	data, err := opml.Generate(feeds)
 	if err != nil {
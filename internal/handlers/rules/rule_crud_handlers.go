@@ -0,0 +1,272 @@
+package rules
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/rules"
+)
+
+// HandleRules lists all rules (GET) or creates a new rule (POST).
+// @Summary      List or create rules
+// @Description  GET returns all automation rules in execution order. POST validates and creates a rule; a zero ID lets the server assign one.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      rules.Rule  false  "Rule to create (POST only)"
+// @Success      200  {array}   rules.Rule  "List of rules (GET)"
+// @Success      201  {object}  map[string]interface{}  "Created rule ID (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule)"
+// @Failure      409  {object}  map[string]string  "Rule ID already exists"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules [get]
+// @Router       /rules [post]
+func HandleRules(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.DB.GetRules()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	case http.MethodPost:
+		var rule rules.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := rules.ValidateRule(rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id, err := h.DB.CreateRule(rule)
+		if err != nil {
+			writeRuleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleUpdateRule replaces an existing rule.
+// @Summary      Update a rule
+// @Description  Validate and replace the name, state, position, conditions and actions of a rule. The previous version stays in the rule history.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      rules.Rule  true  "Rule (id required)"
+// @Success      200  {string}  string  "Rule updated successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule)"
+// @Failure      404  {object}  map[string]string  "Rule not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/update [post]
+func HandleUpdateRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var rule rules.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := rules.ValidateRule(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.UpdateRule(rule); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeleteRule deletes a rule.
+// @Summary      Delete a rule
+// @Description  Delete a rule by ID. The deleted rule stays in the rule history and can be restored with a rollback.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "Rule ID"
+// @Success      200  {string}  string  "Rule deleted successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      404  {object}  map[string]string  "Rule not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/delete [post]
+func HandleDeleteRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.DeleteRule(id); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleRuleHistory returns the recorded versions of rules.
+// @Summary      Get rule history
+// @Description  Get the recorded versions of a rule (or of all rules), newest first
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule_id  query     int64  false  "Rule ID (default: all rules)"
+// @Param        limit    query     int    false  "Maximum number of entries (default: 50)"
+// @Success      200  {array}   models.RuleHistoryEntry  "Rule versions"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule_id)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/history [get]
+func HandleRuleHistory(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var ruleID int64
+	if s := r.URL.Query().Get("rule_id"); s != "" {
+		var err error
+		if ruleID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			http.Error(w, "Invalid rule_id parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	entries, err := h.DB.GetRuleHistory(ruleID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// HandleRollbackRule restores a rule to a recorded version.
+// @Summary      Roll back a rule
+// @Description  Restore the rule version recorded in a history entry. Deleted rules are recreated.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "History entry ID"
+// @Success      200  {object}  rules.Rule  "Restored rule"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      404  {object}  map[string]string  "History entry not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/rollback [post]
+func HandleRollbackRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.DB.RollbackRule(id)
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// HandleExportRules exports all rules as a JSON array.
+// @Summary      Export rules
+// @Description  Download all rules as a JSON array that can be imported again
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   rules.Rule  "Rule set"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/export [get]
+func HandleExportRules(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	list, err := h.DB.GetRules()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=mrrss-rules.json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HandleImportRules replaces all rules with an imported rule set.
+// @Summary      Import rules
+// @Description  Validate a JSON array of rules and replace the current rule set with it. Rules are matched by ID; only changed rules are recorded in the history.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rules  body      []rules.Rule  true  "Rule set"
+// @Success      200  {object}  map[string]interface{}  "Import result (count)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/import [post]
+func HandleImportRules(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var list []rules.Rule
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := rules.ValidateRules(list); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.ReplaceRules(list); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"count": len(list)})
+}
+
+// writeRuleError maps rule database errors to HTTP status codes
+func writeRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "Rule not found", http.StatusNotFound)
+	case strings.Contains(err.Error(), "UNIQUE constraint"):
+		http.Error(w, "Rule already exists", http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -52,3 +52,68 @@ func HandleApplyRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewEncoder(w).Encode(response)
 }
+
+// PreviewRequest represents a request to preview a rule
+type PreviewRequest struct {
+	Rule       rules.Rule `json:"rule"`
+	SampleSize int        `json:"sample_size"`
+}
+
+// HandlePreviewRule evaluates a rule against stored articles without applying it
+// @Summary      Preview rule
+// @Description  Dry-run a rule: returns how many stored articles it matches, a sample of them, and near misses with the condition that rejected each one. Only the newest 10000 articles are checked; truncated is set when there are more. Nothing is changed.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        request  body      PreviewRequest  true  "Rule to preview and optional sample size (default: 20)"
+// @Success      200  {object}  rules.PreviewResult  "Preview result"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/preview [post]
+func HandlePreviewRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	engine := rules.NewEngine(h.DB)
+	result, err := engine.PreviewRule(req.Rule, req.SampleSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// HandleRuleStats returns the hit counters of all rules
+// @Summary      Get rule hit counters
+// @Description  Get how many articles each rule has matched (new articles during feed refreshes, stored articles when applied by hand) and when it last matched
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   database.RuleStats  "Hit counters by rule ID"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/stats [get]
+func HandleRuleStats(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := h.DB.GetRuleStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
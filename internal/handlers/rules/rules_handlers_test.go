@@ -2,11 +2,31 @@ package rules
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/feed"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+	"MrRSS/internal/rules"
 )
 
+func setupHandler(t *testing.T) *core.Handler {
+	t.Helper()
+	db, err := database.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return core.NewHandler(db, feed.NewFetcher(db), nil)
+}
+
 func TestHandleApplyRule_MethodNotAllowed(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/rules/apply", nil)
 	rr := httptest.NewRecorder()
@@ -39,3 +59,127 @@ func TestHandleApplyRule_NoActions(t *testing.T) {
 		t.Fatalf("expected %d got %d", http.StatusBadRequest, rr.Code)
 	}
 }
+
+func TestHandlePreviewRule_MethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/rules/preview", nil)
+	rr := httptest.NewRecorder()
+
+	HandlePreviewRule(nil, rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected %d got %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+}
+
+func TestHandlePreviewRule_InvalidJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/rules/preview", bytes.NewReader([]byte("not json")))
+	rr := httptest.NewRecorder()
+
+	HandlePreviewRule(nil, rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleRuleCRUD_MethodNotAllowed(t *testing.T) {
+	handlers := map[string]func(rr *httptest.ResponseRecorder, req *http.Request){
+		"/rules/update":   func(rr *httptest.ResponseRecorder, req *http.Request) { HandleUpdateRule(nil, rr, req) },
+		"/rules/delete":   func(rr *httptest.ResponseRecorder, req *http.Request) { HandleDeleteRule(nil, rr, req) },
+		"/rules/rollback": func(rr *httptest.ResponseRecorder, req *http.Request) { HandleRollbackRule(nil, rr, req) },
+		"/rules/import":   func(rr *httptest.ResponseRecorder, req *http.Request) { HandleImportRules(nil, rr, req) },
+	}
+	for path, handle := range handlers {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+
+		handle(rr, req)
+
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected %d got %d", path, http.StatusMethodNotAllowed, rr.Code)
+		}
+	}
+}
+
+func TestHandleRules_InvalidRule(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/rules", bytes.NewReader([]byte(`{"name":"r","conditions":[{"field":"is_read","value":"maybe"}],"actions":["mark_read"]}`)))
+	rr := httptest.NewRecorder()
+
+	HandleRules(nil, rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleImportRules_InvalidRule(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/rules/import", bytes.NewReader([]byte(`[{"id":1,"name":"r","conditions":[{"field":"article_title","operator":"regex","value":"("}],"actions":["mark_read"]}]`)))
+	rr := httptest.NewRecorder()
+
+	HandleImportRules(nil, rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandlePreviewApplyAndStats(t *testing.T) {
+	h := setupHandler(t)
+
+	feedID, err := h.DB.AddFeed(&models.Feed{Title: "Tech", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	for _, title := range []string{"Go released", "Rust released", "Weekly digest"} {
+		if err := h.DB.SaveArticle(&models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + title}); err != nil {
+			t.Fatalf("SaveArticle error: %v", err)
+		}
+	}
+	rule := models.Rule{
+		Name:       "Releases",
+		Enabled:    true,
+		Conditions: []models.RuleCondition{{Field: "article_title", Operator: "contains", Value: "released"}},
+		Actions:    []string{"favorite"},
+	}
+	rule.ID, err = h.DB.CreateRule(rule)
+	if err != nil {
+		t.Fatalf("CreateRule error: %v", err)
+	}
+
+	body, _ := json.Marshal(PreviewRequest{Rule: rule, SampleSize: 1})
+	rr := httptest.NewRecorder()
+	HandlePreviewRule(h, rr, httptest.NewRequest(http.MethodPost, "/rules/preview", bytes.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("preview: expected %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var preview rules.PreviewResult
+	if err := json.NewDecoder(rr.Body).Decode(&preview); err != nil {
+		t.Fatalf("decode preview: %v", err)
+	}
+	if preview.Checked != 3 || preview.Matched != 2 || len(preview.Samples) != 1 || preview.NearMissCount != 1 {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	if stats, _ := h.DB.GetRuleStats(); len(stats) != 0 {
+		t.Errorf("preview recorded hits: %+v", stats)
+	}
+
+	body, _ = json.Marshal(rule)
+	rr = httptest.NewRecorder()
+	HandleApplyRule(h, rr, httptest.NewRequest(http.MethodPost, "/rules/apply", bytes.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("apply: expected %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if favorites, _ := h.DB.GetArticles("favorites", 0, "", true, 10, 0); len(favorites) != preview.Matched {
+		t.Errorf("got %d favorites, want the %d previewed matches", len(favorites), preview.Matched)
+	}
+
+	rr = httptest.NewRecorder()
+	HandleRuleStats(h, rr, httptest.NewRequest(http.MethodGet, "/rules/stats", nil))
+	var stats []database.RuleStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].RuleID != rule.ID || stats[0].HitCount != int64(preview.Matched) || stats[0].LastMatchedAt == nil {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
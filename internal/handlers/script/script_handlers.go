@@ -47,6 +47,7 @@ func HandleGetScriptsDir(h *core.Handler, w http.ResponseWriter, r *http.Request
 // @Produce      json
 // @Success      200  {object}  map[string]string  "Open status (status, scripts_dir)"
 // @Failure      400  {object}  map[string]string  "Unsupported platform"
+// @Failure      403  {object}  map[string]string  "Not available in server mode"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /scripts/dir/open [post]
 func HandleOpenScriptsDir(h *core.Handler, w http.ResponseWriter, r *http.Request) {
@@ -55,6 +56,12 @@ func HandleOpenScriptsDir(h *core.Handler, w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// The file explorer would open on the server, not on the user's machine
+	if utils.IsServerMode() {
+		http.Error(w, "Not available in server mode", http.StatusForbidden)
+		return
+	}
+
 	scriptsDir, err := utils.GetScriptsDir()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
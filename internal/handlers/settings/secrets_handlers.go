@@ -0,0 +1,108 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"MrRSS/internal/crypto"
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+)
+
+// HandleSecretsStatus reports how stored secrets are encrypted.
+// @Summary      Get secrets status
+// @Description  Whether API keys and passwords are encrypted with a master passphrase instead of the machine key, and whether it still has to be entered
+// @Tags         settings
+// @Produce      json
+// @Success      200  {object}  database.SecretsStatus  "Secrets status"
+// @Router       /secrets/status [get]
+func HandleSecretsStatus(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.DB.SecretsStatus())
+}
+
+// HandleUnlockSecrets enters the master passphrase after a start.
+// @Summary      Unlock secrets
+// @Description  Enter the master passphrase so stored secrets can be decrypted. Can also be done at startup with $MRRSS_MASTER_PASSPHRASE.
+// @Tags         settings
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Master passphrase (passphrase)"
+// @Success      200  {object}  database.SecretsStatus  "Secrets status"
+// @Failure      400  {string}  string  "No master passphrase is set"
+// @Failure      403  {string}  string  "Wrong passphrase"
+// @Router       /secrets/unlock [post]
+func HandleUnlockSecrets(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Passphrase string `json:"passphrase"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.UnlockSecrets(req.Passphrase); err != nil {
+		writeSecretsError(w, err)
+		return
+	}
+	log.Println("[Secrets] Unlocked with the master passphrase")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.DB.SecretsStatus())
+}
+
+// HandleMasterPassphrase sets, changes or removes the master passphrase.
+// @Summary      Set the master passphrase
+// @Description  Set, change or (with an empty passphrase) remove the master passphrase. All stored secrets are re-encrypted with the new key; secrets that can't be decrypted are listed and must be entered again.
+// @Tags         settings
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "Current and new passphrase (current_passphrase, passphrase)"
+// @Success      200  {object}  database.RekeyReport  "Re-encrypted secrets"
+// @Failure      400  {string}  string  "Bad request"
+// @Failure      403  {string}  string  "Wrong current passphrase"
+// @Router       /secrets/passphrase [post]
+func HandleMasterPassphrase(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		CurrentPassphrase string `json:"current_passphrase"`
+		Passphrase        string `json:"passphrase"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.DB.SetMasterPassphrase(req.CurrentPassphrase, req.Passphrase)
+	if err != nil {
+		writeSecretsError(w, err)
+		return
+	}
+	log.Printf("[Secrets] Master passphrase %s, re-encrypted %d secrets (%d unreadable)",
+		map[bool]string{true: "set", false: "removed"}[req.Passphrase != ""], report.Reencrypted, len(report.Unreadable))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// writeSecretsError answers a failed passphrase operation
+func writeSecretsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, crypto.ErrWrongPassphrase):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, database.ErrNoMasterPassphrase):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[Secrets] %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -56,32 +56,49 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		aiTranslationPrompt := safeGetSetting(h, "ai_translation_prompt")
 		aiUsageLimit := safeGetSetting(h, "ai_usage_limit")
 		aiUsageTokens := safeGetSetting(h, "ai_usage_tokens")
+		autoBackupEnabled := safeGetSetting(h, "auto_backup_enabled")
+		autoBackupIntervalHours := safeGetSetting(h, "auto_backup_interval_hours")
+		autoBackupKeep := safeGetSetting(h, "auto_backup_keep")
 		autoCleanupEnabled := safeGetSetting(h, "auto_cleanup_enabled")
 		autoShowAllContent := safeGetSetting(h, "auto_show_all_content")
 		autoUpdate := safeGetSetting(h, "auto_update")
 		baiduAppId := safeGetSetting(h, "baidu_app_id")
 		baiduSecretKey := safeGetEncryptedSetting(h, "baidu_secret_key")
 		closeToTray := safeGetSetting(h, "close_to_tray")
+		collapseDuplicateArticles := safeGetSetting(h, "collapse_duplicate_articles")
 		customCssFile := safeGetSetting(h, "custom_css_file")
+		deadFeedDays := safeGetSetting(h, "dead_feed_days")
 		deeplApiKey := safeGetEncryptedSetting(h, "deepl_api_key")
 		deeplEndpoint := safeGetSetting(h, "deepl_endpoint")
 		defaultViewMode := safeGetSetting(h, "default_view_mode")
+		feverEnabled := safeGetSetting(h, "fever_enabled")
+		feverPassword := safeGetEncryptedSetting(h, "fever_password")
+		feverUsername := safeGetSetting(h, "fever_username")
 		freshrssApiPassword := safeGetEncryptedSetting(h, "freshrss_api_password")
 		freshrssAutoSyncInterval := safeGetSetting(h, "freshrss_auto_sync_interval")
 		freshrssEnabled := safeGetSetting(h, "freshrss_enabled")
 		freshrssLastSyncTime := safeGetSetting(h, "freshrss_last_sync_time")
+		freshrssProvider := safeGetSetting(h, "freshrss_provider")
 		freshrssServerUrl := safeGetSetting(h, "freshrss_server_url")
 		freshrssSyncOnStartup := safeGetSetting(h, "freshrss_sync_on_startup")
 		freshrssUsername := safeGetSetting(h, "freshrss_username")
 		fullTextFetchEnabled := safeGetSetting(h, "full_text_fetch_enabled")
 		googleTranslateEndpoint := safeGetSetting(h, "google_translate_endpoint")
+		greaderEnabled := safeGetSetting(h, "greader_enabled")
+		greaderPassword := safeGetEncryptedSetting(h, "greader_password")
+		greaderUsername := safeGetSetting(h, "greader_username")
+		hostRequestIntervalMs := safeGetSetting(h, "host_request_interval_ms")
 		hoverMarkAsRead := safeGetSetting(h, "hover_mark_as_read")
 		imageGalleryEnabled := safeGetSetting(h, "image_gallery_enabled")
 		language := safeGetSetting(h, "language")
+		lastAutoBackup := safeGetSetting(h, "last_auto_backup")
 		lastGlobalRefresh := safeGetSetting(h, "last_global_refresh")
 		lastNetworkTest := safeGetSetting(h, "last_network_test")
+		markDuplicatesRead := safeGetSetting(h, "mark_duplicates_read")
+		markUpdatedArticlesUnread := safeGetSetting(h, "mark_updated_articles_unread")
 		maxArticleAgeDays := safeGetSetting(h, "max_article_age_days")
 		maxCacheSizeMb := safeGetSetting(h, "max_cache_size_mb")
+		maxConcurrentPerHost := safeGetSetting(h, "max_concurrent_per_host")
 		maxConcurrentRefreshes := safeGetSetting(h, "max_concurrent_refreshes")
 		mediaCacheEnabled := safeGetSetting(h, "media_cache_enabled")
 		mediaCacheMaxAgeDays := safeGetSetting(h, "media_cache_max_age_days")
@@ -105,10 +122,17 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		rsshubEnabled := safeGetSetting(h, "rsshub_enabled")
 		rsshubEndpoint := safeGetSetting(h, "rsshub_endpoint")
 		rules := safeGetSetting(h, "rules")
+		rulesWebhookUrl := safeGetSetting(h, "rules_webhook_url")
 		shortcuts := safeGetSetting(h, "shortcuts")
 		shortcutsEnabled := safeGetSetting(h, "shortcuts_enabled")
 		showArticlePreviewImages := safeGetSetting(h, "show_article_preview_images")
 		showHiddenArticles := safeGetSetting(h, "show_hidden_articles")
+		smtpFrom := safeGetSetting(h, "smtp_from")
+		smtpHost := safeGetSetting(h, "smtp_host")
+		smtpPassword := safeGetEncryptedSetting(h, "smtp_password")
+		smtpPort := safeGetSetting(h, "smtp_port")
+		smtpSecurity := safeGetSetting(h, "smtp_security")
+		smtpUsername := safeGetEncryptedSetting(h, "smtp_username")
 		startupOnBoot := safeGetSetting(h, "startup_on_boot")
 		summaryEnabled := safeGetSetting(h, "summary_enabled")
 		summaryLength := safeGetSetting(h, "summary_length")
@@ -119,169 +143,220 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		translationEnabled := safeGetSetting(h, "translation_enabled")
 		translationProvider := safeGetSetting(h, "translation_provider")
 		updateInterval := safeGetSetting(h, "update_interval")
+		websubCallbackUrl := safeGetSetting(h, "websub_callback_url")
 		windowHeight := safeGetSetting(h, "window_height")
 		windowMaximized := safeGetSetting(h, "window_maximized")
 		windowWidth := safeGetSetting(h, "window_width")
 		windowX := safeGetSetting(h, "window_x")
 		windowY := safeGetSetting(h, "window_y")
 		json.NewEncoder(w).Encode(map[string]string{
-			"ai_api_key":                  aiApiKey,
-			"ai_chat_enabled":             aiChatEnabled,
-			"ai_custom_headers":           aiCustomHeaders,
-			"ai_endpoint":                 aiEndpoint,
-			"ai_model":                    aiModel,
-			"ai_summary_prompt":           aiSummaryPrompt,
-			"ai_translation_prompt":       aiTranslationPrompt,
-			"ai_usage_limit":              aiUsageLimit,
-			"ai_usage_tokens":             aiUsageTokens,
-			"auto_cleanup_enabled":        autoCleanupEnabled,
-			"auto_show_all_content":       autoShowAllContent,
-			"auto_update":                 autoUpdate,
-			"baidu_app_id":                baiduAppId,
-			"baidu_secret_key":            baiduSecretKey,
-			"close_to_tray":               closeToTray,
-			"custom_css_file":             customCssFile,
-			"deepl_api_key":               deeplApiKey,
-			"deepl_endpoint":              deeplEndpoint,
-			"default_view_mode":           defaultViewMode,
-			"freshrss_api_password":       freshrssApiPassword,
-			"freshrss_auto_sync_interval": freshrssAutoSyncInterval,
-			"freshrss_enabled":            freshrssEnabled,
-			"freshrss_last_sync_time":     freshrssLastSyncTime,
-			"freshrss_server_url":         freshrssServerUrl,
-			"freshrss_sync_on_startup":    freshrssSyncOnStartup,
-			"freshrss_username":           freshrssUsername,
-			"full_text_fetch_enabled":     fullTextFetchEnabled,
-			"google_translate_endpoint":   googleTranslateEndpoint,
-			"hover_mark_as_read":          hoverMarkAsRead,
-			"image_gallery_enabled":       imageGalleryEnabled,
-			"language":                    language,
-			"last_global_refresh":         lastGlobalRefresh,
-			"last_network_test":           lastNetworkTest,
-			"max_article_age_days":        maxArticleAgeDays,
-			"max_cache_size_mb":           maxCacheSizeMb,
-			"max_concurrent_refreshes":    maxConcurrentRefreshes,
-			"media_cache_enabled":         mediaCacheEnabled,
-			"media_cache_max_age_days":    mediaCacheMaxAgeDays,
-			"media_cache_max_size_mb":     mediaCacheMaxSizeMb,
-			"media_proxy_fallback":        mediaProxyFallback,
-			"network_bandwidth_mbps":      networkBandwidthMbps,
-			"network_latency_ms":          networkLatencyMs,
-			"network_speed":               networkSpeed,
-			"obsidian_enabled":            obsidianEnabled,
-			"obsidian_vault":              obsidianVault,
-			"obsidian_vault_path":         obsidianVaultPath,
-			"proxy_enabled":               proxyEnabled,
-			"proxy_host":                  proxyHost,
-			"proxy_password":              proxyPassword,
-			"proxy_port":                  proxyPort,
-			"proxy_type":                  proxyType,
-			"proxy_username":              proxyUsername,
-			"refresh_mode":                refreshMode,
-			"retry_timeout_seconds":       retryTimeoutSeconds,
-			"rsshub_api_key":              rsshubApiKey,
-			"rsshub_enabled":              rsshubEnabled,
-			"rsshub_endpoint":             rsshubEndpoint,
-			"rules":                       rules,
-			"shortcuts":                   shortcuts,
-			"shortcuts_enabled":           shortcutsEnabled,
-			"show_article_preview_images": showArticlePreviewImages,
-			"show_hidden_articles":        showHiddenArticles,
-			"startup_on_boot":             startupOnBoot,
-			"summary_enabled":             summaryEnabled,
-			"summary_length":              summaryLength,
-			"summary_provider":            summaryProvider,
-			"summary_trigger_mode":        summaryTriggerMode,
-			"target_language":             targetLanguage,
-			"theme":                       theme,
-			"translation_enabled":         translationEnabled,
-			"translation_provider":        translationProvider,
-			"update_interval":             updateInterval,
-			"window_height":               windowHeight,
-			"window_maximized":            windowMaximized,
-			"window_width":                windowWidth,
-			"window_x":                    windowX,
-			"window_y":                    windowY,
+			"ai_api_key":                   aiApiKey,
+			"ai_chat_enabled":              aiChatEnabled,
+			"ai_custom_headers":            aiCustomHeaders,
+			"ai_endpoint":                  aiEndpoint,
+			"ai_model":                     aiModel,
+			"ai_summary_prompt":            aiSummaryPrompt,
+			"ai_translation_prompt":        aiTranslationPrompt,
+			"ai_usage_limit":               aiUsageLimit,
+			"ai_usage_tokens":              aiUsageTokens,
+			"auto_backup_enabled":          autoBackupEnabled,
+			"auto_backup_interval_hours":   autoBackupIntervalHours,
+			"auto_backup_keep":             autoBackupKeep,
+			"auto_cleanup_enabled":         autoCleanupEnabled,
+			"auto_show_all_content":        autoShowAllContent,
+			"auto_update":                  autoUpdate,
+			"baidu_app_id":                 baiduAppId,
+			"baidu_secret_key":             baiduSecretKey,
+			"close_to_tray":                closeToTray,
+			"collapse_duplicate_articles":  collapseDuplicateArticles,
+			"custom_css_file":              customCssFile,
+			"dead_feed_days":               deadFeedDays,
+			"deepl_api_key":                deeplApiKey,
+			"deepl_endpoint":               deeplEndpoint,
+			"default_view_mode":            defaultViewMode,
+			"fever_enabled":                feverEnabled,
+			"fever_password":               feverPassword,
+			"fever_username":               feverUsername,
+			"freshrss_api_password":        freshrssApiPassword,
+			"freshrss_auto_sync_interval":  freshrssAutoSyncInterval,
+			"freshrss_enabled":             freshrssEnabled,
+			"freshrss_last_sync_time":      freshrssLastSyncTime,
+			"freshrss_provider":            freshrssProvider,
+			"freshrss_server_url":          freshrssServerUrl,
+			"freshrss_sync_on_startup":     freshrssSyncOnStartup,
+			"freshrss_username":            freshrssUsername,
+			"full_text_fetch_enabled":      fullTextFetchEnabled,
+			"google_translate_endpoint":    googleTranslateEndpoint,
+			"greader_enabled":              greaderEnabled,
+			"greader_password":             greaderPassword,
+			"greader_username":             greaderUsername,
+			"host_request_interval_ms":     hostRequestIntervalMs,
+			"hover_mark_as_read":           hoverMarkAsRead,
+			"image_gallery_enabled":        imageGalleryEnabled,
+			"language":                     language,
+			"last_auto_backup":             lastAutoBackup,
+			"last_global_refresh":          lastGlobalRefresh,
+			"last_network_test":            lastNetworkTest,
+			"mark_duplicates_read":         markDuplicatesRead,
+			"mark_updated_articles_unread": markUpdatedArticlesUnread,
+			"max_article_age_days":         maxArticleAgeDays,
+			"max_cache_size_mb":            maxCacheSizeMb,
+			"max_concurrent_per_host":      maxConcurrentPerHost,
+			"max_concurrent_refreshes":     maxConcurrentRefreshes,
+			"media_cache_enabled":          mediaCacheEnabled,
+			"media_cache_max_age_days":     mediaCacheMaxAgeDays,
+			"media_cache_max_size_mb":      mediaCacheMaxSizeMb,
+			"media_proxy_fallback":         mediaProxyFallback,
+			"network_bandwidth_mbps":       networkBandwidthMbps,
+			"network_latency_ms":           networkLatencyMs,
+			"network_speed":                networkSpeed,
+			"obsidian_enabled":             obsidianEnabled,
+			"obsidian_vault":               obsidianVault,
+			"obsidian_vault_path":          obsidianVaultPath,
+			"proxy_enabled":                proxyEnabled,
+			"proxy_host":                   proxyHost,
+			"proxy_password":               proxyPassword,
+			"proxy_port":                   proxyPort,
+			"proxy_type":                   proxyType,
+			"proxy_username":               proxyUsername,
+			"refresh_mode":                 refreshMode,
+			"retry_timeout_seconds":        retryTimeoutSeconds,
+			"rsshub_api_key":               rsshubApiKey,
+			"rsshub_enabled":               rsshubEnabled,
+			"rsshub_endpoint":              rsshubEndpoint,
+			"rules":                        rules,
+			"rules_webhook_url":            rulesWebhookUrl,
+			"shortcuts":                    shortcuts,
+			"shortcuts_enabled":            shortcutsEnabled,
+			"show_article_preview_images":  showArticlePreviewImages,
+			"show_hidden_articles":         showHiddenArticles,
+			"smtp_from":                    smtpFrom,
+			"smtp_host":                    smtpHost,
+			"smtp_password":                smtpPassword,
+			"smtp_port":                    smtpPort,
+			"smtp_security":                smtpSecurity,
+			"smtp_username":                smtpUsername,
+			"startup_on_boot":              startupOnBoot,
+			"summary_enabled":              summaryEnabled,
+			"summary_length":               summaryLength,
+			"summary_provider":             summaryProvider,
+			"summary_trigger_mode":         summaryTriggerMode,
+			"target_language":              targetLanguage,
+			"theme":                        theme,
+			"translation_enabled":          translationEnabled,
+			"translation_provider":         translationProvider,
+			"update_interval":              updateInterval,
+			"websub_callback_url":          websubCallbackUrl,
+			"window_height":                windowHeight,
+			"window_maximized":             windowMaximized,
+			"window_width":                 windowWidth,
+			"window_x":                     windowX,
+			"window_y":                     windowY,
 		})
 	case http.MethodPost:
 		var req struct {
-			AIAPIKey                 string `json:"ai_api_key"`
-			AIChatEnabled            string `json:"ai_chat_enabled"`
-			AICustomHeaders          string `json:"ai_custom_headers"`
-			AIEndpoint               string `json:"ai_endpoint"`
-			AIModel                  string `json:"ai_model"`
-			AISummaryPrompt          string `json:"ai_summary_prompt"`
-			AITranslationPrompt      string `json:"ai_translation_prompt"`
-			AIUsageLimit             string `json:"ai_usage_limit"`
-			AIUsageTokens            string `json:"ai_usage_tokens"`
-			AutoCleanupEnabled       string `json:"auto_cleanup_enabled"`
-			AutoShowAllContent       string `json:"auto_show_all_content"`
-			AutoUpdate               string `json:"auto_update"`
-			BaiduAppId               string `json:"baidu_app_id"`
-			BaiduSecretKey           string `json:"baidu_secret_key"`
-			CloseToTray              string `json:"close_to_tray"`
-			CustomCssFile            string `json:"custom_css_file"`
-			DeeplAPIKey              string `json:"deepl_api_key"`
-			DeeplEndpoint            string `json:"deepl_endpoint"`
-			DefaultViewMode          string `json:"default_view_mode"`
-			FreshRSSAPIPassword      string `json:"freshrss_api_password"`
-			FreshRSSAutoSyncInterval string `json:"freshrss_auto_sync_interval"`
-			FreshRSSEnabled          string `json:"freshrss_enabled"`
-			FreshRSSLastSyncTime     string `json:"freshrss_last_sync_time"`
-			FreshRSSServerUrl        string `json:"freshrss_server_url"`
-			FreshRSSSyncOnStartup    string `json:"freshrss_sync_on_startup"`
-			FreshRSSUsername         string `json:"freshrss_username"`
-			FullTextFetchEnabled     string `json:"full_text_fetch_enabled"`
-			GoogleTranslateEndpoint  string `json:"google_translate_endpoint"`
-			HoverMarkAsRead          string `json:"hover_mark_as_read"`
-			ImageGalleryEnabled      string `json:"image_gallery_enabled"`
-			Language                 string `json:"language"`
-			LastGlobalRefresh        string `json:"last_global_refresh"`
-			LastNetworkTest          string `json:"last_network_test"`
-			MaxArticleAgeDays        string `json:"max_article_age_days"`
-			MaxCacheSizeMb           string `json:"max_cache_size_mb"`
-			MaxConcurrentRefreshes   string `json:"max_concurrent_refreshes"`
-			MediaCacheEnabled        string `json:"media_cache_enabled"`
-			MediaCacheMaxAgeDays     string `json:"media_cache_max_age_days"`
-			MediaCacheMaxSizeMb      string `json:"media_cache_max_size_mb"`
-			MediaProxyFallback       string `json:"media_proxy_fallback"`
-			NetworkBandwidthMbps     string `json:"network_bandwidth_mbps"`
-			NetworkLatencyMs         string `json:"network_latency_ms"`
-			NetworkSpeed             string `json:"network_speed"`
-			ObsidianEnabled          string `json:"obsidian_enabled"`
-			ObsidianVault            string `json:"obsidian_vault"`
-			ObsidianVaultPath        string `json:"obsidian_vault_path"`
-			ProxyEnabled             string `json:"proxy_enabled"`
-			ProxyHost                string `json:"proxy_host"`
-			ProxyPassword            string `json:"proxy_password"`
-			ProxyPort                string `json:"proxy_port"`
-			ProxyType                string `json:"proxy_type"`
-			ProxyUsername            string `json:"proxy_username"`
-			RefreshMode              string `json:"refresh_mode"`
-			RetryTimeoutSeconds      string `json:"retry_timeout_seconds"`
-			RsshubAPIKey             string `json:"rsshub_api_key"`
-			RsshubEnabled            string `json:"rsshub_enabled"`
-			RsshubEndpoint           string `json:"rsshub_endpoint"`
-			Rules                    string `json:"rules"`
-			Shortcuts                string `json:"shortcuts"`
-			ShortcutsEnabled         string `json:"shortcuts_enabled"`
-			ShowArticlePreviewImages string `json:"show_article_preview_images"`
-			ShowHiddenArticles       string `json:"show_hidden_articles"`
-			StartupOnBoot            string `json:"startup_on_boot"`
-			SummaryEnabled           string `json:"summary_enabled"`
-			SummaryLength            string `json:"summary_length"`
-			SummaryProvider          string `json:"summary_provider"`
-			SummaryTriggerMode       string `json:"summary_trigger_mode"`
-			TargetLanguage           string `json:"target_language"`
-			Theme                    string `json:"theme"`
-			TranslationEnabled       string `json:"translation_enabled"`
-			TranslationProvider      string `json:"translation_provider"`
-			UpdateInterval           string `json:"update_interval"`
-			WindowHeight             string `json:"window_height"`
-			WindowMaximized          string `json:"window_maximized"`
-			WindowWidth              string `json:"window_width"`
-			WindowX                  string `json:"window_x"`
-			WindowY                  string `json:"window_y"`
+			AIAPIKey                  string `json:"ai_api_key"`
+			AIChatEnabled             string `json:"ai_chat_enabled"`
+			AICustomHeaders           string `json:"ai_custom_headers"`
+			AIEndpoint                string `json:"ai_endpoint"`
+			AIModel                   string `json:"ai_model"`
+			AISummaryPrompt           string `json:"ai_summary_prompt"`
+			AITranslationPrompt       string `json:"ai_translation_prompt"`
+			AIUsageLimit              string `json:"ai_usage_limit"`
+			AIUsageTokens             string `json:"ai_usage_tokens"`
+			AutoBackupEnabled         string `json:"auto_backup_enabled"`
+			AutoBackupIntervalHours   string `json:"auto_backup_interval_hours"`
+			AutoBackupKeep            string `json:"auto_backup_keep"`
+			AutoCleanupEnabled        string `json:"auto_cleanup_enabled"`
+			AutoShowAllContent        string `json:"auto_show_all_content"`
+			AutoUpdate                string `json:"auto_update"`
+			BaiduAppId                string `json:"baidu_app_id"`
+			BaiduSecretKey            string `json:"baidu_secret_key"`
+			CloseToTray               string `json:"close_to_tray"`
+			CollapseDuplicateArticles string `json:"collapse_duplicate_articles"`
+			CustomCssFile             string `json:"custom_css_file"`
+			DeadFeedDays              string `json:"dead_feed_days"`
+			DeeplAPIKey               string `json:"deepl_api_key"`
+			DeeplEndpoint             string `json:"deepl_endpoint"`
+			DefaultViewMode           string `json:"default_view_mode"`
+			FeverEnabled              string `json:"fever_enabled"`
+			FeverPassword             string `json:"fever_password"`
+			FeverUsername             string `json:"fever_username"`
+			FreshRSSAPIPassword       string `json:"freshrss_api_password"`
+			FreshRSSAutoSyncInterval  string `json:"freshrss_auto_sync_interval"`
+			FreshRSSEnabled           string `json:"freshrss_enabled"`
+			FreshRSSLastSyncTime      string `json:"freshrss_last_sync_time"`
+			FreshRSSProvider          string `json:"freshrss_provider"`
+			FreshRSSServerUrl         string `json:"freshrss_server_url"`
+			FreshRSSSyncOnStartup     string `json:"freshrss_sync_on_startup"`
+			FreshRSSUsername          string `json:"freshrss_username"`
+			FullTextFetchEnabled      string `json:"full_text_fetch_enabled"`
+			GoogleTranslateEndpoint   string `json:"google_translate_endpoint"`
+			GreaderEnabled            string `json:"greader_enabled"`
+			GreaderPassword           string `json:"greader_password"`
+			GreaderUsername           string `json:"greader_username"`
+			HostRequestIntervalMs     string `json:"host_request_interval_ms"`
+			HoverMarkAsRead           string `json:"hover_mark_as_read"`
+			ImageGalleryEnabled       string `json:"image_gallery_enabled"`
+			Language                  string `json:"language"`
+			LastAutoBackup            string `json:"last_auto_backup"`
+			LastGlobalRefresh         string `json:"last_global_refresh"`
+			LastNetworkTest           string `json:"last_network_test"`
+			MarkDuplicatesRead        string `json:"mark_duplicates_read"`
+			MarkUpdatedArticlesUnread string `json:"mark_updated_articles_unread"`
+			MaxArticleAgeDays         string `json:"max_article_age_days"`
+			MaxCacheSizeMb            string `json:"max_cache_size_mb"`
+			MaxConcurrentPerHost      string `json:"max_concurrent_per_host"`
+			MaxConcurrentRefreshes    string `json:"max_concurrent_refreshes"`
+			MediaCacheEnabled         string `json:"media_cache_enabled"`
+			MediaCacheMaxAgeDays      string `json:"media_cache_max_age_days"`
+			MediaCacheMaxSizeMb       string `json:"media_cache_max_size_mb"`
+			MediaProxyFallback        string `json:"media_proxy_fallback"`
+			NetworkBandwidthMbps      string `json:"network_bandwidth_mbps"`
+			NetworkLatencyMs          string `json:"network_latency_ms"`
+			NetworkSpeed              string `json:"network_speed"`
+			ObsidianEnabled           string `json:"obsidian_enabled"`
+			ObsidianVault             string `json:"obsidian_vault"`
+			ObsidianVaultPath         string `json:"obsidian_vault_path"`
+			ProxyEnabled              string `json:"proxy_enabled"`
+			ProxyHost                 string `json:"proxy_host"`
+			ProxyPassword             string `json:"proxy_password"`
+			ProxyPort                 string `json:"proxy_port"`
+			ProxyType                 string `json:"proxy_type"`
+			ProxyUsername             string `json:"proxy_username"`
+			RefreshMode               string `json:"refresh_mode"`
+			RetryTimeoutSeconds       string `json:"retry_timeout_seconds"`
+			RsshubAPIKey              string `json:"rsshub_api_key"`
+			RsshubEnabled             string `json:"rsshub_enabled"`
+			RsshubEndpoint            string `json:"rsshub_endpoint"`
+			Rules                     string `json:"rules"`
+			RulesWebhookUrl           string `json:"rules_webhook_url"`
+			Shortcuts                 string `json:"shortcuts"`
+			ShortcutsEnabled          string `json:"shortcuts_enabled"`
+			ShowArticlePreviewImages  string `json:"show_article_preview_images"`
+			ShowHiddenArticles        string `json:"show_hidden_articles"`
+			SmtpFrom                  string `json:"smtp_from"`
+			SmtpHost                  string `json:"smtp_host"`
+			SmtpPassword              string `json:"smtp_password"`
+			SmtpPort                  string `json:"smtp_port"`
+			SmtpSecurity              string `json:"smtp_security"`
+			SmtpUsername              string `json:"smtp_username"`
+			StartupOnBoot             string `json:"startup_on_boot"`
+			SummaryEnabled            string `json:"summary_enabled"`
+			SummaryLength             string `json:"summary_length"`
+			SummaryProvider           string `json:"summary_provider"`
+			SummaryTriggerMode        string `json:"summary_trigger_mode"`
+			TargetLanguage            string `json:"target_language"`
+			Theme                     string `json:"theme"`
+			TranslationEnabled        string `json:"translation_enabled"`
+			TranslationProvider       string `json:"translation_provider"`
+			UpdateInterval            string `json:"update_interval"`
+			WebsubCallbackUrl         string `json:"websub_callback_url"`
+			WindowHeight              string `json:"window_height"`
+			WindowMaximized           string `json:"window_maximized"`
+			WindowWidth               string `json:"window_width"`
+			WindowX                   string `json:"window_x"`
+			WindowY                   string `json:"window_y"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -325,6 +400,18 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("ai_usage_tokens", req.AIUsageTokens)
 		}
 
+		if req.AutoBackupEnabled != "" {
+			h.DB.SetSetting("auto_backup_enabled", req.AutoBackupEnabled)
+		}
+
+		if req.AutoBackupIntervalHours != "" {
+			h.DB.SetSetting("auto_backup_interval_hours", req.AutoBackupIntervalHours)
+		}
+
+		if req.AutoBackupKeep != "" {
+			h.DB.SetSetting("auto_backup_keep", req.AutoBackupKeep)
+		}
+
 		if req.AutoCleanupEnabled != "" {
 			h.DB.SetSetting("auto_cleanup_enabled", req.AutoCleanupEnabled)
 		}
@@ -351,10 +438,18 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("close_to_tray", req.CloseToTray)
 		}
 
+		if req.CollapseDuplicateArticles != "" {
+			h.DB.SetSetting("collapse_duplicate_articles", req.CollapseDuplicateArticles)
+		}
+
 		if req.CustomCssFile != "" {
 			h.DB.SetSetting("custom_css_file", req.CustomCssFile)
 		}
 
+		if req.DeadFeedDays != "" {
+			h.DB.SetSetting("dead_feed_days", req.DeadFeedDays)
+		}
+
 		if err := h.DB.SetEncryptedSetting("deepl_api_key", req.DeeplAPIKey); err != nil {
 			log.Printf("Failed to save deepl_api_key: %v", err)
 			http.Error(w, "Failed to save deepl_api_key", http.StatusInternalServerError)
@@ -369,6 +464,20 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("default_view_mode", req.DefaultViewMode)
 		}
 
+		if req.FeverEnabled != "" {
+			h.DB.SetSetting("fever_enabled", req.FeverEnabled)
+		}
+
+		if err := h.DB.SetEncryptedSetting("fever_password", req.FeverPassword); err != nil {
+			log.Printf("Failed to save fever_password: %v", err)
+			http.Error(w, "Failed to save fever_password", http.StatusInternalServerError)
+			return
+		}
+
+		if req.FeverUsername != "" {
+			h.DB.SetSetting("fever_username", req.FeverUsername)
+		}
+
 		if err := h.DB.SetEncryptedSetting("freshrss_api_password", req.FreshRSSAPIPassword); err != nil {
 			log.Printf("Failed to save freshrss_api_password: %v", err)
 			http.Error(w, "Failed to save freshrss_api_password", http.StatusInternalServerError)
@@ -387,6 +496,10 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("freshrss_last_sync_time", req.FreshRSSLastSyncTime)
 		}
 
+		if req.FreshRSSProvider != "" {
+			h.DB.SetSetting("freshrss_provider", req.FreshRSSProvider)
+		}
+
 		if req.FreshRSSServerUrl != "" {
 			h.DB.SetSetting("freshrss_server_url", req.FreshRSSServerUrl)
 		}
@@ -407,6 +520,24 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("google_translate_endpoint", req.GoogleTranslateEndpoint)
 		}
 
+		if req.GreaderEnabled != "" {
+			h.DB.SetSetting("greader_enabled", req.GreaderEnabled)
+		}
+
+		if err := h.DB.SetEncryptedSetting("greader_password", req.GreaderPassword); err != nil {
+			log.Printf("Failed to save greader_password: %v", err)
+			http.Error(w, "Failed to save greader_password", http.StatusInternalServerError)
+			return
+		}
+
+		if req.GreaderUsername != "" {
+			h.DB.SetSetting("greader_username", req.GreaderUsername)
+		}
+
+		if req.HostRequestIntervalMs != "" {
+			h.DB.SetSetting("host_request_interval_ms", req.HostRequestIntervalMs)
+		}
+
 		if req.HoverMarkAsRead != "" {
 			h.DB.SetSetting("hover_mark_as_read", req.HoverMarkAsRead)
 		}
@@ -419,6 +550,10 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("language", req.Language)
 		}
 
+		if req.LastAutoBackup != "" {
+			h.DB.SetSetting("last_auto_backup", req.LastAutoBackup)
+		}
+
 		if req.LastGlobalRefresh != "" {
 			h.DB.SetSetting("last_global_refresh", req.LastGlobalRefresh)
 		}
@@ -427,6 +562,14 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("last_network_test", req.LastNetworkTest)
 		}
 
+		if req.MarkDuplicatesRead != "" {
+			h.DB.SetSetting("mark_duplicates_read", req.MarkDuplicatesRead)
+		}
+
+		if req.MarkUpdatedArticlesUnread != "" {
+			h.DB.SetSetting("mark_updated_articles_unread", req.MarkUpdatedArticlesUnread)
+		}
+
 		if req.MaxArticleAgeDays != "" {
 			h.DB.SetSetting("max_article_age_days", req.MaxArticleAgeDays)
 		}
@@ -435,6 +578,10 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("max_cache_size_mb", req.MaxCacheSizeMb)
 		}
 
+		if req.MaxConcurrentPerHost != "" {
+			h.DB.SetSetting("max_concurrent_per_host", req.MaxConcurrentPerHost)
+		}
+
 		if req.MaxConcurrentRefreshes != "" {
 			h.DB.SetSetting("max_concurrent_refreshes", req.MaxConcurrentRefreshes)
 		}
@@ -533,6 +680,10 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("rules", req.Rules)
 		}
 
+		if req.RulesWebhookUrl != "" {
+			h.DB.SetSetting("rules_webhook_url", req.RulesWebhookUrl)
+		}
+
 		if req.Shortcuts != "" {
 			h.DB.SetSetting("shortcuts", req.Shortcuts)
 		}
@@ -549,6 +700,34 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("show_hidden_articles", req.ShowHiddenArticles)
 		}
 
+		if req.SmtpFrom != "" {
+			h.DB.SetSetting("smtp_from", req.SmtpFrom)
+		}
+
+		if req.SmtpHost != "" {
+			h.DB.SetSetting("smtp_host", req.SmtpHost)
+		}
+
+		if err := h.DB.SetEncryptedSetting("smtp_password", req.SmtpPassword); err != nil {
+			log.Printf("Failed to save smtp_password: %v", err)
+			http.Error(w, "Failed to save smtp_password", http.StatusInternalServerError)
+			return
+		}
+
+		if req.SmtpPort != "" {
+			h.DB.SetSetting("smtp_port", req.SmtpPort)
+		}
+
+		if req.SmtpSecurity != "" {
+			h.DB.SetSetting("smtp_security", req.SmtpSecurity)
+		}
+
+		if err := h.DB.SetEncryptedSetting("smtp_username", req.SmtpUsername); err != nil {
+			log.Printf("Failed to save smtp_username: %v", err)
+			http.Error(w, "Failed to save smtp_username", http.StatusInternalServerError)
+			return
+		}
+
 		if req.StartupOnBoot != "" {
 			h.DB.SetSetting("startup_on_boot", req.StartupOnBoot)
 		}
@@ -589,6 +768,10 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			h.DB.SetSetting("update_interval", req.UpdateInterval)
 		}
 
+		if req.WebsubCallbackUrl != "" {
+			h.DB.SetSetting("websub_callback_url", req.WebsubCallbackUrl)
+		}
+
 		if req.WindowHeight != "" {
 			h.DB.SetSetting("window_height", req.WindowHeight)
 		}
@@ -0,0 +1,159 @@
+package tags
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/handlers/core"
+)
+
+// TagRequest represents a request to create or update a tag
+type TagRequest struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Position int    `json:"position"`
+}
+
+// HandleTags lists all tags (GET) or creates a new tag (POST).
+// @Summary      List or create tags
+// @Description  GET returns all user tags with article counts. POST creates a new tag.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        request  body      TagRequest  false  "Tag to create (POST only)"
+// @Success      200  {array}   models.Tag  "List of tags (GET)"
+// @Success      201  {object}  map[string]interface{}  "Created tag ID (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid tag name)"
+// @Failure      409  {object}  map[string]string  "Tag already exists"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /tags [get]
+// @Router       /tags [post]
+func HandleTags(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tags, err := h.DB.GetTags()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tags)
+	case http.MethodPost:
+		var req TagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		existing, err := h.DB.GetTagByName(req.Name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existing != nil {
+			http.Error(w, "Tag already exists", http.StatusConflict)
+			return
+		}
+
+		id, err := h.DB.CreateTag(req.Name, req.Color)
+		if err != nil {
+			writeTagError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleUpdateTag renames a tag or changes its color and position.
+// @Summary      Update a tag
+// @Description  Update the name, color and position of a user tag. When FreshRSS sync is enabled, a renamed tag's label is renamed on its articles with the next sync.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        request  body      TagRequest  true  "Tag update (id required)"
+// @Success      200  {string}  string  "Tag updated successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid tag name)"
+// @Failure      404  {object}  map[string]string  "Tag not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /tags/update [post]
+func HandleUpdateTag(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	syncReqs, err := h.DB.UpdateTagWithSync(req.ID, req.Name, req.Color, req.Position)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	// Renamed labels are pushed to FreshRSS with the next sync
+	if err := h.DB.EnqueueSyncRequests(syncReqs); err != nil {
+		log.Printf("Error queueing label rename of tag %d: %v", req.ID, err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeleteTag deletes a tag and removes it from all articles.
+// @Summary      Delete a tag
+// @Description  Delete a user tag by ID; articles keep everything except the tag. When FreshRSS sync is enabled, its label is removed from its articles with the next sync.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id   query      int64  true  "Tag ID"
+// @Success      200  {string}  string  "Tag deleted successfully"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid id)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /tags/delete [post]
+func HandleDeleteTag(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		return
+	}
+
+	syncReqs, err := h.DB.DeleteTagWithSync(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.DB.EnqueueSyncRequests(syncReqs); err != nil {
+		log.Printf("Error queueing label removal of tag %d: %v", id, err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeTagError maps tag database errors to HTTP status codes
+func writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case err == database.ErrInvalidTagName:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err == sql.ErrNoRows:
+		http.Error(w, "Tag not found", http.StatusNotFound)
+	case strings.Contains(err.Error(), "UNIQUE constraint"):
+		http.Error(w, "Tag already exists", http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/utils"
 )
 
 func TestHandleDownloadUpdate_MethodNotAllowed(t *testing.T) {
@@ -43,3 +44,18 @@ func TestHandleDownloadUpdate_InvalidAssetName(t *testing.T) {
 		t.Fatalf("expected 400 for invalid asset name, got %d", rr.Code)
 	}
 }
+
+func TestHandleDownloadUpdate_ServerMode(t *testing.T) {
+	utils.SetServerMode(true)
+	defer utils.SetServerMode(false)
+
+	body := bytes.NewReader([]byte(`{"download_url":"https://github.com/WCY-dt/MrRSS/releases/download/v1/app.zip","asset_name":"app.zip"}`))
+	req := httptest.NewRequest(http.MethodPost, "/update/download", body)
+	rr := httptest.NewRecorder()
+
+	HandleDownloadUpdate(&core.Handler{}, rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 in server mode, got %d", rr.Code)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/utils"
 )
 
 // HandleDownloadUpdate downloads the update file.
@@ -21,6 +22,7 @@ import (
 // @Param        request  body      object  true  "Download request (download_url, asset_name)"
 // @Success      200  {object}  map[string]interface{}  "Download success (success, file_path, total_bytes, bytes_written)"
 // @Failure      400  {object}  map[string]string  "Bad request (invalid URL or asset name)"
+// @Failure      403  {object}  map[string]string  "Not available in server mode"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /update/download [post]
 func HandleDownloadUpdate(h *core.Handler, w http.ResponseWriter, r *http.Request) {
@@ -29,6 +31,12 @@ func HandleDownloadUpdate(h *core.Handler, w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Updates are only installed by the desktop app, see HandleInstallUpdate
+	if utils.IsServerMode() {
+		http.Error(w, "Not available in server mode", http.StatusForbidden)
+		return
+	}
+
 	var req struct {
 		DownloadURL string `json:"download_url"`
 		AssetName   string `json:"asset_name"`
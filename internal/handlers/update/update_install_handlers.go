@@ -29,6 +29,7 @@ import (
 // @Param        request  body      object  true  "Install request (file_path)"
 // @Success      200  {object}  map[string]interface{}  "Installation started (success, message)"
 // @Failure      400  {object}  map[string]string  "Bad request (invalid file path or type)"
+// @Failure      403  {object}  map[string]string  "Not available in server mode"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /update/install [post]
 func HandleInstallUpdate(h *core.Handler, w http.ResponseWriter, r *http.Request) {
@@ -37,6 +38,12 @@ func HandleInstallUpdate(h *core.Handler, w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Installing would replace the server binary from a request; server deployments update their image instead
+	if utils.IsServerMode() {
+		http.Error(w, "Not available in server mode", http.StatusForbidden)
+		return
+	}
+
 	var req struct {
 		FilePath string `json:"file_path"`
 	}
@@ -0,0 +1,117 @@
+package websub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"MrRSS/internal/feed"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/websub"
+)
+
+// maxPushBodySize limits the size of content pushed by a hub (10 MB)
+const maxPushBodySize = 10 << 20
+
+// HandleCallback is the WebSub subscriber callback for a feed.
+// GET requests are intent verifications from the hub, POST requests deliver new content.
+// @Summary      WebSub callback
+// @Description  Receives subscription verifications (GET) and content distribution (POST) from WebSub hubs
+// @Tags         websub
+// @Accept       xml
+// @Produce      plain
+// @Param        feedID             path   int64   true   "Feed ID"
+// @Param        hub.mode           query  string  false  "Verification mode (subscribe, unsubscribe, denied)"
+// @Param        hub.topic          query  string  false  "Topic URL being verified"
+// @Param        hub.challenge      query  string  false  "Challenge to echo back"
+// @Param        hub.lease_seconds  query  int     false  "Granted lease duration in seconds"
+// @Success      200  {string}  string  "Challenge (verification) or empty body"
+// @Success      202  {string}  string  "Content accepted"
+// @Failure      400  {string}  string  "Bad request"
+// @Failure      404  {string}  string  "Unknown subscription"
+// @Failure      410  {string}  string  "Feed no longer subscribed"
+// @Router       /websub/{feedID} [get]
+// @Router       /websub/{feedID} [post]
+func HandleCallback(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	// The feed ID is the last path segment, also for callbacks routed per user
+	feedID, err := strconv.ParseInt(path.Base(r.URL.Path), 10, 64)
+	if err != nil || feedID <= 0 {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		handleVerification(h, w, r, feedID)
+	case http.MethodPost:
+		handleContent(h, w, r, feedID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleVerification answers the hub's verification of intent.
+func handleVerification(h *core.Handler, w http.ResponseWriter, r *http.Request, feedID int64) {
+	query := r.URL.Query()
+	mode := query.Get("hub.mode")
+	topic := query.Get("hub.topic")
+
+	if mode == websub.ModeDenied {
+		h.Fetcher.DenyWebSubSubscription(feedID, topic, query.Get("hub.reason"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	challenge := query.Get("hub.challenge")
+	if challenge == "" {
+		http.Error(w, "Missing hub.challenge", http.StatusBadRequest)
+		return
+	}
+
+	leaseSeconds, _ := strconv.Atoi(query.Get("hub.lease_seconds"))
+	if !h.Fetcher.VerifyWebSubIntent(feedID, mode, topic, leaseSeconds) {
+		http.Error(w, "Unknown subscription", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, challenge)
+}
+
+// handleContent accepts a content distribution request and processes it in the background.
+func handleContent(h *core.Handler, w http.ResponseWriter, r *http.Request, feedID int64) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPushBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	signature := r.Header.Get("X-Hub-Signature")
+
+	// Check the subscription synchronously so hubs learn about removed feeds
+	sub, err := h.DB.GetWebSubSubscription(feedID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if sub == nil {
+		http.Error(w, "Feed no longer subscribed", http.StatusGone)
+		return
+	}
+
+	// The spec requires a 2xx response even when the signature does not match,
+	// so invalid payloads are only logged and dropped
+	go func() {
+		err := h.Fetcher.HandleWebSubContent(context.Background(), feedID, body, signature)
+		if err != nil && !errors.Is(err, feed.ErrWebSubUnknownFeed) {
+			log.Printf("WebSub: failed to process pushed content for feed %d: %v", feedID, err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -45,29 +45,335 @@ type Feed struct {
 	// FreshRSS integration
 	IsFreshRSSSource bool   `json:"is_freshrss_source"` // Whether this feed is from FreshRSS sync
 	FreshRSSStreamID string `json:"freshrss_stream_id"` // FreshRSS stream ID (e.g., "feed/http://...")
+	// HTTP conditional GET support
+	ETag             string `json:"-"`                  // ETag response header from the last successful fetch
+	LastModified     string `json:"-"`                  // Last-Modified response header from the last successful fetch
+	FetchCount       int    `json:"fetch_count"`        // Number of recent conditional fetches (halved periodically)
+	NotModifiedCount int    `json:"not_modified_count"` // Number of recent fetches answered with 304 Not Modified
+	// Failure backoff and health
+	ConsecutiveFailures int        `json:"consecutive_failures"`       // Failed refreshes since the last success
+	NextEligibleAt      *time.Time `json:"next_eligible_at,omitempty"` // Scheduled refreshes skip the feed until this time
+	FailingSince        *time.Time `json:"failing_since,omitempty"`    // First failure since the last success
+	IsDead              bool       `json:"is_dead"`                    // Failing for longer than the dead feed threshold
 	// Statistics
 	LatestArticleTime *time.Time `json:"latest_article_time,omitempty"` // Latest article publish time
 	ArticlesPerMonth  float64    `json:"articles_per_month,omitempty"`  // Average articles per month (last 90 days / 3)
 	LastUpdateStatus  string     `json:"last_update_status,omitempty"`  // Last update status ("success" or "failed")
+	// Request customization, only populated for OPML import/export
+	HTTPSettings *FeedHTTPSettings `json:"-"`
 }
 
 type Article struct {
-	ID                    int64     `json:"id"`
-	FeedID                int64     `json:"feed_id"`
-	Title                 string    `json:"title"`
-	URL                   string    `json:"url"`
-	ImageURL              string    `json:"image_url"`
-	AudioURL              string    `json:"audio_url"`
-	VideoURL              string    `json:"video_url"` // YouTube video URL for embedded player
-	PublishedAt           time.Time `json:"published_at"`
-	HasValidPublishedTime bool      `json:"-"` // Internal field, not serialized
-	IsRead                bool      `json:"is_read"`
-	IsFavorite            bool      `json:"is_favorite"`
-	IsHidden              bool      `json:"is_hidden"`
-	IsReadLater           bool      `json:"is_read_later"`
-	FeedTitle             string    `json:"feed_title,omitempty"` // Joined field
-	TranslatedTitle       string    `json:"translated_title"`
-	Summary               string    `json:"summary"`          // Cached AI-generated summary
-	UniqueID              string    `json:"unique_id"`        // Unique identifier for deduplication (title+feed_id+published_date)
-	FreshRSSItemID        string    `json:"freshrss_item_id"` // FreshRSS/Google Reader item ID for API operations
+	ID                    int64      `json:"id"`
+	FeedID                int64      `json:"feed_id"`
+	Title                 string     `json:"title"`
+	URL                   string     `json:"url"`
+	ImageURL              string     `json:"image_url"`
+	AudioURL              string     `json:"audio_url"`
+	VideoURL              string     `json:"video_url"` // YouTube video URL for embedded player
+	PublishedAt           time.Time  `json:"published_at"`
+	HasValidPublishedTime bool       `json:"-"` // Internal field, not serialized
+	IsRead                bool       `json:"is_read"`
+	IsFavorite            bool       `json:"is_favorite"`
+	IsHidden              bool       `json:"is_hidden"`
+	IsReadLater           bool       `json:"is_read_later"`
+	FeedTitle             string     `json:"feed_title,omitempty"` // Joined field
+	TranslatedTitle       string     `json:"translated_title"`
+	Summary               string     `json:"summary"`                   // Cached AI-generated summary
+	UniqueID              string     `json:"unique_id"`                 // Unique identifier for deduplication (title+feed_id+published_date)
+	FreshRSSItemID        string     `json:"freshrss_item_id"`          // FreshRSS/Google Reader item ID for API operations
+	Author                string     `json:"author"`                    // Item author(s) reported by the feed
+	Categories            []string   `json:"categories,omitempty"`      // Item categories reported by the feed
+	Tags                  []string   `json:"tags,omitempty"`            // User-defined tags (labels)
+	UpdatedAt             *time.Time `json:"updated_at,omitempty"`      // When the publisher last changed the title or content
+	ClusterID             int64      `json:"cluster_id,omitempty"`      // Cluster of duplicate stories across feeds, 0 if none
+	DuplicateCount        int        `json:"duplicate_count,omitempty"` // Number of other articles in the cluster
+}
+
+// ArticleRevision is a version of an article's title and content as published in its feed
+type ArticleRevision struct {
+	ID        int64     `json:"id"`
+	ArticleID int64     `json:"article_id"`
+	Revision  int       `json:"revision"` // 1 for the original version
+	Title     string    `json:"title"`
+	Content   string    `json:"content,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Tag is a user-defined label that can be attached to articles
+type Tag struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Color        string    `json:"color"`
+	Position     int       `json:"position"`
+	ArticleCount int       `json:"article_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// FilterCondition is a single condition of the advanced article filter, also used to
+// select the articles of email digests
+type FilterCondition struct {
+	ID       int64    `json:"id"`
+	Logic    string   `json:"logic"`    // "and", "or" (null for first condition)
+	Negate   bool     `json:"negate"`   // NOT modifier for this condition
+	Field    string   `json:"field"`    // "feed_name", "feed_category", "article_title", "article_author", "article_tag", "published_after", "published_before", "is_read", "is_favorite", ...
+	Operator string   `json:"operator"` // "contains", "exact", "regex" (null for date fields and multi-select)
+	Value    string   `json:"value"`    // Single value for text/date fields
+	Values   []string `json:"values"`   // Multiple values for feed_name, feed_category and article_tag
+}
+
+// RuleCondition is a single condition of an automation rule
+type RuleCondition struct {
+	ID       int64    `json:"id"`
+	Logic    string   `json:"logic"`    // "and", "or" (null for first condition)
+	Negate   bool     `json:"negate"`   // NOT modifier for this condition
+	Field    string   `json:"field"`    // "feed_name", "feed_category", "article_title", "article_content", "article_url", "article_summary", "article_author", "article_tag", etc.
+	Operator string   `json:"operator"` // Text fields: "contains", "exact", "starts_with", "word", "regex"
+	Value    string   `json:"value"`    // Single value for text/date fields
+	Values   []string `json:"values"`   // Multiple values for feed_name, feed_category and article_tag
+}
+
+// Rule is an automation rule applied to new and existing articles
+type Rule struct {
+	ID         int64           `json:"id"`
+	Name       string          `json:"name"`
+	Enabled    bool            `json:"enabled"`
+	Conditions []RuleCondition `json:"conditions"`
+	Actions    []string        `json:"actions"`  // "favorite", "unfavorite", "hide", "unhide", "mark_read", "mark_unread", "add_tag:<name>", "ai_summary", "translate_title", "fetch_full_text", "webhook", "delete"
+	Position   int             `json:"position"` // Execution order (0 = first)
+}
+
+// RuleHistoryEntry is a recorded version of a rule.
+// For "delete" entries Rule holds the rule as it was before it was deleted.
+type RuleHistoryEntry struct {
+	ID        int64     `json:"id"`
+	RuleID    int64     `json:"rule_id"`
+	Action    string    `json:"action"` // "create", "update", "delete" or "rollback"
+	Rule      Rule      `json:"rule"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FeedHTTPSettings customizes the HTTP requests made for a feed
+type FeedHTTPSettings struct {
+	FeedID       int64             `json:"feed_id"`
+	UserAgent    string            `json:"user_agent"`              // Overrides the default browser User-Agent
+	Headers      map[string]string `json:"headers"`                 // Extra request headers
+	Cookie       string            `json:"cookie,omitempty"`        // Cookie header value (encrypted at rest)
+	AuthType     string            `json:"auth_type"`               // "", "basic" or "bearer"
+	AuthUsername string            `json:"auth_username,omitempty"` // Username for basic auth
+	AuthSecret   string            `json:"auth_secret,omitempty"`   // Basic auth password or bearer token (encrypted at rest)
+}
+
+// IsEmpty reports whether the settings change nothing about a request
+func (s *FeedHTTPSettings) IsEmpty() bool {
+	return s == nil || (s.UserAgent == "" && len(s.Headers) == 0 && s.Cookie == "" && s.AuthType == "")
+}
+
+// Full-text modes of a feed
+const (
+	FullTextModeDefault = ""        // follows the global full_text_fetch_enabled setting
+	FullTextModeNever   = "never"   // never fetches the full text
+	FullTextModeOnOpen  = "on_open" // fetches the full text when an article is opened
+	FullTextModeEager   = "eager"   // fetches the full text of new articles on refresh
+)
+
+// FeedFullTextSettings customizes the full-text extraction of a feed's articles
+type FeedFullTextSettings struct {
+	FeedID          int64    `json:"feed_id"`
+	Mode            string   `json:"mode"`             // "", "never", "on_open" or "eager"
+	ContentSelector string   `json:"content_selector"` // CSS or XPath selector of the article body; readability picks it when empty
+	StripSelectors  []string `json:"strip_selectors"`  // CSS or XPath selectors of elements removed before extraction
+}
+
+// IsEmpty reports whether the settings change nothing about full-text extraction
+func (s *FeedFullTextSettings) IsEmpty() bool {
+	return s == nil || (s.Mode == FullTextModeDefault && s.ContentSelector == "" && len(s.StripSelectors) == 0)
+}
+
+// Types of newsletter feeds
+const (
+	FeedTypeEmail     = "email"      // reads newsletters from an IMAP mailbox
+	FeedTypeEmailList = "email_list" // one newsletter of a split mailbox, filled when the mailbox feed is refreshed
+)
+
+// Fields and operators of email filters
+const (
+	EmailFilterSender  = "sender"  // address and name of the sender
+	EmailFilterSubject = "subject" // subject of the message
+	EmailFilterListID  = "list_id" // List-Id header of mailing lists and newsletters
+
+	EmailFilterContains = "contains"
+	EmailFilterExact    = "exact"
+	EmailFilterRegex    = "regex"
+)
+
+// What happens on the server to messages that were turned into articles
+const (
+	EmailProcessedKeep     = ""          // leaves them as they are
+	EmailProcessedMarkRead = "mark_read" // flags them as read
+	EmailProcessedMove     = "move"      // moves them to another folder
+)
+
+// EmailFilter selects the messages of a newsletter feed by sender, subject or List-Id.
+// Matching is case-insensitive.
+type EmailFilter struct {
+	Field    string `json:"field"`    // "sender", "subject" or "list_id"
+	Operator string `json:"operator"` // "contains", "exact" or "regex"
+	Value    string `json:"value"`
+	Exclude  bool   `json:"exclude"` // Skips matching messages instead of keeping only matching ones
+}
+
+// FeedEmailSettings customizes how a newsletter feed reads its mailbox
+type FeedEmailSettings struct {
+	FeedID          int64         `json:"feed_id"`
+	Idle            bool          `json:"idle"`             // Keeps an IMAP IDLE connection open so new messages arrive immediately
+	Filters         []EmailFilter `json:"filters"`          // Messages must match an include filter, if any, and no exclude filter
+	SplitByList     bool          `json:"split_by_list"`    // Adds one feed per newsletter, keyed by the List-Id header
+	ProcessedAction string        `json:"processed_action"` // "", "mark_read" or "move"
+	MoveFolder      string        `json:"move_folder"`      // Destination of the "move" action
+}
+
+// IsEmpty reports whether the settings change nothing about reading the mailbox
+func (s *FeedEmailSettings) IsEmpty() bool {
+	return s == nil || (!s.Idle && len(s.Filters) == 0 && !s.SplitByList && s.ProcessedAction == EmailProcessedKeep)
+}
+
+// Digest schedules
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// Digest send states
+const (
+	DigestSendSent   = "sent"
+	DigestSendFailed = "failed"
+)
+
+// Digest is an email of the articles matching its conditions, sent on a schedule
+type Digest struct {
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Enabled     bool              `json:"enabled"`
+	Schedule    string            `json:"schedule"`     // "daily" or "weekly"
+	Weekday     int               `json:"weekday"`      // 0 = Sunday, weekly digests only
+	Hour        int               `json:"hour"`         // Local hour (0-23) the digest is sent at
+	Recipients  []string          `json:"recipients"`   // Email addresses
+	Conditions  []FilterCondition `json:"conditions"`   // Empty matches all articles
+	MaxArticles int               `json:"max_articles"` // 0 uses the default
+	AISummary   bool              `json:"ai_summary"`   // Add AI summaries of the articles
+	LastRunAt   *time.Time        `json:"last_run_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// DigestSend records a digest email. Articles of successful sends are not sent again.
+type DigestSend struct {
+	ID           int64     `json:"id"`
+	DigestID     int64     `json:"digest_id"`
+	SentAt       time.Time `json:"sent_at"`
+	Recipients   []string  `json:"recipients"`
+	ArticleIDs   []int64   `json:"article_ids"`
+	ArticleCount int       `json:"article_count"`
+	Status       string    `json:"status"` // "sent" or "failed"
+	Error        string    `json:"error,omitempty"`
+}
+
+// Notification target types
+const (
+	NotifyWebhook = "webhook" // JSON built from the target's template
+	NotifySlack   = "slack"   // Slack incoming webhook
+	NotifyDiscord = "discord" // Discord webhook
+	NotifyNtfy    = "ntfy"    // ntfy topic URL
+	NotifyGotify  = "gotify"  // Gotify server with an application token
+	NotifyMatrix  = "matrix"  // Matrix homeserver with an access token and room
+)
+
+// Notification delivery states
+const (
+	NotificationPending = "pending" // Waiting for its first attempt or a retry
+	NotificationDead    = "dead"    // Gave up retrying, kept in the dead-letter log
+)
+
+// NotificationTarget receives a message when new articles match its conditions
+type NotificationTarget struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Enabled     bool            `json:"enabled"`
+	Type        string          `json:"type"`
+	URL         string          `json:"url"`          // Webhook URL, ntfy topic URL, Gotify server or Matrix homeserver
+	Token       string          `json:"token"`        // Bearer token (webhook, ntfy), Gotify app token or Matrix access token
+	Room        string          `json:"room"`         // Matrix room ID
+	Template    string          `json:"template"`     // JSON template of generic webhooks, empty for the default body
+	Conditions  []RuleCondition `json:"conditions"`   // Empty matches all new articles
+	BatchWindow int             `json:"batch_window"` // Seconds new articles are collected into one message
+	MinInterval int             `json:"min_interval"` // Minimum seconds between two messages
+	MaxArticles int             `json:"max_articles"` // Articles per message, 0 uses the default
+	LastSentAt  *time.Time      `json:"last_sent_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// NotificationDelivery is a message to a notification target that is waiting to be
+// delivered, or that failed too often and was moved to the dead-letter log
+type NotificationDelivery struct {
+	ID            int64     `json:"id"`
+	TargetID      int64     `json:"target_id"`
+	ArticleIDs    []int64   `json:"article_ids"`
+	Status        string    `json:"status"` // "pending" or "dead"
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// FetchLogEntry records a single refresh attempt of a feed
+type FetchLogEntry struct {
+	ID           int64     `json:"id"`
+	FeedID       int64     `json:"feed_id"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	DurationMs   int64     `json:"duration_ms"`
+	HTTPStatus   int       `json:"http_status"`   // 0 if no HTTP response was received
+	Bytes        int64     `json:"bytes"`         // Response body size
+	NewItems     int       `json:"new_items"`     // Articles added by this refresh
+	UpdatedItems int       `json:"updated_items"` // Existing articles changed by this refresh
+	ErrorClass   string    `json:"error_class"`   // "" on success, else dns, tls, timeout, http, network, parse, xpath, script or other
+	Error        string    `json:"error,omitempty"`
+	Reason       string    `json:"reason"` // Why the refresh ran, e.g. "scheduled_global"
+}
+
+// FeedHealth summarizes a feed's recent refresh attempts
+type FeedHealth struct {
+	FeedID              int64           `json:"feed_id"`
+	Attempts            int             `json:"attempts"`             // Attempts in the fetch log
+	Successes           int             `json:"successes"`            // Successful attempts in the fetch log
+	SuccessRatio        float64         `json:"success_ratio"`        // Successes / attempts (0 without attempts)
+	MeanLatencyMs       float64         `json:"mean_latency_ms"`      // Mean attempt duration
+	ConsecutiveFailures int             `json:"consecutive_failures"` // Failed attempts since the last success
+	LastSuccessAt       *time.Time      `json:"last_success_at,omitempty"`
+	FailingSince        *time.Time      `json:"failing_since,omitempty"` // First failure of the current streak
+	IsDead              bool            `json:"is_dead"`                 // Failing for longer than the dead feed threshold
+	ErrorClasses        map[string]int  `json:"error_classes"`           // Failed attempts by error class
+	Recent              []FetchLogEntry `json:"recent"`                  // Latest attempts, newest first
+}
+
+// AuthToken is a login session or API token of the server build
+type AuthToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Kind       string     `json:"kind"` // "session" (cookie login) or "api" (bearer token)
+	Name       string     `json:"name"` // Token name, or the user agent of a session
+	CSRFToken  string     `json:"-"`    // Required with unsafe requests of cookie sessions
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil if the token never expires
+	Current    bool       `json:"current"`              // Whether the token authenticated the listing request
+}
+
+// User is an account of the server build. Each user has a database of their own with their
+// subscriptions, article state, rules, chat sessions, statistics and settings.
+type User struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	IsAdmin   bool      `json:"is_admin"` // Admins manage the other users
+	DBFile    string    `json:"-"`        // Database file relative to the main database; "" for the main database itself
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,307 @@
+// Package notify sends messages about new articles to webhooks, chat services and push
+// servers. Matching articles are queued per target and delivered in batches, failed
+// deliveries are retried and end up in a dead-letter log.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/rules"
+	"MrRSS/internal/utils"
+)
+
+const (
+	// defaultMaxArticles limits the messages of targets that don't set a maximum
+	defaultMaxArticles = 20
+	// maxArticlesLimit is the largest allowed maximum
+	maxArticlesLimit = 100
+	// maxWaitSeconds is the longest allowed batch window and minimum interval
+	maxWaitSeconds = 24 * 60 * 60
+	// maxAttempts is the number of attempts before a delivery is moved to the dead-letter log
+	maxAttempts = 5
+	// requestTimeout limits a single delivery attempt
+	requestTimeout = 15 * time.Second
+)
+
+// retryDelays are the waits before the second and later attempts of a delivery
+var retryDelays = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour}
+
+// ErrInvalidTarget is wrapped by the errors of Validate
+var ErrInvalidTarget = errors.New("invalid notification target")
+
+// Dispatcher queues new articles for the notification targets they match and delivers them
+type Dispatcher struct {
+	db       *database.DB
+	client   *http.Client
+	flushing sync.Mutex     // Held while messages are delivered, so flushes don't overlap
+	flushes  sync.WaitGroup // Flushes started by Enqueue
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewDispatcher creates a notification dispatcher. Targets are user-supplied URLs, so
+// messages are only delivered to public addresses.
+func NewDispatcher(db *database.DB) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		db:     db,
+		client: utils.NewPublicHTTPClient(requestTimeout),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Stop cancels the deliveries started by Enqueue and waits for them to finish, so the
+// database can be closed. Cancelled deliveries are retried after the next start.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.flushes.Wait()
+}
+
+// Validate checks and normalizes a notification target before it is stored
+func Validate(target *models.NotificationTarget) error {
+	target.Name = strings.TrimSpace(target.Name)
+	if target.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTarget)
+	}
+
+	target.Type = strings.ToLower(strings.TrimSpace(target.Type))
+	switch target.Type {
+	case models.NotifyWebhook, models.NotifySlack, models.NotifyDiscord, models.NotifyNtfy:
+	case models.NotifyGotify:
+		if target.Token == "" {
+			return fmt.Errorf("%w: Gotify needs an application token", ErrInvalidTarget)
+		}
+	case models.NotifyMatrix:
+		target.Room = strings.TrimSpace(target.Room)
+		if target.Token == "" || target.Room == "" {
+			return fmt.Errorf("%w: Matrix needs an access token and a room", ErrInvalidTarget)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidTarget, target.Type)
+	}
+
+	target.URL = strings.TrimSpace(target.URL)
+	u, err := url.Parse(target.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: URL must be an http or https URL", ErrInvalidTarget)
+	}
+
+	if target.Type != models.NotifyWebhook {
+		target.Template = ""
+	} else if target.Template != "" {
+		if _, err := renderWebhook(target, sampleArticles()); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+		}
+	}
+
+	if target.BatchWindow < 0 || target.BatchWindow > maxWaitSeconds || target.MinInterval < 0 || target.MinInterval > maxWaitSeconds {
+		return fmt.Errorf("%w: batch window and minimum interval must be between 0 and %d seconds", ErrInvalidTarget, maxWaitSeconds)
+	}
+	if target.MaxArticles < 0 || target.MaxArticles > maxArticlesLimit {
+		return fmt.Errorf("%w: at most %d articles per message", ErrInvalidTarget, maxArticlesLimit)
+	}
+	if err := rules.ValidateConditions(target.Conditions); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	return nil
+}
+
+// Enqueue queues newly saved articles for the enabled targets whose conditions they match
+// and starts delivering the batches that are ready in the background, so slow targets
+// don't hold up the refresh. Like for rules, contents optionally holds the content of the
+// articles.
+func (d *Dispatcher) Enqueue(articles []models.Article, contents map[int64]string) {
+	if len(articles) == 0 {
+		return
+	}
+	targets, err := d.db.GetNotificationTargets()
+	if err != nil {
+		log.Printf("Error getting notification targets: %v", err)
+		return
+	}
+
+	var matcher *rules.Matcher
+	now := time.Now()
+	queued := false
+	for _, target := range targets {
+		if !target.Enabled {
+			continue
+		}
+		if matcher == nil {
+			if matcher, err = rules.NewMatcher(d.db, contents); err != nil {
+				log.Printf("Error preparing notification conditions: %v", err)
+				return
+			}
+		}
+
+		var ids []int64
+		for _, article := range articles {
+			if matcher.Matches(article, target.Conditions) {
+				ids = append(ids, article.ID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := d.db.QueueNotifications(target.ID, ids, now); err != nil {
+			log.Printf("Error queueing notifications for %q: %v", target.Name, err)
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		d.flushes.Add(1)
+		go func() {
+			defer d.flushes.Done()
+			d.Flush(d.ctx, now)
+		}()
+	}
+}
+
+// Flush turns the queues whose batch window and minimum interval have passed into
+// deliveries, and attempts the deliveries that are due. If a flush is already running,
+// the next one picks up what is left.
+func (d *Dispatcher) Flush(ctx context.Context, now time.Time) {
+	if !d.flushing.TryLock() {
+		return
+	}
+	defer d.flushing.Unlock()
+
+	targets, err := d.db.GetNotificationTargets()
+	if err != nil {
+		log.Printf("Error getting notification targets: %v", err)
+		return
+	}
+	byID := make(map[int64]*models.NotificationTarget, len(targets))
+	for i := range targets {
+		target := &targets[i]
+		byID[target.ID] = target
+		if target.Enabled {
+			d.batch(target, now)
+		}
+	}
+
+	deliveries, err := d.db.GetDueNotificationDeliveries(now)
+	if err != nil {
+		log.Printf("Error getting notification deliveries: %v", err)
+		return
+	}
+	for i := range deliveries {
+		// Deliveries of disabled targets wait until they are enabled again
+		if target := byID[deliveries[i].TargetID]; target != nil && target.Enabled {
+			d.attempt(ctx, target, &deliveries[i], now)
+		}
+	}
+}
+
+// batch creates a delivery of the queued articles of a target once its batch window has
+// passed since the first of them was queued, and its minimum interval since the last message
+func (d *Dispatcher) batch(target *models.NotificationTarget, now time.Time) {
+	start, err := d.db.GetNotificationQueueStart(target.ID)
+	if err != nil {
+		log.Printf("Error getting the notification queue of %q: %v", target.Name, err)
+		return
+	}
+	if start == nil || now.Before(start.Add(time.Duration(target.BatchWindow)*time.Second)) {
+		return
+	}
+	if target.LastSentAt != nil && now.Before(target.LastSentAt.Add(time.Duration(target.MinInterval)*time.Second)) {
+		return
+	}
+
+	limit := target.MaxArticles
+	if limit <= 0 {
+		limit = defaultMaxArticles
+	}
+	if _, err := d.db.CreateNotificationDelivery(target.ID, limit, now); err != nil {
+		log.Printf("Error creating a notification for %q: %v", target.Name, err)
+	}
+}
+
+// attempt sends a delivery once. Failed deliveries are retried later, or moved to the
+// dead-letter log after maxAttempts.
+func (d *Dispatcher) attempt(ctx context.Context, target *models.NotificationTarget, delivery *models.NotificationDelivery, now time.Time) {
+	articles, err := d.articles(delivery.ArticleIDs)
+	if err != nil {
+		log.Printf("Error getting the articles of notification %d: %v", delivery.ID, err)
+		return
+	}
+	if len(articles) == 0 {
+		// All of its articles were deleted in the meantime
+		if err := d.db.DeleteNotificationDelivery(delivery.ID); err != nil {
+			log.Printf("Error removing notification %d: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	// Matrix uses the transaction ID to ignore retries of a message it already received
+	err = d.send(ctx, target, articles, fmt.Sprintf("mrrss-%d", delivery.ID))
+	if err == nil {
+		if err := d.db.CompleteNotificationDelivery(delivery, now); err != nil {
+			log.Printf("Error completing notification %d: %v", delivery.ID, err)
+		}
+		target.LastSentAt = &now
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= maxAttempts {
+		delivery.Status = models.NotificationDead
+		log.Printf("Notification %d to %q failed %d times, moved to the dead-letter log: %v", delivery.ID, target.Name, delivery.Attempts, err)
+	} else {
+		delivery.NextAttemptAt = now.Add(retryDelays[delivery.Attempts-1])
+		log.Printf("Notification %d to %q failed, retrying at %s: %v", delivery.ID, target.Name, delivery.NextAttemptAt.Format(time.RFC3339), err)
+	}
+	if err := d.db.UpdateNotificationDelivery(delivery); err != nil {
+		log.Printf("Error saving notification %d: %v", delivery.ID, err)
+	}
+}
+
+// articles loads articles in the order of their IDs, skipping deleted ones
+func (d *Dispatcher) articles(ids []int64) ([]models.Article, error) {
+	loaded, err := d.db.GetArticlesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Article, len(loaded))
+	for _, article := range loaded {
+		byID[article.ID] = article
+	}
+	articles := make([]models.Article, 0, len(loaded))
+	for _, id := range ids {
+		if article, ok := byID[id]; ok {
+			articles = append(articles, article)
+		}
+	}
+	return articles, nil
+}
+
+// Test sends a sample message to a target right away, without queueing or retries
+func (d *Dispatcher) Test(ctx context.Context, target *models.NotificationTarget) error {
+	return d.send(ctx, target, sampleArticles(), fmt.Sprintf("mrrss-test-%d", time.Now().UnixNano()))
+}
+
+// sampleArticles returns the article of test messages
+func sampleArticles() []models.Article {
+	return []models.Article{{
+		Title:       "Test notification from MrRSS",
+		URL:         "https://github.com/WCY-dt/MrRSS",
+		FeedTitle:   "MrRSS",
+		Author:      "MrRSS",
+		PublishedAt: time.Now(),
+		Summary:     "Notifications to this target work.",
+	}}
+}
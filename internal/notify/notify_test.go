@@ -0,0 +1,378 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/models"
+	"MrRSS/internal/utils"
+)
+
+// receivedRequest is a request received by a test target
+type receivedRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+// startTarget starts a server that records the requests it receives and answers them
+// with the status in status, 200 by default
+func startTarget(t *testing.T) (*httptest.Server, chan receivedRequest, *atomic.Int32) {
+	t.Helper()
+	requests := make(chan receivedRequest, 20)
+	status := &atomic.Int32{}
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- receivedRequest{method: r.Method, path: r.URL.Path, header: r.Header, body: compactJSON(body)}
+		w.WriteHeader(int(status.Load()))
+		if status.Load() != http.StatusOK {
+			io.WriteString(w, "unavailable")
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, status
+}
+
+// compactJSON re-encodes JSON bodies without spaces and HTML escaping, so they can be
+// compared as text. Other bodies are returned as they are.
+func compactJSON(body []byte) string {
+	var value interface{}
+	if json.Unmarshal(body, &value) != nil {
+		return string(body)
+	}
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.TrimSpace(b.String())
+}
+
+func setupTestDispatcher(t *testing.T) (*Dispatcher, *database.DB, int64) {
+	t.Helper()
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+	d := NewDispatcher(db)
+	// The test targets listen on the loopback address, which the dispatcher refuses
+	d.client = &http.Client{Timeout: requestTimeout}
+	return d, db, feedID
+}
+
+// addArticles saves articles and returns them with their IDs, in the given order
+func addArticles(t *testing.T, db *database.DB, feedID int64, titles ...string) []models.Article {
+	t.Helper()
+	saved := make([]*models.Article, len(titles))
+	for i, title := range titles {
+		saved[i] = &models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + strings.ReplaceAll(title, " ", "-"), PublishedAt: time.Now()}
+	}
+	if _, err := db.SaveArticlesCounted(context.Background(), saved); err != nil {
+		t.Fatalf("SaveArticlesCounted error: %v", err)
+	}
+	ids := make([]int64, len(saved))
+	for i, article := range saved {
+		ids[i] = article.ID
+	}
+	articles, err := db.GetArticlesByIDs(ids)
+	if err != nil || len(articles) != len(titles) {
+		t.Fatalf("GetArticlesByIDs = %d articles, %v", len(articles), err)
+	}
+	return articles
+}
+
+func addTarget(t *testing.T, db *database.DB, target models.NotificationTarget) *models.NotificationTarget {
+	t.Helper()
+	if err := Validate(&target); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	id, err := db.CreateNotificationTarget(target)
+	if err != nil {
+		t.Fatalf("CreateNotificationTarget error: %v", err)
+	}
+	saved, err := db.GetNotificationTarget(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func expectNoRequest(t *testing.T, requests chan receivedRequest) {
+	t.Helper()
+	select {
+	case req := <-requests:
+		t.Fatalf("unexpected request: %s %s", req.method, req.body)
+	default:
+	}
+}
+
+func nextRequest(t *testing.T, requests chan receivedRequest) receivedRequest {
+	t.Helper()
+	select {
+	case req := <-requests:
+		return req
+	default:
+		t.Fatal("no request received")
+		return receivedRequest{}
+	}
+}
+
+func TestEnqueue_BatchesAndThrottles(t *testing.T) {
+	d, db, feedID := setupTestDispatcher(t)
+	server, requests, _ := startTarget(t)
+	addTarget(t, db, models.NotificationTarget{
+		Name:        "Go news",
+		Enabled:     true,
+		Type:        models.NotifyWebhook,
+		URL:         server.URL + "/hook",
+		Token:       "secret",
+		Conditions:  []models.RuleCondition{{Field: "article_title", Operator: "contains", Value: "go"}},
+		BatchWindow: 60,
+		MinInterval: 600,
+	})
+
+	articles := addArticles(t, db, feedID, "Go 1.24 released", "Rust 2024 edition", "Go generics")
+	d.Enqueue(articles[:2], nil)
+	d.flushes.Wait()
+	start := time.Now()
+	// The batch window has not passed yet
+	expectNoRequest(t, requests)
+	d.Enqueue(articles[2:], nil)
+	d.flushes.Wait()
+
+	d.Flush(context.Background(), start.Add(61*time.Second))
+	req := nextRequest(t, requests)
+	if req.method != http.MethodPost || req.path != "/hook" || req.header.Get("Authorization") != "Bearer secret" ||
+		req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected request: %s %s %v", req.method, req.path, req.header)
+	}
+	var body webhookData
+	if err := json.Unmarshal([]byte(req.body), &body); err != nil {
+		t.Fatalf("invalid body %q: %v", req.body, err)
+	}
+	if body.Target != "Go news" || body.Count != 2 || len(body.Articles) != 2 ||
+		body.Articles[0].Title != "Go 1.24 released" || body.Articles[1].Title != "Go generics" ||
+		body.Articles[0].FeedTitle != "Go Blog" {
+		t.Errorf("unexpected body: %+v", body)
+	}
+
+	// Articles queued later wait for the minimum interval since the last message
+	more := addArticles(t, db, feedID, "Go tooling")
+	d.Enqueue(more, nil)
+	d.flushes.Wait()
+	d.Flush(context.Background(), start.Add(5*time.Minute))
+	expectNoRequest(t, requests)
+	d.Flush(context.Background(), start.Add(61*time.Second+600*time.Second))
+	if req := nextRequest(t, requests); !strings.Contains(req.body, "Go tooling") {
+		t.Errorf("unexpected body: %s", req.body)
+	}
+
+	// Articles are only sent once
+	d.Flush(context.Background(), start.Add(time.Hour))
+	expectNoRequest(t, requests)
+}
+
+func TestFlush_RetriesAndDeadLetters(t *testing.T) {
+	d, db, feedID := setupTestDispatcher(t)
+	server, requests, status := startTarget(t)
+	target := addTarget(t, db, models.NotificationTarget{Name: "Slack", Enabled: true, Type: models.NotifySlack, URL: server.URL})
+	status.Store(http.StatusServiceUnavailable)
+
+	d.Enqueue(addArticles(t, db, feedID, "Go 1.24 released"), nil)
+	d.flushes.Wait()
+	nextRequest(t, requests)
+	deliveries, err := db.GetDueNotificationDeliveries(time.Now().Add(time.Minute + time.Second))
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("GetDueNotificationDeliveries = %+v, %v", deliveries, err)
+	}
+	if d := deliveries[0]; d.Attempts != 1 || !strings.Contains(d.LastError, "503") || strings.Contains(d.LastError, "unavailable") {
+		t.Errorf("unexpected delivery after the first attempt: %+v", d)
+	}
+
+	// Attempts are spaced by the retry delays, then the delivery is given up
+	now := time.Now()
+	for _, delay := range retryDelays {
+		d.Flush(context.Background(), now.Add(delay-time.Second))
+		expectNoRequest(t, requests)
+		now = now.Add(delay)
+		d.Flush(context.Background(), now)
+		nextRequest(t, requests)
+	}
+	dead, err := db.GetDeadNotifications(target.ID)
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("GetDeadNotifications = %+v, %v", dead, err)
+	}
+	if dead[0].Attempts != maxAttempts || dead[0].Status != models.NotificationDead {
+		t.Errorf("unexpected dead delivery: %+v", dead[0])
+	}
+	d.Flush(context.Background(), now.Add(24*time.Hour))
+	expectNoRequest(t, requests)
+
+	// A retried dead delivery gets a fresh set of attempts
+	status.Store(http.StatusOK)
+	if err := db.RetryDeadNotification(dead[0].ID, now); err != nil {
+		t.Fatalf("RetryDeadNotification error: %v", err)
+	}
+	d.Flush(context.Background(), now)
+	if req := nextRequest(t, requests); !strings.Contains(req.body, "Go 1.24 released") {
+		t.Errorf("unexpected body: %s", req.body)
+	}
+	if dead, _ := db.GetDeadNotifications(0); len(dead) != 0 {
+		t.Errorf("dead deliveries after a successful retry: %+v", dead)
+	}
+	if target, _ := db.GetNotificationTarget(target.ID); target.LastSentAt == nil {
+		t.Error("last send not recorded")
+	}
+}
+
+func TestTest_Formats(t *testing.T) {
+	d, _, _ := setupTestDispatcher(t)
+	server, requests, _ := startTarget(t)
+
+	tests := []struct {
+		target models.NotificationTarget
+		method string
+		path   string
+		header map[string]string
+		body   []string
+	}{
+		{
+			target: models.NotificationTarget{Type: models.NotifyWebhook, URL: server.URL + "/hook",
+				Template: `{"message": {{json (printf "%s: %d" .Target .Count)}}, "first": {{json (index .Articles 0).Title}}}`},
+			method: http.MethodPost, path: "/hook",
+			body: []string{`"message":"Test: 1"`, `"first":"Test notification from MrRSS"`},
+		},
+		{
+			target: models.NotificationTarget{Type: models.NotifySlack, URL: server.URL + "/slack"},
+			method: http.MethodPost, path: "/slack",
+			body: []string{`"text":"*New article in MrRSS*\n• <https://github.com/WCY-dt/MrRSS|Test notification from MrRSS> · MrRSS"`},
+		},
+		{
+			target: models.NotificationTarget{Type: models.NotifyDiscord, URL: server.URL + "/discord"},
+			method: http.MethodPost, path: "/discord",
+			body: []string{`"content":"**New article in MrRSS**\n- [Test notification from MrRSS](<https://github.com/WCY-dt/MrRSS>) · MrRSS"`},
+		},
+		{
+			target: models.NotificationTarget{Type: models.NotifyNtfy, URL: server.URL + "/mrrss", Token: "tk"},
+			method: http.MethodPost, path: "/mrrss",
+			header: map[string]string{"Title": "New article in MrRSS", "Click": "https://github.com/WCY-dt/MrRSS", "Authorization": "Bearer tk"},
+			body:   []string{"Test notification from MrRSS (MrRSS)\nhttps://github.com/WCY-dt/MrRSS"},
+		},
+		{
+			target: models.NotificationTarget{Type: models.NotifyGotify, URL: server.URL + "/", Token: "app-token"},
+			method: http.MethodPost, path: "/message",
+			header: map[string]string{"X-Gotify-Key": "app-token"},
+			body:   []string{`"title":"New article in MrRSS"`, `"contentType":"text/markdown"`},
+		},
+		{
+			target: models.NotificationTarget{Type: models.NotifyMatrix, URL: server.URL, Token: "syt_abc", Room: "!room:example.org"},
+			method: http.MethodPut, path: "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/",
+			header: map[string]string{"Authorization": "Bearer syt_abc"},
+			body:   []string{`"msgtype":"m.text"`, `"formatted_body":"<strong>New article in MrRSS</strong><ul><li><a href=\"https://github.com/WCY-dt/MrRSS\">`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target.Type, func(t *testing.T) {
+			tt.target.Name = "Test"
+			if err := Validate(&tt.target); err != nil {
+				t.Fatalf("Validate error: %v", err)
+			}
+			if err := d.Test(context.Background(), &tt.target); err != nil {
+				t.Fatalf("Test error: %v", err)
+			}
+			req := nextRequest(t, requests)
+			if req.method != tt.method || !strings.HasPrefix(req.path, tt.path) {
+				t.Errorf("request = %s %s, want %s %s", req.method, req.path, tt.method, tt.path)
+			}
+			for name, value := range tt.header {
+				if got := req.header.Get(name); got != value {
+					t.Errorf("header %s = %q, want %q", name, got, value)
+				}
+			}
+			for _, want := range tt.body {
+				if !strings.Contains(req.body, want) {
+					t.Errorf("body %s doesn't contain %s", req.body, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTest_RefusesPrivateAddresses(t *testing.T) {
+	db, err := database.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	d := NewDispatcher(db)
+	server, requests, _ := startTarget(t)
+
+	target := models.NotificationTarget{Name: "Local", Type: models.NotifyWebhook, URL: server.URL}
+	if err := d.Test(context.Background(), &target); !errors.Is(err, utils.ErrURLNotAllowed) {
+		t.Errorf("err = %v, want ErrURLNotAllowed", err)
+	}
+	expectNoRequest(t, requests)
+}
+
+func TestBuildRequest_SlackLinks(t *testing.T) {
+	target := &models.NotificationTarget{Type: models.NotifySlack, URL: "https://hooks.slack.com/services/x"}
+	articles := []models.Article{{Title: "a|b <c>", URL: "https://example.com/?q=a|b>c", FeedTitle: "Blog"}}
+	req, err := buildRequest(context.Background(), target, articles, "")
+	if err != nil {
+		t.Fatalf("buildRequest error: %v", err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	want := `• <https://example.com/?q=a%7Cb%3Ec|a|b &lt;c&gt;> · Blog`
+	if !strings.Contains(compactJSON(body), want) {
+		t.Errorf("body %s doesn't contain %s", compactJSON(body), want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := models.NotificationTarget{Name: " Alerts ", Type: "Slack", URL: " https://hooks.slack.com/services/x "}
+	if err := Validate(&valid); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if valid.Name != "Alerts" || valid.Type != models.NotifySlack || valid.URL != "https://hooks.slack.com/services/x" {
+		t.Errorf("target not normalized: %+v", valid)
+	}
+
+	invalid := map[string]models.NotificationTarget{
+		"no name":          {Type: models.NotifySlack, URL: "https://example.com"},
+		"unknown type":     {Name: "x", Type: "pager", URL: "https://example.com"},
+		"relative URL":     {Name: "x", Type: models.NotifyNtfy, URL: "/topic"},
+		"no gotify token":  {Name: "x", Type: models.NotifyGotify, URL: "https://gotify.example.com"},
+		"no matrix room":   {Name: "x", Type: models.NotifyMatrix, URL: "https://matrix.org", Token: "t"},
+		"invalid template": {Name: "x", Type: models.NotifyWebhook, URL: "https://example.com", Template: `{"title": {{.Title}}}`},
+		"broken template":  {Name: "x", Type: models.NotifyWebhook, URL: "https://example.com", Template: `{{.Missing`},
+		"long batch":       {Name: "x", Type: models.NotifyNtfy, URL: "https://ntfy.sh/x", BatchWindow: maxWaitSeconds + 1},
+		"many articles":    {Name: "x", Type: models.NotifyNtfy, URL: "https://ntfy.sh/x", MaxArticles: maxArticlesLimit + 1},
+		"unknown field": {Name: "x", Type: models.NotifyNtfy, URL: "https://ntfy.sh/x",
+			Conditions: []models.RuleCondition{{Field: "article_mood", Value: "happy"}}},
+	}
+	for name, target := range invalid {
+		if err := Validate(&target); !errors.Is(err, ErrInvalidTarget) {
+			t.Errorf("%s: err = %v, want ErrInvalidTarget", name, err)
+		}
+	}
+}
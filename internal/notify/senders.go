@@ -0,0 +1,257 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// discordContentLimit is the longest message Discord accepts
+const discordContentLimit = 2000
+
+// webhookArticle is an article in the body of a generic webhook
+type webhookArticle struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	FeedTitle   string    `json:"feed_title"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author"`
+	Summary     string    `json:"summary"`
+	ImageURL    string    `json:"image_url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// webhookData is the body of a generic webhook without template, and the data of templates
+type webhookData struct {
+	Target   string           `json:"target"`
+	Title    string           `json:"title"`
+	Count    int              `json:"count"`
+	Articles []webhookArticle `json:"articles"`
+}
+
+// templateFuncs are the functions of webhook templates. json encodes a value, so strings
+// from articles can be put into the JSON safely.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// send delivers a message about articles to a target
+func (d *Dispatcher) send(ctx context.Context, target *models.NotificationTarget, articles []models.Article, txnID string) error {
+	req, err := buildRequest(ctx, target, articles, txnID)
+	if err != nil {
+		return err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The response body is left out, errors are shown to API clients
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", target.Type, resp.StatusCode)
+	}
+	return nil
+}
+
+// buildRequest builds the request of a message in the format of the target's service
+func buildRequest(ctx context.Context, target *models.NotificationTarget, articles []models.Article, txnID string) (*http.Request, error) {
+	title := messageTitle(articles)
+	switch target.Type {
+	case models.NotifyWebhook:
+		body, err := renderWebhook(target, articles)
+		if err != nil {
+			return nil, err
+		}
+		return jsonRequest(ctx, http.MethodPost, target.URL, body, bearer(target.Token))
+
+	case models.NotifySlack:
+		lines := make([]string, 0, len(articles))
+		for _, a := range articles {
+			lines = append(lines, fmt.Sprintf("• <%s|%s> · %s", slackURL(a.URL), slackEscape(a.Title), slackEscape(a.FeedTitle)))
+		}
+		return jsonBody(ctx, http.MethodPost, target.URL, map[string]string{
+			"text": "*" + slackEscape(title) + "*\n" + strings.Join(lines, "\n"),
+		}, nil)
+
+	case models.NotifyDiscord:
+		content := "**" + title + "**\n" + markdownList(articles, true)
+		if runes := []rune(content); len(runes) > discordContentLimit {
+			content = string(runes[:discordContentLimit-1]) + "…"
+		}
+		return jsonBody(ctx, http.MethodPost, target.URL, map[string]string{"content": content}, nil)
+
+	case models.NotifyNtfy:
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, strings.NewReader(plainList(articles)))
+		if err != nil {
+			return nil, err
+		}
+		// Header values are ASCII, ntfy decodes RFC 2047 encoded titles
+		req.Header.Set("Title", mime.BEncoding.Encode("utf-8", title))
+		req.Header.Set("Tags", "newspaper")
+		if len(articles) == 1 {
+			req.Header.Set("Click", articles[0].URL)
+		}
+		if target.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+target.Token)
+		}
+		return req, nil
+
+	case models.NotifyGotify:
+		return jsonBody(ctx, http.MethodPost, strings.TrimSuffix(target.URL, "/")+"/message", map[string]interface{}{
+			"title":    title,
+			"message":  markdownList(articles, false),
+			"priority": 5,
+			"extras": map[string]interface{}{
+				"client::display": map[string]string{"contentType": "text/markdown"},
+			},
+		}, map[string]string{"X-Gotify-Key": target.Token})
+
+	case models.NotifyMatrix:
+		endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+			strings.TrimSuffix(target.URL, "/"), url.PathEscape(target.Room), url.PathEscape(txnID))
+		return jsonBody(ctx, http.MethodPut, endpoint, map[string]string{
+			"msgtype":        "m.text",
+			"body":           title + "\n" + plainList(articles),
+			"format":         "org.matrix.custom.html",
+			"formatted_body": "<strong>" + html.EscapeString(title) + "</strong>" + htmlList(articles),
+		}, bearer(target.Token))
+	}
+	return nil, fmt.Errorf("unknown notification type %q", target.Type)
+}
+
+// renderWebhook builds the JSON body of a generic webhook with its template, or the
+// default body if it has none
+func renderWebhook(target *models.NotificationTarget, articles []models.Article) ([]byte, error) {
+	data := webhookData{Target: target.Name, Title: messageTitle(articles), Count: len(articles)}
+	for _, a := range articles {
+		data.Articles = append(data.Articles, webhookArticle{
+			ID:          a.ID,
+			FeedID:      a.FeedID,
+			FeedTitle:   a.FeedTitle,
+			Title:       a.Title,
+			URL:         a.URL,
+			Author:      a.Author,
+			Summary:     a.Summary,
+			ImageURL:    a.ImageURL,
+			PublishedAt: a.PublishedAt,
+		})
+	}
+	if target.Template == "" {
+		return json.Marshal(data)
+	}
+
+	tmpl, err := template.New("webhook").Funcs(templateFuncs).Parse(target.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, fmt.Errorf("template does not produce valid JSON")
+	}
+	return body.Bytes(), nil
+}
+
+// messageTitle is the title of a message about articles
+func messageTitle(articles []models.Article) string {
+	if len(articles) == 1 {
+		if articles[0].FeedTitle != "" {
+			return "New article in " + articles[0].FeedTitle
+		}
+		return "New article"
+	}
+	return fmt.Sprintf("%d new articles", len(articles))
+}
+
+// plainList lists articles as plain text, with their links on separate lines
+func plainList(articles []models.Article) string {
+	lines := make([]string, 0, len(articles))
+	for _, a := range articles {
+		lines = append(lines, fmt.Sprintf("%s (%s)\n%s", a.Title, a.FeedTitle, a.URL))
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// markdownList lists articles as Markdown links. Discord shows previews of links unless
+// they are wrapped in angle brackets.
+func markdownList(articles []models.Article, noPreviews bool) string {
+	replacer := strings.NewReplacer("[", "\\[", "]", "\\]", "*", "\\*", "_", "\\_")
+	lines := make([]string, 0, len(articles))
+	for _, a := range articles {
+		link := a.URL
+		if noPreviews {
+			link = "<" + link + ">"
+		}
+		lines = append(lines, fmt.Sprintf("- [%s](%s) · %s", replacer.Replace(a.Title), link, replacer.Replace(a.FeedTitle)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlList lists articles as an HTML list of links
+func htmlList(articles []models.Article) string {
+	var b strings.Builder
+	b.WriteString("<ul>")
+	for _, a := range articles {
+		fmt.Fprintf(&b, `<li><a href="%s">%s</a> · %s</li>`, html.EscapeString(a.URL), html.EscapeString(a.Title), html.EscapeString(a.FeedTitle))
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
+
+// slackEscape escapes the characters Slack uses for its markup
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// slackURL percent-encodes the characters that would end the URL of a Slack link early,
+// so a link can't swallow the rest of the message or inject markup
+func slackURL(s string) string {
+	return strings.NewReplacer("|", "%7C", "<", "%3C", ">", "%3E").Replace(s)
+}
+
+// bearer returns the authorization header of a token, or none if it is empty
+func bearer(token string) map[string]string {
+	if token == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// jsonBody builds a request with a value encoded as JSON body
+func jsonBody(ctx context.Context, method, endpoint string, value interface{}, headers map[string]string) (*http.Request, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return jsonRequest(ctx, method, endpoint, body, headers)
+}
+
+// jsonRequest builds a request with a JSON body
+func jsonRequest(ctx context.Context, method, endpoint string, body []byte, headers map[string]string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
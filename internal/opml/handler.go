@@ -4,6 +4,7 @@ import (
 	"MrRSS/internal/models"
 	"MrRSS/internal/utils"
 	"bytes"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"io"
@@ -12,6 +13,9 @@ import (
 	"strings"
 )
 
+// Namespace is the XML namespace of MrRSS-specific outline attributes
+const Namespace = "https://github.com/WCY-dt/MrRSS"
+
 type OPML struct {
 	XMLName xml.Name `xml:"opml"`
 	Version string   `xml:"version,attr"`
@@ -50,6 +54,50 @@ type Outline struct {
 	XPathItemThumbnail  string `xml:"xPathItemThumbnail,attr"`
 	XPathItemCategories string `xml:"xPathItemCategories,attr"`
 	XPathItemUid        string `xml:"xPathItemUid,attr"`
+	// MrRSS per-feed HTTP request settings (in the Namespace namespace)
+	HTTPUserAgent    string `xml:"https://github.com/WCY-dt/MrRSS userAgent,attr,omitempty"`
+	HTTPHeaders      string `xml:"https://github.com/WCY-dt/MrRSS httpHeaders,attr,omitempty"` // JSON object
+	HTTPCookie       string `xml:"https://github.com/WCY-dt/MrRSS cookie,attr,omitempty"`
+	HTTPAuthType     string `xml:"https://github.com/WCY-dt/MrRSS authType,attr,omitempty"`
+	HTTPAuthUsername string `xml:"https://github.com/WCY-dt/MrRSS authUsername,attr,omitempty"`
+	HTTPAuthSecret   string `xml:"https://github.com/WCY-dt/MrRSS authSecret,attr,omitempty"`
+}
+
+// httpSettings returns the outline's HTTP request settings, or nil if it has none
+func (o *Outline) httpSettings() *models.FeedHTTPSettings {
+	settings := &models.FeedHTTPSettings{
+		UserAgent:    o.HTTPUserAgent,
+		Cookie:       o.HTTPCookie,
+		AuthType:     o.HTTPAuthType,
+		AuthUsername: o.HTTPAuthUsername,
+		AuthSecret:   o.HTTPAuthSecret,
+	}
+	if o.HTTPHeaders != "" {
+		if err := json.Unmarshal([]byte(o.HTTPHeaders), &settings.Headers); err != nil {
+			log.Printf("OPML Parse: Ignoring invalid HTTP headers for %s: %v", o.XMLURL, err)
+		}
+	}
+	if settings.IsEmpty() {
+		return nil
+	}
+	return settings
+}
+
+// setHTTPSettings sets the outline's HTTP request attributes
+func (o *Outline) setHTTPSettings(settings *models.FeedHTTPSettings) {
+	if settings.IsEmpty() {
+		return
+	}
+	o.HTTPUserAgent = settings.UserAgent
+	if len(settings.Headers) > 0 {
+		if data, err := json.Marshal(settings.Headers); err == nil {
+			o.HTTPHeaders = string(data)
+		}
+	}
+	o.HTTPCookie = settings.Cookie
+	o.HTTPAuthType = settings.AuthType
+	o.HTTPAuthUsername = settings.AuthUsername
+	o.HTTPAuthSecret = settings.AuthSecret
 }
 
 // normalizeOPMLAttributes normalizes attribute names in OPML content to handle
@@ -152,6 +200,7 @@ func Parse(r io.Reader) ([]models.Feed, error) {
 					XPathItemThumbnail:  o.XPathItemThumbnail,
 					XPathItemCategories: o.XPathItemCategories,
 					XPathItemUid:        o.XPathItemUid,
+					HTTPSettings:        o.httpSettings(),
 				})
 			}
 
@@ -260,7 +309,7 @@ func Generate(feeds []models.Feed) ([]byte, error) {
 			}
 		}
 
-		*currentOutlines = append(*currentOutlines, &Outline{
+		outline := &Outline{
 			Text:   f.Title,
 			Title:  f.Title,
 			Type:   f.Type,
@@ -276,7 +325,9 @@ func Generate(feeds []models.Feed) ([]byte, error) {
 			XPathItemThumbnail:  f.XPathItemThumbnail,
 			XPathItemCategories: f.XPathItemCategories,
 			XPathItemUid:        f.XPathItemUid,
-		})
+		}
+		outline.setHTTPSettings(f.HTTPSettings)
+		*currentOutlines = append(*currentOutlines, outline)
 	}
 
 	return xml.MarshalIndent(doc, "", "  ")
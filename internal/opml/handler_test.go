@@ -64,3 +64,47 @@ func TestGenerate(t *testing.T) {
 		t.Error("Generated XML missing Feed 2 URL")
 	}
 }
+
+func TestHTTPSettingsRoundTrip(t *testing.T) {
+	feeds := []models.Feed{
+		{
+			Title: "Private", URL: "https://example.com/feed",
+			HTTPSettings: &models.FeedHTTPSettings{
+				UserAgent:    "Custom-UA",
+				Headers:      map[string]string{"X-Api-Key": "abc"},
+				AuthType:     "basic",
+				AuthUsername: "user",
+				AuthSecret:   "pass",
+			},
+		},
+		{Title: "Public", URL: "https://example.org/feed"},
+	}
+
+	data, err := Generate(feeds)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(string(data), Namespace) {
+		t.Error("Generated XML missing MrRSS namespace")
+	}
+
+	parsed, err := Parse(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("Expected 2 feeds, got %d", len(parsed))
+	}
+
+	s := parsed[0].HTTPSettings
+	if s == nil {
+		t.Fatal("Expected HTTP settings on first feed")
+	}
+	if s.UserAgent != "Custom-UA" || s.Headers["X-Api-Key"] != "abc" ||
+		s.AuthType != "basic" || s.AuthUsername != "user" || s.AuthSecret != "pass" {
+		t.Errorf("Unexpected HTTP settings: %+v", s)
+	}
+	if parsed[1].HTTPSettings != nil {
+		t.Errorf("Expected no HTTP settings on second feed, got %+v", parsed[1].HTTPSettings)
+	}
+}
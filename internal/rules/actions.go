@@ -0,0 +1,276 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"MrRSS/internal/summary"
+	"MrRSS/internal/translation"
+	"MrRSS/internal/utils"
+)
+
+// Actions that need the network. They run on a bounded worker pool after the
+// rule matched, so rule application (and the post-save step of a feed refresh)
+// never waits for AI providers or remote servers.
+const (
+	ActionAISummary      = "ai_summary"
+	ActionTranslateTitle = "translate_title"
+	ActionFetchFullText  = "fetch_full_text"
+	ActionWebhook        = "webhook"
+)
+
+// ActionDelete deletes the matched article
+const ActionDelete = "delete"
+
+const (
+	networkActionWorkers   = 4
+	networkActionQueueSize = 256
+	webhookTimeout         = 15 * time.Second
+)
+
+// actionPool runs network actions with a fixed number of workers
+type actionPool struct {
+	once    sync.Once
+	jobs    chan func()
+	pending sync.WaitGroup
+}
+
+var networkActions = &actionPool{}
+
+// submit queues a job; it returns false if the queue is full
+func (p *actionPool) submit(job func()) bool {
+	p.once.Do(func() {
+		p.jobs = make(chan func(), networkActionQueueSize)
+		for i := 0; i < networkActionWorkers; i++ {
+			go func() {
+				for job := range p.jobs {
+					job()
+					p.pending.Done()
+				}
+			}()
+		}
+	})
+
+	p.pending.Add(1)
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		p.pending.Done()
+		return false
+	}
+}
+
+// wait blocks until all queued jobs are done
+func (p *actionPool) wait() {
+	p.pending.Wait()
+}
+
+// isNetworkAction reports whether an action runs on the network action pool
+func isNetworkAction(action string) bool {
+	switch action {
+	case ActionAISummary, ActionTranslateTitle, ActionFetchFullText, ActionWebhook:
+		return true
+	}
+	return false
+}
+
+// runNetworkAction performs a network action synchronously
+func (e *Engine) runNetworkAction(articleID int64, action string) error {
+	switch action {
+	case ActionAISummary:
+		return e.generateAISummary(articleID)
+	case ActionTranslateTitle:
+		return e.translateTitle(articleID)
+	case ActionFetchFullText:
+		return e.fetchFullText(articleID)
+	case ActionWebhook:
+		return e.postWebhook(articleID)
+	}
+	return nil
+}
+
+// generateAISummary generates and stores an AI summary if the article has none yet
+func (e *Engine) generateAISummary(articleID int64) error {
+	article, err := e.db.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+	if article.Summary != "" {
+		return nil
+	}
+
+	if e.aiTracker.IsLimitReached() {
+		log.Printf("AI usage limit reached, skipping summary for article %d", articleID)
+		return nil
+	}
+
+	content, _, err := e.db.GetArticleContent(articleID)
+	if err != nil {
+		return err
+	}
+	if content == "" {
+		return nil
+	}
+
+	summaryLength := summary.Medium
+	switch length, _ := e.db.GetSetting("summary_length"); length {
+	case "short":
+		summaryLength = summary.Short
+	case "long":
+		summaryLength = summary.Long
+	}
+
+	apiKey, _ := e.db.GetEncryptedSetting("ai_api_key")
+	endpoint, _ := e.db.GetSetting("ai_endpoint")
+	model, _ := e.db.GetSetting("ai_model")
+	systemPrompt, _ := e.db.GetSetting("ai_summary_prompt")
+	customHeaders, _ := e.db.GetSetting("ai_custom_headers")
+	language, _ := e.db.GetSetting("language")
+
+	aiSummarizer := summary.NewAISummarizerWithDB(apiKey, endpoint, model, e.db)
+	if systemPrompt != "" {
+		aiSummarizer.SetSystemPrompt(systemPrompt)
+	}
+	if customHeaders != "" {
+		aiSummarizer.SetCustomHeaders(customHeaders)
+	}
+	if language != "" {
+		aiSummarizer.SetLanguage(language)
+	}
+
+	// Apply rate limiting for AI requests
+	e.aiTracker.WaitForRateLimit()
+
+	result, err := aiSummarizer.Summarize(content, summaryLength)
+	if err != nil {
+		return err
+	}
+	if result.IsTooShort || result.Summary == "" {
+		return nil
+	}
+
+	// Track AI usage only on success
+	e.aiTracker.TrackSummary(content, result.Summary)
+	_ = e.db.IncrementStat("ai_summary")
+
+	return e.db.UpdateArticleSummary(articleID, result.Summary)
+}
+
+// translateTitle translates the article title into the target language
+func (e *Engine) translateTitle(articleID int64) error {
+	article, err := e.db.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+	if article.TranslatedTitle != "" || article.Title == "" {
+		return nil
+	}
+
+	targetLang, _ := e.db.GetSetting("target_language")
+	if targetLang == "" {
+		return nil
+	}
+
+	// Skip titles that are already in the target language
+	if !translation.GetLanguageDetector().ShouldTranslate(article.Title, targetLang) {
+		return e.db.UpdateArticleTranslation(articleID, article.Title)
+	}
+
+	provider, _ := e.db.GetSetting("translation_provider")
+	isAIProvider := provider == "ai"
+	if isAIProvider {
+		if e.aiTracker.IsLimitReached() {
+			log.Printf("AI usage limit reached, skipping title translation for article %d", articleID)
+			return nil
+		}
+		// Apply rate limiting for AI requests
+		e.aiTracker.WaitForRateLimit()
+	}
+
+	translatedTitle, err := e.translator.Translate(article.Title, targetLang)
+	if err != nil {
+		return err
+	}
+
+	if isAIProvider {
+		e.aiTracker.TrackTranslation(article.Title, translatedTitle)
+	}
+
+	return e.db.UpdateArticleTranslation(articleID, translatedTitle)
+}
+
+// fetchFullText fetches the full article from its URL and stores it as the article's full text
+func (e *Engine) fetchFullText(articleID int64) error {
+	article, err := e.db.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+	if article.URL == "" {
+		return nil
+	}
+
+	var modifiers []func(*http.Request)
+	if settings, _ := e.db.GetFeedHTTPSettings(article.FeedID); settings != nil {
+		if feed, err := e.db.GetFeedByID(article.FeedID); err == nil {
+			modifiers = append(modifiers, func(req *http.Request) {
+				utils.ApplyFeedHTTPSettings(req, settings, feed.URL)
+			})
+		}
+	}
+
+	fullTextSettings, err := e.db.GetFeedFullTextSettings(article.FeedID)
+	if err != nil {
+		return err
+	}
+	content, err := utils.FetchFullText(article.URL, fullTextSettings, modifiers...)
+	if err != nil {
+		return err
+	}
+	if content == "" {
+		return nil
+	}
+
+	return e.db.SetArticleFullText(articleID, content)
+}
+
+// postWebhook POSTs the article as JSON to the configured rules webhook URL
+func (e *Engine) postWebhook(articleID int64) error {
+	webhookURL, _ := e.db.GetSetting("rules_webhook_url")
+	if webhookURL == "" {
+		log.Printf("Rules webhook URL not configured, skipping webhook for article %d", articleID)
+		return nil
+	}
+
+	article, err := e.db.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(article)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestEngine_WebhookThenDelete(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	var mu sync.Mutex
+	var received []models.Article
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var article models.Article
+		if err := json.NewDecoder(r.Body).Decode(&article); err != nil {
+			t.Errorf("Invalid webhook body: %v", err)
+		}
+		mu.Lock()
+		received = append(received, article)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	engine.db.SetSetting("rules_webhook_url", server.URL)
+
+	feedID, err := engine.db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	if err := engine.db.SaveArticle(&models.Article{FeedID: feedID, Title: "Forward me", URL: "https://example.com/a"}); err != nil {
+		t.Fatalf("SaveArticle failed: %v", err)
+	}
+	articles, err := engine.db.GetArticles("", feedID, "", true, 10, 0)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("GetArticles failed: %v", err)
+	}
+
+	// Deletion is listed first but must run after the webhook
+	rule := Rule{Enabled: true, Actions: []string{"delete", "webhook"}}
+	if _, err := engine.ApplyRule(rule); err != nil {
+		t.Fatalf("ApplyRule failed: %v", err)
+	}
+	networkActions.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0].Title != "Forward me" {
+		t.Fatalf("Expected webhook to receive the article, got %+v", received)
+	}
+	if _, err := engine.db.GetArticleByID(articles[0].ID); err == nil {
+		t.Error("Expected article to be deleted after the webhook")
+	}
+}
+
+func TestActionPool_Bounded(t *testing.T) {
+	pool := &actionPool{}
+	block := make(chan struct{})
+
+	// Fill the workers and the queue
+	accepted := 0
+	for i := 0; i < networkActionWorkers+networkActionQueueSize+10; i++ {
+		if pool.submit(func() { <-block }) {
+			accepted++
+		}
+	}
+	close(block)
+	pool.wait()
+
+	if accepted < networkActionQueueSize || accepted > networkActionWorkers+networkActionQueueSize {
+		t.Errorf("Expected the pool to accept between %d and %d jobs, got %d", networkActionQueueSize, networkActionWorkers+networkActionQueueSize, accepted)
+	}
+}
+
+func TestIsNetworkAction(t *testing.T) {
+	for _, action := range []string{"ai_summary", "translate_title", "fetch_full_text", "webhook"} {
+		if !isNetworkAction(action) {
+			t.Errorf("Expected %s to be a network action", action)
+		}
+	}
+	for _, action := range []string{"favorite", "delete", "add_tag:x"} {
+		if isNetworkAction(action) {
+			t.Errorf("Expected %s not to be a network action", action)
+		}
+	}
+}
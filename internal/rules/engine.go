@@ -2,16 +2,18 @@ package rules
 
 import (
 	"context"
-	"encoding/json"
 	"log"
-	"regexp"
+	"net/http"
 	"strings"
 	"time"
 
+	"MrRSS/internal/aiusage"
 	"MrRSS/internal/database"
+	"MrRSS/internal/filter"
 	"MrRSS/internal/freshrss"
 	"MrRSS/internal/models"
 	"MrRSS/internal/rsshub"
+	"MrRSS/internal/translation"
 )
 
 // getFeedType returns the type code of a feed
@@ -47,55 +49,48 @@ func getFeedType(feed *models.Feed) string {
 }
 
 // Condition represents a condition in a rule
-type Condition struct {
-	ID       int64    `json:"id"`
-	Logic    string   `json:"logic"`    // "and", "or" (null for first condition)
-	Negate   bool     `json:"negate"`   // NOT modifier for this condition
-	Field    string   `json:"field"`    // "feed_name", "feed_category", "article_title", etc.
-	Operator string   `json:"operator"` // "contains", "exact"
-	Value    string   `json:"value"`    // Single value for text/date fields
-	Values   []string `json:"values"`   // Multiple values for feed_name and feed_category
-}
+type Condition = models.RuleCondition
 
 // Rule represents an automation rule
-type Rule struct {
-	ID         int64       `json:"id"`
-	Name       string      `json:"name"`
-	Enabled    bool        `json:"enabled"`
-	Conditions []Condition `json:"conditions"`
-	Actions    []string    `json:"actions"`  // "favorite", "unfavorite", "hide", "unhide", "mark_read", "mark_unread"
-	Position   int         `json:"position"` // Execution order (0 = first)
-}
+type Rule = models.Rule
 
 // Engine handles rule application
 type Engine struct {
-	db *database.DB
+	db         *database.DB
+	translator translation.Translator
+	aiTracker  *aiusage.Tracker
+	httpClient *http.Client
 }
 
 // NewEngine creates a new rules engine
 func NewEngine(db *database.DB) *Engine {
-	return &Engine{db: db}
+	return &Engine{
+		db:         db,
+		translator: translation.NewDynamicTranslatorWithCache(db, db),
+		aiTracker:  aiusage.NewTracker(db),
+		httpClient: &http.Client{Timeout: webhookTimeout},
+	}
 }
 
 // ApplyRulesToArticles applies all enabled rules to a batch of articles.
 // Each article is matched against rules in order, and only the first matching rule is applied.
 // This prevents conflicting actions from multiple rules being applied to the same article.
 func (e *Engine) ApplyRulesToArticles(articles []models.Article) (int, error) {
-	// Load rules from settings
-	rulesJSON, _ := e.db.GetSetting("rules")
-	if rulesJSON == "" {
-		return 0, nil
-	}
+	return e.ApplyRulesToArticlesWithContent(articles, nil)
+}
 
-	var rules []Rule
-	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
-		log.Printf("Error parsing rules: %v", err)
+// ApplyRulesToArticlesWithContent is like ApplyRulesToArticles, but takes the content of the
+// articles (article ID -> HTML, e.g. the RSS body captured during a fetch) for content conditions.
+// Articles missing from contents fall back to the article content cache.
+func (e *Engine) ApplyRulesToArticlesWithContent(articles []models.Article, contents map[int64]string) (int, error) {
+	rules, err := e.db.GetRules()
+	if err != nil {
+		log.Printf("Error loading rules: %v", err)
 		return 0, err
 	}
-
-	// Sort rules by position (ascending) to ensure execution order
-	// Rules without a position field (backward compatibility) are treated as position 0
-	sortRulesByPosition(rules)
+	if len(rules) == 0 {
+		return 0, nil
+	}
 
 	// Get feeds for category and title lookup
 	feeds, err := e.db.GetFeeds()
@@ -118,7 +113,11 @@ func (e *Engine) ApplyRulesToArticles(articles []models.Article) (int, error) {
 		feedIsFreshRSS[feed.ID] = feed.IsFreshRSSSource
 	}
 
+	// Regexes and article content are shared by all rules in this run
+	matcher := newTextMatcher(e.db, contents)
+
 	affected := 0
+	hits := make(map[int64]int)
 	for _, article := range articles {
 		for _, rule := range rules {
 			if !rule.Enabled {
@@ -126,20 +125,26 @@ func (e *Engine) ApplyRulesToArticles(articles []models.Article) (int, error) {
 			}
 
 			// Check if article matches conditions
-			if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS) {
+			if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, matcher) {
 				// Apply actions
-				for _, action := range rule.Actions {
-					if err := e.applyAction(article.ID, action); err != nil {
-						log.Printf("Error applying action %s to article %d: %v", action, article.ID, err)
-						continue
-					}
-				}
+				e.applyActions(article.ID, rule.Actions)
 				affected++
+				if rule.ID != 0 {
+					hits[rule.ID]++
+				}
 				break // Only apply first matching rule per article to prevent conflicts
 			}
 		}
 	}
 
+	// Record hit counters so rules that never match can be spotted
+	now := time.Now()
+	for ruleID, count := range hits {
+		if err := e.db.RecordRuleHits(ruleID, count, now); err != nil {
+			log.Printf("Error recording hits for rule %d: %v", ruleID, err)
+		}
+	}
+
 	return affected, nil
 }
 
@@ -174,48 +179,103 @@ func (e *Engine) ApplyRule(rule Rule) (int, error) {
 		feedIsFreshRSS[feed.ID] = feed.IsFreshRSSSource
 	}
 
+	matcher := newTextMatcher(e.db, nil)
+
 	affected := 0
 	for _, article := range articles {
-		if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS) {
-			for _, action := range rule.Actions {
-				if err := e.applyAction(article.ID, action); err != nil {
-					log.Printf("Error applying action %s to article %d: %v", action, article.ID, err)
-					continue
-				}
-			}
+		if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, matcher) {
+			e.applyActions(article.ID, rule.Actions)
 			affected++
 		}
 	}
 
+	// Saved rules applied by hand count towards their hit counter as well
+	if rule.ID != 0 {
+		if err := e.db.RecordRuleHits(rule.ID, affected, time.Now()); err != nil {
+			log.Printf("Error recording hits for rule %d: %v", rule.ID, err)
+		}
+	}
+
 	return affected, nil
 }
 
+// Matcher evaluates rule conditions outside of rules, e.g. for notification targets.
+// The feeds are loaded once, so a matcher is meant for a single batch of articles.
+type Matcher struct {
+	feedCategories  map[int64]string
+	feedTitles      map[int64]string
+	feedTypes       map[int64]string
+	feedIsImageMode map[int64]bool
+	feedIsFreshRSS  map[int64]bool
+	text            *textMatcher
+}
+
+// NewMatcher creates a matcher for the current feeds. Like for
+// ApplyRulesToArticlesWithContent, contents optionally holds the content of the articles.
+func NewMatcher(db *database.DB, contents map[int64]string) (*Matcher, error) {
+	feeds, err := db.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Matcher{
+		feedCategories:  make(map[int64]string),
+		feedTitles:      make(map[int64]string),
+		feedTypes:       make(map[int64]string),
+		feedIsImageMode: make(map[int64]bool),
+		feedIsFreshRSS:  make(map[int64]bool),
+		text:            newTextMatcher(db, contents),
+	}
+	for _, feed := range feeds {
+		m.feedCategories[feed.ID] = feed.Category
+		m.feedTitles[feed.ID] = feed.Title
+		m.feedTypes[feed.ID] = getFeedType(&feed)
+		m.feedIsImageMode[feed.ID] = feed.IsImageMode
+		m.feedIsFreshRSS[feed.ID] = feed.IsFreshRSSSource
+	}
+	return m, nil
+}
+
+// Matches reports whether an article matches the conditions; no conditions match all articles
+func (m *Matcher) Matches(article models.Article, conditions []Condition) bool {
+	return matchesConditions(article, conditions, m.feedCategories, m.feedTitles, m.feedTypes, m.feedIsImageMode, m.feedIsFreshRSS, m.text)
+}
+
 // matchesConditions checks if an article matches the rule conditions
-func matchesConditions(article models.Article, conditions []Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool) bool {
+func matchesConditions(article models.Article, conditions []Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, matcher *textMatcher) bool {
 	// If no conditions, apply to all articles
 	if len(conditions) == 0 {
 		return true
 	}
 
-	result := evaluateCondition(article, conditions[0], feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS)
+	results := make([]bool, len(conditions))
+	for i, condition := range conditions {
+		results[i] = evaluateCondition(article, condition, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, matcher)
+	}
+	return combineConditionResults(conditions, results)
+}
 
-	for i := 1; i < len(conditions); i++ {
-		condition := conditions[i]
-		conditionResult := evaluateCondition(article, condition, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS)
+// combineConditionResults combines the results of the individual conditions
+// from left to right using each condition's logic operator
+func combineConditionResults(conditions []Condition, results []bool) bool {
+	if len(conditions) == 0 {
+		return true
+	}
 
-		switch condition.Logic {
+	result := results[0]
+	for i := 1; i < len(conditions); i++ {
+		switch conditions[i].Logic {
 		case "and":
-			result = result && conditionResult
+			result = result && results[i]
 		case "or":
-			result = result || conditionResult
+			result = result || results[i]
 		}
 	}
-
 	return result
 }
 
 // evaluateCondition evaluates a single rule condition
-func evaluateCondition(article models.Article, condition Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool) bool {
+func evaluateCondition(article models.Article, condition Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, matcher *textMatcher) bool {
 	var result bool
 
 	switch condition.Field {
@@ -224,36 +284,37 @@ func evaluateCondition(article models.Article, condition Condition, feedCategori
 		if feedTitle == "" {
 			feedTitle = article.FeedTitle
 		}
-		result = matchMultiSelect(feedTitle, condition.Values, condition.Value)
+		result = filter.MatchMultiSelect(feedTitle, condition.Values, condition.Value)
 
 	case "feed_category":
 		feedCategory := feedCategories[article.FeedID]
-		result = matchMultiSelect(feedCategory, condition.Values, condition.Value)
+		result = filter.MatchMultiSelect(feedCategory, condition.Values, condition.Value)
 
 	case "article_title":
+		result = matcher.text().MatchText(article.Title, condition.Operator, condition.Value)
+
+	case "article_content":
 		if condition.Value == "" {
 			result = true
 		} else {
-			lowerValue := strings.ToLower(condition.Value)
-			lowerTitle := strings.ToLower(article.Title)
-			if condition.Operator == "exact" {
-				result = lowerTitle == lowerValue
-			} else if condition.Operator == "regex" {
-				matched, err := regexp.MatchString(condition.Value, article.Title)
-				if err != nil {
-					log.Printf("Invalid regex pattern: %v", err)
-					result = false
-				} else {
-					result = matched
-				}
-			} else {
-				result = strings.Contains(lowerTitle, lowerValue)
-			}
+			result = matcher.text().MatchText(matcher.content(article), condition.Operator, condition.Value)
 		}
 
+	case "article_url":
+		result = matcher.text().MatchText(article.URL, condition.Operator, condition.Value)
+
+	case "article_summary":
+		result = matcher.text().MatchText(article.Summary, condition.Operator, condition.Value)
+
+	case "article_author":
+		result = matcher.text().MatchText(article.Author, condition.Operator, condition.Value)
+
+	case "article_tag":
+		result = matcher.text().MatchCategories(article.Categories, condition.Operator, condition.Values, condition.Value)
+
 	case "feed_type":
 		feedType := feedTypes[article.FeedID]
-		result = matchMultiSelect(feedType, condition.Values, condition.Value)
+		result = filter.MatchMultiSelect(feedType, condition.Values, condition.Value)
 
 	case "is_freshrss_feed":
 		if condition.Value == "" {
@@ -340,27 +401,67 @@ func evaluateCondition(article models.Article, condition Condition, feedCategori
 	return result
 }
 
-// matchMultiSelect checks if fieldValue matches any of the selected values
-func matchMultiSelect(fieldValue string, values []string, singleValue string) bool {
-	if len(values) > 0 {
-		lowerField := strings.ToLower(fieldValue)
-		for _, val := range values {
-			if strings.Contains(lowerField, strings.ToLower(val)) {
-				return true
+// applyActions applies the actions of a matched rule to an article.
+// Local actions are applied immediately; network actions are queued on the worker pool
+// and run in order. Deletion always happens last, after any queued network actions.
+func (e *Engine) applyActions(articleID int64, actions []string) {
+	var networkActionList []string
+	deleteArticle := false
+
+	for _, action := range actions {
+		switch {
+		case action == ActionDelete:
+			deleteArticle = true
+		case isNetworkAction(action):
+			networkActionList = append(networkActionList, action)
+		default:
+			if err := e.applyAction(articleID, action); err != nil {
+				log.Printf("Error applying action %s to article %d: %v", action, articleID, err)
+			}
+		}
+	}
+
+	if len(networkActionList) == 0 {
+		if deleteArticle {
+			if err := e.db.DeleteArticle(articleID); err != nil {
+				log.Printf("Error deleting article %d: %v", articleID, err)
+			}
+		}
+		return
+	}
+
+	ok := networkActions.submit(func() {
+		for _, action := range networkActionList {
+			if err := e.runNetworkAction(articleID, action); err != nil {
+				log.Printf("Error applying action %s to article %d: %v", action, articleID, err)
+			}
+		}
+		if deleteArticle {
+			if err := e.db.DeleteArticle(articleID); err != nil {
+				log.Printf("Error deleting article %d: %v", articleID, err)
+			}
+		}
+	})
+	if !ok {
+		log.Printf("Rule action queue is full, skipping actions %v for article %d", networkActionList, articleID)
+		if deleteArticle {
+			if err := e.db.DeleteArticle(articleID); err != nil {
+				log.Printf("Error deleting article %d: %v", articleID, err)
 			}
 		}
-		return false
-	} else if singleValue != "" {
-		return strings.Contains(strings.ToLower(fieldValue), strings.ToLower(singleValue))
 	}
-	return true
 }
 
-// applyAction applies an action to an article with FreshRSS sync if enabled
+// applyAction applies a local action to an article with FreshRSS sync if enabled
 func (e *Engine) applyAction(articleID int64, action string) error {
 	var syncReq *database.SyncRequest
 	var err error
 
+	// "add_tag:<name>" attaches a user tag, creating it if needed
+	if tagName, ok := strings.CutPrefix(action, "add_tag:"); ok {
+		return e.addTag(articleID, tagName)
+	}
+
 	// Apply the action and get sync request if applicable
 	switch action {
 	case "favorite":
@@ -396,6 +497,23 @@ func (e *Engine) applyAction(articleID int64, action string) error {
 	return nil
 }
 
+// addTag attaches a user tag to an article and syncs it as a FreshRSS label if enabled
+func (e *Engine) addTag(articleID int64, tagName string) error {
+	tagID, err := e.db.GetOrCreateTag(tagName)
+	if err != nil {
+		return err
+	}
+
+	_, syncReqs, err := e.db.TagArticlesWithSync(tagID, []int64{articleID}, true)
+	if err != nil {
+		return err
+	}
+	for i := range syncReqs {
+		go e.performImmediateSync(&syncReqs[i])
+	}
+	return nil
+}
+
 // performImmediateSync performs an immediate sync to FreshRSS in a background goroutine
 func (e *Engine) performImmediateSync(syncReq *database.SyncRequest) {
 	// Check if FreshRSS is enabled and configured
@@ -404,14 +522,18 @@ func (e *Engine) performImmediateSync(syncReq *database.SyncRequest) {
 		return
 	}
 
-	serverURL, username, password, err := e.db.GetFreshRSSConfig()
+	provider, serverURL, username, password, err := e.db.GetFreshRSSConfig()
 	if err != nil || serverURL == "" || username == "" || password == "" {
 		log.Printf("[Rule Sync] FreshRSS not configured, skipping sync")
 		return
 	}
 
 	// Create sync service
-	syncService := freshrss.NewBidirectionalSyncService(serverURL, username, password, e.db)
+	syncService, err := freshrss.NewProviderSyncService(provider, serverURL, username, password, e.db)
+	if err != nil {
+		log.Printf("[Rule Sync] %v", err)
+		return
+	}
 
 	// Perform immediate sync
 	ctx := context.Background()
@@ -425,19 +547,3 @@ func (e *Engine) performImmediateSync(syncReq *database.SyncRequest) {
 		log.Printf("[Rule Sync] Success for article %d: %s", syncReq.ArticleID, syncReq.Action)
 	}
 }
-
-// sortRulesByPosition sorts rules by their position field in ascending order
-func sortRulesByPosition(rules []Rule) {
-	// Use the built-in sort package with a custom comparator
-	for i := 0; i < len(rules); i++ {
-		for j := i + 1; j < len(rules); j++ {
-			// Compare positions, defaulting to 0 for backward compatibility
-			posI := rules[i].Position
-			posJ := rules[j].Position
-			if posI > posJ {
-				// Swap
-				rules[i], rules[j] = rules[j], rules[i]
-			}
-		}
-	}
-}
@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"time"
+
+	"MrRSS/internal/models"
+)
+
+// defaultPreviewSampleSize is the default number of matched and near-miss articles returned
+const defaultPreviewSampleSize = 20
+
+// previewBatchSize caps the number of stored articles a preview evaluates
+var previewBatchSize = 10000
+
+// PreviewArticle is a compact article reference returned by a rule preview
+type PreviewArticle struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	FeedTitle   string    `json:"feed_title"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// NearMiss is an article that would match the rule if a single condition had a different result
+type NearMiss struct {
+	Article PreviewArticle `json:"article"`
+	// ConditionIndex is the position of the condition that rejected the article
+	ConditionIndex int       `json:"condition_index"`
+	Condition      Condition `json:"condition"`
+}
+
+// PreviewResult is the outcome of evaluating a rule without applying it
+type PreviewResult struct {
+	Checked       int              `json:"checked"`
+	Truncated     bool             `json:"truncated"` // More articles are stored than were checked; counts cover the newest ones
+	Matched       int              `json:"matched"`
+	Samples       []PreviewArticle `json:"samples"`
+	NearMissCount int              `json:"near_miss_count"`
+	NearMisses    []NearMiss       `json:"near_misses"`
+}
+
+// PreviewRule evaluates a rule against the stored articles without changing anything.
+// It returns the number of matches, up to sampleSize matched articles, and up to sampleSize
+// near misses together with the condition that rejected each of them. Only the newest
+// previewBatchSize articles are evaluated.
+func (e *Engine) PreviewRule(rule Rule, sampleSize int) (*PreviewResult, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultPreviewSampleSize
+	}
+
+	// One more article than evaluated tells whether the preview is truncated
+	articles, err := e.db.GetArticles("", 0, "", true, previewBatchSize+1, 0)
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(articles) > previewBatchSize
+	if truncated {
+		articles = articles[:previewBatchSize]
+	}
+
+	// Get feeds for category and title lookup
+	feeds, err := e.db.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create maps of feed ID to feed data
+	feedCategories := make(map[int64]string)
+	feedTitles := make(map[int64]string)
+	feedTypes := make(map[int64]string)
+	feedIsImageMode := make(map[int64]bool)
+	feedIsFreshRSS := make(map[int64]bool)
+
+	for _, feed := range feeds {
+		feedCategories[feed.ID] = feed.Category
+		feedTitles[feed.ID] = feed.Title
+		feedTypes[feed.ID] = getFeedType(&feed)
+		feedIsImageMode[feed.ID] = feed.IsImageMode
+		feedIsFreshRSS[feed.ID] = feed.IsFreshRSSSource
+	}
+
+	matcher := newTextMatcher(e.db, nil)
+
+	result := &PreviewResult{
+		Truncated:  truncated,
+		Samples:    []PreviewArticle{},
+		NearMisses: []NearMiss{},
+	}
+	results := make([]bool, len(rule.Conditions))
+	for _, article := range articles {
+		result.Checked++
+
+		for i, condition := range rule.Conditions {
+			results[i] = evaluateCondition(article, condition, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, matcher)
+		}
+
+		if combineConditionResults(rule.Conditions, results) {
+			result.Matched++
+			if len(result.Samples) < sampleSize {
+				result.Samples = append(result.Samples, newPreviewArticle(article))
+			}
+			continue
+		}
+
+		if index := rejectingCondition(rule.Conditions, results); index >= 0 {
+			result.NearMissCount++
+			if len(result.NearMisses) < sampleSize {
+				result.NearMisses = append(result.NearMisses, NearMiss{
+					Article:        newPreviewArticle(article),
+					ConditionIndex: index,
+					Condition:      rule.Conditions[index],
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// rejectingCondition returns the index of the only condition whose opposite result
+// would make the rule match, or -1 if there is no such single condition
+func rejectingCondition(conditions []Condition, results []bool) int {
+	found := -1
+	for i := range conditions {
+		results[i] = !results[i]
+		matched := combineConditionResults(conditions, results)
+		results[i] = !results[i]
+
+		if matched {
+			if found >= 0 {
+				return -1
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+func newPreviewArticle(article models.Article) PreviewArticle {
+	return PreviewArticle{
+		ID:          article.ID,
+		FeedID:      article.FeedID,
+		FeedTitle:   article.FeedTitle,
+		Title:       article.Title,
+		URL:         article.URL,
+		PublishedAt: article.PublishedAt,
+	}
+}
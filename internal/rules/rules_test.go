@@ -100,3 +100,180 @@ func TestEngine_ApplyRule(t *testing.T) {
 		t.Errorf("Expected 0 articles to be processed, got %d", count)
 	}
 }
+
+func TestEvaluateCondition_AuthorAndTag(t *testing.T) {
+	article := models.Article{
+		Title:      "Release notes",
+		Author:     "Jane Doe, John Smith",
+		Categories: []string{"Golang", "Release"},
+	}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{"author contains", Condition{Field: "article_author", Operator: "contains", Value: "jane"}, true},
+		{"author exact mismatch", Condition{Field: "article_author", Operator: "exact", Value: "Jane Doe"}, false},
+		{"author regex", Condition{Field: "article_author", Operator: "regex", Value: `Smith$`}, true},
+		{"tag selected values", Condition{Field: "article_tag", Values: []string{"golang"}}, true},
+		{"tag selected values are exact", Condition{Field: "article_tag", Values: []string{"go"}}, false},
+		{"tag single value contains", Condition{Field: "article_tag", Value: "lang"}, true},
+		{"tag negated", Condition{Field: "article_tag", Values: []string{"rust"}, Negate: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateCondition(article, tt.condition, nil, nil, nil, nil, nil, nil); got != tt.want {
+				t.Errorf("evaluateCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Articles without item categories never match a tag selection
+	untagged := models.Article{Title: "Untagged"}
+	if evaluateCondition(untagged, Condition{Field: "article_tag", Values: []string{"golang"}}, nil, nil, nil, nil, nil, nil) {
+		t.Error("expected untagged article not to match tag condition")
+	}
+}
+
+func TestEvaluateCondition_TextFields(t *testing.T) {
+	article := models.Article{
+		ID:      7,
+		Title:   "Weekly Go digest",
+		URL:     "https://blog.example.com/posts/go-digest",
+		Summary: "Generics, fuzzing and more",
+	}
+	matcher := newTextMatcher(nil, map[int64]string{
+		7: "<p>This week: <b>C++</b> interop &amp; the new scheduler.</p>",
+	})
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{"content contains plain text", Condition{Field: "article_content", Value: "interop & the"}, true},
+		{"content ignores markup", Condition{Field: "article_content", Value: "<b>"}, false},
+		{"content word", Condition{Field: "article_content", Operator: "word", Value: "c++"}, true},
+		{"content word needs boundary", Condition{Field: "article_content", Operator: "word", Value: "sched"}, false},
+		{"content regex", Condition{Field: "article_content", Operator: "regex", Value: `new\s+scheduler`}, true},
+		{"url starts_with", Condition{Field: "article_url", Operator: "starts_with", Value: "HTTPS://blog.example.com/"}, true},
+		{"url starts_with mismatch", Condition{Field: "article_url", Operator: "starts_with", Value: "https://news."}, false},
+		{"summary exact", Condition{Field: "article_summary", Operator: "exact", Value: "generics, fuzzing and more"}, true},
+		{"title word", Condition{Field: "article_title", Operator: "word", Value: "go"}, true},
+		{"invalid regex never matches", Condition{Field: "article_title", Operator: "regex", Value: "("}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateCondition(article, tt.condition, nil, nil, nil, nil, nil, matcher); got != tt.want {
+				t.Errorf("evaluateCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_AddTagAction(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	feedID, err := engine.db.AddFeed(&models.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	if err := engine.db.SaveArticle(&models.Article{FeedID: feedID, Title: "Paper to cite", URL: "https://example.com/a"}); err != nil {
+		t.Fatalf("SaveArticle failed: %v", err)
+	}
+	articles, err := engine.db.GetArticles("", feedID, "", true, 10, 0)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("GetArticles failed: %v", err)
+	}
+
+	rule := Rule{Enabled: true, Actions: []string{"add_tag:to-cite"}}
+	if _, err := engine.ApplyRule(rule); err != nil {
+		t.Fatalf("ApplyRule failed: %v", err)
+	}
+
+	tags, err := engine.db.GetArticleTags(articles[0].ID)
+	if err != nil {
+		t.Fatalf("GetArticleTags failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "to-cite" {
+		t.Errorf("Expected article to be tagged to-cite, got %v", tags)
+	}
+}
+
+func TestEngine_PreviewRule(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	feedID, err := engine.db.AddFeed(&models.Feed{Title: "Tech", URL: "https://example.com/feed", Category: "news"})
+	if err != nil {
+		t.Fatalf("AddFeed failed: %v", err)
+	}
+	for _, title := range []string{"Go 1.30 released", "Rust 2.0 released", "Weekly digest"} {
+		if err := engine.db.SaveArticle(&models.Article{FeedID: feedID, Title: title, URL: "https://example.com/" + title}); err != nil {
+			t.Fatalf("SaveArticle failed: %v", err)
+		}
+	}
+
+	rule := Rule{
+		Conditions: []Condition{
+			{Field: "article_title", Operator: "contains", Value: "released"},
+			{Logic: "and", Field: "article_title", Operator: "starts_with", Value: "go"},
+		},
+		Actions: []string{"favorite"},
+	}
+
+	result, err := engine.PreviewRule(rule, 10)
+	if err != nil {
+		t.Fatalf("PreviewRule failed: %v", err)
+	}
+	if result.Checked != 3 || result.Truncated || result.Matched != 1 || result.Samples[0].Title != "Go 1.30 released" {
+		t.Fatalf("Unexpected preview result: %+v", result)
+	}
+	// "Rust 2.0 released" only fails the second condition; "Weekly digest" fails both
+	if result.NearMissCount != 1 || result.NearMisses[0].Article.Title != "Rust 2.0 released" || result.NearMisses[0].ConditionIndex != 1 {
+		t.Fatalf("Unexpected near misses: %+v", result.NearMisses)
+	}
+
+	// Preview must not change anything
+	articles, _ := engine.db.GetArticles("favorites", 0, "", true, 10, 0)
+	if len(articles) != 0 {
+		t.Errorf("Expected preview not to apply actions, got %d favorites", len(articles))
+	}
+
+	// A preview over more articles than it evaluates says so
+	defer func(size int) { previewBatchSize = size }(previewBatchSize)
+	previewBatchSize = 2
+	result, err = engine.PreviewRule(rule, 10)
+	if err != nil {
+		t.Fatalf("PreviewRule failed: %v", err)
+	}
+	if result.Checked != 2 || !result.Truncated {
+		t.Errorf("Expected a truncated preview of 2 articles, got %+v", result)
+	}
+}
+
+func TestEngine_RecordsRuleHits(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	rules := []Rule{
+		{ID: 1, Enabled: true, Conditions: []Condition{{Field: "article_title", Value: "test"}}, Actions: []string{"mark_read"}},
+		{ID: 2, Enabled: true, Conditions: []Condition{{Field: "article_title", Value: "never"}}, Actions: []string{"mark_read"}},
+	}
+	rulesJSON, _ := json.Marshal(rules)
+	engine.db.SetSetting("rules", string(rulesJSON))
+
+	articles := []models.Article{{ID: 1, Title: "a test"}, {ID: 2, Title: "another test"}, {ID: 3, Title: "other"}}
+	if _, err := engine.ApplyRulesToArticles(articles); err != nil {
+		t.Fatalf("ApplyRulesToArticles failed: %v", err)
+	}
+
+	stats, err := engine.db.GetRuleStats()
+	if err != nil {
+		t.Fatalf("GetRuleStats failed: %v", err)
+	}
+	if len(stats) != 1 || stats[0].RuleID != 1 || stats[0].HitCount != 2 || stats[0].LastMatchedAt == nil {
+		t.Errorf("Unexpected rule stats: %+v", stats)
+	}
+}
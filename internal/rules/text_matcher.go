@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"MrRSS/internal/database"
+	"MrRSS/internal/filter"
+	"MrRSS/internal/models"
+)
+
+var (
+	htmlTagRegex    = regexp.MustCompile(`<[^>]*>`)
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// textMatcher evaluates text conditions with the filter text matcher.
+// It compiles each regex pattern once and loads article content lazily, so a single
+// matcher should be shared across all articles of one rule application run.
+// A nil *textMatcher is valid and matches without caching or content.
+type textMatcher struct {
+	db       *database.DB
+	patterns *filter.TextMatcher
+	contents map[int64]string // raw content provided by the caller (e.g. RSS body)
+	texts    map[int64]string // plain text of loaded content
+}
+
+// newTextMatcher creates a matcher. contents maps article IDs to their HTML content and
+// may be nil; articles missing from it are looked up in the article content cache.
+func newTextMatcher(db *database.DB, contents map[int64]string) *textMatcher {
+	return &textMatcher{
+		db:       db,
+		patterns: filter.NewTextMatcher(),
+		contents: contents,
+		texts:    make(map[int64]string),
+	}
+}
+
+// text returns the matcher of text conditions, or nil if m is nil
+func (m *textMatcher) text() *filter.TextMatcher {
+	if m == nil {
+		return nil
+	}
+	return m.patterns
+}
+
+// content returns the plain text content of an article
+func (m *textMatcher) content(article models.Article) string {
+	if m == nil {
+		return ""
+	}
+	if text, ok := m.texts[article.ID]; ok {
+		return text
+	}
+
+	raw, ok := m.contents[article.ID]
+	if !ok && m.db != nil && article.ID > 0 {
+		raw, _, _ = m.db.GetArticleContent(article.ID)
+	}
+
+	text := htmlToText(raw)
+	m.texts[article.ID] = text
+	return text
+}
+
+// htmlToText strips tags and entities from HTML content and collapses whitespace
+func htmlToText(content string) string {
+	if content == "" {
+		return ""
+	}
+	text := htmlTagRegex.ReplaceAllString(content, " ")
+	text = html.UnescapeString(text)
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(text, " "))
+}
@@ -0,0 +1,170 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"MrRSS/internal/database"
+)
+
+// Condition field kinds, which decide the allowed operators and values
+const (
+	fieldText        = "text"
+	fieldMultiSelect = "multi_select"
+	fieldBool        = "bool"
+	fieldDate        = "date"
+)
+
+var conditionFields = map[string]string{
+	"feed_name":          fieldMultiSelect,
+	"feed_category":      fieldMultiSelect,
+	"feed_type":          fieldMultiSelect,
+	"article_title":      fieldText,
+	"article_content":    fieldText,
+	"article_url":        fieldText,
+	"article_summary":    fieldText,
+	"article_author":     fieldText,
+	"article_tag":        fieldText,
+	"is_freshrss_feed":   fieldBool,
+	"is_image_mode_feed": fieldBool,
+	"is_read":            fieldBool,
+	"is_favorite":        fieldBool,
+	"is_hidden":          fieldBool,
+	"is_read_later":      fieldBool,
+	"published_after":    fieldDate,
+	"published_before":   fieldDate,
+}
+
+var textOperators = map[string]bool{
+	"":            true,
+	"contains":    true,
+	"exact":       true,
+	"starts_with": true,
+	"word":        true,
+	"regex":       true,
+}
+
+var localActions = map[string]bool{
+	"favorite":          true,
+	"unfavorite":        true,
+	"hide":              true,
+	"unhide":            true,
+	"mark_read":         true,
+	"mark_unread":       true,
+	"read_later":        true,
+	"remove_read_later": true,
+	ActionDelete:        true,
+}
+
+func init() {
+	// Rules saved through the legacy "rules" setting are validated like the ones saved here
+	database.RegisterRulesValidator(ValidateRules)
+}
+
+// ValidateRule checks that every condition uses a known field with an operator and
+// value that fit it, and that every action is known.
+func ValidateRule(rule Rule) error {
+	if err := ValidateConditions(rule.Conditions); err != nil {
+		return err
+	}
+
+	for _, action := range rule.Actions {
+		if err := validateAction(action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateConditions checks that every condition uses a known field with an operator and
+// value that fit it
+func ValidateConditions(conditions []Condition) error {
+	for i, cond := range conditions {
+		if err := validateCondition(cond); err != nil {
+			return fmt.Errorf("condition %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ValidateRules validates a rule set and rejects duplicate rule IDs
+func ValidateRules(rules []Rule) error {
+	seen := make(map[int64]bool, len(rules))
+	for _, rule := range rules {
+		if rule.ID != 0 {
+			if seen[rule.ID] {
+				return fmt.Errorf("duplicate rule id %d", rule.ID)
+			}
+			seen[rule.ID] = true
+		}
+		if err := ValidateRule(rule); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateCondition(cond Condition) error {
+	switch cond.Logic {
+	case "", "and", "or":
+	default:
+		return fmt.Errorf("unknown logic %q", cond.Logic)
+	}
+
+	kind, ok := conditionFields[cond.Field]
+	if !ok {
+		return fmt.Errorf("unknown field %q", cond.Field)
+	}
+
+	switch kind {
+	case fieldText:
+		if !textOperators[cond.Operator] {
+			return fmt.Errorf("operator %q is not supported for field %q", cond.Operator, cond.Field)
+		}
+		if len(cond.Values) > 0 && cond.Field != "article_tag" {
+			return fmt.Errorf("field %q takes a single value", cond.Field)
+		}
+		if cond.Operator == "regex" {
+			if _, err := regexp.Compile(cond.Value); err != nil {
+				return fmt.Errorf("invalid regex for field %q: %w", cond.Field, err)
+			}
+		}
+	default:
+		// Non-text fields ignore the operator; "contains" is what the editor sets by default
+		if cond.Operator != "" && cond.Operator != "contains" {
+			return fmt.Errorf("operator %q is not supported for field %q", cond.Operator, cond.Field)
+		}
+	}
+
+	switch kind {
+	case fieldBool:
+		if cond.Value != "" && cond.Value != "true" && cond.Value != "false" {
+			return fmt.Errorf("field %q expects \"true\" or \"false\", got %q", cond.Field, cond.Value)
+		}
+	case fieldDate:
+		if cond.Value != "" {
+			if _, err := time.Parse("2006-01-02", cond.Value); err != nil {
+				return fmt.Errorf("field %q expects a YYYY-MM-DD date, got %q", cond.Field, cond.Value)
+			}
+		}
+	}
+	if (kind == fieldBool || kind == fieldDate) && len(cond.Values) > 0 {
+		return fmt.Errorf("field %q takes a single value", cond.Field)
+	}
+	return nil
+}
+
+func validateAction(action string) error {
+	if tagName, ok := strings.CutPrefix(action, "add_tag:"); ok {
+		if _, err := database.NormalizeTagName(tagName); err != nil {
+			return fmt.Errorf("action %q: %w", action, err)
+		}
+		return nil
+	}
+	if localActions[action] || isNetworkAction(action) {
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
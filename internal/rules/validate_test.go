@@ -0,0 +1,58 @@
+package rules
+
+import "testing"
+
+func TestValidateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"text contains", Rule{Conditions: []Condition{{Field: "article_title", Operator: "contains", Value: "go"}}, Actions: []string{"favorite"}}, false},
+		{"feed names", Rule{Conditions: []Condition{{Field: "feed_name", Operator: "contains", Values: []string{"A", "B"}}}, Actions: []string{"hide"}}, false},
+		{"boolean", Rule{Conditions: []Condition{{Field: "is_read", Value: "false"}}, Actions: []string{"add_tag:later"}}, false},
+		{"date", Rule{Conditions: []Condition{{Logic: "and", Field: "published_after", Value: "2024-01-31"}}, Actions: []string{ActionWebhook}}, false},
+		{"unknown field", Rule{Conditions: []Condition{{Field: "article_color", Value: "red"}}}, true},
+		{"operator on multi-select", Rule{Conditions: []Condition{{Field: "feed_category", Operator: "regex", Value: "x"}}}, true},
+		{"invalid regex", Rule{Conditions: []Condition{{Field: "article_url", Operator: "regex", Value: "(["}}}, true},
+		{"bad boolean", Rule{Conditions: []Condition{{Field: "is_favorite", Value: "yes"}}}, true},
+		{"bad date", Rule{Conditions: []Condition{{Field: "published_before", Value: "31/01/2024"}}}, true},
+		{"values on text field", Rule{Conditions: []Condition{{Field: "article_title", Values: []string{"a"}}}}, true},
+		{"unknown logic", Rule{Conditions: []Condition{{Logic: "xor", Field: "article_title"}}}, true},
+		{"unknown action", Rule{Actions: []string{"explode"}}, true},
+		{"empty tag", Rule{Actions: []string{"add_tag:"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRules_DuplicateID(t *testing.T) {
+	if err := ValidateRules([]Rule{{ID: 1}, {ID: 1}}); err == nil {
+		t.Error("Expected error for duplicate rule IDs")
+	}
+}
+
+func TestRulesSetting_Validated(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	if err := engine.db.SetSetting("rules", `[{"id":1,"name":"ok","conditions":[{"field":"article_title","value":"go"}],"actions":["mark_read"]}]`); err != nil {
+		t.Fatalf("SetSetting with valid rules failed: %v", err)
+	}
+	if err := engine.db.SetSetting("rules", `[{"id":1,"name":"bad","conditions":[{"field":"is_read","value":"maybe"}],"actions":["mark_read"]}]`); err == nil {
+		t.Fatal("Expected SetSetting to reject an invalid rule")
+	}
+	rules, err := engine.db.GetRules()
+	if err != nil {
+		t.Fatalf("GetRules failed: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Name != "ok" {
+		t.Errorf("Expected the rejected save to leave the rules alone, got %+v", rules)
+	}
+}
@@ -0,0 +1,92 @@
+package search
+
+import (
+	"strings"
+)
+
+// BuildMatchQuery translates a user search query into an FTS5 MATCH expression.
+//
+// Supported syntax:
+//   - plain words are ANDed:           rust async
+//   - double-quoted phrases:           "open source"
+//   - prefix queries with a trailing *: transl*
+//   - OR between terms:                golang OR rust
+//
+// Every term is quoted so FTS5 operators and punctuation in user input
+// cannot produce syntax errors. CJK words are segmented the same way as
+// the indexed text, so a Chinese word becomes a phrase of its segments.
+// Returns "" if the query contains no searchable terms.
+func BuildMatchQuery(query string) string {
+	var parts []string
+	lastWasTerm := false
+
+	for _, token := range splitQuery(query) {
+		if token.text == "OR" && !token.quoted {
+			if lastWasTerm {
+				parts = append(parts, "OR")
+				lastWasTerm = false
+			}
+			continue
+		}
+
+		prefix := false
+		text := token.text
+		if !token.quoted && strings.HasSuffix(text, "*") {
+			prefix = true
+			text = strings.TrimRight(text, "*")
+		}
+
+		words := strings.Fields(SegmentText(text))
+		if len(words) == 0 {
+			continue
+		}
+
+		term := `"` + strings.ReplaceAll(strings.Join(words, " "), `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		parts = append(parts, term)
+		lastWasTerm = true
+	}
+
+	// Drop a dangling OR at the end
+	if len(parts) > 0 && parts[len(parts)-1] == "OR" {
+		parts = parts[:len(parts)-1]
+	}
+
+	return strings.Join(parts, " ")
+}
+
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits a query on whitespace, keeping double-quoted phrases together
+func splitQuery(query string) []queryToken {
+	var tokens []queryToken
+	var current strings.Builder
+	inQuotes := false
+
+	flush := func(quoted bool) {
+		if current.Len() > 0 {
+			tokens = append(tokens, queryToken{text: current.String(), quoted: quoted})
+			current.Reset()
+		}
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			flush(inQuotes)
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n' || r == '　'):
+			flush(false)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush(inQuotes)
+
+	return tokens
+}
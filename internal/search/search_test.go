@@ -0,0 +1,69 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildMatchQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"single word", "golang", `"golang"`},
+		{"multiple words", "rust async", `"rust" "async"`},
+		{"phrase", `"open source" news`, `"open source" "news"`},
+		{"prefix", "transl*", `"transl"*`},
+		{"or", "golang OR rust", `"golang" OR "rust"`},
+		{"dangling or", "golang OR", `"golang"`},
+		{"leading or", "OR golang", `"golang"`},
+		{"operators are quoted", "NOT AND", `"NOT" "AND"`},
+		{"quotes are escaped", `a"b`, `"a" "b"`},
+		{"empty", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildMatchQuery(tt.query); got != tt.want {
+				t.Errorf("BuildMatchQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareForIndex(t *testing.T) {
+	got := PrepareForIndex("<p>Hello &amp; <b>world</b></p>")
+	if got != "Hello & world" {
+		t.Errorf("PrepareForIndex() = %q", got)
+	}
+}
+
+func TestSegmentTextCJK(t *testing.T) {
+	segmented := SegmentText("我们喜欢阅读新闻")
+	if !strings.Contains(segmented, " ") {
+		t.Errorf("expected CJK text to be segmented, got %q", segmented)
+	}
+	if strings.ReplaceAll(segmented, " ", "") != "我们喜欢阅读新闻" {
+		t.Errorf("segmentation should not change characters, got %q", segmented)
+	}
+	if SegmentText("plain english") != "plain english" {
+		t.Error("non-CJK text should be unchanged")
+	}
+}
+
+func TestFormatSnippet(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"我们 喜欢 " + SnippetStart + "阅读" + SnippetEnd + " 新闻", "我们喜欢<mark>阅读</mark>新闻"},
+		{"Go 语言 " + SnippetStart + "news" + SnippetEnd, "Go 语言 <mark>news</mark>"},
+		{"<script> " + SnippetStart + "x" + SnippetEnd, "&lt;script&gt; <mark>x</mark>"},
+	}
+	for _, tt := range tests {
+		if got := FormatSnippet(tt.in); got != tt.want {
+			t.Errorf("FormatSnippet(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
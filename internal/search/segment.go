@@ -0,0 +1,118 @@
+// Package search provides text preparation for the SQLite FTS5 article index:
+// HTML stripping, CJK word segmentation and user query translation.
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-ego/gse"
+)
+
+// Global segmenter instance with lazy initialization, shared with the summary package
+var (
+	segmenter     gse.Segmenter
+	segmenterOnce sync.Once
+)
+
+var (
+	htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+	spaceRegex   = regexp.MustCompile(`\s+`)
+)
+
+// Segmenter returns the global gse segmenter, loading the dictionary on first use.
+func Segmenter() *gse.Segmenter {
+	segmenterOnce.Do(func() {
+		// Load default dictionary for Chinese segmentation
+		segmenter.LoadDict()
+	})
+	return &segmenter
+}
+
+// isCJK reports whether r is a Chinese, Japanese or Korean character
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// HasCJK reports whether the text contains any CJK characters.
+func HasCJK(text string) bool {
+	for _, r := range text {
+		if isCJK(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// SegmentText inserts spaces between CJK words so the FTS5 unicode61 tokenizer
+// indexes them as separate tokens. Non-CJK text is returned unchanged.
+func SegmentText(text string) string {
+	if !HasCJK(text) {
+		return text
+	}
+	segments := Segmenter().Cut(text, true)
+	return spaceRegex.ReplaceAllString(strings.Join(segments, " "), " ")
+}
+
+// PrepareForIndex strips HTML, decodes entities and segments CJK text for indexing.
+func PrepareForIndex(text string) string {
+	if text == "" {
+		return ""
+	}
+	text = htmlTagRegex.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = strings.TrimSpace(spaceRegex.ReplaceAllString(text, " "))
+	return SegmentText(text)
+}
+
+// Snippet markers passed to the FTS5 snippet() function. Control characters are
+// used so they cannot collide with article text; FormatSnippet turns them into
+// HTML highlight tags after escaping.
+const (
+	SnippetStart = "\x02"
+	SnippetEnd   = "\x03"
+)
+
+// FormatSnippet converts a raw FTS5 snippet into safe HTML with <mark> highlights.
+func FormatSnippet(snippet string) string {
+	snippet = html.EscapeString(JoinCJK(snippet))
+	snippet = strings.ReplaceAll(snippet, SnippetStart, "<mark>")
+	return strings.ReplaceAll(snippet, SnippetEnd, "</mark>")
+}
+
+// JoinCJK removes the spaces that SegmentText inserted between CJK characters,
+// so snippets read naturally again. Snippet markers are kept in place.
+func JoinCJK(text string) string {
+	if !HasCJK(text) {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == ' ' && isCJK(lastVisibleRune(text[:i])) && isCJK(firstVisibleRune(text[i+1:])) {
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// lastVisibleRune returns the last rune of s, ignoring trailing snippet markers
+func lastVisibleRune(s string) rune {
+	s = strings.TrimRight(s, SnippetStart+SnippetEnd)
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// firstVisibleRune returns the first rune of s, ignoring leading snippet markers
+func firstVisibleRune(s string) rune {
+	s = strings.TrimLeft(s, SnippetStart+SnippetEnd)
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
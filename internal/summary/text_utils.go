@@ -3,25 +3,17 @@ package summary
 import (
 	"regexp"
 	"strings"
-	"sync"
 	"unicode"
 
-	"github.com/go-ego/gse"
-)
+	"MrRSS/internal/search"
 
-// Global segmenter instance with lazy initialization
-var (
-	segmenter     gse.Segmenter
-	segmenterOnce sync.Once
+	"github.com/go-ego/gse"
 )
 
-// getSegmenter returns the global segmenter, initializing it if necessary
+// getSegmenter returns the global segmenter shared with the search index,
+// so the dictionary is only loaded once
 func getSegmenter() *gse.Segmenter {
-	segmenterOnce.Do(func() {
-		// Load default dictionary for Chinese segmentation
-		segmenter.LoadDict()
-	})
-	return &segmenter
+	return search.Segmenter()
 }
 
 // cleanText removes HTML tags and normalizes whitespace
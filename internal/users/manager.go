@@ -0,0 +1,199 @@
+// Package users runs the accounts of the multi-user server build. Each user's
+// subscriptions, article state, rules, chat sessions, AI usage and statistics live in a
+// database of their own, served by a handler of their own. The main database holds the
+// users and their tokens, and the data of the first admin, so an existing single-user
+// installation becomes that admin's account.
+package users
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"MrRSS/internal/auth"
+	"MrRSS/internal/database"
+	"MrRSS/internal/feed"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+)
+
+// dbDir is the directory of the user databases, relative to the main database
+const dbDir = "users"
+
+// HandlerFactory creates the handler serving a user's database
+type HandlerFactory func(db *database.DB) *core.Handler
+
+// Manager resolves requests to the handler of the user making them.
+type Manager struct {
+	db         *database.DB  // main database
+	primary    *core.Handler // handler of the main database
+	dataDir    string        // directory of the main database
+	newHandler HandlerFactory
+	ctx        context.Context // lifetime of the background schedulers
+
+	mu       sync.Mutex
+	handlers map[int64]*userHandler
+}
+
+// userHandler is the handler of a user with their own database
+type userHandler struct {
+	h      *core.Handler
+	file   string
+	cancel context.CancelFunc
+}
+
+// NewManager creates a manager for the users of the main database served by primary.
+// Handlers of other users are created with newHandler; their background schedulers
+// run until ctx is canceled.
+func NewManager(ctx context.Context, primary *core.Handler, dataDir string, newHandler HandlerFactory) *Manager {
+	return &Manager{
+		db:         primary.DB,
+		primary:    primary,
+		dataDir:    dataDir,
+		newHandler: newHandler,
+		ctx:        ctx,
+		handlers:   make(map[int64]*userHandler),
+	}
+}
+
+// Start opens the databases of all users and starts their background schedulers,
+// so their feeds refresh and their sync APIs answer before they log in.
+func (m *Manager) Start() error {
+	users, err := m.db.GetUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if _, err := m.Handler(&user); err != nil {
+			return fmt.Errorf("user %s: %w", user.Username, err)
+		}
+	}
+	return nil
+}
+
+// Handler returns the handler of a user, opening their database on first use.
+func (m *Manager) Handler(user *models.User) (*core.Handler, error) {
+	if user.DBFile == "" {
+		return m.primary, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if uh := m.handlers[user.ID]; uh != nil {
+		return uh.h, nil
+	}
+
+	file := filepath.Join(m.dataDir, filepath.FromSlash(user.DBFile))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	db, err := database.NewDB(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Init(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	h := m.newHandler(db)
+	h.Fetcher.SetWebSubPath(fmt.Sprintf("%susers/%d/", feed.DefaultWebSubPath, user.ID))
+	ctx, cancel := context.WithCancel(m.ctx)
+	go h.StartBackgroundScheduler(ctx)
+
+	m.handlers[user.ID] = &userHandler{h: h, file: file, cancel: cancel}
+	log.Printf("[Users] Opened database of %s", user.Username)
+	return h, nil
+}
+
+// HandlerByID returns the handler of the user with the given ID.
+func (m *Manager) HandlerByID(id int64) (*core.Handler, error) {
+	user, err := m.db.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.Handler(user)
+}
+
+// ForRequest returns the handler of the user authenticated for a request. Without
+// authentication, everything is served from the main database.
+func (m *Manager) ForRequest(r *http.Request) (*core.Handler, error) {
+	token := auth.TokenFromContext(r.Context())
+	if token == nil {
+		return m.primary, nil
+	}
+	return m.HandlerByID(token.UserID)
+}
+
+// Route adapts an API handler to serve each request from the database of its user.
+func (m *Manager) Route(handle func(*core.Handler, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h, err := m.ForRequest(r)
+		if err != nil {
+			log.Printf("[Users] Error resolving user: %v", err)
+			http.Error(w, "Failed to open user data", http.StatusInternalServerError)
+			return
+		}
+		handle(h, w, r)
+	}
+}
+
+// Databases returns the main database and the open databases of the other users.
+func (m *Manager) Databases() []*database.DB {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	databases := []*database.DB{m.db}
+	for _, uh := range m.handlers {
+		databases = append(databases, uh.h.DB)
+	}
+	return databases
+}
+
+// Close stops the background work of all users and closes their databases.
+// The main database is left to its owner.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, uh := range m.handlers {
+		uh.stop()
+		delete(m.handlers, id)
+	}
+}
+
+// remove stops a user's handler and deletes their database
+func (m *Manager) remove(id int64) {
+	m.mu.Lock()
+	uh := m.handlers[id]
+	delete(m.handlers, id)
+	m.mu.Unlock()
+	if uh == nil {
+		return
+	}
+
+	uh.stop()
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Remove(uh.file + suffix); err != nil && !os.IsNotExist(err) {
+			log.Printf("[Users] Error removing %s: %v", uh.file+suffix, err)
+		}
+	}
+}
+
+// stop cancels the background work of a handler and closes its database
+func (uh *userHandler) stop() {
+	uh.cancel()
+	uh.h.Fetcher.GetTaskManager().Stop()
+	uh.h.Fetcher.GetCleanupManager().Stop()
+	uh.h.Notifications.Stop()
+	if err := uh.h.DB.Close(); err != nil {
+		log.Printf("[Users] Error closing database: %v", err)
+	}
+}
+
+// dbFile returns the database file of a new user, relative to the main database
+func dbFile(id int64) string {
+	return fmt.Sprintf("%s/%d.db", dbDir, id)
+}
@@ -0,0 +1,152 @@
+package users_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"MrRSS/internal/auth"
+	"MrRSS/internal/database"
+	"MrRSS/internal/feed"
+	"MrRSS/internal/handlers/core"
+	"MrRSS/internal/models"
+	"MrRSS/internal/users"
+)
+
+const testPassword = "correct horse"
+
+func newHandler(db *database.DB) *core.Handler {
+	return core.NewHandler(db, feed.NewFetcher(db), nil)
+}
+
+func setupManager(t *testing.T) (*users.Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := database.NewDB(filepath.Join(dir, "rss.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	if _, err := auth.EnsureAdmin(db, testPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := users.NewManager(ctx, newHandler(db), dir, newHandler)
+	t.Cleanup(func() {
+		cancel()
+		m.Close()
+		db.Close()
+	})
+	return m, dir
+}
+
+// as authenticates a request as a user, the way the middleware does
+func as(req *http.Request, userID int64) *http.Request {
+	return req.WithContext(auth.WithToken(req.Context(), &models.AuthToken{UserID: userID}))
+}
+
+func createUser(t *testing.T, m *users.Manager, asUser int64, body string) (*httptest.ResponseRecorder, models.User) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.HandleUsers(w, as(httptest.NewRequest("POST", "/api/users", strings.NewReader(body)), asUser))
+	var user models.User
+	json.NewDecoder(w.Body).Decode(&user)
+	return w, user
+}
+
+func TestManager_SeparatesUserData(t *testing.T) {
+	m, dir := setupManager(t)
+
+	w, bob := createUser(t, m, 1, `{"username":"bob","password":"`+testPassword+`"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users", strconv.FormatInt(bob.ID, 10)+".db")); err != nil {
+		t.Errorf("user database not created: %v", err)
+	}
+
+	admin, err := m.ForRequest(as(httptest.NewRequest("GET", "/api/feeds", nil), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobs, err := m.ForRequest(as(httptest.NewRequest("GET", "/api/feeds", nil), bob.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin == bobs || admin.DB == bobs.DB {
+		t.Fatal("users share a handler")
+	}
+	if len(m.Databases()) != 2 {
+		t.Errorf("databases = %d, want 2", len(m.Databases()))
+	}
+
+	if _, err := bobs.DB.AddFeed(&models.Feed{Title: "Bob's", URL: "https://example.com/bob.xml"}); err != nil {
+		t.Fatal(err)
+	}
+	if feeds, _ := admin.DB.GetFeeds(); len(feeds) != 0 {
+		t.Errorf("admin sees feeds of another user: %+v", feeds)
+	}
+
+	// Without authentication, everything is served from the main database
+	if h, _ := m.ForRequest(httptest.NewRequest("GET", "/api/feeds", nil)); h != admin {
+		t.Error("unauthenticated request not served from the main database")
+	}
+}
+
+func TestHandleUsers(t *testing.T) {
+	m, dir := setupManager(t)
+
+	if w, _ := createUser(t, m, 1, `{"username":"carol","password":"short"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("short password: expected 400, got %d", w.Code)
+	}
+	if w, _ := createUser(t, m, 1, `{"username":"Admin","password":"`+testPassword+`"}`); w.Code != http.StatusConflict {
+		t.Errorf("taken username: expected 409, got %d", w.Code)
+	}
+	_, carol := createUser(t, m, 1, `{"username":"carol","password":"`+testPassword+`"}`)
+	if carol.ID == 0 || carol.IsAdmin {
+		t.Fatalf("unexpected user: %+v", carol)
+	}
+
+	// Only admins manage users
+	if w, _ := createUser(t, m, carol.ID, `{"username":"dave","password":"`+testPassword+`"}`); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin: expected 403, got %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	m.HandleUsers(w, as(httptest.NewRequest("GET", "/api/users", nil), 1))
+	var list []models.User
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list) != 2 || list[1].Username != "carol" {
+		t.Errorf("unexpected users: %+v", list)
+	}
+
+	deleteUser := func(id int64) int {
+		w := httptest.NewRecorder()
+		m.HandleUsers(w, as(httptest.NewRequest("DELETE", "/api/users?id="+strconv.FormatInt(id, 10), nil), 1))
+		return w.Code
+	}
+	if code := deleteUser(1); code != http.StatusForbidden {
+		t.Errorf("delete first admin: expected 403, got %d", code)
+	}
+	if code := deleteUser(1000); code != http.StatusNotFound {
+		t.Errorf("delete unknown user: expected 404, got %d", code)
+	}
+	if code := deleteUser(carol.ID); code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users", strconv.FormatInt(carol.ID, 10)+".db")); !os.IsNotExist(err) {
+		t.Errorf("user database not removed: %v", err)
+	}
+	if len(m.Databases()) != 1 {
+		t.Errorf("databases = %d, want 1", len(m.Databases()))
+	}
+}
@@ -0,0 +1,146 @@
+package users
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MrRSS/internal/auth"
+	"MrRSS/internal/models"
+)
+
+// maxUsernameLength bounds usernames
+const maxUsernameLength = 64
+
+// HandleUsers lists, creates and deletes users. Only admins may manage users.
+// @Summary      Manage users
+// @Description  GET lists the users. POST creates a user with an empty database of their own. DELETE removes a user with all their data; the first admin and the current user can't be deleted.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id       query     int64   false  "User ID (DELETE)"
+// @Param        request  body      object  false  "New user (username, password, is_admin) (POST)"
+// @Success      200  {array}   models.User  "Users (GET)"
+// @Success      201  {object}  models.User  "Created user (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      403  {object}  map[string]string  "Not an admin, or the user can't be deleted"
+// @Failure      404  {object}  map[string]string  "User not found"
+// @Failure      409  {object}  map[string]string  "Username taken"
+// @Security     BearerAuth
+// @Router       /users [get]
+// @Router       /users [post]
+// @Router       /users [delete]
+func (m *Manager) HandleUsers(w http.ResponseWriter, r *http.Request) {
+	token := auth.TokenFromContext(r.Context())
+	if token == nil {
+		http.Error(w, "Authentication is disabled", http.StatusNotFound)
+		return
+	}
+	current, err := m.db.GetUser(token.UserID)
+	if err != nil || !current.IsAdmin {
+		http.Error(w, "Only admins can manage users", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		users, err := m.db.GetUsers()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users)
+
+	case http.MethodPost:
+		m.createUser(w, r)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		user, err := m.db.GetUser(id)
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if user.DBFile == "" || user.ID == current.ID {
+			http.Error(w, "This user can't be deleted", http.StatusForbidden)
+			return
+		}
+
+		if err := m.db.DeleteUser(id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		m.remove(id)
+		log.Printf("[Users] %s deleted user %s", current.Username, user.Username)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createUser adds a user and opens their database
+func (m *Manager) createUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		IsAdmin  bool   `json:"is_admin"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" || len(req.Username) > maxUsernameLength {
+		http.Error(w, "Username is required", http.StatusBadRequest)
+		return
+	}
+	hash, err := auth.HashPassword(req.Password)
+	if err == auth.ErrPasswordTooShort {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, _, err := m.db.GetUserByUsername(req.Username); err == nil {
+		http.Error(w, "Username is already taken", http.StatusConflict)
+		return
+	}
+	user := &models.User{Username: req.Username, IsAdmin: req.IsAdmin}
+	if err := m.db.CreateUser(user, hash); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	user.DBFile = dbFile(user.ID)
+	err = m.db.SetUserDBFile(user.ID, user.DBFile)
+	if err == nil {
+		_, err = m.Handler(user)
+	}
+	if err != nil {
+		m.db.DeleteUser(user.ID)
+		m.remove(user.ID)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[Users] Created user %s", user.Username)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
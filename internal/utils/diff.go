@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"golang.org/x/net/html"
+)
+
+// Word diff operations
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffSegment is a run of text that is unchanged, inserted or deleted between two versions
+type DiffSegment struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// wordTokenRegex splits text into words and the whitespace between them
+var wordTokenRegex = regexp.MustCompile(`\s+|[^\s]+`)
+
+// wordDiffTimeout bounds the time spent diffing very long articles; the diff is
+// less minimal but still correct when it is reached
+const wordDiffTimeout = 2 * time.Second
+
+// WordDiff returns the word-level differences between two texts
+func WordDiff(oldText, newText string) []DiffSegment {
+	// Map every distinct token to a rune so the character diff works on words
+	tokens := []string{""}
+	runeOf := map[string]rune{}
+	encode := func(text string) []rune {
+		words := wordTokenRegex.FindAllString(text, -1)
+		runes := make([]rune, len(words))
+		for i, word := range words {
+			r, ok := runeOf[word]
+			if !ok {
+				r = tokenRune(len(tokens))
+				runeOf[word] = r
+				tokens = append(tokens, word)
+			}
+			runes[i] = r
+		}
+		return runes
+	}
+	decode := func(text string) string {
+		var b strings.Builder
+		for _, r := range text {
+			b.WriteString(tokens[runeToken(r)])
+		}
+		return b.String()
+	}
+
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = wordDiffTimeout
+	diffs := dmp.DiffMainRunes(encode(oldText), encode(newText), false)
+	diffs = dmp.DiffCleanupSemantic(diffs)
+
+	segments := make([]DiffSegment, 0, len(diffs))
+	for _, d := range diffs {
+		op := DiffEqual
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = DiffInsert
+		case diffmatchpatch.DiffDelete:
+			op = DiffDelete
+		}
+		segments = append(segments, DiffSegment{Op: op, Text: decode(d.Text)})
+	}
+	return segments
+}
+
+// tokenRune maps a token index to a valid rune, skipping the UTF-16 surrogate range
+// so the runes survive the conversion to strings
+func tokenRune(i int) rune {
+	if i >= 0xD800 {
+		i += 0x800
+	}
+	return rune(i)
+}
+
+// runeToken is the inverse of tokenRune
+func runeToken(r rune) int {
+	if r >= 0xE000 {
+		r -= 0x800
+	}
+	return int(r)
+}
+
+// HTMLToText returns the visible text of an HTML fragment, with paragraphs
+// and other blocks separated by blank lines
+func HTMLToText(content string) string {
+	if content == "" {
+		return ""
+	}
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	skip := 0
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return strings.TrimSpace(collapseBlankLines(b.String()))
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(strings.Join(strings.Fields(string(tokenizer.Text())), " "))
+				b.WriteString(" ")
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "script", "style":
+				if tokenType == html.StartTagToken {
+					skip++
+				} else if tokenType == html.EndTagToken && skip > 0 {
+					skip--
+				}
+			case "p", "div", "br", "li", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "tr", "figure", "figcaption":
+				b.WriteString("\n\n")
+			}
+		}
+	}
+}
+
+var (
+	// blankLinesRegex matches runs of line breaks with the spaces around them
+	blankLinesRegex = regexp.MustCompile(`[ \t]*\n\s*`)
+	// spacesRegex matches runs of spaces within a line
+	spacesRegex = regexp.MustCompile(`[ \t]+`)
+)
+
+// collapseBlankLines reduces runs of line breaks to a single blank line and runs of
+// spaces to a single space
+func collapseBlankLines(text string) string {
+	return spacesRegex.ReplaceAllString(blankLinesRegex.ReplaceAllString(text, "\n\n"), " ")
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWordDiff(t *testing.T) {
+	diff := WordDiff("The minister resigned on Monday.", "The minister did not resign on Monday.")
+
+	var oldText, newText strings.Builder
+	var inserted, deleted []string
+	for _, seg := range diff {
+		switch seg.Op {
+		case DiffEqual:
+			oldText.WriteString(seg.Text)
+			newText.WriteString(seg.Text)
+		case DiffDelete:
+			oldText.WriteString(seg.Text)
+			deleted = append(deleted, seg.Text)
+		case DiffInsert:
+			newText.WriteString(seg.Text)
+			inserted = append(inserted, seg.Text)
+		}
+	}
+	if oldText.String() != "The minister resigned on Monday." || newText.String() != "The minister did not resign on Monday." {
+		t.Errorf("diff does not rebuild both texts: %q / %q", oldText.String(), newText.String())
+	}
+	if strings.Join(deleted, "") != "resigned" || strings.Join(inserted, "") != "did not resign" {
+		t.Errorf("deleted %q, inserted %q; want whole words", deleted, inserted)
+	}
+
+	if diff := WordDiff("same text", "same text"); len(diff) != 1 || diff[0].Op != DiffEqual {
+		t.Errorf("identical texts: %+v", diff)
+	}
+}
+
+func TestWordDiff_ManyDistinctWords(t *testing.T) {
+	// More distinct words than runes below the surrogate range
+	var words []string
+	for i := 0; i < 60000; i++ {
+		words = append(words, "w"+strconv.Itoa(i))
+	}
+	oldText := strings.Join(words, " ")
+	newText := oldText + " appended"
+
+	var rebuilt strings.Builder
+	for _, seg := range WordDiff(oldText, newText) {
+		if seg.Op != DiffDelete {
+			rebuilt.WriteString(seg.Text)
+		}
+	}
+	if rebuilt.String() != newText {
+		t.Error("diff of a long text does not rebuild the new text")
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	got := HTMLToText(`<p>First  <b>bold</b> paragraph.</p><script>var x = 1;</script><p>Second &amp; last</p>`)
+	want := "First bold paragraph.\n\nSecond & last"
+	if got != want {
+		t.Errorf("HTMLToText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"MrRSS/internal/models"
+
+	"codeberg.org/readeck/go-readability/v2"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// FullTextTimeout is the timeout for fetching the full text of an article
+const FullTextTimeout = 30 * time.Second
+
+// maxFullTextPageSize bounds the size of a web page read for its full text
+const maxFullTextPageSize = 10 << 20
+
+// ErrURLNotAllowed is returned by FetchPublicFullText for pages that aren't http or https
+// URLs on public addresses
+var ErrURLNotAllowed = errors.New("only http and https URLs on public addresses are allowed")
+
+// FetchFullText fetches an article's web page and extracts its content with the
+// full-text settings of its feed, which may be nil.
+func FetchFullText(pageURL string, settings *models.FeedFullTextSettings, requestModifiers ...func(*http.Request)) (string, error) {
+	return fetchFullText(&http.Client{Timeout: FullTextTimeout}, pageURL, settings, requestModifiers...)
+}
+
+// FetchPublicFullText is FetchFullText for URLs given by API clients. It refuses anything but
+// http and https pages on public addresses, including after redirects, so the server can't be
+// used to reach itself or its local network.
+func FetchPublicFullText(pageURL string, settings *models.FeedFullTextSettings, requestModifiers ...func(*http.Request)) (string, error) {
+	if !isWebURL(pageURL) {
+		return "", ErrURLNotAllowed
+	}
+
+	client := NewPublicHTTPClient(FullTextTimeout)
+	return fetchFullText(client, pageURL, settings, requestModifiers...)
+}
+
+// NewPublicHTTPClient returns an HTTP client that only connects to public addresses and
+// only follows redirects to http and https URLs. The address is checked when connecting,
+// after DNS resolution; no proxy is used so the check applies to the request's host itself.
+// Refused connections fail with ErrURLNotAllowed.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrURLNotAllowed
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !isWebURL(req.URL.String()) {
+				return ErrURLNotAllowed
+			}
+			return nil
+		},
+	}
+}
+
+// isWebURL reports whether rawURL is an absolute http or https URL
+func isWebURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+// fetchFullText fetches and extracts a page with client
+func fetchFullText(client *http.Client, pageURL string, settings *models.FeedFullTextSettings, requestModifiers ...func(*http.Request)) (string, error) {
+	if _, err := url.ParseRequestURI(pageURL); err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, modify := range requestModifiers {
+		modify(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch page: status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "html") {
+		return "", fmt.Errorf("page is not an HTML document (%s)", contentType)
+	}
+
+	return ExtractFullText(io.LimitReader(resp.Body, maxFullTextPageSize), resp.Request.URL, settings)
+}
+
+// ExtractFullText extracts the article content of a web page. Elements matching the
+// strip selectors are removed first; the content selector then picks the article
+// body. Without a content selector, or when it matches nothing, readability picks it.
+func ExtractFullText(page io.Reader, pageURL *url.URL, settings *models.FeedFullTextSettings) (string, error) {
+	doc, err := html.Parse(page)
+	if err != nil {
+		return "", fmt.Errorf("parse page: %w", err)
+	}
+
+	if settings != nil {
+		for _, selector := range settings.StripSelectors {
+			if strings.TrimSpace(selector) == "" {
+				continue
+			}
+			nodes, err := selectNodes(doc, selector)
+			if err != nil {
+				return "", err
+			}
+			for _, node := range nodes {
+				if node.Parent != nil {
+					node.Parent.RemoveChild(node)
+				}
+			}
+		}
+
+		if strings.TrimSpace(settings.ContentSelector) != "" {
+			nodes, err := selectNodes(doc, settings.ContentSelector)
+			if err != nil {
+				return "", err
+			}
+			if len(nodes) > 0 {
+				return renderSelected(nodes, pageURL)
+			}
+			DebugLog("Content selector %q matched nothing on %s, using readability", settings.ContentSelector, pageURL)
+		}
+	}
+
+	article, err := readability.FromDocument(doc, pageURL)
+	if err != nil {
+		return "", fmt.Errorf("readability parse: %w", err)
+	}
+
+	// Render the article content as HTML
+	var buf bytes.Buffer
+	if err := article.RenderHTML(&buf); err != nil {
+		return "", fmt.Errorf("render HTML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// ValidateSelector checks that a selector is a valid CSS selector, or a valid XPath
+// expression if it starts with "/" or "(".
+func ValidateSelector(selector string) error {
+	_, err := selectNodes(&html.Node{Type: html.DocumentNode}, selector)
+	return err
+}
+
+// selectNodes returns the nodes matching a CSS or XPath selector
+func selectNodes(doc *html.Node, selector string) ([]*html.Node, error) {
+	selector = strings.TrimSpace(selector)
+	if strings.HasPrefix(selector, "/") || strings.HasPrefix(selector, "(") {
+		nodes, err := htmlquery.QueryAll(doc, selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XPath %q: %w", selector, err)
+		}
+		return nodes, nil
+	}
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSS selector %q: %w", selector, err)
+	}
+	return sel.MatchAll(doc), nil
+}
+
+// renderSelected renders the nodes picked by a content selector, with absolute links
+// and without scripts and styles
+func renderSelected(nodes []*html.Node, pageURL *url.URL) (string, error) {
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		resolveURLs(node, pageURL)
+		if err := html.Render(&buf, node); err != nil {
+			return "", fmt.Errorf("render HTML: %w", err)
+		}
+	}
+	return CleanHTML(buf.String()), nil
+}
+
+// resolveURLs makes the links and media sources below a node absolute
+func resolveURLs(node *html.Node, base *url.URL) {
+	if base == nil {
+		return
+	}
+	if node.Type == html.ElementNode {
+		for i, attr := range node.Attr {
+			if attr.Key != "href" && attr.Key != "src" && attr.Key != "poster" {
+				continue
+			}
+			if ref, err := url.Parse(strings.TrimSpace(attr.Val)); err == nil {
+				node.Attr[i].Val = base.ResolveReference(ref).String()
+			}
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		resolveURLs(child, base)
+	}
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+const fullTextTestPage = `<!DOCTYPE html>
+<html><head><title>Teaser feeds</title></head>
+<body>
+<nav><a href="/">Home</a><a href="/about">About</a></nav>
+<main>
+<article class="post">
+<h1>Why feeds only carry teasers</h1>
+<div class="body">
+<p>Many publishers only put a short teaser into their feeds, so readers have to open the page for the rest of the article. This paragraph is long enough for readability to consider it content.</p>
+<div class="share">Share this on social media</div>
+<p>A second paragraph with an <a href="/related">internal link</a> and an image <img src="img/chart.png">, written so the extraction has more than one paragraph to keep around.</p>
+<script>track()</script>
+</div>
+</article>
+</main>
+<footer>Copyright footer</footer>
+</body></html>`
+
+func TestFetchFullText(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "Custom-UA" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, fullTextTestPage)
+	}))
+	defer srv.Close()
+
+	withUA := func(req *http.Request) { req.Header.Set("User-Agent", "Custom-UA") }
+
+	t.Run("Readability", func(t *testing.T) {
+		content, err := FetchFullText(srv.URL+"/posts/1", nil, withUA)
+		if err != nil {
+			t.Fatalf("FetchFullText error: %v", err)
+		}
+		if !strings.Contains(content, "short teaser") || strings.Contains(content, "Copyright footer") {
+			t.Errorf("unexpected content: %s", content)
+		}
+	})
+
+	t.Run("CSS selectors", func(t *testing.T) {
+		settings := &models.FeedFullTextSettings{ContentSelector: "article .body", StripSelectors: []string{".share"}}
+		content, err := FetchFullText(srv.URL+"/posts/1", settings, withUA)
+		if err != nil {
+			t.Fatalf("FetchFullText error: %v", err)
+		}
+		if !strings.Contains(content, "second paragraph") {
+			t.Errorf("selected content missing: %s", content)
+		}
+		for _, unwanted := range []string{"Share this", "track()", "Home", "Why feeds only"} {
+			if strings.Contains(content, unwanted) {
+				t.Errorf("content contains %q: %s", unwanted, content)
+			}
+		}
+		if !strings.Contains(content, `href="`+srv.URL+`/related"`) || !strings.Contains(content, `src="`+srv.URL+`/posts/img/chart.png"`) {
+			t.Errorf("links not made absolute: %s", content)
+		}
+	})
+
+	t.Run("XPath selectors", func(t *testing.T) {
+		settings := &models.FeedFullTextSettings{ContentSelector: "//article", StripSelectors: []string{"//h1", "//div[@class='share']"}}
+		content, err := FetchFullText(srv.URL+"/posts/1", settings, withUA)
+		if err != nil {
+			t.Fatalf("FetchFullText error: %v", err)
+		}
+		if !strings.Contains(content, "short teaser") || strings.Contains(content, "Why feeds only") || strings.Contains(content, "Share this") {
+			t.Errorf("unexpected content: %s", content)
+		}
+	})
+
+	t.Run("Unmatched selector falls back to readability", func(t *testing.T) {
+		settings := &models.FeedFullTextSettings{ContentSelector: ".does-not-exist"}
+		content, err := FetchFullText(srv.URL+"/posts/1", settings, withUA)
+		if err != nil || !strings.Contains(content, "short teaser") {
+			t.Errorf("content = %q, err = %v", content, err)
+		}
+	})
+
+	t.Run("Request modifiers are applied", func(t *testing.T) {
+		if _, err := FetchFullText(srv.URL+"/posts/1", nil); err == nil {
+			t.Error("expected an error without the required User-Agent")
+		}
+	})
+}
+
+func TestFetchPublicFullText(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, fullTextTestPage)
+	}))
+	defer srv.Close()
+
+	for _, pageURL := range []string{srv.URL + "/posts/1", "http://localhost:1/", "http://[::1]/", "http://10.0.0.1/", "file:///etc/passwd", "ftp://example.com/"} {
+		if _, err := FetchPublicFullText(pageURL, nil); !errors.Is(err, ErrURLNotAllowed) {
+			t.Errorf("%s: err = %v, want ErrURLNotAllowed", pageURL, err)
+		}
+	}
+
+	for _, ip := range []string{"93.184.216.34", "2606:2800:220:1::1"} {
+		if !isPublicIP(net.ParseIP(ip)) {
+			t.Errorf("%s not considered public", ip)
+		}
+	}
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fd00::1", "fe80::1"} {
+		if isPublicIP(net.ParseIP(ip)) {
+			t.Errorf("%s considered public", ip)
+		}
+	}
+}
+
+func TestValidateSelector(t *testing.T) {
+	for _, selector := range []string{"article .body", "div.share, aside", "//div[@id='content']", "(//p)[1]"} {
+		if err := ValidateSelector(selector); err != nil {
+			t.Errorf("ValidateSelector(%q) error: %v", selector, err)
+		}
+	}
+	for _, selector := range []string{"div[", "//div[", "::"} {
+		if err := ValidateSelector(selector); err == nil {
+			t.Errorf("ValidateSelector(%q) accepted an invalid selector", selector)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"MrRSS/internal/models"
+)
+
+// ApplyFeedHTTPSettings applies a feed's request settings to req.
+// The User-Agent is always applied; headers, cookie and credentials are only sent
+// to the host of feedURL so they never leak to third-party article pages.
+func ApplyFeedHTTPSettings(req *http.Request, settings *models.FeedHTTPSettings, feedURL string) {
+	if settings.IsEmpty() {
+		return
+	}
+
+	if settings.UserAgent != "" {
+		req.Header.Set("User-Agent", settings.UserAgent)
+	}
+
+	if !sameHost(req.URL, feedURL) {
+		return
+	}
+
+	for name, value := range settings.Headers {
+		req.Header.Set(name, value)
+	}
+	if settings.Cookie != "" {
+		req.Header.Set("Cookie", settings.Cookie)
+	}
+
+	switch strings.ToLower(settings.AuthType) {
+	case "basic":
+		req.SetBasicAuth(settings.AuthUsername, settings.AuthSecret)
+	case "bearer":
+		if settings.AuthSecret != "" {
+			req.Header.Set("Authorization", "Bearer "+settings.AuthSecret)
+		}
+	}
+}
+
+// sameHost reports whether u points to the same host as rawURL
+func sameHost(u *url.URL, rawURL string) bool {
+	if u == nil {
+		return false
+	}
+	other, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Hostname(), other.Hostname())
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	"MrRSS/internal/models"
+)
+
+func TestApplyFeedHTTPSettings(t *testing.T) {
+	settings := &models.FeedHTTPSettings{
+		UserAgent:  "Custom-UA",
+		Headers:    map[string]string{"X-Api-Key": "abc"},
+		Cookie:     "session=1",
+		AuthType:   "bearer",
+		AuthSecret: "tok",
+	}
+
+	req, _ := http.NewRequest("GET", "https://example.com/article/1", nil)
+	ApplyFeedHTTPSettings(req, settings, "https://EXAMPLE.com/feed.xml")
+	if req.Header.Get("User-Agent") != "Custom-UA" {
+		t.Errorf("expected User-Agent to be set, got %q", req.Header.Get("User-Agent"))
+	}
+	if req.Header.Get("X-Api-Key") != "abc" || req.Header.Get("Cookie") != "session=1" {
+		t.Errorf("expected headers and cookie on the feed host, got %v", req.Header)
+	}
+	if req.Header.Get("Authorization") != "Bearer tok" {
+		t.Errorf("expected bearer auth, got %q", req.Header.Get("Authorization"))
+	}
+
+	// Credentials never go to other hosts
+	req, _ = http.NewRequest("GET", "https://other.com/article/1", nil)
+	ApplyFeedHTTPSettings(req, settings, "https://example.com/feed.xml")
+	if req.Header.Get("User-Agent") != "Custom-UA" {
+		t.Errorf("expected User-Agent on other hosts, got %q", req.Header.Get("User-Agent"))
+	}
+	if req.Header.Get("X-Api-Key") != "" || req.Header.Get("Cookie") != "" || req.Header.Get("Authorization") != "" {
+		t.Errorf("expected no credentials for other hosts, got %v", req.Header)
+	}
+
+	// Basic auth
+	settings = &models.FeedHTTPSettings{AuthType: "basic", AuthUsername: "user", AuthSecret: "pass"}
+	req, _ = http.NewRequest("GET", "https://example.com/feed.xml", nil)
+	ApplyFeedHTTPSettings(req, settings, "https://example.com/feed.xml")
+	if user, pass, ok := req.BasicAuth(); !ok || user != "user" || pass != "pass" {
+		t.Errorf("expected basic auth user:pass, got %q:%q (%v)", user, pass, ok)
+	}
+
+	// Nil settings leave the request alone
+	req, _ = http.NewRequest("GET", "https://example.com/feed.xml", nil)
+	ApplyFeedHTTPSettings(req, nil, "https://example.com/feed.xml")
+	if len(req.Header) != 0 {
+		t.Errorf("expected no headers, got %v", req.Header)
+	}
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// CanonicalURL normalizes an article URL so the same story linked from different feeds
+// compares equal: the scheme, "www.", default ports, fragments, trailing slashes and
+// tracking parameters are dropped. URLs of a site's front page return "" because they
+// don't identify a story.
+func CanonicalURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if port := parsed.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := strings.TrimRight(parsed.EscapedPath(), "/")
+	query := parsed.Query()
+	importantParams := make(url.Values)
+	for key, values := range query {
+		if isImportantParameter(key, values) {
+			importantParams[key] = values
+		}
+	}
+	if path == "" && len(importantParams) == 0 {
+		return ""
+	}
+
+	result := host + path
+	if len(importantParams) > 0 {
+		result += "?" + importantParams.Encode()
+	}
+	return result
+}
+
+// similarityTokens splits text into lowercase words for similarity comparisons.
+// Han characters are tokens on their own, since Chinese text has no spaces.
+func similarityTokens(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// minTitleKeyTokens is the number of words a title needs before it is specific enough
+// to identify a story across feeds
+const minTitleKeyTokens = 4
+
+// TitleKey returns a title reduced to its lowercase words, so headlines that only differ
+// in punctuation or case compare equal. Short titles such as "Weekly links" return "".
+func TitleKey(title string) string {
+	tokens := similarityTokens(title)
+	if len(tokens) < minTitleKeyTokens {
+		return ""
+	}
+	return strings.Join(tokens, " ")
+}
+
+const (
+	// simHashShingleSize is the number of consecutive words hashed together
+	simHashShingleSize = 3
+	// minSimHashShingles is the number of shingles a text needs for a stable SimHash;
+	// in shorter texts a single changed word flips too many bits
+	minSimHashShingles = 30
+)
+
+// SimHash returns a 64-bit fingerprint of a text in which similar texts differ in few
+// bits. It returns false if the text is too short to fingerprint reliably.
+func SimHash(text string) (uint64, bool) {
+	tokens := similarityTokens(text)
+	shingles := len(tokens) - simHashShingleSize + 1
+	if shingles < minSimHashShingles {
+		return 0, false
+	}
+
+	var weights [64]int
+	for i := 0; i < shingles; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:i+simHashShingleSize], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash, true
+}
+
+// HammingDistance returns the number of bits in which two SimHashes differ
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"https://www.example.com/news/story/?utm_source=rss&utm_medium=feed", "http://example.com/news/story"},
+		{"https://Example.com:443/news/story#comments", "https://example.com/news/story"},
+		{"https://example.com/read?id=42&fbclid=abc", "https://example.com/read?id=42"},
+	}
+	for _, tt := range tests {
+		if a, b := CanonicalURL(tt.a), CanonicalURL(tt.b); a == "" || a != b {
+			t.Errorf("CanonicalURL(%q) = %q, CanonicalURL(%q) = %q; want equal", tt.a, a, tt.b, b)
+		}
+	}
+
+	if a, b := CanonicalURL("https://example.com/read?id=42"), CanonicalURL("https://example.com/read?id=43"); a == b {
+		t.Errorf("different article IDs share canonical URL %q", a)
+	}
+	for _, u := range []string{"", "not a url", "https://example.com/", "https://www.example.com/?utm_source=rss"} {
+		if got := CanonicalURL(u); got != "" {
+			t.Errorf("CanonicalURL(%q) = %q, want empty", u, got)
+		}
+	}
+}
+
+func TestTitleKey(t *testing.T) {
+	if a, b := TitleKey("Apple announces the new iPhone!"), TitleKey("apple announces the NEW iPhone"); a == "" || a != b {
+		t.Errorf("TitleKey = %q and %q, want equal", a, b)
+	}
+	if got := TitleKey("Weekly links"); got != "" {
+		t.Errorf("TitleKey of a short title = %q, want empty", got)
+	}
+	if got := TitleKey("苹果发布新款手机"); got == "" {
+		t.Error("TitleKey of a Chinese title is empty")
+	}
+}
+
+// councilStory is a news item long enough for a stable SimHash
+const councilStory = "The city council voted on Tuesday to approve a new budget that increases funding for public transport, " +
+	"parks and libraries while cutting administrative costs across several departments over the next two years. " +
+	"Mayor Jane Smith said the plan would make the city more livable, but opposition members warned that the spending " +
+	"commitments rely on optimistic revenue forecasts. Bus fares will be frozen until 2027 and three new tram lines are " +
+	"planned for the eastern districts. The library service will extend its opening hours on weekends, and a programme " +
+	"of tree planting will begin in the spring. Critics argued that the consultation period was too short and that " +
+	"residents of outlying neighbourhoods had been ignored. The budget passed by eleven votes to seven after a debate " +
+	"lasting more than five hours."
+
+func TestSimHash(t *testing.T) {
+	// The same wire story with a small edit and a syndication footer
+	rewritten := strings.Replace(councilStory, "several", "many", 1) + " Read more at Example News."
+	other := "Scientists have discovered a new species of deep sea fish near the coast of New Zealand during an expedition " +
+		"that mapped previously unexplored underwater canyons with autonomous submarines and sonar equipment. The fish, " +
+		"which lives at a depth of more than three thousand metres, has translucent skin and no scales. Researchers said " +
+		"the discovery shows how little is known about life in the deep ocean and called for more funding for marine surveys."
+
+	a, ok := SimHash(councilStory)
+	if !ok {
+		t.Fatal("SimHash rejected a full article")
+	}
+	b, _ := SimHash(rewritten)
+	c, ok := SimHash(other)
+	if !ok {
+		t.Fatal("SimHash rejected a full article")
+	}
+	if d := HammingDistance(a, b); d > 6 {
+		t.Errorf("lightly edited story is %d bits away", d)
+	}
+	if d := HammingDistance(a, c); d < 16 {
+		t.Errorf("unrelated stories are only %d bits apart", d)
+	}
+	if same, _ := SimHash(councilStory); same != a {
+		t.Error("SimHash is not deterministic")
+	}
+
+	if _, ok := SimHash("A teaser that is too short to tell stories apart."); ok {
+		t.Error("SimHash accepted a short text")
+	}
+}
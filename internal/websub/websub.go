@@ -0,0 +1,217 @@
+// Package websub implements the subscriber side of the WebSub (formerly PubSubHubbub) protocol.
+// See https://www.w3.org/TR/websub/ for the specification.
+package websub
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultLeaseSeconds is the lease duration requested from hubs (10 days)
+	DefaultLeaseSeconds = 10 * 24 * 60 * 60
+
+	ModeSubscribe   = "subscribe"
+	ModeUnsubscribe = "unsubscribe"
+	ModeDenied      = "denied"
+)
+
+// Links holds the hub and self (topic) URLs advertised by a feed.
+type Links struct {
+	Hub  string
+	Self string
+}
+
+// DiscoverLinks looks for rel="hub" and rel="self" links in the HTTP Link headers
+// and in the <link> / <atom:link> elements of a feed document.
+// HTTP headers take precedence over links in the document, as required by the spec.
+func DiscoverLinks(header http.Header, body []byte) Links {
+	var links Links
+
+	for _, value := range header.Values("Link") {
+		for _, part := range strings.Split(value, ",") {
+			target, rels := parseLinkHeader(part)
+			if target == "" {
+				continue
+			}
+			for _, rel := range rels {
+				if rel == "hub" && links.Hub == "" {
+					links.Hub = target
+				}
+				if rel == "self" && links.Self == "" {
+					links.Self = target
+				}
+			}
+		}
+	}
+
+	if links.Hub != "" && links.Self != "" {
+		return links
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "link" {
+			continue
+		}
+		var rel, href string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "rel":
+				rel = attr.Value
+			case "href":
+				href = attr.Value
+			}
+		}
+		if href == "" {
+			continue
+		}
+		for _, r := range strings.Fields(rel) {
+			if r == "hub" && links.Hub == "" {
+				links.Hub = href
+			}
+			if r == "self" && links.Self == "" {
+				links.Self = href
+			}
+		}
+	}
+
+	return links
+}
+
+// parseLinkHeader parses a single `<url>; rel="a b"` entry of a Link header.
+func parseLinkHeader(part string) (string, []string) {
+	part = strings.TrimSpace(part)
+	if !strings.HasPrefix(part, "<") {
+		return "", nil
+	}
+	end := strings.Index(part, ">")
+	if end < 0 {
+		return "", nil
+	}
+	target := part[1:end]
+
+	var rels []string
+	for _, param := range strings.Split(part[end+1:], ";") {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(strings.ToLower(param), "rel=") {
+			continue
+		}
+		rels = append(rels, strings.Fields(strings.Trim(param[4:], `"`))...)
+	}
+	return target, rels
+}
+
+// GenerateSecret returns a random hex secret used to sign content distribution requests.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// SubscriptionRequest describes a subscribe or unsubscribe request sent to a hub.
+type SubscriptionRequest struct {
+	Hub          string
+	Topic        string
+	Callback     string
+	Secret       string
+	LeaseSeconds int
+}
+
+// Subscribe asks the hub to start delivering updates for the topic to the callback.
+// The hub verifies the intent asynchronously by calling the callback with a challenge.
+func Subscribe(ctx context.Context, client *http.Client, req SubscriptionRequest) error {
+	return sendRequest(ctx, client, ModeSubscribe, req)
+}
+
+// Unsubscribe asks the hub to stop delivering updates for the topic.
+func Unsubscribe(ctx context.Context, client *http.Client, req SubscriptionRequest) error {
+	return sendRequest(ctx, client, ModeUnsubscribe, req)
+}
+
+func sendRequest(ctx context.Context, client *http.Client, mode string, req SubscriptionRequest) error {
+	form := url.Values{}
+	form.Set("hub.mode", mode)
+	form.Set("hub.topic", req.Topic)
+	form.Set("hub.callback", req.Callback)
+	if mode == ModeSubscribe {
+		if req.Secret != "" {
+			form.Set("hub.secret", req.Secret)
+		}
+		if req.LeaseSeconds > 0 {
+			form.Set("hub.lease_seconds", strconv.Itoa(req.LeaseSeconds))
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.Hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create hub request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to contact hub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("hub returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// VerifySignature checks the X-Hub-Signature header ("method=hexdigest") of a
+// content distribution request against the HMAC of the body using the secret.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	method, digest, ok := strings.Cut(signature, "=")
+	if !ok || digest == "" {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch strings.ToLower(method) {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
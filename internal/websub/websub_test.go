@@ -0,0 +1,130 @@
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiscoverLinks_FromAtomLinks(t *testing.T) {
+	body := []byte(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+<title>Test</title>
+<atom:link rel="hub" href="https://hub.example.com/"/>
+<atom:link rel="self" href="https://example.com/feed.xml" type="application/rss+xml"/>
+</channel>
+</rss>`)
+
+	links := DiscoverLinks(http.Header{}, body)
+	if links.Hub != "https://hub.example.com/" {
+		t.Errorf("expected hub link, got %q", links.Hub)
+	}
+	if links.Self != "https://example.com/feed.xml" {
+		t.Errorf("expected self link, got %q", links.Self)
+	}
+}
+
+func TestDiscoverLinks_HeaderTakesPrecedence(t *testing.T) {
+	header := http.Header{}
+	header.Add("Link", `<https://header-hub.example.com/>; rel="hub", <https://example.com/topic>; rel="self"`)
+	body := []byte(`<feed xmlns="http://www.w3.org/2005/Atom"><link rel="hub" href="https://body-hub.example.com/"/></feed>`)
+
+	links := DiscoverLinks(header, body)
+	if links.Hub != "https://header-hub.example.com/" {
+		t.Errorf("expected hub from header, got %q", links.Hub)
+	}
+	if links.Self != "https://example.com/topic" {
+		t.Errorf("expected self from header, got %q", links.Self)
+	}
+}
+
+func TestDiscoverLinks_NoHub(t *testing.T) {
+	links := DiscoverLinks(http.Header{}, []byte(`<rss><channel><link>https://example.com</link></channel></rss>`))
+	if links.Hub != "" {
+		t.Errorf("expected no hub, got %q", links.Hub)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := "s3cret"
+	body := []byte("<feed></feed>")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !VerifySignature(secret, body, signature) {
+		t.Error("expected valid signature to verify")
+	}
+	if VerifySignature("wrong", body, signature) {
+		t.Error("expected signature with wrong secret to fail")
+	}
+	if VerifySignature(secret, []byte("tampered"), signature) {
+		t.Error("expected signature over tampered body to fail")
+	}
+	if VerifySignature(secret, body, "md5=abcd") {
+		t.Error("expected unsupported method to fail")
+	}
+	if VerifySignature(secret, body, "") {
+		t.Error("expected empty signature to fail")
+	}
+}
+
+func TestSubscribe_SendsFormRequest(t *testing.T) {
+	var gotForm map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm error: %v", err)
+		}
+		gotForm = map[string]string{
+			"hub.mode":          r.PostForm.Get("hub.mode"),
+			"hub.topic":         r.PostForm.Get("hub.topic"),
+			"hub.callback":      r.PostForm.Get("hub.callback"),
+			"hub.secret":        r.PostForm.Get("hub.secret"),
+			"hub.lease_seconds": r.PostForm.Get("hub.lease_seconds"),
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	err := Subscribe(context.Background(), srv.Client(), SubscriptionRequest{
+		Hub:          srv.URL,
+		Topic:        "https://example.com/feed.xml",
+		Callback:     "https://reader.example.com/api/websub/1",
+		Secret:       "abc",
+		LeaseSeconds: 3600,
+	})
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+
+	expected := map[string]string{
+		"hub.mode":          "subscribe",
+		"hub.topic":         "https://example.com/feed.xml",
+		"hub.callback":      "https://reader.example.com/api/websub/1",
+		"hub.secret":        "abc",
+		"hub.lease_seconds": "3600",
+	}
+	for k, v := range expected {
+		if gotForm[k] != v {
+			t.Errorf("%s = %q, want %q", k, gotForm[k], v)
+		}
+	}
+}
+
+func TestSubscribe_HubError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad topic", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := Subscribe(context.Background(), srv.Client(), SubscriptionRequest{Hub: srv.URL, Topic: "x", Callback: "y"})
+	if err == nil {
+		t.Fatal("expected error for non-2xx hub response")
+	}
+}
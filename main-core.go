@@ -13,24 +13,32 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"MrRSS/internal/auth"
 	"MrRSS/internal/database"
 	"MrRSS/internal/feed"
+	"MrRSS/internal/fever"
+	"MrRSS/internal/greader"
 	aihandlers "MrRSS/internal/handlers/ai"
 	article "MrRSS/internal/handlers/article"
+	authhandlers "MrRSS/internal/handlers/auth"
+	backuphandlers "MrRSS/internal/handlers/backup"
 	browser "MrRSS/internal/handlers/browser"
 	chat "MrRSS/internal/handlers/chat"
 	handlers "MrRSS/internal/handlers/core"
 	customcss "MrRSS/internal/handlers/custom_css"
+	digesthandlers "MrRSS/internal/handlers/digest"
 	discovery "MrRSS/internal/handlers/discovery"
 	feedhandlers "MrRSS/internal/handlers/feed"
 	freshrssHandler "MrRSS/internal/handlers/freshrss"
 	media "MrRSS/internal/handlers/media"
 	networkhandlers "MrRSS/internal/handlers/network"
+	notifyhandlers "MrRSS/internal/handlers/notify"
 	opml "MrRSS/internal/handlers/opml"
 	rsshubHandler "MrRSS/internal/handlers/rsshub"
 	rules "MrRSS/internal/handlers/rules"
@@ -38,11 +46,14 @@ import (
 	settings "MrRSS/internal/handlers/settings"
 	stathandlers "MrRSS/internal/handlers/statistics"
 	summary "MrRSS/internal/handlers/summary"
+	taghandlers "MrRSS/internal/handlers/tags"
 	translationhandlers "MrRSS/internal/handlers/translation"
 	update "MrRSS/internal/handlers/update"
+	websubHandler "MrRSS/internal/handlers/websub"
 	window "MrRSS/internal/handlers/window"
 	"MrRSS/internal/network"
 	"MrRSS/internal/translation"
+	"MrRSS/internal/users"
 	"MrRSS/internal/utils"
 
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -65,7 +76,7 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-// @description Type "Bearer" followed by a space and JWT token.
+// @description Type "Bearer" followed by a space and an API token from /auth/tokens. The browser UI uses a session cookie instead, with the X-CSRF-Token header on unsafe requests.
 
 var debugLogging = os.Getenv("MRRSS_DEBUG") != ""
 
@@ -79,13 +90,17 @@ func debugLog(format string, args ...interface{}) {
 var frontendFiles embed.FS
 
 type CombinedHandler struct {
-	apiMux     *http.ServeMux
+	api        http.Handler
 	fileServer http.Handler
 }
 
 func (h *CombinedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if strings.HasPrefix(r.URL.Path, "/api/") {
-		h.apiMux.ServeHTTP(w, r)
+	if strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, fever.PathPrefix) {
+		h.api.ServeHTTP(w, r)
+		return
+	}
+	if r.URL.Path == auth.LoginPath {
+		auth.ServeLoginPage(w, r)
 		return
 	}
 	h.fileServer.ServeHTTP(w, r)
@@ -103,6 +118,8 @@ func main() {
 	})
 	host := flag.String("host", "0.0.0.0", "Host to listen on in server mode")
 	port := flag.String("port", "1234", "Port to listen on in server mode")
+	authEnabled := flag.Bool("auth", true, "Require a password for the web UI and API")
+	password := flag.String("password", os.Getenv("MRRSS_PASSWORD"), "Set the password of the admin user (default $MRRSS_PASSWORD)")
 	flag.Parse()
 
 	// Force server mode for this build
@@ -160,46 +177,76 @@ func main() {
 	}
 	log.Println("Database initialized successfully")
 
-	translator := translation.NewDynamicTranslatorWithCache(db, db)
-	fetcher := feed.NewFetcher(db)
-	h := handlers.NewHandler(db, fetcher, translator)
+	// Secrets encrypted with a master passphrase can be unlocked at startup
+	if passphrase := os.Getenv("MRRSS_MASTER_PASSPHRASE"); passphrase != "" && db.SecretsStatus().Locked {
+		if err := db.UnlockSecrets(passphrase); err != nil {
+			log.Printf("Could not unlock secrets with $MRRSS_MASTER_PASSPHRASE: %v", err)
+		}
+	}
+
+	// Use a context that we can cancel on shutdown for the background schedulers
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+
+	// Every user has a handler on their own database; feed downloads are shared between them
+	sharedFetches := feed.NewSharedFetches()
+	newHandler := func(db *database.DB) *handlers.Handler {
+		translator := translation.NewDynamicTranslatorWithCache(db, db)
+		fetcher := feed.NewFetcher(db)
+		fetcher.ShareFetches(sharedFetches)
+		return handlers.NewHandler(db, fetcher, translator)
+	}
+	h := newHandler(db)
+	userHandlers := users.NewManager(bgCtx, h, filepath.Dir(dbPath), newHandler)
 
 	// API Routes
 	log.Println("Setting up API routes...")
 	apiMux := http.NewServeMux()
-	apiMux.HandleFunc("/api/feeds", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeeds(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/add", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleAddFeed(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/delete", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleDeleteFeed(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/update", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleUpdateFeed(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/refresh", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleRefreshFeed(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover", func(w http.ResponseWriter, r *http.Request) { discovery.HandleDiscoverBlogs(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover-all", func(w http.ResponseWriter, r *http.Request) { discovery.HandleDiscoverAllFeeds(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover/start", func(w http.ResponseWriter, r *http.Request) { discovery.HandleStartSingleDiscovery(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover/progress", func(w http.ResponseWriter, r *http.Request) { discovery.HandleGetSingleDiscoveryProgress(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover/clear", func(w http.ResponseWriter, r *http.Request) { discovery.HandleClearSingleDiscovery(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover-all/start", func(w http.ResponseWriter, r *http.Request) { discovery.HandleStartBatchDiscovery(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover-all/progress", func(w http.ResponseWriter, r *http.Request) { discovery.HandleGetBatchDiscoveryProgress(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/discover-all/clear", func(w http.ResponseWriter, r *http.Request) { discovery.HandleClearBatchDiscovery(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/reorder", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleReorderFeed(h, w, r) })
-	apiMux.HandleFunc("/api/feeds/test-imap", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleTestIMAPConnection(h, w, r) })
-	apiMux.HandleFunc("/api/articles", func(w http.ResponseWriter, r *http.Request) { article.HandleArticles(h, w, r) })
-	apiMux.HandleFunc("/api/articles/images", func(w http.ResponseWriter, r *http.Request) { article.HandleImageGalleryArticles(h, w, r) })
-	apiMux.HandleFunc("/api/articles/filter", func(w http.ResponseWriter, r *http.Request) { article.HandleFilteredArticles(h, w, r) })
-	apiMux.HandleFunc("/api/articles/read", func(w http.ResponseWriter, r *http.Request) { article.HandleMarkReadWithImmediateSync(h, w, r) })
-	apiMux.HandleFunc("/api/articles/favorite", func(w http.ResponseWriter, r *http.Request) { article.HandleToggleFavoriteWithImmediateSync(h, w, r) })
-	apiMux.HandleFunc("/api/articles/cleanup", func(w http.ResponseWriter, r *http.Request) { article.HandleCleanupArticles(h, w, r) })
-	apiMux.HandleFunc("/api/articles/cleanup-content", func(w http.ResponseWriter, r *http.Request) { article.HandleCleanupArticleContent(h, w, r) })
-	apiMux.HandleFunc("/api/articles/content-cache-info", func(w http.ResponseWriter, r *http.Request) { article.HandleGetArticleContentCacheInfo(h, w, r) })
-	apiMux.HandleFunc("/api/articles/translate", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleTranslateArticle(h, w, r) })
-	apiMux.HandleFunc("/api/articles/translate-text", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleTranslateText(h, w, r) })
-	apiMux.HandleFunc("/api/articles/clear-translations", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleClearTranslations(h, w, r) })
-	apiMux.HandleFunc("/api/ai-usage", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleGetAIUsage(h, w, r) })
-	apiMux.HandleFunc("/api/ai-usage/reset", func(w http.ResponseWriter, r *http.Request) { translationhandlers.HandleResetAIUsage(h, w, r) })
-	apiMux.HandleFunc("/api/ai-chat", func(w http.ResponseWriter, r *http.Request) { chat.HandleAIChat(h, w, r) })
-	apiMux.HandleFunc("/api/ai/chat/sessions/delete-all", func(w http.ResponseWriter, r *http.Request) { chat.HandleDeleteAllSessions(h, w, r) })
-	apiMux.HandleFunc("/api/ai/chat/sessions", func(w http.ResponseWriter, r *http.Request) { chat.HandleListSessions(h, w, r) })
-	apiMux.HandleFunc("/api/ai/chat/session/create", func(w http.ResponseWriter, r *http.Request) { chat.HandleCreateSession(h, w, r) })
-	apiMux.HandleFunc("/api/ai/chat/session", func(w http.ResponseWriter, r *http.Request) {
+	apiMux.HandleFunc("/api/auth/login", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleLogin(h, w, r) })
+	apiMux.HandleFunc("/api/auth/logout", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleLogout(h, w, r) })
+	apiMux.HandleFunc("/api/auth/tokens", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleTokens(h, w, r) })
+	apiMux.HandleFunc("/api/auth/password", func(w http.ResponseWriter, r *http.Request) { authhandlers.HandleChangePassword(h, w, r) })
+	apiMux.HandleFunc("/api/users", userHandlers.HandleUsers)
+	apiMux.HandleFunc("/api/feeds", userHandlers.Route(feedhandlers.HandleFeeds))
+	apiMux.HandleFunc("/api/feeds/add", userHandlers.Route(feedhandlers.HandleAddFeed))
+	apiMux.HandleFunc("/api/feeds/delete", userHandlers.Route(feedhandlers.HandleDeleteFeed))
+	apiMux.HandleFunc("/api/feeds/update", userHandlers.Route(feedhandlers.HandleUpdateFeed))
+	apiMux.HandleFunc("/api/feeds/refresh", userHandlers.Route(feedhandlers.HandleRefreshFeed))
+	apiMux.HandleFunc("/api/feeds/discover", userHandlers.Route(discovery.HandleDiscoverBlogs))
+	apiMux.HandleFunc("/api/feeds/discover-all", userHandlers.Route(discovery.HandleDiscoverAllFeeds))
+	apiMux.HandleFunc("/api/feeds/discover/start", userHandlers.Route(discovery.HandleStartSingleDiscovery))
+	apiMux.HandleFunc("/api/feeds/discover/progress", userHandlers.Route(discovery.HandleGetSingleDiscoveryProgress))
+	apiMux.HandleFunc("/api/feeds/discover/clear", userHandlers.Route(discovery.HandleClearSingleDiscovery))
+	apiMux.HandleFunc("/api/feeds/discover-all/start", userHandlers.Route(discovery.HandleStartBatchDiscovery))
+	apiMux.HandleFunc("/api/feeds/discover-all/progress", userHandlers.Route(discovery.HandleGetBatchDiscoveryProgress))
+	apiMux.HandleFunc("/api/feeds/discover-all/clear", userHandlers.Route(discovery.HandleClearBatchDiscovery))
+	apiMux.HandleFunc("/api/feeds/http-settings", userHandlers.Route(feedhandlers.HandleFeedHTTPSettings))
+	apiMux.HandleFunc("/api/feeds/fulltext-settings", userHandlers.Route(feedhandlers.HandleFeedFullTextSettings))
+	apiMux.HandleFunc("/api/feeds/fulltext-test", userHandlers.Route(feedhandlers.HandleTestFullTextExtraction))
+	apiMux.HandleFunc("/api/feeds/email-settings", userHandlers.Route(feedhandlers.HandleFeedEmailSettings))
+	apiMux.HandleFunc("/api/feeds/{id}/health", userHandlers.Route(feedhandlers.HandleFeedHealth))
+	apiMux.HandleFunc("/api/feeds/reorder", userHandlers.Route(feedhandlers.HandleReorderFeed))
+	apiMux.HandleFunc("/api/feeds/test-imap", userHandlers.Route(feedhandlers.HandleTestIMAPConnection))
+	apiMux.HandleFunc("/api/articles", userHandlers.Route(article.HandleArticles))
+	apiMux.HandleFunc("/api/articles/images", userHandlers.Route(article.HandleImageGalleryArticles))
+	apiMux.HandleFunc("/api/articles/filter", userHandlers.Route(article.HandleFilteredArticles))
+	apiMux.HandleFunc("/api/articles/search", userHandlers.Route(article.HandleSearchArticles))
+	apiMux.HandleFunc("/api/articles/categories", userHandlers.Route(article.HandleArticleCategories))
+	apiMux.HandleFunc("/api/articles/tags", userHandlers.Route(article.HandleBulkTagArticles))
+	apiMux.HandleFunc("/api/articles/read", userHandlers.Route(article.HandleMarkReadWithImmediateSync))
+	apiMux.HandleFunc("/api/articles/favorite", userHandlers.Route(article.HandleToggleFavoriteWithImmediateSync))
+	apiMux.HandleFunc("/api/articles/cleanup", userHandlers.Route(article.HandleCleanupArticles))
+	apiMux.HandleFunc("/api/articles/cleanup-content", userHandlers.Route(article.HandleCleanupArticleContent))
+	apiMux.HandleFunc("/api/articles/content-cache-info", userHandlers.Route(article.HandleGetArticleContentCacheInfo))
+	apiMux.HandleFunc("/api/articles/translate", userHandlers.Route(translationhandlers.HandleTranslateArticle))
+	apiMux.HandleFunc("/api/articles/translate-text", userHandlers.Route(translationhandlers.HandleTranslateText))
+	apiMux.HandleFunc("/api/articles/clear-translations", userHandlers.Route(translationhandlers.HandleClearTranslations))
+	apiMux.HandleFunc("/api/ai-usage", userHandlers.Route(translationhandlers.HandleGetAIUsage))
+	apiMux.HandleFunc("/api/ai-usage/reset", userHandlers.Route(translationhandlers.HandleResetAIUsage))
+	apiMux.HandleFunc("/api/ai-chat", userHandlers.Route(chat.HandleAIChat))
+	apiMux.HandleFunc("/api/ai/chat/sessions/delete-all", userHandlers.Route(chat.HandleDeleteAllSessions))
+	apiMux.HandleFunc("/api/ai/chat/sessions", userHandlers.Route(chat.HandleListSessions))
+	apiMux.HandleFunc("/api/ai/chat/session/create", userHandlers.Route(chat.HandleCreateSession))
+	apiMux.HandleFunc("/api/ai/chat/session", userHandlers.Route(func(h *handlers.Handler, w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			chat.HandleGetSession(h, w, r)
@@ -210,69 +257,119 @@ func main() {
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
-	apiMux.HandleFunc("/api/ai/chat/messages", func(w http.ResponseWriter, r *http.Request) { chat.HandleListMessages(h, w, r) })
-	apiMux.HandleFunc("/api/ai/chat/message/delete", func(w http.ResponseWriter, r *http.Request) { chat.HandleDeleteMessage(h, w, r) })
-	apiMux.HandleFunc("/api/ai/test", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleTestAIConfig(h, w, r) })
-	apiMux.HandleFunc("/api/ai/test/info", func(w http.ResponseWriter, r *http.Request) { aihandlers.HandleGetAITestInfo(h, w, r) })
-	apiMux.HandleFunc("/api/articles/toggle-hide", func(w http.ResponseWriter, r *http.Request) { article.HandleToggleHideArticle(h, w, r) })
-	apiMux.HandleFunc("/api/articles/toggle-read-later", func(w http.ResponseWriter, r *http.Request) { article.HandleToggleReadLater(h, w, r) })
-	apiMux.HandleFunc("/api/articles/content", func(w http.ResponseWriter, r *http.Request) { article.HandleGetArticleContent(h, w, r) })
-	apiMux.HandleFunc("/api/articles/fetch-full", func(w http.ResponseWriter, r *http.Request) { article.HandleFetchFullArticle(h, w, r) })
-	apiMux.HandleFunc("/api/articles/unread-counts", func(w http.ResponseWriter, r *http.Request) { article.HandleGetUnreadCounts(h, w, r) })
-	apiMux.HandleFunc("/api/articles/mark-all-read", func(w http.ResponseWriter, r *http.Request) { article.HandleMarkAllAsRead(h, w, r) })
-	apiMux.HandleFunc("/api/articles/clear-read-later", func(w http.ResponseWriter, r *http.Request) { article.HandleClearReadLater(h, w, r) })
-	apiMux.HandleFunc("/api/articles/summarize", func(w http.ResponseWriter, r *http.Request) { summary.HandleSummarizeArticle(h, w, r) })
-	apiMux.HandleFunc("/api/articles/clear-summaries", func(w http.ResponseWriter, r *http.Request) { summary.HandleClearSummaries(h, w, r) })
-	apiMux.HandleFunc("/api/articles/export/obsidian", func(w http.ResponseWriter, r *http.Request) { article.HandleExportToObsidian(h, w, r) })
-	apiMux.HandleFunc("/api/settings", func(w http.ResponseWriter, r *http.Request) { settings.HandleSettings(h, w, r) })
-	apiMux.HandleFunc("/api/refresh", func(w http.ResponseWriter, r *http.Request) { article.HandleRefresh(h, w, r) })
-	apiMux.HandleFunc("/api/progress", func(w http.ResponseWriter, r *http.Request) { article.HandleProgress(h, w, r) })
-	apiMux.HandleFunc("/api/progress/task-details", func(w http.ResponseWriter, r *http.Request) { article.HandleTaskDetails(h, w, r) })
-	apiMux.HandleFunc("/api/opml/import", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLImport(h, w, r) })
-	apiMux.HandleFunc("/api/opml/export", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLExport(h, w, r) })
-	apiMux.HandleFunc("/api/opml/import-dialog", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLImportDialog(h, w, r) })
-	apiMux.HandleFunc("/api/opml/export-dialog", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLExportDialog(h, w, r) })
-	apiMux.HandleFunc("/api/check-updates", func(w http.ResponseWriter, r *http.Request) { update.HandleCheckUpdates(h, w, r) })
-	apiMux.HandleFunc("/api/download-update", func(w http.ResponseWriter, r *http.Request) { update.HandleDownloadUpdate(h, w, r) })
-	apiMux.HandleFunc("/api/install-update", func(w http.ResponseWriter, r *http.Request) { update.HandleInstallUpdate(h, w, r) })
-	apiMux.HandleFunc("/api/version", func(w http.ResponseWriter, r *http.Request) { update.HandleVersion(h, w, r) })
-	apiMux.HandleFunc("/api/rules/apply", func(w http.ResponseWriter, r *http.Request) { rules.HandleApplyRule(h, w, r) })
-	apiMux.HandleFunc("/api/scripts/dir", func(w http.ResponseWriter, r *http.Request) { script.HandleGetScriptsDir(h, w, r) })
-	apiMux.HandleFunc("/api/scripts/open", func(w http.ResponseWriter, r *http.Request) { script.HandleOpenScriptsDir(h, w, r) })
-	apiMux.HandleFunc("/api/scripts/list", func(w http.ResponseWriter, r *http.Request) { script.HandleListScripts(h, w, r) })
-	apiMux.HandleFunc("/api/media/proxy", func(w http.ResponseWriter, r *http.Request) { media.HandleMediaProxy(h, w, r) })
-	apiMux.HandleFunc("/api/media/cleanup", func(w http.ResponseWriter, r *http.Request) { media.HandleMediaCacheCleanup(h, w, r) })
-	apiMux.HandleFunc("/api/media/info", func(w http.ResponseWriter, r *http.Request) { media.HandleMediaCacheInfo(h, w, r) })
-	apiMux.HandleFunc("/api/webpage/proxy", func(w http.ResponseWriter, r *http.Request) { media.HandleWebpageProxy(h, w, r) })
-	apiMux.HandleFunc("/api/webpage/resource", func(w http.ResponseWriter, r *http.Request) { media.HandleWebpageResource(h, w, r) })
-	apiMux.HandleFunc("/api/window/state", func(w http.ResponseWriter, r *http.Request) { window.HandleGetWindowState(h, w, r) })
-	apiMux.HandleFunc("/api/window/save", func(w http.ResponseWriter, r *http.Request) { window.HandleSaveWindowState(h, w, r) })
-	apiMux.HandleFunc("/api/network/detect", func(w http.ResponseWriter, r *http.Request) { networkhandlers.HandleDetectNetwork(h, w, r) })
-	apiMux.HandleFunc("/api/network/info", func(w http.ResponseWriter, r *http.Request) { networkhandlers.HandleGetNetworkInfo(h, w, r) })
-	apiMux.HandleFunc("/api/browser/open", func(w http.ResponseWriter, r *http.Request) { browser.HandleOpenURL(h, w, r) })
-	apiMux.HandleFunc("/api/custom-css/upload-dialog", func(w http.ResponseWriter, r *http.Request) { customcss.HandleUploadCSSDialog(h, w, r) })
-	apiMux.HandleFunc("/api/custom-css/upload", func(w http.ResponseWriter, r *http.Request) { customcss.HandleUploadCSS(h, w, r) })
-	apiMux.HandleFunc("/api/custom-css", func(w http.ResponseWriter, r *http.Request) { customcss.HandleGetCSS(h, w, r) })
-	apiMux.HandleFunc("/api/custom-css/delete", func(w http.ResponseWriter, r *http.Request) { customcss.HandleDeleteCSS(h, w, r) })
-	apiMux.HandleFunc("/api/freshrss/sync", func(w http.ResponseWriter, r *http.Request) { freshrssHandler.HandleSync(h, w, r) })
-	apiMux.HandleFunc("/api/freshrss/sync-feed", func(w http.ResponseWriter, r *http.Request) { freshrssHandler.HandleSyncFeed(h, w, r) })
-	apiMux.HandleFunc("/api/freshrss/status", func(w http.ResponseWriter, r *http.Request) { freshrssHandler.HandleSyncStatus(h, w, r) })
+	}))
+	apiMux.HandleFunc("/api/ai/chat/messages", userHandlers.Route(chat.HandleListMessages))
+	apiMux.HandleFunc("/api/ai/chat/message/delete", userHandlers.Route(chat.HandleDeleteMessage))
+	apiMux.HandleFunc("/api/ai/test", userHandlers.Route(aihandlers.HandleTestAIConfig))
+	apiMux.HandleFunc("/api/ai/test/info", userHandlers.Route(aihandlers.HandleGetAITestInfo))
+	apiMux.HandleFunc("/api/articles/toggle-hide", userHandlers.Route(article.HandleToggleHideArticle))
+	apiMux.HandleFunc("/api/articles/toggle-read-later", userHandlers.Route(article.HandleToggleReadLater))
+	apiMux.HandleFunc("/api/articles/content", userHandlers.Route(article.HandleGetArticleContent))
+	apiMux.HandleFunc("/api/articles/fetch-full", userHandlers.Route(article.HandleFetchFullArticle))
+	apiMux.HandleFunc("/api/articles/revisions", userHandlers.Route(article.HandleArticleRevisions))
+	apiMux.HandleFunc("/api/articles/revisions/diff", userHandlers.Route(article.HandleArticleRevisionDiff))
+	apiMux.HandleFunc("/api/articles/unread-counts", userHandlers.Route(article.HandleGetUnreadCounts))
+	apiMux.HandleFunc("/api/articles/mark-all-read", userHandlers.Route(article.HandleMarkAllAsRead))
+	apiMux.HandleFunc("/api/articles/clear-read-later", userHandlers.Route(article.HandleClearReadLater))
+	apiMux.HandleFunc("/api/articles/summarize", userHandlers.Route(summary.HandleSummarizeArticle))
+	apiMux.HandleFunc("/api/articles/clear-summaries", userHandlers.Route(summary.HandleClearSummaries))
+	apiMux.HandleFunc("/api/articles/export/obsidian", userHandlers.Route(article.HandleExportToObsidian))
+	apiMux.HandleFunc("/api/settings", userHandlers.Route(settings.HandleSettings))
+	apiMux.HandleFunc("/api/secrets/status", userHandlers.Route(settings.HandleSecretsStatus))
+	apiMux.HandleFunc("/api/secrets/unlock", userHandlers.Route(settings.HandleUnlockSecrets))
+	apiMux.HandleFunc("/api/secrets/passphrase", userHandlers.Route(settings.HandleMasterPassphrase))
+	apiMux.HandleFunc("/api/refresh", userHandlers.Route(article.HandleRefresh))
+	apiMux.HandleFunc("/api/progress", userHandlers.Route(article.HandleProgress))
+	apiMux.HandleFunc("/api/progress/task-details", userHandlers.Route(article.HandleTaskDetails))
+	apiMux.HandleFunc("/api/opml/import", userHandlers.Route(opml.HandleOPMLImport))
+	apiMux.HandleFunc("/api/opml/export", userHandlers.Route(opml.HandleOPMLExport))
+	apiMux.HandleFunc("/api/opml/import-dialog", userHandlers.Route(opml.HandleOPMLImportDialog))
+	apiMux.HandleFunc("/api/opml/export-dialog", userHandlers.Route(opml.HandleOPMLExportDialog))
+	apiMux.HandleFunc("/api/backup/export", userHandlers.Route(backuphandlers.HandleBackupExport))
+	apiMux.HandleFunc("/api/backup/import", userHandlers.Route(backuphandlers.HandleBackupImport))
+	apiMux.HandleFunc("/api/check-updates", userHandlers.Route(update.HandleCheckUpdates))
+	apiMux.HandleFunc("/api/download-update", userHandlers.Route(update.HandleDownloadUpdate))
+	apiMux.HandleFunc("/api/install-update", userHandlers.Route(update.HandleInstallUpdate))
+	apiMux.HandleFunc("/api/version", userHandlers.Route(update.HandleVersion))
+	apiMux.HandleFunc("/api/rules", userHandlers.Route(rules.HandleRules))
+	apiMux.HandleFunc("/api/rules/update", userHandlers.Route(rules.HandleUpdateRule))
+	apiMux.HandleFunc("/api/rules/delete", userHandlers.Route(rules.HandleDeleteRule))
+	apiMux.HandleFunc("/api/rules/history", userHandlers.Route(rules.HandleRuleHistory))
+	apiMux.HandleFunc("/api/rules/rollback", userHandlers.Route(rules.HandleRollbackRule))
+	apiMux.HandleFunc("/api/rules/export", userHandlers.Route(rules.HandleExportRules))
+	apiMux.HandleFunc("/api/rules/import", userHandlers.Route(rules.HandleImportRules))
+	apiMux.HandleFunc("/api/rules/apply", userHandlers.Route(rules.HandleApplyRule))
+	apiMux.HandleFunc("/api/rules/preview", userHandlers.Route(rules.HandlePreviewRule))
+	apiMux.HandleFunc("/api/rules/stats", userHandlers.Route(rules.HandleRuleStats))
+	apiMux.HandleFunc("/api/digests", userHandlers.Route(digesthandlers.HandleDigests))
+	apiMux.HandleFunc("/api/digests/update", userHandlers.Route(digesthandlers.HandleUpdateDigest))
+	apiMux.HandleFunc("/api/digests/delete", userHandlers.Route(digesthandlers.HandleDeleteDigest))
+	apiMux.HandleFunc("/api/digests/send", userHandlers.Route(digesthandlers.HandleSendDigest))
+	apiMux.HandleFunc("/api/digests/sends", userHandlers.Route(digesthandlers.HandleDigestSends))
+	apiMux.HandleFunc("/api/notifications/targets", userHandlers.Route(notifyhandlers.HandleNotificationTargets))
+	apiMux.HandleFunc("/api/notifications/targets/update", userHandlers.Route(notifyhandlers.HandleUpdateNotificationTarget))
+	apiMux.HandleFunc("/api/notifications/targets/delete", userHandlers.Route(notifyhandlers.HandleDeleteNotificationTarget))
+	apiMux.HandleFunc("/api/notifications/test", userHandlers.Route(notifyhandlers.HandleTestNotification))
+	apiMux.HandleFunc("/api/notifications/dead-letters", userHandlers.Route(notifyhandlers.HandleDeadNotifications))
+	apiMux.HandleFunc("/api/notifications/dead-letters/retry", userHandlers.Route(notifyhandlers.HandleRetryDeadNotification))
+	apiMux.HandleFunc("/api/notifications/dead-letters/delete", userHandlers.Route(notifyhandlers.HandleDeleteDeadNotification))
+	apiMux.HandleFunc("/api/tags", userHandlers.Route(taghandlers.HandleTags))
+	apiMux.HandleFunc("/api/tags/update", userHandlers.Route(taghandlers.HandleUpdateTag))
+	apiMux.HandleFunc("/api/tags/delete", userHandlers.Route(taghandlers.HandleDeleteTag))
+	apiMux.HandleFunc("/api/scripts/dir", userHandlers.Route(script.HandleGetScriptsDir))
+	apiMux.HandleFunc("/api/scripts/open", userHandlers.Route(script.HandleOpenScriptsDir))
+	apiMux.HandleFunc("/api/scripts/list", userHandlers.Route(script.HandleListScripts))
+	apiMux.HandleFunc("/api/media/proxy", userHandlers.Route(media.HandleMediaProxy))
+	apiMux.HandleFunc("/api/media/cleanup", userHandlers.Route(media.HandleMediaCacheCleanup))
+	apiMux.HandleFunc("/api/media/info", userHandlers.Route(media.HandleMediaCacheInfo))
+	apiMux.HandleFunc("/api/webpage/proxy", userHandlers.Route(media.HandleWebpageProxy))
+	apiMux.HandleFunc("/api/webpage/resource", userHandlers.Route(media.HandleWebpageResource))
+	apiMux.HandleFunc("/api/window/state", userHandlers.Route(window.HandleGetWindowState))
+	apiMux.HandleFunc("/api/window/save", userHandlers.Route(window.HandleSaveWindowState))
+	apiMux.HandleFunc("/api/network/detect", userHandlers.Route(networkhandlers.HandleDetectNetwork))
+	apiMux.HandleFunc("/api/network/info", userHandlers.Route(networkhandlers.HandleGetNetworkInfo))
+	apiMux.HandleFunc("/api/browser/open", userHandlers.Route(browser.HandleOpenURL))
+	apiMux.HandleFunc("/api/custom-css/upload-dialog", userHandlers.Route(customcss.HandleUploadCSSDialog))
+	apiMux.HandleFunc("/api/custom-css/upload", userHandlers.Route(customcss.HandleUploadCSS))
+	apiMux.HandleFunc("/api/custom-css", userHandlers.Route(customcss.HandleGetCSS))
+	apiMux.HandleFunc("/api/custom-css/delete", userHandlers.Route(customcss.HandleDeleteCSS))
+	apiMux.HandleFunc("/api/freshrss/sync", userHandlers.Route(freshrssHandler.HandleSync))
+	apiMux.HandleFunc("/api/freshrss/sync-feed", userHandlers.Route(freshrssHandler.HandleSyncFeed))
+	apiMux.HandleFunc("/api/freshrss/status", userHandlers.Route(freshrssHandler.HandleSyncStatus))
 	// RSSHub routes
-	apiMux.HandleFunc("/api/rsshub/add", func(w http.ResponseWriter, r *http.Request) { rsshubHandler.HandleAddFeed(h, w, r) })
-	apiMux.HandleFunc("/api/rsshub/test-connection", func(w http.ResponseWriter, r *http.Request) { rsshubHandler.HandleTestConnection(h, w, r) })
-	apiMux.HandleFunc("/api/rsshub/validate-route", func(w http.ResponseWriter, r *http.Request) { rsshubHandler.HandleValidateRoute(h, w, r) })
-	apiMux.HandleFunc("/api/rsshub/transform-url", func(w http.ResponseWriter, r *http.Request) { rsshubHandler.HandleTransformURL(h, w, r) })
+	apiMux.HandleFunc("/api/rsshub/add", userHandlers.Route(rsshubHandler.HandleAddFeed))
+	apiMux.HandleFunc("/api/rsshub/test-connection", userHandlers.Route(rsshubHandler.HandleTestConnection))
+	apiMux.HandleFunc("/api/rsshub/validate-route", userHandlers.Route(rsshubHandler.HandleValidateRoute))
+	apiMux.HandleFunc("/api/rsshub/transform-url", userHandlers.Route(rsshubHandler.HandleTransformURL))
+	// WebSub callback (hubs push feed updates here)
+	apiMux.HandleFunc("/api/websub/", userHandlers.Route(websubHandler.HandleCallback))
+	apiMux.HandleFunc("/api/websub/users/{user}/{feed}", func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.ParseInt(r.PathValue("user"), 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		uh, err := userHandlers.HandlerByID(userID)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		websubHandler.HandleCallback(uh, w, r)
+	})
+	// Google Reader API for mobile sync clients (Reeder, FeedMe, NetNewsWire, ...)
+	apiMux.Handle(greader.PathPrefix+"/", greader.NewMultiUserServer(userHandlers.Databases))
+	// Fever API for older third-party readers
+	apiMux.Handle(fever.PathPrefix, fever.NewMultiUserServer(userHandlers.Databases))
 	// Statistics routes
-	apiMux.HandleFunc("/api/statistics", func(w http.ResponseWriter, r *http.Request) {
+	apiMux.HandleFunc("/api/statistics", userHandlers.Route(func(h *handlers.Handler, w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodDelete {
 			stathandlers.HandleResetStatistics(h, w, r)
 		} else {
 			stathandlers.HandleGetStatistics(h, w, r)
 		}
-	})
-	apiMux.HandleFunc("/api/statistics/all-time", func(w http.ResponseWriter, r *http.Request) { stathandlers.HandleGetAllTimeStatistics(h, w, r) })
-	apiMux.HandleFunc("/api/statistics/available-months", func(w http.ResponseWriter, r *http.Request) { stathandlers.HandleGetAvailableMonths(h, w, r) })
+	}))
+	apiMux.HandleFunc("/api/statistics/all-time", userHandlers.Route(stathandlers.HandleGetAllTimeStatistics))
+	apiMux.HandleFunc("/api/statistics/available-months", userHandlers.Route(stathandlers.HandleGetAvailableMonths))
 
 	// Swagger Documentation - Serve swagger.json file
 	apiMux.HandleFunc("/docs/SERVER_MODE/swagger.json", func(w http.ResponseWriter, r *http.Request) {
@@ -292,19 +389,42 @@ func main() {
 
 	fileServer := http.FileServer(http.FS(frontendFS))
 
+	// Authentication: everything but login, the version (health checks), WebSub callbacks
+	// and the sync APIs, which check their own credentials, needs a session or API token
+	var api http.Handler = apiMux
+	if *authEnabled {
+		generated, err := auth.EnsureAdmin(db, *password)
+		if err != nil {
+			log.Fatalf("Error setting admin password: %v", err)
+		}
+		if generated != "" {
+			log.Printf("Created user %q with generated password: %s", auth.AdminUsername, generated)
+			log.Println("Change it after logging in, or restart with -password / MRRSS_PASSWORD")
+		}
+		api = auth.NewMiddleware(db,
+			"/api/auth/login",
+			"/api/version",
+			"/api/websub/",
+			greader.PathPrefix+"/",
+			fever.PathPrefix,
+		).Wrap(apiMux)
+	} else {
+		log.Println("WARNING: Authentication is disabled; anyone who can reach the server can use the API")
+	}
+
 	combinedHandler := &CombinedHandler{
-		apiMux:     apiMux,
+		api:        api,
 		fileServer: fileServer,
 	}
 
 	log.Printf("Starting in headless server mode on http://%s:%s", *host, *port)
 
-	// Start background scheduler
-	// Use a context that we can cancel on shutdown
-	bgCtx, bgCancel := context.WithCancel(context.Background())
-
+	// Start background schedulers
 	log.Println("Starting background scheduler...")
 	go h.StartBackgroundScheduler(bgCtx)
+	if err := userHandlers.Start(); err != nil {
+		log.Printf("Error opening user databases: %v", err)
+	}
 
 	// Start Network Speed Detection (optional but good to have)
 	go func() {
@@ -315,8 +435,10 @@ func main() {
 
 		result := detector.DetectSpeed(detectCtx)
 		if result.DetectionSuccess {
-			db.SetSetting("network_speed", string(result.SpeedLevel))
-			db.SetSetting("network_bandwidth_mbps", fmt.Sprintf("%.2f", result.BandwidthMbps))
+			for _, db := range userHandlers.Databases() {
+				db.SetSetting("network_speed", string(result.SpeedLevel))
+				db.SetSetting("network_bandwidth_mbps", fmt.Sprintf("%.2f", result.BandwidthMbps))
+			}
 			log.Printf("Network detection complete: %s", result.SpeedLevel)
 		}
 	}()
@@ -348,7 +470,9 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
-	// Close Database
+	// Close Databases
+	userHandlers.Close()
+	h.Notifications.Stop()
 	if err := db.Close(); err != nil {
 		log.Printf("Error closing database: %v", err)
 	} else {
@@ -24,15 +24,18 @@ import (
 	"MrRSS/internal/feed"
 	aihandlers "MrRSS/internal/handlers/ai"
 	article "MrRSS/internal/handlers/article"
+	backuphandlers "MrRSS/internal/handlers/backup"
 	browser "MrRSS/internal/handlers/browser"
 	chat "MrRSS/internal/handlers/chat"
 	handlers "MrRSS/internal/handlers/core"
 	customcss "MrRSS/internal/handlers/custom_css"
+	digesthandlers "MrRSS/internal/handlers/digest"
 	discovery "MrRSS/internal/handlers/discovery"
 	feedhandlers "MrRSS/internal/handlers/feed"
 	freshrssHandler "MrRSS/internal/handlers/freshrss"
 	media "MrRSS/internal/handlers/media"
 	networkhandlers "MrRSS/internal/handlers/network"
+	notifyhandlers "MrRSS/internal/handlers/notify"
 	opml "MrRSS/internal/handlers/opml"
 	rsshubHandler "MrRSS/internal/handlers/rsshub"
 	rules "MrRSS/internal/handlers/rules"
@@ -40,6 +43,7 @@ import (
 	settings "MrRSS/internal/handlers/settings"
 	stathandlers "MrRSS/internal/handlers/statistics"
 	summary "MrRSS/internal/handlers/summary"
+	taghandlers "MrRSS/internal/handlers/tags"
 	translationhandlers "MrRSS/internal/handlers/translation"
 	update "MrRSS/internal/handlers/update"
 	window "MrRSS/internal/handlers/window"
@@ -168,6 +172,13 @@ func main() {
 	}
 	log.Println("Database initialized successfully")
 
+	// Secrets encrypted with a master passphrase can be unlocked at startup
+	if passphrase := os.Getenv("MRRSS_MASTER_PASSPHRASE"); passphrase != "" && db.SecretsStatus().Locked {
+		if err := db.UnlockSecrets(passphrase); err != nil {
+			log.Printf("Could not unlock secrets with $MRRSS_MASTER_PASSPHRASE: %v", err)
+		}
+	}
+
 	translator := translation.NewDynamicTranslatorWithCache(db, db)
 	fetcher := feed.NewFetcher(db)
 	h := handlers.NewHandler(db, fetcher, translator)
@@ -191,11 +202,19 @@ func main() {
 	apiMux.HandleFunc("/api/feeds/discover-all/start", func(w http.ResponseWriter, r *http.Request) { discovery.HandleStartBatchDiscovery(h, w, r) })
 	apiMux.HandleFunc("/api/feeds/discover-all/progress", func(w http.ResponseWriter, r *http.Request) { discovery.HandleGetBatchDiscoveryProgress(h, w, r) })
 	apiMux.HandleFunc("/api/feeds/discover-all/clear", func(w http.ResponseWriter, r *http.Request) { discovery.HandleClearBatchDiscovery(h, w, r) })
+	apiMux.HandleFunc("/api/feeds/http-settings", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedHTTPSettings(h, w, r) })
+	apiMux.HandleFunc("/api/feeds/fulltext-settings", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedFullTextSettings(h, w, r) })
+	apiMux.HandleFunc("/api/feeds/fulltext-test", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleTestFullTextExtraction(h, w, r) })
+	apiMux.HandleFunc("/api/feeds/email-settings", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedEmailSettings(h, w, r) })
+	apiMux.HandleFunc("/api/feeds/{id}/health", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedHealth(h, w, r) })
 	apiMux.HandleFunc("/api/feeds/reorder", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleReorderFeed(h, w, r) })
 	apiMux.HandleFunc("/api/feeds/test-imap", func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleTestIMAPConnection(h, w, r) })
 	apiMux.HandleFunc("/api/articles", func(w http.ResponseWriter, r *http.Request) { article.HandleArticles(h, w, r) })
 	apiMux.HandleFunc("/api/articles/images", func(w http.ResponseWriter, r *http.Request) { article.HandleImageGalleryArticles(h, w, r) })
 	apiMux.HandleFunc("/api/articles/filter", func(w http.ResponseWriter, r *http.Request) { article.HandleFilteredArticles(h, w, r) })
+	apiMux.HandleFunc("/api/articles/search", func(w http.ResponseWriter, r *http.Request) { article.HandleSearchArticles(h, w, r) })
+	apiMux.HandleFunc("/api/articles/categories", func(w http.ResponseWriter, r *http.Request) { article.HandleArticleCategories(h, w, r) })
+	apiMux.HandleFunc("/api/articles/tags", func(w http.ResponseWriter, r *http.Request) { article.HandleBulkTagArticles(h, w, r) })
 	apiMux.HandleFunc("/api/articles/read", func(w http.ResponseWriter, r *http.Request) { article.HandleMarkReadWithImmediateSync(h, w, r) })
 	apiMux.HandleFunc("/api/articles/favorite", func(w http.ResponseWriter, r *http.Request) { article.HandleToggleFavoriteWithImmediateSync(h, w, r) })
 	apiMux.HandleFunc("/api/articles/cleanup", func(w http.ResponseWriter, r *http.Request) { article.HandleCleanupArticles(h, w, r) })
@@ -230,6 +249,8 @@ func main() {
 	apiMux.HandleFunc("/api/articles/toggle-read-later", func(w http.ResponseWriter, r *http.Request) { article.HandleToggleReadLater(h, w, r) })
 	apiMux.HandleFunc("/api/articles/content", func(w http.ResponseWriter, r *http.Request) { article.HandleGetArticleContent(h, w, r) })
 	apiMux.HandleFunc("/api/articles/fetch-full", func(w http.ResponseWriter, r *http.Request) { article.HandleFetchFullArticle(h, w, r) })
+	apiMux.HandleFunc("/api/articles/revisions", func(w http.ResponseWriter, r *http.Request) { article.HandleArticleRevisions(h, w, r) })
+	apiMux.HandleFunc("/api/articles/revisions/diff", func(w http.ResponseWriter, r *http.Request) { article.HandleArticleRevisionDiff(h, w, r) })
 	apiMux.HandleFunc("/api/articles/unread-counts", func(w http.ResponseWriter, r *http.Request) { article.HandleGetUnreadCounts(h, w, r) })
 	apiMux.HandleFunc("/api/articles/mark-all-read", func(w http.ResponseWriter, r *http.Request) { article.HandleMarkAllAsRead(h, w, r) })
 	apiMux.HandleFunc("/api/articles/clear-read-later", func(w http.ResponseWriter, r *http.Request) { article.HandleClearReadLater(h, w, r) })
@@ -237,6 +258,9 @@ func main() {
 	apiMux.HandleFunc("/api/articles/clear-summaries", func(w http.ResponseWriter, r *http.Request) { summary.HandleClearSummaries(h, w, r) })
 	apiMux.HandleFunc("/api/articles/export/obsidian", func(w http.ResponseWriter, r *http.Request) { article.HandleExportToObsidian(h, w, r) })
 	apiMux.HandleFunc("/api/settings", func(w http.ResponseWriter, r *http.Request) { settings.HandleSettings(h, w, r) })
+	apiMux.HandleFunc("/api/secrets/status", func(w http.ResponseWriter, r *http.Request) { settings.HandleSecretsStatus(h, w, r) })
+	apiMux.HandleFunc("/api/secrets/unlock", func(w http.ResponseWriter, r *http.Request) { settings.HandleUnlockSecrets(h, w, r) })
+	apiMux.HandleFunc("/api/secrets/passphrase", func(w http.ResponseWriter, r *http.Request) { settings.HandleMasterPassphrase(h, w, r) })
 	apiMux.HandleFunc("/api/refresh", func(w http.ResponseWriter, r *http.Request) { article.HandleRefresh(h, w, r) })
 	apiMux.HandleFunc("/api/progress", func(w http.ResponseWriter, r *http.Request) { article.HandleProgress(h, w, r) })
 	apiMux.HandleFunc("/api/progress/task-details", func(w http.ResponseWriter, r *http.Request) { article.HandleTaskDetails(h, w, r) })
@@ -244,11 +268,37 @@ func main() {
 	apiMux.HandleFunc("/api/opml/export", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLExport(h, w, r) })
 	apiMux.HandleFunc("/api/opml/import-dialog", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLImportDialog(h, w, r) })
 	apiMux.HandleFunc("/api/opml/export-dialog", func(w http.ResponseWriter, r *http.Request) { opml.HandleOPMLExportDialog(h, w, r) })
+	apiMux.HandleFunc("/api/backup/export", func(w http.ResponseWriter, r *http.Request) { backuphandlers.HandleBackupExport(h, w, r) })
+	apiMux.HandleFunc("/api/backup/import", func(w http.ResponseWriter, r *http.Request) { backuphandlers.HandleBackupImport(h, w, r) })
 	apiMux.HandleFunc("/api/check-updates", func(w http.ResponseWriter, r *http.Request) { update.HandleCheckUpdates(h, w, r) })
 	apiMux.HandleFunc("/api/download-update", func(w http.ResponseWriter, r *http.Request) { update.HandleDownloadUpdate(h, w, r) })
 	apiMux.HandleFunc("/api/install-update", func(w http.ResponseWriter, r *http.Request) { update.HandleInstallUpdate(h, w, r) })
 	apiMux.HandleFunc("/api/version", func(w http.ResponseWriter, r *http.Request) { update.HandleVersion(h, w, r) })
+	apiMux.HandleFunc("/api/rules", func(w http.ResponseWriter, r *http.Request) { rules.HandleRules(h, w, r) })
+	apiMux.HandleFunc("/api/rules/update", func(w http.ResponseWriter, r *http.Request) { rules.HandleUpdateRule(h, w, r) })
+	apiMux.HandleFunc("/api/rules/delete", func(w http.ResponseWriter, r *http.Request) { rules.HandleDeleteRule(h, w, r) })
+	apiMux.HandleFunc("/api/rules/history", func(w http.ResponseWriter, r *http.Request) { rules.HandleRuleHistory(h, w, r) })
+	apiMux.HandleFunc("/api/rules/rollback", func(w http.ResponseWriter, r *http.Request) { rules.HandleRollbackRule(h, w, r) })
+	apiMux.HandleFunc("/api/rules/export", func(w http.ResponseWriter, r *http.Request) { rules.HandleExportRules(h, w, r) })
+	apiMux.HandleFunc("/api/rules/import", func(w http.ResponseWriter, r *http.Request) { rules.HandleImportRules(h, w, r) })
 	apiMux.HandleFunc("/api/rules/apply", func(w http.ResponseWriter, r *http.Request) { rules.HandleApplyRule(h, w, r) })
+	apiMux.HandleFunc("/api/rules/preview", func(w http.ResponseWriter, r *http.Request) { rules.HandlePreviewRule(h, w, r) })
+	apiMux.HandleFunc("/api/rules/stats", func(w http.ResponseWriter, r *http.Request) { rules.HandleRuleStats(h, w, r) })
+	apiMux.HandleFunc("/api/digests", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDigests(h, w, r) })
+	apiMux.HandleFunc("/api/digests/update", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleUpdateDigest(h, w, r) })
+	apiMux.HandleFunc("/api/digests/delete", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDeleteDigest(h, w, r) })
+	apiMux.HandleFunc("/api/digests/send", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleSendDigest(h, w, r) })
+	apiMux.HandleFunc("/api/digests/sends", func(w http.ResponseWriter, r *http.Request) { digesthandlers.HandleDigestSends(h, w, r) })
+	apiMux.HandleFunc("/api/notifications/targets", func(w http.ResponseWriter, r *http.Request) { notifyhandlers.HandleNotificationTargets(h, w, r) })
+	apiMux.HandleFunc("/api/notifications/targets/update", func(w http.ResponseWriter, r *http.Request) { notifyhandlers.HandleUpdateNotificationTarget(h, w, r) })
+	apiMux.HandleFunc("/api/notifications/targets/delete", func(w http.ResponseWriter, r *http.Request) { notifyhandlers.HandleDeleteNotificationTarget(h, w, r) })
+	apiMux.HandleFunc("/api/notifications/test", func(w http.ResponseWriter, r *http.Request) { notifyhandlers.HandleTestNotification(h, w, r) })
+	apiMux.HandleFunc("/api/notifications/dead-letters", func(w http.ResponseWriter, r *http.Request) { notifyhandlers.HandleDeadNotifications(h, w, r) })
+	apiMux.HandleFunc("/api/notifications/dead-letters/retry", func(w http.ResponseWriter, r *http.Request) { notifyhandlers.HandleRetryDeadNotification(h, w, r) })
+	apiMux.HandleFunc("/api/notifications/dead-letters/delete", func(w http.ResponseWriter, r *http.Request) { notifyhandlers.HandleDeleteDeadNotification(h, w, r) })
+	apiMux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) { taghandlers.HandleTags(h, w, r) })
+	apiMux.HandleFunc("/api/tags/update", func(w http.ResponseWriter, r *http.Request) { taghandlers.HandleUpdateTag(h, w, r) })
+	apiMux.HandleFunc("/api/tags/delete", func(w http.ResponseWriter, r *http.Request) { taghandlers.HandleDeleteTag(h, w, r) })
 	apiMux.HandleFunc("/api/scripts/dir", func(w http.ResponseWriter, r *http.Request) { script.HandleGetScriptsDir(h, w, r) })
 	apiMux.HandleFunc("/api/scripts/open", func(w http.ResponseWriter, r *http.Request) { script.HandleOpenScriptsDir(h, w, r) })
 	apiMux.HandleFunc("/api/scripts/list", func(w http.ResponseWriter, r *http.Request) { script.HandleListScripts(h, w, r) })
@@ -713,6 +763,7 @@ func main() {
 	bgCancel()
 	// Give some time for tasks to finish
 	time.Sleep(500 * time.Millisecond)
+	h.Notifications.Stop()
 
 	// Close DB with timeout
 	done := make(chan struct{})